@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+// maxAgentPreviewLen limits the prompt preview printed for each agent by --list-agents.
+const maxAgentPreviewLen = 72
+
+// listAgents prints every agent available for {{agent:name}} references with a one-line prompt preview,
+// marking built-in agents and user files that replace them, followed by the review agent sets in use
+// and the built-in agents disabled in review.
+func listAgents(w io.Writer, cfg *config.Config) {
+	if len(cfg.CustomAgents) == 0 {
+		fmt.Fprintln(w, "no agents configured")
+	} else {
+		fmt.Fprintln(w, "agents:")
+	}
+	for _, agent := range cfg.CustomAgents {
+		var tags []string
+		switch {
+		case agent.Overridden:
+			tags = append(tags, "custom, overrides built-in")
+		case agent.Builtin:
+			tags = append(tags, "built-in")
+		}
+		if agent.IsCommand() {
+			tags = append(tags, "command")
+		}
+		if agent.Model != "" {
+			tags = append(tags, "model: "+agent.Model)
+		}
+		name := agent.Name
+		if len(tags) > 0 {
+			name += " (" + strings.Join(tags, ", ") + ")"
+		}
+		fmt.Fprintf(w, "  %s: %s\n", name, agentPreview(agent.Prompt))
+	}
+
+	fmt.Fprintf(w, "first review agents: %s\n", agentList(cfg.ReviewFirstAgents))
+	fmt.Fprintf(w, "second review agents: %s\n", agentList(cfg.ReviewSecondAgents))
+	if len(cfg.DisabledReviewAgents) > 0 {
+		fmt.Fprintf(w, "disabled review agents: %s\n", agentList(cfg.DisabledReviewAgents))
+	}
+}
+
+// agentList joins agent names for display, "none" for an empty set.
+func agentList(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// agentPreview returns the first line of the prompt that is neither blank nor a comment, shortened to maxAgentPreviewLen.
+func agentPreview(prompt string) string {
+	for line := range strings.Lines(prompt) {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "#" || strings.HasPrefix(line, "# ") { // blank or comment line
+			continue
+		}
+		if runes := []rune(line); len(runes) > maxAgentPreviewLen {
+			return string(runes[:maxAgentPreviewLen-3]) + "..."
+		}
+		return line
+	}
+	return "(empty prompt)"
+}
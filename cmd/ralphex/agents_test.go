@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+func TestListAgents(t *testing.T) {
+	t.Run("agents with review sets", func(t *testing.T) {
+		cfg := &config.Config{
+			CustomAgents: []config.CustomAgent{
+				{Name: "documentation", Prompt: "Review documentation.", Builtin: true},
+				{Name: "quality", Prompt: "# my override\nCheck everything twice.", Builtin: true, Overridden: true},
+				{Name: "lint", Prompt: "golangci-lint run ./...", Options: config.Options{Kind: config.AgentKindCommand}},
+				{Name: "security", Prompt: "Look for injection bugs.", Options: config.Options{Model: "opus"}},
+			},
+			ReviewFirstAgents:    []string{"quality", "documentation"},
+			ReviewSecondAgents:   []string{"quality"},
+			DisabledReviewAgents: []string{"testing"},
+		}
+		var buf bytes.Buffer
+		listAgents(&buf, cfg)
+
+		assert.Equal(t, "agents:\n"+
+			"  documentation (built-in): Review documentation.\n"+
+			"  quality (custom, overrides built-in): Check everything twice.\n"+
+			"  lint (command): golangci-lint run ./...\n"+
+			"  security (model: opus): Look for injection bugs.\n"+
+			"first review agents: quality, documentation\n"+
+			"second review agents: quality\n"+
+			"disabled review agents: testing\n", buf.String())
+	})
+
+	t.Run("no agents", func(t *testing.T) {
+		var buf bytes.Buffer
+		listAgents(&buf, &config.Config{})
+		assert.Equal(t, "no agents configured\nfirst review agents: none\nsecond review agents: none\n", buf.String())
+	})
+}
+
+func TestAgentPreview(t *testing.T) {
+	tests := []struct {
+		name   string
+		prompt string
+		want   string
+	}{
+		{name: "first line", prompt: "Check security.\n\nMore details.", want: "Check security."},
+		{name: "skips blanks and comments", prompt: "\n# note\n#\n  ## Heading\nbody", want: "## Heading"},
+		{name: "long line shortened", prompt: strings.Repeat("a", 100), want: strings.Repeat("a", 69) + "..."},
+		{name: "empty", prompt: "# only a comment", want: "(empty prompt)"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, agentPreview(tc.prompt))
+		})
+	}
+}
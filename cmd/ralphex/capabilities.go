@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// modeFlags maps each mode to the CLI flag that selects it, full is the default with a plan file.
+var modeFlags = map[processor.Mode]string{
+	processor.ModeFull:         "",
+	processor.ModeReview:       "--review",
+	processor.ModeExternalOnly: "--external-only",
+	processor.ModeTasksOnly:    "--tasks-only",
+	processor.ModePlan:         "--plan",
+}
+
+// capabilities is what --list-modes reports, in the JSON form used by --list-modes=json.
+type capabilities struct {
+	Version string              `json:"version"`
+	Modes   []modeInfo          `json:"modes"`
+	Signals []status.SignalInfo `json:"signals"`
+	Config  map[string]any      `json:"config"`
+}
+
+// modeInfo describes one execution mode.
+type modeInfo struct {
+	Name        string `json:"name"`
+	Flag        string `json:"flag,omitempty"`
+	Default     bool   `json:"default,omitempty"`
+	Description string `json:"description"`
+}
+
+// listModes prints the execution modes, the recognized signal markers and the effective config values.
+// format is "text" or "json", the JSON form is meant for wrapper tools adapting to the installed version.
+func listModes(w io.Writer, cfg *config.Config, format string) error {
+	defaultMode := processor.ModeFull
+	if cfg.DefaultMode != "" {
+		defaultMode = processor.Mode(cfg.DefaultMode)
+	}
+	caps := capabilities{Version: revision, Signals: status.AllSignals, Config: cfg.EffectiveValues()}
+	for _, m := range processor.AllModes {
+		caps.Modes = append(caps.Modes, modeInfo{Name: string(m), Flag: modeFlags[m], Default: m == defaultMode,
+			Description: m.Description()})
+	}
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(caps, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal capabilities: %w", err)
+		}
+		fmt.Fprintf(w, "%s\n", data)
+		return nil
+	case "text":
+	default:
+		return fmt.Errorf("invalid --list-modes format %q, expected text or json", format)
+	}
+
+	fmt.Fprintf(w, "ralphex %s\n\nmodes:\n", caps.Version)
+	for _, m := range caps.Modes {
+		name := m.Name
+		var tags []string
+		if m.Flag != "" {
+			tags = append(tags, m.Flag)
+		}
+		if m.Default {
+			tags = append(tags, "default")
+		}
+		if len(tags) > 0 {
+			name += " (" + strings.Join(tags, ", ") + ")"
+		}
+		fmt.Fprintf(w, "  %s: %s\n", name, m.Description)
+	}
+	fmt.Fprintln(w, "\nsignals:")
+	for _, s := range caps.Signals {
+		fmt.Fprintf(w, "  %s: %s\n", s.Marker, s.Description)
+	}
+	fmt.Fprintln(w, "\nconfig:")
+	for _, k := range slices.Sorted(maps.Keys(caps.Config)) {
+		fmt.Fprintf(w, "  %s = %s\n", k, formatConfigValue(caps.Config[k]))
+	}
+	return nil
+}
+
+// formatConfigValue renders a config value for the text listing, strings as is and anything else as JSON.
+func formatConfigValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
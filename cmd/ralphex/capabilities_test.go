@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestListModes(t *testing.T) {
+	cfg := &config.Config{DefaultMode: "review", MaxIterations: 7, WaitOnLimit: time.Hour, WatchDirs: []string{"a", "b"}}
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, listModes(&buf, cfg, "text"))
+		out := buf.String()
+		assert.Contains(t, out, "modes:\n  full: execute plan tasks")
+		assert.Contains(t, out, "\n  review (--review, default): skip tasks")
+		assert.Contains(t, out, "\n  plan (--plan): create a plan")
+		assert.Contains(t, out, "\nsignals:\n  <<<RALPHEX:ALL_TASKS_DONE>>>: all plan tasks are done")
+		assert.Contains(t, out, "\n  max_iterations = 7\n")
+		assert.Contains(t, out, "\n  wait_on_limit = 1h0m0s\n")
+		assert.Contains(t, out, "\n  watch_dirs = [\"a\",\"b\"]\n")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, listModes(&buf, cfg, "json"))
+		var caps capabilities
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &caps))
+		require.Len(t, caps.Modes, len(processor.AllModes))
+		assert.Equal(t, modeInfo{Name: "external-only", Flag: "--external-only",
+			Description: processor.ModeExternalOnly.Description()}, caps.Modes[2])
+		assert.True(t, caps.Modes[1].Default)
+		assert.Equal(t, status.AllSignals, caps.Signals)
+		assert.InDelta(t, 7, caps.Config["max_iterations"], 0)
+		assert.Equal(t, "1h0m0s", caps.Config["wait_on_limit"])
+	})
+
+	t.Run("full is the default without default_mode", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, listModes(&buf, &config.Config{}, "text"))
+		assert.Contains(t, buf.String(), "\n  full (default): ")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		err := listModes(&bytes.Buffer{}, cfg, "yaml")
+		require.EqualError(t, err, `invalid --list-modes format "yaml", expected text or json`)
+	})
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/processor"
+)
+
+// diskCheckInterval is how often free disk space is checked while a run is active.
+const diskCheckInterval = 30 * time.Second
+
+// errLowDiskSpace marks a run stopped because free disk space dropped below min_free_disk_mb.
+var errLowDiskSpace = errors.New("low disk space")
+
+// checkDiskSpace returns an errLowDiskSpace error when the filesystem holding dir has less than minMB
+// megabytes free. minMB 0 disables the check. when free space can't be read (unsupported platform or
+// filesystem) the check passes, it is a safety net and should not block a run on its own.
+func checkDiskSpace(dir string, minMB int) error {
+	if minMB <= 0 {
+		return nil
+	}
+	free, err := freeDiskSpace(dir)
+	if err != nil {
+		return nil //nolint:nilerr // unknown free space doesn't block the run
+	}
+	const mb = 1024 * 1024
+	if free < uint64(minMB)*mb { //nolint:gosec // minMB is validated as non-negative
+		return fmt.Errorf("%w: %d MB free on the filesystem of %s, below min_free_disk_mb = %d",
+			errLowDiskSpace, free/mb, dir, minMB)
+	}
+	return nil
+}
+
+// watchDiskSpace checks free space in dir every interval and cancels the returned context with the
+// errLowDiskSpace error once it drops below minMB, so the run stops through its normal interrupt path
+// (executors killed, post-run hooks and worktree cleanup run) before writes start failing.
+// the returned function stops the checks and must be called when the run returns.
+func watchDiskSpace(ctx context.Context, dir string, minMB int, interval time.Duration,
+	log processor.Logger) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	if minMB <= 0 {
+		return ctx, func() { cancel(nil) }
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := checkDiskSpace(dir, minMB); err != nil {
+					log.Print("stopping run: %v", err)
+					cancel(err)
+					return
+				}
+			}
+		}
+	})
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+			cancel(nil)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package main
+
+import (
+	"errors"
+	"runtime"
+)
+
+// freeDiskSpace is not implemented on this platform, so the min_free_disk_mb check is skipped.
+func freeDiskSpace(string) (uint64, error) {
+	return 0, errors.New("free disk space is not supported on " + runtime.GOOS)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	procmocks "github.com/umputun/ralphex/pkg/processor/mocks"
+)
+
+func TestFreeDiskSpace(t *testing.T) {
+	free, err := freeDiskSpace(t.TempDir())
+	require.NoError(t, err)
+	assert.Positive(t, free)
+
+	_, err = freeDiskSpace("/does/not/exist")
+	require.Error(t, err)
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, checkDiskSpace(dir, 0), "0 disables the check")
+	require.NoError(t, checkDiskSpace(dir, 1))
+	require.NoError(t, checkDiskSpace("/does/not/exist", math.MaxInt32), "unreadable free space passes")
+
+	err := checkDiskSpace(dir, math.MaxInt32)
+	require.ErrorIs(t, err, errLowDiskSpace)
+	assert.Contains(t, err.Error(), "below min_free_disk_mb = 2147483647")
+}
+
+func TestWatchDiskSpace(t *testing.T) {
+	newLog := func() *procmocks.LoggerMock {
+		return &procmocks.LoggerMock{PrintFunc: func(string, ...any) {}}
+	}
+
+	t.Run("low space cancels with cause", func(t *testing.T) {
+		log := newLog()
+		ctx, stop := watchDiskSpace(t.Context(), t.TempDir(), math.MaxInt32, time.Millisecond, log)
+		defer stop()
+		select {
+		case <-ctx.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("context not canceled")
+		}
+		require.ErrorIs(t, context.Cause(ctx), errLowDiskSpace)
+		require.Len(t, log.PrintCalls(), 1)
+		assert.Equal(t, "stopping run: %v", log.PrintCalls()[0].Format)
+	})
+
+	t.Run("enough space keeps running", func(t *testing.T) {
+		log := newLog()
+		ctx, stop := watchDiskSpace(t.Context(), t.TempDir(), 1, time.Millisecond, log)
+		time.Sleep(20 * time.Millisecond)
+		require.NoError(t, ctx.Err())
+		stop()
+		stop() // safe to call twice
+		require.ErrorIs(t, context.Cause(ctx), context.Canceled)
+		assert.Empty(t, log.PrintCalls())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		ctx, stop := watchDiskSpace(t.Context(), t.TempDir(), 0, time.Millisecond, newLog())
+		time.Sleep(5 * time.Millisecond)
+		require.NoError(t, ctx.Err())
+		stop()
+		assert.NotErrorIs(t, context.Cause(ctx), errLowDiskSpace)
+	})
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/plan"
+	"github.com/umputun/ralphex/pkg/processor"
+)
+
+// estimateDiffStep is the number of changed lines that adds one more average to a review iteration,
+// reviews of large diffs read more code and take more rounds of fixes.
+const estimateDiffStep = 1000
+
+// default per-iteration averages for --estimate, estimate_tokens and estimate_minutes override them per phase.
+var (
+	defaultEstimateTokens  = map[string]int{"task": 80000, "review": 120000, "external": 60000, "finalize": 40000}
+	defaultEstimateMinutes = map[string]int{"task": 6, "review": 8, "external": 6, "finalize": 3}
+)
+
+// phaseEstimate is the estimated iteration range of one phase and the averages of a single iteration.
+type phaseEstimate struct {
+	name           string
+	minIter        int
+	maxIter        int
+	tokensPerIter  int
+	minutesPerIter int
+}
+
+// estimateRun prints a rough estimate of the iterations, tokens and wall-clock time the run would take:
+// the phases come from explainPhases, task iterations from the open tasks of the plan and review costs
+// grow with the current diff. it only reads the plan and the repo, nothing is executed.
+func estimateRun(w io.Writer, o opts, req executePlanRequest) error {
+	openTasks := 0
+	if req.PlanFile != "" {
+		p, err := plan.ParsePlanFile(req.PlanFile)
+		if err != nil {
+			return fmt.Errorf("estimate: %w", err)
+		}
+		for _, t := range p.Tasks {
+			if t.HasUncompletedActionableWork() {
+				openTasks++
+			}
+		}
+		fmt.Fprintf(w, "plan: %s (%d open of %d tasks)\n", toRelPath(req.PlanFile), openTasks, len(p.Tasks))
+	}
+
+	stats, err := req.GitSvc.DiffStats(req.BaseRef, statsExcludes(req, "")...)
+	if err != nil {
+		return fmt.Errorf("estimate: %w", err)
+	}
+	fmt.Fprintf(w, "diff: %d files, +%d/-%d lines since %s\n", stats.Files, stats.Additions, stats.Deletions, req.BaseRef)
+
+	phases := estimatePhases(o, req.Mode, req.Config, openTasks, stats.Additions+stats.Deletions)
+	table, err := formatEstimate(phases)
+	if err != nil {
+		return fmt.Errorf("estimate: %w", err)
+	}
+	fmt.Fprint(w, table)
+	return nil
+}
+
+// estimatePhases turns the phases of the run into iteration ranges with per-iteration averages.
+// each open task takes one or two iterations, the first review pass and finalize run once, the review
+// and external review loops run at least once and at most up to their iteration limits.
+func estimatePhases(o opts, mode processor.Mode, cfg *config.Config, openTasks, diffLines int) []phaseEstimate {
+	maxIter := resolveMaxIterations(o.MaxIterations, cfg)
+	reviewLimit := processor.ReviewIterationLimit(maxIter)
+	externalLimit := processor.ExternalIterationLimit(maxIter, cfg.MaxExternalIterations)
+
+	average := func(phase string) (tokens, minutes int) {
+		tokens, minutes = defaultEstimateTokens[phase], defaultEstimateMinutes[phase]
+		if v, ok := cfg.EstimateTokens[phase]; ok {
+			tokens = v
+		}
+		if v, ok := cfg.EstimateMinutes[phase]; ok {
+			minutes = v
+		}
+		if phase == "review" || phase == "external" {
+			tokens += tokens * diffLines / estimateDiffStep
+			minutes += minutes * diffLines / estimateDiffStep
+		}
+		return tokens, minutes
+	}
+	add := func(res []phaseEstimate, name, phase string, lo, hi int) []phaseEstimate {
+		tokens, minutes := average(phase)
+		return append(res, phaseEstimate{name: name, minIter: lo, maxIter: hi, tokensPerIter: tokens, minutesPerIter: minutes})
+	}
+
+	var res []phaseEstimate
+	names := explainPhases(o, mode, cfg)
+	lastReview := -1 // the claude review loop, an earlier review is the first pass
+	for i, name := range names {
+		if name == "review" {
+			lastReview = i
+		}
+	}
+	for i, name := range names {
+		switch name {
+		case "tasks":
+			res = add(res, name, "task", openTasks, min(2*openTasks, maxIter))
+		case "tasks with per-task review":
+			res = add(res, "tasks", "task", openTasks, min(2*openTasks, maxIter))
+			res = add(res, "task reviews", "review", openTasks, openTasks*reviewLimit)
+		case "review":
+			if i != lastReview {
+				res = add(res, name, "review", 1, 1)
+				continue
+			}
+			res = add(res, name, "review", 1, reviewLimit)
+		case "codex", "custom review":
+			res = add(res, name, "external", 1, externalLimit)
+		case "finalize":
+			res = add(res, name, "finalize", 1, 1)
+		}
+	}
+	return res
+}
+
+// formatEstimate renders phase estimates as an aligned table followed by the totals.
+func formatEstimate(phases []phaseEstimate) (string, error) {
+	var sb strings.Builder
+	var total phaseTotals
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  phase\titerations\ttokens\ttime")
+	for _, p := range phases {
+		var t phaseTotals
+		t.add(p)
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", p.name, formatRange(t.minIter, t.maxIter, strconv.Itoa),
+			formatRange(t.minTokens, t.maxTokens, formatTokens), formatRange(t.minMinutes, t.maxMinutes, formatMinutes))
+		total.add(p)
+	}
+	if err := tw.Flush(); err != nil {
+		return "", fmt.Errorf("flush estimate table: %w", err)
+	}
+	fmt.Fprintf(&sb, "total: %s iterations, ~%s tokens, %s\n", formatRange(total.minIter, total.maxIter, strconv.Itoa),
+		formatRange(total.minTokens, total.maxTokens, formatTokens), formatRange(total.minMinutes, total.maxMinutes, formatMinutes))
+	sb.WriteString("rough heuristic, tune the per-phase averages with estimate_tokens and estimate_minutes\n")
+	return sb.String(), nil
+}
+
+// phaseTotals sums the low and high ends of phase estimates.
+type phaseTotals struct {
+	minIter, maxIter       int
+	minTokens, maxTokens   int
+	minMinutes, maxMinutes int
+}
+
+func (t *phaseTotals) add(p phaseEstimate) {
+	t.minIter += p.minIter
+	t.maxIter += p.maxIter
+	t.minTokens += p.minIter * p.tokensPerIter
+	t.maxTokens += p.maxIter * p.tokensPerIter
+	t.minMinutes += p.minIter * p.minutesPerIter
+	t.maxMinutes += p.maxIter * p.minutesPerIter
+}
+
+// formatRange renders lo-hi with the given formatter, or a single value when both ends are equal.
+func formatRange(lo, hi int, format func(int) string) string {
+	if lo == hi {
+		return format(lo)
+	}
+	return format(lo) + "-" + format(hi)
+}
+
+// formatTokens renders a token count rounded to thousands or millions, e.g. 850k or 1.2M.
+func formatTokens(n int) string {
+	switch {
+	case n >= 999_500: // would round to 1000k
+		return strconv.FormatFloat(float64(n)/1_000_000, 'f', 1, 64) + "M"
+	case n >= 1000:
+		return strconv.Itoa((n+500)/1000) + "k"
+	default:
+		return strconv.Itoa(n)
+	}
+}
+
+// formatMinutes renders minutes as a short duration, e.g. 45m, 2h or 2h10m.
+func formatMinutes(m int) string {
+	switch {
+	case m < 60:
+		return fmt.Sprintf("%dm", m)
+	case m%60 == 0:
+		return fmt.Sprintf("%dh", m/60)
+	default:
+		return fmt.Sprintf("%dh%dm", m/60, m%60)
+	}
+}
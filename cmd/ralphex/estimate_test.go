@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/processor"
+)
+
+func TestEstimatePhases(t *testing.T) {
+	t.Run("full mode with codex", func(t *testing.T) {
+		cfg := &config.Config{CodexEnabled: true, MaxIterations: 50}
+		want := []phaseEstimate{
+			{name: "tasks", minIter: 3, maxIter: 6, tokensPerIter: 80000, minutesPerIter: 6},
+			{name: "review", minIter: 1, maxIter: 1, tokensPerIter: 120000, minutesPerIter: 8},
+			{name: "codex", minIter: 1, maxIter: 10, tokensPerIter: 60000, minutesPerIter: 6},
+			{name: "review", minIter: 1, maxIter: 5, tokensPerIter: 120000, minutesPerIter: 8},
+		}
+		assert.Equal(t, want, estimatePhases(opts{}, processor.ModeFull, cfg, 3, 0))
+	})
+
+	t.Run("review costs grow with the diff", func(t *testing.T) {
+		cfg := &config.Config{MaxIterations: 50, FinalizeEnabled: true}
+		want := []phaseEstimate{
+			{name: "review", minIter: 1, maxIter: 1, tokensPerIter: 360000, minutesPerIter: 24},
+			{name: "review", minIter: 1, maxIter: 5, tokensPerIter: 360000, minutesPerIter: 24},
+			{name: "finalize", minIter: 1, maxIter: 1, tokensPerIter: 40000, minutesPerIter: 3},
+		}
+		assert.Equal(t, want, estimatePhases(opts{}, processor.ModeReview, cfg, 0, 2000))
+	})
+
+	t.Run("configured averages and limits", func(t *testing.T) {
+		cfg := &config.Config{MaxIterations: 50, MaxExternalIterations: 2, ReviewPerTask: true, SkipFinalReview: true,
+			CodexEnabled: true, ExternalReviewTool: "custom",
+			EstimateTokens: map[string]int{"task": 1000}, EstimateMinutes: map[string]int{"review": 1}}
+		want := []phaseEstimate{
+			{name: "tasks", minIter: 2, maxIter: 4, tokensPerIter: 1000, minutesPerIter: 6},
+			{name: "task reviews", minIter: 2, maxIter: 10, tokensPerIter: 120000, minutesPerIter: 1},
+			{name: "custom review", minIter: 1, maxIter: 2, tokensPerIter: 60000, minutesPerIter: 6},
+			{name: "review", minIter: 1, maxIter: 5, tokensPerIter: 120000, minutesPerIter: 1},
+		}
+		assert.Equal(t, want, estimatePhases(opts{}, processor.ModeFull, cfg, 2, 0))
+	})
+
+	t.Run("task iterations capped by max iterations", func(t *testing.T) {
+		got := estimatePhases(opts{MaxIterations: 3}, processor.ModeTasksOnly, &config.Config{}, 2, 0)
+		require.Len(t, got, 1)
+		assert.Equal(t, 3, got[0].maxIter)
+	})
+}
+
+func TestFormatEstimate(t *testing.T) {
+	phases := []phaseEstimate{
+		{name: "tasks", minIter: 3, maxIter: 6, tokensPerIter: 80000, minutesPerIter: 6},
+		{name: "review", minIter: 1, maxIter: 5, tokensPerIter: 250000, minutesPerIter: 30},
+		{name: "finalize", minIter: 1, maxIter: 1, tokensPerIter: 40000, minutesPerIter: 3},
+	}
+	want := "  phase     iterations  tokens     time\n" +
+		"  tasks     3-6         240k-480k  18m-36m\n" +
+		"  review    1-5         250k-1.2M  30m-2h30m\n" +
+		"  finalize  1           40k        3m\n" +
+		"total: 5-12 iterations, ~530k-1.8M tokens, 51m-3h9m\n" +
+		"rough heuristic, tune the per-phase averages with estimate_tokens and estimate_minutes\n"
+	got, err := formatEstimate(phases)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestFormatTokens(t *testing.T) {
+	assert.Equal(t, "500", formatTokens(500))
+	assert.Equal(t, "80k", formatTokens(80000))
+	assert.Equal(t, "1.0M", formatTokens(999_900))
+	assert.Equal(t, "2.4M", formatTokens(2_400_000))
+}
+
+func TestFormatMinutes(t *testing.T) {
+	assert.Equal(t, "0m", formatMinutes(0))
+	assert.Equal(t, "45m", formatMinutes(45))
+	assert.Equal(t, "2h", formatMinutes(120))
+	assert.Equal(t, "2h10m", formatMinutes(130))
+}
+
+func TestEstimateRun(t *testing.T) {
+	dir := setupTestRepo(t)
+	t.Chdir(dir)
+	gitSvc, err := git.NewService(dir, noopLogger())
+	require.NoError(t, err)
+
+	planFile := filepath.Join(dir, "docs", "plans", "add-auth.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(planFile), 0o750))
+	require.NoError(t, os.WriteFile(planFile, []byte("# Add auth\n\n### Task 1: model\n- [x] add user\n\n"+
+		"### Task 2: api\n- [ ] add login\n\n### Task 3: docs\n- [ ] update readme\n"), 0o600))
+
+	t.Run("tasks only mode", func(t *testing.T) {
+		var buf bytes.Buffer
+		req := executePlanRequest{PlanFile: planFile, Mode: processor.ModeTasksOnly, GitSvc: gitSvc,
+			Config: &config.Config{MaxIterations: 50}, DefaultBranch: "master", BaseRef: "master"}
+		require.NoError(t, estimateRun(&buf, opts{}, req))
+
+		assert.Equal(t, "plan: docs/plans/add-auth.md (2 open of 3 tasks)\n"+
+			"diff: 0 files, +0/-0 lines since master\n"+
+			"  phase  iterations  tokens     time\n"+
+			"  tasks  2-4         160k-320k  12m-24m\n"+
+			"total: 2-4 iterations, ~160k-320k tokens, 12m-24m\n"+
+			"rough heuristic, tune the per-phase averages with estimate_tokens and estimate_minutes\n", buf.String())
+	})
+
+	t.Run("review mode counts the branch diff", func(t *testing.T) {
+		runGit(t, dir, "checkout", "-b", "feature")
+		t.Cleanup(func() { runGit(t, dir, "checkout", "master") })
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o600))
+		runGit(t, dir, "add", "main.go")
+		runGit(t, dir, "commit", "-m", "add main")
+
+		var buf bytes.Buffer
+		req := executePlanRequest{Mode: processor.ModeReview, GitSvc: gitSvc,
+			Config: &config.Config{MaxIterations: 50}, DefaultBranch: "master", BaseRef: "master"}
+		require.NoError(t, estimateRun(&buf, opts{}, req))
+
+		assert.Contains(t, buf.String(), "diff: 1 files, +3/-0 lines since master\n")
+		assert.Contains(t, buf.String(), "total: 2-6 iterations")
+		assert.NotContains(t, buf.String(), "plan:")
+	})
+
+	t.Run("missing plan file", func(t *testing.T) {
+		req := executePlanRequest{PlanFile: filepath.Join(dir, "missing.md"), Mode: processor.ModeFull, GitSvc: gitSvc,
+			Config: &config.Config{}, BaseRef: "master"}
+		err := estimateRun(&bytes.Buffer{}, opts{}, req)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "estimate:")
+	})
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/plan"
+	"github.com/umputun/ralphex/pkg/processor"
+)
+
+// explainRun prints what a run with the resolved config and mode would do: mode, plan, branch,
+// worktree, phases, iteration limit and base ref. it only inspects the repo, nothing is executed.
+func explainRun(w io.Writer, o opts, req executePlanRequest) error {
+	fmt.Fprintf(w, "mode: %s\n", req.Mode)
+	if req.Mode == processor.ModePlan {
+		fmt.Fprintf(w, "plan request: %s\n", o.PlanDescription)
+	}
+	if req.PlanFile != "" {
+		fmt.Fprintf(w, "plan: %s\n", toRelPath(req.PlanFile))
+	}
+
+	branch, err := explainBranch(o, req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "branch: %s\n", branch)
+
+	worktree := "no"
+	if req.Config.WorktreeEnabled && req.PlanFile != "" && modeRequiresBranch(req.Mode) {
+		worktree = "yes"
+	}
+	fmt.Fprintf(w, "worktree: %s\n", worktree)
+	fmt.Fprintf(w, "phases: %s\n", strings.Join(explainPhases(o, req.Mode, req.Config), " -> "))
+	fmt.Fprintf(w, "max iterations: %d\n", resolveMaxIterations(o.MaxIterations, req.Config))
+	fmt.Fprintf(w, "base ref: %s\n", req.BaseRef)
+	return nil
+}
+
+// explainBranch describes the branch the run works on, following the same rules as CreateBranchForPlan:
+// a feature branch is only created when a branch mode starts from the default branch.
+func explainBranch(o opts, req executePlanRequest) (string, error) {
+	current, err := req.GitSvc.CurrentBranch()
+	if err != nil {
+		return "", fmt.Errorf("explain: %w", err)
+	}
+	if current == "" {
+		current = "detached HEAD"
+	}
+	if req.PlanFile == "" || !modeRequiresBranch(req.Mode) {
+		return fmt.Sprintf("%s (current, no branch is created)", current), nil
+	}
+
+	feature := plan.ExtractBranchNameWith(req.PlanFile, branchNameRules(req.Config))
+	if o.BranchName != "" {
+		feature = o.BranchName
+	}
+	if req.Config.WorktreeEnabled {
+		return fmt.Sprintf("%s (in a new worktree from %s)", feature, req.DefaultBranch), nil
+	}
+	isDefault, err := req.GitSvc.IsDefaultBranch(req.DefaultBranch)
+	if err != nil {
+		return "", fmt.Errorf("explain: %w", err)
+	}
+	if !isDefault {
+		return fmt.Sprintf("%s (current, already on a feature branch)", current), nil
+	}
+	return fmt.Sprintf("%s (created from %s)", feature, current), nil
+}
+
+// explainPhases returns the phases the mode runs, in pipeline order. the external review phase is named
+// after the configured tool and left out when external review is disabled, matching the runner's tool selection.
+func explainPhases(o opts, mode processor.Mode, cfg *config.Config) []string {
+	var tail []string
+	if cfg.CodexEnabled || mode == processor.ModeExternalOnly { // external-only mode forces external review, see createRunner
+		switch cfg.ExternalReviewTool {
+		case "", "codex":
+			tail = append(tail, "codex")
+		case "custom":
+			tail = append(tail, "custom review")
+		}
+	}
+	tail = append(tail, "review")
+	if cfg.FinalizeEnabled {
+		tail = append(tail, "finalize")
+	}
+
+	start := processor.StartPhase(o.StartPhase)
+	switch {
+	case mode == processor.ModePlan:
+		return []string{"plan creation"}
+	case mode == processor.ModeTasksOnly:
+		return []string{"tasks"}
+	case mode == processor.ModeExternalOnly || (mode == processor.ModeFull && start == processor.StartCodex):
+		return tail
+	case mode == processor.ModeReview || (mode == processor.ModeFull && start == processor.StartReview):
+		return append([]string{"review"}, tail...)
+	case start == processor.StartFinalize:
+		return []string{"finalize"}
+	case cfg.ReviewPerTask && cfg.SkipFinalReview:
+		return append([]string{"tasks with per-task review"}, tail...)
+	case cfg.ReviewPerTask:
+		return append([]string{"tasks with per-task review", "review"}, tail...)
+	default:
+		return append([]string{"tasks", "review"}, tail...)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/processor"
+)
+
+func TestExplainPhases(t *testing.T) {
+	tests := []struct {
+		name string
+		o    opts
+		mode processor.Mode
+		cfg  config.Config
+		want []string
+	}{
+		{name: "full with codex and finalize", mode: processor.ModeFull,
+			cfg:  config.Config{CodexEnabled: true, FinalizeEnabled: true},
+			want: []string{"tasks", "review", "codex", "review", "finalize"}},
+		{name: "full without external review", mode: processor.ModeFull,
+			cfg: config.Config{CodexEnabled: true, ExternalReviewTool: "none"}, want: []string{"tasks", "review", "review"}},
+		{name: "full with custom tool", mode: processor.ModeFull,
+			cfg:  config.Config{CodexEnabled: true, ExternalReviewTool: "custom"},
+			want: []string{"tasks", "review", "custom review", "review"}},
+		{name: "per-task review", mode: processor.ModeFull, cfg: config.Config{ReviewPerTask: true},
+			want: []string{"tasks with per-task review", "review", "review"}},
+		{name: "per-task review without final review", mode: processor.ModeFull,
+			cfg: config.Config{ReviewPerTask: true, SkipFinalReview: true}, want: []string{"tasks with per-task review", "review"}},
+		{name: "start at codex", o: opts{StartPhase: "codex"}, mode: processor.ModeFull,
+			cfg: config.Config{CodexEnabled: true}, want: []string{"codex", "review"}},
+		{name: "start at finalize", o: opts{StartPhase: "finalize"}, mode: processor.ModeFull,
+			cfg: config.Config{FinalizeEnabled: true}, want: []string{"finalize"}},
+		{name: "review only", mode: processor.ModeReview, cfg: config.Config{}, want: []string{"review", "review"}},
+		{name: "external only forces codex", mode: processor.ModeExternalOnly, cfg: config.Config{}, want: []string{"codex", "review"}},
+		{name: "tasks only", mode: processor.ModeTasksOnly, cfg: config.Config{CodexEnabled: true}, want: []string{"tasks"}},
+		{name: "plan creation", mode: processor.ModePlan, cfg: config.Config{}, want: []string{"plan creation"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, explainPhases(tc.o, tc.mode, &tc.cfg))
+		})
+	}
+}
+
+func TestExplainRun(t *testing.T) {
+	dir := setupTestRepo(t)
+	t.Chdir(dir)
+	gitSvc, err := git.NewService(dir, noopLogger())
+	require.NoError(t, err)
+
+	t.Run("full mode on default branch", func(t *testing.T) {
+		var buf bytes.Buffer
+		req := executePlanRequest{PlanFile: "docs/plans/add-auth.md", Mode: processor.ModeFull, GitSvc: gitSvc,
+			Config: &config.Config{CodexEnabled: true}, DefaultBranch: "master", BaseRef: "master"}
+		require.NoError(t, explainRun(&buf, opts{MaxIterations: 20}, req))
+
+		assert.Equal(t, "mode: full\nplan: docs/plans/add-auth.md\nbranch: add-auth (created from master)\nworktree: no\n"+
+			"phases: tasks -> review -> codex -> review\nmax iterations: 20\nbase ref: master\n", buf.String())
+	})
+
+	t.Run("worktree with branch name override", func(t *testing.T) {
+		var buf bytes.Buffer
+		req := executePlanRequest{PlanFile: "docs/plans/add-auth.md", Mode: processor.ModeTasksOnly, GitSvc: gitSvc,
+			Config: &config.Config{WorktreeEnabled: true}, DefaultBranch: "master", BaseRef: "master"}
+		require.NoError(t, explainRun(&buf, opts{BranchName: "feat/auth"}, req))
+
+		assert.Contains(t, buf.String(), "branch: feat/auth (in a new worktree from master)\n")
+		assert.Contains(t, buf.String(), "worktree: yes\n")
+		assert.Contains(t, buf.String(), "max iterations: 50\n")
+	})
+
+	t.Run("review mode keeps current branch", func(t *testing.T) {
+		var buf bytes.Buffer
+		req := executePlanRequest{Mode: processor.ModeReview, GitSvc: gitSvc,
+			Config: &config.Config{}, DefaultBranch: "master", BaseRef: "abc1234"}
+		require.NoError(t, explainRun(&buf, opts{}, req))
+
+		assert.Contains(t, buf.String(), "branch: master (current, no branch is created)\n")
+		assert.Contains(t, buf.String(), "base ref: abc1234\n")
+		assert.NotContains(t, buf.String(), "plan:")
+	})
+
+	t.Run("already on feature branch", func(t *testing.T) {
+		runGit(t, dir, "checkout", "-b", "existing")
+		t.Cleanup(func() { runGit(t, dir, "checkout", "master") })
+		var buf bytes.Buffer
+		req := executePlanRequest{PlanFile: "docs/plans/add-auth.md", Mode: processor.ModeFull, GitSvc: gitSvc,
+			Config: &config.Config{}, DefaultBranch: "master", BaseRef: "master"}
+		require.NoError(t, explainRun(&buf, opts{}, req))
+
+		assert.Contains(t, buf.String(), "branch: existing (current, already on a feature branch)\n")
+	})
+}
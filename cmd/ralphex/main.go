@@ -3,6 +3,9 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -10,16 +13,22 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"regexp"
 	"runtime/debug"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/jessevdk/go-flags"
+	"golang.org/x/term"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
 	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/hooks"
 	"github.com/umputun/ralphex/pkg/input"
 	"github.com/umputun/ralphex/pkg/notify"
 	"github.com/umputun/ralphex/pkg/plan"
@@ -34,32 +43,86 @@ type opts struct {
 	MaxIterations         int           `short:"m" long:"max-iterations" description:"maximum task iterations (default: 50)"`
 	MaxExternalIterations int           `long:"max-external-iterations" default:"0" description:"override external review iteration limit (0 = auto)"`
 	ReviewPatience        int           `long:"review-patience" default:"0" description:"terminate external review after N unchanged rounds (0 = disabled)"`
+	MaxDiffLines          int           `long:"max-diff-lines" default:"0" description:"refuse review phases on a diff with more added+deleted lines (0 = no limit)"`
+	InteractiveGates      bool          `long:"interactive-gates" description:"ask before proceeding to each next phase (disabled with --yes or without a terminal)"`
 	Review                bool          `short:"r" long:"review" description:"skip task execution, run full review pipeline"`
 	ExternalOnly          bool          `short:"e" long:"external-only" description:"skip tasks and first review, run only external review loop"`
 	CodexOnly             bool          `short:"c" long:"codex-only" description:"alias for --external-only (deprecated)"`
 	TasksOnly             bool          `short:"t" long:"tasks-only" description:"run only task phase, skip all reviews"`
+	StartPhase            string        `long:"start-phase" description:"start full mode at phase: tasks, review, codex or finalize"`
 	BaseRef               string        `short:"b" long:"base-ref" description:"override default branch for review diffs (branch name or commit hash)"`
+	SinceTag              bool          `long:"since-tag" description:"review changes since the latest ralphex completion tag (falls back to default branch)"`
 	Wait                  time.Duration `long:"wait" description:"wait duration on rate limit before retry (e.g. 1h, 30m)"`
 	SessionTimeout        time.Duration `long:"session-timeout" description:"per-session timeout for claude (e.g. 30m, 1h)"`
+	RetryFailedReview     int           `long:"retry-failed-review" description:"retry a failed claude review iteration up to N times (overrides review_retry_count)"`
 	SkipFinalize          bool          `long:"skip-finalize" description:"skip finalize step even if enabled in config"`
+	NoFinalizeCommit      bool          `long:"no-finalize-commit" description:"leave finalize changes staged but uncommitted for manual review"`
 	Worktree              bool          `long:"worktree" description:"run in isolated git worktree"`
+	KeepWorktree          bool          `long:"keep-worktree" description:"keep the worktree when the run fails, for inspection"`
+	Autostash             bool          `long:"autostash" description:"stash uncommitted changes before branch/worktree setup and restore them afterwards"`
+	BranchName            string        `long:"branch-name" description:"override feature branch name derived from plan file"`
+	CodexConfig           []string      `long:"codex-config" description:"extra codex config override as key=value (repeatable)"`
+	Force                 bool          `long:"force" description:"allow --codex-config to override settings managed by ralphex"`
+	Yes                   bool          `short:"y" long:"yes" description:"don't ask for confirmation (uncommitted changes preflight, --reset-to, --max-diff-lines, --interactive-gates, NEEDS_INPUT questions, reopening completed plans)"`
+	ResetTo               string        `long:"reset-to" description:"reset the current feature branch to the given commit, discarding later work"`
+	IterationsReport      bool          `long:"iterations-report" description:"print a per-iteration summary table when the run ends"`
+	DetailedStats         bool          `long:"detailed-stats" description:"print per-file change stats when the run completes"`
 	PlanDescription       string        `long:"plan" description:"create plan interactively (enter plan description)"`
+	PlanModeModel         string        `long:"plan-mode-model" description:"claude model for plan creation only (overrides plan_model)"`
+	InlinePlan            string        `long:"inline-plan" description:"run a plan given as markdown text, saved to the plans dir first"`
+	Recent                int           `long:"recent" optional:"yes" optional-value:"5" description:"select only among the N most recently modified plans (--recent=1 picks the latest)"`
 	Debug                 bool          `short:"d" long:"debug" description:"enable debug logging"`
+	StreamRaw             bool          `long:"stream-raw" description:"show codex output unfiltered (verbose, for debugging)"`
 	NoColor               bool          `long:"no-color" description:"disable color output"`
 	Version               bool          `short:"v" long:"version" description:"print version and exit"`
 	Serve                 bool          `short:"s" long:"serve" description:"start web dashboard for real-time streaming"`
 	Port                  int           `short:"p" long:"port" default:"8080" description:"web dashboard port"`
 	Host                  string        `long:"host" default:"127.0.0.1" env:"RALPHEX_WEB_HOST" description:"web dashboard listen address"`
 	Watch                 []string      `short:"w" long:"watch" description:"directories to watch for progress files (repeatable)"`
+	Replay                string        `long:"replay" description:"replay a recorded progress file in the web dashboard"`
+	ReplayRealtime        bool          `long:"replay-realtime" description:"replay with original timing from progress file timestamps"`
+	ServeCheck            bool          `long:"serve-check" description:"start the web dashboard on an ephemeral port, check its endpoints respond, then exit"`
 	Reset                 bool          `long:"reset" description:"interactively reset global config to embedded defaults"`
 	DumpDefaults          string        `long:"dump-defaults" description:"extract raw embedded defaults to specified directory"`
+	Only                  string        `long:"only" description:"with --dump-defaults, extract only the named default (- as directory prints it)"`
+	ListDefaults          bool          `long:"list-defaults" description:"print the names of embedded defaults accepted by --only and exit"`
+	DumpSchema            bool          `long:"dump-schema" description:"print JSON schema of the config and exit"`
+	NotifyTest            bool          `long:"notify-test" description:"send a test notification to all configured channels and exit"`
+	Explain               bool          `long:"explain" description:"print what the run would do (mode, branch, phases) and exit"`
+	Estimate              bool          `long:"estimate" description:"print a rough iteration, token and time estimate for the run and exit"`
+	ListAgents            bool          `long:"list-agents" description:"print configured agents and active review agents, then exit"`
+	ListModes             string        `long:"list-modes" optional:"yes" optional-value:"text" description:"print execution modes, signal markers and effective config values, then exit (--list-modes=json for JSON)"`
 	ConfigDir             string        `long:"config-dir" env:"RALPHEX_CONFIG_DIR" description:"custom config directory"`
+	ConfigFile            string        `long:"config" description:"config file layered over the global and local config"`
+	Profile               string        `long:"profile" description:"apply a named config profile, a [profile <name>] section of the config"`
+	PlanSummary           string        `long:"plan-summary" hidden:"true" description:"print parsed plan summary and exit (used by fzf preview)"`
 
 	PlanFile string `positional-arg-name:"plan-file" description:"path to plan file (optional, uses fzf if omitted)"`
 }
 
 var revision = "unknown"
 
+// tagSlugRe matches runs of characters not allowed in completion tag slugs.
+var tagSlugRe = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// completionTagPrefix starts every completion tag created with tag_on_complete, --since-tag looks for it.
+const completionTagPrefix = "ralphex/"
+
+// maxPreflightFiles limits the number of files listed in the preflight uncommitted changes summary.
+const maxPreflightFiles = 20
+
+// inlinePlanSlugRe matches runs of characters not allowed in inline plan file names.
+var inlinePlanSlugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// maxInlinePlanName limits the length of the file name derived from an inline plan title.
+const maxInlinePlanName = 50
+
+// makeTestTargetRe matches a "test" target definition in a Makefile.
+var makeTestTargetRe = regexp.MustCompile(`(?m)^test\s*:`)
+
+// npmDefaultTestScript is the placeholder test script written by "npm init".
+const npmDefaultTestScript = `echo "Error: no test specified" && exit 1`
+
 // resolveVersion returns the best available version string.
 // priority: ldflags revision → module version from go install → VCS commit hash → "unknown".
 func resolveVersion() string {
@@ -96,9 +159,18 @@ type startupInfo struct {
 	PlanFile        string
 	PlanDescription string // used for plan mode instead of PlanFile
 	Branch          string
+	Divergence      string // branch position against the base ref, e.g. "3 ahead, 0 behind master"
+	RebaseHint      string // suggestion to rebase before review, empty when not needed
 	Mode            processor.Mode
 	MaxIterations   int
 	ProgressPath    string
+	RunID           string               // run identifier, empty if not assigned
+	Tools           string               // claude/codex versions, e.g. "claude 2.1.3, codex 0.46.0"
+	PhaseNames      status.PhaseNames    // custom phase labels, listed when configured
+	TestCommand     string               // resolved test command, empty if none
+	TestSource      string               // where the test command came from, e.g. "config" or "go.mod"
+	StartPhase      processor.StartPhase // full mode entry point when not starting with tasks
+	Model           string               // claude model for plan mode, empty if the default is used
 }
 
 // executePlanRequest holds parameters for plan execution.
@@ -113,9 +185,15 @@ type executePlanRequest struct {
 	DefaultBranch string // actual default branch for branch/worktree creation (config or auto-detect)
 	BaseRef       string // base reference for review diffs and templates (--base-ref override or DefaultBranch)
 	NotifySvc     *notify.Service
-	WtCleanup     *worktreeCleanupFn  // worktree cleanup for interrupt handler; nil when not in worktree mode
-	ProgressLog   *progress.Logger    // pre-created logger (worktree mode); nil in normal mode
-	PhaseHolder   *status.PhaseHolder // pre-created holder (worktree mode); nil in normal mode
+	WtCleanup     *worktreeCleanupFn    // worktree cleanup for interrupt handler; nil when not in worktree mode
+	ProgressLog   *progress.Logger      // pre-created logger (worktree mode); nil in normal mode
+	PhaseHolder   *status.PhaseHolder   // pre-created holder (worktree mode); nil in normal mode
+	TreeStatus    string                // uncommitted changes summary from preflight, shown on the dashboard
+	TestCommand   string                // test command from config or detected from repo markers
+	TestResult    *processor.TestResult // outcome of the test run after the work, nil when tests were not run
+	DashboardURL  string                // web dashboard link included in notifications, empty without --serve
+	RunID         string                // run identifier, written to the progress header, dashboard title and notifications
+	ToolVersions  toolVersions          // claude and codex versions detected at startup, recorded in the progress header
 }
 
 // worktreeCleanupFn holds a worktree cleanup function with mutex for safe cross-goroutine access.
@@ -141,7 +219,9 @@ func (c *worktreeCleanupFn) call() {
 }
 
 func main() {
-	if os.Getenv("GO_FLAGS_COMPLETION") == "" {
+	// the banner goes out before flags are parsed, so help and parse errors show it too.
+	// --list-modes=json skips it to keep stdout valid JSON, optional flag values always come with "="
+	if os.Getenv("GO_FLAGS_COMPLETION") == "" && !slices.Contains(os.Args[1:], "--list-modes=json") {
 		fmt.Printf("ralphex %s\n", resolveVersion())
 	}
 
@@ -179,7 +259,7 @@ func main() {
 
 func run(ctx context.Context, o opts) error {
 	// suppress ^C echo in terminal before setting up interrupt watcher
-	restoreTerminal := disableCtrlCEcho()
+	restoreTerminal := disableCtrlCEcho(o.Debug)
 	defer restoreTerminal()
 
 	// worktree cleanup function, populated after worktree creation.
@@ -193,6 +273,9 @@ func run(ctx context.Context, o opts) error {
 		wtCleanup.call()
 	})()
 
+	// past this point only ExternalOnly is checked, the warning waits for the config
+	o, codexOnlyAlias := foldCodexOnly(o)
+
 	// validate conflicting flags
 	if err := validateFlags(o); err != nil {
 		return err
@@ -204,30 +287,83 @@ func run(ctx context.Context, o opts) error {
 	}
 
 	// load config first to get custom command paths
-	cfg, err := config.Load(o.ConfigDir)
+	cfg, err := config.LoadWithFile(o.ConfigDir, o.ConfigFile)
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
+	// the profile is layered over the config files, command line flags still override it
+	if o.Profile != "" {
+		if cfg, err = cfg.ApplyProfile(o.Profile); err != nil {
+			return fmt.Errorf("apply profile: %w", err)
+		}
+	}
+	// plans parsed from here on, dashboard included, recognize the configured in-progress checkbox mark
+	if err := plan.SetInProgressMarker(cfg.InProgressMarker); err != nil {
+		return fmt.Errorf("in_progress_marker: %w", err)
+	}
+	// check the push ref template now rather than after the run, any branch name stands in
+	if cfg.PushRefspecTemplate != "" {
+		if _, err := git.PushRefspec(cfg.PushRefspecTemplate, "branch"); err != nil {
+			return fmt.Errorf("push_refspec_template: %w", err)
+		}
+	}
 
 	// create colors from config (all colors guaranteed populated via fallback)
 	colors := progress.NewColors(cfg.Colors)
 
+	if codexOnlyAlias && !cfg.SuppressDeprecationWarnings {
+		fmt.Fprintln(os.Stderr, "warning: --codex-only is deprecated, use --external-only instead")
+	}
+
+	// serve-check mode: verify the dashboard starts and serves its pages and exit, needs no git repo
+	if o.ServeCheck {
+		return runServeCheck(ctx, o, cfg, os.Stdout)
+	}
+
+	// replay mode: re-render a recorded progress file in the dashboard, read-only and needs no git repo
+	if o.Replay != "" {
+		return runReplay(ctx, o, cfg, colors)
+	}
+
 	// create notification service (nil if no channels configured)
 	notifySvc, err := notify.New(cfg.NotifyParams, stderrLog{})
 	if err != nil {
 		return fmt.Errorf("create notification service: %w", err)
 	}
 
+	// list-agents mode: print configured agents and exit, needs no git repo
+	if o.ListAgents {
+		listAgents(os.Stdout, cfg)
+		return nil
+	}
+
+	// list-modes mode: print modes, signals and effective config for scripting and exit, needs no git repo
+	if o.ListModes != "" {
+		return listModes(os.Stdout, cfg, o.ListModes)
+	}
+
+	// notify-test mode: verify notification wiring and exit, needs no git repo
+	if o.NotifyTest {
+		return runNotifyTest(ctx, notifySvc, cfg.NotifyParams.Channels, os.Stdout)
+	}
+
 	// watch-only mode: --serve with watch dirs (CLI or config) and no plan file
 	// runs web dashboard without plan execution, can run from any directory
 	if isWatchOnlyMode(o, cfg.WatchDirs) {
 		return runWatchOnly(ctx, o, cfg, colors)
 	}
 
+	// reset-to mode: rewind the current feature branch and exit, needs only the git repo
+	if o.ResetTo != "" {
+		return runResetTo(ctx, o, cfg, colors)
+	}
+
 	// check dependencies using configured command (or default "claude")
 	if depErr := checkClaudeDep(cfg); depErr != nil {
 		return depErr
 	}
+	// record the CLI versions, behavior changes after an upgrade are otherwise hard to trace
+	versions := detectToolVersions(ctx, cfg)
 
 	// require running from repo root.
 	// when using a non-git vcs command, skip the .git check — rely on NewService's
@@ -243,10 +379,25 @@ func run(ctx context.Context, o opts) error {
 	if err != nil {
 		return fmt.Errorf("open git repo: %w", err)
 	}
+	gitSvc.SetBranchNameRules(branchNameRules(cfg))
+	gitSvc.SetCommitTrailer(commitTrailer(cfg))
+
+	mode, err := determineMode(o, processor.Mode(cfg.DefaultMode))
+	if err != nil {
+		return err
+	}
+
+	// repo repairs below prompt and change the repo, --explain and --estimate only describe the run
+	if !o.Explain && !o.Estimate {
+		// ensure repository has commits (prompts to create initial commit if empty)
+		if ensureErr := ensureRepoHasCommits(ctx, gitSvc, os.Stdin, os.Stdout); ensureErr != nil {
+			return ensureErr
+		}
 
-	// ensure repository has commits (prompts to create initial commit if empty)
-	if ensureErr := ensureRepoHasCommits(ctx, gitSvc, os.Stdin, os.Stdout); ensureErr != nil {
-		return ensureErr
+		// interrupted rebase/merge/cherry-pick (e.g., from a killed finalize step) blocks branch operations
+		if abortErr := abortInProgressOperation(ctx, gitSvc, mode, os.Stdin, os.Stdout); abortErr != nil {
+			return abortErr
+		}
 	}
 
 	autoDetected := gitSvc.GetDefaultBranch()
@@ -254,17 +405,42 @@ func run(ctx context.Context, o opts) error {
 	defaultBranch := resolveDefaultBranch("", cfg.DefaultBranch, autoDetected)
 	// baseRef is for review diffs and {{DEFAULT_BRANCH}} template variable (--base-ref override)
 	baseRef := resolveDefaultBranch(o.BaseRef, cfg.DefaultBranch, autoDetected)
+	if o.SinceTag {
+		baseRef = resolveSinceTag(gitSvc, baseRef, colors, os.Stdout)
+	}
 	applyCLIOverrides(o, cfg)
 
-	mode := determineMode(o)
+	// shallow clones miss the base history, unshallowing changes the repo so --explain and --estimate only warn
+	checkShallowClone(gitSvc, cfg.AutoUnshallow && !o.Explain && !o.Estimate, baseRef, colors, os.Stdout)
+
+	// tasks commit as they go, so there is no uncommitted working tree to review
+	if cfg.ReviewWorkingTree && modeRequiresBranch(mode) {
+		return fmt.Errorf("review_working_tree can't be used in %s mode, use --review or --external-only", mode)
+	}
+
+	// the run ID correlates the progress log, dashboard and notifications of this run
+	runID := newRunID(time.Now())
 
 	// create plan selector for use by plan selection and plan mode
 	selector := plan.NewSelector(cfg.PlansDir, colors)
+	selector.FzfCommand = cfg.FzfCommand
+	selector.FzfArgs = cfg.FzfArgs
+	selector.Recent = o.Recent
 
 	// plan mode has different flow - doesn't require plan file selection
+	if mode == processor.ModePlan && o.Explain {
+		return explainRun(os.Stdout, o, executePlanRequest{
+			Mode: mode, GitSvc: gitSvc, Config: cfg, DefaultBranch: defaultBranch, BaseRef: baseRef,
+		})
+	}
+	if mode == processor.ModePlan && o.Estimate {
+		return errors.New("--estimate needs a plan to run, plan creation mode has nothing to estimate")
+	}
 	if mode == processor.ModePlan {
 		return runPlanMode(ctx, o, executePlanRequest{
 			Mode:          processor.ModePlan,
+			RunID:         runID,
+			ToolVersions:  versions,
 			GitSvc:        gitSvc,
 			Config:        cfg,
 			Colors:        colors,
@@ -275,8 +451,20 @@ func run(ctx context.Context, o opts) error {
 		}, selector)
 	}
 
+	// inline plan is saved as a regular plan file, so branch naming and the move to completed/ work as usual
+	if o.InlinePlan != "" {
+		planFile, inlineErr := writeInlinePlan(o.InlinePlan, cfg.PlansDir, time.Now())
+		if inlineErr != nil {
+			return fmt.Errorf("inline plan: %w", inlineErr)
+		}
+		colors.Info().Printf("inline plan saved to %s\n", toRelPath(planFile))
+		o.PlanFile = planFile
+	}
+
 	return selectAndExecutePlan(ctx, o, executePlanRequest{
 		Mode:          mode,
+		RunID:         runID,
+		ToolVersions:  versions,
 		GitSvc:        gitSvc,
 		Config:        cfg,
 		Colors:        colors,
@@ -287,21 +475,140 @@ func run(ctx context.Context, o opts) error {
 	}, selector)
 }
 
+// writeInlinePlan saves --inline-plan content as a plan file in plansDir and returns its path.
+// literal "\n" sequences are expanded when the content has no real newlines, as typed in a shell argument.
+// the file name comes from the plan title, a timestamp is appended if that name is already taken.
+func writeInlinePlan(content, plansDir string, now time.Time) (string, error) {
+	if !strings.Contains(content, "\n") {
+		content = strings.ReplaceAll(content, `\n`, "\n")
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", errors.New("plan content is empty")
+	}
+
+	p, err := plan.ParsePlan(content)
+	if err != nil {
+		return "", fmt.Errorf("parse plan: %w", err)
+	}
+	name := inlinePlanSlugRe.ReplaceAllString(strings.ToLower(p.Title), "-")
+	name = strings.Trim(name[:min(len(name), maxInlinePlanName)], "-")
+	if name == "" {
+		name = "inline-plan"
+	}
+
+	if err := os.MkdirAll(plansDir, 0o750); err != nil {
+		return "", fmt.Errorf("create plans dir: %w", err)
+	}
+	path := filepath.Join(plansDir, name+".md")
+	if _, statErr := os.Stat(path); statErr == nil {
+		path = filepath.Join(plansDir, name+"-"+now.Format("20060102-150405")+".md")
+	}
+	if err := os.WriteFile(path, []byte(content+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("write plan file: %w", err)
+	}
+	return path, nil
+}
+
 // selectAndExecutePlan selects a plan file, sets up branch or worktree, and runs execution.
 func selectAndExecutePlan(ctx context.Context, o opts, req executePlanRequest, selector *plan.Selector) error {
-	// plan is optional only for review modes (ModeReview, ModeCodexOnly)
-	planOptional := req.Mode == processor.ModeReview || req.Mode == processor.ModeCodexOnly
+	// plan is optional only for review modes (ModeReview, ModeExternalOnly)
+	planOptional := req.Mode == processor.ModeReview || req.Mode == processor.ModeExternalOnly
 	planFile, err := selector.Select(ctx, o.PlanFile, planOptional)
 	if err != nil {
 		// check for auto-plan-mode: no plans found on default branch
-		handled, autoPlanErr := tryAutoPlanMode(ctx, err, o, req, selector)
-		if handled {
-			return autoPlanErr
+		if !o.Explain && !o.Estimate {
+			if handled, autoPlanErr := tryAutoPlanMode(ctx, err, o, req, selector); handled {
+				return autoPlanErr
+			}
 		}
 		return fmt.Errorf("select plan: %w", err)
 	}
+	if planFile != "" && !req.Config.AllowExternalPlans && !selector.InPlansDir(planFile) {
+		return fmt.Errorf("plan %s is outside plans directory %s, move it there or set allow_external_plans = true",
+			planFile, selector.PlansDir)
+	}
 
 	req.PlanFile = planFile
+	if o.Explain {
+		return explainRun(os.Stdout, o, req)
+	}
+	if o.Estimate {
+		return estimateRun(os.Stdout, o, req)
+	}
+	// a completed plan is only confirmed here, it moves back out of completed/ on its feature branch
+	// once the lock, preflight and branch or worktree setup are done
+	reopen := isCompletedPlan(planFile)
+	if reopen {
+		interactive := term.IsTerminal(int(os.Stdin.Fd()))
+		confirmed, confirmErr := confirmReopenPlan(ctx, o, planFile, os.Stdin, os.Stdout, interactive)
+		if confirmErr != nil {
+			return confirmErr
+		}
+		if !confirmed {
+			if ctx.Err() != nil {
+				return fmt.Errorf("reopen plan: %w", ctx.Err())
+			}
+			req.Colors.Info().Printf("canceled, the plan stays in completed/\n")
+			return nil
+		}
+	}
+	if planFile != "" {
+		oversized := printPlanWarnings(planFile, req.Config.PlanMaxTasks, req.Colors, os.Stdout)
+		interactive := term.IsTerminal(int(os.Stdin.Fd()))
+		if oversized && interactive && !o.Yes && !input.AskYesNo(ctx, "run this plan anyway?", os.Stdin, os.Stdout) {
+			if ctx.Err() != nil {
+				return fmt.Errorf("plan size check: %w", ctx.Err())
+			}
+			req.Colors.Info().Printf("canceled, split the plan into smaller ones and run again\n")
+			return nil
+		}
+	}
+
+	// lock before branch, stash or worktree setup
+	release, lockErr := acquireLockForRun(req, planFile)
+	if lockErr != nil {
+		return lockErr
+	}
+	defer release()
+
+	// autostash: move unrelated uncommitted changes out of the way before branch or worktree setup.
+	// normal mode restores them right after branch creation, worktree mode after the run;
+	// the deferred restore covers early returns.
+	var stashed bool
+	restoreStash := func() {
+		if stashed {
+			stashed = false
+			restoreAutostash(req.GitSvc)
+		}
+	}
+	defer restoreStash()
+	if o.Autostash && planFile != "" && modeRequiresBranch(req.Mode) {
+		if stashed, err = req.GitSvc.StashPush("ralphex autostash: "+filepath.Base(planFile), planFile); err != nil {
+			return fmt.Errorf("autostash: %w", err)
+		}
+	}
+
+	// preflight: show uncommitted changes before they get carried onto the feature branch or worktree
+	if planFile != "" && modeRequiresBranch(req.Mode) {
+		interactive := term.IsTerminal(int(os.Stdin.Fd()))
+		summary, proceed := preflightTreeStatus(ctx, o, req.GitSvc, req.Colors, os.Stdin, os.Stdout, interactive)
+		if !proceed {
+			if ctx.Err() != nil {
+				return fmt.Errorf("preflight: %w", ctx.Err())
+			}
+			req.Colors.Info().Printf("canceled, commit or stash the changes and run again\n")
+			return nil
+		}
+		req.TreeStatus = summary
+	}
+
+	// the branch of a reopened plan usually exists from the earlier run and is merged already
+	if reopen && modeRequiresBranch(req.Mode) {
+		if err := req.GitSvc.RecreatePlanBranch(planFile, req.DefaultBranch, o.BranchName); err != nil {
+			return fmt.Errorf("recreate branch for plan: %w", err)
+		}
+	}
 
 	// worktree mode: create worktree, chdir into it, run execution from there.
 	// EnsureIgnored is called inside runWithWorktree after worktree creation
@@ -314,8 +621,15 @@ func selectAndExecutePlan(ctx context.Context, o opts, req executePlanRequest, s
 	// EnsureIgnored must be called AFTER CreateBranchForPlan because it modifies
 	// .gitignore, and CreateBranchForPlan checks HasChangesOtherThan(planFile).
 	if planFile != "" && modeRequiresBranch(req.Mode) {
-		if err := req.GitSvc.CreateBranchForPlan(planFile, req.DefaultBranch); err != nil {
-			return fmt.Errorf("create branch for plan: %w", err)
+		branchErr := req.GitSvc.CreateBranchForPlan(planFile, req.DefaultBranch, o.BranchName)
+		restoreStash() // before EnsureIgnored, which may edit a stashed .gitignore
+		if branchErr != nil {
+			return fmt.Errorf("create branch for plan: %w", branchErr)
+		}
+	}
+	if reopen {
+		if req.PlanFile, err = reopenPlan(req.GitSvc, req.Colors, planFile); err != nil {
+			return err
 		}
 	}
 	if err := req.GitSvc.EnsureIgnored(".ralphex/progress/", ".ralphex/progress/progress-test.txt"); err != nil {
@@ -325,6 +639,14 @@ func selectAndExecutePlan(ctx context.Context, o opts, req executePlanRequest, s
 	return executePlan(ctx, o, req)
 }
 
+// restoreAutostash pops changes stashed by --autostash. failures are warnings: the run itself is not affected
+// and git keeps the stash entry on conflict, so nothing is lost.
+func restoreAutostash(gitSvc *git.Service) {
+	if err := gitSvc.StashPop(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to restore autostashed changes: %v\n", err)
+	}
+}
+
 // getCurrentBranch returns the current git branch name or "unknown" if unavailable.
 func getCurrentBranch(gitSvc *git.Service) string {
 	branch, err := gitSvc.CurrentBranch()
@@ -338,7 +660,7 @@ func getCurrentBranch(gitSvc *git.Service) string {
 // returns (true, nil) if user canceled, (true, err) if plan mode was attempted, or (false, nil) if auto-plan-mode doesn't apply.
 func tryAutoPlanMode(ctx context.Context, err error, o opts, req executePlanRequest,
 	selector *plan.Selector) (bool, error) {
-	if !errors.Is(err, plan.ErrNoPlansFound) || o.Review || o.ExternalOnly || o.CodexOnly || o.TasksOnly {
+	if !errors.Is(err, plan.ErrNoPlansFound) || req.Mode != processor.ModeFull {
 		return false, nil
 	}
 
@@ -380,10 +702,14 @@ func setupProgressLogger(o opts, req executePlanRequest, branch string) (progres
 	} else {
 		var err error
 		baseLog, err = progress.NewLogger(progress.Config{
-			PlanFile: req.PlanFile,
-			Mode:     string(req.Mode),
-			Branch:   branch,
-			NoColor:  o.NoColor,
+			PlanFile:    req.PlanFile,
+			Mode:        string(req.Mode),
+			Branch:      branch,
+			RunID:       req.RunID,
+			Tools:       req.ToolVersions.String(),
+			NoColor:     o.NoColor,
+			PhaseNames:  req.Config.PhaseNames,
+			SplitByTask: req.Config.SplitProgressByTask,
 		}, req.Colors, holder)
 		if err != nil {
 			return progressLogResult{}, fmt.Errorf("create progress logger: %w", err)
@@ -399,6 +725,24 @@ func setupProgressLogger(o opts, req executePlanRequest, branch string) (progres
 	return progressLogResult{holder: holder, baseLog: baseLog, closeLog: closeLog}, nil
 }
 
+// archiveProgress commits the progress file of a run, with its task segments, to the progress_branch branch.
+// it runs after the log is closed, so the completion footer is included. no-op when progress_branch is not set,
+// failures are warnings since the run itself is already over.
+func archiveProgress(cfg *config.Config, gitSvc *git.Service, progressPath string) {
+	if cfg.ProgressBranch == "" || gitSvc == nil || progressPath == "" {
+		return
+	}
+	absPath, err := filepath.Abs(progressPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to resolve progress path: %v\n", err)
+		return
+	}
+	files := append([]string{absPath}, progress.TaskSegmentPaths(absPath)...)
+	if err := gitSvc.CommitToOrphanBranch(cfg.ProgressBranch, files...); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to commit progress to branch %s: %v\n", cfg.ProgressBranch, err)
+	}
+}
+
 // sendNotification sends a completion or failure notification.
 // uses context.Background() because the parent ctx may be canceled (e.g. SIGINT),
 // and the notification timeout is applied inside Send() independently.
@@ -409,10 +753,12 @@ func sendNotification(req executePlanRequest, branch, elapsed string, stats git.
 // buildNotifyResult constructs a notify.Result from execution parameters.
 func buildNotifyResult(req executePlanRequest, branch, elapsed string, stats git.DiffStats, runErr error) notify.Result {
 	result := notify.Result{
-		Mode:     string(req.Mode),
-		PlanFile: req.PlanFile,
-		Branch:   branch,
-		Duration: elapsed,
+		Mode:         string(req.Mode),
+		PlanFile:     req.PlanFile,
+		Branch:       branch,
+		Duration:     elapsed,
+		RunID:        req.RunID,
+		DashboardURL: req.DashboardURL,
 	}
 	if runErr != nil {
 		result.Status = "failure"
@@ -423,15 +769,63 @@ func buildNotifyResult(req executePlanRequest, branch, elapsed string, stats git
 		result.Additions = stats.Additions
 		result.Deletions = stats.Deletions
 	}
+	if req.TestResult != nil {
+		result.TestsRun = true
+		passed := req.TestResult.Passed
+		result.TestsPassed = &passed
+		result.Coverage = req.TestResult.Coverage
+	}
 	return result
 }
 
+// completionDiffStats returns the size of the run's changes for the completion message,
+// uncommitted changes with review_working_tree, the branch diff against the base ref otherwise.
+// the exclude paths (plan file, progress dir) are left out.
+func completionDiffStats(req executePlanRequest, exclude []string) (git.DiffStats, error) {
+	if req.Config != nil && req.Config.ReviewWorkingTree {
+		stats, err := req.GitSvc.DiffStatsRange("HEAD", "", exclude...)
+		if err != nil {
+			return git.DiffStats{}, fmt.Errorf("working tree diff stats: %w", err)
+		}
+		return stats, nil
+	}
+	stats, err := req.GitSvc.DiffStats(req.BaseRef, exclude...)
+	if err != nil {
+		return git.DiffStats{}, fmt.Errorf("branch diff stats: %w", err)
+	}
+	return stats, nil
+}
+
+// statsExcludes returns the bookkeeping paths left out of the completion stats, the plan file and
+// the progress directory, so "files changed" reflects code changes. returns nil with review_include_plan.
+func statsExcludes(req executePlanRequest, progressPath string) []string {
+	if req.Config != nil && req.Config.ReviewIncludePlan {
+		return nil
+	}
+	var res []string
+	if req.PlanFile != "" {
+		res = append(res, req.PlanFile)
+	}
+	if progressPath != "" {
+		res = append(res, filepath.Dir(progressPath))
+	}
+	return res
+}
+
 // displayStats prints completion summary with optional diff statistics and paths.
-func displayStats(req executePlanRequest, baseLog *progress.Logger, stats git.DiffStats, elapsed string) {
+// files holds per-file stats (--detailed-stats), shown as a table after the summary line when not empty.
+func displayStats(req executePlanRequest, baseLog *progress.Logger, stats git.DiffStats, files []git.FileDiffStat, elapsed string) {
 	if stats.Files > 0 {
 		baseLog.LogDiffStats(stats.Files, stats.Additions, stats.Deletions)
 		req.Colors.Info().Printf("\ncompleted in %s (%d files, +%d/-%d lines)\n",
 			elapsed, stats.Files, stats.Additions, stats.Deletions)
+		fileStats := make([]progress.FileStat, 0, len(files))
+		for _, f := range files {
+			fileStats = append(fileStats, progress.FileStat{
+				Path: f.Path, OldPath: f.OldPath, Additions: f.Additions, Deletions: f.Deletions, Binary: f.Binary,
+			})
+		}
+		baseLog.LogFileStats(fileStats)
 	} else {
 		req.Colors.Info().Printf("\ncompleted in %s\n", elapsed)
 	}
@@ -448,6 +842,147 @@ func displayStats(req executePlanRequest, baseLog *progress.Logger, stats git.Di
 	req.Colors.Info().Printf("  progress: %s\n", baseLog.Path())
 }
 
+// branchDivergence describes how far the branch has diverged from the base ref, e.g. "3 ahead, 0 behind master",
+// and suggests a rebase when the base moved on and a review will run, since reviews diff against the base.
+// returns empty strings when the branch is the base itself, the base doesn't exist or the lookup fails.
+func branchDivergence(req executePlanRequest) (desc, rebaseHint string) {
+	if req.GitSvc == nil || req.BaseRef == "" {
+		return "", ""
+	}
+	ahead, behind, err := req.GitSvc.CountAheadBehind(req.BaseRef)
+	if err != nil || (ahead == 0 && behind == 0) {
+		return "", ""
+	}
+	desc = fmt.Sprintf("%d ahead, %d behind %s", ahead, behind, req.BaseRef)
+	if behind > 0 && req.Mode != processor.ModeTasksOnly {
+		rebaseHint = fmt.Sprintf("branch is %d commit(s) behind %s, consider rebasing before review", behind, req.BaseRef)
+	}
+	return desc, rebaseHint
+}
+
+// printUpstreamHint suggests the push command when the feature branch has no upstream yet,
+// so opening a PR manually is a copy-paste away. a missing push remote is reported instead.
+// lookup failures skip the hint, it is informational only.
+func printUpstreamHint(w io.Writer, req executePlanRequest, branch string) {
+	if branch == "" || branch == "unknown" {
+		return
+	}
+	if isDefault, err := req.GitSvc.IsDefaultBranch(req.DefaultBranch); err != nil || isDefault {
+		return
+	}
+	if upstream, err := req.GitSvc.CurrentUpstream(); err != nil || upstream != "" {
+		return
+	}
+	remote := pushRemote(req.Config)
+	exists, err := req.GitSvc.RemoteExists(remote)
+	if err != nil {
+		return
+	}
+	if !exists {
+		req.Colors.Info().Fprintf(w, "  push: no '%s' remote configured, add one with git remote add %s <url>\n", remote, remote)
+		return
+	}
+	req.Colors.Info().Fprintf(w, "  push: git push -u %s %s\n", remote, branch)
+}
+
+// pushCompletedBranch pushes the feature branch to push_remote under the ref named by push_refspec_template.
+// returns false without an error when no template is set or the branch is the default one.
+func pushCompletedBranch(req executePlanRequest, branch string) (bool, error) {
+	if req.Config == nil || req.Config.PushRefspecTemplate == "" || branch == "" || branch == "unknown" {
+		return false, nil
+	}
+	isDefault, err := req.GitSvc.IsDefaultBranch(req.DefaultBranch)
+	if err != nil {
+		return false, fmt.Errorf("check default branch: %w", err)
+	}
+	if isDefault {
+		return false, nil
+	}
+	remote := pushRemote(req.Config)
+	exists, err := req.GitSvc.RemoteExists(remote)
+	if err != nil {
+		return false, fmt.Errorf("check push remote: %w", err)
+	}
+	if !exists {
+		return false, fmt.Errorf("no '%s' remote configured, add one with git remote add %s <url>", remote, remote)
+	}
+	refspec, err := git.PushRefspec(req.Config.PushRefspecTemplate, branch)
+	if err != nil {
+		return false, fmt.Errorf("push_refspec_template: %w", err)
+	}
+	if err := req.GitSvc.Push(remote, refspec); err != nil {
+		return false, fmt.Errorf("push %s: %w", branch, err)
+	}
+	return true, nil
+}
+
+// pushRemote returns the remote name from push_remote config, origin by default.
+func pushRemote(cfg *config.Config) string {
+	if cfg == nil || cfg.PushRemote == "" {
+		return git.DefaultRemote
+	}
+	return cfg.PushRemote
+}
+
+// runHook runs a pre-run or post-run hook command, streaming its output to the run log.
+func runHook(ctx context.Context, name, command string, log processor.Logger) error {
+	log.Print("running %s: %s", name, command)
+	hook := &executor.HookExecutor{Command: command, OutputHandler: log.PrintAligned}
+	if err := hook.Run(ctx); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// runPostRunHook runs the post-run teardown command if configured. it runs even when the run
+// was interrupted, so the context cancellation is dropped. failures are logged as warnings.
+func runPostRunHook(ctx context.Context, command string, log processor.Logger) {
+	if command == "" {
+		return
+	}
+	if err := runHook(context.WithoutCancel(ctx), "post-run command", command, log); err != nil {
+		log.Print("warning: %v", err)
+	}
+}
+
+// newHookRunner returns a runner for lifecycle scripts in the hooks/ subdirectory of the local .ralphex/ config dir.
+// returns nil (no hooks) when no local config dir was detected.
+func newHookRunner(req executePlanRequest, branch string, log processor.Logger) *hooks.Runner {
+	localDir := req.Config.LocalDir()
+	if localDir == "" {
+		return nil
+	}
+	return &hooks.Runner{
+		Dir:     filepath.Join(localDir, "hooks"),
+		Failure: req.Config.HookFailure,
+		Env:     []string{"RALPHEX_PLAN_FILE=" + req.PlanFile, "RALPHEX_BRANCH=" + branch, "RALPHEX_MODE=" + string(req.Mode)},
+		Log:     log,
+	}
+}
+
+// runPreRunHooks runs the pre-run command and then the pre-run hook script, stopping at the first failure.
+func runPreRunHooks(ctx context.Context, command string, hookRunner *hooks.Runner, log processor.Logger) error {
+	if command != "" {
+		if err := runHook(ctx, "pre-run command", command, log); err != nil {
+			return err
+		}
+	}
+	if err := hookRunner.Run(ctx, hooks.PreRun, ""); err != nil {
+		return fmt.Errorf("hooks: %w", err)
+	}
+	return nil
+}
+
+// runPostRunHooks runs the post-run command and then the post-run hook script, both even when the run
+// was interrupted. phase is the last phase reached. returns an error only if the hook script failure is fatal.
+func runPostRunHooks(ctx context.Context, command string, hookRunner *hooks.Runner, phase string, log processor.Logger) error {
+	runPostRunHook(ctx, command, log)
+	if err := hookRunner.Run(context.WithoutCancel(ctx), hooks.PostRun, phase); err != nil {
+		return fmt.Errorf("hooks: %w", err)
+	}
+	return nil
+}
+
 // keepDashboardAlive keeps the web dashboard running after execution completes.
 // blocks until context is canceled (Ctrl+C). no-op if --serve is not enabled.
 func keepDashboardAlive(ctx context.Context, o opts, req executePlanRequest, closeLog func()) {
@@ -455,11 +990,23 @@ func keepDashboardAlive(ctx context.Context, o opts, req executePlanRequest, clo
 		return
 	}
 	closeLog()
-	req.Colors.Info().Printf("web dashboard still running at http://%s:%d (press Ctrl+C to exit)\n",
-		web.ConnectHost(o.Host), o.Port)
+	req.Colors.Info().Printf("web dashboard still running at %s (press Ctrl+C to exit)\n", dashboardURL(o))
 	<-ctx.Done()
 }
 
+// newRunID returns a short identifier for a run, the start time plus random hex, e.g. "20260115-103000-4f2a9c".
+// it stays the same for the whole run and ties together the progress log, dashboard and notifications.
+func newRunID(now time.Time) string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b) // crypto/rand.Read never returns an error
+	return now.Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
+
+// dashboardURL returns the user-facing address of the web dashboard started with --serve.
+func dashboardURL(o opts) string {
+	return fmt.Sprintf("http://%s:%d", web.ConnectHost(o.Host), o.Port)
+}
+
 // executePlan runs the main execution loop for a plan file.
 // handles progress logging, web dashboard, runner execution, and post-execution tasks.
 // when req.ProgressLog and req.PhaseHolder are pre-created (worktree mode), uses them directly.
@@ -467,12 +1014,31 @@ func keepDashboardAlive(ctx context.Context, o opts, req executePlanRequest, clo
 func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 	branch := getCurrentBranch(req.GitSvc)
 
+	if req.Mode == processor.ModeFull {
+		if err := checkStartPhase(processor.StartPhase(o.StartPhase), req); err != nil {
+			return err
+		}
+	}
+
+	// refuse to start on a nearly full disk, before the progress log is created
+	if err := checkDiskSpace(".", req.Config.MinFreeDiskMB); err != nil {
+		return err
+	}
+
 	// set up progress logger and phase holder
 	plr, err := setupProgressLogger(o, req, branch)
 	if err != nil {
 		return err
 	}
-	defer plr.closeLog()
+	defer func() {
+		plr.closeLog()
+		if req.ProgressLog == nil { // a pre-created logger is archived by its owner after closing it
+			archiveProgress(req.Config, req.GitSvc, plr.baseLog.Path())
+		}
+	}()
+
+	restoreTitle := startTerminalTitle(req.Config.TerminalTitle, o.NoColor, plr.holder, req.Config.PhaseNames, plr.baseLog.Elapsed)
+	defer restoreTitle()
 
 	// wrap logger with broadcast logger if --serve is enabled
 	var runnerLog processor.Logger = plr.baseLog
@@ -483,50 +1049,143 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 			Host:            o.Host,
 			PlanFile:        req.PlanFile,
 			Branch:          branch,
+			RunID:           req.RunID,
 			WatchDirs:       o.Watch,
 			ConfigWatchDirs: req.Config.WatchDirs,
 			Colors:          req.Colors,
+			PhaseNames:      req.Config.PhaseNames,
+			BatchInterval:   req.Config.DashboardBatchInterval,
 		}, plr.holder)
 		var dashErr error
 		runnerLog, dashErr = dashboard.Start(ctx)
 		if dashErr != nil {
 			return fmt.Errorf("start dashboard: %w", dashErr)
 		}
+		if req.TreeStatus != "" {
+			runnerLog.Print("%s", req.TreeStatus)
+		}
+		req.DashboardURL = dashboardURL(o)
 	}
 
+	// resolve test command in the working directory of the run (worktree-aware)
+	testCmd, testSource := resolveTestCommand(req.Config.TestCommand, ".")
+	req.TestCommand = testCmd
+
 	// print startup info
+	divergence, rebaseHint := branchDivergence(req)
 	printStartupInfo(startupInfo{
 		PlanFile:      req.PlanFile,
 		Branch:        branch,
+		Divergence:    divergence,
+		RebaseHint:    rebaseHint,
 		Mode:          req.Mode,
 		MaxIterations: resolveMaxIterations(o.MaxIterations, req.Config),
 		ProgressPath:  plr.baseLog.Path(),
+		RunID:         req.RunID,
+		Tools:         req.ToolVersions.String(),
+		PhaseNames:    req.Config.PhaseNames,
+		TestCommand:   testCmd,
+		TestSource:    testSource,
+		StartPhase:    processor.StartPhase(o.StartPhase),
 	}, req.Colors)
 
+	// scripts from .ralphex/hooks/ run at lifecycle points, next to the pre/post run commands
+	hookRunner := newHookRunner(req, branch, runnerLog)
+
+	// pre-run hook prepares the environment, a failure aborts the run (teardown still runs)
+	if hookErr := runPreRunHooks(ctx, req.Config.PreRunCommand, hookRunner, runnerLog); hookErr != nil {
+		if postErr := runPostRunHooks(ctx, req.Config.PostRunCommand, hookRunner, "", runnerLog); postErr != nil {
+			runnerLog.Print("warning: %v", postErr)
+		}
+		sendNotification(req, branch, plr.baseLog.Elapsed(), git.DiffStats{}, hookErr)
+		return hookErr
+	}
+
 	// create and run the runner
 	r := createRunner(req, o, runnerLog, plr.holder)
+	r.SetHooks(hookRunner)
+	// an oversized diff (--max-diff-lines) asks for confirmation in a terminal, otherwise it aborts.
+	// phase gates (--interactive-gates) only ask in a terminal too, with --yes they are skipped.
+	if (o.MaxDiffLines > 0 || o.InteractiveGates) && !o.Yes && term.IsTerminal(int(os.Stdin.Fd())) {
+		r.SetConfirm(func(ctx context.Context, question string) bool {
+			return input.AskYesNo(ctx, question, os.Stdin, os.Stdout)
+		})
+	} else if o.InteractiveGates {
+		runnerLog.Print("--interactive-gates ignored, no terminal or --yes set")
+	}
+	// a NEEDS_INPUT question from claude is asked in a terminal, otherwise it fails the run
+	if !o.Yes && term.IsTerminal(int(os.Stdin.Fd())) {
+		r.SetInputCollector(input.NewTerminalCollector(o.NoColor))
+	}
 
 	// listen for SIGQUIT (Ctrl+\) for manual external review loop termination
 	if breakCh := startBreakSignal(); breakCh != nil {
 		r.SetBreakCh(breakCh)
 	}
 
-	if runErr := r.Run(ctx); runErr != nil {
+	// the disk space watch cancels only the runner, post-run hooks and cleanup still get the parent context
+	runCtx, stopDiskWatch := watchDiskSpace(ctx, ".", req.Config.MinFreeDiskMB, diskCheckInterval, runnerLog)
+	runErr := r.Run(runCtx)
+	stopDiskWatch()
+	if cause := context.Cause(runCtx); runErr != nil && errors.Is(cause, errLowDiskSpace) {
+		runErr = cause
+	}
+	postErr := runPostRunHooks(ctx, req.Config.PostRunCommand, hookRunner, string(plr.holder.Get()), runnerLog)
+	if o.IterationsReport {
+		report, reportErr := processor.FormatIterationsReport(r.Iterations())
+		switch {
+		case reportErr != nil:
+			runnerLog.Print("warning: %v", reportErr)
+		case report != "":
+			runnerLog.PrintRaw("\n%s", report)
+		}
+	}
+	if errors.Is(runErr, processor.ErrStoppedAtGate) {
+		// declined phase gate is a clean stop, the plan stays in place for a later --start-phase run
+		if postErr != nil {
+			runnerLog.Print("warning: %v", postErr)
+		}
+		runnerLog.Print("run stopped at phase gate after %s, branch %s left as is", plr.baseLog.Elapsed(), branch)
+		return nil
+	}
+	if runErr != nil {
+		if postErr != nil {
+			runnerLog.Print("warning: %v", postErr)
+		}
 		sendNotification(req, branch, plr.baseLog.Elapsed(), git.DiffStats{}, runErr)
 		return fmt.Errorf("runner: %w", runErr)
 	}
+	if postErr != nil {
+		sendNotification(req, branch, plr.baseLog.Elapsed(), git.DiffStats{}, postErr)
+		return postErr
+	}
+
+	// report_test_results: run the test command once more, the outcome goes into the notification and summary
+	if req.Config.ReportTestResults {
+		if res, ok := r.RunTests(ctx); ok {
+			req.TestResult = &res
+		}
+	}
 
 	elapsed := plr.baseLog.Elapsed()
 
 	// get diff stats for completion message (optional - errors logged but don't block).
 	// use worktree GitSvc (has correct HEAD with committed changes).
-	stats, statsErr := req.GitSvc.DiffStats(req.BaseRef)
+	excludes := statsExcludes(req, plr.baseLog.Path())
+	stats, statsErr := completionDiffStats(req, excludes)
 	if statsErr != nil {
 		fmt.Fprintf(os.Stderr, "warning: failed to get diff stats: %v\n", statsErr)
 	}
 
 	sendNotification(req, branch, elapsed, stats, nil)
 
+	var fileStats []git.FileDiffStat
+	if o.DetailedStats && stats.Files > 0 {
+		if fileStats, statsErr = req.GitSvc.DiffStatsPerFile(req.BaseRef, excludes...); statsErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to get per-file diff stats: %v\n", statsErr)
+		}
+	}
+
 	// move completed plan to completed/ directory.
 	// use MainGitSvc+MainPlanFile when available (worktree mode) because the plan file is in the main repo.
 	if req.PlanFile != "" && modeRequiresBranch(req.Mode) {
@@ -538,22 +1197,60 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 		if req.MainPlanFile != "" {
 			movePlanFile = req.MainPlanFile
 		}
-		if moveErr := moveSvc.MovePlanToCompleted(movePlanFile); moveErr != nil {
+		var summary []byte
+		if req.Config.WriteSummary {
+			var summaryErr error
+			if summary, summaryErr = buildRunSummary(req, branch, elapsed, stats, r.Iterations(), time.Now()); summaryErr != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to build run summary: %v\n", summaryErr)
+			}
+		}
+		if moveErr := moveSvc.MovePlanToCompletedWithSummary(movePlanFile, summary); moveErr != nil {
 			fmt.Fprintf(os.Stderr, "warning: failed to move plan to completed: %v\n", moveErr)
 		}
 	}
 
-	displayStats(req, plr.baseLog, stats, elapsed)
+	// tag the completion commit after reviews passed (tasks-only mode skips reviews)
+	if req.Config.TagOnComplete && req.PlanFile != "" && req.Mode != processor.ModeTasksOnly {
+		tagName := completionTagName(req.PlanFile, time.Now())
+		if tagErr := req.GitSvc.CreateTag(tagName, "ralphex completed plan "+filepath.Base(req.PlanFile)); tagErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to create completion tag: %v\n", tagErr)
+		}
+	}
+
+	displayStats(req, plr.baseLog, stats, fileStats, elapsed)
+	pushed, pushErr := pushCompletedBranch(req, branch)
+	switch {
+	case errors.Is(pushErr, git.ErrPushRejected):
+		fmt.Fprintf(os.Stderr, "warning: %s refused the push, the remote ref has diverged (non-fast-forward) or is protected; "+
+			"fetch and reconcile it, then push again: %v\n", pushRemote(req.Config), pushErr)
+	case pushErr != nil:
+		fmt.Fprintf(os.Stderr, "warning: failed to push branch: %v\n", pushErr)
+	}
+	if !pushed && pushErr == nil {
+		printUpstreamHint(os.Stdout, req, branch)
+	}
 	keepDashboardAlive(ctx, o, req, plr.closeLog)
 
 	return nil
 }
 
+// completionTagName returns the tag name for a completed plan, e.g. "ralphex/add-auth-2026-01-22".
+// the plan name is slugified to characters valid in git ref names.
+func completionTagName(planFile string, now time.Time) string {
+	slug := tagSlugRe.ReplaceAllString(strings.ToLower(plan.ExtractBranchName(planFile)), "-")
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		slug = "plan"
+	}
+	return completionTagPrefix + slug + "-" + now.Format("2006-01-02")
+}
+
 // runWithWorktree creates a worktree, creates the progress logger (before chdir so it lands
 // in the main repo), chdirs into the worktree, and runs executePlan. On return the worktree
-// is cleaned up and CWD is restored. req.WtCleanup is populated for interrupt handler use.
+// is cleaned up and CWD is restored. With keep_worktree_on_failure a failed or interrupted run
+// keeps the worktree for inspection. req.WtCleanup is populated for interrupt handler use.
 func runWithWorktree(ctx context.Context, o opts, req executePlanRequest) error {
-	wtPath, planNeedsCommit, err := req.GitSvc.CreateWorktreeForPlan(req.PlanFile, req.DefaultBranch)
+	wtPath, planNeedsCommit, err := req.GitSvc.CreateWorktreeForPlan(req.PlanFile, req.DefaultBranch, o.BranchName)
 	if err != nil {
 		return fmt.Errorf("create worktree: %w", err)
 	}
@@ -590,22 +1287,31 @@ func runWithWorktree(ctx context.Context, o opts, req executePlanRequest) error
 	}
 
 	// create progress logger BEFORE chdir so progress files land in main repo's .ralphex/progress/.
-	// use branch name derived from plan file since gitSvc still points at the main repo (on master).
+	// use branch name derived from plan file (or --branch-name) since gitSvc still points at the main repo (on master).
 	holder := &status.PhaseHolder{}
-	branch := plan.ExtractBranchName(req.PlanFile)
+	branch := plan.ExtractBranchNameWith(req.PlanFile, branchNameRules(req.Config))
+	if o.BranchName != "" {
+		branch = o.BranchName
+	}
 	baseLog, err := progress.NewLogger(progress.Config{
-		PlanFile: req.PlanFile,
-		Mode:     string(req.Mode),
-		Branch:   branch,
-		NoColor:  o.NoColor,
+		PlanFile:    req.PlanFile,
+		Mode:        string(req.Mode),
+		Branch:      branch,
+		RunID:       req.RunID,
+		Tools:       req.ToolVersions.String(),
+		NoColor:     o.NoColor,
+		PhaseNames:  req.Config.PhaseNames,
+		SplitByTask: req.Config.SplitProgressByTask,
 	}, req.Colors, holder)
 	if err != nil {
 		return fmt.Errorf("create progress logger: %w", err)
 	}
+	progressPath, _ := filepath.Abs(baseLog.Path()) // resolved before chdir, the log lives in the main repo
 	defer func() {
 		if closeErr := baseLog.Close(); closeErr != nil {
 			fmt.Fprintf(os.Stderr, "warning: failed to close progress log: %v\n", closeErr)
 		}
+		archiveProgress(req.Config, req.GitSvc, progressPath)
 	}()
 
 	// chdir into worktree
@@ -615,12 +1321,20 @@ func runWithWorktree(ctx context.Context, o opts, req executePlanRequest) error
 
 	// register cleanup: restore CWD and remove worktree.
 	// sync.Once prevents double-execution between defer and interrupt handler's force-exit path.
+	// with keep_worktree_on_failure the worktree stays unless the run succeeded; an interrupt
+	// force-exit never reaches success, so that path keeps it too.
 	var cleanupOnce sync.Once
+	var succeeded atomic.Bool
 	cleanup := func() {
 		cleanupOnce.Do(func() {
 			if chdirErr := os.Chdir(origDir); chdirErr != nil {
 				fmt.Fprintf(os.Stderr, "warning: failed to restore working directory: %v\n", chdirErr)
 			}
+			if req.Config.KeepWorktreeOnFailure && !succeeded.Load() {
+				fmt.Fprintf(os.Stderr, "worktree kept for inspection: %s (branch %s)\n", wtPath, branch)
+				fmt.Fprintf(os.Stderr, "remove it with: git worktree remove --force %s\n", wtPath)
+				return
+			}
 			if rmErr := req.GitSvc.RemoveWorktree(wtPath); rmErr != nil {
 				fmt.Fprintf(os.Stderr, "warning: failed to remove worktree: %v\n", rmErr)
 			}
@@ -635,6 +1349,8 @@ func runWithWorktree(ctx context.Context, o opts, req executePlanRequest) error
 	if err != nil {
 		return fmt.Errorf("open worktree git service: %w", err)
 	}
+	wtGitSvc.SetBranchNameRules(branchNameRules(req.Config))
+	wtGitSvc.SetCommitTrailer(commitTrailer(req.Config))
 
 	// resolve plan file path inside the worktree so Claude operates on the local copy,
 	// not the original in the main repo. the plan was copied by CreateWorktreeForPlan.
@@ -646,7 +1362,9 @@ func runWithWorktree(ctx context.Context, o opts, req executePlanRequest) error
 		if resolved, evalErr := filepath.EvalSymlinks(resolvedPlan); evalErr == nil {
 			resolvedPlan = resolved
 		}
-		if rel, relErr := filepath.Rel(req.GitSvc.Root(), resolvedPlan); relErr == nil {
+		// a plan outside the repository has no copy in the worktree and keeps its path
+		rel, relErr := filepath.Rel(req.GitSvc.Root(), resolvedPlan)
+		if relErr == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
 			abs, absErr := filepath.Abs(rel) // resolve relative to CWD (now the worktree)
 			if absErr == nil {
 				wtPlanFile = abs
@@ -661,12 +1379,22 @@ func runWithWorktree(ctx context.Context, o opts, req executePlanRequest) error
 		}
 	}
 
-	return executePlan(ctx, o, executePlanRequest{
+	// a reopened plan moves out of completed/ on the feature branch only, the main repo copy stays in completed/.
+	// the plan is then moved back to completed/ in the worktree as well, there is nothing to move in the main repo.
+	mainPlanFile, mainGitSvc := req.PlanFile, req.GitSvc
+	if isCompletedPlan(wtPlanFile) {
+		if wtPlanFile, err = reopenPlan(wtGitSvc, req.Colors, wtPlanFile); err != nil {
+			return err
+		}
+		mainPlanFile, mainGitSvc = "", nil
+	}
+
+	err = executePlan(ctx, o, executePlanRequest{
 		PlanFile:      wtPlanFile,
-		MainPlanFile:  req.PlanFile, // original path in main repo for MovePlanToCompleted
+		MainPlanFile:  mainPlanFile, // original path in main repo for MovePlanToCompleted
 		Mode:          req.Mode,
 		GitSvc:        wtGitSvc,
-		MainGitSvc:    req.GitSvc,
+		MainGitSvc:    mainGitSvc,
 		Config:        req.Config,
 		Colors:        req.Colors,
 		DefaultBranch: req.DefaultBranch,
@@ -674,7 +1402,12 @@ func runWithWorktree(ctx context.Context, o opts, req executePlanRequest) error
 		NotifySvc:     req.NotifySvc,
 		ProgressLog:   baseLog,
 		PhaseHolder:   holder,
+		TreeStatus:    req.TreeStatus,
+		RunID:         req.RunID,
+		ToolVersions:  req.ToolVersions,
 	})
+	succeeded.Store(err == nil)
+	return err
 }
 
 // openGitService creates a git.Service for the current directory.
@@ -687,6 +1420,27 @@ func openGitService(colors *progress.Colors, vcsCmd string) (*git.Service, error
 	return svc, nil
 }
 
+// branchNameRules builds the plan branch naming rules from branch_name_template and branch_strip_pattern.
+// the pattern is validated when the config is loaded, an invalid one falls back to the date prefix.
+func branchNameRules(cfg *config.Config) plan.BranchNameRules {
+	rules := plan.BranchNameRules{Template: cfg.BranchNameTemplate}
+	if cfg.BranchStripPattern != "" {
+		if re, err := regexp.Compile(cfg.BranchStripPattern); err == nil {
+			rules.StripPrefix = re
+		}
+	}
+	return rules
+}
+
+// commitTrailer returns commit_trailer with {{VERSION}} replaced by the ralphex version, empty if not set.
+// the trailer format is validated when the config is loaded.
+func commitTrailer(cfg *config.Config) string {
+	if cfg.CommitTrailer == "" {
+		return ""
+	}
+	return strings.ReplaceAll(cfg.CommitTrailer, "{{VERSION}}", resolveVersion())
+}
+
 // ensureGitIgnored adds patterns to .gitignore and commits if .gitignore was clean before.
 // patterns are pairs of (pattern, probePath) passed to EnsureIgnored.
 // returns error if arguments are invalid or pattern addition fails; commit errors are logged as warnings.
@@ -735,16 +1489,18 @@ func checkClaudeDep(cfg *config.Config) error {
 // isWatchOnlyMode returns true if running in watch-only mode.
 // watch-only mode runs the web dashboard without executing any plan.
 func isWatchOnlyMode(o opts, configWatchDirs []string) bool {
-	return o.Serve && o.PlanFile == "" && o.PlanDescription == "" && (len(o.Watch) > 0 || len(configWatchDirs) > 0)
+	return o.Serve && o.PlanFile == "" && o.PlanDescription == "" && o.InlinePlan == "" &&
+		(len(o.Watch) > 0 || len(configWatchDirs) > 0)
 }
 
 // runWatchOnly starts the web dashboard in watch-only mode without plan execution.
 func runWatchOnly(ctx context.Context, o opts, cfg *config.Config, colors *progress.Colors) error {
 	dirs := web.ResolveWatchDirs(o.Watch, cfg.WatchDirs)
 	dashboard := web.NewDashboard(web.DashboardConfig{
-		Port:   o.Port,
-		Host:   o.Host,
-		Colors: colors,
+		Port:       o.Port,
+		Host:       o.Host,
+		Colors:     colors,
+		PhaseNames: cfg.PhaseNames,
 	}, nil)
 	if watchErr := dashboard.RunWatchOnly(ctx, dirs); watchErr != nil {
 		return fmt.Errorf("run watch-only mode: %w", watchErr)
@@ -752,20 +1508,72 @@ func runWatchOnly(ctx context.Context, o opts, cfg *config.Config, colors *progr
 	return nil
 }
 
+// runReplay starts the web dashboard and streams a recorded progress file into it.
+func runReplay(ctx context.Context, o opts, cfg *config.Config, colors *progress.Colors) error {
+	dashboard := web.NewDashboard(web.DashboardConfig{
+		Port:       o.Port,
+		Host:       o.Host,
+		Colors:     colors,
+		PhaseNames: cfg.PhaseNames,
+	}, nil)
+	if err := dashboard.RunReplay(ctx, web.ReplayConfig{Path: o.Replay, Realtime: o.ReplayRealtime}); err != nil {
+		return fmt.Errorf("run replay mode: %w", err)
+	}
+	return nil
+}
+
+// runServeCheck starts the web dashboard on an ephemeral port, requests its endpoints and reports each result.
+// returns an error when any endpoint failed, so CI catches template and asset regressions.
+func runServeCheck(ctx context.Context, o opts, cfg *config.Config, w io.Writer) error {
+	dashboard := web.NewDashboard(web.DashboardConfig{Host: o.Host, PhaseNames: cfg.PhaseNames}, nil)
+	results, err := dashboard.RunCheck(ctx)
+	for _, res := range results {
+		if res.Err != nil {
+			fmt.Fprintf(w, "  %s (%s): failed, %v\n", res.Name, res.Path, res.Err)
+			continue
+		}
+		fmt.Fprintf(w, "  %s (%s): ok\n", res.Name, res.Path)
+	}
+	if err != nil {
+		return fmt.Errorf("serve check: %w", err)
+	}
+	fmt.Fprintf(w, "web dashboard check passed, %d endpoints ok\n", len(results))
+	return nil
+}
+
+// foldCodexOnly maps the deprecated --codex-only flag onto --external-only.
+// returns true if --codex-only was used, so the caller can warn about it.
+func foldCodexOnly(o opts) (opts, bool) {
+	if !o.CodexOnly {
+		return o, false
+	}
+	o.ExternalOnly, o.CodexOnly = true, false
+	return o, true
+}
+
 // determineMode returns the execution mode based on CLI flags.
-func determineMode(o opts) processor.Mode {
+// when no mode flag is set, defaultMode (from default_mode config) is used, falling back to full.
+// returns an error when --start-phase is set and the resolved mode is not full, e.g. default_mode = review.
+func determineMode(o opts, defaultMode processor.Mode) (processor.Mode, error) {
+	var mode processor.Mode
 	switch {
 	case o.PlanDescription != "":
-		return processor.ModePlan
+		mode = processor.ModePlan
 	case o.TasksOnly:
-		return processor.ModeTasksOnly
-	case o.ExternalOnly || o.CodexOnly:
-		return processor.ModeCodexOnly
+		mode = processor.ModeTasksOnly
+	case o.ExternalOnly:
+		mode = processor.ModeExternalOnly
 	case o.Review:
-		return processor.ModeReview
+		mode = processor.ModeReview
+	case defaultMode != "":
+		mode = defaultMode
 	default:
-		return processor.ModeFull
+		mode = processor.ModeFull
+	}
+	if o.StartPhase != "" && mode != processor.ModeFull {
+		return "", fmt.Errorf("--start-phase is only used in full mode, the run is in %s mode", mode)
 	}
+	return mode, nil
 }
 
 // modeRequiresBranch returns true if the mode requires creating a feature branch.
@@ -779,6 +1587,63 @@ func validateFlags(o opts) error {
 	if o.PlanDescription != "" && o.PlanFile != "" {
 		return errors.New("--plan flag conflicts with plan file argument; use one or the other")
 	}
+	if o.InlinePlan != "" && (o.PlanFile != "" || o.PlanDescription != "") {
+		return errors.New("--inline-plan conflicts with plan file argument and --plan; use only one of them")
+	}
+	if o.Replay != "" && (o.PlanFile != "" || o.PlanDescription != "" || o.InlinePlan != "") {
+		return errors.New("--replay conflicts with plan execution; use it without a plan file or --plan")
+	}
+	if o.SinceTag && o.BaseRef != "" {
+		return errors.New("--since-tag conflicts with --base-ref; use one or the other")
+	}
+	if o.BranchName != "" {
+		if err := git.ValidateBranchName(o.BranchName); err != nil {
+			return fmt.Errorf("invalid --branch-name: %w", err)
+		}
+		if o.Review || o.ExternalOnly {
+			return errors.New("--branch-name is only used when ralphex creates a feature branch (full or tasks-only mode)")
+		}
+	}
+	if o.StartPhase != "" {
+		switch processor.StartPhase(o.StartPhase) {
+		case processor.StartTasks, processor.StartReview, processor.StartCodex, processor.StartFinalize:
+		default:
+			return fmt.Errorf("invalid --start-phase %q, expected tasks, review, codex or finalize", o.StartPhase)
+		}
+		if o.Review || o.ExternalOnly || o.TasksOnly || o.PlanDescription != "" {
+			return errors.New("--start-phase is only used in full mode; it can't be combined with --review, --external-only, --tasks-only or --plan")
+		}
+	}
+	if o.Autostash && (o.Review || o.ExternalOnly) {
+		return errors.New("--autostash is only used when ralphex creates a feature branch (full or tasks-only mode)")
+	}
+	for _, kv := range o.CodexConfig {
+		if err := executor.ValidateCodexOverride(kv, o.Force); err != nil {
+			if errors.Is(err, executor.ErrCodexManagedKey) {
+				return fmt.Errorf("invalid --codex-config: %w (use --force to override)", err)
+			}
+			return fmt.Errorf("invalid --codex-config: %w", err)
+		}
+	}
+	if o.ResetTo != "" && (o.PlanFile != "" || o.PlanDescription != "" || o.InlinePlan != "" || o.Replay != "") {
+		return errors.New("--reset-to is a standalone action; it can't be combined with a plan file, --plan, --inline-plan or --replay")
+	}
+	if o.Explain && (o.InlinePlan != "" || o.ResetTo != "" || o.Replay != "" || o.NotifyTest) {
+		return errors.New("--explain describes a plan run; it can't be combined with --inline-plan, --reset-to, --replay or --notify-test")
+	}
+	if o.Estimate && (o.Explain || o.PlanDescription != "" || o.InlinePlan != "" || o.ResetTo != "" || o.Replay != "" || o.NotifyTest) {
+		return errors.New("--estimate estimates a plan run; it can't be combined with " +
+			"--explain, --plan, --inline-plan, --reset-to, --replay or --notify-test")
+	}
+	if o.Only != "" && o.DumpDefaults == "" {
+		return errors.New("--only requires --dump-defaults")
+	}
+	if o.DumpDefaults == "-" && o.Only == "" {
+		return errors.New("--dump-defaults - prints a single default and requires --only")
+	}
+	if o.ReplayRealtime && o.Replay == "" {
+		return errors.New("--replay-realtime requires --replay")
+	}
 	if o.Wait < 0 {
 		return fmt.Errorf("--wait must be non-negative, got %s", o.Wait)
 	}
@@ -788,11 +1653,39 @@ func validateFlags(o opts) error {
 	return nil
 }
 
+// checkStartPhase verifies that skipping phases with --start-phase is safe: the run must be on
+// a feature branch that already has commits ahead of the base ref, otherwise there is nothing to review.
+// starting at finalize also requires the finalize step to be enabled.
+func checkStartPhase(start processor.StartPhase, req executePlanRequest) error {
+	if start == "" || start == processor.StartTasks {
+		return nil
+	}
+	if start == processor.StartFinalize && !req.Config.FinalizeEnabled {
+		return errors.New("--start-phase finalize requires the finalize step, set finalize_enabled = true")
+	}
+	isDefault, err := req.GitSvc.IsDefaultBranch(req.DefaultBranch)
+	if err != nil {
+		return fmt.Errorf("--start-phase %s: %w", start, err)
+	}
+	if isDefault {
+		return fmt.Errorf("--start-phase %s must run on an existing feature branch, not the default branch", start)
+	}
+	commits, err := req.GitSvc.CommitLog(req.BaseRef)
+	if err != nil {
+		return fmt.Errorf("--start-phase %s: %w", start, err)
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("--start-phase %s skips task execution, but there are no commits ahead of %s to review",
+			start, req.BaseRef)
+	}
+	return nil
+}
+
 // createRunner creates a processor.Runner with the given configuration.
 func createRunner(req executePlanRequest, o opts, log processor.Logger, holder *status.PhaseHolder) *processor.Runner {
-	// --codex-only mode forces codex enabled regardless of config
+	// external-only mode forces the external review enabled regardless of config
 	codexEnabled := req.Config.CodexEnabled
-	if req.Mode == processor.ModeCodexOnly {
+	if req.Mode == processor.ModeExternalOnly {
 		codexEnabled = true
 	}
 	// resolve max external iterations: CLI flag > config file > 0 (auto)
@@ -814,13 +1707,23 @@ func createRunner(req executePlanRequest, o opts, log processor.Logger, holder *
 		MaxIterations:         resolveMaxIterations(o.MaxIterations, req.Config),
 		MaxExternalIterations: maxExtIter,
 		ReviewPatience:        reviewPatience,
+		MaxDiffLines:          o.MaxDiffLines,
+		InteractiveGates:      o.InteractiveGates,
 		Debug:                 o.Debug,
+		StreamRaw:             o.StreamRaw,
 		NoColor:               o.NoColor,
 		IterationDelayMs:      req.Config.IterationDelayMs,
 		TaskRetryCount:        req.Config.TaskRetryCount,
+		ReviewRetryCount:      req.Config.ReviewRetryCount,
+		EmptyIterationLimit:   req.Config.EmptyIterationLimit,
+		FailOnP1:              req.Config.CodexFailOnP1,
 		CodexEnabled:          codexEnabled,
 		FinalizeEnabled:       req.Config.FinalizeEnabled,
 		DefaultBranch:         req.BaseRef,
+		CodexExtraConfig:      o.CodexConfig,
+		TestCommand:           req.TestCommand,
+		CommitTrailer:         commitTrailer(req.Config),
+		StartPhase:            processor.StartPhase(o.StartPhase),
 		AppConfig:             req.Config,
 	}, log, holder)
 	if req.GitSvc != nil {
@@ -834,6 +1737,17 @@ func printStartupInfo(info startupInfo, colors *progress.Colors) {
 		colors.Info().Printf("starting interactive plan creation\n")
 		colors.Info().Printf("request: %s\n", info.PlanDescription)
 		colors.Info().Printf("branch: %s (max %d iterations)\n", info.Branch, info.MaxIterations)
+		model := info.Model
+		if model == "" {
+			model = "default"
+		}
+		colors.Info().Printf("model: %s\n", model)
+		if info.RunID != "" {
+			colors.Info().Printf("run: %s\n", info.RunID)
+		}
+		if info.Tools != "" {
+			colors.Info().Printf("tools: %s\n", info.Tools)
+		}
 		colors.Info().Printf("progress log: %s\n\n", info.ProgressPath)
 		return
 	}
@@ -842,18 +1756,63 @@ func printStartupInfo(info startupInfo, colors *progress.Colors) {
 	if info.Mode != processor.ModeFull {
 		modeStr = fmt.Sprintf(" (%s mode)", info.Mode)
 	}
+	if info.StartPhase != "" && info.StartPhase != processor.StartTasks {
+		modeStr += fmt.Sprintf(" (starting at %s phase)", info.StartPhase)
+	}
 	colors.Info().Printf("starting ralphex loop (max %d iterations)%s\n", info.MaxIterations, modeStr)
 	if info.PlanFile != "" {
 		colors.Info().Printf("plan: %s\n", toRelPath(info.PlanFile))
 	}
-	colors.Info().Printf("branch: %s\n", info.Branch)
+	if info.Divergence != "" {
+		colors.Info().Printf("branch: %s (%s)\n", info.Branch, info.Divergence)
+	} else {
+		colors.Info().Printf("branch: %s\n", info.Branch)
+	}
+	if info.RebaseHint != "" {
+		colors.Warn().Printf("%s\n", info.RebaseHint)
+	}
+	if names := formatPhaseNames(info.PhaseNames); names != "" {
+		colors.Info().Printf("phase names: %s\n", names)
+	}
+	if info.TestCommand != "" {
+		colors.Info().Printf("test command: %s (%s)\n", info.TestCommand, info.TestSource)
+	}
+	if info.RunID != "" {
+		colors.Info().Printf("run: %s\n", info.RunID)
+	}
+	if info.Tools != "" {
+		colors.Info().Printf("tools: %s\n", info.Tools)
+	}
 	colors.Info().Printf("progress log: %s\n\n", info.ProgressPath)
 }
 
+// formatPhaseNames formats custom phase labels as "phase=label" pairs in pipeline order.
+// returns empty string when no labels are configured.
+func formatPhaseNames(names status.PhaseNames) string {
+	var pairs []string
+	for _, p := range status.AllPhases {
+		if label := names[p]; label != "" {
+			pairs = append(pairs, string(p)+"="+label)
+		}
+	}
+	return strings.Join(pairs, ", ")
+}
+
 // runPlanMode executes interactive plan creation mode.
 // creates input collector, progress logger, and runs the plan creation loop.
 // after plan creation, prompts user to continue with implementation or exit.
 func runPlanMode(ctx context.Context, o opts, req executePlanRequest, selector *plan.Selector) error {
+	// the plan is written into the plans dir, offer to create it on first run
+	if err := selector.EnsurePlansDir(ctx); err != nil {
+		return err
+	}
+
+	release, err := acquireRunLock(req.GitSvc.Root(), "(plan creation: "+o.PlanDescription+")")
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	// ensure gitignore has progress files (check dirty, add, commit if was clean)
 	if err := ensureGitIgnored(req.GitSvc, ".ralphex/progress/", ".ralphex/progress/progress-test.txt"); err != nil {
 		return fmt.Errorf("ensure gitignore: %w", err)
@@ -869,7 +1828,10 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest, selector *
 		PlanDescription: o.PlanDescription,
 		Mode:            string(processor.ModePlan),
 		Branch:          branch,
+		RunID:           req.RunID,
+		Tools:           req.ToolVersions.String(),
 		NoColor:         o.NoColor,
+		PhaseNames:      req.Config.PhaseNames,
 	}, req.Colors, holder)
 	if err != nil {
 		return fmt.Errorf("create progress logger: %w", err)
@@ -880,6 +1842,9 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest, selector *
 		}
 	}()
 
+	restoreTitle := startTerminalTitle(req.Config.TerminalTitle, o.NoColor, holder, req.Config.PhaseNames, baseLog.Elapsed)
+	defer restoreTitle()
+
 	maxIter := resolveMaxIterations(o.MaxIterations, req.Config)
 
 	// print startup info for plan mode
@@ -889,6 +1854,9 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest, selector *
 		Mode:            processor.ModePlan,
 		MaxIterations:   maxIter,
 		ProgressPath:    baseLog.Path(),
+		RunID:           req.RunID,
+		Tools:           req.ToolVersions.String(),
+		Model:           req.Config.PlanModel,
 	}, req.Colors)
 
 	// create input collector
@@ -897,6 +1865,37 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest, selector *
 	// record start time for finding the created plan
 	startTime := time.Now()
 
+	// with --serve the dashboard streams the run and previews the plan as claude writes it.
+	// it stops before continuing to implementation, which starts its own dashboard on the same port
+	var runnerLog processor.Logger = baseLog
+	stopDashboard := func() {}
+	if o.Serve {
+		dashCtx, cancelDash := context.WithCancel(ctx)
+		dashboard := web.NewDashboard(web.DashboardConfig{
+			BaseLog:         baseLog,
+			Port:            o.Port,
+			Host:            o.Host,
+			Branch:          branch,
+			RunID:           req.RunID,
+			WatchDirs:       o.Watch,
+			ConfigWatchDirs: req.Config.WatchDirs,
+			Colors:          req.Colors,
+			PhaseNames:      req.Config.PhaseNames,
+			BatchInterval:   req.Config.DashboardBatchInterval,
+			PlanPreview:     func() string { return selector.FindRecent(startTime) },
+		}, holder)
+		var dashErr error
+		if runnerLog, dashErr = dashboard.Start(dashCtx); dashErr != nil {
+			cancelDash()
+			return fmt.Errorf("start dashboard: %w", dashErr)
+		}
+		stopDashboard = sync.OnceFunc(func() {
+			cancelDash()
+			<-dashboard.Done()
+		})
+	}
+	defer stopDashboard()
+
 	// create and configure runner
 	r := processor.New(processor.Config{
 		PlanDescription:  o.PlanDescription,
@@ -907,14 +1906,16 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest, selector *
 		NoColor:          o.NoColor,
 		IterationDelayMs: req.Config.IterationDelayMs,
 		DefaultBranch:    req.BaseRef,
+		ClaudeModel:      req.Config.PlanModel,
 		AppConfig:        req.Config,
-	}, baseLog, holder)
+	}, runnerLog, holder)
 	r.SetInputCollector(collector)
 
 	// run the plan creation loop
 	if runErr := r.Run(ctx); runErr != nil {
 		return fmt.Errorf("plan creation: %w", runErr)
 	}
+	restoreTitle() // execution sets its own title if the user continues with the plan
 
 	// find the newly created plan file
 	planFile := selector.FindRecent(startTime)
@@ -936,6 +1937,7 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest, selector *
 	if !input.AskYesNo(ctx, "Continue with plan implementation?", os.Stdin, os.Stdout) {
 		return nil
 	}
+	stopDashboard()
 
 	// resolve plan file to absolute path before potential chdir
 	planFile, err = filepath.Abs(planFile)
@@ -958,11 +1960,13 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest, selector *
 			BaseRef:       req.BaseRef,
 			NotifySvc:     req.NotifySvc,
 			WtCleanup:     req.WtCleanup,
+			RunID:         req.RunID,
+			ToolVersions:  req.ToolVersions,
 		})
 	}
 
 	// normal mode: create branch and run in place
-	if err := req.GitSvc.CreateBranchForPlan(planFile, req.DefaultBranch); err != nil {
+	if err := req.GitSvc.CreateBranchForPlan(planFile, req.DefaultBranch, o.BranchName); err != nil {
 		return fmt.Errorf("create branch for plan: %w", err)
 	}
 
@@ -975,6 +1979,8 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest, selector *
 		DefaultBranch: req.DefaultBranch,
 		BaseRef:       req.BaseRef,
 		NotifySvc:     req.NotifySvc,
+		RunID:         req.RunID,
+		ToolVersions:  req.ToolVersions,
 	})
 }
 
@@ -987,7 +1993,8 @@ func runReset(configDir string, stdin io.Reader, stdout io.Writer) error {
 	return nil
 }
 
-// handleEarlyFlags processes flags that should run before full config load (--reset, --dump-defaults).
+// handleEarlyFlags processes flags that should run before full config load
+// (--reset, --list-defaults, --dump-defaults, --dump-schema).
 // returns (true, nil) if an early exit occurred, (true, err) on error, or (false, nil) to continue.
 func handleEarlyFlags(o opts) (bool, error) {
 	if o.Reset {
@@ -999,13 +2006,176 @@ func handleEarlyFlags(o opts) (bool, error) {
 		}
 	}
 
+	if o.ListDefaults {
+		return true, listDefaults(os.Stdout)
+	}
+
 	if o.DumpDefaults != "" {
+		if o.Only != "" {
+			return true, dumpDefault(o.DumpDefaults, o.Only, os.Stdout)
+		}
 		return true, dumpDefaults(o.DumpDefaults)
 	}
 
+	if o.DumpSchema {
+		return true, dumpSchema(os.Stdout)
+	}
+
+	if o.PlanSummary != "" {
+		return true, printPlanSummary(o.PlanSummary, os.Stdout)
+	}
+
 	return false, nil
 }
 
+// runNotifyTest sends a test notification through every configured channel and reports the outcome per channel.
+// returns an error if no channels are configured or any channel failed.
+func runNotifyTest(ctx context.Context, svc *notify.Service, configured []string, w io.Writer) error {
+	if len(configured) == 0 {
+		return errors.New("no notification channels configured, set notify_channels in config")
+	}
+	fmt.Fprintf(w, "configured channels: %s\n", strings.Join(configured, ", "))
+
+	results := svc.SendTest(ctx)
+	var failed int
+	for _, res := range results {
+		if res.Err != nil {
+			failed++
+			fmt.Fprintf(w, "  %s: failed, %v\n", res.Channel, res.Err)
+			continue
+		}
+		fmt.Fprintf(w, "  %s: ok\n", res.Channel)
+	}
+	if failed > 0 {
+		return fmt.Errorf("test notification failed for %d of %d channel(s)", failed, len(results))
+	}
+	fmt.Fprintf(w, "test notification sent to %d channel(s)\n", len(results))
+	return nil
+}
+
+// printPlanSummary parses the plan file and writes its summary to w.
+func printPlanSummary(path string, w io.Writer) error {
+	p, err := plan.ParsePlanFile(path)
+	if err != nil {
+		return fmt.Errorf("plan summary: %w", err)
+	}
+	if _, err := io.WriteString(w, p.Summary()); err != nil {
+		return fmt.Errorf("write plan summary: %w", err)
+	}
+	return nil
+}
+
+// printPlanWarnings prints plan lint and size warnings as a heads-up before execution.
+// maxTasks is the plan_max_tasks limit. returns true if the plan exceeds the size limits.
+// parse errors are ignored here, they are reported by the runner when it reads the plan.
+func printPlanWarnings(planFile string, maxTasks int, colors *progress.Colors, w io.Writer) bool {
+	p, err := plan.ParsePlanFile(planFile)
+	if err != nil {
+		return false
+	}
+	sizeWarnings := p.ValidateSize(maxTasks)
+	warnings := append(p.Validate(), sizeWarnings...)
+	if len(warnings) == 0 {
+		return false
+	}
+	colors.Warn().Fprintf(w, "plan %s has %d warning(s):\n", filepath.Base(planFile), len(warnings))
+	for _, pw := range warnings {
+		colors.Warn().Fprintf(w, "  - %s\n", pw.String())
+	}
+	return len(sizeWarnings) > 0
+}
+
+// acquireLockForRun takes the lock a run needs: worktree runs lock their plan, so runs on other plans
+// can go side by side, every other run takes the repo-wide run lock.
+func acquireLockForRun(req executePlanRequest, planFile string) (func(), error) {
+	if req.Config.WorktreeEnabled && planFile != "" && modeRequiresBranch(req.Mode) {
+		return acquirePlanLock(req.GitSvc.Root(), planFile)
+	}
+	lockPlan := "(" + string(req.Mode) + " without plan)"
+	if planFile != "" {
+		lockPlan = toRelPath(planFile)
+	}
+	return acquireRunLock(req.GitSvc.Root(), lockPlan)
+}
+
+// isCompletedPlan reports whether planFile lives in a completed/ directory, where finished plans are moved.
+func isCompletedPlan(planFile string) bool {
+	return planFile != "" && filepath.Base(filepath.Dir(planFile)) == "completed"
+}
+
+// confirmReopenPlan asks whether a finished plan should move out of completed/ and run again, running it in place
+// would move it to completed/ a second time. the move itself happens later, on the plan's feature branch.
+// in interactive mode asks for confirmation unless --yes is set, non-interactive runs without --yes are refused.
+// returns false if the user declined.
+func confirmReopenPlan(ctx context.Context, o opts, planFile string, stdin io.Reader, stdout io.Writer, interactive bool) (bool, error) {
+	if o.Yes {
+		return true, nil
+	}
+	plansDir := filepath.Dir(filepath.Dir(planFile))
+	if !interactive {
+		return false, fmt.Errorf("plan %s is already completed, move it back to %s or pass --yes to reopen it",
+			toRelPath(planFile), toRelPath(plansDir))
+	}
+	question := fmt.Sprintf("plan %s is already completed, move it back to %s and run it again?",
+		filepath.Base(planFile), toRelPath(plansDir))
+	return input.AskYesNo(ctx, question, stdin, stdout), nil
+}
+
+// reopenPlan moves a confirmed completed plan back out of completed/ on the current branch.
+func reopenPlan(gitSvc *git.Service, colors *progress.Colors, planFile string) (string, error) {
+	reopened, err := gitSvc.ReopenPlan(planFile)
+	if err != nil {
+		return "", fmt.Errorf("reopen plan: %w", err)
+	}
+	colors.Info().Printf("reopened plan %s\n", toRelPath(reopened))
+	return reopened, nil
+}
+
+// preflightTreeStatus prints uncommitted changes present before branch or worktree setup,
+// so stray local edits don't end up in ralphex commits by surprise.
+// in interactive mode asks for confirmation unless --yes is set.
+// returns the printed summary (empty for a clean tree) and whether to proceed.
+// status errors are reported as warnings and never block the run.
+func preflightTreeStatus(ctx context.Context, o opts, gitSvc *git.Service, colors *progress.Colors,
+	stdin io.Reader, stdout io.Writer, interactive bool) (string, bool) {
+	changes, err := gitSvc.WorkingTreeStatus()
+	if err != nil {
+		fmt.Fprintf(stdout, "warning: %v\n", err)
+		return "", true
+	}
+	if len(changes) == 0 {
+		return "", true
+	}
+
+	summary := formatTreeStatus(changes)
+	colors.Warn().Fprintf(stdout, "%s\n", summary)
+	if !interactive || o.Yes {
+		return summary, true
+	}
+	return summary, input.AskYesNo(ctx, "proceed with these uncommitted changes?", stdin, stdout)
+}
+
+// formatTreeStatus formats working tree changes as a header with totals followed by one line per file.
+// the file list is truncated to maxPreflightFiles entries.
+func formatTreeStatus(changes []git.FileChange) string {
+	var additions, deletions int
+	for _, c := range changes {
+		additions += c.Additions
+		deletions += c.Deletions
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "working tree has %d uncommitted file(s), ~+%d/-%d lines:", len(changes), additions, deletions)
+	for i, c := range changes {
+		if i >= maxPreflightFiles {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(changes)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n  %-2s %s (+%d/-%d)", c.Status, c.Path, c.Additions, c.Deletions)
+	}
+	return b.String()
+}
+
 // dumpDefaults extracts raw embedded defaults to the specified directory.
 func dumpDefaults(dir string) error {
 	if err := config.DumpDefaults(dir); err != nil {
@@ -1015,6 +2185,54 @@ func dumpDefaults(dir string) error {
 	return nil
 }
 
+// dumpDefault extracts a single named embedded default. with dir "-" the content is written to w,
+// otherwise the file is written under dir at its config directory path (e.g. prompts/task.txt).
+func dumpDefault(dir, name string, w io.Writer) error {
+	relPath, data, err := config.DumpDefault(name)
+	if err != nil {
+		return fmt.Errorf("dump default: %w, run --list-defaults to see available names", err)
+	}
+	if dir == "-" {
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("dump default: %w", err)
+		}
+		return nil
+	}
+	path := filepath.Join(dir, relPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("dump default: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("dump default: %w", err)
+	}
+	fmt.Fprintf(w, "default %s extracted to %s\n", name, path)
+	return nil
+}
+
+// listDefaults writes the names of embedded defaults accepted by --only to w, one per line.
+func listDefaults(w io.Writer) error {
+	names, err := config.DefaultNames()
+	if err != nil {
+		return fmt.Errorf("list defaults: %w", err)
+	}
+	for _, name := range names {
+		fmt.Fprintln(w, name)
+	}
+	return nil
+}
+
+// dumpSchema writes the JSON schema of the config to w.
+func dumpSchema(w io.Writer) error {
+	schema, err := config.Schema()
+	if err != nil {
+		return fmt.Errorf("dump schema: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", schema); err != nil {
+		return fmt.Errorf("write schema: %w", err)
+	}
+	return nil
+}
+
 // toRelPath converts an absolute path to relative (from cwd). returns original on error.
 func toRelPath(p string) string {
 	cwd, err := os.Getwd()
@@ -1039,12 +2257,21 @@ func isResetOnly(o opts) bool {
 	return o.PlanFile == "" &&
 		!o.Review &&
 		!o.ExternalOnly &&
-		!o.CodexOnly &&
 		!o.TasksOnly &&
 		!o.Serve &&
 		o.PlanDescription == "" &&
+		o.InlinePlan == "" &&
 		len(o.Watch) == 0 &&
-		o.DumpDefaults == ""
+		o.Replay == "" &&
+		o.ResetTo == "" &&
+		o.DumpDefaults == "" &&
+		!o.ListDefaults &&
+		!o.DumpSchema &&
+		!o.NotifyTest &&
+		!o.Explain &&
+		!o.Estimate &&
+		!o.ListAgents &&
+		o.ListModes == ""
 }
 
 // startInterruptWatcher prints immediate feedback when context is canceled.
@@ -1077,9 +2304,16 @@ func applyCLIOverrides(o opts, cfg *config.Config) {
 	if o.SkipFinalize {
 		cfg.FinalizeEnabled = false
 	}
+	if o.NoFinalizeCommit {
+		cfg.FinalizeNoCommit = true
+		cfg.FinalizeNoCommitSet = true
+	}
 	if o.Worktree {
 		cfg.WorktreeEnabled = true
 	}
+	if o.KeepWorktree {
+		cfg.KeepWorktreeOnFailure = true
+	}
 	if o.Wait > 0 {
 		cfg.WaitOnLimit = o.Wait
 		cfg.WaitOnLimitSet = true
@@ -1088,6 +2322,13 @@ func applyCLIOverrides(o opts, cfg *config.Config) {
 		cfg.SessionTimeout = o.SessionTimeout
 		cfg.SessionTimeoutSet = true
 	}
+	if o.RetryFailedReview > 0 {
+		cfg.ReviewRetryCount = o.RetryFailedReview
+		cfg.ReviewRetryCountSet = true
+	}
+	if o.PlanModeModel != "" {
+		cfg.PlanModel = o.PlanModeModel
+	}
 }
 
 // resolveMaxIterations returns the effective max iterations value.
@@ -1114,6 +2355,177 @@ func resolveDefaultBranch(cliRef, configBranch, autoDetected string) string {
 	return autoDetected
 }
 
+// resolveSinceTag returns the latest completion tag reachable from HEAD, for --since-tag review diffs.
+// falls back to the given default branch when no tag exists or the lookup fails.
+func resolveSinceTag(gitSvc *git.Service, fallback string, colors *progress.Colors, w io.Writer) string {
+	tag, err := gitSvc.LatestTagMatching(completionTagPrefix + "*")
+	if err != nil {
+		colors.Warn().Fprintf(w, "warning: %v, reviewing against %s\n", err, fallback)
+		return fallback
+	}
+	if tag == "" {
+		colors.Info().Fprintf(w, "no %s* tag found, reviewing against %s\n", completionTagPrefix, fallback)
+		return fallback
+	}
+	colors.Info().Fprintf(w, "reviewing changes since tag %s\n", tag)
+	return tag
+}
+
+// resolveTestCommand returns the test command and its source: the configured command wins,
+// otherwise the command is detected from repo markers in dir. returns empty strings if nothing matches.
+func resolveTestCommand(configCmd, dir string) (command, source string) {
+	if configCmd != "" {
+		return configCmd, "config"
+	}
+	command, marker := detectTestCommand(dir)
+	if command == "" {
+		return "", ""
+	}
+	return command, "detected from " + marker
+}
+
+// detectTestCommand picks a test command based on marker files in dir.
+// a Makefile test target wins because it's the project's own entry point, then language markers.
+// returns the command and the marker file name, or empty strings if no marker is found.
+func detectTestCommand(dir string) (command, marker string) {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "Makefile")); err == nil && makeTestTargetRe.Match(data) { //nolint:gosec // path in repo dir
+		return "make test", "Makefile"
+	}
+	if exists("go.mod") {
+		return "go test ./...", "go.mod"
+	}
+	if exists("Cargo.toml") {
+		return "cargo test", "Cargo.toml"
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil { //nolint:gosec // path in repo dir
+		var pkg struct {
+			Scripts map[string]string `json:"scripts"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && pkg.Scripts["test"] != "" && pkg.Scripts["test"] != npmDefaultTestScript {
+			switch {
+			case exists("pnpm-lock.yaml"):
+				return "pnpm test", "package.json"
+			case exists("yarn.lock"):
+				return "yarn test", "package.json"
+			default:
+				return "npm test", "package.json"
+			}
+		}
+	}
+	for _, name := range []string{"pyproject.toml", "setup.py", "pytest.ini"} {
+		if exists(name) {
+			return "pytest", name
+		}
+	}
+	return "", ""
+}
+
+// runResetTo opens the git repository and resets the current feature branch to o.ResetTo.
+func runResetTo(ctx context.Context, o opts, cfg *config.Config, colors *progress.Colors) error {
+	gitSvc, err := openGitService(colors, cfg.VcsCommand)
+	if err != nil {
+		return fmt.Errorf("open git repo: %w", err)
+	}
+	defaultBranch := resolveDefaultBranch("", cfg.DefaultBranch, gitSvc.GetDefaultBranch())
+	return resetToRef(ctx, gitSvc, o.ResetTo, defaultBranch, o.Yes, os.Stdin, os.Stdout)
+}
+
+// resetToRef resets the current feature branch to ref after confirmation (skipped when yes is set).
+// the default branch is never reset. declining the prompt is not an error, nothing is changed.
+func resetToRef(ctx context.Context, gitSvc *git.Service, ref, defaultBranch string, yes bool, stdin io.Reader,
+	stdout io.Writer) error {
+	branch, err := gitSvc.CurrentBranch()
+	if err != nil {
+		return fmt.Errorf("reset to %s: %w", ref, err)
+	}
+
+	fmt.Fprintf(stdout, "resetting branch %q to %s discards all later commits and uncommitted changes.\n", branch, ref)
+	if !yes && !input.AskYesNo(ctx, "reset the branch?", stdin, stdout) {
+		if ctx.Err() != nil {
+			return fmt.Errorf("reset to %s: %w", ref, ctx.Err())
+		}
+		fmt.Fprintln(stdout, "reset canceled")
+		return nil
+	}
+
+	if err := gitSvc.ResetHard(ref, defaultBranch); err != nil {
+		return fmt.Errorf("reset to %s: %w", ref, err)
+	}
+	return nil
+}
+
+// abortInProgressOperation detects an interrupted rebase, merge or cherry-pick and offers to abort it
+// when a previous ralphex run left it behind: the lock of a run that is no longer running, the repo-wide
+// or a worktree run's plan lock, is still in place and the operation started after that run.
+// any other operation is the user's and is never touched: modes that create a branch are refused,
+// since switching branches fails on top of it, other modes only get a warning.
+// detection failures are reported as warnings and don't block the run.
+// declining the prompt returns an error, since ralphex can't safely continue on top of it.
+func abortInProgressOperation(ctx context.Context, gitSvc *git.Service, mode processor.Mode, stdin io.Reader,
+	stdout io.Writer) error {
+	op, started, err := gitSvc.InProgressOperationStarted()
+	if err != nil {
+		fmt.Fprintf(stdout, "warning: %v\n", err)
+		return nil
+	}
+	if op == "" {
+		return nil
+	}
+
+	holder, stale := staleRunLock(gitSvc.Root(), started)
+	if !stale {
+		if modeRequiresBranch(mode) {
+			return fmt.Errorf("repository has an in-progress %s, finish it (git %s --continue) or abort it (git %s --abort) "+
+				"before running ralphex", op, op, op)
+		}
+		fmt.Fprintf(stdout, "warning: repository has an in-progress %s, %s mode continues without touching it\n", op, mode)
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "repository has an in-progress %s left by an interrupted ralphex run (plan %s, started %s)\n",
+		op, holder.Plan, holder.Started.Format(time.DateTime))
+	fmt.Fprintf(stdout, "aborting restores the pre-%s state and discards any conflict resolution made so far.\n", op)
+	fmt.Fprintln(stdout)
+	if !input.AskYesNo(ctx, fmt.Sprintf("abort the %s?", op), stdin, stdout) {
+		if ctx.Err() != nil {
+			return fmt.Errorf("abort %s: %w", op, ctx.Err())
+		}
+		return fmt.Errorf("in-progress %s must be finished or aborted before running ralphex", op)
+	}
+
+	if err := gitSvc.AbortInProgress(); err != nil {
+		return fmt.Errorf("abort in-progress operation: %w", err)
+	}
+	return nil
+}
+
+// checkShallowClone warns when the repo is a shallow clone, where history missing below the base ref makes
+// diff stats and review diffs incomplete. with unshallow set the full history is fetched instead of warning.
+// failures to check or fetch are warnings, the run continues either way.
+func checkShallowClone(gitSvc *git.Service, unshallow bool, baseRef string, colors *progress.Colors, w io.Writer) {
+	shallow, err := gitSvc.IsShallow()
+	if err != nil {
+		colors.Warn().Fprintf(w, "warning: %v\n", err)
+		return
+	}
+	if !shallow {
+		return
+	}
+	if unshallow {
+		if err = gitSvc.Unshallow(); err == nil {
+			return
+		}
+		colors.Warn().Fprintf(w, "warning: %v\n", err)
+	}
+	colors.Warn().Fprintf(w, "warning: shallow clone, review diffs against %s may be incomplete or wrong\n", baseRef)
+	colors.Warn().Fprintf(w, "  fix: git fetch --unshallow, set auto_unshallow = true, or clone with full history (fetch-depth: 0 on CI)\n")
+}
+
 // ensureRepoHasCommits checks that the repository has at least one commit.
 // If the repository is empty, prompts the user to create an initial commit.
 func ensureRepoHasCommits(ctx context.Context, gitSvc *git.Service, stdin io.Reader, stdout io.Writer) error {
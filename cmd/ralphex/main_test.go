@@ -3,10 +3,15 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -17,9 +22,11 @@ import (
 	"github.com/umputun/ralphex/pkg/config"
 	"github.com/umputun/ralphex/pkg/git"
 	gitmocks "github.com/umputun/ralphex/pkg/git/mocks"
+	"github.com/umputun/ralphex/pkg/hooks"
 	"github.com/umputun/ralphex/pkg/notify"
 	"github.com/umputun/ralphex/pkg/plan"
 	"github.com/umputun/ralphex/pkg/processor"
+	procmocks "github.com/umputun/ralphex/pkg/processor/mocks"
 	"github.com/umputun/ralphex/pkg/progress"
 	"github.com/umputun/ralphex/pkg/status"
 )
@@ -96,19 +103,32 @@ func TestPromptPlanDescription(t *testing.T) {
 	})
 }
 
+func TestFoldCodexOnly(t *testing.T) {
+	o, used := foldCodexOnly(opts{CodexOnly: true, Review: true})
+	assert.True(t, used)
+	assert.True(t, o.ExternalOnly)
+	assert.False(t, o.CodexOnly)
+	assert.True(t, o.Review, "other flags are kept")
+
+	o, used = foldCodexOnly(opts{ExternalOnly: true})
+	assert.False(t, used)
+	assert.Equal(t, opts{ExternalOnly: true}, o)
+}
+
 func TestDetermineMode(t *testing.T) {
 	tests := []struct {
-		name     string
-		opts     opts
-		expected processor.Mode
+		name        string
+		opts        opts
+		defaultMode processor.Mode
+		expected    processor.Mode
 	}{
 		{name: "default_is_full", opts: opts{}, expected: processor.ModeFull},
 		{name: "review_flag", opts: opts{Review: true}, expected: processor.ModeReview},
-		{name: "codex_only_flag", opts: opts{CodexOnly: true}, expected: processor.ModeCodexOnly},
-		{name: "external_only_flag", opts: opts{ExternalOnly: true}, expected: processor.ModeCodexOnly},
-		{name: "both_external_and_codex_flags", opts: opts{ExternalOnly: true, CodexOnly: true}, expected: processor.ModeCodexOnly},
-		{name: "codex_only_takes_precedence_over_review", opts: opts{Review: true, CodexOnly: true}, expected: processor.ModeCodexOnly},
-		{name: "external_only_takes_precedence_over_review", opts: opts{Review: true, ExternalOnly: true}, expected: processor.ModeCodexOnly},
+		{name: "codex_only_flag", opts: opts{CodexOnly: true}, expected: processor.ModeExternalOnly},
+		{name: "external_only_flag", opts: opts{ExternalOnly: true}, expected: processor.ModeExternalOnly},
+		{name: "both_external_and_codex_flags", opts: opts{ExternalOnly: true, CodexOnly: true}, expected: processor.ModeExternalOnly},
+		{name: "codex_only_takes_precedence_over_review", opts: opts{Review: true, CodexOnly: true}, expected: processor.ModeExternalOnly},
+		{name: "external_only_takes_precedence_over_review", opts: opts{Review: true, ExternalOnly: true}, expected: processor.ModeExternalOnly},
 		{name: "tasks_only_flag", opts: opts{TasksOnly: true}, expected: processor.ModeTasksOnly},
 		{name: "tasks_only_takes_precedence_over_codex", opts: opts{TasksOnly: true, CodexOnly: true}, expected: processor.ModeTasksOnly},
 		{name: "tasks_only_takes_precedence_over_external", opts: opts{TasksOnly: true, ExternalOnly: true}, expected: processor.ModeTasksOnly},
@@ -118,14 +138,32 @@ func TestDetermineMode(t *testing.T) {
 		{name: "plan_takes_precedence_over_codex", opts: opts{PlanDescription: "add caching", CodexOnly: true}, expected: processor.ModePlan},
 		{name: "plan_takes_precedence_over_external", opts: opts{PlanDescription: "add caching", ExternalOnly: true}, expected: processor.ModePlan},
 		{name: "plan_takes_precedence_over_tasks_only", opts: opts{PlanDescription: "add caching", TasksOnly: true}, expected: processor.ModePlan},
+		{name: "configured_default", opts: opts{}, defaultMode: processor.ModeReview, expected: processor.ModeReview},
+		{name: "flag_overrides_configured_default", opts: opts{TasksOnly: true}, defaultMode: processor.ModeReview,
+			expected: processor.ModeTasksOnly},
+		{name: "plan_overrides_configured_default", opts: opts{PlanDescription: "add caching"}, defaultMode: processor.ModeExternalOnly,
+			expected: processor.ModePlan},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			result := determineMode(tc.opts)
+			o, _ := foldCodexOnly(tc.opts)
+			result, err := determineMode(o, tc.defaultMode)
+			require.NoError(t, err)
 			assert.Equal(t, tc.expected, result)
 		})
 	}
+
+	t.Run("start_phase_with_full_default", func(t *testing.T) {
+		result, err := determineMode(opts{StartPhase: "review"}, processor.ModeFull)
+		require.NoError(t, err)
+		assert.Equal(t, processor.ModeFull, result)
+	})
+
+	t.Run("start_phase_with_review_default", func(t *testing.T) {
+		_, err := determineMode(opts{StartPhase: "review"}, processor.ModeReview)
+		require.EqualError(t, err, "--start-phase is only used in full mode, the run is in review mode")
+	})
 }
 
 func TestIsWatchOnlyMode(t *testing.T) {
@@ -424,7 +462,7 @@ func TestCreateRunner(t *testing.T) {
 		defer log.Close()
 
 		// tests that codex-only mode code path runs without panic
-		req := executePlanRequest{Mode: processor.ModeCodexOnly, Config: cfg, DefaultBranch: "main"}
+		req := executePlanRequest{Mode: processor.ModeExternalOnly, Config: cfg, DefaultBranch: "main"}
 		runner := createRunner(req, o, log, holder)
 		assert.NotNil(t, runner)
 	})
@@ -478,6 +516,65 @@ func TestCreateRunner(t *testing.T) {
 	})
 }
 
+func TestCompletionTagName(t *testing.T) {
+	now := time.Date(2026, 1, 22, 10, 30, 0, 0, time.Local)
+	tests := []struct {
+		name     string
+		planFile string
+		want     string
+	}{
+		{name: "simple", planFile: "docs/plans/add-auth.md", want: "ralphex/add-auth-2026-01-22"},
+		{name: "date prefix stripped", planFile: "docs/plans/2026-01-20-add-auth.md", want: "ralphex/add-auth-2026-01-22"},
+		{name: "spaces and case", planFile: "docs/plans/Add User Auth.md", want: "ralphex/add-user-auth-2026-01-22"},
+		{name: "dots and symbols", planFile: "docs/plans/v1.2 fix: login!.md", want: "ralphex/v1-2-fix-login-2026-01-22"},
+		{name: "nothing usable", planFile: "docs/plans/!!!.md", want: "ralphex/plan-2026-01-22"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, completionTagName(tc.planFile, now))
+		})
+	}
+}
+
+func TestStatsExcludes(t *testing.T) {
+	req := executePlanRequest{PlanFile: "docs/plans/feature.md", Config: &config.Config{}}
+	assert.Equal(t, []string{"docs/plans/feature.md", ".ralphex/progress"},
+		statsExcludes(req, ".ralphex/progress/progress-feature.txt"))
+	assert.Equal(t, []string{".ralphex/progress"},
+		statsExcludes(executePlanRequest{Config: &config.Config{}}, ".ralphex/progress/progress-review.txt"))
+
+	req.Config.ReviewIncludePlan = true
+	assert.Nil(t, statsExcludes(req, ".ralphex/progress/progress-feature.txt"))
+}
+
+func TestCommitTrailer(t *testing.T) {
+	assert.Empty(t, commitTrailer(&config.Config{}))
+	assert.Equal(t, "Assisted-by: ralphex", commitTrailer(&config.Config{CommitTrailer: "Assisted-by: ralphex"}))
+	assert.Equal(t, "Generated-by: ralphex "+resolveVersion(),
+		commitTrailer(&config.Config{CommitTrailer: "Generated-by: ralphex {{VERSION}}"}))
+}
+
+func TestResolveSinceTag(t *testing.T) {
+	dir := setupTestRepo(t)
+	gitSvc, err := git.NewService(dir, noopLogger())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.Equal(t, "master", resolveSinceTag(gitSvc, "master", testColors(), &buf), "falls back without tags")
+	assert.Contains(t, buf.String(), "no ralphex/* tag found, reviewing against master")
+
+	tag := completionTagName("docs/plans/first.md", time.Now())
+	require.NoError(t, gitSvc.CreateTag(tag, "first"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "next.txt"), []byte("next\n"), 0o600))
+	runGit(t, dir, "add", "next.txt")
+	runGit(t, dir, "commit", "-m", "next plan work")
+
+	buf.Reset()
+	assert.Equal(t, tag, resolveSinceTag(gitSvc, "master", testColors(), &buf))
+	assert.Contains(t, buf.String(), "reviewing changes since tag "+tag)
+}
+
 func TestResolveDefaultBranch(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -562,6 +659,21 @@ func TestSkipFinalizeFlag(t *testing.T) {
 	})
 }
 
+func TestNoFinalizeCommitFlag(t *testing.T) {
+	t.Run("cli_enables", func(t *testing.T) {
+		cfg := &config.Config{}
+		applyCLIOverrides(opts{NoFinalizeCommit: true}, cfg)
+		assert.True(t, cfg.FinalizeNoCommit)
+		assert.True(t, cfg.FinalizeNoCommitSet)
+	})
+
+	t.Run("not_set_preserves_config", func(t *testing.T) {
+		cfg := &config.Config{FinalizeNoCommit: true, FinalizeNoCommitSet: true}
+		applyCLIOverrides(opts{}, cfg)
+		assert.True(t, cfg.FinalizeNoCommit, "config value should be preserved when CLI not set")
+	})
+}
+
 func TestWaitFlag(t *testing.T) {
 	t.Run("wait_cli_overrides_config", func(t *testing.T) {
 		cfg := &config.Config{WaitOnLimit: 10 * time.Minute, WaitOnLimitSet: true}
@@ -588,6 +700,35 @@ func TestWaitFlag(t *testing.T) {
 	})
 }
 
+func TestKeepWorktreeFlag(t *testing.T) {
+	t.Run("cli_enables", func(t *testing.T) {
+		cfg := &config.Config{}
+		applyCLIOverrides(opts{KeepWorktree: true}, cfg)
+		assert.True(t, cfg.KeepWorktreeOnFailure)
+	})
+
+	t.Run("not_set_preserves_config", func(t *testing.T) {
+		cfg := &config.Config{KeepWorktreeOnFailure: true, KeepWorktreeOnFailureSet: true}
+		applyCLIOverrides(opts{}, cfg)
+		assert.True(t, cfg.KeepWorktreeOnFailure, "config value should be preserved when CLI not set")
+	})
+}
+
+func TestRetryFailedReviewFlag(t *testing.T) {
+	t.Run("cli_overrides_config", func(t *testing.T) {
+		cfg := &config.Config{ReviewRetryCount: 1, ReviewRetryCountSet: true}
+		applyCLIOverrides(opts{RetryFailedReview: 3}, cfg)
+		assert.Equal(t, 3, cfg.ReviewRetryCount)
+		assert.True(t, cfg.ReviewRetryCountSet)
+	})
+
+	t.Run("zero_preserves_config", func(t *testing.T) {
+		cfg := &config.Config{ReviewRetryCount: 2, ReviewRetryCountSet: true}
+		applyCLIOverrides(opts{}, cfg)
+		assert.Equal(t, 2, cfg.ReviewRetryCount)
+	})
+}
+
 func TestSessionTimeoutFlag(t *testing.T) {
 	t.Run("cli_overrides_config", func(t *testing.T) {
 		cfg := &config.Config{SessionTimeout: 10 * time.Minute, SessionTimeoutSet: true}
@@ -614,6 +755,66 @@ func TestSessionTimeoutFlag(t *testing.T) {
 	})
 }
 
+func TestCheckStartPhase(t *testing.T) {
+	newReq := func(t *testing.T, dir string, finalize bool) executePlanRequest {
+		t.Helper()
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+		return executePlanRequest{GitSvc: gitSvc, DefaultBranch: "master", BaseRef: "master",
+			Config: &config.Config{FinalizeEnabled: finalize}}
+	}
+
+	t.Run("tasks and empty need no checks", func(t *testing.T) {
+		req := executePlanRequest{Config: &config.Config{}}
+		require.NoError(t, checkStartPhase("", req))
+		require.NoError(t, checkStartPhase(processor.StartTasks, req))
+	})
+
+	t.Run("rejects default branch", func(t *testing.T) {
+		err := checkStartPhase(processor.StartReview, newReq(t, setupTestRepo(t), false))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "existing feature branch")
+	})
+
+	t.Run("rejects feature branch without commits", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		runGit(t, dir, "checkout", "-b", "feature")
+		err := checkStartPhase(processor.StartCodex, newReq(t, dir, false))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no commits ahead of master")
+	})
+
+	t.Run("accepts feature branch with commits", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		runGit(t, dir, "checkout", "-b", "feature")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "work.txt"), []byte("done\n"), 0o600))
+		runGit(t, dir, "add", "work.txt")
+		runGit(t, dir, "commit", "-m", "add work")
+		require.NoError(t, checkStartPhase(processor.StartReview, newReq(t, dir, false)))
+		require.NoError(t, checkStartPhase(processor.StartFinalize, newReq(t, dir, true)))
+	})
+
+	t.Run("finalize requires finalize_enabled", func(t *testing.T) {
+		err := checkStartPhase(processor.StartFinalize, newReq(t, setupTestRepo(t), false))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "finalize_enabled")
+	})
+}
+
+func TestPlanModeModelFlag(t *testing.T) {
+	t.Run("cli_overrides_config", func(t *testing.T) {
+		cfg := &config.Config{PlanModel: "sonnet"}
+		applyCLIOverrides(opts{PlanModeModel: "opus"}, cfg)
+		assert.Equal(t, "opus", cfg.PlanModel)
+	})
+
+	t.Run("not_set_preserves_config", func(t *testing.T) {
+		cfg := &config.Config{PlanModel: "sonnet"}
+		applyCLIOverrides(opts{}, cfg)
+		assert.Equal(t, "sonnet", cfg.PlanModel, "config value should be preserved when CLI not set")
+	})
+}
+
 func TestGetCurrentBranch(t *testing.T) {
 	t.Run("returns_branch_name", func(t *testing.T) {
 		dir := setupTestRepo(t)
@@ -656,6 +857,42 @@ func TestValidateFlags(t *testing.T) {
 		{name: "negative_session_timeout_is_invalid", opts: opts{SessionTimeout: -10 * time.Minute}, wantErr: true, errMsg: "non-negative"},
 		{name: "positive_session_timeout_is_valid", opts: opts{SessionTimeout: 30 * time.Minute}, wantErr: false},
 		{name: "zero_session_timeout_is_valid", opts: opts{SessionTimeout: 0}, wantErr: false},
+		{name: "branch_name_is_valid", opts: opts{BranchName: "feat/login"}, wantErr: false},
+		{name: "branch_name_with_tasks_only_is_valid", opts: opts{BranchName: "feat/login", TasksOnly: true}, wantErr: false},
+		{name: "invalid_branch_name", opts: opts{BranchName: "bad name"}, wantErr: true, errMsg: "invalid --branch-name"},
+		{name: "branch_name_with_review_conflicts", opts: opts{BranchName: "feat", Review: true}, wantErr: true, errMsg: "only used when"},
+		{name: "branch_name_with_external_only_conflicts", opts: opts{BranchName: "feat", ExternalOnly: true}, wantErr: true, errMsg: "only used when"},
+		{name: "autostash_with_review_conflicts", opts: opts{Autostash: true, Review: true}, wantErr: true, errMsg: "--autostash is only used when"},
+		{name: "autostash_with_tasks_only_ok", opts: opts{Autostash: true, TasksOnly: true}, wantErr: false},
+		{name: "start_phase_review_is_valid", opts: opts{StartPhase: "review"}, wantErr: false},
+		{name: "start_phase_unknown", opts: opts{StartPhase: "deploy"}, wantErr: true, errMsg: "invalid --start-phase"},
+		{name: "start_phase_with_review_conflicts", opts: opts{StartPhase: "codex", Review: true}, wantErr: true, errMsg: "only used in full mode"},
+		{name: "start_phase_with_tasks_only_conflicts", opts: opts{StartPhase: "review", TasksOnly: true}, wantErr: true, errMsg: "only used in full mode"},
+		{name: "codex_config_is_valid", opts: opts{CodexConfig: []string{"model_verbosity=high", "features.web_search=true"}}, wantErr: false},
+		{name: "codex_config_malformed", opts: opts{CodexConfig: []string{"model_verbosity"}}, wantErr: true, errMsg: "expected key=value"},
+		{name: "codex_config_managed_key", opts: opts{CodexConfig: []string{"model=o3"}}, wantErr: true, errMsg: "use --force"},
+		{name: "codex_config_managed_key_with_force", opts: opts{CodexConfig: []string{"model=o3"}, Force: true}, wantErr: false},
+		{name: "reset_to_is_valid", opts: opts{ResetTo: "HEAD~1", Yes: true}, wantErr: false},
+		{name: "reset_to_with_planfile_conflicts", opts: opts{ResetTo: "HEAD~1", PlanFile: "docs/plans/test.md"}, wantErr: true, errMsg: "standalone action"},
+		{name: "reset_to_with_replay_conflicts", opts: opts{ResetTo: "HEAD~1", Replay: "progress.txt"}, wantErr: true, errMsg: "standalone action"},
+		{name: "inline_plan_is_valid", opts: opts{InlinePlan: "# Fix\n### Task 1\n- [ ] do X"}, wantErr: false},
+		{name: "inline_plan_with_planfile_conflicts", opts: opts{InlinePlan: "# Fix", PlanFile: "docs/plans/test.md"}, wantErr: true, errMsg: "--inline-plan conflicts"},
+		{name: "inline_plan_with_plan_conflicts", opts: opts{InlinePlan: "# Fix", PlanDescription: "add feature"}, wantErr: true, errMsg: "--inline-plan conflicts"},
+		{name: "inline_plan_with_replay_conflicts", opts: opts{InlinePlan: "# Fix", Replay: "progress.txt"}, wantErr: true, errMsg: "--replay conflicts"},
+		{name: "since_tag_with_base_ref_conflicts", opts: opts{SinceTag: true, BaseRef: "main"}, wantErr: true, errMsg: "--since-tag conflicts with --base-ref"},
+		{name: "since_tag_alone_is_valid", opts: opts{SinceTag: true}, wantErr: false},
+		{name: "replay_only_is_valid", opts: opts{Replay: "progress.txt", ReplayRealtime: true}, wantErr: false},
+		{name: "replay_with_planfile_conflicts", opts: opts{Replay: "progress.txt", PlanFile: "docs/plans/test.md"}, wantErr: true, errMsg: "conflicts"},
+		{name: "replay_with_plan_conflicts", opts: opts{Replay: "progress.txt", PlanDescription: "add feature"}, wantErr: true, errMsg: "conflicts"},
+		{name: "replay_realtime_without_replay", opts: opts{ReplayRealtime: true}, wantErr: true, errMsg: "requires --replay"},
+		{name: "explain_with_external_only_is_valid", opts: opts{Explain: true, ExternalOnly: true}, wantErr: false},
+		{name: "only_with_dump_defaults_is_valid", opts: opts{DumpDefaults: "-", Only: "task-prompt"}, wantErr: false},
+		{name: "only_without_dump_defaults", opts: opts{Only: "task-prompt"}, wantErr: true, errMsg: "--only requires --dump-defaults"},
+		{name: "dump_defaults_stdout_without_only", opts: opts{DumpDefaults: "-"}, wantErr: true, errMsg: "requires --only"},
+		{name: "explain_with_inline_plan_conflicts", opts: opts{Explain: true, InlinePlan: "# Fix"}, wantErr: true, errMsg: "--explain describes a plan run"},
+		{name: "estimate_with_review_is_valid", opts: opts{Estimate: true, Review: true}, wantErr: false},
+		{name: "estimate_with_explain_conflicts", opts: opts{Estimate: true, Explain: true}, wantErr: true, errMsg: "--estimate estimates a plan run"},
+		{name: "estimate_with_plan_conflicts", opts: opts{Estimate: true, PlanDescription: "add auth"}, wantErr: true, errMsg: "--estimate estimates a plan run"},
 	}
 
 	for _, tc := range tests {
@@ -697,6 +934,30 @@ func TestPrintStartupInfo(t *testing.T) {
 		// verify it doesn't panic with empty plan
 		printStartupInfo(info, colors)
 	})
+
+	t.Run("prints_model_for_plan_mode", func(t *testing.T) {
+		for _, model := range []string{"", "opus"} {
+			info := startupInfo{
+				PlanDescription: "add caching",
+				Branch:          "master",
+				Mode:            processor.ModePlan,
+				MaxIterations:   50,
+				ProgressPath:    "progress-plan.txt",
+				Model:           model,
+			}
+			// verify it doesn't panic with and without an explicit model
+			printStartupInfo(info, colors)
+		}
+	})
+}
+
+func TestFormatPhaseNames(t *testing.T) {
+	assert.Empty(t, formatPhaseNames(nil))
+	assert.Equal(t, "task=Implementation, codex=Second Opinion, finalize=Wrap-up", formatPhaseNames(status.PhaseNames{
+		status.PhaseFinalize: "Wrap-up",
+		status.PhaseCodex:    "Second Opinion",
+		status.PhaseTask:     "Implementation",
+	}), "listed in pipeline order")
 }
 
 func TestToRelPath(t *testing.T) {
@@ -745,6 +1006,231 @@ func noopLogger() *gitmocks.LoggerMock {
 	}
 }
 
+func TestAbortInProgressOperation(t *testing.T) {
+	// setupMergeConflict leaves the repo in a conflicted merge between master and "feature".
+	// with killedRun set, the lock of a dead ralphex run is written first, as if the run started the merge.
+	setupMergeConflict := func(t *testing.T, killedRun bool) string {
+		t.Helper()
+		dir := setupTestRepo(t)
+		if killedRun {
+			writeRunLock(t, dir, deadPID(t), "docs/plans/feature.md")
+		}
+		runGit(t, dir, "checkout", "-b", "feature")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("feature\n"), 0o600))
+		runGit(t, dir, "commit", "-am", "feature change")
+		runGit(t, dir, "checkout", "master")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("master\n"), 0o600))
+		runGit(t, dir, "commit", "-am", "master change")
+		cmd := exec.Command("git", "merge", "feature")
+		cmd.Dir = dir
+		_, err := cmd.CombinedOutput()
+		require.Error(t, err, "merge should conflict")
+		return dir
+	}
+
+	t.Run("clean repo does nothing", func(t *testing.T) {
+		gitSvc, err := git.NewService(setupTestRepo(t), noopLogger())
+		require.NoError(t, err)
+
+		var stdout bytes.Buffer
+		require.NoError(t, abortInProgressOperation(t.Context(), gitSvc, processor.ModeFull, strings.NewReader(""), &stdout))
+		assert.Empty(t, stdout.String())
+	})
+
+	t.Run("aborts when user answers yes", func(t *testing.T) {
+		gitSvc, err := git.NewService(setupMergeConflict(t, true), noopLogger())
+		require.NoError(t, err)
+
+		var stdout bytes.Buffer
+		require.NoError(t, abortInProgressOperation(t.Context(), gitSvc, processor.ModeFull, strings.NewReader("y\n"), &stdout))
+		assert.Contains(t, stdout.String(), "repository has an in-progress merge left by an interrupted ralphex run "+
+			"(plan docs/plans/feature.md")
+
+		op, err := gitSvc.InProgressOperation()
+		require.NoError(t, err)
+		assert.Empty(t, op)
+	})
+
+	t.Run("returns error when user answers no", func(t *testing.T) {
+		gitSvc, err := git.NewService(setupMergeConflict(t, true), noopLogger())
+		require.NoError(t, err)
+
+		var stdout bytes.Buffer
+		err = abortInProgressOperation(t.Context(), gitSvc, processor.ModeFull, strings.NewReader("n\n"), &stdout)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "in-progress merge must be finished or aborted")
+
+		op, err := gitSvc.InProgressOperation()
+		require.NoError(t, err)
+		assert.Equal(t, git.OpMerge, op, "declined abort must leave the merge untouched")
+	})
+
+	t.Run("refuses without prompt when the operation is not ralphex's", func(t *testing.T) {
+		dir := setupMergeConflict(t, false)
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+		check := func() {
+			var stdout bytes.Buffer
+			err := abortInProgressOperation(t.Context(), gitSvc, processor.ModeFull, strings.NewReader("y\n"), &stdout)
+			require.EqualError(t, err, "repository has an in-progress merge, finish it (git merge --continue) "+
+				"or abort it (git merge --abort) before running ralphex")
+			assert.Empty(t, stdout.String(), "no abort offered")
+			op, err := gitSvc.InProgressOperation()
+			require.NoError(t, err)
+			assert.Equal(t, git.OpMerge, op)
+		}
+
+		check() // no ralphex run was interrupted
+		writeRunLock(t, dir, os.Getppid(), "docs/plans/other.md")
+		check() // the run holding the lock is still active
+		lock := runLockInfo{PID: deadPID(t), Plan: "docs/plans/later.md", Started: time.Now().Add(time.Hour)}
+		data, err := json.Marshal(lock)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(runLockPath(dir), data, 0o600))
+		check() // the merge started before the interrupted run
+	})
+
+	t.Run("warns without prompt in modes that don't create a branch", func(t *testing.T) {
+		gitSvc, err := git.NewService(setupMergeConflict(t, false), noopLogger())
+		require.NoError(t, err)
+
+		var stdout bytes.Buffer
+		require.NoError(t, abortInProgressOperation(t.Context(), gitSvc, processor.ModeReview, strings.NewReader("y\n"), &stdout))
+		assert.Equal(t, "warning: repository has an in-progress merge, review mode continues without touching it\n",
+			stdout.String())
+		op, err := gitSvc.InProgressOperation()
+		require.NoError(t, err)
+		assert.Equal(t, git.OpMerge, op, "user's merge must be left untouched")
+	})
+
+	t.Run("recognizes the plan lock of an interrupted worktree run", func(t *testing.T) {
+		dir := t.TempDir()
+		lockPath := planLockPath(dir, "docs/plans/feature.md")
+		require.NoError(t, os.MkdirAll(filepath.Dir(lockPath), 0o750))
+		data, err := json.Marshal(runLockInfo{PID: deadPID(t), Plan: "docs/plans/feature.md", Started: time.Now()})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(lockPath, data, 0o600))
+
+		holder, stale := staleRunLock(dir, time.Now().Add(time.Minute))
+		require.True(t, stale)
+		assert.Equal(t, "docs/plans/feature.md", holder.Plan)
+
+		_, stale = staleRunLock(dir, time.Now().Add(-time.Hour))
+		assert.False(t, stale, "operation started before the run")
+	})
+}
+
+func TestResetToRef(t *testing.T) {
+	// setupFeatureBranch creates a feature branch with one commit on top of master, returns the repo dir.
+	setupFeatureBranch := func(t *testing.T) string {
+		t.Helper()
+		dir := setupTestRepo(t)
+		runGit(t, dir, "checkout", "-b", "feature")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.txt"), []byte("bad\n"), 0o600))
+		runGit(t, dir, "add", "bad.txt")
+		runGit(t, dir, "commit", "-m", "bad change")
+		return dir
+	}
+
+	tests := []struct {
+		name      string
+		yes       bool
+		answer    string
+		wantReset bool
+		wantOut   string
+	}{
+		{name: "confirmed", answer: "y\n", wantReset: true, wantOut: "reset the branch? [y/N]"},
+		{name: "declined", answer: "n\n", wantReset: false, wantOut: "reset canceled"},
+		{name: "yes flag skips prompt", yes: true, wantReset: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := setupFeatureBranch(t)
+			gitSvc, err := git.NewService(dir, noopLogger())
+			require.NoError(t, err)
+
+			var stdout bytes.Buffer
+			require.NoError(t, resetToRef(t.Context(), gitSvc, "HEAD~1", "master", tc.yes, strings.NewReader(tc.answer), &stdout))
+			assert.Contains(t, stdout.String(), `resetting branch "feature" to HEAD~1`)
+			assert.Contains(t, stdout.String(), tc.wantOut)
+			if tc.yes {
+				assert.NotContains(t, stdout.String(), "[y/N]")
+			}
+			_, statErr := os.Stat(filepath.Join(dir, "bad.txt"))
+			assert.Equal(t, tc.wantReset, os.IsNotExist(statErr))
+		})
+	}
+
+	t.Run("refuses default branch", func(t *testing.T) {
+		gitSvc, err := git.NewService(setupTestRepo(t), noopLogger())
+		require.NoError(t, err)
+
+		var stdout bytes.Buffer
+		err = resetToRef(t.Context(), gitSvc, "HEAD", "", true, strings.NewReader(""), &stdout)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "refusing to reset default branch")
+	})
+
+	t.Run("refuses configured default branch", func(t *testing.T) {
+		dir := setupFeatureBranch(t)
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+
+		var stdout bytes.Buffer
+		err = resetToRef(t.Context(), gitSvc, "HEAD~1", "feature", true, strings.NewReader(""), &stdout)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `refusing to reset default branch "feature"`)
+		_, statErr := os.Stat(filepath.Join(dir, "bad.txt"))
+		require.NoError(t, statErr, "branch must be left intact")
+	})
+}
+
+func TestCheckShallowClone(t *testing.T) {
+	// shallowClone clones a two-commit repo with depth 1
+	shallowClone := func(t *testing.T) *git.Service {
+		t.Helper()
+		src := setupTestRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(src, "a.txt"), []byte("a\n"), 0o600))
+		runGit(t, src, "add", "a.txt")
+		runGit(t, src, "commit", "-m", "second commit")
+		dir := filepath.Join(t.TempDir(), "clone")
+		runGit(t, t.TempDir(), "clone", "--depth", "1", "file://"+src, dir)
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+		return gitSvc
+	}
+
+	t.Run("full clone is silent", func(t *testing.T) {
+		gitSvc, err := git.NewService(setupTestRepo(t), noopLogger())
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		checkShallowClone(gitSvc, false, "master", testColors(), &buf)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("shallow clone warns", func(t *testing.T) {
+		gitSvc := shallowClone(t)
+		var buf bytes.Buffer
+		checkShallowClone(gitSvc, false, "master", testColors(), &buf)
+		assert.Contains(t, buf.String(), "warning: shallow clone, review diffs against master may be incomplete")
+		assert.Contains(t, buf.String(), "git fetch --unshallow")
+		shallow, err := gitSvc.IsShallow()
+		require.NoError(t, err)
+		assert.True(t, shallow)
+	})
+
+	t.Run("auto unshallow fetches history", func(t *testing.T) {
+		gitSvc := shallowClone(t)
+		var buf bytes.Buffer
+		checkShallowClone(gitSvc, true, "master", testColors(), &buf)
+		assert.Empty(t, buf.String())
+		shallow, err := gitSvc.IsShallow()
+		require.NoError(t, err)
+		assert.False(t, shallow)
+	})
+}
+
 func TestEnsureRepoHasCommits(t *testing.T) {
 	t.Run("returns nil for repo with commits", func(t *testing.T) {
 		dir := setupTestRepo(t)
@@ -839,6 +1325,44 @@ func TestEnsureRepoHasCommits(t *testing.T) {
 	})
 }
 
+func TestAutostash(t *testing.T) {
+	skipIfClaudeNotAvailable(t)
+
+	dir := setupTestRepo(t)
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+
+	// untracked plan file plus unrelated local edits that would block branch creation
+	require.NoError(t, os.MkdirAll("docs/plans", 0o750))
+	planPath := filepath.Join(dir, "docs", "plans", "stash-plan.md")
+	require.NoError(t, os.WriteFile(planPath, []byte("# Stash Plan\n\n### Task 1: one\n- [ ] task 1\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Local edit\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scratch.txt"), []byte("notes\n"), 0o600))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	o := opts{TasksOnly: true, Autostash: true, Yes: true, PlanFile: planPath, MaxIterations: 1, NoColor: true, ConfigDir: t.TempDir()}
+	_ = run(ctx, o)
+
+	gitSvc, err := git.NewService(dir, noopLogger())
+	require.NoError(t, err)
+	branch, err := gitSvc.CurrentBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "stash-plan", branch, "branch should be created despite local edits")
+
+	// local edits are restored on the feature branch and the stash is empty again
+	data, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Local edit\n", string(data))
+	assert.FileExists(t, filepath.Join(dir, "scratch.txt"))
+	stashList, err := exec.Command("git", "-C", dir, "stash", "list").Output()
+	require.NoError(t, err)
+	assert.Empty(t, strings.TrimSpace(string(stashList)))
+}
+
 func TestTasksOnlyModeBranchCreation(t *testing.T) {
 	t.Run("tasks_only_creates_branch_for_plan", func(t *testing.T) {
 		skipIfClaudeNotAvailable(t)
@@ -994,7 +1518,7 @@ func TestModeRequiresBranch(t *testing.T) {
 		{processor.ModeFull, true},
 		{processor.ModeTasksOnly, true},
 		{processor.ModeReview, false},
-		{processor.ModeCodexOnly, false},
+		{processor.ModeExternalOnly, false},
 		{processor.ModePlan, false},
 	}
 
@@ -1052,6 +1576,56 @@ func TestExecutePlanRequestHasNotifySvc(t *testing.T) {
 	req.NotifySvc.Send(t.Context(), notify.Result{Status: "success"})
 }
 
+func TestRunServeCheck(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, runServeCheck(t.Context(), opts{}, &config.Config{}, &buf))
+	out := buf.String()
+	assert.Contains(t, out, "  index (/): ok\n")
+	assert.Contains(t, out, "  status (/api/status): ok\n")
+	assert.Contains(t, out, "  stream (/events): ok\n")
+	assert.Contains(t, out, "web dashboard check passed, 6 endpoints ok\n")
+}
+
+func TestRunNotifyTest(t *testing.T) {
+	newSvc := func(t *testing.T, code int) *notify.Service {
+		t.Helper()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(code)
+		}))
+		t.Cleanup(srv.Close)
+		svc, err := notify.New(notify.Params{Channels: []string{"webhook"}, WebhookURLs: []string{srv.URL}}, stderrLog{})
+		require.NoError(t, err)
+		return svc
+	}
+
+	t.Run("no_channels", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := runNotifyTest(t.Context(), nil, nil, &buf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no notification channels configured")
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := runNotifyTest(t.Context(), newSvc(t, http.StatusOK), []string{"webhook"}, &buf)
+		require.NoError(t, err)
+		out := buf.String()
+		assert.Contains(t, out, "configured channels: webhook")
+		assert.Contains(t, out, "  webhook 127.0.0.1")
+		assert.Contains(t, out, ": ok")
+		assert.Contains(t, out, "test notification sent to 1 channel(s)")
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := runNotifyTest(t.Context(), newSvc(t, http.StatusInternalServerError), []string{"webhook"}, &buf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "test notification failed for 1 of 1 channel(s)")
+		assert.Contains(t, buf.String(), ": failed, ")
+	})
+}
+
 // runGit executes a git command in the given directory and fails the test on error.
 func runGit(t *testing.T, dir string, args ...string) {
 	t.Helper()
@@ -1149,6 +1723,20 @@ func TestConfigDirCustomPath(t *testing.T) {
 	})
 }
 
+func TestDumpSchema(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, dumpSchema(&buf))
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &schema))
+	assert.Equal(t, "object", schema["type"])
+	assert.Contains(t, schema["properties"], "codex_sandbox")
+
+	t.Run("handled_as_early_flag", func(t *testing.T) {
+		assert.False(t, isResetOnly(opts{DumpSchema: true}))
+	})
+}
+
 func TestDumpDefaults(t *testing.T) {
 	t.Run("extracts_files_to_target_dir", func(t *testing.T) {
 		tmpDir := filepath.Join(t.TempDir(), "defaults")
@@ -1194,6 +1782,42 @@ func TestDumpDefaults(t *testing.T) {
 	})
 }
 
+func TestDumpDefault(t *testing.T) {
+	t.Run("single_file_to_dir", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "defaults")
+		var buf bytes.Buffer
+		require.NoError(t, dumpDefault(dir, "task-prompt", &buf))
+
+		path := filepath.Join(dir, "prompts", "task.txt")
+		assert.FileExists(t, path)
+		assert.NoFileExists(t, filepath.Join(dir, "config"))
+		assert.Equal(t, "default task-prompt extracted to "+path+"\n", buf.String())
+	})
+
+	t.Run("single_file_to_stdout", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, dumpDefault("-", "quality-agent", &buf))
+		assert.Contains(t, buf.String(), "security issues")
+	})
+
+	t.Run("unknown_name", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := dumpDefault(t.TempDir(), "nope", &buf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown default "nope", run --list-defaults`)
+	})
+}
+
+func TestListDefaults(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, listDefaults(&buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "config", lines[0])
+	assert.Contains(t, lines, "task-prompt")
+	assert.Contains(t, lines, "documentation-agent")
+	assert.False(t, isResetOnly(opts{ListDefaults: true}))
+}
+
 func TestHandleEarlyFlags(t *testing.T) {
 	t.Run("no_flags_continues", func(t *testing.T) {
 		done, err := handleEarlyFlags(opts{})
@@ -1209,6 +1833,15 @@ func TestHandleEarlyFlags(t *testing.T) {
 		assert.FileExists(t, filepath.Join(tmpDir, "config"))
 	})
 
+	t.Run("dump_defaults_only_exits", func(t *testing.T) {
+		tmpDir := filepath.Join(t.TempDir(), "defaults")
+		done, err := handleEarlyFlags(opts{DumpDefaults: tmpDir, Only: "config"})
+		require.NoError(t, err)
+		assert.True(t, done)
+		assert.FileExists(t, filepath.Join(tmpDir, "config"))
+		assert.NoDirExists(t, filepath.Join(tmpDir, "prompts"))
+	})
+
 	t.Run("dump_defaults_error", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		blocker := filepath.Join(tmpDir, "blocker")
@@ -1220,42 +1853,250 @@ func TestHandleEarlyFlags(t *testing.T) {
 	})
 }
 
-func TestIsResetOnly(t *testing.T) {
-	t.Run("reset_only", func(t *testing.T) {
-		assert.True(t, isResetOnly(opts{Reset: true}))
-	})
+func TestPrintPlanSummary(t *testing.T) {
+	t.Run("prints parsed summary", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# My Plan\n\n### Task 1: First\n- [ ] do it\n"), 0o600))
 
-	t.Run("reset_with_plan_file", func(t *testing.T) {
-		assert.False(t, isResetOnly(opts{Reset: true, PlanFile: "plan.md"}))
+		var buf bytes.Buffer
+		require.NoError(t, printPlanSummary(planFile, &buf))
+		assert.Contains(t, buf.String(), "My Plan")
+		assert.Contains(t, buf.String(), "[ ] Task 1: First (0/1)")
 	})
 
-	t.Run("reset_with_dump_defaults", func(t *testing.T) {
-		assert.False(t, isResetOnly(opts{Reset: true, DumpDefaults: "/tmp/dir"}))
+	t.Run("missing file returns error", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := printPlanSummary(filepath.Join(t.TempDir(), "missing.md"), &buf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "plan summary")
 	})
 
-	t.Run("reset_with_review", func(t *testing.T) {
-		assert.False(t, isResetOnly(opts{Reset: true, Review: true}))
+	t.Run("handled as early flag", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# My Plan\n"), 0o600))
+		done, err := handleEarlyFlags(opts{PlanSummary: planFile})
+		require.NoError(t, err)
+		assert.True(t, done)
 	})
 }
 
-func TestResolveVersion(t *testing.T) {
-	t.Run("ldflags_set", func(t *testing.T) {
-		orig := revision
-		t.Cleanup(func() { revision = orig })
-		revision = "v1.2.3-abc1234"
-		assert.Equal(t, "v1.2.3-abc1234", resolveVersion())
+func TestPrintPlanWarnings(t *testing.T) {
+	t.Run("prints warnings", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("### Task 1: First\n- [ ] do it\n"), 0o600))
+
+		var buf bytes.Buffer
+		assert.False(t, printPlanWarnings(planFile, 20, testColors(), &buf), "lint warnings are not size warnings")
+		assert.Contains(t, buf.String(), "plan plan.md has 1 warning(s)")
+		assert.Contains(t, buf.String(), "  - empty_title: plan has no title")
 	})
 
-	t.Run("fallback_to_build_info", func(t *testing.T) {
-		orig := revision
-		t.Cleanup(func() { revision = orig })
-		revision = "unknown"
-		// in test context, debug.ReadBuildInfo returns (devel) module version
-		// but VCS info should be available from the git repo
-		v := resolveVersion()
-		assert.NotEmpty(t, v)
+	t.Run("reports too many tasks", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		content := "# Big Plan\n\n### Task 1: One\n- [ ] a\n\n### Task 2: Two\n- [ ] b\n\n### Task 3: Three\n- [ ] c\n"
+		require.NoError(t, os.WriteFile(planFile, []byte(content), 0o600))
+
+		var buf bytes.Buffer
+		assert.True(t, printPlanWarnings(planFile, 2, testColors(), &buf))
+		assert.Contains(t, buf.String(), "  - too_many_tasks: plan has 3 tasks (limit 2)")
+
+		buf.Reset()
+		assert.False(t, printPlanWarnings(planFile, 0, testColors(), &buf), "0 disables the task limit")
+		assert.Empty(t, buf.String())
 	})
-}
+
+	t.Run("silent for valid plan", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# My Plan\n\n### Task 1: First\n- [ ] do it\n"), 0o600))
+
+		var buf bytes.Buffer
+		printPlanWarnings(planFile, 20, testColors(), &buf)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("silent for missing file", func(t *testing.T) {
+		var buf bytes.Buffer
+		printPlanWarnings(filepath.Join(t.TempDir(), "missing.md"), 20, testColors(), &buf)
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestPreflightTreeStatus(t *testing.T) {
+	t.Run("clean tree proceeds silently", func(t *testing.T) {
+		gitSvc, err := git.NewService(setupTestRepo(t), noopLogger())
+		require.NoError(t, err)
+
+		var stdout bytes.Buffer
+		summary, proceed := preflightTreeStatus(t.Context(), opts{}, gitSvc, testColors(), strings.NewReader(""), &stdout, true)
+		assert.True(t, proceed)
+		assert.Empty(t, summary)
+		assert.Empty(t, stdout.String())
+	})
+
+	dirtyRepo := func(t *testing.T) *git.Service {
+		t.Helper()
+		dir := setupTestRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Test\nmore\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "stray.txt"), []byte("a\nb\nc\n"), 0o600))
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+		return gitSvc
+	}
+
+	tests := []struct {
+		name        string
+		o           opts
+		interactive bool
+		answer      string
+		wantProceed bool
+		wantPrompt  bool
+	}{
+		{name: "non-interactive proceeds without prompt", interactive: false, wantProceed: true},
+		{name: "yes flag skips prompt", o: opts{Yes: true}, interactive: true, wantProceed: true},
+		{name: "interactive confirmed", interactive: true, answer: "y\n", wantProceed: true, wantPrompt: true},
+		{name: "interactive declined", interactive: true, answer: "n\n", wantProceed: false, wantPrompt: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			summary, proceed := preflightTreeStatus(t.Context(), tc.o, dirtyRepo(t), testColors(),
+				strings.NewReader(tc.answer), &stdout, tc.interactive)
+			assert.Equal(t, tc.wantProceed, proceed)
+			assert.Contains(t, summary, "working tree has 2 uncommitted file(s), ~+4/-0 lines:")
+			assert.Contains(t, stdout.String(), "stray.txt (+3/-0)")
+			assert.Equal(t, tc.wantPrompt, strings.Contains(stdout.String(), "proceed with these uncommitted changes?"))
+		})
+	}
+}
+
+func TestConfirmReopenPlan(t *testing.T) {
+	planFile := filepath.Join("docs", "plans", "completed", "add-auth.md")
+	tests := []struct {
+		name        string
+		o           opts
+		interactive bool
+		answer      string
+		wantConfirm bool
+		wantPrompt  bool
+		wantErr     string
+	}{
+		{name: "non-interactive refused", interactive: false, wantErr: "pass --yes to reopen it"},
+		{name: "yes flag confirms without prompt", o: opts{Yes: true}, wantConfirm: true},
+		{name: "interactive confirmed", interactive: true, answer: "y\n", wantConfirm: true, wantPrompt: true},
+		{name: "interactive declined", interactive: true, answer: "n\n", wantPrompt: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			confirmed, err := confirmReopenPlan(t.Context(), tc.o, planFile, strings.NewReader(tc.answer), &stdout, tc.interactive)
+			assert.Equal(t, tc.wantPrompt, strings.Contains(stdout.String(), "plan add-auth.md is already completed"))
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantConfirm, confirmed)
+		})
+	}
+}
+
+func TestReopenPlan(t *testing.T) {
+	dir := setupTestRepo(t)
+	planFile := filepath.Join(dir, "docs", "plans", "completed", "add-auth.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(planFile), 0o750))
+	require.NoError(t, os.WriteFile(planFile, []byte("# Add auth\n"), 0o600))
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "complete plan")
+	runGit(t, dir, "checkout", "-b", "add-auth")
+	gitSvc, err := git.NewService(dir, noopLogger())
+	require.NoError(t, err)
+
+	reopened, err := reopenPlan(gitSvc, testColors(), planFile)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "docs", "plans", "add-auth.md"), reopened)
+	assert.FileExists(t, reopened)
+	assert.NoFileExists(t, planFile)
+	out, err := exec.Command("git", "-C", dir, "log", "-1", "--format=%s", "add-auth").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "reopen plan: add-auth.md", strings.TrimSpace(string(out)), "committed on the feature branch")
+
+	_, err = reopenPlan(gitSvc, testColors(), planFile)
+	require.ErrorContains(t, err, "reopen plan:")
+}
+
+func TestIsCompletedPlan(t *testing.T) {
+	assert.True(t, isCompletedPlan(filepath.Join("docs", "plans", "completed", "a.md")))
+	assert.False(t, isCompletedPlan(filepath.Join("docs", "plans", "a.md")))
+	assert.False(t, isCompletedPlan(""))
+}
+
+func TestFormatTreeStatus(t *testing.T) {
+	t.Run("lists files with counts", func(t *testing.T) {
+		got := formatTreeStatus([]git.FileChange{
+			{Path: "a.go", Status: "M", Additions: 3, Deletions: 1},
+			{Path: "b.txt", Status: "??", Additions: 2},
+		})
+		assert.Equal(t, "working tree has 2 uncommitted file(s), ~+5/-1 lines:\n  M  a.go (+3/-1)\n  ?? b.txt (+2/-0)", got)
+	})
+
+	t.Run("truncates long lists", func(t *testing.T) {
+		changes := make([]git.FileChange, maxPreflightFiles+5)
+		for i := range changes {
+			changes[i] = git.FileChange{Path: fmt.Sprintf("f%d.txt", i), Status: "??", Additions: 1}
+		}
+		got := formatTreeStatus(changes)
+		assert.Contains(t, got, fmt.Sprintf("%d uncommitted file(s), ~+%d/-0", len(changes), len(changes)))
+		assert.Contains(t, got, "  ... and 5 more")
+		assert.NotContains(t, got, fmt.Sprintf("f%d.txt", maxPreflightFiles))
+	})
+}
+
+func TestIsResetOnly(t *testing.T) {
+	t.Run("reset_only", func(t *testing.T) {
+		assert.True(t, isResetOnly(opts{Reset: true}))
+	})
+
+	t.Run("reset_with_plan_file", func(t *testing.T) {
+		assert.False(t, isResetOnly(opts{Reset: true, PlanFile: "plan.md"}))
+	})
+
+	t.Run("reset_with_reset_to", func(t *testing.T) {
+		assert.False(t, isResetOnly(opts{Reset: true, ResetTo: "HEAD~1"}))
+	})
+
+	t.Run("reset_with_dump_defaults", func(t *testing.T) {
+		assert.False(t, isResetOnly(opts{Reset: true, DumpDefaults: "/tmp/dir"}))
+	})
+
+	t.Run("reset_with_review", func(t *testing.T) {
+		assert.False(t, isResetOnly(opts{Reset: true, Review: true}))
+	})
+
+	t.Run("reset_with_replay", func(t *testing.T) {
+		assert.False(t, isResetOnly(opts{Reset: true, Replay: "progress.txt"}))
+	})
+}
+
+func TestResolveVersion(t *testing.T) {
+	t.Run("ldflags_set", func(t *testing.T) {
+		orig := revision
+		t.Cleanup(func() { revision = orig })
+		revision = "v1.2.3-abc1234"
+		assert.Equal(t, "v1.2.3-abc1234", resolveVersion())
+	})
+
+	t.Run("fallback_to_build_info", func(t *testing.T) {
+		orig := revision
+		t.Cleanup(func() { revision = orig })
+		revision = "unknown"
+		// in test context, debug.ReadBuildInfo returns (devel) module version
+		// but VCS info should be available from the git repo
+		v := resolveVersion()
+		assert.NotEmpty(t, v)
+	})
+}
 
 func TestRunWithWorktree(t *testing.T) {
 	t.Run("creates_worktree_and_restores_cwd", func(t *testing.T) {
@@ -1309,6 +2150,48 @@ func TestRunWithWorktree(t *testing.T) {
 		assert.True(t, branchExists(t, dir, "wt-test"), "branch should be preserved after worktree removal")
 	})
 
+	t.Run("keeps_worktree_on_failure", func(t *testing.T) {
+		skipIfClaudeNotAvailable(t)
+
+		dir := setupTestRepo(t)
+		origDir, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(dir))
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+
+		resolvedDir, err := filepath.EvalSymlinks(dir)
+		require.NoError(t, err)
+
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs", "plans"), 0o750))
+		planPath := filepath.Join(dir, "docs", "plans", "wt-keep.md")
+		require.NoError(t, os.WriteFile(planPath, []byte("# WT Keep\n\n- [ ] task 1\n"), 0o600))
+		runGit(t, dir, "add", "docs/plans/wt-keep.md")
+		runGit(t, dir, "commit", "-m", "add wt keep plan")
+
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+
+		cfg := &config.Config{WorktreeEnabled: true, KeepWorktreeOnFailure: true}
+
+		// canceled context makes the run fail
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		err = runWithWorktree(ctx, opts{MaxIterations: 1, NoColor: true}, executePlanRequest{
+			PlanFile: planPath, Mode: processor.ModeFull, GitSvc: gitSvc, Config: cfg,
+			Colors: testColors(), DefaultBranch: "master", WtCleanup: &worktreeCleanupFn{},
+		})
+		require.Error(t, err)
+
+		cwd, cwdErr := os.Getwd()
+		require.NoError(t, cwdErr)
+		assert.Equal(t, resolvedDir, cwd, "cwd should be restored even when the worktree is kept")
+
+		wtPath := filepath.Join(dir, ".ralphex", "worktrees", "wt-keep")
+		assert.DirExists(t, wtPath, "worktree should be kept after a failed run")
+		runGit(t, dir, "worktree", "remove", "--force", wtPath)
+	})
+
 	t.Run("populates_worktree_cleanup_ptr", func(t *testing.T) {
 		skipIfClaudeNotAvailable(t)
 
@@ -1422,6 +2305,33 @@ func TestWorktreeMode_SkippedForNonBranchModes(t *testing.T) {
 	})
 }
 
+func TestSelectAndExecutePlan_ExternalPlan(t *testing.T) {
+	dir := setupTestRepo(t)
+	gitSvc, err := git.NewService(dir, noopLogger())
+	require.NoError(t, err)
+	plansDir := filepath.Join(dir, "docs", "plans")
+	require.NoError(t, os.MkdirAll(plansDir, 0o750))
+	extPlan := filepath.Join(dir, "notes", "feature.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(extPlan), 0o750))
+	require.NoError(t, os.WriteFile(extPlan, []byte("# Plan\n\n### Task 1: do\n- [ ] item\n"), 0o600))
+	selector := plan.NewSelector(plansDir, testColors())
+
+	t.Run("refused when external plans are not allowed", func(t *testing.T) {
+		req := executePlanRequest{Mode: processor.ModeFull, Config: &config.Config{}, Colors: testColors()}
+		err := selectAndExecutePlan(t.Context(), opts{PlanFile: extPlan}, req, selector)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is outside plans directory")
+		assert.Contains(t, err.Error(), "allow_external_plans = true")
+	})
+
+	t.Run("accepted when external plans are allowed", func(t *testing.T) {
+		req := executePlanRequest{Mode: processor.ModeFull, Config: &config.Config{AllowExternalPlans: true},
+			Colors: testColors(), GitSvc: gitSvc, DefaultBranch: "master"}
+		err := selectAndExecutePlan(t.Context(), opts{PlanFile: extPlan, Explain: true}, req, selector)
+		require.NoError(t, err)
+	})
+}
+
 func TestRunWithWorktree_UntrackedPlan(t *testing.T) {
 	skipIfClaudeNotAvailable(t)
 
@@ -1559,7 +2469,7 @@ func TestSetupProgressLogger(t *testing.T) {
 		chdirTemp(t)
 
 		colors := testColors()
-		req := executePlanRequest{PlanFile: "test-plan.md", Mode: processor.ModeFull, Colors: colors}
+		req := executePlanRequest{PlanFile: "test-plan.md", Mode: processor.ModeFull, Colors: colors, Config: &config.Config{}}
 		plr, err := setupProgressLogger(opts{NoColor: true}, req, "test-branch")
 		require.NoError(t, err)
 		defer plr.closeLog()
@@ -1602,7 +2512,7 @@ func TestSetupProgressLogger(t *testing.T) {
 		chdirTemp(t)
 
 		colors := testColors()
-		req := executePlanRequest{PlanFile: "holder-test.md", Mode: processor.ModeReview, Colors: colors}
+		req := executePlanRequest{PlanFile: "holder-test.md", Mode: processor.ModeReview, Colors: colors, Config: &config.Config{}}
 		plr, err := setupProgressLogger(opts{NoColor: true}, req, "main")
 		require.NoError(t, err)
 		defer plr.closeLog()
@@ -1614,7 +2524,7 @@ func TestSetupProgressLogger(t *testing.T) {
 		chdirTemp(t)
 
 		colors := testColors()
-		req := executePlanRequest{PlanFile: "idempotent.md", Mode: processor.ModeFull, Colors: colors}
+		req := executePlanRequest{PlanFile: "idempotent.md", Mode: processor.ModeFull, Colors: colors, Config: &config.Config{}}
 		plr, err := setupProgressLogger(opts{NoColor: true}, req, "main")
 		require.NoError(t, err)
 
@@ -1663,7 +2573,46 @@ func TestBuildNotifyResult(t *testing.T) {
 		assert.Zero(t, result.Files)
 		assert.Zero(t, result.Additions)
 		assert.Zero(t, result.Deletions)
+		assert.Empty(t, result.DashboardURL)
 	})
+
+	t.Run("dashboard_url", func(t *testing.T) {
+		req := executePlanRequest{Mode: processor.ModeFull, DashboardURL: "http://localhost:8080"}
+		result := buildNotifyResult(req, "main", "1m", git.DiffStats{}, nil)
+		assert.Equal(t, "http://localhost:8080", result.DashboardURL)
+	})
+
+	t.Run("run_id", func(t *testing.T) {
+		req := executePlanRequest{Mode: processor.ModeFull, RunID: "20260115-103000-4f2a9c"}
+		result := buildNotifyResult(req, "main", "1m", git.DiffStats{}, errors.New("failed"))
+		assert.Equal(t, "20260115-103000-4f2a9c", result.RunID)
+	})
+
+	t.Run("test_result", func(t *testing.T) {
+		result := buildNotifyResult(executePlanRequest{Mode: processor.ModeFull}, "main", "1m", git.DiffStats{}, nil)
+		assert.False(t, result.TestsRun, "no test run")
+		assert.Nil(t, result.TestsPassed, "no test run")
+
+		req := executePlanRequest{Mode: processor.ModeFull, TestResult: &processor.TestResult{Passed: true, Coverage: 81.3}}
+		result = buildNotifyResult(req, "main", "1m", git.DiffStats{}, nil)
+		assert.True(t, result.TestsRun)
+		require.NotNil(t, result.TestsPassed)
+		assert.True(t, *result.TestsPassed)
+		assert.InDelta(t, 81.3, result.Coverage, 0.001)
+	})
+}
+
+func TestNewRunID(t *testing.T) {
+	now := time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC)
+	id := newRunID(now)
+	assert.Regexp(t, `^20260115-103000-[0-9a-f]{6}$`, id)
+	assert.NotEqual(t, id, newRunID(now), "random suffix separates runs started in the same second")
+}
+
+func TestDashboardURL(t *testing.T) {
+	assert.Equal(t, "http://localhost:8080", dashboardURL(opts{Host: "127.0.0.1", Port: 8080}))
+	assert.Equal(t, "http://localhost:9090", dashboardURL(opts{Host: "0.0.0.0", Port: 9090}))
+	assert.Equal(t, "http://build-box:8080", dashboardURL(opts{Host: "build-box", Port: 8080}))
 }
 
 func TestDisplayStats(t *testing.T) {
@@ -1680,7 +2629,7 @@ func TestDisplayStats(t *testing.T) {
 
 		req := executePlanRequest{PlanFile: "docs/plans/feature.md", Colors: colors}
 		stats := git.DiffStats{Files: 5, Additions: 200, Deletions: 50}
-		displayStats(req, baseLog, stats, "2m15s")
+		displayStats(req, baseLog, stats, nil, "2m15s")
 	})
 
 	t.Run("without_diff_stats", func(t *testing.T) {
@@ -1695,7 +2644,7 @@ func TestDisplayStats(t *testing.T) {
 		defer func() { _ = baseLog.Close() }()
 
 		req := executePlanRequest{Colors: colors}
-		displayStats(req, baseLog, git.DiffStats{}, "30s")
+		displayStats(req, baseLog, git.DiffStats{}, nil, "30s")
 	})
 
 	t.Run("with_main_plan_file", func(t *testing.T) {
@@ -1714,7 +2663,257 @@ func TestDisplayStats(t *testing.T) {
 			MainPlanFile: "docs/plans/feature.md",
 			Colors:       colors,
 		}
-		displayStats(req, baseLog, git.DiffStats{Files: 1, Additions: 10, Deletions: 5}, "10s")
+		displayStats(req, baseLog, git.DiffStats{Files: 1, Additions: 10, Deletions: 5}, nil, "10s")
+	})
+
+	t.Run("with_file_stats", func(t *testing.T) {
+		chdirTemp(t)
+
+		colors := testColors()
+		holder := &status.PhaseHolder{}
+		baseLog, err := progress.NewLogger(progress.Config{
+			PlanFile: "file-stats.md", Mode: "full", Branch: "main", NoColor: true,
+		}, colors, holder)
+		require.NoError(t, err)
+		defer func() { _ = baseLog.Close() }()
+
+		req := executePlanRequest{Colors: colors}
+		files := []git.FileDiffStat{
+			{Path: "main.go", Additions: 10, Deletions: 5},
+			{Path: "logo.png", Binary: true},
+			{Path: "new.go", OldPath: "old.go", Additions: 1},
+		}
+		displayStats(req, baseLog, git.DiffStats{Files: 3, Additions: 11, Deletions: 5}, files, "10s")
+
+		content, err := os.ReadFile(baseLog.Path())
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "changed files:")
+		assert.Contains(t, string(content), "+10  -5  main.go")
+		assert.Contains(t, string(content), "binary      logo.png")
+		assert.Contains(t, string(content), "new.go (renamed from old.go)")
+	})
+}
+
+func TestBranchDivergence(t *testing.T) {
+	t.Run("diverged branch with review", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		runGit(t, dir, "checkout", "-b", "add-auth")
+		runGit(t, dir, "commit", "--allow-empty", "-m", "feature work")
+		runGit(t, dir, "checkout", "master")
+		runGit(t, dir, "commit", "--allow-empty", "-m", "upstream work")
+		runGit(t, dir, "checkout", "add-auth")
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+
+		desc, hint := branchDivergence(executePlanRequest{GitSvc: gitSvc, BaseRef: "master", Mode: processor.ModeFull})
+		assert.Equal(t, "1 ahead, 1 behind master", desc)
+		assert.Equal(t, "branch is 1 commit(s) behind master, consider rebasing before review", hint)
+
+		desc, hint = branchDivergence(executePlanRequest{GitSvc: gitSvc, BaseRef: "master", Mode: processor.ModeTasksOnly})
+		assert.Equal(t, "1 ahead, 1 behind master", desc)
+		assert.Empty(t, hint, "tasks-only mode runs no review")
+	})
+
+	t.Run("up to date branch", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		runGit(t, dir, "checkout", "-b", "add-auth")
+		runGit(t, dir, "commit", "--allow-empty", "-m", "feature work")
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+
+		desc, hint := branchDivergence(executePlanRequest{GitSvc: gitSvc, BaseRef: "master", Mode: processor.ModeFull})
+		assert.Equal(t, "1 ahead, 0 behind master", desc)
+		assert.Empty(t, hint)
+	})
+
+	t.Run("same branch or missing base", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+
+		for _, base := range []string{"master", "nonexistent"} {
+			desc, hint := branchDivergence(executePlanRequest{GitSvc: gitSvc, BaseRef: base, Mode: processor.ModeFull})
+			assert.Empty(t, desc, base)
+			assert.Empty(t, hint, base)
+		}
+	})
+}
+
+func TestArchiveProgress(t *testing.T) {
+	t.Run("commits progress and task segments", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+
+		progressDir := filepath.Join(dir, ".ralphex", "progress")
+		require.NoError(t, os.MkdirAll(progressDir, 0o750))
+		progressPath := filepath.Join(progressDir, "progress-feature.txt")
+		require.NoError(t, os.WriteFile(progressPath, []byte("run log\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(progressDir, "progress-feature-task-1.txt"), []byte("task 1\n"), 0o600))
+
+		archiveProgress(&config.Config{ProgressBranch: "ralphex-logs"}, gitSvc, progressPath)
+
+		files, err := exec.Command("git", "-C", dir, "ls-tree", "-r", "--name-only", "ralphex-logs").Output()
+		require.NoError(t, err)
+		assert.Equal(t, ".ralphex/progress/progress-feature-task-1.txt\n.ralphex/progress/progress-feature.txt\n", string(files))
+		branch, err := gitSvc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "master", branch)
+	})
+
+	t.Run("no-op without progress_branch", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+		progressPath := filepath.Join(dir, "progress-feature.txt")
+		require.NoError(t, os.WriteFile(progressPath, []byte("run log\n"), 0o600))
+
+		archiveProgress(&config.Config{}, gitSvc, progressPath)
+		require.Error(t, exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", "ralphex-logs").Run())
+	})
+}
+
+func TestPrintUpstreamHint(t *testing.T) {
+	t.Run("feature branch without upstream", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		runGit(t, dir, "remote", "add", "origin", t.TempDir())
+		runGit(t, dir, "checkout", "-b", "add-auth")
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		printUpstreamHint(&buf, executePlanRequest{GitSvc: gitSvc, Colors: testColors(), DefaultBranch: "master"}, "add-auth")
+		assert.Equal(t, "  push: git push -u origin add-auth\n", buf.String())
+	})
+
+	t.Run("configured push remote", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		runGit(t, dir, "remote", "add", "fork", t.TempDir())
+		runGit(t, dir, "checkout", "-b", "add-auth")
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		req := executePlanRequest{GitSvc: gitSvc, Colors: testColors(), DefaultBranch: "master",
+			Config: &config.Config{PushRemote: "fork"}}
+		printUpstreamHint(&buf, req, "add-auth")
+		assert.Equal(t, "  push: git push -u fork add-auth\n", buf.String())
+	})
+
+	t.Run("no remote configured", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		runGit(t, dir, "checkout", "-b", "add-auth")
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		printUpstreamHint(&buf, executePlanRequest{GitSvc: gitSvc, Colors: testColors(), DefaultBranch: "master"}, "add-auth")
+		assert.Equal(t, "  push: no 'origin' remote configured, add one with git remote add origin <url>\n", buf.String())
+	})
+
+	t.Run("feature branch with upstream", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		remote := t.TempDir()
+		runGit(t, remote, "init", "--bare")
+		runGit(t, dir, "remote", "add", "origin", remote)
+		runGit(t, dir, "checkout", "-b", "add-auth")
+		runGit(t, dir, "push", "-u", "origin", "add-auth")
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		printUpstreamHint(&buf, executePlanRequest{GitSvc: gitSvc, Colors: testColors(), DefaultBranch: "master"}, "add-auth")
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("default branch", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		printUpstreamHint(&buf, executePlanRequest{GitSvc: gitSvc, Colors: testColors(), DefaultBranch: "master"}, "master")
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestPushCompletedBranch(t *testing.T) {
+	setup := func(t *testing.T) (dir, remote string, gitSvc *git.Service) {
+		t.Helper()
+		dir = setupTestRepo(t)
+		remote = t.TempDir()
+		runGit(t, remote, "init", "--bare")
+		runGit(t, dir, "remote", "add", "origin", remote)
+		runGit(t, dir, "checkout", "-b", "add-auth")
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+		return dir, remote, gitSvc
+	}
+	remoteRef := func(t *testing.T, remote, ref string) string {
+		t.Helper()
+		out, err := exec.Command("git", "-C", remote, "rev-parse", "--verify", "-q", ref).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	t.Run("pushes to the review namespace", func(t *testing.T) {
+		dir, remote, gitSvc := setup(t)
+		req := executePlanRequest{GitSvc: gitSvc, DefaultBranch: "master",
+			Config: &config.Config{PushRefspecTemplate: "review/{{BRANCH}}"}}
+		pushed, err := pushCompletedBranch(req, "add-auth")
+		require.NoError(t, err)
+		assert.True(t, pushed)
+
+		head, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+		require.NoError(t, err)
+		assert.Equal(t, strings.TrimSpace(string(head)), remoteRef(t, remote, "refs/heads/review/add-auth"))
+		assert.Empty(t, remoteRef(t, remote, "refs/heads/add-auth"))
+	})
+
+	t.Run("no template", func(t *testing.T) {
+		_, remote, gitSvc := setup(t)
+		req := executePlanRequest{GitSvc: gitSvc, DefaultBranch: "master", Config: &config.Config{}}
+		pushed, err := pushCompletedBranch(req, "add-auth")
+		require.NoError(t, err)
+		assert.False(t, pushed)
+		assert.Empty(t, remoteRef(t, remote, "refs/heads/add-auth"))
+	})
+
+	t.Run("default branch is not pushed", func(t *testing.T) {
+		dir, remote, _ := setup(t)
+		runGit(t, dir, "checkout", "master")
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+		req := executePlanRequest{GitSvc: gitSvc, DefaultBranch: "master",
+			Config: &config.Config{PushRefspecTemplate: "review/{{BRANCH}}"}}
+		pushed, err := pushCompletedBranch(req, "master")
+		require.NoError(t, err)
+		assert.False(t, pushed)
+		assert.Empty(t, remoteRef(t, remote, "refs/heads/review/master"))
+	})
+
+	t.Run("missing remote", func(t *testing.T) {
+		_, _, gitSvc := setup(t)
+		req := executePlanRequest{GitSvc: gitSvc, DefaultBranch: "master",
+			Config: &config.Config{PushRefspecTemplate: "review/{{BRANCH}}", PushRemote: "fork"}}
+		pushed, err := pushCompletedBranch(req, "add-auth")
+		require.EqualError(t, err, "no 'fork' remote configured, add one with git remote add fork <url>")
+		assert.False(t, pushed)
+	})
+
+	t.Run("diverged remote ref is rejected", func(t *testing.T) {
+		dir, _, gitSvc := setup(t)
+		runGit(t, dir, "commit", "--allow-empty", "-m", "pushed by someone else")
+		runGit(t, dir, "push", "origin", "add-auth:review/add-auth")
+		runGit(t, dir, "reset", "--hard", "HEAD~1")
+		runGit(t, dir, "commit", "--allow-empty", "-m", "local work")
+		req := executePlanRequest{GitSvc: gitSvc, DefaultBranch: "master",
+			Config: &config.Config{PushRefspecTemplate: "review/{{BRANCH}}"}}
+		pushed, err := pushCompletedBranch(req, "add-auth")
+		require.ErrorIs(t, err, git.ErrPushRejected)
+		assert.False(t, pushed)
 	})
 }
 
@@ -1752,3 +2951,216 @@ func branchExists(t *testing.T, dir, branch string) bool {
 	require.NoError(t, err)
 	return strings.TrimSpace(string(out)) != ""
 }
+
+func TestRunReplay(t *testing.T) {
+	colors := testColors()
+
+	t.Run("missing_file_returns_error", func(t *testing.T) {
+		err := runReplay(context.Background(), opts{Replay: filepath.Join(t.TempDir(), "missing.txt")}, &config.Config{}, colors)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "run replay mode")
+	})
+
+	t.Run("replays_until_canceled", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "progress-test.txt")
+		content := "# Ralphex Progress Log\nPlan: docs/plans/test.md\nBranch: test\n" +
+			"------------------------------------------------------------\n[26-01-22 10:00:01] done\n"
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+		require.NoError(t, runReplay(ctx, opts{Replay: path, Port: 0, Host: "127.0.0.1"}, &config.Config{}, colors))
+	})
+}
+
+func TestRunHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh syntax")
+	}
+	newLog := func() (*procmocks.LoggerMock, *strings.Builder) {
+		var out strings.Builder
+		return &procmocks.LoggerMock{
+			PrintFunc:        func(format string, args ...any) { fmt.Fprintf(&out, format+"\n", args...) },
+			PrintAlignedFunc: func(text string) { out.WriteString(text) },
+		}, &out
+	}
+
+	t.Run("success streams output", func(t *testing.T) {
+		log, out := newLog()
+		require.NoError(t, runHook(t.Context(), "pre-run command", "echo ready", log))
+		assert.Equal(t, "running pre-run command: echo ready\nready\n", out.String())
+	})
+
+	t.Run("failure returns error", func(t *testing.T) {
+		log, _ := newLog()
+		err := runHook(t.Context(), "pre-run command", "exit 2", log)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pre-run command: hook exited with error")
+	})
+
+	t.Run("post-run failure is a warning", func(t *testing.T) {
+		log, out := newLog()
+		runPostRunHook(t.Context(), "echo bye && exit 1", log)
+		assert.Contains(t, out.String(), "bye\n")
+		assert.Contains(t, out.String(), "warning: post-run command: hook exited with error")
+	})
+
+	t.Run("post-run runs after cancel", func(t *testing.T) {
+		log, out := newLog()
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		runPostRunHook(ctx, "echo teardown", log)
+		assert.Contains(t, out.String(), "teardown\n")
+		assert.NotContains(t, out.String(), "warning")
+	})
+
+	t.Run("post-run not configured", func(t *testing.T) {
+		log, out := newLog()
+		runPostRunHook(t.Context(), "", log)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("pre-run script runs after command", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "pre-run"), []byte("#!/bin/sh\necho \"script $RALPHEX_PLAN_FILE\"\n"), 0o700))
+		log, out := newLog()
+		hookRunner := &hooks.Runner{Dir: dir, Env: []string{"RALPHEX_PLAN_FILE=plan.md"}, Log: log}
+		require.NoError(t, runPreRunHooks(t.Context(), "echo command", hookRunner, log))
+		assert.Regexp(t, `(?s)command\n.*script plan.md\n`, out.String())
+	})
+
+	t.Run("pre-run command failure skips script", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "pre-run"), []byte("#!/bin/sh\necho script\n"), 0o700))
+		log, out := newLog()
+		err := runPreRunHooks(t.Context(), "exit 1", &hooks.Runner{Dir: dir, Log: log}, log)
+		require.Error(t, err)
+		assert.NotContains(t, out.String(), "script\n")
+	})
+
+	t.Run("post-run script gets last phase and runs after cancel", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "post-run"), []byte("#!/bin/sh\necho \"phase $RALPHEX_PHASE\"\n"), 0o700))
+		log, out := newLog()
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		require.NoError(t, runPostRunHooks(ctx, "echo teardown", &hooks.Runner{Dir: dir, Log: log}, "review", log))
+		assert.Regexp(t, `(?s)teardown\n.*phase review\n`, out.String())
+	})
+
+	t.Run("fatal post-run script returns error", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "post-run"), []byte("#!/bin/sh\nexit 1\n"), 0o700))
+		log, _ := newLog()
+		hookRunner := &hooks.Runner{Dir: dir, Log: log, Failure: map[hooks.Point]hooks.Failure{hooks.PostRun: hooks.FailureFatal}}
+		err := runPostRunHooks(t.Context(), "", hookRunner, "", log)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "hooks: post-run hook:")
+	})
+}
+
+func TestWriteInlinePlan(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 30, 0, 0, time.UTC)
+
+	t.Run("expands escaped newlines and names file from title", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "docs", "plans")
+		path, err := writeInlinePlan(`# Fix Login Redirect!\n### Task 1: redirect\n- [ ] do X`, dir, now)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "fix-login-redirect.md"), path)
+
+		data, err := os.ReadFile(path) //nolint:gosec // test path
+		require.NoError(t, err)
+		assert.Equal(t, "# Fix Login Redirect!\n### Task 1: redirect\n- [ ] do X\n", string(data))
+	})
+
+	t.Run("real newlines kept as is", func(t *testing.T) {
+		dir := t.TempDir()
+		content := "# Plan\n\n### Task 1: a\n- [ ] use `\\n` escapes\n"
+		path, err := writeInlinePlan(content, dir, now)
+		require.NoError(t, err)
+		data, err := os.ReadFile(path) //nolint:gosec // test path
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	})
+
+	t.Run("existing name gets timestamp", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "fix.md"), []byte("old"), 0o600))
+		path, err := writeInlinePlan("# Fix\n### Task 1\n- [ ] x", dir, now)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "fix-20261016-153000.md"), path)
+	})
+
+	t.Run("no title falls back to generic name", func(t *testing.T) {
+		path, err := writeInlinePlan("### Task 1: x\n- [ ] y", t.TempDir(), now)
+		require.NoError(t, err)
+		assert.Equal(t, "inline-plan.md", filepath.Base(path))
+	})
+
+	t.Run("long title truncated", func(t *testing.T) {
+		path, err := writeInlinePlan("# "+strings.Repeat("word ", 30), t.TempDir(), now)
+		require.NoError(t, err)
+		name := strings.TrimSuffix(filepath.Base(path), ".md")
+		assert.LessOrEqual(t, len(name), maxInlinePlanName)
+		assert.False(t, strings.HasSuffix(name, "-"))
+	})
+
+	t.Run("empty content", func(t *testing.T) {
+		_, err := writeInlinePlan("  \\n ", t.TempDir(), now)
+		require.EqualError(t, err, "plan content is empty")
+	})
+}
+
+func TestDetectTestCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		wantCmd    string
+		wantMarker string
+	}{
+		{name: "no markers", files: nil},
+		{name: "go module", files: map[string]string{"go.mod": "module x"}, wantCmd: "go test ./...", wantMarker: "go.mod"},
+		{name: "cargo", files: map[string]string{"Cargo.toml": "[package]"}, wantCmd: "cargo test", wantMarker: "Cargo.toml"},
+		{name: "makefile test target wins", files: map[string]string{"go.mod": "module x", "Makefile": "build:\n\tgo build\n\ntest: build\n\tgo test ./...\n"},
+			wantCmd: "make test", wantMarker: "Makefile"},
+		{name: "makefile without test target", files: map[string]string{"go.mod": "module x", "Makefile": "build:\n\tgo build\ntest-e2e:\n"},
+			wantCmd: "go test ./...", wantMarker: "go.mod"},
+		{name: "npm", files: map[string]string{"package.json": `{"scripts":{"test":"jest"}}`}, wantCmd: "npm test", wantMarker: "package.json"},
+		{name: "pnpm", files: map[string]string{"package.json": `{"scripts":{"test":"vitest"}}`, "pnpm-lock.yaml": ""},
+			wantCmd: "pnpm test", wantMarker: "package.json"},
+		{name: "yarn", files: map[string]string{"package.json": `{"scripts":{"test":"jest"}}`, "yarn.lock": ""},
+			wantCmd: "yarn test", wantMarker: "package.json"},
+		{name: "npm init placeholder ignored", files: map[string]string{"package.json": `{"scripts":{"test":"echo \"Error: no test specified\" && exit 1"}}`}},
+		{name: "package.json without test script", files: map[string]string{"package.json": `{"scripts":{"build":"tsc"}}`}},
+		{name: "python", files: map[string]string{"pyproject.toml": "[project]"}, wantCmd: "pytest", wantMarker: "pyproject.toml"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+			}
+			cmd, marker := detectTestCommand(dir)
+			assert.Equal(t, tc.wantCmd, cmd)
+			assert.Equal(t, tc.wantMarker, marker)
+		})
+	}
+}
+
+func TestResolveTestCommand(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x"), 0o600))
+
+	cmd, source := resolveTestCommand("make check", dir)
+	assert.Equal(t, "make check", cmd)
+	assert.Equal(t, "config", source)
+
+	cmd, source = resolveTestCommand("", dir)
+	assert.Equal(t, "go test ./...", cmd)
+	assert.Equal(t, "detected from go.mod", source)
+
+	cmd, source = resolveTestCommand("", t.TempDir())
+	assert.Empty(t, cmd)
+	assert.Empty(t, source)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runLockInfo is the content of the run lock file, identifying the run that holds the lock.
+type runLockInfo struct {
+	PID     int       `json:"pid"`
+	Plan    string    `json:"plan"`
+	Started time.Time `json:"started"`
+}
+
+// runLockPath returns the path of the repo-wide run lock file.
+// the lock lives in its own directory with a "*" .gitignore, so it never shows up as an untracked file.
+func runLockPath(repoRoot string) string {
+	return filepath.Join(repoRoot, ".ralphex", "locks", "run.lock")
+}
+
+// planLockPath returns the path of the lock a worktree run takes for its plan. it is keyed on the plan
+// file name, the same name the worktree and its branch are derived from.
+func planLockPath(repoRoot, planFile string) string {
+	name := strings.TrimSuffix(filepath.Base(planFile), filepath.Ext(planFile))
+	return filepath.Join(repoRoot, ".ralphex", "locks", "plan-"+name+".lock")
+}
+
+// acquireRunLock takes the repo-wide run lock, so a second ralphex run in the same repo refuses to start
+// instead of competing for branches and the working tree. plan describes this run in the lock file.
+// returns a function that releases the lock; it is safe to call more than once.
+func acquireRunLock(repoRoot, plan string) (func(), error) {
+	return acquireLock(runLockPath(repoRoot), plan)
+}
+
+// acquirePlanLock takes the lock of a single plan for a worktree run. worktree runs don't share
+// the working tree, so runs on different plans go side by side; a second run on the same plan,
+// which would compete for its branch and worktree, refuses to start.
+func acquirePlanLock(repoRoot, planFile string) (func(), error) {
+	return acquireLock(planLockPath(repoRoot, planFile), toRelPath(planFile))
+}
+
+// acquireLock creates the lock file at path, recording this process and plan. a lock left behind
+// by a process that is no longer running is reclaimed.
+func acquireLock(path, plan string) (func(), error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create lock dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*\n"), 0o600); err != nil {
+		return nil, fmt.Errorf("write lock dir gitignore: %w", err)
+	}
+
+	info := runLockInfo{PID: os.Getpid(), Plan: plan, Started: time.Now()}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return nil, fmt.Errorf("marshal lock info: %w", err)
+	}
+
+	// second attempt runs after a stale lock was removed
+	for range 2 {
+		f, openErr := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) //nolint:gosec // path under repo root
+		if errors.Is(openErr, fs.ErrExist) {
+			holder, alive := readRunLock(path)
+			if alive && holder.PID == 0 {
+				return nil, errors.New("another ralphex run is active in this repo and still starting, try again in a moment")
+			}
+			if alive {
+				return nil, fmt.Errorf("another ralphex run is active in this repo (pid %d, plan %s, started %s), "+
+					"wait for it to finish or stop it; remove %s if it is not running",
+					holder.PID, holder.Plan, holder.Started.Format(time.DateTime), path)
+			}
+			if rmErr := os.Remove(path); rmErr != nil && !errors.Is(rmErr, fs.ErrNotExist) {
+				return nil, fmt.Errorf("remove stale run lock: %w", rmErr)
+			}
+			continue
+		}
+		if openErr != nil {
+			return nil, fmt.Errorf("create run lock: %w", openErr)
+		}
+		_, writeErr := f.Write(data)
+		if closeErr := f.Close(); writeErr == nil {
+			writeErr = closeErr
+		}
+		if writeErr != nil {
+			_ = os.Remove(path) // best-effort cleanup, write error is reported
+			return nil, fmt.Errorf("write run lock: %w", writeErr)
+		}
+		return releaseRunLock(path, info.PID), nil
+	}
+	return nil, fmt.Errorf("lock %s is held by another ralphex run", path)
+}
+
+// runLockWriteGrace is how long a malformed lock file is considered in use, to cover another run
+// that created the file but has not written its content yet.
+const runLockWriteGrace = 5 * time.Second
+
+// readRunLock reads the lock file and reports whether the process that holds it is still running.
+// an unreadable or malformed lock is treated as stale unless it was just created.
+func readRunLock(path string) (runLockInfo, bool) {
+	var info runLockInfo
+	data, err := os.ReadFile(path) //nolint:gosec // path under repo root
+	if err != nil || json.Unmarshal(data, &info) != nil {
+		st, statErr := os.Stat(path)
+		return info, statErr == nil && time.Since(st.ModTime()) < runLockWriteGrace
+	}
+	return info, processAlive(info.PID)
+}
+
+// staleRunLock returns the lock left behind by a run that is no longer running, e.g. one killed mid-run,
+// and started no later than before. both the repo-wide run lock and the plan locks of worktree runs are
+// checked, the most recently started match is returned. reports false when there is no such lock.
+func staleRunLock(repoRoot string, before time.Time) (runLockInfo, bool) {
+	paths, _ := filepath.Glob(filepath.Join(filepath.Dir(runLockPath(repoRoot)), "*.lock"))
+	var res runLockInfo
+	var found bool
+	for _, path := range paths {
+		info, alive := readRunLock(path)
+		if alive || info.PID == 0 || info.Started.After(before) {
+			continue
+		}
+		if !found || info.Started.After(res.Started) {
+			res, found = info, true
+		}
+	}
+	return res, found
+}
+
+// releaseRunLock returns a function that removes the lock file if it still belongs to pid.
+func releaseRunLock(path string, pid int) func() {
+	var released bool
+	return func() {
+		if released {
+			return
+		}
+		released = true
+		if info, _ := readRunLock(path); info.PID != pid {
+			return // reclaimed by another run, leave it alone
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "warning: failed to remove run lock: %v\n", err)
+		}
+	}
+}
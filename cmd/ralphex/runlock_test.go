@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/processor"
+)
+
+// deadPID returns the pid of a process that has already exited.
+func deadPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command("go", "version")
+	require.NoError(t, cmd.Run())
+	return cmd.Process.Pid
+}
+
+// writeRunLock writes a lock file held by the given pid.
+func writeRunLock(t *testing.T, root string, pid int, plan string) {
+	t.Helper()
+	path := runLockPath(root)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	data, err := json.Marshal(runLockInfo{PID: pid, Plan: plan, Started: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func TestAcquireRunLock(t *testing.T) {
+	t.Run("acquire and release", func(t *testing.T) {
+		root := t.TempDir()
+		release, err := acquireRunLock(root, "docs/plans/feature.md")
+		require.NoError(t, err)
+
+		info, alive := readRunLock(runLockPath(root))
+		assert.True(t, alive)
+		assert.Equal(t, os.Getpid(), info.PID)
+		assert.Equal(t, "docs/plans/feature.md", info.Plan)
+
+		ignore, err := os.ReadFile(filepath.Join(root, ".ralphex", "locks", ".gitignore"))
+		require.NoError(t, err)
+		assert.Equal(t, "*\n", string(ignore))
+
+		release()
+		release() // second call is a no-op
+		assert.NoFileExists(t, runLockPath(root))
+	})
+
+	t.Run("second run refused while lock is held", func(t *testing.T) {
+		root := t.TempDir()
+		writeRunLock(t, root, os.Getppid(), "docs/plans/other.md")
+
+		_, err := acquireRunLock(root, "docs/plans/feature.md")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "another ralphex run is active in this repo")
+		assert.Contains(t, err.Error(), "docs/plans/other.md")
+		assert.FileExists(t, runLockPath(root))
+	})
+
+	t.Run("stale lock from dead process is reclaimed", func(t *testing.T) {
+		root := t.TempDir()
+		writeRunLock(t, root, deadPID(t), "docs/plans/old.md")
+
+		release, err := acquireRunLock(root, "docs/plans/feature.md")
+		require.NoError(t, err)
+		defer release()
+		info, _ := readRunLock(runLockPath(root))
+		assert.Equal(t, os.Getpid(), info.PID)
+	})
+
+	t.Run("old malformed lock is reclaimed", func(t *testing.T) {
+		root := t.TempDir()
+		path := runLockPath(root)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+		old := time.Now().Add(-time.Minute)
+		require.NoError(t, os.Chtimes(path, old, old))
+
+		release, err := acquireRunLock(root, "docs/plans/feature.md")
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("fresh malformed lock is treated as in use", func(t *testing.T) {
+		root := t.TempDir()
+		path := runLockPath(root)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+		_, err := acquireRunLock(root, "docs/plans/feature.md")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "another ralphex run is active")
+	})
+
+	t.Run("release keeps a lock reclaimed by another run", func(t *testing.T) {
+		root := t.TempDir()
+		release, err := acquireRunLock(root, "docs/plans/feature.md")
+		require.NoError(t, err)
+		writeRunLock(t, root, os.Getppid(), "docs/plans/other.md")
+
+		release()
+		assert.FileExists(t, runLockPath(root))
+	})
+}
+
+func TestAcquireLockForRun(t *testing.T) {
+	root := setupTestRepo(t)
+	gitSvc, err := git.NewService(root, noopLogger())
+	require.NoError(t, err)
+	worktreeReq := executePlanRequest{Mode: processor.ModeFull, GitSvc: gitSvc, Config: &config.Config{WorktreeEnabled: true}}
+	planA := filepath.Join(root, "docs", "plans", "feature-a.md")
+	planB := filepath.Join(root, "docs", "plans", "feature-b.md")
+
+	releaseA, err := acquireLockForRun(worktreeReq, planA)
+	require.NoError(t, err)
+	defer releaseA()
+	assert.FileExists(t, planLockPath(root, planA))
+	assert.NoFileExists(t, runLockPath(root), "worktree runs don't take the repo-wide lock")
+
+	releaseB, err := acquireLockForRun(worktreeReq, planB)
+	require.NoError(t, err, "worktree runs on other plans go side by side")
+	defer releaseB()
+
+	_, err = acquireLockForRun(worktreeReq, planA)
+	require.Error(t, err, "second worktree run on the same plan is refused")
+	assert.Contains(t, err.Error(), "another ralphex run is active")
+
+	reviewReq := executePlanRequest{Mode: processor.ModeReview, GitSvc: gitSvc, Config: &config.Config{WorktreeEnabled: true}}
+	releaseReview, err := acquireLockForRun(reviewReq, "")
+	require.NoError(t, err)
+	defer releaseReview()
+	info, alive := readRunLock(runLockPath(root))
+	assert.True(t, alive)
+	assert.Equal(t, "(review without plan)", info.Plan)
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// processAlive reports whether a process with the given pid is running.
+// on windows FindProcess opens a process handle and fails if there is no such process.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release() //nolint:errcheck // handle cleanup only
+	return true
+}
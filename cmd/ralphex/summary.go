@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/notify"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// runSummary is the archived record of a completed run, written next to the completed plan with write_summary.
+// it carries the same fields as the completion notification plus per-phase timing.
+type runSummary struct {
+	notify.Result
+	CompletedAt time.Time      `json:"completed_at"`
+	Tools       *toolVersions  `json:"tool_versions,omitempty"` // claude/codex versions, nil when not detected
+	Phases      []phaseSummary `json:"phases,omitempty"`
+}
+
+// phaseSummary aggregates the executor runs of one phase.
+type phaseSummary struct {
+	Phase      status.Phase `json:"phase"`
+	Iterations int          `json:"iterations"`
+	Duration   string       `json:"duration"`
+	Seconds    float64      `json:"seconds"`
+}
+
+// buildRunSummary serializes the result of a successful run as indented JSON.
+// phases are listed in the order they first ran.
+func buildRunSummary(req executePlanRequest, branch, elapsed string, stats git.DiffStats,
+	iterations []processor.IterationRecord, now time.Time) ([]byte, error) {
+	summary := runSummary{
+		Result:      buildNotifyResult(req, branch, elapsed, stats, nil),
+		CompletedAt: now,
+	}
+	if req.ToolVersions != (toolVersions{}) {
+		summary.Tools = &req.ToolVersions
+	}
+
+	idx := make(map[status.Phase]int)
+	durations := make(map[status.Phase]time.Duration)
+	for _, rec := range iterations {
+		i, ok := idx[rec.Phase]
+		if !ok {
+			i = len(summary.Phases)
+			idx[rec.Phase] = i
+			summary.Phases = append(summary.Phases, phaseSummary{Phase: rec.Phase})
+		}
+		summary.Phases[i].Iterations++
+		durations[rec.Phase] += rec.Duration
+	}
+	for i := range summary.Phases {
+		d := durations[summary.Phases[i].Phase]
+		summary.Phases[i].Duration = d.Round(time.Second).String()
+		summary.Phases[i].Seconds = d.Seconds()
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal run summary: %w", err)
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestBuildRunSummary(t *testing.T) {
+	req := executePlanRequest{PlanFile: "docs/plans/feature.md", Mode: processor.ModeFull}
+	iterations := []processor.IterationRecord{
+		{Num: 1, Phase: status.PhaseTask, Tool: "claude", Duration: 90 * time.Second},
+		{Num: 2, Phase: status.PhaseTask, Tool: "claude", Duration: 30 * time.Second},
+		{Num: 3, Phase: status.PhaseReview, Tool: "claude", Duration: 45 * time.Second},
+		{Num: 4, Phase: status.PhaseCodex, Tool: "codex", Duration: 10 * time.Second},
+	}
+	now := time.Date(2026, 1, 22, 10, 30, 0, 0, time.UTC)
+
+	data, err := buildRunSummary(req, "feature", "3m5s", git.DiffStats{Files: 3, Additions: 40, Deletions: 2}, iterations, now)
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "success", got["status"])
+	assert.Equal(t, "full", got["mode"])
+	assert.Equal(t, "feature", got["branch"])
+	assert.Equal(t, "3m5s", got["duration"])
+	assert.InDelta(t, 3, got["files"], 0)
+	assert.InDelta(t, 40, got["additions"], 0)
+	assert.Equal(t, "2026-01-22T10:30:00Z", got["completed_at"])
+
+	var summary runSummary
+	require.NoError(t, json.Unmarshal(data, &summary))
+	assert.Equal(t, []phaseSummary{
+		{Phase: status.PhaseTask, Iterations: 2, Duration: "2m0s", Seconds: 120},
+		{Phase: status.PhaseReview, Iterations: 1, Duration: "45s", Seconds: 45},
+		{Phase: status.PhaseCodex, Iterations: 1, Duration: "10s", Seconds: 10},
+	}, summary.Phases)
+	assert.Nil(t, summary.Tools)
+	assert.NotContains(t, got, "tool_versions", "omitted when versions were not detected")
+}
+
+func TestBuildRunSummary_TestResult(t *testing.T) {
+	req := executePlanRequest{Mode: processor.ModeFull, TestResult: &processor.TestResult{Passed: false}}
+	data, err := buildRunSummary(req, "feature", "1m", git.DiffStats{}, nil, time.Now())
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, true, got["tests_run"])
+	assert.Equal(t, false, got["tests_passed"], "failed tests are reported, not omitted")
+	assert.NotContains(t, got, "coverage")
+}
+
+func TestBuildRunSummary_ToolVersions(t *testing.T) {
+	req := executePlanRequest{Mode: processor.ModeFull, ToolVersions: toolVersions{Claude: "2.1.3 (Claude Code)", Codex: "unknown"}}
+	data, err := buildRunSummary(req, "feature", "1m", git.DiffStats{}, nil, time.Now())
+	require.NoError(t, err)
+
+	var summary runSummary
+	require.NoError(t, json.Unmarshal(data, &summary))
+	require.NotNil(t, summary.Tools)
+	assert.Equal(t, toolVersions{Claude: "2.1.3 (Claude Code)", Codex: "unknown"}, *summary.Tools)
+}
@@ -3,32 +3,55 @@
 package main
 
 import (
+	"log"
 	"os"
+	"sync"
 
 	"golang.org/x/sys/unix"
 	"golang.org/x/term"
 )
 
 // disableCtrlCEcho disables the ECHOCTL terminal flag so that pressing Ctrl+C
-// does not echo "^C" to the terminal. returns a function that restores the original state.
-func disableCtrlCEcho() func() {
-	fd := int(os.Stdin.Fd())
+// does not echo "^C" to the terminal. returns a function that restores the original state,
+// safe to call more than once. non-interactive stdin (pipes, CI) is left alone, and a terminal
+// that can't be configured only gets a debug note, the run continues with ^C echo.
+func disableCtrlCEcho(debug bool) func() {
+	return disableTerminalEcho(int(os.Stdin.Fd()), debug)
+}
+
+// disableTerminalEcho clears ECHOCTL on the terminal fd, see disableCtrlCEcho.
+func disableTerminalEcho(fd int, debug bool) func() {
 	if !term.IsTerminal(fd) {
 		return func() {}
 	}
 
 	termios, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
 	if err != nil {
+		debugf(debug, "can't read terminal state, keeping ^C echo: %v", err)
 		return func() {}
 	}
 
 	original := *termios
 	termios.Lflag &^= unix.ECHOCTL
 	if err := unix.IoctlSetTermios(fd, ioctlWriteTermios, termios); err != nil {
+		debugf(debug, "can't disable ^C echo: %v", err)
+		unix.IoctlSetTermios(fd, ioctlWriteTermios, &original) //nolint:errcheck // undo a partial change, best-effort
 		return func() {}
 	}
 
+	var once sync.Once
 	return func() {
-		unix.IoctlSetTermios(fd, ioctlWriteTermios, &original) //nolint:errcheck // best-effort restore
+		once.Do(func() {
+			if err := unix.IoctlSetTermios(fd, ioctlWriteTermios, &original); err != nil {
+				debugf(debug, "can't restore terminal state: %v", err)
+			}
+		})
+	}
+}
+
+// debugf logs a debug note when debug logging is enabled.
+func debugf(debug bool, format string, args ...any) {
+	if debug {
+		log.Printf("[debug] "+format, args...)
 	}
 }
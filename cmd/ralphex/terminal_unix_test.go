@@ -0,0 +1,53 @@
+//go:build !windows
+
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisableTerminalEcho(t *testing.T) {
+	t.Run("non-terminal fd is left alone", func(t *testing.T) {
+		f, err := os.Create(filepath.Join(t.TempDir(), "stdin"))
+		require.NoError(t, err)
+		defer f.Close()
+
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+		defer log.SetOutput(os.Stderr)
+
+		restore := disableTerminalEcho(int(f.Fd()), true)
+		restore()
+		restore() // safe to call twice
+		assert.Empty(t, buf.String(), "no terminal, nothing to note")
+	})
+
+	t.Run("pipe is left alone", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		defer r.Close()
+		defer w.Close()
+
+		restore := disableTerminalEcho(int(r.Fd()), false)
+		restore()
+	})
+}
+
+func TestDebugf(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	debugf(false, "hidden %d", 1)
+	assert.Empty(t, buf.String())
+
+	debugf(true, "shown %d", 2)
+	assert.Contains(t, buf.String(), "[debug] shown 2")
+}
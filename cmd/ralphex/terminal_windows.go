@@ -3,6 +3,6 @@
 package main
 
 // disableCtrlCEcho is a no-op on windows.
-func disableCtrlCEcho() func() {
+func disableCtrlCEcho(bool) func() {
 	return func() {}
 }
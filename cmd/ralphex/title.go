@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// xterm control sequences for the window title. the title stack (push/pop) is supported by
+// xterm-compatible terminals and ignored elsewhere, so the title is also cleared on restore.
+const (
+	titlePush = "\x1b[22;0t"
+	titlePop  = "\x1b[23;0t"
+	titleSet  = "\x1b]0;%s\x07"
+)
+
+// titleRefreshInterval is how often the terminal title is refreshed to advance the elapsed time.
+const titleRefreshInterval = time.Second
+
+// startTerminalTitle shows the current phase and elapsed time in the terminal title when enabled.
+// titles are skipped when stdout is not a terminal or colors are disabled, since both usually mean
+// the output is captured or the terminal doesn't handle escape sequences.
+// returns a function that stops updates and restores the original title.
+func startTerminalTitle(enabled, noColor bool, holder *status.PhaseHolder, names status.PhaseNames, elapsed func() string) func() {
+	if !enabled || noColor || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return func() {}
+	}
+	return runTitleUpdates(os.Stdout, holder, names, elapsed, titleRefreshInterval)
+}
+
+// runTitleUpdates writes the title to w every interval, skipping writes when the text didn't change.
+// the returned function stops the updates, waits for the updater to exit and restores the title.
+// it is safe to call more than once.
+func runTitleUpdates(w io.Writer, holder *status.PhaseHolder, names status.PhaseNames, elapsed func() string,
+	interval time.Duration) func() {
+	fmt.Fprint(w, titlePush)
+
+	var last string
+	update := func() {
+		title := formatTitle(names.Label(holder.Get()), elapsed())
+		if title == last {
+			return
+		}
+		last = title
+		fmt.Fprintf(w, titleSet, title)
+	}
+	update()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				update()
+			}
+		}
+	})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+			fmt.Fprintf(w, titleSet, "")
+			fmt.Fprint(w, titlePop)
+		})
+	}
+}
+
+// formatTitle builds the title text, e.g. "ralphex: review 12m30s".
+// the phase is omitted before the first phase starts.
+func formatTitle(phase, elapsed string) string {
+	if phase == "" {
+		return "ralphex " + elapsed
+	}
+	return "ralphex: " + phase + " " + elapsed
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// lockedBuffer is a bytes.Buffer safe for concurrent writes and reads.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestFormatTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		phase   string
+		elapsed string
+		want    string
+	}{
+		{name: "with phase", phase: "review", elapsed: "12m30s", want: "ralphex: review 12m30s"},
+		{name: "custom label", phase: "Code Review", elapsed: "5s", want: "ralphex: Code Review 5s"},
+		{name: "no phase yet", phase: "", elapsed: "0s", want: "ralphex 0s"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, formatTitle(tc.phase, tc.elapsed))
+		})
+	}
+}
+
+func TestRunTitleUpdates(t *testing.T) {
+	t.Run("sets and restores title", func(t *testing.T) {
+		var buf lockedBuffer
+		holder := &status.PhaseHolder{}
+		holder.Set(status.PhaseTask)
+
+		stop := runTitleUpdates(&buf, holder, nil, func() string { return "1s" }, time.Hour)
+		stop()
+		stop() // second call is a no-op
+
+		assert.Equal(t, titlePush+"\x1b]0;ralphex: task 1s\x07"+"\x1b]0;\x07"+titlePop, buf.String())
+	})
+
+	t.Run("follows phase changes with custom labels", func(t *testing.T) {
+		var buf lockedBuffer
+		holder := &status.PhaseHolder{}
+		holder.Set(status.PhaseTask)
+		names := status.PhaseNames{status.PhaseReview: "Code Review"}
+
+		stop := runTitleUpdates(&buf, holder, names, func() string { return "2m" }, 10*time.Millisecond)
+		holder.Set(status.PhaseReview)
+		require.Eventually(t, func() bool {
+			return strings.Contains(buf.String(), "ralphex: Code Review 2m")
+		}, time.Second, 10*time.Millisecond)
+		stop()
+
+		out := buf.String()
+		assert.Equal(t, 1, strings.Count(out, "ralphex: task 2m"), "unchanged title should not be rewritten")
+		assert.True(t, strings.HasSuffix(out, titlePop))
+	})
+
+	t.Run("disabled returns noop", func(t *testing.T) {
+		stop := startTerminalTitle(false, false, &status.PhaseHolder{}, nil, func() string { return "" })
+		stop()
+	})
+}
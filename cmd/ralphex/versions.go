@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+// toolVersionTimeout limits how long a single "--version" probe may take.
+const toolVersionTimeout = 5 * time.Second
+
+// maxToolVersionLen caps a reported version, a binary that ignores --version may print a long banner.
+const maxToolVersionLen = 80
+
+// unknownToolVersion is reported for a binary that failed or printed nothing for --version.
+const unknownToolVersion = "unknown"
+
+// toolVersions holds the versions reported by the external CLIs used in a run.
+// a field is empty when the tool was not probed, e.g. codex with a custom external review tool.
+type toolVersions struct {
+	Claude string `json:"claude,omitempty"`
+	Codex  string `json:"codex,omitempty"`
+}
+
+// String formats the probed versions for the progress header and startup output,
+// e.g. "claude 2.1.3 (Claude Code), codex codex-cli 0.46.0". returns empty if nothing was probed.
+func (v toolVersions) String() string {
+	var parts []string
+	if v.Claude != "" {
+		parts = append(parts, "claude "+v.Claude)
+	}
+	if v.Codex != "" {
+		parts = append(parts, "codex "+v.Codex)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// detectToolVersions runs "<command> --version" for claude and, when the codex review phase needs it, for codex.
+// codex missing from PATH is not probed, the runner disables the codex phase for it anyway.
+func detectToolVersions(ctx context.Context, cfg *config.Config) toolVersions {
+	claudeCmd := cfg.ClaudeCommand
+	if claudeCmd == "" {
+		claudeCmd = "claude"
+	}
+	res := toolVersions{Claude: commandVersion(ctx, claudeCmd)}
+
+	if !cfg.CodexEnabled || cfg.ExternalReviewTool == "custom" || cfg.ExternalReviewTool == "none" {
+		return res
+	}
+	codexCmd := cfg.CodexCommand
+	if codexCmd == "" {
+		codexCmd = "codex"
+	}
+	if _, err := exec.LookPath(codexCmd); err == nil {
+		res.Codex = commandVersion(ctx, codexCmd)
+	}
+	return res
+}
+
+// commandVersion returns the first non-empty line printed by "command --version".
+// returns "unknown" if the command fails, times out or prints nothing.
+func commandVersion(ctx context.Context, command string) string {
+	ctx, cancel := context.WithTimeout(ctx, toolVersionTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, command, "--version").Output() //nolint:gosec // command comes from user config
+	if err != nil {
+		return unknownToolVersion
+	}
+	for line := range strings.SplitSeq(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			if len(line) > maxToolVersionLen {
+				line = line[:maxToolVersionLen]
+			}
+			return line
+		}
+	}
+	return unknownToolVersion
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+// writeScript creates an executable sh script in dir and returns its path.
+func writeScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o700))
+	return path
+}
+
+func TestCommandVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh scripts")
+	}
+	dir := t.TempDir()
+
+	t.Run("first non-empty line", func(t *testing.T) {
+		cmd := writeScript(t, dir, "tool-ok", "printf '\\n  2.1.3 (Claude Code)  \\nextra\\n'\n")
+		assert.Equal(t, "2.1.3 (Claude Code)", commandVersion(context.Background(), cmd))
+	})
+
+	t.Run("failing command", func(t *testing.T) {
+		cmd := writeScript(t, dir, "tool-fail", "echo 'unknown option --version' >&2\nexit 2\n")
+		assert.Equal(t, "unknown", commandVersion(context.Background(), cmd))
+	})
+
+	t.Run("no output", func(t *testing.T) {
+		cmd := writeScript(t, dir, "tool-silent", "exit 0\n")
+		assert.Equal(t, "unknown", commandVersion(context.Background(), cmd))
+	})
+
+	t.Run("long banner is truncated", func(t *testing.T) {
+		cmd := writeScript(t, dir, "tool-banner", "echo "+strings.Repeat("x", 200)+"\n")
+		assert.Len(t, commandVersion(context.Background(), cmd), maxToolVersionLen)
+	})
+
+	t.Run("missing binary", func(t *testing.T) {
+		assert.Equal(t, "unknown", commandVersion(context.Background(), filepath.Join(dir, "no-such-tool")))
+	})
+}
+
+func TestDetectToolVersions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh scripts")
+	}
+	dir := t.TempDir()
+	claude := writeScript(t, dir, "claude", "echo '2.1.3 (Claude Code)'\n")
+	codex := writeScript(t, dir, "codex", "echo 'codex-cli 0.46.0'\n")
+
+	t.Run("claude and codex", func(t *testing.T) {
+		cfg := &config.Config{ClaudeCommand: claude, CodexCommand: codex, CodexEnabled: true}
+		v := detectToolVersions(context.Background(), cfg)
+		assert.Equal(t, toolVersions{Claude: "2.1.3 (Claude Code)", Codex: "codex-cli 0.46.0"}, v)
+		assert.Equal(t, "claude 2.1.3 (Claude Code), codex codex-cli 0.46.0", v.String())
+	})
+
+	t.Run("codex not used", func(t *testing.T) {
+		cfg := &config.Config{ClaudeCommand: claude, CodexCommand: codex, CodexEnabled: true, ExternalReviewTool: "custom"}
+		assert.Equal(t, toolVersions{Claude: "2.1.3 (Claude Code)"}, detectToolVersions(context.Background(), cfg))
+
+		cfg = &config.Config{ClaudeCommand: claude, CodexCommand: codex}
+		assert.Empty(t, detectToolVersions(context.Background(), cfg).Codex, "codex disabled")
+	})
+
+	t.Run("codex not installed", func(t *testing.T) {
+		cfg := &config.Config{ClaudeCommand: claude, CodexCommand: filepath.Join(dir, "missing"), CodexEnabled: true}
+		assert.Equal(t, "claude 2.1.3 (Claude Code)", detectToolVersions(context.Background(), cfg).String())
+	})
+}
@@ -0,0 +1,50 @@
+// Package cmdargs splits configured argument strings, e.g. claude_args or fzf_args, into command arguments.
+package cmdargs
+
+import "strings"
+
+// Split splits a space-separated argument string into a slice.
+// handles quoted strings (both single and double quotes) and backslash escapes, empty quoted arguments are dropped.
+func Split(s string) []string {
+	return split(s, false)
+}
+
+// SplitShell splits a space-separated argument string into a slice, the way a shell splits words.
+// unlike Split, backslashes are literal inside single quotes and empty quoted arguments are kept.
+func SplitShell(s string) []string {
+	return split(s, true)
+}
+
+func split(s string, shell bool) []string {
+	var args []string
+	var current strings.Builder
+	var inQuote rune
+	var escaped, hasArg bool
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && (!shell || inQuote != '\''):
+			escaped = true
+		case (r == '"' || r == '\'') && inQuote == 0:
+			inQuote, hasArg = r, shell
+		case r == inQuote:
+			inQuote = 0
+		case r == ' ' && inQuote == 0:
+			if hasArg || current.Len() > 0 {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if hasArg || current.Len() > 0 {
+		args = append(args, current.String())
+	}
+	return args
+}
@@ -0,0 +1,56 @@
+package cmdargs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "simple args", input: "--flag1 --flag2 value", want: []string{"--flag1", "--flag2", "value"}},
+		{name: "double quoted", input: `--flag "value with spaces"`, want: []string{"--flag", "value with spaces"}},
+		{name: "single quoted", input: `--flag 'value with spaces'`, want: []string{"--flag", "value with spaces"}},
+		{name: "empty string", input: "", want: nil},
+		{name: "only spaces", input: "   ", want: nil},
+		{name: "multiple spaces between", input: "arg1   arg2", want: []string{"arg1", "arg2"}},
+		{name: "mixed quotes", input: `--a "b" --c 'd'`, want: []string{"--a", "b", "--c", "d"}},
+		{name: "escaped quote", input: `--flag \"quoted\"`, want: []string{"--flag", `"quoted"`}},
+		{name: "real claude args", input: "--dangerously-skip-permissions --output-format stream-json --verbose", want: []string{"--dangerously-skip-permissions", "--output-format", "stream-json", "--verbose"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Split(tc.input)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSplitShell(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "empty", input: "", want: nil},
+		{name: "simple", input: "--reverse --border", want: []string{"--reverse", "--border"}},
+		{name: "extra spaces", input: "  --reverse   --border ", want: []string{"--reverse", "--border"}},
+		{name: "double quotes", input: `--preview "cat {}"`, want: []string{"--preview", "cat {}"}},
+		{name: "single quotes", input: `--preview='head -5 {}'`, want: []string{"--preview=head -5 {}"}},
+		{name: "nested quotes", input: `--preview "echo 'x y'"`, want: []string{"--preview", "echo 'x y'"}},
+		{name: "escaped space", input: `a\ b c`, want: []string{"a b", "c"}},
+		{name: "backslash in single quotes", input: `'a\b'`, want: []string{`a\b`}},
+		{name: "empty quoted arg", input: `--header ""`, want: []string{"--header", ""}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, SplitShell(tc.input))
+		})
+	}
+}
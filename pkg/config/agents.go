@@ -8,10 +8,60 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 )
 
+// AgentModes maps agent names to the execution modes they run in.
+// agents without an entry run in all modes.
+type AgentModes map[string][]string
+
+// Allows reports whether the named agent runs in the given mode.
+func (m AgentModes) Allows(agent, mode string) bool {
+	modes, ok := m[agent]
+	return !ok || slices.Contains(modes, mode)
+}
+
+// validateAgentModes checks that every agent referenced in agent_modes exists.
+func validateAgentModes(modes AgentModes, agents []CustomAgent) error {
+	names := make([]string, 0, len(modes))
+	for name := range modes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !slices.ContainsFunc(agents, func(a CustomAgent) bool { return a.Name == name }) {
+			return fmt.Errorf("invalid agent_modes: unknown agent %q", name)
+		}
+	}
+	return nil
+}
+
+// validateReviewAgents checks that every agent listed in a review agent set exists. key names the config option.
+func validateReviewAgents(key string, names []string, agents []CustomAgent) error {
+	for _, name := range names {
+		if !slices.ContainsFunc(agents, func(a CustomAgent) bool { return a.Name == name }) {
+			return fmt.Errorf("invalid %s: unknown agent %q", key, name)
+		}
+	}
+	return nil
+}
+
+// builtinAgentNames returns the names from disabled_review_agents that refer to built-in agents.
+// unknown names and custom agents are logged and dropped, only built-in agents can be disabled.
+func builtinAgentNames(names []string, agents []CustomAgent) []string {
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if !slices.ContainsFunc(agents, func(a CustomAgent) bool { return a.Name == name && a.Builtin }) {
+			log.Printf("[WARN] disabled_review_agents: %q is not a built-in agent, ignored", name)
+			continue
+		}
+		result = append(result, name)
+	}
+	return result
+}
+
 // agentLoader loads custom agent files from config directories with per-file fallback.
 type agentLoader struct {
 	embedFS embed.FS
@@ -67,8 +117,14 @@ func (al *agentLoader) Load(localDir, globalDir string) ([]CustomAgent, error) {
 		if prompt == "" {
 			continue
 		}
-		name := strings.TrimSuffix(filename, ".txt")
-		agents = append(agents, al.buildAgent(name, prompt))
+		embedded, embedErr := al.loadFromEmbedFS(filename)
+		if embedErr != nil {
+			return nil, embedErr
+		}
+		agent := al.buildAgent(strings.TrimSuffix(filename, ".txt"), prompt)
+		agent.Builtin = embedded != ""
+		agent.Overridden = agent.Builtin && prompt != embedded
+		agents = append(agents, agent)
 	}
 
 	sort.Slice(agents, func(i, j int) bool {
@@ -177,7 +233,7 @@ func (al *agentLoader) loadFileWithFallback(path, filename string) (string, erro
 		return content, nil
 	}
 	// warn only when frontmatter options are being dropped; silent fallback for all-commented files
-	if opts.Model != "" || opts.AgentType != "" {
+	if opts != (Options{}) {
 		log.Printf("[WARN] agent %s: no prompt body, falling back to embedded default (frontmatter options dropped)", filename)
 	}
 	return al.loadFromEmbedFS(filename)
@@ -24,6 +24,20 @@ func findAgent(agents []CustomAgent, name string) *CustomAgent {
 	return nil
 }
 
+func TestBuiltinAgentNames(t *testing.T) {
+	var buf bytes.Buffer
+	origOut := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(origOut) })
+
+	agents := []CustomAgent{{Name: "quality", Builtin: true}, {Name: "testing", Builtin: true}, {Name: "security"}}
+	got := builtinAgentNames([]string{"testing", "security", "nope"}, agents)
+	assert.Equal(t, []string{"testing"}, got)
+	assert.Contains(t, buf.String(), `disabled_review_agents: "security" is not a built-in agent, ignored`)
+	assert.Contains(t, buf.String(), `disabled_review_agents: "nope" is not a built-in agent, ignored`)
+	assert.Empty(t, builtinAgentNames(nil, agents))
+}
+
 func Test_newAgentLoader(t *testing.T) {
 	loader := newAgentLoader(defaultsFS)
 	assert.NotNil(t, loader)
@@ -58,6 +72,21 @@ func TestAgentLoader_Load_FromAgentsDir(t *testing.T) {
 	}
 }
 
+func TestAgentLoader_Load_CommandAgent(t *testing.T) {
+	agentsDir := t.TempDir()
+	content := "# runs the linter, its report goes into the review prompt\n---\ntype: command\n---\ngolangci-lint run ./..."
+	require.NoError(t, os.WriteFile(filepath.Join(agentsDir, "lint.txt"), []byte(content), 0o600))
+
+	agents, err := newAgentLoader(defaultsFS).Load("", agentsDir)
+	require.NoError(t, err)
+
+	lint := findAgent(agents, "lint")
+	require.NotNil(t, lint)
+	assert.True(t, lint.IsCommand())
+	assert.Equal(t, "golangci-lint run ./...", lint.Prompt)
+	assert.False(t, findAgent(agents, "quality").IsCommand(), "built-in agents are prompt agents")
+}
+
 func TestAgentLoader_Load_NoAgentsDir_FallsBackToEmbedded(t *testing.T) {
 	tmpDir := t.TempDir()
 	nonexistentAgentsDir := filepath.Join(tmpDir, "nonexistent", "agents")
@@ -249,6 +278,38 @@ func TestAgentLoader_Load_LocalOverridesGlobalPerFile(t *testing.T) {
 	assert.Equal(t, "global performance", perf.Prompt)
 }
 
+func TestAgentLoader_Load_MarksBuiltinAndOverridden(t *testing.T) {
+	agentsDir := filepath.Join(t.TempDir(), "agents")
+	require.NoError(t, os.MkdirAll(agentsDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(agentsDir, "quality.txt"), []byte("my quality review"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(agentsDir, "security.txt"), []byte("check for security issues"), 0o600))
+	embedded, err := defaultsFS.ReadFile("defaults/agents/testing.txt")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(agentsDir, "testing.txt"), embedded, 0o600))
+
+	agents, err := newAgentLoader(defaultsFS).Load("", agentsDir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		builtin    bool
+		overridden bool
+	}{
+		{name: "quality", builtin: true, overridden: true},
+		{name: "testing", builtin: true, overridden: false},
+		{name: "documentation", builtin: true, overridden: false},
+		{name: "security", builtin: false, overridden: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			agent := findAgent(agents, tc.name)
+			require.NotNil(t, agent)
+			assert.Equal(t, tc.builtin, agent.Builtin)
+			assert.Equal(t, tc.overridden, agent.Overridden)
+		})
+	}
+}
+
 func TestAgentLoader_Load_LocalAgentsEmptyFallsBackToGlobal(t *testing.T) {
 	tmpDir := t.TempDir()
 	globalDir := filepath.Join(tmpDir, "global", "agents")
@@ -729,3 +790,25 @@ func TestAgentLoader_Load_ResultsSortedAlphabetically(t *testing.T) {
 		assert.Less(t, agents[i-1].Name, agents[i].Name, "agents should be sorted alphabetically")
 	}
 }
+
+func TestAgentModes_Allows(t *testing.T) {
+	modes := AgentModes{"testing": {"full", "tasks-only"}}
+	tests := []struct {
+		agent, mode string
+		want        bool
+	}{
+		{agent: "testing", mode: "full", want: true},
+		{agent: "testing", mode: "tasks-only", want: true},
+		{agent: "testing", mode: "review", want: false},
+		{agent: "quality", mode: "review", want: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.agent+"/"+tc.mode, func(t *testing.T) {
+			assert.Equal(t, tc.want, modes.Allows(tc.agent, tc.mode))
+		})
+	}
+
+	t.Run("nil map allows everything", func(t *testing.T) {
+		assert.True(t, AgentModes(nil).Allows("testing", "review"))
+	})
+}
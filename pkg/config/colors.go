@@ -88,6 +88,9 @@ func (cl *colorLoader) parseColorsFromBytes(data []byte) (ColorConfig, error) {
 
 	var colors ColorConfig
 	section := cfg.Section("")
+	if err := interpolateEnv(section); err != nil {
+		return ColorConfig{}, err
+	}
 	colorKeys := []struct {
 		key   string
 		field *string
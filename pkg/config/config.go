@@ -4,11 +4,16 @@ package config
 import (
 	"embed"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/umputun/ralphex/pkg/hooks"
 	"github.com/umputun/ralphex/pkg/notify"
+	"github.com/umputun/ralphex/pkg/status"
 )
 
 //go:embed defaults/config defaults/prompts/* defaults/agents/*
@@ -25,6 +30,7 @@ const (
 	customReviewPromptFile = "custom_review.txt"
 	customEvalPromptFile   = "custom_eval.txt"
 	codexReviewPromptFile  = "codex_review.txt"
+	taskReviewPromptFile   = "task_review.txt"
 )
 
 // Config holds all configuration settings for ralphex.
@@ -33,18 +39,53 @@ const (
 // merge behavior where local config can override global config with zero values.
 //
 // *Set fields:
+//   - ClaudeOutputFilterSet: tracks if claude_output_filter was explicitly set
+//   - StripOutputANSISet: tracks if strip_output_ansi was explicitly set
 //   - CodexEnabledSet: tracks if codex_enabled was explicitly set
 //   - CodexTimeoutMsSet: tracks if codex_timeout_ms was explicitly set
 //   - IterationDelayMsSet: tracks if iteration_delay_ms was explicitly set
+//   - CodexBlameHintsSet: tracks if codex_blame_hints was explicitly set
+//   - CodexFailOnP1Set: tracks if codex_fail_on_p1 was explicitly set
+//   - ClaudeIdleTimeoutSet: tracks if claude_idle_timeout was explicitly set
+//   - DashboardBatchSet: tracks if dashboard_batch_interval was explicitly set
+//   - HeartbeatIntervalSet: tracks if heartbeat_interval was explicitly set
 //   - TaskRetryCountSet: tracks if task_retry_count was explicitly set
+//   - ReviewRetryCountSet: tracks if review_retry_count was explicitly set
 //   - FinalizeEnabledSet: tracks if finalize_enabled was explicitly set
+//   - FinalizeNoCommitSet: tracks if finalize_no_commit was explicitly set
+//   - IncludeCommitLogSet: tracks if include_commit_log was explicitly set
+//   - EmbedPlanInPromptSet: tracks if embed_plan_in_prompt was explicitly set
+//   - TagOnCompleteSet: tracks if tag_on_complete was explicitly set
+//   - WriteSummarySet: tracks if write_summary was explicitly set
+//   - ReportTestResultsSet: tracks if report_test_results was explicitly set
+//   - SuppressDeprecationWarningsSet: tracks if suppress_deprecation_warnings was explicitly set
+//   - ReviewPerTaskSet: tracks if review_per_task was explicitly set
+//   - SkipFinalReviewSet: tracks if skip_final_review was explicitly set
+//   - ReviewWorkingTreeSet: tracks if review_working_tree was explicitly set
+//   - ReviewIncludePlanSet: tracks if review_include_plan was explicitly set
+//   - AutoUnshallowSet: tracks if auto_unshallow was explicitly set
+//   - AllowExternalPlansSet: tracks if allow_external_plans was explicitly set
+//   - TerminalTitleSet: tracks if terminal_title was explicitly set
+//   - SplitProgressByTaskSet: tracks if split_progress_by_task was explicitly set
 //   - WorktreeEnabledSet: tracks if use_worktree was explicitly set
+//   - KeepWorktreeOnFailureSet: tracks if keep_worktree_on_failure was explicitly set
 //   - MaxIterationsSet: tracks if max_iterations was explicitly set
+//   - PlanMaxTasksSet: tracks if plan_max_tasks was explicitly set
+//   - EmptyIterationLimitSet: tracks if empty_iteration_limit was explicitly set
+//   - MinFreeDiskMBSet: tracks if min_free_disk_mb was explicitly set
 //   - WaitOnLimitSet: tracks if wait_on_limit was explicitly set
 type Config struct {
 	ClaudeCommand string `json:"claude_command"`
 	ClaudeArgs    string `json:"claude_args"`
 
+	ClaudeOutputFilter    bool `json:"claude_output_filter"`
+	ClaudeOutputFilterSet bool `json:"-"` // tracks if claude_output_filter was explicitly set in config
+
+	StripOutputANSI    bool `json:"strip_output_ansi"`
+	StripOutputANSISet bool `json:"-"` // tracks if strip_output_ansi was explicitly set in config
+
+	PlanModel string `json:"plan_model"` // claude model for plan creation mode, empty = claude_args/default model
+
 	CodexEnabled         bool   `json:"codex_enabled"`
 	CodexEnabledSet      bool   `json:"-"` // tracks if codex_enabled was explicitly set in config
 	CodexCommand         string `json:"codex_command"`
@@ -61,21 +102,128 @@ type Config struct {
 	IterationDelayMsSet   bool `json:"-"` // tracks if iteration_delay_ms was explicitly set in config
 	TaskRetryCount        int  `json:"task_retry_count"`
 	TaskRetryCountSet     bool `json:"-"` // tracks if task_retry_count was explicitly set in config
+	ReviewRetryCount      int  `json:"review_retry_count"`
+	ReviewRetryCountSet   bool `json:"-"` // tracks if review_retry_count was explicitly set in config
 	MaxIterations         int  `json:"max_iterations"`
 	MaxIterationsSet      bool `json:"-"` // tracks if max_iterations was explicitly set in config
 	MaxExternalIterations int  `json:"max_external_iterations"`
+	MaxExternalRounds     int  `json:"max_external_rounds"` // cap on external review rounds across the run, 0 = no cap
 	ReviewPatience        int  `json:"review_patience"`
+	CodexMinDiffLines     int  `json:"codex_min_diff_lines"`
+	PlanMaxTasks          int  `json:"plan_max_tasks"`
+	PlanMaxTasksSet       bool `json:"-"` // tracks if plan_max_tasks was explicitly set in config
+
+	EmptyIterationLimit    int  `json:"empty_iteration_limit"`
+	EmptyIterationLimitSet bool `json:"-"` // tracks if empty_iteration_limit was explicitly set in config
+
+	MinFreeDiskMB    int  `json:"min_free_disk_mb"` // abort the run when free space drops below this, 0 = disabled
+	MinFreeDiskMBSet bool `json:"-"`                // tracks if min_free_disk_mb was explicitly set in config
+
+	CodexBlameHints    bool `json:"codex_blame_hints"`
+	CodexBlameHintsSet bool `json:"-"` // tracks if codex_blame_hints was explicitly set in config
+
+	CodexFailOnP1    bool `json:"codex_fail_on_p1"`
+	CodexFailOnP1Set bool `json:"-"` // tracks if codex_fail_on_p1 was explicitly set in config
+
+	// named codex passes run in each external review round, empty = single pass with codex_review.txt
+	CodexReviewers []CodexReviewer `json:"codex_reviewers,omitempty"`
+
+	// when each codex reviewer runs, keyed by reviewer name. reviewers without an entry run in every round
+	CodexReviewerConditions map[string]ReviewerConditions `json:"codex_reviewer_conditions,omitempty"`
+
+	// focus snippets for {{REVIEW_FOCUS}}, each added when a changed file matches its pattern
+	ReviewFocus []ReviewFocus `json:"review_focus,omitempty"`
 
 	FinalizeEnabled    bool `json:"finalize_enabled"`
 	FinalizeEnabledSet bool `json:"-"` // tracks if finalize_enabled was explicitly set in config
 
+	FinalizeNoCommit    bool `json:"finalize_no_commit"`
+	FinalizeNoCommitSet bool `json:"-"` // tracks if finalize_no_commit was explicitly set in config
+
+	PreRunCommand  string `json:"pre_run_command"`  // shell command run before the runner starts
+	PostRunCommand string `json:"post_run_command"` // shell command run after the runner finishes
+
+	IncludeCommitLog    bool `json:"include_commit_log"`
+	IncludeCommitLogSet bool `json:"-"` // tracks if include_commit_log was explicitly set in config
+
+	EmbedPlanInPrompt    bool `json:"embed_plan_in_prompt"`
+	EmbedPlanInPromptSet bool `json:"-"` // tracks if embed_plan_in_prompt was explicitly set in config
+
+	TagOnComplete    bool `json:"tag_on_complete"`
+	TagOnCompleteSet bool `json:"-"` // tracks if tag_on_complete was explicitly set in config
+
+	WriteSummary    bool `json:"write_summary"`
+	WriteSummarySet bool `json:"-"` // tracks if write_summary was explicitly set in config
+
+	ReportTestResults    bool `json:"report_test_results"`
+	ReportTestResultsSet bool `json:"-"` // tracks if report_test_results was explicitly set in config
+
+	SuppressDeprecationWarnings    bool `json:"suppress_deprecation_warnings"`
+	SuppressDeprecationWarningsSet bool `json:"-"` // tracks if suppress_deprecation_warnings was explicitly set in config
+
+	ReviewPerTask    bool `json:"review_per_task"`
+	ReviewPerTaskSet bool `json:"-"` // tracks if review_per_task was explicitly set in config
+
+	SkipFinalReview    bool `json:"skip_final_review"`
+	SkipFinalReviewSet bool `json:"-"` // tracks if skip_final_review was explicitly set in config
+
+	ReviewWorkingTree    bool `json:"review_working_tree"`
+	ReviewWorkingTreeSet bool `json:"-"` // tracks if review_working_tree was explicitly set in config
+
+	ReviewIncludePlan    bool `json:"review_include_plan"`
+	ReviewIncludePlanSet bool `json:"-"` // tracks if review_include_plan was explicitly set in config
+
+	AutoUnshallow    bool `json:"auto_unshallow"`
+	AutoUnshallowSet bool `json:"-"` // tracks if auto_unshallow was explicitly set in config
+
+	AllowExternalPlans    bool `json:"allow_external_plans"`
+	AllowExternalPlansSet bool `json:"-"` // tracks if allow_external_plans was explicitly set in config
+
+	TerminalTitle    bool `json:"terminal_title"`
+	TerminalTitleSet bool `json:"-"` // tracks if terminal_title was explicitly set in config
+
+	SplitProgressByTask    bool `json:"split_progress_by_task"`
+	SplitProgressByTaskSet bool `json:"-"` // tracks if split_progress_by_task was explicitly set in config
+
 	WorktreeEnabled    bool `json:"worktree_enabled"`
 	WorktreeEnabledSet bool `json:"-"` // tracks if use_worktree was explicitly set in config
 
-	PlansDir      string   `json:"plans_dir"`
-	WatchDirs     []string `json:"watch_dirs"`     // directories to watch for progress files
-	DefaultBranch string   `json:"default_branch"` // override auto-detected default branch
-	VcsCommand    string   `json:"vcs_command"`    // custom VCS command (default: "git")
+	KeepWorktreeOnFailure    bool `json:"keep_worktree_on_failure"`
+	KeepWorktreeOnFailureSet bool `json:"-"` // tracks if keep_worktree_on_failure was explicitly set in config
+
+	PlansDir       string   `json:"plans_dir"`
+	WatchDirs      []string `json:"watch_dirs"`      // directories to watch for progress files
+	DefaultBranch  string   `json:"default_branch"`  // override auto-detected default branch
+	PushRemote     string   `json:"push_remote"`     // remote pushed to, empty = origin
+	ProgressBranch string   `json:"progress_branch"` // orphan branch progress files are committed to, empty = off
+	DefaultMode    string   `json:"default_mode"`    // execution mode used when no mode flag is given
+	TaskOrder      string   `json:"task_order"`      // how the next plan task is picked, empty = sequential
+	TestCommand    string   `json:"test_command"`    // project test command, auto-detected when empty
+	VcsCommand     string   `json:"vcs_command"`     // custom VCS command (default: "git")
+
+	BranchNameTemplate string `json:"branch_name_template"` // branch name derived from a plan, {{SLUG}} = plan slug
+	InProgressMarker   string `json:"in_progress_marker"`   // checkbox mark of an in-progress plan item, empty = "~"
+	BranchStripPattern string `json:"branch_strip_pattern"` // regex removed from the plan file name start, empty = date prefix
+	CommitTrailer      string `json:"commit_trailer"`       // trailer added to every ralphex commit, {{VERSION}} = ralphex version
+
+	// ref the feature branch is pushed to after a completed run, {{BRANCH}} = branch name, empty = no push
+	PushRefspecTemplate string `json:"push_refspec_template"`
+
+	FzfCommand string `json:"fzf_command"` // fzf binary used for plan selection (default: "fzf")
+	FzfArgs    string `json:"fzf_args"`    // extra fzf arguments appended after the built-in ones
+
+	PhaseNames status.PhaseNames `json:"phase_names,omitempty"` // custom phase display labels (unmapped phases use default names)
+	AgentModes AgentModes        `json:"agent_modes,omitempty"` // modes each agent runs in (unlisted agents run in all modes)
+
+	ReviewFirstAgents  []string `json:"review_first_agents"`  // agents {{REVIEW_AGENTS}} expands to in the first review pass
+	ReviewSecondAgents []string `json:"review_second_agents"` // agents {{REVIEW_AGENTS}} expands to in the second review pass
+
+	DisabledReviewAgents []string `json:"disabled_review_agents,omitempty"` // built-in agents dropped from the review prompts
+
+	HookFailure map[hooks.Point]hooks.Failure `json:"hook_failure,omitempty"` // per-hook failure mode for .ralphex/hooks/ scripts
+
+	EstimateTokens  map[string]int `json:"estimate_tokens,omitempty"`  // --estimate average tokens per iteration by phase
+	EstimateMinutes map[string]int `json:"estimate_minutes,omitempty"` // --estimate average minutes per iteration by phase
 
 	// error patterns to detect in executor output (e.g., rate limit messages)
 	ClaudeErrorPatterns []string `json:"claude_error_patterns"`
@@ -86,11 +234,24 @@ type Config struct {
 	CodexLimitPatterns  []string      `json:"codex_limit_patterns"`
 	WaitOnLimit         time.Duration `json:"wait_on_limit"`
 	WaitOnLimitSet      bool          `json:"-"` // tracks if wait_on_limit was explicitly set in config
+	MaxLimitRetries     int           `json:"max_limit_retries"`
 
 	// session timeout for claude sessions (kills hanging sessions)
 	SessionTimeout    time.Duration `json:"session_timeout"`
 	SessionTimeoutSet bool          `json:"-"` // tracks if session_timeout was explicitly set in config
 
+	// idle timeout for claude sessions (kills sessions that stopped producing output)
+	ClaudeIdleTimeout    time.Duration `json:"claude_idle_timeout"`
+	ClaudeIdleTimeoutSet bool          `json:"-"` // tracks if claude_idle_timeout was explicitly set in config
+
+	// coalescing interval for live dashboard output (0 = push every line)
+	DashboardBatchInterval time.Duration `json:"dashboard_batch_interval"`
+	DashboardBatchSet      bool          `json:"-"` // tracks if dashboard_batch_interval was explicitly set in config
+
+	// interval of "still working" progress log lines during long executor calls (0 = disabled)
+	HeartbeatInterval    time.Duration `json:"heartbeat_interval"`
+	HeartbeatIntervalSet bool          `json:"-"` // tracks if heartbeat_interval was explicitly set in config
+
 	// notification parameters
 	NotifyParams notify.Params `json:"-"`
 
@@ -107,12 +268,17 @@ type Config struct {
 	CustomReviewPrompt string `json:"-"`
 	CustomEvalPrompt   string `json:"-"`
 	CodexReviewPrompt  string `json:"-"`
+	TaskReviewPrompt   string `json:"-"`
 
 	// custom agents (loaded separately from files)
 	CustomAgents []CustomAgent `json:"-"`
 
-	configDir string // private, global config directory set by Load()
-	localDir  string // private, local project config directory (.ralphex/) if found
+	// named profiles from [profile <name>] sections, each holding only the keys it sets
+	Profiles map[string]*Config `json:"-"`
+
+	configDir string  // private, global config directory set by Load()
+	localDir  string  // private, local project config directory (.ralphex/) if found
+	values    *Values // private, the config values c was built from, layered by Merge; nil for a hand-built config
 }
 
 // CustomAgent represents a user-defined review agent.
@@ -120,6 +286,54 @@ type CustomAgent struct {
 	Name    string // filename without extension
 	Prompt  string // contents of the agent file (body after options header)
 	Options        // embedded: model and agent type parsed from frontmatter
+
+	Builtin    bool // agent ships with ralphex as an embedded default
+	Overridden bool // built-in agent replaced by a user agent file with different content
+}
+
+// CodexReviewer is a named codex review pass from codex_reviewers, e.g. a security-only review.
+type CodexReviewer struct {
+	Name       string `json:"name"`
+	ProjectDoc string `json:"project_doc,omitempty"` // instructions file passed to codex as project_doc, empty = codex default
+	Prompt     string `json:"-"`                     // prompts/codex_review_<name>.txt, empty = the codex_review prompt
+}
+
+// ReviewFocus is a review_focus entry, the prompts/review_focus_<name>.txt snippet added to {{REVIEW_FOCUS}}
+// when a changed file matches the pattern.
+type ReviewFocus struct {
+	Pattern string `json:"pattern"`
+	Name    string `json:"name"`
+	Prompt  string `json:"-"` // content of review_focus_<name>.txt
+}
+
+// ReviewerConditions limit when a codex reviewer runs, the zero value runs it always.
+type ReviewerConditions struct {
+	Modes        []string `json:"modes,omitempty"`          // execution modes the reviewer runs in, empty = all modes
+	MinDiffLines int      `json:"min_diff_lines,omitempty"` // minimum added+deleted lines of the diff, 0 = any size
+	Paths        []string `json:"paths,omitempty"`          // changed file patterns, at least one must match, empty = any change
+}
+
+// mergeReviewerConditions layers the conditions of override over base field by field for each reviewer.
+func mergeReviewerConditions(base, override map[string]ReviewerConditions) map[string]ReviewerConditions {
+	if len(override) == 0 {
+		return base
+	}
+	res := make(map[string]ReviewerConditions, len(base)+len(override))
+	maps.Copy(res, base)
+	for name, src := range override {
+		dst := res[name]
+		if len(src.Modes) > 0 {
+			dst.Modes = src.Modes
+		}
+		if src.MinDiffLines > 0 {
+			dst.MinDiffLines = src.MinDiffLines
+		}
+		if len(src.Paths) > 0 {
+			dst.Paths = src.Paths
+		}
+		res[name] = dst
+	}
+	return res
 }
 
 // ColorConfig holds RGB values for output colors.
@@ -150,6 +364,58 @@ func Load(configDir string) (*Config, error) {
 	return loadWithLocal(globalDir, localDir)
 }
 
+// LoadWithFile loads configuration like Load and layers the config file at configFile over it,
+// as the highest-precedence user config: its keys win over local and global config, CLI flags still win over it.
+// the file uses the same format as the config in the config directory. an empty configFile is the same as Load.
+func LoadWithFile(configDir, configFile string) (*Config, error) {
+	c, err := Load(configDir)
+	if err != nil {
+		return nil, err
+	}
+	if configFile == "" {
+		return c, nil
+	}
+	fileCfg, err := c.loadConfigFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	return c.Merge(fileCfg), nil
+}
+
+// loadConfigFile parses a single config file into a Config holding only the keys it sets, like a profile.
+// agent references are checked against the agents of c, reviewer prompts come from c's prompt directories.
+func (c *Config) loadConfigFile(path string) (*Config, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+	values, err := newValuesLoader(defaultsFS).parseValuesFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	colors, err := newColorLoader(defaultsFS).parseColorsFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	var localPromptsPath string
+	if c.localDir != "" {
+		localPromptsPath = filepath.Join(c.localDir, "prompts")
+	}
+	pl := newPromptLoader(defaultsFS)
+	loadPrompt := func(file string) (string, error) {
+		return pl.loadPromptWithLocalFallback(localPromptsPath, filepath.Join(c.configDir, "prompts"), file)
+	}
+	res, err := configFromResolvedValues(values, c.CustomAgents, loadPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	res.Colors = colors
+	if res.Profiles, err = profilesFromValues(values.Profiles, c.CustomAgents, loadPrompt); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return res, nil
+}
+
 // loadWithLocal loads configuration with explicit global and local directories.
 // local config (.ralphex/) overrides global config (~/.config/ralphex/) per-field.
 // if localDir is empty, only global config is used.
@@ -265,45 +531,264 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("load agents: %w", err)
 	}
+	loadPrompt := func(file string) (string, error) {
+		return pl.loadPromptWithLocalFallback(localPromptsPath, globalPromptsPath, file)
+	}
+	reviewers, disabledAgents, err := resolveAgentValues(values, agents, loadPrompt)
+	if err != nil {
+		return nil, err
+	}
+	focus, err := resolveReviewFocus(values.ReviewFocus, loadPrompt)
+	if err != nil {
+		return nil, err
+	}
 
 	// assemble config
+	c := configFromValues(values)
+	c.CodexReviewers = reviewers
+	c.ReviewFocus = focus
+	c.DisabledReviewAgents = disabledAgents
+	c.Colors = colors
+	c.TaskPrompt = prompts.Task
+	c.ReviewFirstPrompt = prompts.ReviewFirst
+	c.ReviewSecondPrompt = prompts.ReviewSecond
+	c.CodexPrompt = prompts.Codex
+	c.MakePlanPrompt = prompts.MakePlan
+	c.FinalizePrompt = prompts.Finalize
+	c.CustomReviewPrompt = prompts.CustomReview
+	c.CustomEvalPrompt = prompts.CustomEval
+	c.CodexReviewPrompt = prompts.CodexReview
+	c.TaskReviewPrompt = prompts.TaskReview
+	c.CustomAgents = agents
+	c.configDir = globalDir
+	c.localDir = localDir
+
+	// named profiles carry only the keys they set, ApplyProfile layers one over the config
+	if c.Profiles, err = profilesFromValues(values.Profiles, agents, loadPrompt); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// profilesFromValues builds the named profiles, each holding only the keys its section sets.
+// returns nil when there are no profiles.
+func profilesFromValues(profiles map[string]Values, agents []CustomAgent,
+	loadPrompt func(file string) (string, error)) (map[string]*Config, error) {
+	var res map[string]*Config
+	for name, pv := range profiles {
+		p, err := configFromResolvedValues(pv, agents, loadPrompt)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", name, err)
+		}
+		if res == nil {
+			res = make(map[string]*Config, len(profiles))
+		}
+		res[name] = p
+	}
+	return res, nil
+}
+
+// configFromResolvedValues maps values onto a Config with the agent references resolved, see resolveAgentValues.
+func configFromResolvedValues(values Values, agents []CustomAgent, loadPrompt func(file string) (string, error)) (*Config, error) {
+	reviewers, disabled, err := resolveAgentValues(values, agents, loadPrompt)
+	if err != nil {
+		return nil, err
+	}
+	focus, err := resolveReviewFocus(values.ReviewFocus, loadPrompt)
+	if err != nil {
+		return nil, err
+	}
+	c := configFromValues(values)
+	c.CodexReviewers = reviewers
+	c.ReviewFocus = focus
+	c.DisabledReviewAgents = disabled
+	return c, nil
+}
+
+// resolveAgentValues validates the agent references in values against the loaded agents and returns
+// the codex reviewers with their prompts loaded and the disabled built-in review agents.
+// loadPrompt loads a prompt file by name, a reviewer without its own file keeps an empty prompt.
+func resolveAgentValues(values Values, agents []CustomAgent,
+	loadPrompt func(file string) (string, error)) ([]CodexReviewer, []string, error) {
+	if err := validateAgentModes(values.AgentModes, agents); err != nil {
+		return nil, nil, err
+	}
+	if err := validateReviewAgents("review_first_agents", values.ReviewFirstAgents, agents); err != nil {
+		return nil, nil, err
+	}
+	if err := validateReviewAgents("review_second_agents", values.ReviewSecondAgents, agents); err != nil {
+		return nil, nil, err
+	}
+	disabledAgents := builtinAgentNames(values.DisabledReviewAgents, agents)
+
+	// conditions may come without codex_reviewers, e.g. in a profile layered over the main config
+	if len(values.CodexReviewers) > 0 {
+		for _, name := range slices.Sorted(maps.Keys(values.CodexReviewerConditions)) {
+			if !slices.ContainsFunc(values.CodexReviewers, func(r CodexReviewer) bool { return r.Name == name }) {
+				return nil, nil, fmt.Errorf("invalid codex reviewer conditions: unknown reviewer %q, not listed in codex_reviewers", name)
+			}
+		}
+	}
+
+	// per-reviewer codex prompts, a missing file leaves the reviewer on the codex_review prompt
+	reviewers := values.CodexReviewers
+	for i := range reviewers {
+		file := "codex_review_" + reviewers[i].Name + ".txt"
+		prompt, err := loadPrompt(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load %s prompt: %w", file, err)
+		}
+		reviewers[i].Prompt = prompt
+	}
+	return reviewers, disabledAgents, nil
+}
+
+// resolveReviewFocus loads the review_focus_<name>.txt snippet of each review_focus entry.
+// unlike codex reviewer prompts there is nothing to fall back to, so a missing or empty file is an error.
+func resolveReviewFocus(focus []ReviewFocus, loadPrompt func(file string) (string, error)) ([]ReviewFocus, error) {
+	if len(focus) == 0 {
+		return nil, nil
+	}
+	result := make([]ReviewFocus, 0, len(focus))
+	for _, f := range focus {
+		file := "review_focus_" + f.Name + ".txt"
+		prompt, err := loadPrompt(file)
+		if err != nil {
+			return nil, fmt.Errorf("load %s prompt: %w", file, err)
+		}
+		if strings.TrimSpace(prompt) == "" {
+			return nil, fmt.Errorf("invalid review_focus: prompts/%s is missing or empty", file)
+		}
+		f.Prompt = prompt
+		result = append(result, f)
+	}
+	return result, nil
+}
+
+// configFromValues maps parsed config values onto a Config and keeps them for Merge.
+// colors, prompts and agents are loaded separately and are left empty.
+func configFromValues(values Values) *Config {
 	c := &Config{
-		ClaudeCommand:         values.ClaudeCommand,
-		ClaudeArgs:            values.ClaudeArgs,
-		CodexEnabled:          values.CodexEnabled,
-		CodexEnabledSet:       values.CodexEnabledSet,
-		CodexCommand:          values.CodexCommand,
-		CodexModel:            values.CodexModel,
-		CodexReasoningEffort:  values.CodexReasoningEffort,
-		CodexTimeoutMs:        values.CodexTimeoutMs,
-		CodexTimeoutMsSet:     values.CodexTimeoutMsSet,
-		CodexSandbox:          values.CodexSandbox,
-		ExternalReviewTool:    values.ExternalReviewTool,
-		CustomReviewScript:    values.CustomReviewScript,
-		IterationDelayMs:      values.IterationDelayMs,
-		IterationDelayMsSet:   values.IterationDelayMsSet,
-		TaskRetryCount:        values.TaskRetryCount,
-		TaskRetryCountSet:     values.TaskRetryCountSet,
-		MaxIterations:         values.MaxIterations,
-		MaxIterationsSet:      values.MaxIterationsSet,
-		MaxExternalIterations: values.MaxExternalIterations,
-		ReviewPatience:        values.ReviewPatience,
-		FinalizeEnabled:       values.FinalizeEnabled,
-		FinalizeEnabledSet:    values.FinalizeEnabledSet,
-		WorktreeEnabled:       values.WorktreeEnabled,
-		WorktreeEnabledSet:    values.WorktreeEnabledSet,
-		PlansDir:              values.PlansDir,
-		DefaultBranch:         values.DefaultBranch,
-		VcsCommand:            values.VcsCommand,
-		WatchDirs:             values.WatchDirs,
-		ClaudeErrorPatterns:   values.ClaudeErrorPatterns,
-		CodexErrorPatterns:    values.CodexErrorPatterns,
-		ClaudeLimitPatterns:   values.ClaudeLimitPatterns,
-		CodexLimitPatterns:    values.CodexLimitPatterns,
-		WaitOnLimit:           values.WaitOnLimit,
-		WaitOnLimitSet:        values.WaitOnLimitSet,
-		SessionTimeout:        values.SessionTimeout,
-		SessionTimeoutSet:     values.SessionTimeoutSet,
+		ClaudeCommand:                  values.ClaudeCommand,
+		ClaudeArgs:                     values.ClaudeArgs,
+		ClaudeOutputFilter:             values.ClaudeOutputFilter,
+		ClaudeOutputFilterSet:          values.ClaudeOutputFilterSet,
+		StripOutputANSI:                values.StripOutputANSI,
+		StripOutputANSISet:             values.StripOutputANSISet,
+		PlanModel:                      values.PlanModel,
+		CodexEnabled:                   values.CodexEnabled,
+		CodexEnabledSet:                values.CodexEnabledSet,
+		CodexCommand:                   values.CodexCommand,
+		CodexModel:                     values.CodexModel,
+		CodexReasoningEffort:           values.CodexReasoningEffort,
+		CodexTimeoutMs:                 values.CodexTimeoutMs,
+		CodexTimeoutMsSet:              values.CodexTimeoutMsSet,
+		CodexSandbox:                   values.CodexSandbox,
+		ExternalReviewTool:             values.ExternalReviewTool,
+		CustomReviewScript:             values.CustomReviewScript,
+		IterationDelayMs:               values.IterationDelayMs,
+		IterationDelayMsSet:            values.IterationDelayMsSet,
+		TaskRetryCount:                 values.TaskRetryCount,
+		TaskRetryCountSet:              values.TaskRetryCountSet,
+		ReviewRetryCount:               values.ReviewRetryCount,
+		ReviewRetryCountSet:            values.ReviewRetryCountSet,
+		MaxIterations:                  values.MaxIterations,
+		MaxIterationsSet:               values.MaxIterationsSet,
+		MaxExternalIterations:          values.MaxExternalIterations,
+		MaxExternalRounds:              values.MaxExternalRounds,
+		ReviewPatience:                 values.ReviewPatience,
+		CodexMinDiffLines:              values.CodexMinDiffLines,
+		PlanMaxTasks:                   values.PlanMaxTasks,
+		PlanMaxTasksSet:                values.PlanMaxTasksSet,
+		EmptyIterationLimit:            values.EmptyIterationLimit,
+		EmptyIterationLimitSet:         values.EmptyIterationLimitSet,
+		MinFreeDiskMB:                  values.MinFreeDiskMB,
+		MinFreeDiskMBSet:               values.MinFreeDiskMBSet,
+		CodexBlameHints:                values.CodexBlameHints,
+		CodexBlameHintsSet:             values.CodexBlameHintsSet,
+		CodexFailOnP1:                  values.CodexFailOnP1,
+		CodexFailOnP1Set:               values.CodexFailOnP1Set,
+		CodexReviewers:                 values.CodexReviewers,
+		CodexReviewerConditions:        values.CodexReviewerConditions,
+		ReviewFocus:                    values.ReviewFocus,
+		FinalizeEnabled:                values.FinalizeEnabled,
+		FinalizeEnabledSet:             values.FinalizeEnabledSet,
+		FinalizeNoCommit:               values.FinalizeNoCommit,
+		FinalizeNoCommitSet:            values.FinalizeNoCommitSet,
+		PreRunCommand:                  values.PreRunCommand,
+		PostRunCommand:                 values.PostRunCommand,
+		IncludeCommitLog:               values.IncludeCommitLog,
+		IncludeCommitLogSet:            values.IncludeCommitLogSet,
+		EmbedPlanInPrompt:              values.EmbedPlanInPrompt,
+		EmbedPlanInPromptSet:           values.EmbedPlanInPromptSet,
+		TagOnComplete:                  values.TagOnComplete,
+		TagOnCompleteSet:               values.TagOnCompleteSet,
+		WriteSummary:                   values.WriteSummary,
+		WriteSummarySet:                values.WriteSummarySet,
+		ReportTestResults:              values.ReportTestResults,
+		ReportTestResultsSet:           values.ReportTestResultsSet,
+		SuppressDeprecationWarnings:    values.SuppressDeprecationWarnings,
+		SuppressDeprecationWarningsSet: values.SuppressDeprecationWarningsSet,
+		ReviewPerTask:                  values.ReviewPerTask,
+		ReviewPerTaskSet:               values.ReviewPerTaskSet,
+		SkipFinalReview:                values.SkipFinalReview,
+		SkipFinalReviewSet:             values.SkipFinalReviewSet,
+		ReviewWorkingTree:              values.ReviewWorkingTree,
+		ReviewWorkingTreeSet:           values.ReviewWorkingTreeSet,
+		ReviewIncludePlan:              values.ReviewIncludePlan,
+		ReviewIncludePlanSet:           values.ReviewIncludePlanSet,
+		AutoUnshallow:                  values.AutoUnshallow,
+		AutoUnshallowSet:               values.AutoUnshallowSet,
+		AllowExternalPlans:             values.AllowExternalPlans,
+		AllowExternalPlansSet:          values.AllowExternalPlansSet,
+		TerminalTitle:                  values.TerminalTitle,
+		TerminalTitleSet:               values.TerminalTitleSet,
+		SplitProgressByTask:            values.SplitProgressByTask,
+		SplitProgressByTaskSet:         values.SplitProgressByTaskSet,
+		WorktreeEnabled:                values.WorktreeEnabled,
+		WorktreeEnabledSet:             values.WorktreeEnabledSet,
+		KeepWorktreeOnFailure:          values.KeepWorktreeOnFailure,
+		KeepWorktreeOnFailureSet:       values.KeepWorktreeOnFailureSet,
+		PlansDir:                       values.PlansDir,
+		DefaultBranch:                  values.DefaultBranch,
+		PushRemote:                     values.PushRemote,
+		PushRefspecTemplate:            values.PushRefspecTemplate,
+		ProgressBranch:                 values.ProgressBranch,
+		InProgressMarker:               values.InProgressMarker,
+		BranchNameTemplate:             values.BranchNameTemplate,
+		CommitTrailer:                  values.CommitTrailer,
+		BranchStripPattern:             values.BranchStripPattern,
+		DefaultMode:                    values.DefaultMode,
+		TaskOrder:                      values.TaskOrder,
+		TestCommand:                    values.TestCommand,
+		VcsCommand:                     values.VcsCommand,
+		FzfCommand:                     values.FzfCommand,
+		FzfArgs:                        values.FzfArgs,
+		WatchDirs:                      values.WatchDirs,
+		PhaseNames:                     values.PhaseNames,
+		AgentModes:                     values.AgentModes,
+		ReviewFirstAgents:              values.ReviewFirstAgents,
+		ReviewSecondAgents:             values.ReviewSecondAgents,
+		DisabledReviewAgents:           values.DisabledReviewAgents,
+		HookFailure:                    values.HookFailure,
+		EstimateTokens:                 values.EstimateTokens,
+		EstimateMinutes:                values.EstimateMinutes,
+		ClaudeErrorPatterns:            values.ClaudeErrorPatterns,
+		CodexErrorPatterns:             values.CodexErrorPatterns,
+		ClaudeLimitPatterns:            values.ClaudeLimitPatterns,
+		CodexLimitPatterns:             values.CodexLimitPatterns,
+		WaitOnLimit:                    values.WaitOnLimit,
+		WaitOnLimitSet:                 values.WaitOnLimitSet,
+		MaxLimitRetries:                values.MaxLimitRetries,
+		SessionTimeout:                 values.SessionTimeout,
+		SessionTimeoutSet:              values.SessionTimeoutSet,
+		ClaudeIdleTimeout:              values.ClaudeIdleTimeout,
+		ClaudeIdleTimeoutSet:           values.ClaudeIdleTimeoutSet,
+		DashboardBatchInterval:         values.DashboardBatchInterval,
+		DashboardBatchSet:              values.DashboardBatchSet,
+		HeartbeatInterval:              values.HeartbeatInterval,
+		HeartbeatIntervalSet:           values.HeartbeatIntervalSet,
 		NotifyParams: notify.Params{
 			Channels:      values.NotifyChannels,
 			OnError:       values.NotifyOnError,
@@ -323,20 +808,8 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 			WebhookURLs:   values.NotifyWebhookURLs,
 			CustomScript:  values.NotifyCustomScript,
 		},
-		Colors:             colors,
-		TaskPrompt:         prompts.Task,
-		ReviewFirstPrompt:  prompts.ReviewFirst,
-		ReviewSecondPrompt: prompts.ReviewSecond,
-		CodexPrompt:        prompts.Codex,
-		MakePlanPrompt:     prompts.MakePlan,
-		FinalizePrompt:     prompts.Finalize,
-		CustomReviewPrompt: prompts.CustomReview,
-		CustomEvalPrompt:   prompts.CustomEval,
-		CodexReviewPrompt:  prompts.CodexReview,
-		CustomAgents:       agents,
-		configDir:          globalDir,
-		localDir:           localDir,
 	}
+	c.values = &values
 
 	// notify_on_error and notify_on_complete default to true when not explicitly set
 	if !values.NotifyOnErrorSet {
@@ -345,8 +818,20 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 	if !values.NotifyOnCompleteSet {
 		c.NotifyParams.OnComplete = true
 	}
+	return c
+}
 
-	return c, nil
+// ApplyProfile returns a new config with the named profile layered on top of c using Merge.
+// an unknown name is an error listing the available profiles.
+func (c *Config) ApplyProfile(name string) (*Config, error) {
+	p, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return nil, fmt.Errorf("unknown profile %q, no profiles defined", name)
+		}
+		return nil, fmt.Errorf("unknown profile %q, available: %s", name, strings.Join(slices.Sorted(maps.Keys(c.Profiles)), ", "))
+	}
+	return c.Merge(p), nil
 }
 
 // DefaultConfigDir returns the default configuration directory path.
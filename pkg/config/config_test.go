@@ -30,10 +30,11 @@ func Test_defaultsFS_PromptFiles(t *testing.T) {
 		contains []string
 	}{
 		{file: "defaults/prompts/task.txt", contains: []string{"{{PLAN_FILE}}", "{{PROGRESS_FILE}}", "RALPHEX:ALL_TASKS_DONE", "RALPHEX:TASK_FAILED", "Success criteria", "Task sections", "### Task N:", "mark them [x]", "do not loop indefinitely"}},
-		{file: "defaults/prompts/review_first.txt", contains: []string{"{{GOAL}}", "{{PROGRESS_FILE}}", "RALPHEX:REVIEW_DONE", "{{agent:quality}}", "{{agent:testing}}"}},
-		{file: "defaults/prompts/review_second.txt", contains: []string{"{{GOAL}}", "{{PROGRESS_FILE}}", "RALPHEX:REVIEW_DONE", "{{agent:quality}}", "{{agent:implementation}}"}},
+		{file: "defaults/prompts/review_first.txt", contains: []string{"{{GOAL}}", "{{PROGRESS_FILE}}", "RALPHEX:REVIEW_DONE", "{{REVIEW_AGENTS}}"}},
+		{file: "defaults/prompts/review_second.txt", contains: []string{"{{GOAL}}", "{{PROGRESS_FILE}}", "RALPHEX:REVIEW_DONE", "{{REVIEW_AGENTS}}"}},
 		{file: "defaults/prompts/codex.txt", contains: []string{"{{CODEX_OUTPUT}}", "RALPHEX:CODEX_REVIEW_DONE", "Codex reviewed"}},
 		{file: "defaults/prompts/codex_review.txt", contains: []string{"{{DIFF_INSTRUCTION}}", "{{PROGRESS_FILE}}", "{{PREVIOUS_REVIEW_CONTEXT}}", "{{PLAN_FILE}}"}},
+		{file: "defaults/prompts/task_review.txt", contains: []string{"{{TASK_NUMBER}}", "{{TASK_DIFF_RANGE}}", "RALPHEX:REVIEW_DONE", "{{REVIEW_AGENTS}}"}},
 	}
 
 	for _, tc := range testCases {
@@ -58,6 +59,7 @@ func Test_defaultsFS_AllFilesPresent(t *testing.T) {
 		"defaults/prompts/review_second.txt",
 		"defaults/prompts/codex.txt",
 		"defaults/prompts/codex_review.txt",
+		"defaults/prompts/task_review.txt",
 	}
 
 	for _, file := range expectedFiles {
@@ -126,6 +128,7 @@ func TestLoad_PopulatesAllFields(t *testing.T) {
 	assert.NotEmpty(t, cfg.ReviewSecondPrompt)
 	assert.NotEmpty(t, cfg.CodexPrompt)
 	assert.NotEmpty(t, cfg.CodexReviewPrompt)
+	assert.NotEmpty(t, cfg.TaskReviewPrompt)
 }
 
 func TestLoad_WithUserConfig(t *testing.T) {
@@ -253,6 +256,22 @@ func TestLoad_ExplicitZeroTaskRetryCount(t *testing.T) {
 	assert.True(t, cfg.TaskRetryCountSet)
 }
 
+func TestLoad_ReviewRetryCount(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "prompts"), 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "agents"), 0o700))
+
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(`review_retry_count = 2`), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.ReviewRetryCount)
+	assert.True(t, cfg.ReviewRetryCountSet)
+	assert.Equal(t, 1, cfg.TaskRetryCount, "task retries are configured separately")
+}
+
 func TestLoad_MaxIterationsFromConfig(t *testing.T) {
 	tmpDir := t.TempDir()
 	configDir := filepath.Join(tmpDir, "ralphex")
@@ -325,6 +344,215 @@ func TestLoad_ExplicitTrueFinalizeEnabled(t *testing.T) {
 	assert.True(t, cfg.FinalizeEnabledSet)
 }
 
+func TestLoad_IncludeCommitLog(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(`include_commit_log = true`), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+	assert.True(t, cfg.IncludeCommitLog)
+	assert.True(t, cfg.IncludeCommitLogSet)
+}
+
+func TestLoad_TagOnComplete(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(`tag_on_complete = true`), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+	assert.True(t, cfg.TagOnComplete)
+	assert.True(t, cfg.TagOnCompleteSet)
+}
+
+func TestLoad_AgentModes(t *testing.T) {
+	t.Run("known agents", func(t *testing.T) {
+		configDir := filepath.Join(t.TempDir(), "ralphex")
+		require.NoError(t, os.MkdirAll(filepath.Join(configDir, "agents"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "agents", "security.txt"), []byte("check security"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"),
+			[]byte("agent_modes = testing:full, security:review"), 0o600))
+
+		cfg, err := Load(configDir)
+		require.NoError(t, err)
+		assert.Equal(t, AgentModes{"testing": {"full"}, "security": {"review"}}, cfg.AgentModes)
+	})
+
+	t.Run("unknown agent", func(t *testing.T) {
+		configDir := filepath.Join(t.TempDir(), "ralphex")
+		require.NoError(t, os.MkdirAll(configDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("agent_modes = no-such-agent:full"), 0o600))
+
+		_, err := Load(configDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown agent "no-such-agent"`)
+	})
+}
+
+func TestLoad_CodexReviewers(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, "ralphex")
+	localDir := filepath.Join(dir, "project", ".ralphex")
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "prompts"), 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(localDir, "prompts"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"),
+		[]byte("codex_reviewers = broad, security:docs/security.md"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "prompts", "codex_review_security.txt"),
+		[]byte("global security review"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "prompts", "codex_review_security.txt"),
+		[]byte("local security review"), 0o600))
+
+	cfg, err := loadConfigFromDirs(configDir, localDir)
+	require.NoError(t, err)
+	assert.Equal(t, []CodexReviewer{
+		{Name: "broad"},
+		{Name: "security", ProjectDoc: "docs/security.md", Prompt: "local security review"},
+	}, cfg.CodexReviewers, "reviewer without a prompt file falls back to codex_review")
+}
+
+func TestLoad_ReviewFocus(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, "ralphex")
+	localDir := filepath.Join(dir, "project", ".ralphex")
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "prompts"), 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(localDir, "prompts"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"),
+		[]byte("review_focus = migrations/**:data-safety, *.md:docs"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "prompts", "review_focus_data-safety.txt"),
+		[]byte("check migrations are reversible"), 0o600))
+
+	t.Run("missing snippet", func(t *testing.T) {
+		_, err := loadConfigFromDirs(configDir, localDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "prompts/review_focus_docs.txt is missing or empty")
+	})
+
+	t.Run("snippets from global and local prompts", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(localDir, "prompts", "review_focus_docs.txt"),
+			[]byte("check docs match the code"), 0o600))
+		cfg, err := loadConfigFromDirs(configDir, localDir)
+		require.NoError(t, err)
+		assert.Equal(t, []ReviewFocus{
+			{Pattern: "migrations/**", Name: "data-safety", Prompt: "check migrations are reversible"},
+			{Pattern: "*.md", Name: "docs", Prompt: "check docs match the code"},
+		}, cfg.ReviewFocus)
+	})
+}
+
+func TestLoad_CodexReviewerConditions(t *testing.T) {
+	dir := t.TempDir()
+	configDir := filepath.Join(dir, "ralphex")
+	localDir := filepath.Join(dir, "project", ".ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+	require.NoError(t, os.MkdirAll(localDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("codex_reviewers = broad, security\n"+
+		"codex_reviewer_modes = security:full\ncodex_reviewer_paths = security:auth/**"), 0o600))
+
+	t.Run("local layer overrides the keys it sets", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"),
+			[]byte("codex_reviewer_paths = security:*.go\ncodex_reviewer_min_diff_lines = broad:50"), 0o600))
+		cfg, err := loadConfigFromDirs(configDir, localDir)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]ReviewerConditions{
+			"security": {Modes: []string{"full"}, Paths: []string{"*.go"}},
+			"broad":    {MinDiffLines: 50},
+		}, cfg.CodexReviewerConditions)
+	})
+
+	t.Run("unknown reviewer", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte("codex_reviewer_modes = perf:full"), 0o600))
+		_, err := loadConfigFromDirs(configDir, localDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown reviewer "perf"`)
+	})
+}
+
+func TestLoad_Profiles(t *testing.T) {
+	t.Run("local profile merges over global", func(t *testing.T) {
+		dir := t.TempDir()
+		globalDir := filepath.Join(dir, "global")
+		localDir := filepath.Join(dir, ".ralphex")
+		require.NoError(t, os.MkdirAll(globalDir, 0o700))
+		require.NoError(t, os.MkdirAll(localDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"),
+			[]byte("max_iterations = 40\n[profile fast]\ncodex_enabled = false\nmax_iterations = 10\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"),
+			[]byte("[profile fast]\nplan_model = sonnet\n"), 0o600))
+
+		cfg, err := loadWithLocal(globalDir, localDir)
+		require.NoError(t, err)
+		assert.Equal(t, 40, cfg.MaxIterations)
+
+		res, err := cfg.ApplyProfile("fast")
+		require.NoError(t, err)
+		assert.Equal(t, 10, res.MaxIterations)
+		assert.False(t, res.CodexEnabled)
+		assert.Equal(t, "sonnet", res.PlanModel)
+		assert.Equal(t, cfg.TaskPrompt, res.TaskPrompt, "prompts are kept")
+		assert.Equal(t, cfg.Colors, res.Colors, "colors are kept")
+	})
+
+	t.Run("profile with unknown review agent", func(t *testing.T) {
+		configDir := filepath.Join(t.TempDir(), "ralphex")
+		require.NoError(t, os.MkdirAll(configDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"),
+			[]byte("[profile thorough]\nreview_first_agents = no-such-agent\n"), 0o600))
+
+		_, err := Load(configDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "profile thorough")
+	})
+}
+
+func TestLoad_ReviewAgents(t *testing.T) {
+	t.Run("defaults reproduce built-in passes", func(t *testing.T) {
+		configDir := filepath.Join(t.TempDir(), "ralphex")
+		require.NoError(t, os.MkdirAll(configDir, 0o700))
+
+		cfg, err := Load(configDir)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"quality", "implementation", "testing", "simplification", "documentation"}, cfg.ReviewFirstAgents)
+		assert.Equal(t, []string{"quality", "implementation"}, cfg.ReviewSecondAgents)
+	})
+
+	t.Run("custom agent in second pass", func(t *testing.T) {
+		configDir := filepath.Join(t.TempDir(), "ralphex")
+		require.NoError(t, os.MkdirAll(filepath.Join(configDir, "agents"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "agents", "security.txt"), []byte("check security"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"),
+			[]byte("review_second_agents = quality, security"), 0o600))
+
+		cfg, err := Load(configDir)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"quality", "security"}, cfg.ReviewSecondAgents)
+		assert.Len(t, cfg.ReviewFirstAgents, 5)
+	})
+
+	t.Run("unknown agent", func(t *testing.T) {
+		configDir := filepath.Join(t.TempDir(), "ralphex")
+		require.NoError(t, os.MkdirAll(configDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("review_first_agents = quality, nope"), 0o600))
+
+		_, err := Load(configDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid review_first_agents: unknown agent "nope"`)
+	})
+
+	t.Run("disabled agents", func(t *testing.T) {
+		configDir := filepath.Join(t.TempDir(), "ralphex")
+		require.NoError(t, os.MkdirAll(configDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("disabled_review_agents = testing, nope"), 0o600))
+
+		cfg, err := Load(configDir)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"testing"}, cfg.DisabledReviewAgents)
+		assert.Len(t, cfg.ReviewFirstAgents, 5) // review sets are left as configured
+	})
+}
+
 func TestLoad_FinalizeEnabledDefaultFalse(t *testing.T) {
 	tmpDir := t.TempDir()
 	configDir := filepath.Join(tmpDir, "ralphex")
@@ -833,6 +1061,44 @@ func TestLoad_MaxExternalIterations(t *testing.T) {
 	assert.Equal(t, 8, cfg.MaxExternalIterations)
 }
 
+func TestLoad_MaxExternalRounds(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "prompts"), 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "agents"), 0o700))
+
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("max_external_rounds = 12"), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, 12, cfg.MaxExternalRounds)
+}
+
+func TestLoad_AllowExternalPlans(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   bool
+	}{
+		{name: "default allows external plans", config: "", want: true},
+		{name: "disabled", config: "allow_external_plans = false", want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			configDir := filepath.Join(t.TempDir(), "ralphex")
+			require.NoError(t, os.MkdirAll(filepath.Join(configDir, "prompts"), 0o700))
+			require.NoError(t, os.MkdirAll(filepath.Join(configDir, "agents"), 0o700))
+			require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(tc.config), 0o600))
+
+			cfg, err := Load(configDir)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, cfg.AllowExternalPlans)
+		})
+	}
+}
+
 func TestLoad_MaxExternalIterations_DefaultZero(t *testing.T) {
 	tmpDir := t.TempDir()
 	configDir := filepath.Join(tmpDir, "ralphex")
@@ -1171,3 +1437,67 @@ func TestLocalConfig_LocalOverridesSessionTimeout(t *testing.T) {
 	assert.Equal(t, 15*time.Minute, cfg.SessionTimeout)
 	assert.True(t, cfg.SessionTimeoutSet)
 }
+
+func TestLoadWithFile(t *testing.T) {
+	setup := func(t *testing.T) (globalDir, localDir string) {
+		t.Helper()
+		tmpDir := t.TempDir()
+		origDir, err := os.Getwd()
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, os.Chdir(origDir)) })
+		require.NoError(t, os.Chdir(tmpDir))
+
+		globalDir = filepath.Join(tmpDir, "global")
+		localDir = filepath.Join(tmpDir, ".ralphex")
+		require.NoError(t, os.MkdirAll(globalDir, 0o700))
+		require.NoError(t, os.MkdirAll(localDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"),
+			[]byte("max_iterations = 40\ncodex_enabled = false\ncolor_error = #00ff00\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte("max_iterations = 30\ntask_retry_count = 2\n"), 0o600))
+		return globalDir, localDir
+	}
+
+	t.Run("file overrides local and global", func(t *testing.T) {
+		globalDir, _ := setup(t)
+		file := filepath.Join(t.TempDir(), "ralphex.cfg")
+		require.NoError(t, os.WriteFile(file,
+			[]byte("max_iterations = 7\ncolor_task = #0000ff\n\n[profile fast]\nmax_iterations = 3\n"), 0o600))
+
+		cfg, err := LoadWithFile(globalDir, file)
+		require.NoError(t, err)
+		assert.Equal(t, 7, cfg.MaxIterations, "file wins over local")
+		assert.Equal(t, 2, cfg.TaskRetryCount, "local kept when the file doesn't set it")
+		assert.False(t, cfg.CodexEnabled, "global kept when neither sets it")
+		assert.Equal(t, "0,0,255", cfg.Colors.Task)
+		assert.Equal(t, "0,255,0", cfg.Colors.Error)
+
+		fast, err := cfg.ApplyProfile("fast")
+		require.NoError(t, err)
+		assert.Equal(t, 3, fast.MaxIterations)
+	})
+
+	t.Run("empty path loads the directories only", func(t *testing.T) {
+		globalDir, _ := setup(t)
+		cfg, err := LoadWithFile(globalDir, "")
+		require.NoError(t, err)
+		assert.Equal(t, 30, cfg.MaxIterations)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		globalDir, _ := setup(t)
+		_, err := LoadWithFile(globalDir, filepath.Join(t.TempDir(), "nope.cfg"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "config file:")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		globalDir, _ := setup(t)
+		file := filepath.Join(t.TempDir(), "ralphex.cfg")
+		require.NoError(t, os.WriteFile(file, []byte("max_iterations = lots\n"), 0o600))
+		_, err := LoadWithFile(globalDir, file)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "config file "+file)
+		assert.Contains(t, err.Error(), "max_iterations")
+	})
+}
@@ -585,6 +585,68 @@ func DumpDefaults(dir string) error {
 	return nil
 }
 
+// namedDefault is a single embedded default file addressable by name, e.g. "task-prompt" or "quality-agent".
+type namedDefault struct {
+	name      string // name used to select the file
+	embedPath string // path in the embedded filesystem
+	relPath   string // path relative to the config directory
+}
+
+// namedDefaults returns the embedded default files with their names: "config", then prompts as "<name>-prompt"
+// and agents as "<name>-agent", with underscores in file names turned into dashes.
+func (d *defaultsInstaller) namedDefaults() ([]namedDefault, error) {
+	result := []namedDefault{{name: "config", embedPath: "defaults/config", relPath: "config"}}
+	for _, dir := range []struct{ sub, suffix string }{{"prompts", "-prompt"}, {"agents", "-agent"}} {
+		entries, err := d.embedFS.ReadDir("defaults/" + dir.sub)
+		if err != nil {
+			return nil, fmt.Errorf("read embedded dir %s: %w", dir.sub, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") {
+				continue
+			}
+			base := strings.ReplaceAll(strings.TrimSuffix(entry.Name(), ".txt"), "_", "-")
+			result = append(result, namedDefault{name: base + dir.suffix, embedPath: "defaults/" + dir.sub + "/" + entry.Name(),
+				relPath: filepath.Join(dir.sub, entry.Name())})
+		}
+	}
+	return result, nil
+}
+
+// DefaultNames returns the names of the embedded defaults accepted by DumpDefault.
+func DefaultNames() ([]string, error) {
+	defaults, err := newDefaultsInstaller(defaultsFS).namedDefaults()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(defaults))
+	for _, nd := range defaults {
+		names = append(names, nd.name)
+	}
+	return names, nil
+}
+
+// DumpDefault returns the raw content of a single named embedded default (see DefaultNames)
+// and its path relative to the config directory, e.g. "prompts/task.txt" for "task-prompt".
+func DumpDefault(name string) (relPath string, data []byte, err error) {
+	installer := newDefaultsInstaller(defaultsFS)
+	defaults, err := installer.namedDefaults()
+	if err != nil {
+		return "", nil, err
+	}
+	for _, nd := range defaults {
+		if nd.name != name {
+			continue
+		}
+		data, err := installer.embedFS.ReadFile(nd.embedPath)
+		if err != nil {
+			return "", nil, fmt.Errorf("read embedded %s: %w", nd.embedPath, err)
+		}
+		return nd.relPath, data, nil
+	}
+	return "", nil, fmt.Errorf("unknown default %q", name)
+}
+
 // Reset interactively restores configuration files to embedded defaults.
 // if configDir is empty, uses DefaultConfigDir().
 func Reset(configDir string, stdin io.Reader, stdout io.Writer) (ResetResult, error) {
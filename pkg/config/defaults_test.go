@@ -255,7 +255,7 @@ func TestDefaultsInstaller_installDefaultFiles_Prompts(t *testing.T) {
 	installer := &defaultsInstaller{embedFS: defaultsFS}
 	require.NoError(t, installer.installDefaultFiles(promptsDir, "defaults/prompts", "prompt"))
 
-	expectedPrompts := []string{"task.txt", "review_first.txt", "review_second.txt", "codex.txt", "make_plan.txt", "finalize.txt", "custom_review.txt", "custom_eval.txt", "codex_review.txt"}
+	expectedPrompts := []string{"task.txt", "review_first.txt", "review_second.txt", "codex.txt", "make_plan.txt", "finalize.txt", "custom_review.txt", "custom_eval.txt", "codex_review.txt", "task_review.txt"}
 	for _, prompt := range expectedPrompts {
 		promptPath := filepath.Join(promptsDir, prompt)
 		assert.FileExists(t, promptPath, "prompt file %s should be installed", prompt)
@@ -297,7 +297,7 @@ func TestDefaultsInstaller_Install_InstallsPromptFiles(t *testing.T) {
 	require.NoError(t, installer.Install(configDir))
 
 	promptsDir := filepath.Join(configDir, "prompts")
-	expectedPrompts := []string{"task.txt", "review_first.txt", "review_second.txt", "codex.txt", "make_plan.txt", "finalize.txt", "custom_review.txt", "custom_eval.txt", "codex_review.txt"}
+	expectedPrompts := []string{"task.txt", "review_first.txt", "review_second.txt", "codex.txt", "make_plan.txt", "finalize.txt", "custom_review.txt", "custom_eval.txt", "codex_review.txt", "task_review.txt"}
 
 	for _, prompt := range expectedPrompts {
 		promptPath := filepath.Join(promptsDir, prompt)
@@ -863,6 +863,43 @@ func TestDefaultsInstaller_AskYesNo(t *testing.T) {
 	}
 }
 
+func TestDefaultNames(t *testing.T) {
+	names, err := DefaultNames()
+	require.NoError(t, err)
+	assert.Equal(t, "config", names[0])
+	for _, name := range []string{"task-prompt", "review-first-prompt", "codex-review-prompt", "make-plan-prompt", "quality-agent"} {
+		assert.Contains(t, names, name)
+	}
+}
+
+func TestDumpDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		wantPath string
+		wantErr  string
+	}{
+		{name: "config", wantPath: "config"},
+		{name: "task-prompt", wantPath: filepath.Join("prompts", "task.txt")},
+		{name: "review-second-prompt", wantPath: filepath.Join("prompts", "review_second.txt")},
+		{name: "testing-agent", wantPath: filepath.Join("agents", "testing.txt")},
+		{name: "task", wantErr: `unknown default "task"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			relPath, data, err := DumpDefault(tc.name)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantPath, relPath)
+			embedded, err := defaultsFS.ReadFile("defaults/" + filepath.ToSlash(tc.wantPath))
+			require.NoError(t, err)
+			assert.Equal(t, embedded, data, "content is raw, not commented out")
+		})
+	}
+}
+
 func TestDumpDefaults(t *testing.T) {
 	t.Run("creates_all_files", func(t *testing.T) {
 		tmpDir := filepath.Join(t.TempDir(), "dump")
@@ -7,16 +7,34 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// AgentKind selects how an agent runs: as a prompt for a Task tool agent, or as a command whose output goes into the prompt.
+type AgentKind string
+
+// agent kinds, set with the "type" frontmatter option. an empty kind is a prompt agent.
+const (
+	AgentKindPrompt  AgentKind = "prompt"
+	AgentKindCommand AgentKind = "command"
+)
+
 // Options holds agent options parsed from YAML frontmatter in agent files.
 type Options struct {
-	Model     string `yaml:"model"`
-	AgentType string `yaml:"agent"`
+	Model     string    `yaml:"model"`
+	AgentType string    `yaml:"agent"`
+	Kind      AgentKind `yaml:"type"`
+}
+
+// IsCommand reports whether the agent body is a shell command run by ralphex instead of a prompt.
+func (o Options) IsCommand() bool {
+	return o.Kind == AgentKindCommand
 }
 
 var validModels = map[string]bool{"haiku": true, "sonnet": true, "opus": true}
 
 // String returns a human-readable summary of the options for logging.
 func (o Options) String() string {
+	if o.IsCommand() {
+		return "type=command"
+	}
 	model := o.Model
 	if model == "" {
 		model = "default"
@@ -35,6 +53,9 @@ func (o Options) Validate() []string {
 	if o.Model != "" && !validModels[o.Model] {
 		warnings = append(warnings, fmt.Sprintf("unknown model %q, must be one of: haiku, sonnet, opus", o.Model))
 	}
+	if o.Kind != "" && o.Kind != AgentKindPrompt && o.Kind != AgentKindCommand {
+		warnings = append(warnings, fmt.Sprintf("unknown type %q, must be one of: prompt, command", o.Kind))
+	}
 	return warnings
 }
 
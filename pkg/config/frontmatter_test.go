@@ -22,6 +22,7 @@ func TestParseOptions(t *testing.T) {
 		{"unknown keys ignored", "---\nmodel: opus\nfoo: bar\n---\nbody", Options{Model: "opus"}, "body"},
 		{"whitespace in values", "---\nmodel:  haiku  \nagent:  code-reviewer  \n---\nbody", Options{Model: "haiku", AgentType: "code-reviewer"}, "body"},
 		{"malformed yaml", "---\n: :\n  bad:\n---\nbody", Options{}, "---\n: :\n  bad:\n---\nbody"},
+		{"command type", "---\ntype: command\n---\ngolangci-lint run ./...", Options{Kind: AgentKindCommand}, "golangci-lint run ./..."},
 
 		// closing delimiter must be on its own line
 		{"closing delimiter not on own line", "---\nmodel: haiku\n---extra\nbody", Options{}, "---\nmodel: haiku\n---extra\nbody"},
@@ -65,6 +66,7 @@ func TestOptions_String(t *testing.T) {
 		{"model only", Options{Model: "haiku"}, "model=haiku, subagent=general-purpose"},
 		{"agent only", Options{AgentType: "code-reviewer"}, "model=default, subagent=code-reviewer"},
 		{"both fields", Options{Model: "opus", AgentType: "code-reviewer"}, "model=opus, subagent=code-reviewer"},
+		{"command type", Options{Kind: AgentKindCommand}, "type=command"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -86,6 +88,9 @@ func TestOptions_Validate(t *testing.T) {
 		{"unknown model", Options{Model: "gpt-5"}, []string{`unknown model "gpt-5", must be one of: haiku, sonnet, opus`}},
 		{"agent type not validated", Options{AgentType: "anything-goes"}, nil},
 		{"unknown model with agent", Options{Model: "bad", AgentType: "reviewer"}, []string{`unknown model "bad", must be one of: haiku, sonnet, opus`}},
+		{"prompt type", Options{Kind: AgentKindPrompt}, nil},
+		{"command type", Options{Kind: AgentKindCommand}, nil},
+		{"unknown type", Options{Kind: "script"}, []string{`unknown type "script", must be one of: prompt, command`}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,296 @@
+package config
+
+import (
+	"maps"
+	"slices"
+)
+
+// Merge returns a new config with override layered on top of c, e.g. a profile or --config file over
+// the loaded config. neither c nor override is modified. a nil override returns a copy of c.
+//
+// config keys are layered by the same rules as the global and local config files, see Values.mergeFrom:
+// a key set in override wins, keys with a *Set flag win even with a false/0 value, slices and maps replace
+// the base when non-empty. configs built by this package keep the values they were parsed from, for a
+// hand-built config they are derived from its fields, a non-zero field counts as set. the remaining parts
+// are layered here:
+//   - CustomAgents merge by name: an override agent replaces the base agent with the same name
+//     in place, agents only present in the override are appended
+//   - codex reviewers, review focus and disabled agents, resolved at load, follow their config keys
+//   - prompts and colors take the override value field by field when non-empty
+//   - Profiles merge by name, a profile defined in both is itself merged with these rules
+func (c *Config) Merge(override *Config) *Config {
+	if override == nil {
+		return c.clone()
+	}
+	values, overrideValues := c.layerValues(), override.layerValues()
+	values.mergeFrom(&overrideValues)
+	res := configFromValues(values).clone()
+	res.CodexReviewers = slices.Clone(c.CodexReviewers)
+	overrideSlice(&res.CodexReviewers, override.CodexReviewers)
+	res.ReviewFocus = slices.Clone(c.ReviewFocus)
+	overrideSlice(&res.ReviewFocus, override.ReviewFocus)
+	res.DisabledReviewAgents = slices.Clone(c.DisabledReviewAgents)
+	overrideSlice(&res.DisabledReviewAgents, override.DisabledReviewAgents)
+
+	res.Colors = c.Colors
+	res.Colors.mergeFrom(&override.Colors)
+	res.mergePromptsFrom(c)
+	res.mergePromptsFrom(override)
+	res.CustomAgents = mergeAgents(slices.Clone(c.CustomAgents), override.CustomAgents)
+	res.Profiles = mergeProfiles(maps.Clone(c.Profiles), override.Profiles)
+	res.configDir, res.localDir = c.configDir, c.localDir
+	overrideValue(&res.configDir, override.configDir)
+	overrideValue(&res.localDir, override.localDir)
+	return res
+}
+
+// layerValues returns the config values c was built from, or values derived from its fields for a hand-built config.
+func (c *Config) layerValues() Values {
+	if c.values != nil {
+		return *c.values
+	}
+	return valuesFromConfig(c)
+}
+
+// valuesFromConfig maps the fields of a hand-built config back onto config values, the inverse of configFromValues.
+// a field without a *Set flag counts as set when it is non-zero. prompts, colors, agents and profiles are not
+// config values and are layered by Merge directly.
+func valuesFromConfig(c *Config) Values {
+	return Values{
+		ClaudeCommand:                  c.ClaudeCommand,
+		ClaudeArgs:                     c.ClaudeArgs,
+		ClaudeOutputFilter:             c.ClaudeOutputFilter,
+		ClaudeOutputFilterSet:          c.ClaudeOutputFilterSet || c.ClaudeOutputFilter,
+		StripOutputANSI:                c.StripOutputANSI,
+		StripOutputANSISet:             c.StripOutputANSISet || c.StripOutputANSI,
+		PlanModel:                      c.PlanModel,
+		CodexEnabled:                   c.CodexEnabled,
+		CodexEnabledSet:                c.CodexEnabledSet || c.CodexEnabled,
+		CodexCommand:                   c.CodexCommand,
+		CodexModel:                     c.CodexModel,
+		CodexReasoningEffort:           c.CodexReasoningEffort,
+		CodexTimeoutMs:                 c.CodexTimeoutMs,
+		CodexTimeoutMsSet:              c.CodexTimeoutMsSet || c.CodexTimeoutMs != 0,
+		CodexSandbox:                   c.CodexSandbox,
+		ExternalReviewTool:             c.ExternalReviewTool,
+		CustomReviewScript:             c.CustomReviewScript,
+		IterationDelayMs:               c.IterationDelayMs,
+		IterationDelayMsSet:            c.IterationDelayMsSet || c.IterationDelayMs != 0,
+		TaskRetryCount:                 c.TaskRetryCount,
+		TaskRetryCountSet:              c.TaskRetryCountSet || c.TaskRetryCount != 0,
+		ReviewRetryCount:               c.ReviewRetryCount,
+		ReviewRetryCountSet:            c.ReviewRetryCountSet || c.ReviewRetryCount != 0,
+		MaxIterations:                  c.MaxIterations,
+		MaxIterationsSet:               c.MaxIterationsSet || c.MaxIterations != 0,
+		MaxExternalIterations:          c.MaxExternalIterations,
+		MaxExternalRounds:              c.MaxExternalRounds,
+		ReviewPatience:                 c.ReviewPatience,
+		CodexMinDiffLines:              c.CodexMinDiffLines,
+		PlanMaxTasks:                   c.PlanMaxTasks,
+		PlanMaxTasksSet:                c.PlanMaxTasksSet || c.PlanMaxTasks != 0,
+		EmptyIterationLimit:            c.EmptyIterationLimit,
+		EmptyIterationLimitSet:         c.EmptyIterationLimitSet || c.EmptyIterationLimit != 0,
+		MinFreeDiskMB:                  c.MinFreeDiskMB,
+		MinFreeDiskMBSet:               c.MinFreeDiskMBSet || c.MinFreeDiskMB != 0,
+		CodexBlameHints:                c.CodexBlameHints,
+		CodexBlameHintsSet:             c.CodexBlameHintsSet || c.CodexBlameHints,
+		CodexFailOnP1:                  c.CodexFailOnP1,
+		CodexFailOnP1Set:               c.CodexFailOnP1Set || c.CodexFailOnP1,
+		CodexReviewers:                 c.CodexReviewers,
+		CodexReviewerConditions:        c.CodexReviewerConditions,
+		ReviewFocus:                    c.ReviewFocus,
+		FinalizeEnabled:                c.FinalizeEnabled,
+		FinalizeEnabledSet:             c.FinalizeEnabledSet || c.FinalizeEnabled,
+		FinalizeNoCommit:               c.FinalizeNoCommit,
+		FinalizeNoCommitSet:            c.FinalizeNoCommitSet || c.FinalizeNoCommit,
+		PreRunCommand:                  c.PreRunCommand,
+		PostRunCommand:                 c.PostRunCommand,
+		IncludeCommitLog:               c.IncludeCommitLog,
+		IncludeCommitLogSet:            c.IncludeCommitLogSet || c.IncludeCommitLog,
+		EmbedPlanInPrompt:              c.EmbedPlanInPrompt,
+		EmbedPlanInPromptSet:           c.EmbedPlanInPromptSet || c.EmbedPlanInPrompt,
+		TagOnComplete:                  c.TagOnComplete,
+		TagOnCompleteSet:               c.TagOnCompleteSet || c.TagOnComplete,
+		WriteSummary:                   c.WriteSummary,
+		WriteSummarySet:                c.WriteSummarySet || c.WriteSummary,
+		ReportTestResults:              c.ReportTestResults,
+		ReportTestResultsSet:           c.ReportTestResultsSet || c.ReportTestResults,
+		SuppressDeprecationWarnings:    c.SuppressDeprecationWarnings,
+		SuppressDeprecationWarningsSet: c.SuppressDeprecationWarningsSet || c.SuppressDeprecationWarnings,
+		ReviewPerTask:                  c.ReviewPerTask,
+		ReviewPerTaskSet:               c.ReviewPerTaskSet || c.ReviewPerTask,
+		SkipFinalReview:                c.SkipFinalReview,
+		SkipFinalReviewSet:             c.SkipFinalReviewSet || c.SkipFinalReview,
+		ReviewWorkingTree:              c.ReviewWorkingTree,
+		ReviewWorkingTreeSet:           c.ReviewWorkingTreeSet || c.ReviewWorkingTree,
+		ReviewIncludePlan:              c.ReviewIncludePlan,
+		ReviewIncludePlanSet:           c.ReviewIncludePlanSet || c.ReviewIncludePlan,
+		AutoUnshallow:                  c.AutoUnshallow,
+		AutoUnshallowSet:               c.AutoUnshallowSet || c.AutoUnshallow,
+		AllowExternalPlans:             c.AllowExternalPlans,
+		AllowExternalPlansSet:          c.AllowExternalPlansSet || c.AllowExternalPlans,
+		TerminalTitle:                  c.TerminalTitle,
+		TerminalTitleSet:               c.TerminalTitleSet || c.TerminalTitle,
+		SplitProgressByTask:            c.SplitProgressByTask,
+		SplitProgressByTaskSet:         c.SplitProgressByTaskSet || c.SplitProgressByTask,
+		WorktreeEnabled:                c.WorktreeEnabled,
+		WorktreeEnabledSet:             c.WorktreeEnabledSet || c.WorktreeEnabled,
+		KeepWorktreeOnFailure:          c.KeepWorktreeOnFailure,
+		KeepWorktreeOnFailureSet:       c.KeepWorktreeOnFailureSet || c.KeepWorktreeOnFailure,
+		PlansDir:                       c.PlansDir,
+		DefaultBranch:                  c.DefaultBranch,
+		PushRemote:                     c.PushRemote,
+		PushRefspecTemplate:            c.PushRefspecTemplate,
+		ProgressBranch:                 c.ProgressBranch,
+		InProgressMarker:               c.InProgressMarker,
+		BranchNameTemplate:             c.BranchNameTemplate,
+		CommitTrailer:                  c.CommitTrailer,
+		BranchStripPattern:             c.BranchStripPattern,
+		DefaultMode:                    c.DefaultMode,
+		TaskOrder:                      c.TaskOrder,
+		TestCommand:                    c.TestCommand,
+		VcsCommand:                     c.VcsCommand,
+		FzfCommand:                     c.FzfCommand,
+		FzfArgs:                        c.FzfArgs,
+		WatchDirs:                      c.WatchDirs,
+		PhaseNames:                     c.PhaseNames,
+		AgentModes:                     c.AgentModes,
+		ReviewFirstAgents:              c.ReviewFirstAgents,
+		ReviewSecondAgents:             c.ReviewSecondAgents,
+		DisabledReviewAgents:           c.DisabledReviewAgents,
+		HookFailure:                    c.HookFailure,
+		EstimateTokens:                 c.EstimateTokens,
+		EstimateMinutes:                c.EstimateMinutes,
+		ClaudeErrorPatterns:            c.ClaudeErrorPatterns,
+		CodexErrorPatterns:             c.CodexErrorPatterns,
+		ClaudeLimitPatterns:            c.ClaudeLimitPatterns,
+		CodexLimitPatterns:             c.CodexLimitPatterns,
+		WaitOnLimit:                    c.WaitOnLimit,
+		WaitOnLimitSet:                 c.WaitOnLimitSet || c.WaitOnLimit != 0,
+		MaxLimitRetries:                c.MaxLimitRetries,
+		SessionTimeout:                 c.SessionTimeout,
+		SessionTimeoutSet:              c.SessionTimeoutSet || c.SessionTimeout != 0,
+		ClaudeIdleTimeout:              c.ClaudeIdleTimeout,
+		ClaudeIdleTimeoutSet:           c.ClaudeIdleTimeoutSet || c.ClaudeIdleTimeout != 0,
+		DashboardBatchInterval:         c.DashboardBatchInterval,
+		DashboardBatchSet:              c.DashboardBatchSet || c.DashboardBatchInterval != 0,
+		HeartbeatInterval:              c.HeartbeatInterval,
+		HeartbeatIntervalSet:           c.HeartbeatIntervalSet || c.HeartbeatInterval != 0,
+		NotifyChannels:                 c.NotifyParams.Channels,
+		NotifyChannelsSet:              len(c.NotifyParams.Channels) > 0,
+		NotifyOnError:                  c.NotifyParams.OnError,
+		NotifyOnErrorSet:               c.NotifyParams.OnError,
+		NotifyOnComplete:               c.NotifyParams.OnComplete,
+		NotifyOnCompleteSet:            c.NotifyParams.OnComplete,
+		NotifyTimeoutMs:                c.NotifyParams.TimeoutMs,
+		NotifyTimeoutMsSet:             c.NotifyParams.TimeoutMs != 0,
+		NotifyTelegramToken:            c.NotifyParams.TelegramToken,
+		NotifyTelegramChat:             c.NotifyParams.TelegramChat,
+		NotifySlackToken:               c.NotifyParams.SlackToken,
+		NotifySlackChannel:             c.NotifyParams.SlackChannel,
+		NotifySMTPHost:                 c.NotifyParams.SMTPHost,
+		NotifySMTPPort:                 c.NotifyParams.SMTPPort,
+		NotifySMTPPortSet:              c.NotifyParams.SMTPPort != 0,
+		NotifySMTPUsername:             c.NotifyParams.SMTPUsername,
+		NotifySMTPPassword:             c.NotifyParams.SMTPPassword,
+		NotifySMTPStartTLS:             c.NotifyParams.SMTPStartTLS,
+		NotifySMTPStartTLSSet:          c.NotifyParams.SMTPStartTLS,
+		NotifyEmailFrom:                c.NotifyParams.EmailFrom,
+		NotifyEmailTo:                  c.NotifyParams.EmailTo,
+		NotifyEmailToSet:               len(c.NotifyParams.EmailTo) > 0,
+		NotifyWebhookURLs:              c.NotifyParams.WebhookURLs,
+		NotifyWebhookURLsSet:           len(c.NotifyParams.WebhookURLs) > 0,
+		NotifyCustomScript:             c.NotifyParams.CustomScript,
+	}
+}
+
+// clone returns a copy of c that shares no slices or maps with it.
+// the values c was parsed from are shared, merging never modifies them.
+func (c *Config) clone() *Config {
+	res := *c
+	res.WatchDirs = slices.Clone(c.WatchDirs)
+	res.ReviewFirstAgents = slices.Clone(c.ReviewFirstAgents)
+	res.ReviewSecondAgents = slices.Clone(c.ReviewSecondAgents)
+	res.DisabledReviewAgents = slices.Clone(c.DisabledReviewAgents)
+	res.ClaudeErrorPatterns = slices.Clone(c.ClaudeErrorPatterns)
+	res.CodexErrorPatterns = slices.Clone(c.CodexErrorPatterns)
+	res.ClaudeLimitPatterns = slices.Clone(c.ClaudeLimitPatterns)
+	res.CodexLimitPatterns = slices.Clone(c.CodexLimitPatterns)
+	res.CustomAgents = slices.Clone(c.CustomAgents)
+	res.CodexReviewers = slices.Clone(c.CodexReviewers)
+	res.CodexReviewerConditions = maps.Clone(c.CodexReviewerConditions)
+	res.ReviewFocus = slices.Clone(c.ReviewFocus)
+	res.PhaseNames = maps.Clone(c.PhaseNames)
+	res.HookFailure = maps.Clone(c.HookFailure)
+	res.EstimateTokens = maps.Clone(c.EstimateTokens)
+	res.EstimateMinutes = maps.Clone(c.EstimateMinutes)
+	res.Profiles = maps.Clone(c.Profiles)
+	res.AgentModes = nil
+	if c.AgentModes != nil {
+		res.AgentModes = make(AgentModes, len(c.AgentModes))
+		for k, v := range c.AgentModes {
+			res.AgentModes[k] = slices.Clone(v)
+		}
+	}
+	res.NotifyParams.Channels = slices.Clone(c.NotifyParams.Channels)
+	res.NotifyParams.EmailTo = slices.Clone(c.NotifyParams.EmailTo)
+	res.NotifyParams.WebhookURLs = slices.Clone(c.NotifyParams.WebhookURLs)
+	return &res
+}
+
+func (c *Config) mergePromptsFrom(src *Config) {
+	overrideValue(&c.TaskPrompt, src.TaskPrompt)
+	overrideValue(&c.ReviewFirstPrompt, src.ReviewFirstPrompt)
+	overrideValue(&c.ReviewSecondPrompt, src.ReviewSecondPrompt)
+	overrideValue(&c.CodexPrompt, src.CodexPrompt)
+	overrideValue(&c.MakePlanPrompt, src.MakePlanPrompt)
+	overrideValue(&c.FinalizePrompt, src.FinalizePrompt)
+	overrideValue(&c.CustomReviewPrompt, src.CustomReviewPrompt)
+	overrideValue(&c.CustomEvalPrompt, src.CustomEvalPrompt)
+	overrideValue(&c.CodexReviewPrompt, src.CodexReviewPrompt)
+	overrideValue(&c.TaskReviewPrompt, src.TaskReviewPrompt)
+}
+
+// mergeAgents layers override agents over base by name, keeping base order and appending new agents.
+func mergeAgents(base, override []CustomAgent) []CustomAgent {
+	for _, agent := range override {
+		idx := slices.IndexFunc(base, func(a CustomAgent) bool { return a.Name == agent.Name })
+		if idx < 0 {
+			base = append(base, agent)
+			continue
+		}
+		base[idx] = agent
+	}
+	return base
+}
+
+// mergeProfiles layers override profiles over base by name, a profile in both is merged with Merge.
+// base is modified in place if non-nil.
+func mergeProfiles(base, override map[string]*Config) map[string]*Config {
+	for name, p := range override {
+		if base == nil {
+			base = make(map[string]*Config, len(override))
+		}
+		if existing, ok := base[name]; ok {
+			base[name] = existing.Merge(p)
+			continue
+		}
+		base[name] = p
+	}
+	return base
+}
+
+// overrideValue sets dst to src if src is not the zero value.
+func overrideValue[T comparable](dst *T, src T) {
+	var zero T
+	if src != zero {
+		*dst = src
+	}
+}
+
+// overrideSlice replaces dst with a copy of src if src is not empty.
+func overrideSlice[T any](dst *[]T, src []T) {
+	if len(src) > 0 {
+		*dst = slices.Clone(src)
+	}
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/hooks"
+	"github.com/umputun/ralphex/pkg/notify"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestConfig_Merge_NilOverride(t *testing.T) {
+	base := configFromValues(Values{ClaudeCommand: "claude", WatchDirs: []string{"a"}, CodexEnabled: true, CodexEnabledSet: true})
+	res := base.Merge(nil)
+	require.NotNil(t, res)
+	assert.Equal(t, base, res)
+	assert.NotSame(t, base, res)
+
+	res.WatchDirs[0] = "changed"
+	assert.Equal(t, []string{"a"}, base.WatchDirs, "result must not share slices with base")
+}
+
+func TestConfig_Merge_Scalars(t *testing.T) {
+	base := configFromValues(Values{
+		ClaudeCommand:     "claude",
+		ClaudeArgs:        "--base",
+		CodexEnabled:      true,
+		CodexEnabledSet:   true,
+		IterationDelayMs:  2000,
+		MaxIterations:     50,
+		MaxIterationsSet:  true,
+		SessionTimeout:    time.Hour,
+		SessionTimeoutSet: true,
+		DefaultBranch:     "main",
+		NotifyOnError:     true, NotifyOnErrorSet: true,
+		NotifyTelegramChat: "base-chat",
+	})
+	base.Colors = ColorConfig{Task: "0,255,0", Review: "0,255,255"}
+	base.TaskPrompt = "base task prompt"
+	base.configDir = "/global"
+
+	override := configFromValues(Values{
+		ClaudeArgs:          "--override",
+		CodexEnabled:        false,
+		CodexEnabledSet:     true, // explicit false wins
+		IterationDelayMs:    0,
+		IterationDelayMsSet: true, // explicit 0 wins
+		MaxIterations:       10,   // not set, ignored
+		DefaultBranch:       "trunk",
+		NotifyOnError:       false, NotifyOnErrorSet: true, // explicit false wins, like in config files
+		NotifyTelegramToken: "token",
+	})
+	override.Colors = ColorConfig{Task: "255,0,0"}
+	override.localDir = "/repo/.ralphex"
+
+	res := base.Merge(override)
+	assert.Equal(t, "claude", res.ClaudeCommand, "empty override keeps base")
+	assert.Equal(t, "--override", res.ClaudeArgs)
+	assert.False(t, res.CodexEnabled)
+	assert.True(t, res.CodexEnabledSet)
+	assert.Equal(t, 0, res.IterationDelayMs)
+	assert.True(t, res.IterationDelayMsSet)
+	assert.Equal(t, 50, res.MaxIterations, "override without Set flag ignored")
+	assert.Equal(t, time.Hour, res.SessionTimeout)
+	assert.Equal(t, "trunk", res.DefaultBranch)
+	assert.False(t, res.NotifyParams.OnError)
+	assert.True(t, res.NotifyParams.OnComplete, "defaults to true when not set")
+	assert.Equal(t, "base-chat", res.NotifyParams.TelegramChat)
+	assert.Equal(t, "token", res.NotifyParams.TelegramToken)
+	assert.Equal(t, ColorConfig{Task: "255,0,0", Review: "0,255,255"}, res.Colors)
+	assert.Equal(t, "base task prompt", res.TaskPrompt)
+	assert.Equal(t, "/global", res.configDir)
+	assert.Equal(t, "/repo/.ralphex", res.LocalDir())
+
+	// inputs untouched
+	assert.True(t, base.CodexEnabled)
+	assert.Equal(t, "--base", base.ClaudeArgs)
+	assert.Equal(t, 10, override.MaxIterations)
+}
+
+func TestConfig_Merge_HandBuilt(t *testing.T) {
+	base := &Config{IterationDelayMs: 5, ClaudeCommand: "myclaude", MaxIterations: 7, WatchDirs: []string{"/base"},
+		NotifyParams: notify.Params{TelegramChat: "chat"}}
+	override := &Config{MaxIterations: 3, CodexEnabled: false, CodexEnabledSet: true, NotifyParams: notify.Params{SMTPPort: 587}}
+
+	res := base.Merge(override)
+	assert.Equal(t, 5, res.IterationDelayMs)
+	assert.Equal(t, "myclaude", res.ClaudeCommand)
+	assert.Equal(t, 3, res.MaxIterations, "non-zero field of a hand-built override wins")
+	assert.False(t, res.CodexEnabled)
+	assert.True(t, res.CodexEnabledSet, "explicit Set flag carries a false value")
+	assert.Equal(t, []string{"/base"}, res.WatchDirs)
+	assert.Equal(t, "chat", res.NotifyParams.TelegramChat)
+	assert.Equal(t, 587, res.NotifyParams.SMTPPort)
+
+	loaded := configFromValues(Values{MaxIterations: 50, MaxIterationsSet: true, PlansDir: "docs/plans"})
+	res = loaded.Merge(&Config{PlansDir: "plans"})
+	assert.Equal(t, 50, res.MaxIterations, "zero field of a hand-built override keeps the loaded value")
+	assert.Equal(t, "plans", res.PlansDir)
+}
+
+func TestConfig_Merge_Slices(t *testing.T) {
+	base := configFromValues(Values{
+		WatchDirs:          []string{"/base/a", "/base/b"},
+		ReviewFirstAgents:  []string{"quality", "testing"},
+		CodexErrorPatterns: []string{"base error"},
+		NotifyChannels:     []string{"telegram"}, NotifyChannelsSet: true,
+		NotifyEmailTo: []string{"a@example.com"}, NotifyEmailToSet: true,
+		PhaseNames:  status.PhaseNames{status.PhaseTask: "build", status.PhaseReview: "check"},
+		AgentModes:  AgentModes{"quality": {"full"}},
+		HookFailure: map[hooks.Point]hooks.Failure{hooks.PreTask: hooks.FailureWarn},
+	})
+	base.CustomAgents = []CustomAgent{
+		{Name: "quality", Prompt: "base quality", Builtin: true},
+		{Name: "security", Prompt: "base security"},
+	}
+	base.CodexReviewers = []CodexReviewer{{Name: "broad", Prompt: "broad prompt"}}
+
+	override := configFromValues(Values{
+		WatchDirs:          []string{"/repo"},
+		ReviewSecondAgents: []string{"quality"},
+		NotifyChannels:     []string{"slack"}, NotifyChannelsSet: true,
+		PhaseNames: status.PhaseNames{status.PhaseTask: "implement"},
+		AgentModes: AgentModes{"perf": {"review"}},
+	})
+	override.CustomAgents = []CustomAgent{
+		{Name: "security", Prompt: "repo security"},
+		{Name: "perf", Prompt: "repo perf"},
+	}
+
+	res := base.Merge(override)
+	assert.Equal(t, []string{"/repo"}, res.WatchDirs, "non-empty slice replaces")
+	assert.Equal(t, []string{"quality", "testing"}, res.ReviewFirstAgents, "empty slice keeps base")
+	assert.Equal(t, []string{"quality"}, res.ReviewSecondAgents)
+	assert.Equal(t, []string{"base error"}, res.CodexErrorPatterns)
+	assert.Equal(t, []CodexReviewer{{Name: "broad", Prompt: "broad prompt"}}, res.CodexReviewers, "resolved reviewers kept")
+	assert.Equal(t, []string{"slack"}, res.NotifyParams.Channels)
+	assert.Equal(t, []string{"a@example.com"}, res.NotifyParams.EmailTo)
+
+	assert.Equal(t, []CustomAgent{
+		{Name: "quality", Prompt: "base quality", Builtin: true},
+		{Name: "security", Prompt: "repo security"},
+		{Name: "perf", Prompt: "repo perf"},
+	}, res.CustomAgents, "agents merge by name, new ones appended")
+
+	assert.Equal(t, status.PhaseNames{status.PhaseTask: "implement"}, res.PhaseNames, "non-empty map replaces")
+	assert.Equal(t, AgentModes{"perf": {"review"}}, res.AgentModes)
+	assert.Equal(t, map[hooks.Point]hooks.Failure{hooks.PreTask: hooks.FailureWarn}, res.HookFailure, "empty map keeps base")
+
+	// inputs untouched
+	res.PhaseNames[status.PhaseTask] = "changed"
+	assert.Equal(t, "implement", override.PhaseNames[status.PhaseTask])
+	assert.Equal(t, []string{"/base/a", "/base/b"}, base.WatchDirs)
+	assert.Equal(t, "base security", base.CustomAgents[1].Prompt)
+	assert.Len(t, base.CustomAgents, 2)
+	assert.Equal(t, "build", base.PhaseNames[status.PhaseTask])
+}
+
+func TestConfig_Merge_Profiles(t *testing.T) {
+	base := &Config{Profiles: map[string]*Config{
+		"fast":     configFromValues(Values{MaxIterations: 10, MaxIterationsSet: true, CodexModel: "gpt-5"}),
+		"thorough": configFromValues(Values{ReviewPatience: 3}),
+	}}
+	override := &Config{Profiles: map[string]*Config{
+		"fast": configFromValues(Values{CodexModel: "gpt-5-mini"}),
+		"docs": configFromValues(Values{PlansDir: "docs"}),
+	}}
+
+	res := base.Merge(override)
+	require.Len(t, res.Profiles, 3)
+	assert.Equal(t, 10, res.Profiles["fast"].MaxIterations)
+	assert.Equal(t, "gpt-5-mini", res.Profiles["fast"].CodexModel)
+	assert.Equal(t, 3, res.Profiles["thorough"].ReviewPatience)
+	assert.Equal(t, "docs", res.Profiles["docs"].PlansDir)
+	assert.Len(t, base.Profiles, 2, "base is not modified")
+	assert.Equal(t, "gpt-5", base.Profiles["fast"].CodexModel)
+}
+
+func TestConfig_ApplyProfile(t *testing.T) {
+	cfg := configFromValues(Values{
+		MaxIterations: 50, MaxIterationsSet: true, CodexEnabled: true, CodexEnabledSet: true, PlansDir: "docs/plans",
+	})
+	cfg.Profiles = map[string]*Config{
+		"fast":     configFromValues(Values{MaxIterations: 10, MaxIterationsSet: true, CodexEnabled: false, CodexEnabledSet: true}),
+		"thorough": configFromValues(Values{ReviewPatience: 3}),
+	}
+
+	res, err := cfg.ApplyProfile("fast")
+	require.NoError(t, err)
+	assert.Equal(t, 10, res.MaxIterations)
+	assert.False(t, res.CodexEnabled)
+	assert.Equal(t, "docs/plans", res.PlansDir, "keys the profile doesn't set are kept")
+	assert.Equal(t, 50, cfg.MaxIterations, "config is not modified")
+
+	_, err = cfg.ApplyProfile("slow")
+	require.EqualError(t, err, `unknown profile "slow", available: fast, thorough`)
+
+	_, err = (&Config{}).ApplyProfile("fast")
+	require.EqualError(t, err, `unknown profile "fast", no profiles defined`)
+}
+
+func TestConfig_Merge_Layered(t *testing.T) {
+	global := configFromValues(Values{ClaudeCommand: "claude", MaxIterations: 50, MaxIterationsSet: true, PlansDir: "docs/plans"})
+	user := configFromValues(Values{MaxIterations: 30, MaxIterationsSet: true, CodexModel: "gpt-5"})
+	repo := configFromValues(Values{PlansDir: "plans", CodexModel: "gpt-5-codex"})
+
+	res := global.Merge(user).Merge(repo)
+	assert.Equal(t, "claude", res.ClaudeCommand)
+	assert.Equal(t, 30, res.MaxIterations)
+	assert.Equal(t, "plans", res.PlansDir)
+	assert.Equal(t, "gpt-5-codex", res.CodexModel)
+}
+
+func TestConfig_Merge_MatchesFileLayering(t *testing.T) {
+	global := Values{MaxIterations: 50, MaxIterationsSet: true, NotifyOnComplete: true, NotifyOnCompleteSet: true,
+		PhaseNames: status.PhaseNames{status.PhaseTask: "build"}}
+	local := Values{MaxIterations: 0, MaxIterationsSet: true, NotifyOnComplete: false, NotifyOnCompleteSet: true,
+		CodexModel: "gpt-5"}
+
+	layered := global
+	layered.mergeFrom(&local)
+	assert.Equal(t, configFromValues(layered), configFromValues(global).Merge(configFromValues(local)),
+		"Merge layers config keys the same way Load layers the config files")
+}
@@ -22,6 +22,7 @@ type Prompts struct {
 	CustomReview string
 	CustomEval   string
 	CodexReview  string
+	TaskReview   string
 }
 
 // promptLoader implements PromptLoader with embedded filesystem fallback.
@@ -84,6 +85,11 @@ func (p *promptLoader) Load(localDir, globalDir string) (Prompts, error) {
 		return Prompts{}, fmt.Errorf("load codex_review prompt: %w", err)
 	}
 
+	prompts.TaskReview, err = p.loadPromptWithLocalFallback(localDir, globalDir, taskReviewPromptFile)
+	if err != nil {
+		return Prompts{}, fmt.Errorf("load task_review prompt: %w", err)
+	}
+
 	return prompts, nil
 }
 
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// schemaKeyRe matches a key description line in the config template, e.g. "# codex_model: model ID for codex".
+var schemaKeyRe = regexp.MustCompile(`^#\s*([a-z][a-z0-9_]*):\s*(.+)$`)
+
+// schemaAvailableRe matches the allowed values line that follows a key description, e.g. "# available: low, high".
+var schemaAvailableRe = regexp.MustCompile(`^#\s*available:\s*(.+)$`)
+
+// schemaDefaultRe matches the default value line that follows a key description, e.g. "# default: xhigh".
+var schemaDefaultRe = regexp.MustCompile(`^#\s*default:\s*(.+)$`)
+
+// schemaTemplateKeys maps json names to config file keys for the few fields where they differ.
+var schemaTemplateKeys = map[string]string{"worktree_enabled": "use_worktree"}
+
+// schemaNode is a JSON Schema fragment, limited to the keywords the config needs.
+type schemaNode struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	Items                *schemaNode            `json:"items,omitempty"`
+	Properties           map[string]*schemaNode `json:"properties,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+}
+
+// templateDoc holds documentation for a single key collected from the config template.
+type templateDoc struct {
+	description string
+	enum        []string
+	defaultVal  string
+}
+
+// Schema returns a JSON Schema describing the JSON form of Config.
+// properties are generated from Config's json tags, so new fields are picked up automatically.
+// descriptions, defaults and allowed values come from the embedded config template
+// for the matching config file key (the json tag, or its schemaTemplateKeys alias).
+func Schema() ([]byte, error) {
+	tmpl, err := defaultsFS.ReadFile("defaults/config")
+	if err != nil {
+		return nil, fmt.Errorf("read config template: %w", err)
+	}
+	docs := parseTemplateDocs(string(tmpl))
+
+	root := &schemaNode{
+		Schema:               "https://json-schema.org/draft/2020-12/schema",
+		Title:                "ralphex config",
+		Type:                 "object",
+		Properties:           map[string]*schemaNode{},
+		AdditionalProperties: false,
+	}
+	t := reflect.TypeFor[Config]()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		node := schemaForType(field.Type)
+		key := name
+		if k, ok := schemaTemplateKeys[name]; ok {
+			key = k
+		}
+		if doc, ok := docs[key]; ok {
+			desc := doc.description
+			if doc.defaultVal != "" {
+				desc += " (default: " + doc.defaultVal + ")"
+			}
+			if node.Description != "" {
+				desc += "; " + node.Description // keep the type hint, e.g. duration format
+			}
+			node.Description = desc
+			target := node
+			if node.Type == "array" {
+				target = node.Items // allowed values apply to list elements
+			}
+			target.Enum = doc.enum
+		}
+		root.Properties[name] = node
+	}
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal schema: %w", err)
+	}
+	return data, nil
+}
+
+// EffectiveValues returns the settings of c that Schema describes, keyed by the same json names.
+// durations are given in their string form (e.g. "30m0s"), other values as they are.
+// prompts, agents, colors and notification settings are not included.
+func (c *Config) EffectiveValues() map[string]any {
+	res := map[string]any{}
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		val := v.Field(i).Interface()
+		if d, ok := val.(time.Duration); ok {
+			val = d.String()
+		}
+		res[name] = val
+	}
+	return res
+}
+
+// schemaForType maps a Go type to its JSON Schema representation.
+func schemaForType(t reflect.Type) *schemaNode {
+	if t == reflect.TypeFor[time.Duration]() {
+		return &schemaNode{Type: "string", Description: "duration, e.g. 30m or 1h"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &schemaNode{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		zero := 0
+		return &schemaNode{Type: "integer", Minimum: &zero}
+	case reflect.Slice, reflect.Array:
+		return &schemaNode{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &schemaNode{Type: "object", AdditionalProperties: schemaForType(t.Elem())}
+	default:
+		return &schemaNode{Type: "string"}
+	}
+}
+
+// parseTemplateDocs collects key descriptions, allowed values and defaults from config template comments.
+// the template documents each key as "# key: description", optionally followed by
+// "# available: a, b" and "# default: x" lines before the next key.
+func parseTemplateDocs(tmpl string) map[string]templateDoc {
+	docs := map[string]templateDoc{}
+	var current string
+	scanner := bufio.NewScanner(strings.NewReader(tmpl))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "#") {
+			current = "" // blank line or key assignment ends the block
+			continue
+		}
+		if m := schemaAvailableRe.FindStringSubmatch(line); m != nil && current != "" {
+			doc := docs[current]
+			for v := range strings.SplitSeq(m[1], ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					doc.enum = append(doc.enum, v)
+				}
+			}
+			docs[current] = doc
+			continue
+		}
+		if m := schemaDefaultRe.FindStringSubmatch(line); m != nil && current != "" {
+			doc := docs[current]
+			doc.defaultVal = strings.TrimSpace(m[1])
+			docs[current] = doc
+			continue
+		}
+		if m := schemaKeyRe.FindStringSubmatch(line); m != nil && current == "" {
+			if _, seen := docs[m[1]]; !seen {
+				current = m[1]
+				docs[current] = templateDoc{description: m[2]}
+			}
+		}
+	}
+	return docs
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	data, err := Schema()
+	require.NoError(t, err)
+
+	var schema struct {
+		Type                 string `json:"type"`
+		AdditionalProperties bool   `json:"additionalProperties"`
+		Properties           map[string]struct {
+			Type        string   `json:"type"`
+			Description string   `json:"description"`
+			Enum        []string `json:"enum"`
+			Items       *struct {
+				Type string `json:"type"`
+			} `json:"items"`
+		} `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(data, &schema))
+	assert.Equal(t, "object", schema.Type)
+	assert.False(t, schema.AdditionalProperties)
+
+	tests := []struct {
+		key      string
+		wantType string
+		wantEnum []string
+		wantDesc string
+	}{
+		{key: "codex_sandbox", wantType: "string", wantEnum: []string{"read-only", "workspace-write", "danger-full-access"}},
+		{key: "codex_reasoning_effort", wantType: "string", wantEnum: []string{"low", "medium", "high", "xhigh"}},
+		{key: "default_mode", wantType: "string", wantEnum: []string{"full", "review", "external-only", "tasks-only"}},
+		{key: "task_order", wantType: "string", wantEnum: []string{"sequential", "as-listed", "by-number"}},
+		{key: "codex_model", wantType: "string", wantDesc: "model ID for codex (default: gpt-5.4)"},
+		{key: "max_iterations", wantType: "integer"},
+		{key: "codex_enabled", wantType: "boolean"},
+		{key: "watch_dirs", wantType: "array"},
+		{key: "wait_on_limit", wantType: "string", wantDesc: "duration, e.g. 30m or 1h"},
+		{key: "phase_names", wantType: "object"},
+		{key: "worktree_enabled", wantType: "boolean", wantDesc: "isolated git worktree"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.key, func(t *testing.T) {
+			prop, ok := schema.Properties[tc.key]
+			require.True(t, ok, "schema should describe %s", tc.key)
+			assert.Equal(t, tc.wantType, prop.Type)
+			assert.Equal(t, tc.wantEnum, prop.Enum)
+			assert.Contains(t, prop.Description, tc.wantDesc)
+		})
+	}
+
+	t.Run("covers every json-tagged config field", func(t *testing.T) {
+		typ := reflect.TypeFor[Config]()
+		var want int
+		for i := range typ.NumField() {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			want++
+			assert.Contains(t, schema.Properties, name)
+		}
+		assert.Len(t, schema.Properties, want)
+	})
+}
+
+func TestConfig_EffectiveValues(t *testing.T) {
+	cfg := &Config{MaxIterations: 7, WorktreeEnabled: true, WaitOnLimit: 90 * time.Minute,
+		ReviewFirstAgents: []string{"quality"}, TaskPrompt: "task prompt"}
+	values := cfg.EffectiveValues()
+	assert.Equal(t, 7, values["max_iterations"])
+	assert.Equal(t, true, values["worktree_enabled"])
+	assert.Equal(t, "1h30m0s", values["wait_on_limit"])
+	assert.Equal(t, []string{"quality"}, values["review_first_agents"])
+	assert.NotContains(t, values, "task_prompt")
+
+	data, err := Schema()
+	require.NoError(t, err)
+	var schema struct {
+		Properties map[string]any `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(data, &schema))
+	assert.Len(t, values, len(schema.Properties), "same keys as the schema")
+	for key := range schema.Properties {
+		assert.Contains(t, values, key)
+	}
+}
+
+func TestParseTemplateDocs(t *testing.T) {
+	docs := parseTemplateDocs(`# ---------------
+# section title
+# ---------------
+
+# some_key: what it does
+# available: a, b ,c
+# note: this is not a key
+# default: a
+some_key = a
+
+# other_key: another setting
+# other_key =
+`)
+	assert.Equal(t, templateDoc{description: "what it does", enum: []string{"a", "b", "c"}, defaultVal: "a"}, docs["some_key"])
+	assert.Equal(t, templateDoc{description: "another setting"}, docs["other_key"])
+	assert.NotContains(t, docs, "note")
+}
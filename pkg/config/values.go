@@ -3,54 +3,173 @@ package config
 import (
 	"embed"
 	"fmt"
+	"maps"
 	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"gopkg.in/ini.v1"
+
+	"github.com/umputun/ralphex/pkg/hooks"
+	"github.com/umputun/ralphex/pkg/status"
 )
 
+// defaultModes lists execution modes allowed in default_mode. plan mode needs a description, so it is excluded.
+var defaultModes = []string{"full", "review", "external-only", "tasks-only"}
+
+// taskOrders lists the values allowed in task_order.
+var taskOrders = []string{"sequential", "as-listed", "by-number"}
+
+// normalizeMode maps deprecated mode names to their current form, "codex-only" is an alias of "external-only".
+func normalizeMode(mode string) string {
+	if mode == "codex-only" {
+		return "external-only"
+	}
+	return mode
+}
+
+// envRefRe matches ${VAR} and ${VAR:-default} references, plus the $${ escape for a literal ${.
+var envRefRe = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// reviewerNameRe matches valid codex reviewer names, used in the codex_review_<name>.txt prompt file name.
+var reviewerNameRe = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// commitTrailerRe matches a git trailer line, "Key: value" with a token key made of letters, digits and dashes.
+var commitTrailerRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*: \S.*$`)
+
+// commitTrailerPlaceholderRe matches {{NAME}} placeholders in commit_trailer.
+var commitTrailerPlaceholderRe = regexp.MustCompile(`\{\{[^}]*\}\}`)
+
 // Values holds scalar configuration values.
 // Fields ending in *Set (e.g., CodexEnabledSet) track whether that field was explicitly
 // set in config. This allows distinguishing explicit false/0 from "not set", enabling
 // proper merge behavior where local config can override global config with zero values.
 type Values struct {
-	ClaudeCommand         string
-	ClaudeArgs            string
-	ClaudeErrorPatterns   []string // patterns to detect in claude output (e.g., rate limit messages)
-	CodexEnabled          bool
-	CodexEnabledSet       bool // tracks if codex_enabled was explicitly set
-	CodexCommand          string
-	CodexModel            string
-	CodexReasoningEffort  string
-	CodexTimeoutMs        int
-	CodexTimeoutMsSet     bool // tracks if codex_timeout_ms was explicitly set
-	CodexSandbox          string
-	CodexErrorPatterns    []string // patterns to detect in codex output (e.g., rate limit messages)
-	ClaudeLimitPatterns   []string // patterns to detect rate limits in claude output (for wait+retry)
-	CodexLimitPatterns    []string // patterns to detect rate limits in codex output (for wait+retry)
-	WaitOnLimit           time.Duration
-	WaitOnLimitSet        bool // tracks if wait_on_limit was explicitly set
-	SessionTimeout        time.Duration
-	SessionTimeoutSet     bool   // tracks if session_timeout was explicitly set
-	ExternalReviewTool    string // "codex", "custom", or "none"
-	CustomReviewScript    string // path to custom review script (when ExternalReviewTool = "custom")
-	IterationDelayMs      int
-	IterationDelayMsSet   bool // tracks if iteration_delay_ms was explicitly set
-	TaskRetryCount        int
-	TaskRetryCountSet     bool // tracks if task_retry_count was explicitly set
-	MaxIterations         int
-	MaxIterationsSet      bool // tracks if max_iterations was explicitly set
-	MaxExternalIterations int  // override external review iteration limit (0 = auto)
-	ReviewPatience        int  // terminate external review after N unchanged rounds (0 = disabled)
-	FinalizeEnabled       bool
-	FinalizeEnabledSet    bool // tracks if finalize_enabled was explicitly set
-	WorktreeEnabled       bool
-	WorktreeEnabledSet    bool   // tracks if use_worktree was explicitly set
-	VcsCommand            string // custom VCS command (default: "git")
-	PlansDir              string
-	DefaultBranch         string   // override auto-detected default branch
-	WatchDirs             []string // directories to watch for progress files
+	ClaudeCommand                  string
+	ClaudeArgs                     string
+	ClaudeErrorPatterns            []string // patterns to detect in claude output (e.g., rate limit messages)
+	ClaudeOutputFilter             bool
+	ClaudeOutputFilterSet          bool // tracks if claude_output_filter was explicitly set
+	StripOutputANSI                bool // remove ANSI escape sequences from captured executor output
+	StripOutputANSISet             bool // tracks if strip_output_ansi was explicitly set
+	PlanModel                      string
+	CodexEnabled                   bool
+	CodexEnabledSet                bool // tracks if codex_enabled was explicitly set
+	CodexCommand                   string
+	CodexModel                     string
+	CodexReasoningEffort           string
+	CodexTimeoutMs                 int
+	CodexTimeoutMsSet              bool // tracks if codex_timeout_ms was explicitly set
+	CodexSandbox                   string
+	CodexErrorPatterns             []string // patterns to detect in codex output (e.g., rate limit messages)
+	ClaudeLimitPatterns            []string // patterns to detect rate limits in claude output (for wait+retry)
+	CodexLimitPatterns             []string // patterns to detect rate limits in codex output (for wait+retry)
+	WaitOnLimit                    time.Duration
+	WaitOnLimitSet                 bool // tracks if wait_on_limit was explicitly set
+	MaxLimitRetries                int  // cap on wait+retry cycles per run call (0 = unlimited)
+	ClaudeIdleTimeout              time.Duration
+	ClaudeIdleTimeoutSet           bool // tracks if claude_idle_timeout was explicitly set
+	DashboardBatchInterval         time.Duration
+	DashboardBatchSet              bool // tracks if dashboard_batch_interval was explicitly set
+	HeartbeatInterval              time.Duration
+	HeartbeatIntervalSet           bool // tracks if heartbeat_interval was explicitly set
+	SessionTimeout                 time.Duration
+	SessionTimeoutSet              bool   // tracks if session_timeout was explicitly set
+	ExternalReviewTool             string // "codex", "custom", or "none"
+	CustomReviewScript             string // path to custom review script (when ExternalReviewTool = "custom")
+	IterationDelayMs               int
+	IterationDelayMsSet            bool // tracks if iteration_delay_ms was explicitly set
+	TaskRetryCount                 int
+	TaskRetryCountSet              bool // tracks if task_retry_count was explicitly set
+	ReviewRetryCount               int
+	ReviewRetryCountSet            bool // tracks if review_retry_count was explicitly set
+	MaxIterations                  int
+	MaxIterationsSet               bool // tracks if max_iterations was explicitly set
+	MaxExternalIterations          int  // override external review iteration limit (0 = auto)
+	MaxExternalRounds              int  // cap on external review rounds across the run, exceeding it fails the run (0 = no cap)
+	ReviewPatience                 int  // terminate external review after N unchanged rounds (0 = disabled)
+	CodexMinDiffLines              int  // skip codex review when branch diff has fewer changed lines (0 = never skip)
+	CodexBlameHints                bool // log the last author of each file:line referenced by codex findings
+	CodexBlameHintsSet             bool // tracks if codex_blame_hints was explicitly set
+	CodexFailOnP1                  bool // abort the run on codex P0/P1 findings instead of letting claude fix them
+	CodexFailOnP1Set               bool // tracks if codex_fail_on_p1 was explicitly set
+	PlanMaxTasks                   int  // warn when a plan has more tasks than this (0 = no limit)
+	PlanMaxTasksSet                bool // tracks if plan_max_tasks was explicitly set
+	EmptyIterationLimit            int  // fail the task phase after N consecutive iterations without output (0 = disabled)
+	EmptyIterationLimitSet         bool // tracks if empty_iteration_limit was explicitly set
+	MinFreeDiskMB                  int  // abort the run when free disk space drops below N megabytes (0 = disabled)
+	MinFreeDiskMBSet               bool // tracks if min_free_disk_mb was explicitly set
+	FinalizeEnabled                bool
+	FinalizeEnabledSet             bool   // tracks if finalize_enabled was explicitly set
+	FinalizeNoCommit               bool   // finalize stages its changes but leaves them uncommitted
+	FinalizeNoCommitSet            bool   // tracks if finalize_no_commit was explicitly set
+	PreRunCommand                  string // shell command run before the runner starts, failure aborts the run
+	PostRunCommand                 string // shell command run after the runner finishes, failure is a warning
+	IncludeCommitLog               bool
+	IncludeCommitLogSet            bool // tracks if include_commit_log was explicitly set
+	EmbedPlanInPrompt              bool
+	EmbedPlanInPromptSet           bool // tracks if embed_plan_in_prompt was explicitly set
+	TagOnComplete                  bool
+	TagOnCompleteSet               bool // tracks if tag_on_complete was explicitly set
+	WriteSummary                   bool // write completed/<plan>.summary.json with the run summary
+	WriteSummarySet                bool // tracks if write_summary was explicitly set
+	ReportTestResults              bool // run the test command after a successful run and report the outcome
+	ReportTestResultsSet           bool // tracks if report_test_results was explicitly set
+	SuppressDeprecationWarnings    bool // skip warnings about deprecated flags and options
+	SuppressDeprecationWarningsSet bool // tracks if suppress_deprecation_warnings was explicitly set
+	ReviewPerTask                  bool // review each task's commits right after the task
+	ReviewPerTaskSet               bool // tracks if review_per_task was explicitly set
+	SkipFinalReview                bool // with review_per_task, skip the whole-branch claude review before codex
+	SkipFinalReviewSet             bool // tracks if skip_final_review was explicitly set
+	ReviewWorkingTree              bool // review uncommitted working tree changes instead of the branch diff
+	ReviewWorkingTreeSet           bool // tracks if review_working_tree was explicitly set
+	ReviewIncludePlan              bool // include the plan file and progress dir in review diffs and stats
+	ReviewIncludePlanSet           bool // tracks if review_include_plan was explicitly set
+	AutoUnshallow                  bool
+	AutoUnshallowSet               bool // tracks if auto_unshallow was explicitly set
+	AllowExternalPlans             bool // run plan files located outside plans_dir
+	AllowExternalPlansSet          bool // tracks if allow_external_plans was explicitly set
+	TerminalTitle                  bool
+	TerminalTitleSet               bool // tracks if terminal_title was explicitly set
+	SplitProgressByTask            bool
+	SplitProgressByTaskSet         bool // tracks if split_progress_by_task was explicitly set
+	WorktreeEnabled                bool
+	WorktreeEnabledSet             bool // tracks if use_worktree was explicitly set
+	KeepWorktreeOnFailure          bool
+	KeepWorktreeOnFailureSet       bool   // tracks if keep_worktree_on_failure was explicitly set
+	VcsCommand                     string // custom VCS command (default: "git")
+	FzfCommand                     string // fzf binary used for plan selection (default: "fzf")
+	FzfArgs                        string // extra fzf arguments (space-separated, quotes supported)
+	PlansDir                       string
+	DefaultBranch                  string                        // override auto-detected default branch
+	PushRemote                     string                        // remote pushed to, empty = origin
+	PushRefspecTemplate            string                        // ref the branch is pushed to after completion, {{BRANCH}} = branch, empty = no push
+	ProgressBranch                 string                        // orphan branch progress files are committed to, empty = off
+	BranchNameTemplate             string                        // branch name derived from a plan, {{SLUG}} = plan slug
+	CommitTrailer                  string                        // trailer added to every ralphex commit, {{VERSION}} = ralphex version
+	BranchStripPattern             string                        // regex removed from the start of the plan file name, empty = date prefix
+	InProgressMarker               string                        // checkbox mark of an in-progress plan item, empty = "~"
+	DefaultMode                    string                        // execution mode used when no mode flag is given
+	TaskOrder                      string                        // how the next plan task is picked, empty = sequential
+	TestCommand                    string                        // project test command, auto-detected from repo markers when empty
+	WatchDirs                      []string                      // directories to watch for progress files
+	PhaseNames                     status.PhaseNames             // custom phase display labels, e.g. task -> Implementation
+	AgentModes                     AgentModes                    // agent name -> modes the agent runs in, unlisted agents run in all modes
+	CodexReviewers                 []CodexReviewer               // named codex passes run in each external review round, prompts not loaded yet
+	CodexReviewerConditions        map[string]ReviewerConditions // reviewer name -> when it runs, from the codex_reviewer_* keys
+	ReviewFocus                    []ReviewFocus                 // pattern -> focus snippet entries for {{REVIEW_FOCUS}}, prompts not loaded yet
+	ReviewFirstAgents              []string                      // agents launched by the first review pass, expands {{REVIEW_AGENTS}}
+	ReviewSecondAgents             []string                      // agents launched by the second review pass, expands {{REVIEW_AGENTS}}
+	DisabledReviewAgents           []string                      // built-in agents removed from the review prompts
+
+	HookFailure map[hooks.Point]hooks.Failure // per-hook failure mode overrides, e.g. pre-task -> warn
+
+	EstimateTokens  map[string]int // --estimate average tokens per iteration by phase, e.g. review -> 120000
+	EstimateMinutes map[string]int // --estimate average minutes per iteration by phase, e.g. task -> 6
 
 	// notification settings
 	NotifyChannels        []string // channels to use: telegram, email, webhook, slack, custom
@@ -78,8 +197,13 @@ type Values struct {
 	NotifyWebhookURLs     []string // comma-separated in config
 	NotifyWebhookURLsSet  bool     // tracks if notify_webhook_urls was explicitly set (allows empty to disable)
 	NotifyCustomScript    string   // path to custom notification script (tilde-expanded)
+
+	Profiles map[string]Values // named presets from [profile <name>] sections, selected with --profile
 }
 
+// profileSection is the first word of a config section holding a named profile, e.g. "[profile fast]".
+const profileSection = "profile"
+
 // valuesLoader implements ValuesLoader with embedded filesystem fallback.
 type valuesLoader struct {
 	embedFS embed.FS
@@ -157,8 +281,8 @@ func (vl *valuesLoader) parseValuesFromEmbedded() (Values, error) {
 }
 
 // parseValuesFromBytes parses configuration from a byte slice into Values.
-//
-//nolint:gocyclo // high complexity from many config keys; splitting would hurt readability
+// keys before any section header are the main settings, [profile <name>] sections hold named profiles
+// with the same keys. other sections are ignored.
 func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 	// ignoreInlineComment: true prevents # from being treated as inline comment marker
 	cfg, err := ini.LoadSources(ini.LoadOptions{IgnoreInlineComment: true}, data)
@@ -166,8 +290,42 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		return Values{}, fmt.Errorf("parse config: %w", err)
 	}
 
+	values, err := vl.parseSectionValues(cfg.Section("")) // default section (no section header)
+	if err != nil {
+		return Values{}, err
+	}
+
+	for _, section := range cfg.Sections() {
+		kind, name, _ := strings.Cut(section.Name(), " ")
+		if kind != profileSection {
+			continue
+		}
+		if name = strings.TrimSpace(name); name == "" {
+			return Values{}, fmt.Errorf("invalid profile section %q: missing profile name", section.Name())
+		}
+		profile, err := vl.parseSectionValues(section)
+		if err != nil {
+			return Values{}, fmt.Errorf("profile %s: %w", name, err)
+		}
+		if values.Profiles == nil {
+			values.Profiles = make(map[string]Values)
+		}
+		values.Profiles[name] = profile
+	}
+
+	return values, nil
+}
+
+// parseSectionValues parses the config keys of a single INI section into Values.
+//
+//nolint:gocyclo // high complexity from many config keys; splitting would hurt readability
+func (vl *valuesLoader) parseSectionValues(section *ini.Section) (Values, error) {
 	var values Values
-	section := cfg.Section("") // default section (no section header)
+
+	// expand ${VAR} and ${VAR:-default} references before any value is parsed
+	if err := interpolateEnv(section); err != nil {
+		return Values{}, err
+	}
 
 	// claude settings
 	if key, err := section.GetKey("claude_command"); err == nil {
@@ -176,6 +334,26 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 	if key, err := section.GetKey("claude_args"); err == nil {
 		values.ClaudeArgs = key.String()
 	}
+	if key, err := section.GetKey("claude_output_filter"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid claude_output_filter: %w", boolErr)
+		}
+		values.ClaudeOutputFilter = val
+		values.ClaudeOutputFilterSet = true
+	}
+	if key, err := section.GetKey("strip_output_ansi"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid strip_output_ansi: %w", boolErr)
+		}
+		values.StripOutputANSI = val
+		values.StripOutputANSISet = true
+	}
+
+	if key, err := section.GetKey("plan_model"); err == nil {
+		values.PlanModel = key.String()
+	}
 
 	// codex settings
 	if key, err := section.GetKey("codex_enabled"); err == nil {
@@ -241,6 +419,17 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		values.TaskRetryCount = val
 		values.TaskRetryCountSet = true
 	}
+	if key, err := section.GetKey("review_retry_count"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid review_retry_count: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid review_retry_count: must be non-negative, got %d", val)
+		}
+		values.ReviewRetryCount = val
+		values.ReviewRetryCountSet = true
+	}
 	if key, err := section.GetKey("max_iterations"); err == nil {
 		val, intErr := key.Int()
 		if intErr != nil {
@@ -262,6 +451,16 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		}
 		values.MaxExternalIterations = val
 	}
+	if key, err := section.GetKey("max_external_rounds"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid max_external_rounds: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid max_external_rounds: must be non-negative, got %d", val)
+		}
+		values.MaxExternalRounds = val
+	}
 	if key, err := section.GetKey("review_patience"); err == nil {
 		val, intErr := key.Int()
 		if intErr != nil {
@@ -272,6 +471,65 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		}
 		values.ReviewPatience = val
 	}
+	if key, err := section.GetKey("codex_min_diff_lines"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid codex_min_diff_lines: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid codex_min_diff_lines: must be non-negative, got %d", val)
+		}
+		values.CodexMinDiffLines = val
+	}
+	if key, err := section.GetKey("codex_blame_hints"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid codex_blame_hints: %w", boolErr)
+		}
+		values.CodexBlameHints = val
+		values.CodexBlameHintsSet = true
+	}
+	if key, err := section.GetKey("codex_fail_on_p1"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid codex_fail_on_p1: %w", boolErr)
+		}
+		values.CodexFailOnP1 = val
+		values.CodexFailOnP1Set = true
+	}
+	if key, err := section.GetKey("plan_max_tasks"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid plan_max_tasks: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid plan_max_tasks: must be non-negative, got %d", val)
+		}
+		values.PlanMaxTasks = val
+		values.PlanMaxTasksSet = true
+	}
+	if key, err := section.GetKey("empty_iteration_limit"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid empty_iteration_limit: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid empty_iteration_limit: must be non-negative, got %d", val)
+		}
+		values.EmptyIterationLimit = val
+		values.EmptyIterationLimitSet = true
+	}
+	if key, err := section.GetKey("min_free_disk_mb"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid min_free_disk_mb: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid min_free_disk_mb: must be non-negative, got %d", val)
+		}
+		values.MinFreeDiskMB = val
+		values.MinFreeDiskMBSet = true
+	}
 
 	// finalize settings
 	if key, err := section.GetKey("finalize_enabled"); err == nil {
@@ -282,6 +540,145 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		values.FinalizeEnabled = val
 		values.FinalizeEnabledSet = true
 	}
+	if key, err := section.GetKey("finalize_no_commit"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid finalize_no_commit: %w", boolErr)
+		}
+		values.FinalizeNoCommit = val
+		values.FinalizeNoCommitSet = true
+	}
+
+	// run hooks
+	if key, err := section.GetKey("pre_run_command"); err == nil {
+		values.PreRunCommand = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("post_run_command"); err == nil {
+		values.PostRunCommand = strings.TrimSpace(key.String())
+	}
+
+	// review prompt settings
+	if key, err := section.GetKey("include_commit_log"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid include_commit_log: %w", boolErr)
+		}
+		values.IncludeCommitLog = val
+		values.IncludeCommitLogSet = true
+	}
+	if key, err := section.GetKey("embed_plan_in_prompt"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid embed_plan_in_prompt: %w", boolErr)
+		}
+		values.EmbedPlanInPrompt = val
+		values.EmbedPlanInPromptSet = true
+	}
+
+	// completion tag settings
+	if key, err := section.GetKey("tag_on_complete"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid tag_on_complete: %w", boolErr)
+		}
+		values.TagOnComplete = val
+		values.TagOnCompleteSet = true
+	}
+	if key, err := section.GetKey("write_summary"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid write_summary: %w", boolErr)
+		}
+		values.WriteSummary = val
+		values.WriteSummarySet = true
+	}
+	if key, err := section.GetKey("report_test_results"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid report_test_results: %w", boolErr)
+		}
+		values.ReportTestResults = val
+		values.ReportTestResultsSet = true
+	}
+	if key, err := section.GetKey("suppress_deprecation_warnings"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid suppress_deprecation_warnings: %w", boolErr)
+		}
+		values.SuppressDeprecationWarnings = val
+		values.SuppressDeprecationWarningsSet = true
+	}
+	if key, err := section.GetKey("review_per_task"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid review_per_task: %w", boolErr)
+		}
+		values.ReviewPerTask = val
+		values.ReviewPerTaskSet = true
+	}
+	if key, err := section.GetKey("skip_final_review"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid skip_final_review: %w", boolErr)
+		}
+		values.SkipFinalReview = val
+		values.SkipFinalReviewSet = true
+	}
+	if key, err := section.GetKey("review_working_tree"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid review_working_tree: %w", boolErr)
+		}
+		values.ReviewWorkingTree = val
+		values.ReviewWorkingTreeSet = true
+	}
+	if key, err := section.GetKey("review_include_plan"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid review_include_plan: %w", boolErr)
+		}
+		values.ReviewIncludePlan = val
+		values.ReviewIncludePlanSet = true
+	}
+
+	// shallow clone settings
+	if key, err := section.GetKey("auto_unshallow"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid auto_unshallow: %w", boolErr)
+		}
+		values.AutoUnshallow = val
+		values.AutoUnshallowSet = true
+	}
+
+	if key, err := section.GetKey("allow_external_plans"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid allow_external_plans: %w", boolErr)
+		}
+		values.AllowExternalPlans = val
+		values.AllowExternalPlansSet = true
+	}
+
+	// terminal title settings
+	if key, err := section.GetKey("terminal_title"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid terminal_title: %w", boolErr)
+		}
+		values.TerminalTitle = val
+		values.TerminalTitleSet = true
+	}
+
+	// per-task progress segments
+	if key, err := section.GetKey("split_progress_by_task"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid split_progress_by_task: %w", boolErr)
+		}
+		values.SplitProgressByTask = val
+		values.SplitProgressByTaskSet = true
+	}
 
 	// worktree settings
 	if key, err := section.GetKey("use_worktree"); err == nil {
@@ -292,6 +689,14 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		values.WorktreeEnabled = val
 		values.WorktreeEnabledSet = true
 	}
+	if key, err := section.GetKey("keep_worktree_on_failure"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid keep_worktree_on_failure: %w", boolErr)
+		}
+		values.KeepWorktreeOnFailure = val
+		values.KeepWorktreeOnFailureSet = true
+	}
 
 	// paths
 	if key, err := section.GetKey("plans_dir"); err == nil {
@@ -300,13 +705,131 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 	if key, err := section.GetKey("default_branch"); err == nil {
 		values.DefaultBranch = strings.TrimSpace(key.String())
 	}
+	if key, err := section.GetKey("push_remote"); err == nil {
+		values.PushRemote = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("push_refspec_template"); err == nil {
+		values.PushRefspecTemplate = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("progress_branch"); err == nil {
+		values.ProgressBranch = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("branch_name_template"); err == nil {
+		tmpl := strings.TrimSpace(key.String())
+		if tmpl != "" && !strings.Contains(tmpl, "{{SLUG}}") {
+			return Values{}, fmt.Errorf("invalid branch_name_template %q: must contain {{SLUG}}", tmpl)
+		}
+		values.BranchNameTemplate = tmpl
+	}
+	if key, err := section.GetKey("commit_trailer"); err == nil {
+		trailer := strings.TrimSpace(key.String())
+		if err := validateCommitTrailer(trailer); err != nil {
+			return Values{}, err
+		}
+		values.CommitTrailer = trailer
+	}
+	if key, err := section.GetKey("branch_strip_pattern"); err == nil {
+		pattern := strings.TrimSpace(key.String())
+		if _, reErr := regexp.Compile(pattern); reErr != nil {
+			return Values{}, fmt.Errorf("invalid branch_strip_pattern: %w", reErr)
+		}
+		values.BranchStripPattern = pattern
+	}
+	if key, err := section.GetKey("in_progress_marker"); err == nil {
+		values.InProgressMarker = strings.TrimSpace(key.String())
+	}
 	if key, err := section.GetKey("vcs_command"); err == nil {
 		values.VcsCommand = expandTilde(key.String())
 	}
 
+	if key, err := section.GetKey("test_command"); err == nil {
+		values.TestCommand = strings.TrimSpace(key.String())
+	}
+
+	// execution mode
+	if key, err := section.GetKey("default_mode"); err == nil {
+		mode := normalizeMode(strings.TrimSpace(key.String()))
+		if mode != "" && !slices.Contains(defaultModes, mode) {
+			return Values{}, fmt.Errorf("invalid default_mode %q, expected one of: %s", mode, strings.Join(defaultModes, ", "))
+		}
+		values.DefaultMode = mode
+	}
+
+	// task selection
+	if key, err := section.GetKey("task_order"); err == nil {
+		order := strings.TrimSpace(key.String())
+		if order != "" && !slices.Contains(taskOrders, order) {
+			return Values{}, fmt.Errorf("invalid task_order %q, expected one of: %s", order, strings.Join(taskOrders, ", "))
+		}
+		values.TaskOrder = order
+	}
+
+	// plan selection
+	if key, err := section.GetKey("fzf_command"); err == nil {
+		values.FzfCommand = expandTilde(strings.TrimSpace(key.String()))
+	}
+	if key, err := section.GetKey("fzf_args"); err == nil {
+		values.FzfArgs = strings.TrimSpace(key.String())
+	}
+
 	// watch directories (comma-separated)
 	values.WatchDirs = vl.parseCommaSeparated(section, "watch_dirs")
 
+	// phase display names (comma-separated phase:label pairs)
+	phaseNames, err := vl.parsePhaseNames(section)
+	if err != nil {
+		return Values{}, err
+	}
+	values.PhaseNames = phaseNames
+
+	// agent mode scoping (comma-separated agent:mode pairs)
+	agentModes, err := vl.parseAgentModes(section)
+	if err != nil {
+		return Values{}, err
+	}
+	values.AgentModes = agentModes
+
+	// codex reviewers (comma-separated name or name:project_doc entries)
+	reviewers, err := vl.parseCodexReviewers(section)
+	if err != nil {
+		return Values{}, err
+	}
+	values.CodexReviewers = reviewers
+
+	// codex reviewer conditions (comma-separated reviewer:value pairs)
+	conditions, err := vl.parseReviewerConditions(section)
+	if err != nil {
+		return Values{}, err
+	}
+	values.CodexReviewerConditions = conditions
+
+	// review focus (comma-separated pattern:name pairs)
+	focus, err := vl.parseReviewFocus(section)
+	if err != nil {
+		return Values{}, err
+	}
+	values.ReviewFocus = focus
+
+	// review agent sets (comma-separated agent names)
+	values.ReviewFirstAgents = vl.parseCommaSeparated(section, "review_first_agents")
+	values.ReviewSecondAgents = vl.parseCommaSeparated(section, "review_second_agents")
+	values.DisabledReviewAgents = vl.parseCommaSeparated(section, "disabled_review_agents")
+
+	// hooks directory failure modes (comma-separated hook:mode pairs)
+	hookFailure, err := vl.parseHookFailure(section)
+	if err != nil {
+		return Values{}, err
+	}
+	values.HookFailure = hookFailure
+
+	// --estimate per-phase averages (comma-separated phase:number pairs)
+	if values.EstimateTokens, err = vl.parseEstimateAverages(section, "estimate_tokens"); err != nil {
+		return Values{}, err
+	}
+	if values.EstimateMinutes, err = vl.parseEstimateAverages(section, "estimate_minutes"); err != nil {
+		return Values{}, err
+	}
+
 	// notification settings
 	if err := vl.parseNotifyValues(section, &values); err != nil {
 		return Values{}, err
@@ -324,12 +847,37 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 	if err := vl.parseWaitOnLimit(section, &values); err != nil {
 		return Values{}, err
 	}
+	if key, err := section.GetKey("max_limit_retries"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid max_limit_retries: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid max_limit_retries: must be non-negative, got %d", val)
+		}
+		values.MaxLimitRetries = val
+	}
 
 	// session_timeout duration
 	if err := vl.parseSessionTimeout(section, &values); err != nil {
 		return Values{}, err
 	}
 
+	// claude_idle_timeout duration
+	if err := vl.parseClaudeIdleTimeout(section, &values); err != nil {
+		return Values{}, err
+	}
+
+	// dashboard_batch_interval duration
+	if err := vl.parseDashboardBatchInterval(section, &values); err != nil {
+		return Values{}, err
+	}
+
+	// heartbeat_interval duration
+	if err := vl.parseHeartbeatInterval(section, &values); err != nil {
+		return Values{}, err
+	}
+
 	return values, nil
 }
 
@@ -375,6 +923,71 @@ func (vl *valuesLoader) parseSessionTimeout(section *ini.Section, values *Values
 	return nil
 }
 
+// parseClaudeIdleTimeout parses claude_idle_timeout duration from an INI section.
+func (vl *valuesLoader) parseClaudeIdleTimeout(section *ini.Section, values *Values) error {
+	if !section.HasKey("claude_idle_timeout") {
+		return nil
+	}
+	val := strings.TrimSpace(section.Key("claude_idle_timeout").String())
+	if val == "" {
+		return nil
+	}
+	d, parseErr := time.ParseDuration(val)
+	if parseErr != nil {
+		return fmt.Errorf("invalid claude_idle_timeout: %w", parseErr)
+	}
+	if d < 0 {
+		return fmt.Errorf("invalid claude_idle_timeout: must be non-negative, got %s", val)
+	}
+	values.ClaudeIdleTimeout = d
+	values.ClaudeIdleTimeoutSet = true
+	return nil
+}
+
+// parseDashboardBatchInterval parses dashboard_batch_interval duration from an INI section.
+// 0 is a valid value and disables batching.
+func (vl *valuesLoader) parseDashboardBatchInterval(section *ini.Section, values *Values) error {
+	if !section.HasKey("dashboard_batch_interval") {
+		return nil
+	}
+	val := strings.TrimSpace(section.Key("dashboard_batch_interval").String())
+	if val == "" {
+		return nil
+	}
+	d, parseErr := time.ParseDuration(val)
+	if parseErr != nil {
+		return fmt.Errorf("invalid dashboard_batch_interval: %w", parseErr)
+	}
+	if d < 0 {
+		return fmt.Errorf("invalid dashboard_batch_interval: must be non-negative, got %s", val)
+	}
+	values.DashboardBatchInterval = d
+	values.DashboardBatchSet = true
+	return nil
+}
+
+// parseHeartbeatInterval parses heartbeat_interval duration from an INI section.
+// 0 is a valid value and disables the heartbeat.
+func (vl *valuesLoader) parseHeartbeatInterval(section *ini.Section, values *Values) error {
+	if !section.HasKey("heartbeat_interval") {
+		return nil
+	}
+	val := strings.TrimSpace(section.Key("heartbeat_interval").String())
+	if val == "" {
+		return nil
+	}
+	d, parseErr := time.ParseDuration(val)
+	if parseErr != nil {
+		return fmt.Errorf("invalid heartbeat_interval: %w", parseErr)
+	}
+	if d < 0 {
+		return fmt.Errorf("invalid heartbeat_interval: must be non-negative, got %s", val)
+	}
+	values.HeartbeatInterval = d
+	values.HeartbeatIntervalSet = true
+	return nil
+}
+
 // mergeFrom merges non-empty values from src into dst.
 func (dst *Values) mergeFrom(src *Values) {
 	if src.ClaudeCommand != "" {
@@ -383,6 +996,9 @@ func (dst *Values) mergeFrom(src *Values) {
 	if src.ClaudeArgs != "" {
 		dst.ClaudeArgs = src.ClaudeArgs
 	}
+	if src.PlanModel != "" {
+		dst.PlanModel = src.PlanModel
+	}
 	if src.CodexEnabledSet {
 		dst.CodexEnabled = src.CodexEnabled
 		dst.CodexEnabledSet = true
@@ -412,6 +1028,25 @@ func (dst *Values) mergeFrom(src *Values) {
 	dst.mergeExecutionFrom(src)
 	dst.mergeExtraFrom(src)
 	dst.mergeNotifyFrom(src)
+	dst.mergeProfilesFrom(src)
+}
+
+// mergeProfilesFrom merges named profiles from src into dst. a profile defined in both
+// is merged key by key, so a local profile only needs the keys it changes.
+func (dst *Values) mergeProfilesFrom(src *Values) {
+	if len(src.Profiles) == 0 {
+		return
+	}
+	profiles := maps.Clone(dst.Profiles)
+	if profiles == nil {
+		profiles = make(map[string]Values, len(src.Profiles))
+	}
+	for name, p := range src.Profiles {
+		merged := profiles[name]
+		merged.mergeFrom(&p)
+		profiles[name] = merged
+	}
+	dst.Profiles = profiles
 }
 
 // mergeExecutionFrom merges execution-related fields from src into dst.
@@ -425,6 +1060,10 @@ func (dst *Values) mergeExecutionFrom(src *Values) {
 		dst.TaskRetryCount = src.TaskRetryCount
 		dst.TaskRetryCountSet = true
 	}
+	if src.ReviewRetryCountSet {
+		dst.ReviewRetryCount = src.ReviewRetryCount
+		dst.ReviewRetryCountSet = true
+	}
 	if src.MaxIterationsSet {
 		dst.MaxIterations = src.MaxIterations
 		dst.MaxIterationsSet = true
@@ -432,34 +1071,205 @@ func (dst *Values) mergeExecutionFrom(src *Values) {
 	if src.MaxExternalIterations > 0 {
 		dst.MaxExternalIterations = src.MaxExternalIterations
 	}
+	if src.MaxExternalRounds > 0 {
+		dst.MaxExternalRounds = src.MaxExternalRounds
+	}
 	if src.ReviewPatience > 0 {
 		dst.ReviewPatience = src.ReviewPatience
 	}
+	if src.CodexMinDiffLines > 0 {
+		dst.CodexMinDiffLines = src.CodexMinDiffLines
+	}
+	if src.CodexBlameHintsSet {
+		dst.CodexBlameHints = src.CodexBlameHints
+		dst.CodexBlameHintsSet = true
+	}
+	if src.CodexFailOnP1Set {
+		dst.CodexFailOnP1 = src.CodexFailOnP1
+		dst.CodexFailOnP1Set = true
+	}
+	if src.PlanMaxTasksSet {
+		dst.PlanMaxTasks = src.PlanMaxTasks
+		dst.PlanMaxTasksSet = true
+	}
+	if src.EmptyIterationLimitSet {
+		dst.EmptyIterationLimit = src.EmptyIterationLimit
+		dst.EmptyIterationLimitSet = true
+	}
+	if src.MinFreeDiskMBSet {
+		dst.MinFreeDiskMB = src.MinFreeDiskMB
+		dst.MinFreeDiskMBSet = true
+	}
 }
 
 // mergeExtraFrom merges feature flags, paths, error/limit patterns, and wait settings from src into dst.
 // called from mergeFrom to manage cyclomatic complexity.
 func (dst *Values) mergeExtraFrom(src *Values) {
+	if src.ClaudeOutputFilterSet {
+		dst.ClaudeOutputFilter = src.ClaudeOutputFilter
+		dst.ClaudeOutputFilterSet = true
+	}
+	if src.StripOutputANSISet {
+		dst.StripOutputANSI = src.StripOutputANSI
+		dst.StripOutputANSISet = true
+	}
 	if src.FinalizeEnabledSet {
 		dst.FinalizeEnabled = src.FinalizeEnabled
 		dst.FinalizeEnabledSet = true
 	}
+	if src.FinalizeNoCommitSet {
+		dst.FinalizeNoCommit = src.FinalizeNoCommit
+		dst.FinalizeNoCommitSet = true
+	}
+	if src.PreRunCommand != "" {
+		dst.PreRunCommand = src.PreRunCommand
+	}
+	if src.PostRunCommand != "" {
+		dst.PostRunCommand = src.PostRunCommand
+	}
+	if src.IncludeCommitLogSet {
+		dst.IncludeCommitLog = src.IncludeCommitLog
+		dst.IncludeCommitLogSet = true
+	}
+	if src.EmbedPlanInPromptSet {
+		dst.EmbedPlanInPrompt = src.EmbedPlanInPrompt
+		dst.EmbedPlanInPromptSet = true
+	}
+	if src.TagOnCompleteSet {
+		dst.TagOnComplete = src.TagOnComplete
+		dst.TagOnCompleteSet = true
+	}
+	if src.WriteSummarySet {
+		dst.WriteSummary = src.WriteSummary
+		dst.WriteSummarySet = true
+	}
+	if src.ReportTestResultsSet {
+		dst.ReportTestResults = src.ReportTestResults
+		dst.ReportTestResultsSet = true
+	}
+	if src.SuppressDeprecationWarningsSet {
+		dst.SuppressDeprecationWarnings = src.SuppressDeprecationWarnings
+		dst.SuppressDeprecationWarningsSet = true
+	}
+	if src.ReviewPerTaskSet {
+		dst.ReviewPerTask = src.ReviewPerTask
+		dst.ReviewPerTaskSet = true
+	}
+	if src.SkipFinalReviewSet {
+		dst.SkipFinalReview = src.SkipFinalReview
+		dst.SkipFinalReviewSet = true
+	}
+	if src.ReviewWorkingTreeSet {
+		dst.ReviewWorkingTree = src.ReviewWorkingTree
+		dst.ReviewWorkingTreeSet = true
+	}
+	if src.ReviewIncludePlanSet {
+		dst.ReviewIncludePlan = src.ReviewIncludePlan
+		dst.ReviewIncludePlanSet = true
+	}
+	if src.AutoUnshallowSet {
+		dst.AutoUnshallow = src.AutoUnshallow
+		dst.AutoUnshallowSet = true
+	}
+	if src.AllowExternalPlansSet {
+		dst.AllowExternalPlans = src.AllowExternalPlans
+		dst.AllowExternalPlansSet = true
+	}
+	if src.TerminalTitleSet {
+		dst.TerminalTitle = src.TerminalTitle
+		dst.TerminalTitleSet = true
+	}
+	if src.SplitProgressByTaskSet {
+		dst.SplitProgressByTask = src.SplitProgressByTask
+		dst.SplitProgressByTaskSet = true
+	}
 	if src.WorktreeEnabledSet {
 		dst.WorktreeEnabled = src.WorktreeEnabled
 		dst.WorktreeEnabledSet = true
 	}
+	if src.KeepWorktreeOnFailureSet {
+		dst.KeepWorktreeOnFailure = src.KeepWorktreeOnFailure
+		dst.KeepWorktreeOnFailureSet = true
+	}
 	if src.PlansDir != "" {
 		dst.PlansDir = src.PlansDir
 	}
 	if src.DefaultBranch != "" {
 		dst.DefaultBranch = src.DefaultBranch
 	}
+	if src.PushRemote != "" {
+		dst.PushRemote = src.PushRemote
+	}
+	if src.PushRefspecTemplate != "" {
+		dst.PushRefspecTemplate = src.PushRefspecTemplate
+	}
+	if src.ProgressBranch != "" {
+		dst.ProgressBranch = src.ProgressBranch
+	}
+	if src.BranchNameTemplate != "" {
+		dst.BranchNameTemplate = src.BranchNameTemplate
+	}
+	if src.BranchStripPattern != "" {
+		dst.BranchStripPattern = src.BranchStripPattern
+	}
+	if src.CommitTrailer != "" {
+		dst.CommitTrailer = src.CommitTrailer
+	}
+	if src.InProgressMarker != "" {
+		dst.InProgressMarker = src.InProgressMarker
+	}
+	if src.DefaultMode != "" {
+		dst.DefaultMode = src.DefaultMode
+	}
+	if src.TaskOrder != "" {
+		dst.TaskOrder = src.TaskOrder
+	}
+	if src.TestCommand != "" {
+		dst.TestCommand = src.TestCommand
+	}
 	if src.VcsCommand != "" {
 		dst.VcsCommand = src.VcsCommand
 	}
+	if src.FzfCommand != "" {
+		dst.FzfCommand = src.FzfCommand
+	}
+	if src.FzfArgs != "" {
+		dst.FzfArgs = src.FzfArgs
+	}
 	if len(src.WatchDirs) > 0 {
 		dst.WatchDirs = src.WatchDirs
 	}
+	if len(src.PhaseNames) > 0 {
+		dst.PhaseNames = src.PhaseNames
+	}
+	if len(src.AgentModes) > 0 {
+		dst.AgentModes = src.AgentModes
+	}
+	if len(src.CodexReviewers) > 0 {
+		dst.CodexReviewers = src.CodexReviewers
+	}
+	dst.CodexReviewerConditions = mergeReviewerConditions(dst.CodexReviewerConditions, src.CodexReviewerConditions)
+	if len(src.ReviewFocus) > 0 {
+		dst.ReviewFocus = src.ReviewFocus
+	}
+	if len(src.ReviewFirstAgents) > 0 {
+		dst.ReviewFirstAgents = src.ReviewFirstAgents
+	}
+	if len(src.ReviewSecondAgents) > 0 {
+		dst.ReviewSecondAgents = src.ReviewSecondAgents
+	}
+	if len(src.DisabledReviewAgents) > 0 {
+		dst.DisabledReviewAgents = src.DisabledReviewAgents
+	}
+	if len(src.HookFailure) > 0 {
+		dst.HookFailure = src.HookFailure
+	}
+	if len(src.EstimateTokens) > 0 {
+		dst.EstimateTokens = src.EstimateTokens
+	}
+	if len(src.EstimateMinutes) > 0 {
+		dst.EstimateMinutes = src.EstimateMinutes
+	}
 	if len(src.ClaudeErrorPatterns) > 0 {
 		dst.ClaudeErrorPatterns = src.ClaudeErrorPatterns
 	}
@@ -476,10 +1286,25 @@ func (dst *Values) mergeExtraFrom(src *Values) {
 		dst.WaitOnLimit = src.WaitOnLimit
 		dst.WaitOnLimitSet = true
 	}
+	if src.MaxLimitRetries > 0 {
+		dst.MaxLimitRetries = src.MaxLimitRetries
+	}
 	if src.SessionTimeoutSet {
 		dst.SessionTimeout = src.SessionTimeout
 		dst.SessionTimeoutSet = true
 	}
+	if src.ClaudeIdleTimeoutSet {
+		dst.ClaudeIdleTimeout = src.ClaudeIdleTimeout
+		dst.ClaudeIdleTimeoutSet = true
+	}
+	if src.DashboardBatchSet {
+		dst.DashboardBatchInterval = src.DashboardBatchInterval
+		dst.DashboardBatchSet = true
+	}
+	if src.HeartbeatIntervalSet {
+		dst.HeartbeatInterval = src.HeartbeatInterval
+		dst.HeartbeatIntervalSet = true
+	}
 }
 
 // mergeNotifyFrom merges notification-related fields from src into dst.
@@ -676,6 +1501,287 @@ func (vl *valuesLoader) parseCommaSeparated(section *ini.Section, key string) []
 	return result
 }
 
+// parsePhaseNames reads phase_names as comma-separated phase:label pairs, e.g. "task:Implementation, review:Code Review".
+// returns an error for malformed pairs and for keys that are not known phase names.
+func (vl *valuesLoader) parsePhaseNames(section *ini.Section) (status.PhaseNames, error) {
+	pairs := vl.parseCommaSeparated(section, "phase_names")
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	names := make(status.PhaseNames, len(pairs))
+	for _, pair := range pairs {
+		phase, label, ok := strings.Cut(pair, ":")
+		phase, label = strings.TrimSpace(phase), strings.TrimSpace(label)
+		if !ok || phase == "" || label == "" {
+			return nil, fmt.Errorf("invalid phase_names entry %q, expected phase:label", pair)
+		}
+		if !status.Phase(phase).IsKnown() {
+			return nil, fmt.Errorf("invalid phase_names: unknown phase %q", phase)
+		}
+		names[status.Phase(phase)] = label
+	}
+	return names, nil
+}
+
+// parseAgentModes reads agent_modes as comma-separated agent:mode pairs, e.g. "testing:full, testing:tasks-only".
+// an agent may be listed several times to allow it in several modes.
+// returns an error for malformed pairs and unknown modes; agent names are checked once agents are loaded.
+func (vl *valuesLoader) parseAgentModes(section *ini.Section) (AgentModes, error) {
+	pairs := vl.parseCommaSeparated(section, "agent_modes")
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	modes := make(AgentModes, len(pairs))
+	for _, pair := range pairs {
+		agent, mode, ok := strings.Cut(pair, ":")
+		agent, mode = strings.TrimSpace(agent), normalizeMode(strings.TrimSpace(mode))
+		if !ok || agent == "" || mode == "" {
+			return nil, fmt.Errorf("invalid agent_modes entry %q, expected agent:mode", pair)
+		}
+		if !slices.Contains(defaultModes, mode) {
+			return nil, fmt.Errorf("invalid agent_modes: unknown mode %q, expected one of: %s", mode, strings.Join(defaultModes, ", "))
+		}
+		if !slices.Contains(modes[agent], mode) {
+			modes[agent] = append(modes[agent], mode)
+		}
+	}
+	return modes, nil
+}
+
+// parseCodexReviewers reads codex_reviewers as comma-separated name or name:project_doc entries,
+// e.g. "broad, security:docs/security-review.md". names are used in prompt file names,
+// so they are limited to lowercase letters, digits, dash and underscore.
+func (vl *valuesLoader) parseCodexReviewers(section *ini.Section) ([]CodexReviewer, error) {
+	entries := vl.parseCommaSeparated(section, "codex_reviewers")
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	result := make([]CodexReviewer, 0, len(entries))
+	for _, entry := range entries {
+		name, doc, _ := strings.Cut(entry, ":")
+		name, doc = strings.TrimSpace(name), strings.TrimSpace(doc)
+		if !reviewerNameRe.MatchString(name) {
+			return nil, fmt.Errorf("invalid codex_reviewers entry %q, expected name or name:project_doc "+
+				"with a name of lowercase letters, digits, - or _", entry)
+		}
+		if slices.ContainsFunc(result, func(r CodexReviewer) bool { return r.Name == name }) {
+			return nil, fmt.Errorf("invalid codex_reviewers: duplicate reviewer %q", name)
+		}
+		result = append(result, CodexReviewer{Name: name, ProjectDoc: doc})
+	}
+	return result, nil
+}
+
+// parseReviewerConditions reads codex_reviewer_modes, codex_reviewer_min_diff_lines and codex_reviewer_paths
+// as comma-separated reviewer:value pairs, e.g. "security:auth/**/*.go, security:*.sql". a reviewer may be
+// listed several times in modes and paths. reviewer names are checked against codex_reviewers later.
+func (vl *valuesLoader) parseReviewerConditions(section *ini.Section) (map[string]ReviewerConditions, error) {
+	res := map[string]ReviewerConditions{}
+	parse := func(key string, apply func(c *ReviewerConditions, value string) error) error {
+		for _, pair := range vl.parseCommaSeparated(section, key) {
+			name, value, ok := strings.Cut(pair, ":")
+			name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+			if !ok || !reviewerNameRe.MatchString(name) || value == "" {
+				return fmt.Errorf("invalid %s entry %q, expected reviewer:value", key, pair)
+			}
+			c := res[name]
+			if err := apply(&c, value); err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			res[name] = c
+		}
+		return nil
+	}
+
+	err := parse("codex_reviewer_modes", func(c *ReviewerConditions, value string) error {
+		mode := normalizeMode(value)
+		if !slices.Contains(defaultModes, mode) {
+			return fmt.Errorf("unknown mode %q, expected one of: %s", value, strings.Join(defaultModes, ", "))
+		}
+		if !slices.Contains(c.Modes, mode) {
+			c.Modes = append(c.Modes, mode)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = parse("codex_reviewer_min_diff_lines", func(c *ReviewerConditions, value string) error {
+		n, convErr := strconv.Atoi(value)
+		if convErr != nil || n < 0 {
+			return fmt.Errorf("must be a non-negative number, got %q", value)
+		}
+		c.MinDiffLines = n
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = parse("codex_reviewer_paths", func(c *ReviewerConditions, value string) error {
+		if _, matchErr := path.Match(value, ""); matchErr != nil {
+			return fmt.Errorf("bad pattern %q: %w", value, matchErr)
+		}
+		c.Paths = append(c.Paths, value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, nil
+	}
+	return res, nil
+}
+
+// validateCommitTrailer checks that commit_trailer is a single "Key: value" git trailer whose only
+// placeholder is {{VERSION}}. an empty trailer is valid and disables it.
+func validateCommitTrailer(trailer string) error {
+	if trailer == "" {
+		return nil
+	}
+	for _, ph := range commitTrailerPlaceholderRe.FindAllString(trailer, -1) {
+		if ph != "{{VERSION}}" {
+			return fmt.Errorf("invalid commit_trailer %q: unknown placeholder %s, only {{VERSION}} is supported", trailer, ph)
+		}
+	}
+	if !commitTrailerRe.MatchString(trailer) {
+		return fmt.Errorf("invalid commit_trailer %q: expected a single \"Key: value\" line, "+
+			"e.g. \"Generated-by: ralphex {{VERSION}}\"", trailer)
+	}
+	return nil
+}
+
+// parseReviewFocus reads review_focus as comma-separated pattern:name pairs, e.g. "migrations/**:data-safety, *.md:docs".
+// the name selects the prompts/review_focus_<name>.txt snippet, so it follows the codex reviewer name rules.
+// a pattern may be listed with several names and a name with several patterns.
+func (vl *valuesLoader) parseReviewFocus(section *ini.Section) ([]ReviewFocus, error) {
+	pairs := vl.parseCommaSeparated(section, "review_focus")
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	result := make([]ReviewFocus, 0, len(pairs))
+	for _, pair := range pairs {
+		idx := strings.LastIndex(pair, ":")
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid review_focus entry %q, expected pattern:name", pair)
+		}
+		pattern, name := strings.TrimSpace(pair[:idx]), strings.TrimSpace(pair[idx+1:])
+		if pattern == "" || !reviewerNameRe.MatchString(name) {
+			return nil, fmt.Errorf("invalid review_focus entry %q, expected pattern:name "+
+				"with a name of lowercase letters, digits, - or _", pair)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid review_focus: bad pattern %q: %w", pattern, err)
+		}
+		result = append(result, ReviewFocus{Pattern: pattern, Name: name})
+	}
+	return result, nil
+}
+
+// parseHookFailure reads hook_failure as comma-separated hook:mode pairs, e.g. "pre-task:warn, post-review:fatal".
+// returns an error for malformed pairs, unknown hook names and modes other than fatal or warn.
+func (vl *valuesLoader) parseHookFailure(section *ini.Section) (map[hooks.Point]hooks.Failure, error) {
+	pairs := vl.parseCommaSeparated(section, "hook_failure")
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	result := make(map[hooks.Point]hooks.Failure, len(pairs))
+	for _, pair := range pairs {
+		hook, mode, ok := strings.Cut(pair, ":")
+		hook, mode = strings.TrimSpace(hook), strings.TrimSpace(mode)
+		if !ok || hook == "" || mode == "" {
+			return nil, fmt.Errorf("invalid hook_failure entry %q, expected hook:mode", pair)
+		}
+		if !hooks.Point(hook).IsKnown() {
+			return nil, fmt.Errorf("invalid hook_failure: unknown hook %q", hook)
+		}
+		switch f := hooks.Failure(mode); f {
+		case hooks.FailureFatal, hooks.FailureWarn:
+			result[hooks.Point(hook)] = f
+		default:
+			return nil, fmt.Errorf("invalid hook_failure: unknown mode %q, expected fatal or warn", mode)
+		}
+	}
+	return result, nil
+}
+
+// EstimatePhases are the phases estimate_tokens and estimate_minutes set averages for.
+var EstimatePhases = []string{"task", "review", "external", "finalize"}
+
+// parseEstimateAverages reads estimate_tokens or estimate_minutes as comma-separated phase:number pairs,
+// e.g. "task:80000, review:120000". returns an error for malformed pairs, unknown phases and negative numbers.
+func (vl *valuesLoader) parseEstimateAverages(section *ini.Section, key string) (map[string]int, error) {
+	pairs := vl.parseCommaSeparated(section, key)
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]int, len(pairs))
+	for _, pair := range pairs {
+		phase, num, ok := strings.Cut(pair, ":")
+		phase, num = strings.TrimSpace(phase), strings.TrimSpace(num)
+		if !ok || phase == "" || num == "" {
+			return nil, fmt.Errorf("invalid %s entry %q, expected phase:number", key, pair)
+		}
+		if !slices.Contains(EstimatePhases, phase) {
+			return nil, fmt.Errorf("invalid %s: unknown phase %q, expected one of %s", key, phase, strings.Join(EstimatePhases, ", "))
+		}
+		val, err := strconv.Atoi(num)
+		if err != nil || val < 0 {
+			return nil, fmt.Errorf("invalid %s: %q is not a non-negative number", key, num)
+		}
+		result[phase] = val
+	}
+	return result, nil
+}
+
+// interpolateEnv expands environment variable references in every value of the section.
+// returns an error naming the key and variable when a referenced variable is unset and has no default.
+func interpolateEnv(section *ini.Section) error {
+	for _, key := range section.Keys() {
+		val, err := expandEnvRefs(key.Value())
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", key.Name(), err)
+		}
+		key.SetValue(val)
+	}
+	return nil
+}
+
+// expandEnvRefs replaces ${VAR} and ${VAR:-default} with values from the process environment.
+// the default is used when VAR is unset or empty; ${VAR} without a default fails if VAR is unset.
+// $${ is an escape for a literal ${. a bare $ is left as is, so shell snippets like $HOME keep working.
+func expandEnvRefs(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var missing string
+	result := envRefRe.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+		m := envRefRe.FindStringSubmatch(match)
+		name, hasDefault, def := m[1], m[2] != "", m[3]
+		val, ok := os.LookupEnv(name)
+		switch {
+		case hasDefault && val == "":
+			return def
+		case !ok:
+			if missing == "" {
+				missing = name
+			}
+			return match
+		}
+		return val
+	})
+	if missing != "" {
+		return "", fmt.Errorf("environment variable %q is not set", missing)
+	}
+	return result, nil
+}
+
 // expandTilde expands a leading ~ in a path to the user's home directory.
 // returns the original path if it doesn't start with ~/ or if home dir is unavailable.
 func expandTilde(path string) string {
@@ -9,6 +9,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/ini.v1"
+
+	"github.com/umputun/ralphex/pkg/hooks"
+	"github.com/umputun/ralphex/pkg/status"
 )
 
 func Test_newValuesLoader(t *testing.T) {
@@ -51,6 +54,7 @@ func TestValuesLoader_Load_EmbeddedOnly(t *testing.T) {
 	// all values should come from embedded defaults
 	assert.Equal(t, "claude", values.ClaudeCommand)
 	assert.Equal(t, "--dangerously-skip-permissions --output-format stream-json --verbose", values.ClaudeArgs)
+	assert.Empty(t, values.PlanModel)
 	assert.True(t, values.CodexEnabled)
 	assert.True(t, values.CodexEnabledSet)
 	assert.Equal(t, "codex", values.CodexCommand)
@@ -165,6 +169,8 @@ func TestValuesLoader_Load_InvalidConfig(t *testing.T) {
 		{name: "invalid codex_enabled", config: "codex_enabled = maybe", errPart: "codex_enabled"},
 		{name: "invalid finalize_enabled", config: "finalize_enabled = maybe", errPart: "finalize_enabled"},
 		{name: "negative task_retry_count", config: "task_retry_count = -1", errPart: "task_retry_count"},
+		{name: "negative review_retry_count", config: "review_retry_count = -1", errPart: "review_retry_count"},
+		{name: "invalid review_retry_count", config: "review_retry_count = abc", errPart: "review_retry_count"},
 		{name: "negative codex_timeout_ms", config: "codex_timeout_ms = -100", errPart: "codex_timeout_ms"},
 		{name: "negative iteration_delay_ms", config: "iteration_delay_ms = -50", errPart: "iteration_delay_ms"},
 		{name: "invalid max_iterations", config: "max_iterations = abc", errPart: "max_iterations"},
@@ -172,8 +178,21 @@ func TestValuesLoader_Load_InvalidConfig(t *testing.T) {
 		{name: "negative max_iterations", config: "max_iterations = -5", errPart: "max_iterations"},
 		{name: "negative max_external_iterations", config: "max_external_iterations = -1", errPart: "max_external_iterations"},
 		{name: "invalid max_external_iterations", config: "max_external_iterations = abc", errPart: "max_external_iterations"},
+		{name: "negative max_external_rounds", config: "max_external_rounds = -1", errPart: "max_external_rounds"},
+		{name: "invalid max_external_rounds", config: "max_external_rounds = abc", errPart: "max_external_rounds"},
+		{name: "invalid allow_external_plans", config: "allow_external_plans = maybe", errPart: "allow_external_plans"},
 		{name: "negative review_patience", config: "review_patience = -1", errPart: "review_patience"},
 		{name: "invalid review_patience", config: "review_patience = abc", errPart: "review_patience"},
+		{name: "unknown default_mode", config: "default_mode = plan", errPart: "default_mode"},
+		{name: "unknown task_order", config: "task_order = random", errPart: "task_order"},
+		{name: "invalid profile value", config: "[profile fast]\nmax_iterations = abc", errPart: "profile fast: invalid max_iterations"},
+		{name: "profile without name", config: "[profile]\nmax_iterations = 5", errPart: "missing profile name"},
+		{name: "branch_name_template without slug", config: "branch_name_template = feature/x", errPart: "branch_name_template"},
+		{name: "invalid branch_strip_pattern", config: "branch_strip_pattern = ^([0-9", errPart: "branch_strip_pattern"},
+		{name: "negative max_limit_retries", config: "max_limit_retries = -1", errPart: "max_limit_retries"},
+		{name: "invalid max_limit_retries", config: "max_limit_retries = x", errPart: "max_limit_retries"},
+		{name: "negative codex_min_diff_lines", config: "codex_min_diff_lines = -1", errPart: "codex_min_diff_lines"},
+		{name: "invalid codex_min_diff_lines", config: "codex_min_diff_lines = abc", errPart: "codex_min_diff_lines"},
 		{name: "invalid wait_on_limit", config: "wait_on_limit = not-a-duration", errPart: "wait_on_limit"},
 		{name: "negative wait_on_limit", config: "wait_on_limit = -30m", errPart: "wait_on_limit"},
 	}
@@ -261,57 +280,868 @@ func TestValuesLoader_Load_ExplicitZeroIterationDelayMs(t *testing.T) {
 	values, err := loader.Load("", configPath)
 	require.NoError(t, err)
 
-	// explicit zero should be preserved (not overwritten by embedded default)
-	assert.Equal(t, 0, values.IterationDelayMs)
-	assert.True(t, values.IterationDelayMsSet)
+	// explicit zero should be preserved (not overwritten by embedded default)
+	assert.Equal(t, 0, values.IterationDelayMs)
+	assert.True(t, values.IterationDelayMsSet)
+}
+
+func TestValuesLoader_Load_LocalOverridesCodexEnabled(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte(`codex_enabled = true`), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte(`codex_enabled = false`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+
+	assert.False(t, values.CodexEnabled)
+	assert.True(t, values.CodexEnabledSet)
+}
+
+func TestValuesLoader_Load_LocalOverridesTaskRetryCount(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte(`task_retry_count = 5`), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte(`task_retry_count = 0`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, values.TaskRetryCount)
+	assert.True(t, values.TaskRetryCountSet)
+}
+
+func TestValuesLoader_Load_LocalOverridesFinalizeEnabled(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte(`finalize_enabled = false`), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte(`finalize_enabled = true`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+
+	assert.True(t, values.FinalizeEnabled)
+	assert.True(t, values.FinalizeEnabledSet)
+}
+
+func TestValuesLoader_Load_FinalizeNoCommit(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`finalize_no_commit = true`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(cfgPath, "")
+	require.NoError(t, err)
+	assert.True(t, values.FinalizeNoCommit)
+	assert.True(t, values.FinalizeNoCommitSet)
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.False(t, values.FinalizeNoCommit)
+	assert.False(t, values.FinalizeNoCommitSet)
+}
+
+func TestValuesLoader_Load_StripOutputANSI(t *testing.T) {
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", "")
+	require.NoError(t, err)
+	assert.True(t, values.StripOutputANSI, "embedded default strips escape sequences")
+	assert.True(t, values.StripOutputANSISet)
+
+	cfgPath := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`strip_output_ansi = false`), 0o600))
+	values, err = loader.Load(cfgPath, "")
+	require.NoError(t, err)
+	assert.False(t, values.StripOutputANSI)
+	assert.True(t, values.StripOutputANSISet)
+}
+
+func TestValuesLoader_Load_ClaudeOutputFilter(t *testing.T) {
+	t.Run("embedded default enables filter", func(t *testing.T) {
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", "")
+		require.NoError(t, err)
+		assert.True(t, values.ClaudeOutputFilter)
+		assert.True(t, values.ClaudeOutputFilterSet)
+	})
+
+	t.Run("user config disables filter", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`claude_output_filter = false`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", cfgPath)
+		require.NoError(t, err)
+		assert.False(t, values.ClaudeOutputFilter)
+		assert.True(t, values.ClaudeOutputFilterSet)
+	})
+
+	t.Run("invalid value returns error", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`claude_output_filter = sometimes`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		_, err := loader.Load("", cfgPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid claude_output_filter")
+	})
+}
+
+func TestValuesLoader_Load_PhaseNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    status.PhaseNames
+		wantErr string
+	}{
+		{name: "not set", content: "", want: nil},
+		{
+			name:    "parses pairs",
+			content: "phase_names = task:Implementation, review : Code Review,claude-eval:Triage",
+			want: status.PhaseNames{
+				status.PhaseTask:       "Implementation",
+				status.PhaseReview:     "Code Review",
+				status.PhaseClaudeEval: "Triage",
+			},
+		},
+		{name: "label with colon", content: "phase_names = codex:Review: external", want: status.PhaseNames{status.PhaseCodex: "Review: external"}},
+		{name: "unknown phase", content: "phase_names = tasks:Implementation", wantErr: `unknown phase "tasks"`},
+		{name: "missing label", content: "phase_names = task:", wantErr: "expected phase:label"},
+		{name: "missing separator", content: "phase_names = Implementation", wantErr: "expected phase:label"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(cfgPath, []byte(tc.content), 0o600))
+
+			loader := newValuesLoader(defaultsFS)
+			values, err := loader.Load("", cfgPath)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, values.PhaseNames)
+		})
+	}
+
+	t.Run("local overrides global", func(t *testing.T) {
+		dir := t.TempDir()
+		globalPath := filepath.Join(dir, "global")
+		localPath := filepath.Join(dir, "local")
+		require.NoError(t, os.WriteFile(globalPath, []byte("phase_names = task:Build, review:Check"), 0o600))
+		require.NoError(t, os.WriteFile(localPath, []byte("phase_names = codex:Audit"), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load(localPath, globalPath)
+		require.NoError(t, err)
+		assert.Equal(t, status.PhaseNames{status.PhaseCodex: "Audit"}, values.PhaseNames)
+	})
+}
+
+func TestValuesLoader_Load_HookFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[hooks.Point]hooks.Failure
+		wantErr string
+	}{
+		{name: "not set", content: "", want: nil},
+		{
+			name:    "parses pairs",
+			content: "hook_failure = pre-review:warn, post-task : fatal",
+			want:    map[hooks.Point]hooks.Failure{hooks.PreReview: hooks.FailureWarn, hooks.PostTask: hooks.FailureFatal},
+		},
+		{name: "unknown hook", content: "hook_failure = pre-commit:warn", wantErr: `unknown hook "pre-commit"`},
+		{name: "unknown mode", content: "hook_failure = pre-task:ignore", wantErr: `unknown mode "ignore"`},
+		{name: "missing separator", content: "hook_failure = pre-task", wantErr: "expected hook:mode"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(cfgPath, []byte(tc.content), 0o600))
+
+			loader := newValuesLoader(defaultsFS)
+			values, err := loader.Load("", cfgPath)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, values.HookFailure)
+		})
+	}
+}
+
+func TestValuesLoader_Load_EstimateAverages(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantTokens  map[string]int
+		wantMinutes map[string]int
+		wantErr     string
+	}{
+		{name: "not set", content: ""},
+		{
+			name:        "parses pairs",
+			content:     "estimate_tokens = task:60000, review : 150000\nestimate_minutes = external:12, finalize:0",
+			wantTokens:  map[string]int{"task": 60000, "review": 150000},
+			wantMinutes: map[string]int{"external": 12, "finalize": 0},
+		},
+		{name: "unknown phase", content: "estimate_tokens = plan:1000", wantErr: `invalid estimate_tokens: unknown phase "plan"`},
+		{name: "negative number", content: "estimate_minutes = task:-5", wantErr: `invalid estimate_minutes: "-5"`},
+		{name: "not a number", content: "estimate_minutes = task:10m", wantErr: `invalid estimate_minutes: "10m"`},
+		{name: "missing separator", content: "estimate_tokens = task", wantErr: "expected phase:number"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(cfgPath, []byte(tc.content), 0o600))
+
+			loader := newValuesLoader(defaultsFS)
+			values, err := loader.Load("", cfgPath)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantTokens, values.EstimateTokens)
+			assert.Equal(t, tc.wantMinutes, values.EstimateMinutes)
+		})
+	}
+}
+
+func TestValuesLoader_Load_CodexReviewers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []CodexReviewer
+		wantErr string
+	}{
+		{name: "not set", content: "", want: nil},
+		{
+			name:    "names and docs",
+			content: "codex_reviewers = broad, security : docs/security-review.md,perf_2",
+			want: []CodexReviewer{{Name: "broad"}, {Name: "security", ProjectDoc: "docs/security-review.md"},
+				{Name: "perf_2"}},
+		},
+		{name: "invalid name", content: "codex_reviewers = Security", wantErr: `invalid codex_reviewers entry "Security"`},
+		{name: "path in name", content: "codex_reviewers = ../x:doc.md", wantErr: "invalid codex_reviewers entry"},
+		{name: "empty name", content: "codex_reviewers = :doc.md", wantErr: "invalid codex_reviewers entry"},
+		{name: "duplicate", content: "codex_reviewers = broad, broad:doc.md", wantErr: `duplicate reviewer "broad"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(cfgPath, []byte(tc.content), 0o600))
+
+			loader := newValuesLoader(defaultsFS)
+			values, err := loader.Load("", cfgPath)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, values.CodexReviewers)
+		})
+	}
+}
+
+func TestValuesLoader_Load_CodexReviewerConditions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]ReviewerConditions
+		wantErr string
+	}{
+		{name: "not set", content: "", want: nil},
+		{
+			name: "all conditions",
+			content: "codex_reviewer_modes = security:full, security:codex-only, perf:review\n" +
+				"codex_reviewer_min_diff_lines = perf:200\n" +
+				"codex_reviewer_paths = security:auth/**/*.go, security:*.sql\n",
+			want: map[string]ReviewerConditions{
+				"security": {Modes: []string{"full", "external-only"}, Paths: []string{"auth/**/*.go", "*.sql"}},
+				"perf":     {Modes: []string{"review"}, MinDiffLines: 200},
+			},
+		},
+		{name: "missing value", content: "codex_reviewer_paths = security", wantErr: `invalid codex_reviewer_paths entry "security"`},
+		{name: "invalid name", content: "codex_reviewer_modes = Sec:full", wantErr: "invalid codex_reviewer_modes entry"},
+		{name: "unknown mode", content: "codex_reviewer_modes = security:plan", wantErr: `unknown mode "plan"`},
+		{name: "negative lines", content: "codex_reviewer_min_diff_lines = perf:-1", wantErr: "must be a non-negative number"},
+		{name: "bad pattern", content: "codex_reviewer_paths = security:auth/[", wantErr: `bad pattern "auth/["`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(cfgPath, []byte(tc.content), 0o600))
+
+			loader := newValuesLoader(defaultsFS)
+			values, err := loader.Load("", cfgPath)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, values.CodexReviewerConditions)
+		})
+	}
+}
+
+func TestValuesLoader_Load_ReviewFocus(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []ReviewFocus
+		wantErr string
+	}{
+		{name: "not set", content: "", want: nil},
+		{
+			name:    "pairs",
+			content: "review_focus = migrations/**:data-safety, *.md:docs, *.sql:data-safety",
+			want: []ReviewFocus{
+				{Pattern: "migrations/**", Name: "data-safety"},
+				{Pattern: "*.md", Name: "docs"},
+				{Pattern: "*.sql", Name: "data-safety"},
+			},
+		},
+		{name: "missing name", content: "review_focus = *.md", wantErr: `invalid review_focus entry "*.md"`},
+		{name: "empty pattern", content: "review_focus = :docs", wantErr: `invalid review_focus entry ":docs"`},
+		{name: "invalid name", content: "review_focus = *.md:Docs", wantErr: `invalid review_focus entry "*.md:Docs"`},
+		{name: "bad pattern", content: "review_focus = db/[:data", wantErr: `bad pattern "db/["`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(cfgPath, []byte(tc.content), 0o600))
+
+			loader := newValuesLoader(defaultsFS)
+			values, err := loader.Load("", cfgPath)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, values.ReviewFocus)
+		})
+	}
+}
+
+func TestValuesLoader_Load_CommitTrailer(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr string
+	}{
+		{name: "not set", content: "", want: ""},
+		{name: "with version", content: "commit_trailer = Generated-by: ralphex {{VERSION}}", want: "Generated-by: ralphex {{VERSION}}"},
+		{name: "plain value", content: "commit_trailer = Assisted-by: ralphex", want: "Assisted-by: ralphex"},
+		{name: "unknown placeholder", content: "commit_trailer = Generated-by: {{BRANCH}}", wantErr: "unknown placeholder {{BRANCH}}"},
+		{name: "no key", content: "commit_trailer = generated by ralphex", wantErr: `expected a single "Key: value" line`},
+		{name: "space in key", content: "commit_trailer = Generated by: ralphex", wantErr: `expected a single "Key: value" line`},
+		{name: "empty value", content: "commit_trailer = Generated-by:", wantErr: `expected a single "Key: value" line`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(cfgPath, []byte(tc.content), 0o600))
+
+			loader := newValuesLoader(defaultsFS)
+			values, err := loader.Load("", cfgPath)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, values.CommitTrailer)
+		})
+	}
+}
+
+func TestValuesLoader_Load_AgentModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    AgentModes
+		wantErr string
+	}{
+		{name: "not set", content: "", want: nil},
+		{
+			name:    "parses pairs",
+			content: "agent_modes = testing:full, testing : tasks-only,documentation:full, testing:full",
+			want:    AgentModes{"testing": {"full", "tasks-only"}, "documentation": {"full"}},
+		},
+		{
+			name:    "normalizes codex-only",
+			content: "agent_modes = testing:codex-only, testing:external-only",
+			want:    AgentModes{"testing": {"external-only"}},
+		},
+		{name: "unknown mode", content: "agent_modes = testing:plan", wantErr: `unknown mode "plan"`},
+		{name: "missing mode", content: "agent_modes = testing:", wantErr: "expected agent:mode"},
+		{name: "missing separator", content: "agent_modes = testing", wantErr: "expected agent:mode"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(cfgPath, []byte(tc.content), 0o600))
+
+			loader := newValuesLoader(defaultsFS)
+			values, err := loader.Load("", cfgPath)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, values.AgentModes)
+		})
+	}
+
+	t.Run("local overrides global", func(t *testing.T) {
+		dir := t.TempDir()
+		globalPath := filepath.Join(dir, "global")
+		localPath := filepath.Join(dir, "local")
+		require.NoError(t, os.WriteFile(globalPath, []byte("agent_modes = testing:full"), 0o600))
+		require.NoError(t, os.WriteFile(localPath, []byte("agent_modes = documentation:review"), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load(localPath, globalPath)
+		require.NoError(t, err)
+		assert.Equal(t, AgentModes{"documentation": {"review"}}, values.AgentModes)
+	})
+}
+
+func TestExpandEnvRefs(t *testing.T) {
+	t.Setenv("RALPHEX_TEST_URL", "https://hooks.example.com/abc")
+	t.Setenv("RALPHEX_TEST_EMPTY", "")
+
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr string
+	}{
+		{name: "no references", in: "plain value", want: "plain value"},
+		{name: "set variable", in: "${RALPHEX_TEST_URL}/path", want: "https://hooks.example.com/abc/path"},
+		{name: "default for unset", in: "${RALPHEX_TEST_UNSET:-fallback}", want: "fallback"},
+		{name: "default for empty", in: "${RALPHEX_TEST_EMPTY:-fallback}", want: "fallback"},
+		{name: "empty default", in: "a${RALPHEX_TEST_UNSET:-}b", want: "ab"},
+		{name: "set variable ignores default", in: "${RALPHEX_TEST_URL:-x}", want: "https://hooks.example.com/abc"},
+		{name: "set but empty without default", in: "[${RALPHEX_TEST_EMPTY}]", want: "[]"},
+		{name: "escaped reference", in: "$${RALPHEX_TEST_URL}", want: "${RALPHEX_TEST_URL}"},
+		{name: "bare dollar untouched", in: "echo $HOME $$", want: "echo $HOME $$"},
+		{name: "unset without default", in: "x ${RALPHEX_TEST_UNSET} ${RALPHEX_TEST_OTHER}", wantErr: `"RALPHEX_TEST_UNSET" is not set`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := expandEnvRefs(tc.in)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestValuesLoader_Load_EnvInterpolation(t *testing.T) {
+	t.Setenv("RALPHEX_TEST_TOKEN", "secret-token")
+	t.Setenv("RALPHEX_TEST_RETRIES", "3")
+
+	t.Run("expands string and numeric values", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		content := "notify_slack_token = ${RALPHEX_TEST_TOKEN}\n" +
+			"task_retry_count = ${RALPHEX_TEST_RETRIES}\n" +
+			"notify_slack_channel = ${RALPHEX_TEST_CHANNEL:-general}\n"
+		require.NoError(t, os.WriteFile(cfgPath, []byte(content), 0o600))
+
+		values, err := newValuesLoader(defaultsFS).Load("", cfgPath)
+		require.NoError(t, err)
+		assert.Equal(t, "secret-token", values.NotifySlackToken)
+		assert.Equal(t, 3, values.TaskRetryCount)
+		assert.Equal(t, "general", values.NotifySlackChannel)
+	})
+
+	t.Run("missing variable names key and variable", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte("notify_telegram_token = ${RALPHEX_TEST_MISSING}\n"), 0o600))
+
+		_, err := newValuesLoader(defaultsFS).Load("", cfgPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid notify_telegram_token")
+		assert.Contains(t, err.Error(), `environment variable "RALPHEX_TEST_MISSING" is not set`)
+	})
+}
+
+func TestValuesLoader_Load_IncludeCommitLog(t *testing.T) {
+	t.Run("parse include_commit_log true", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`include_commit_log = true`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", cfgPath)
+		require.NoError(t, err)
+		assert.True(t, values.IncludeCommitLog)
+		assert.True(t, values.IncludeCommitLogSet)
+	})
+
+	t.Run("not set uses default false", func(t *testing.T) {
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", "")
+		require.NoError(t, err)
+		assert.False(t, values.IncludeCommitLog)
+		assert.False(t, values.IncludeCommitLogSet)
+	})
+
+	t.Run("local overrides global", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		globalCfg := filepath.Join(tmpDir, "global")
+		localCfg := filepath.Join(tmpDir, "local")
+		require.NoError(t, os.WriteFile(globalCfg, []byte(`include_commit_log = true`), 0o600))
+		require.NoError(t, os.WriteFile(localCfg, []byte(`include_commit_log = false`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load(localCfg, globalCfg)
+		require.NoError(t, err)
+		assert.False(t, values.IncludeCommitLog)
+		assert.True(t, values.IncludeCommitLogSet)
+	})
+
+	t.Run("invalid value returns error", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`include_commit_log = maybe`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		_, err := loader.Load("", cfgPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid include_commit_log")
+	})
+}
+
+func TestValuesLoader_Load_KeepWorktreeOnFailure(t *testing.T) {
+	t.Run("parse true", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`keep_worktree_on_failure = true`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", cfgPath)
+		require.NoError(t, err)
+		assert.True(t, values.KeepWorktreeOnFailure)
+		assert.True(t, values.KeepWorktreeOnFailureSet)
+	})
+
+	t.Run("local overrides global", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		globalCfg := filepath.Join(tmpDir, "global")
+		localCfg := filepath.Join(tmpDir, "local")
+		require.NoError(t, os.WriteFile(globalCfg, []byte(`keep_worktree_on_failure = true`), 0o600))
+		require.NoError(t, os.WriteFile(localCfg, []byte(`keep_worktree_on_failure = false`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load(localCfg, globalCfg)
+		require.NoError(t, err)
+		assert.False(t, values.KeepWorktreeOnFailure)
+		assert.True(t, values.KeepWorktreeOnFailureSet)
+	})
+
+	t.Run("invalid value returns error", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`keep_worktree_on_failure = maybe`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		_, err := loader.Load("", cfgPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid keep_worktree_on_failure")
+	})
+}
+
+func TestValuesLoader_Load_TagOnComplete(t *testing.T) {
+	t.Run("parse tag_on_complete true", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`tag_on_complete = true`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", cfgPath)
+		require.NoError(t, err)
+		assert.True(t, values.TagOnComplete)
+		assert.True(t, values.TagOnCompleteSet)
+	})
+
+	t.Run("not set uses default false", func(t *testing.T) {
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", "")
+		require.NoError(t, err)
+		assert.False(t, values.TagOnComplete)
+		assert.False(t, values.TagOnCompleteSet)
+	})
+
+	t.Run("local overrides global", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		globalCfg := filepath.Join(tmpDir, "global")
+		localCfg := filepath.Join(tmpDir, "local")
+		require.NoError(t, os.WriteFile(globalCfg, []byte(`tag_on_complete = true`), 0o600))
+		require.NoError(t, os.WriteFile(localCfg, []byte(`tag_on_complete = false`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load(localCfg, globalCfg)
+		require.NoError(t, err)
+		assert.False(t, values.TagOnComplete)
+		assert.True(t, values.TagOnCompleteSet)
+	})
+
+	t.Run("invalid value returns error", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`tag_on_complete = maybe`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		_, err := loader.Load("", cfgPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid tag_on_complete")
+	})
+}
+
+func TestValuesLoader_Load_EmbedPlanInPrompt(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+		wantSet bool
+		wantErr string
+	}{
+		{name: "not set", content: "", want: false, wantSet: false},
+		{name: "enabled", content: "embed_plan_in_prompt = true", want: true, wantSet: true},
+		{name: "explicitly disabled", content: "embed_plan_in_prompt = false", want: false, wantSet: true},
+		{name: "invalid", content: "embed_plan_in_prompt = maybe", wantErr: "invalid embed_plan_in_prompt"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(cfgPath, []byte(tc.content), 0o600))
+
+			loader := newValuesLoader(defaultsFS)
+			values, err := loader.Load("", cfgPath)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, values.EmbedPlanInPrompt)
+			assert.Equal(t, tc.wantSet, values.EmbedPlanInPromptSet)
+		})
+	}
+}
+
+func TestValuesLoader_Load_TerminalTitle(t *testing.T) {
+	t.Run("parse terminal_title true", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`terminal_title = true`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", cfgPath)
+		require.NoError(t, err)
+		assert.True(t, values.TerminalTitle)
+		assert.True(t, values.TerminalTitleSet)
+	})
+
+	t.Run("not set uses default false", func(t *testing.T) {
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", "")
+		require.NoError(t, err)
+		assert.False(t, values.TerminalTitle)
+		assert.False(t, values.TerminalTitleSet)
+	})
+
+	t.Run("local overrides global", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		globalCfg := filepath.Join(tmpDir, "global")
+		localCfg := filepath.Join(tmpDir, "local")
+		require.NoError(t, os.WriteFile(globalCfg, []byte(`terminal_title = true`), 0o600))
+		require.NoError(t, os.WriteFile(localCfg, []byte(`terminal_title = false`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load(localCfg, globalCfg)
+		require.NoError(t, err)
+		assert.False(t, values.TerminalTitle)
+		assert.True(t, values.TerminalTitleSet)
+	})
+
+	t.Run("invalid value returns error", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`terminal_title = maybe`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		_, err := loader.Load("", cfgPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid terminal_title")
+	})
+}
+
+func TestValuesLoader_Load_DashboardBatchInterval(t *testing.T) {
+	t.Run("embedded default", func(t *testing.T) {
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", "")
+		require.NoError(t, err)
+		assert.Equal(t, 100*time.Millisecond, values.DashboardBatchInterval)
+		assert.True(t, values.DashboardBatchSet)
+	})
+
+	t.Run("zero disables batching", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`dashboard_batch_interval = 0`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", cfgPath)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), values.DashboardBatchInterval)
+	})
+
+	t.Run("invalid values return error", func(t *testing.T) {
+		for _, val := range []string{"fast", "-1s"} {
+			cfgPath := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(cfgPath, []byte("dashboard_batch_interval = "+val), 0o600))
+
+			loader := newValuesLoader(defaultsFS)
+			_, err := loader.Load("", cfgPath)
+			require.Error(t, err, val)
+			assert.Contains(t, err.Error(), "invalid dashboard_batch_interval")
+		}
+	})
+}
+
+func TestValuesLoader_Load_HeartbeatInterval(t *testing.T) {
+	t.Run("embedded default", func(t *testing.T) {
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", "")
+		require.NoError(t, err)
+		assert.Equal(t, 60*time.Second, values.HeartbeatInterval)
+		assert.True(t, values.HeartbeatIntervalSet)
+	})
+
+	t.Run("zero disables heartbeat", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`heartbeat_interval = 0`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", cfgPath)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), values.HeartbeatInterval)
+	})
+
+	t.Run("invalid values return error", func(t *testing.T) {
+		for _, val := range []string{"fast", "-1s"} {
+			cfgPath := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(cfgPath, []byte("heartbeat_interval = "+val), 0o600))
+
+			loader := newValuesLoader(defaultsFS)
+			_, err := loader.Load("", cfgPath)
+			require.Error(t, err, val)
+			assert.Contains(t, err.Error(), "invalid heartbeat_interval")
+		}
+	})
 }
 
-func TestValuesLoader_Load_LocalOverridesCodexEnabled(t *testing.T) {
-	tmpDir := t.TempDir()
-	globalConfig := filepath.Join(tmpDir, "global")
-	localConfig := filepath.Join(tmpDir, "local")
+func TestValuesLoader_Load_CodexFailOnP1(t *testing.T) {
+	t.Run("parse codex_fail_on_p1 true", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`codex_fail_on_p1 = true`), 0o600))
 
-	require.NoError(t, os.WriteFile(globalConfig, []byte(`codex_enabled = true`), 0o600))
-	require.NoError(t, os.WriteFile(localConfig, []byte(`codex_enabled = false`), 0o600))
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", cfgPath)
+		require.NoError(t, err)
+		assert.True(t, values.CodexFailOnP1)
+		assert.True(t, values.CodexFailOnP1Set)
+	})
 
-	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load(localConfig, globalConfig)
-	require.NoError(t, err)
+	t.Run("not set uses default false", func(t *testing.T) {
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", "")
+		require.NoError(t, err)
+		assert.False(t, values.CodexFailOnP1)
+		assert.False(t, values.CodexFailOnP1Set)
+	})
 
-	assert.False(t, values.CodexEnabled)
-	assert.True(t, values.CodexEnabledSet)
-}
+	t.Run("invalid value returns error", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`codex_fail_on_p1 = sometimes`), 0o600))
 
-func TestValuesLoader_Load_LocalOverridesTaskRetryCount(t *testing.T) {
-	tmpDir := t.TempDir()
-	globalConfig := filepath.Join(tmpDir, "global")
-	localConfig := filepath.Join(tmpDir, "local")
+		loader := newValuesLoader(defaultsFS)
+		_, err := loader.Load("", cfgPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid codex_fail_on_p1")
+	})
+}
 
-	require.NoError(t, os.WriteFile(globalConfig, []byte(`task_retry_count = 5`), 0o600))
-	require.NoError(t, os.WriteFile(localConfig, []byte(`task_retry_count = 0`), 0o600))
+func TestValuesLoader_Load_SplitProgressByTask(t *testing.T) {
+	t.Run("parse split_progress_by_task true", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`split_progress_by_task = true`), 0o600))
 
-	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load(localConfig, globalConfig)
-	require.NoError(t, err)
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", cfgPath)
+		require.NoError(t, err)
+		assert.True(t, values.SplitProgressByTask)
+		assert.True(t, values.SplitProgressByTaskSet)
+	})
 
-	assert.Equal(t, 0, values.TaskRetryCount)
-	assert.True(t, values.TaskRetryCountSet)
-}
+	t.Run("not set uses default false", func(t *testing.T) {
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", "")
+		require.NoError(t, err)
+		assert.False(t, values.SplitProgressByTask)
+		assert.False(t, values.SplitProgressByTaskSet)
+	})
 
-func TestValuesLoader_Load_LocalOverridesFinalizeEnabled(t *testing.T) {
-	tmpDir := t.TempDir()
-	globalConfig := filepath.Join(tmpDir, "global")
-	localConfig := filepath.Join(tmpDir, "local")
+	t.Run("local overrides global", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		globalCfg := filepath.Join(tmpDir, "global")
+		localCfg := filepath.Join(tmpDir, "local")
+		require.NoError(t, os.WriteFile(globalCfg, []byte(`split_progress_by_task = true`), 0o600))
+		require.NoError(t, os.WriteFile(localCfg, []byte(`split_progress_by_task = false`), 0o600))
 
-	require.NoError(t, os.WriteFile(globalConfig, []byte(`finalize_enabled = false`), 0o600))
-	require.NoError(t, os.WriteFile(localConfig, []byte(`finalize_enabled = true`), 0o600))
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load(localCfg, globalCfg)
+		require.NoError(t, err)
+		assert.False(t, values.SplitProgressByTask)
+		assert.True(t, values.SplitProgressByTaskSet)
+	})
 
-	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load(localConfig, globalConfig)
-	require.NoError(t, err)
+	t.Run("invalid value returns error", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`split_progress_by_task = maybe`), 0o600))
 
-	assert.True(t, values.FinalizeEnabled)
-	assert.True(t, values.FinalizeEnabledSet)
+		loader := newValuesLoader(defaultsFS)
+		_, err := loader.Load("", cfgPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid split_progress_by_task")
+	})
 }
 
 func TestValuesLoader_Load_WorktreeEnabled(t *testing.T) {
@@ -473,6 +1303,7 @@ func TestValuesLoader_Load_AllValuesFromUserConfig(t *testing.T) {
 	configContent := `
 claude_command = /custom/claude
 claude_args = --custom
+plan_model = opus
 codex_enabled = false
 codex_command = /custom/codex
 codex_model = custom-model
@@ -492,6 +1323,7 @@ plans_dir = my/plans
 
 	assert.Equal(t, "/custom/claude", values.ClaudeCommand)
 	assert.Equal(t, "--custom", values.ClaudeArgs)
+	assert.Equal(t, "opus", values.PlanModel)
 	assert.False(t, values.CodexEnabled)
 	assert.True(t, values.CodexEnabledSet)
 	assert.Equal(t, "/custom/codex", values.CodexCommand)
@@ -1573,6 +2405,308 @@ func TestValuesLoader_Load_ReviewPatience(t *testing.T) {
 	})
 }
 
+func TestValuesLoader_Load_EmptyIterationLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+		wantSet bool
+		wantErr bool
+	}{
+		{name: "default from embedded config", content: "", want: 3, wantSet: true},
+		{name: "custom value", content: "empty_iteration_limit = 5", want: 5, wantSet: true},
+		{name: "zero disables", content: "empty_iteration_limit = 0", want: 0, wantSet: true},
+		{name: "negative returns error", content: "empty_iteration_limit = -1", wantErr: true},
+		{name: "invalid returns error", content: "empty_iteration_limit = abc", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(cfgPath, []byte(tc.content), 0o600))
+
+			loader := newValuesLoader(defaultsFS)
+			values, err := loader.Load("", cfgPath)
+			if tc.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "empty_iteration_limit")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, values.EmptyIterationLimit)
+			assert.Equal(t, tc.wantSet, values.EmptyIterationLimitSet)
+		})
+	}
+}
+
+func TestValuesLoader_Load_MinFreeDiskMB(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+		wantSet bool
+		wantErr bool
+	}{
+		{name: "default from embedded config", content: "", want: 100, wantSet: true},
+		{name: "custom value", content: "min_free_disk_mb = 2048", want: 2048, wantSet: true},
+		{name: "zero disables", content: "min_free_disk_mb = 0", want: 0, wantSet: true},
+		{name: "negative returns error", content: "min_free_disk_mb = -1", wantErr: true},
+		{name: "invalid returns error", content: "min_free_disk_mb = 1G", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfgPath := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(cfgPath, []byte(tc.content), 0o600))
+
+			loader := newValuesLoader(defaultsFS)
+			values, err := loader.Load("", cfgPath)
+			if tc.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "min_free_disk_mb")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, values.MinFreeDiskMB)
+			assert.Equal(t, tc.wantSet, values.MinFreeDiskMBSet)
+		})
+	}
+}
+
+func TestValuesLoader_Load_CodexMinDiffLines(t *testing.T) {
+	t.Run("parse valid value", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		cfgPath := filepath.Join(tmpDir, "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte(`codex_min_diff_lines = 20`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", cfgPath)
+		require.NoError(t, err)
+		assert.Equal(t, 20, values.CodexMinDiffLines)
+	})
+
+	t.Run("not set defaults to zero", func(t *testing.T) {
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", "")
+		require.NoError(t, err)
+		assert.Equal(t, 0, values.CodexMinDiffLines)
+	})
+
+	t.Run("local overrides global", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		globalCfg := filepath.Join(tmpDir, "global")
+		localCfg := filepath.Join(tmpDir, "local")
+		require.NoError(t, os.WriteFile(globalCfg, []byte(`codex_min_diff_lines = 10`), 0o600))
+		require.NoError(t, os.WriteFile(localCfg, []byte(`codex_min_diff_lines = 50`), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load(localCfg, globalCfg)
+		require.NoError(t, err)
+		assert.Equal(t, 50, values.CodexMinDiffLines)
+	})
+}
+
+func TestValuesLoader_Load_TaskOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalCfg := filepath.Join(tmpDir, "global")
+	localCfg := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(globalCfg, []byte(`task_order = by-number`), 0o600))
+	require.NoError(t, os.WriteFile(localCfg, []byte(`task_order = as-listed`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "by-number", values.TaskOrder)
+
+	values, err = loader.Load(localCfg, globalCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "as-listed", values.TaskOrder, "local overrides global")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.TaskOrder)
+}
+
+func TestValuesLoader_Load_BranchNaming(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalCfg := filepath.Join(tmpDir, "global")
+	localCfg := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(globalCfg, []byte("branch_name_template = feature/{{SLUG}}\nbranch_strip_pattern = ^JIRA-\\d+-\n"), 0o600))
+	require.NoError(t, os.WriteFile(localCfg, []byte("branch_name_template = fix/{{SLUG}}\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "feature/{{SLUG}}", values.BranchNameTemplate)
+	assert.Equal(t, `^JIRA-\d+-`, values.BranchStripPattern)
+
+	values, err = loader.Load(localCfg, globalCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "fix/{{SLUG}}", values.BranchNameTemplate, "local overrides global")
+	assert.Equal(t, `^JIRA-\d+-`, values.BranchStripPattern, "global kept when local doesn't set it")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.BranchNameTemplate)
+	assert.Empty(t, values.BranchStripPattern)
+}
+
+func TestValuesLoader_Load_InProgressMarker(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalCfg := filepath.Join(tmpDir, "global")
+	localCfg := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(globalCfg, []byte("in_progress_marker = /\n"), 0o600))
+	require.NoError(t, os.WriteFile(localCfg, []byte("in_progress_marker = -\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "/", values.InProgressMarker)
+
+	values, err = loader.Load(localCfg, globalCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "-", values.InProgressMarker, "local overrides global")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.InProgressMarker, "default marker is applied by the plan parser")
+}
+
+func TestValuesLoader_Load_PushRefspecTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalCfg := filepath.Join(tmpDir, "global")
+	localCfg := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(globalCfg, []byte("push_refspec_template = review/{{BRANCH}}\n"), 0o600))
+	require.NoError(t, os.WriteFile(localCfg, []byte("push_refspec_template = refs/for/{{BRANCH}}\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "review/{{BRANCH}}", values.PushRefspecTemplate)
+
+	values, err = loader.Load(localCfg, globalCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "refs/for/{{BRANCH}}", values.PushRefspecTemplate, "local overrides global")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.PushRefspecTemplate)
+}
+
+func TestValuesLoader_Load_Profiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalCfg := filepath.Join(tmpDir, "global")
+	localCfg := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(globalCfg, []byte(`max_iterations = 40
+[profile fast]
+codex_enabled = false
+max_iterations = 10
+[profile thorough]
+review_patience = 3
+[other]
+max_iterations = 99
+`), 0o600))
+	require.NoError(t, os.WriteFile(localCfg, []byte(`[profile fast]
+claude_args = --model sonnet
+`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localCfg, globalCfg)
+	require.NoError(t, err)
+	assert.Equal(t, 40, values.MaxIterations, "profile and unknown sections don't change the main settings")
+	require.Len(t, values.Profiles, 2)
+
+	fast := values.Profiles["fast"]
+	assert.False(t, fast.CodexEnabled)
+	assert.True(t, fast.CodexEnabledSet)
+	assert.Equal(t, 10, fast.MaxIterations)
+	assert.Equal(t, "--model sonnet", fast.ClaudeArgs, "local profile merged into the global one")
+	assert.Equal(t, 3, values.Profiles["thorough"].ReviewPatience)
+	assert.Empty(t, fast.ClaudeCommand, "profiles hold only their own keys")
+}
+
+func TestValuesLoader_Load_DefaultMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalCfg := filepath.Join(tmpDir, "global")
+	localCfg := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(globalCfg, []byte(`default_mode = review`), 0o600))
+	require.NoError(t, os.WriteFile(localCfg, []byte(`default_mode = tasks-only`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "review", values.DefaultMode)
+
+	values, err = loader.Load(localCfg, globalCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "tasks-only", values.DefaultMode, "local overrides global")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.DefaultMode)
+
+	require.NoError(t, os.WriteFile(localCfg, []byte(`default_mode = codex-only`), 0o600))
+	values, err = loader.Load(localCfg, "")
+	require.NoError(t, err)
+	assert.Equal(t, "external-only", values.DefaultMode, "deprecated codex-only is normalized")
+}
+
+func TestValuesLoader_Load_TestCommand(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(cfgPath, []byte("test_command =  make check  "), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", cfgPath)
+	require.NoError(t, err)
+	assert.Equal(t, "make check", values.TestCommand)
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.TestCommand, "empty by default, detection happens at run time")
+}
+
+func TestValuesLoader_Load_RunHooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalCfg := filepath.Join(tmpDir, "global")
+	localCfg := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(globalCfg, []byte("pre_run_command = make deps && make up\npost_run_command = make down"), 0o600))
+	require.NoError(t, os.WriteFile(localCfg, []byte("pre_run_command = npm ci"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "make deps && make up", values.PreRunCommand)
+	assert.Equal(t, "make down", values.PostRunCommand)
+
+	values, err = loader.Load(localCfg, globalCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "npm ci", values.PreRunCommand, "local overrides global")
+	assert.Equal(t, "make down", values.PostRunCommand, "unset local keeps global")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.PreRunCommand)
+	assert.Empty(t, values.PostRunCommand)
+}
+
+func TestValuesLoader_Load_MaxLimitRetries(t *testing.T) {
+	t.Run("parse valid value", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		cfgPath := filepath.Join(tmpDir, "config")
+		require.NoError(t, os.WriteFile(cfgPath, []byte("wait_on_limit = 30m\nmax_limit_retries = 3"), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", cfgPath)
+		require.NoError(t, err)
+		assert.Equal(t, 3, values.MaxLimitRetries)
+	})
+
+	t.Run("not set defaults to unlimited", func(t *testing.T) {
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", "")
+		require.NoError(t, err)
+		assert.Equal(t, 0, values.MaxLimitRetries)
+	})
+}
+
 func TestValues_mergeFrom_ReviewPatience(t *testing.T) {
 	t.Run("non-zero overrides", func(t *testing.T) {
 		dst := Values{ReviewPatience: 0}
@@ -1678,6 +2812,42 @@ func TestValues_mergeFrom_VcsCommand(t *testing.T) {
 	})
 }
 
+func TestValuesLoader_Load_FzfSettings(t *testing.T) {
+	t.Run("parse fzf_command and fzf_args", func(t *testing.T) {
+		cfgPath := filepath.Join(t.TempDir(), "config")
+		content := "fzf_command = /opt/bin/fzf\nfzf_args = --height=40% --preview 'cat {}'\n"
+		require.NoError(t, os.WriteFile(cfgPath, []byte(content), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", cfgPath)
+		require.NoError(t, err)
+		assert.Equal(t, "/opt/bin/fzf", values.FzfCommand)
+		assert.Equal(t, "--height=40% --preview 'cat {}'", values.FzfArgs)
+	})
+
+	t.Run("defaults are empty", func(t *testing.T) {
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load("", "")
+		require.NoError(t, err)
+		assert.Empty(t, values.FzfCommand)
+		assert.Empty(t, values.FzfArgs)
+	})
+
+	t.Run("local overrides global", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		globalCfg := filepath.Join(tmpDir, "global")
+		localCfg := filepath.Join(tmpDir, "local")
+		require.NoError(t, os.WriteFile(globalCfg, []byte("fzf_command = /global/fzf\nfzf_args = --border"), 0o600))
+		require.NoError(t, os.WriteFile(localCfg, []byte("fzf_args = --reverse"), 0o600))
+
+		loader := newValuesLoader(defaultsFS)
+		values, err := loader.Load(localCfg, globalCfg)
+		require.NoError(t, err)
+		assert.Equal(t, "/global/fzf", values.FzfCommand)
+		assert.Equal(t, "--reverse", values.FzfArgs)
+	})
+}
+
 func TestValuesLoader_parseValuesFromBytes_LimitPatterns(t *testing.T) {
 	vl := &valuesLoader{embedFS: defaultsFS}
 
@@ -1936,6 +3106,45 @@ func TestValuesLoader_Load_SessionTimeout(t *testing.T) {
 	})
 }
 
+func TestValuesLoader_Load_ClaudeIdleTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		global  string
+		local   string
+		want    time.Duration
+		wantSet bool
+		errPart string
+	}{
+		{name: "not set", want: 0, wantSet: false},
+		{name: "from global", global: "claude_idle_timeout = 10m", want: 10 * time.Minute, wantSet: true},
+		{name: "local overrides global", global: "claude_idle_timeout = 10m", local: "claude_idle_timeout = 90s",
+			want: 90 * time.Second, wantSet: true},
+		{name: "explicit zero overrides global", global: "claude_idle_timeout = 10m", local: "claude_idle_timeout = 0s",
+			want: 0, wantSet: true},
+		{name: "invalid format", global: "claude_idle_timeout = soon", errPart: "invalid claude_idle_timeout"},
+		{name: "negative", global: "claude_idle_timeout = -1m", errPart: "must be non-negative"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			globalCfg := filepath.Join(tmpDir, "global")
+			localCfg := filepath.Join(tmpDir, "local")
+			require.NoError(t, os.WriteFile(globalCfg, []byte(tc.global), 0o600))
+			require.NoError(t, os.WriteFile(localCfg, []byte(tc.local), 0o600))
+
+			values, err := newValuesLoader(defaultsFS).Load(localCfg, globalCfg)
+			if tc.errPart != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errPart)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, values.ClaudeIdleTimeout)
+			assert.Equal(t, tc.wantSet, values.ClaudeIdleTimeoutSet)
+		})
+	}
+}
+
 func TestValues_mergeFrom_SessionTimeout(t *testing.T) {
 	t.Run("set flag merges", func(t *testing.T) {
 		dst := Values{SessionTimeout: 0, SessionTimeoutSet: false}
@@ -0,0 +1,91 @@
+package executor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// toolLeadInRe matches short narration lines claude writes right before a tool call,
+// e.g. "Let me check the tests:" or "Now I'll read the config file:".
+var toolLeadInRe = regexp.MustCompile(`(?i)^(?:(?:now|next|first|then|ok|okay|good|great)[,.!]?\s+){0,2}(?:let me|let's|i'll|i will|i need to|i'm going to)\b.{0,120}:$`)
+
+// claudeOutputFilter cleans up claude text output for display.
+// it works on complete lines: partial lines from stream deltas are buffered until a newline arrives.
+// hides tool-call lead-in lines, collapses consecutive repeated lines and squeezes blank line runs.
+// the filter only affects displayed output, Result.Output is always the raw text.
+type claudeOutputFilter struct {
+	partial  string // incomplete line waiting for newline
+	lastLine string // last displayed non-blank line, used for repeat detection
+	repeats  int    // number of hidden repeats of lastLine
+	blank    bool   // last displayed line was blank
+}
+
+// write consumes a chunk of streamed text and returns the filtered text ready for display.
+// returns empty string if nothing should be displayed yet.
+func (f *claudeOutputFilter) write(text string) string {
+	f.partial += text
+	idx := strings.LastIndexByte(f.partial, '\n')
+	if idx < 0 {
+		return ""
+	}
+	complete := f.partial[:idx]
+	f.partial = f.partial[idx+1:]
+
+	var out strings.Builder
+	for line := range strings.SplitSeq(complete, "\n") {
+		out.WriteString(f.filterLine(line))
+	}
+	return out.String()
+}
+
+// flush returns any buffered output, including a pending partial line and repeat marker.
+// called once the stream ends, whether it completed or was canceled.
+func (f *claudeOutputFilter) flush() string {
+	var out strings.Builder
+	if f.partial != "" {
+		line := f.partial
+		f.partial = ""
+		out.WriteString(f.filterLine(line))
+	}
+	out.WriteString(f.repeatMarker())
+	return out.String()
+}
+
+// filterLine decides how a single complete line is displayed. returns the line with trailing newline,
+// a repeat marker followed by the line, or empty string if the line is hidden.
+func (f *claudeOutputFilter) filterLine(line string) string {
+	trimmed := strings.TrimSpace(line)
+
+	if trimmed == "" {
+		if f.blank {
+			return "" // squeeze consecutive blank lines
+		}
+		f.blank = true
+		return f.repeatMarker() + "\n"
+	}
+
+	if toolLeadInRe.MatchString(trimmed) {
+		return ""
+	}
+
+	if trimmed == f.lastLine && !f.blank {
+		f.repeats++
+		return ""
+	}
+
+	marker := f.repeatMarker()
+	f.lastLine = trimmed
+	f.blank = false
+	return marker + line + "\n"
+}
+
+// repeatMarker returns a note about hidden repeats of the last line and resets the counter.
+func (f *claudeOutputFilter) repeatMarker() string {
+	if f.repeats == 0 {
+		return ""
+	}
+	n := f.repeats
+	f.repeats = 0
+	return fmt.Sprintf("(previous line repeated %dx)\n", n)
+}
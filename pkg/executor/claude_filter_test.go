@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaudeOutputFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+	}{
+		{
+			name:   "plain text passes through",
+			chunks: []string{"Implemented the parser.\n", "All tests pass.\n"},
+			want:   "Implemented the parser.\nAll tests pass.\n",
+		},
+		{
+			name: "tool lead-ins hidden",
+			chunks: []string{
+				"Let me check the existing tests:\n",
+				"Now let me read the config file:\n",
+				"I'll run the linter:\n",
+				"The config loader ignores unknown keys, so I added validation.\n",
+			},
+			want: "The config loader ignores unknown keys, so I added validation.\n",
+		},
+		{
+			name:   "lead-in without colon kept",
+			chunks: []string{"Let me explain the approach. The parser is recursive descent.\n"},
+			want:   "Let me explain the approach. The parser is recursive descent.\n",
+		},
+		{
+			name:   "lead-in with prefix word hidden",
+			chunks: []string{"Good, now let me run the tests:\n", "Okay. I need to update the mocks:\n", "done\n"},
+			want:   "done\n",
+		},
+		{
+			name:   "long colon line kept",
+			chunks: []string{"Let me " + strings.Repeat("x", 130) + ":\n"},
+			want:   "Let me " + strings.Repeat("x", 130) + ":\n",
+		},
+		{
+			name:   "repeated lines collapsed",
+			chunks: []string{"Running tests...\n", "Running tests...\n", "Running tests...\n", "ok\n"},
+			want:   "Running tests...\n(previous line repeated 2x)\nok\n",
+		},
+		{
+			name:   "repeat marker flushed at end",
+			chunks: []string{"waiting\n", "waiting\n"},
+			want:   "waiting\n(previous line repeated 1x)\n",
+		},
+		{
+			name:   "blank line runs squeezed",
+			chunks: []string{"first\n\n\n\nsecond\n"},
+			want:   "first\n\nsecond\n",
+		},
+		{
+			name:   "same line separated by blank is not a repeat",
+			chunks: []string{"step\n\nstep\n"},
+			want:   "step\n\nstep\n",
+		},
+		{
+			name:   "deltas split across lines",
+			chunks: []string{"Let me che", "ck the file:\nThe fi", "le is fine.\n"},
+			want:   "The file is fine.\n",
+		},
+		{
+			name:   "partial last line flushed",
+			chunks: []string{"summary\n<<<RALPHEX:ALL_TASKS_DONE>>>"},
+			want:   "summary\n<<<RALPHEX:ALL_TASKS_DONE>>>\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &claudeOutputFilter{}
+			var out strings.Builder
+			for _, chunk := range tc.chunks {
+				out.WriteString(f.write(chunk))
+			}
+			out.WriteString(f.flush())
+			assert.Equal(t, tc.want, out.String())
+		})
+	}
+}
+
+func TestClaudeOutputFilter_WriteBuffersPartialLine(t *testing.T) {
+	f := &claudeOutputFilter{}
+	assert.Empty(t, f.write("no newline yet"))
+	assert.Equal(t, "no newline yet, done\n", f.write(", done\n"))
+	assert.Empty(t, f.flush())
+}
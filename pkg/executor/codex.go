@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 )
 
@@ -80,7 +81,58 @@ type CodexExecutor struct {
 	Debug           bool              // enable debug output
 	ErrorPatterns   []string          // patterns to detect in output (e.g., rate limit messages)
 	LimitPatterns   []string          // patterns to detect rate limits (checked before error patterns)
+	ExtraConfig     []string          // extra key=value overrides, passed as -c after the built-in ones
+	StripANSI       bool              // remove ANSI escape sequences from Result.Output
+	StreamRaw       bool              // pass every stderr line to output handlers unfiltered, verbose, for debugging the filter
 	runner          CodexRunner       // for testing, nil uses default
+	extraHandlers   []func(text string)
+}
+
+// AddOutputHandler registers an additional output sink, called after OutputHandler for each filtered line.
+// lets callers tee codex output to several destinations (e.g. dashboard and file) without fanning out manually.
+// must be called before Run.
+func (e *CodexExecutor) AddOutputHandler(h func(text string)) {
+	if h != nil {
+		e.extraHandlers = append(e.extraHandlers, h)
+	}
+}
+
+// emitOutput sends text to OutputHandler and every handler added with AddOutputHandler.
+func (e *CodexExecutor) emitOutput(text string) {
+	if e.OutputHandler != nil {
+		e.OutputHandler(text)
+	}
+	for _, h := range e.extraHandlers {
+		h(text)
+	}
+}
+
+// codexManagedKeys lists codex config keys set by ralphex itself.
+// overriding them via extra config requires an explicit force.
+var codexManagedKeys = []string{"model", "model_reasoning_effort", "stream_idle_timeout_ms", "project_doc"}
+
+// ErrCodexManagedKey is returned by ValidateCodexOverride when the key is one ralphex sets itself.
+var ErrCodexManagedKey = errors.New("key is managed by ralphex")
+
+// ValidateCodexOverride checks a single extra codex config override in key=value form.
+// keys managed by ralphex (model, sandbox, timeouts, project doc) are rejected unless force is set.
+func ValidateCodexOverride(kv string, force bool) error {
+	key, value, ok := strings.Cut(kv, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" || strings.TrimSpace(value) == "" {
+		return fmt.Errorf("invalid codex config %q, expected key=value", kv)
+	}
+	if strings.ContainsAny(key, " \t") {
+		return fmt.Errorf("invalid codex config key %q, must not contain whitespace", key)
+	}
+	if force {
+		return nil
+	}
+	// sandbox_mode and sandbox_* tables would conflict with the --sandbox flag ralphex passes
+	if slices.Contains(codexManagedKeys, key) || strings.HasPrefix(key, "sandbox_") {
+		return fmt.Errorf("codex config key %q: %w", key, ErrCodexManagedKey)
+	}
+	return nil
 }
 
 // codexFilterState tracks header separator count for filtering.
@@ -90,7 +142,7 @@ type codexFilterState struct {
 }
 
 // Run executes codex CLI with the given prompt and returns filtered output.
-// stderr is streamed line-by-line to OutputHandler for progress indication.
+// stderr is streamed line-by-line to OutputHandler (and handlers added via AddOutputHandler) for progress indication.
 // stdout is captured entirely as the final response (returned in Result.Output).
 func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 	cmd := e.Command
@@ -134,6 +186,11 @@ func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 		args = append(args, "-c", fmt.Sprintf("project_doc=%q", e.ProjectDoc))
 	}
 
+	// extra overrides go last so codex applies them on top of the built-in settings
+	for _, kv := range e.ExtraConfig {
+		args = append(args, "-c", kv)
+	}
+
 	// pass prompt via stdin to avoid Windows 8191-char command-line limit;
 	// codex reads from stdin when no positional prompt argument is given
 	stdinReader := strings.NewReader(prompt)
@@ -155,6 +212,9 @@ func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 
 	// read stdout entirely as final response
 	stdoutContent, stdoutErr := e.readStdout(streams.Stdout)
+	if e.StripANSI {
+		stdoutContent = stripANSI(stdoutContent)
+	}
 
 	// wait for stderr processing to complete
 	stderrRes := <-stderrDone
@@ -184,7 +244,7 @@ func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 	}
 
 	// detect signal in stdout (the actual response)
-	signal := detectSignal(stdoutContent)
+	signal, ambiguous := detectSignal(stdoutContent)
 
 	// only check error/limit patterns when the process failed (non-zero exit or stream error).
 	// when codex exits cleanly, pattern matches in output are false positives from findings
@@ -194,24 +254,26 @@ func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 		// check limit patterns first (higher priority)
 		if pattern := matchPattern(stdoutContent, e.LimitPatterns); pattern != "" {
 			return Result{
-				Output: stdoutContent,
-				Signal: signal,
-				Error:  &LimitPatternError{Pattern: pattern, HelpCmd: "codex /status"},
+				Output:          stdoutContent,
+				Signal:          signal,
+				SignalAmbiguous: ambiguous,
+				Error:           &LimitPatternError{Pattern: pattern, HelpCmd: "codex /status"},
 			}
 		}
 
 		// check for error patterns in output
 		if pattern := matchPattern(stdoutContent, e.ErrorPatterns); pattern != "" {
 			return Result{
-				Output: stdoutContent,
-				Signal: signal,
-				Error:  &PatternMatchError{Pattern: pattern, HelpCmd: "codex /status"},
+				Output:          stdoutContent,
+				Signal:          signal,
+				SignalAmbiguous: ambiguous,
+				Error:           &PatternMatchError{Pattern: pattern, HelpCmd: "codex /status"},
 			}
 		}
 	}
 
 	// return stdout content as the result (the actual answer from codex)
-	return Result{Output: stdoutContent, Signal: signal, Error: finalErr}
+	return Result{Output: stdoutContent, Signal: signal, SignalAmbiguous: ambiguous, Error: finalErr}
 }
 
 // stderrResult holds processed stderr output and any error from reading.
@@ -221,7 +283,7 @@ type stderrResult struct {
 }
 
 // processStderr reads stderr line-by-line, filters for progress display.
-// shows header block (between first two "--------" separators) and bold summaries.
+// shows header block (between first two "--------" separators) and bold summaries, or every line with StreamRaw.
 // also captures last lines of unfiltered output for error reporting.
 func (e *CodexExecutor) processStderr(ctx context.Context, r io.Reader) stderrResult {
 	const maxTailLines = 5    // keep last N lines for error context
@@ -244,10 +306,12 @@ func (e *CodexExecutor) processStderr(ctx context.Context, r io.Reader) stderrRe
 			}
 		}
 
+		if e.StreamRaw {
+			e.emitOutput(line + "\n")
+			return
+		}
 		if show, filtered := e.shouldDisplay(line, state); show {
-			if e.OutputHandler != nil {
-				e.OutputHandler(filtered + "\n")
-			}
+			e.emitOutput(filtered + "\n")
 		}
 	})
 
@@ -114,6 +114,25 @@ Even more noise`
 	assert.Equal(t, "<<<RALPHEX:CODEX_REVIEW_DONE>>>", result.Signal)
 }
 
+func TestCodexExecutor_Run_StreamRaw(t *testing.T) {
+	stderr := "--------\nmodel: gpt-5\n--------\nSome thinking noise\n**Summary: Found 2 issues**\nsame\nsame\n"
+	stdout := "Final response from codex.\n<<<RALPHEX:CODEX_REVIEW_DONE>>>"
+	mock := &mockCodexRunner{
+		runFunc: func(_ context.Context, _ string, _ ...string) (CodexStreams, func() error, error) {
+			return mockStreams(stderr, stdout), mockWait(), nil
+		},
+	}
+
+	var streamed strings.Builder
+	e := &CodexExecutor{runner: mock, StreamRaw: true, OutputHandler: func(text string) { streamed.WriteString(text) }}
+	result := e.Run(context.Background(), "analyze code")
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, stderr, streamed.String(), "every line passes through unchanged, including noise and repeats")
+	assert.Equal(t, "Final response from codex.\n<<<RALPHEX:CODEX_REVIEW_DONE>>>", result.Output)
+	assert.Equal(t, "<<<RALPHEX:CODEX_REVIEW_DONE>>>", result.Signal, "signal detection is unaffected")
+}
+
 func TestCodexExecutor_Run_StdoutIsResult(t *testing.T) {
 	// verify that Result.Output contains stdout content, not stderr
 	stderr := "--------\nheader\n--------\n**progress**\nthinking noise\n"
@@ -134,6 +153,26 @@ func TestCodexExecutor_Run_StdoutIsResult(t *testing.T) {
 	assert.NotContains(t, result.Output, "thinking noise", "stderr content should not be in Result.Output")
 }
 
+func TestCodexExecutor_Run_StripANSI(t *testing.T) {
+	stdout := "\x1b[1mfindings:\x1b[0m none\n<<<RALPHEX:\x1b[0mCODEX_REVIEW_DONE>>>"
+	mock := &mockCodexRunner{
+		runFunc: func(_ context.Context, _ string, _ ...string) (CodexStreams, func() error, error) {
+			return mockStreams("", stdout), mockWait(), nil
+		},
+	}
+
+	e := &CodexExecutor{runner: mock, StripANSI: true}
+	result := e.Run(context.Background(), "analyze code")
+	require.NoError(t, result.Error)
+	assert.Equal(t, "findings: none\n<<<RALPHEX:CODEX_REVIEW_DONE>>>", result.Output)
+	assert.Equal(t, "<<<RALPHEX:CODEX_REVIEW_DONE>>>", result.Signal)
+
+	e = &CodexExecutor{runner: mock}
+	result = e.Run(context.Background(), "analyze code")
+	require.NoError(t, result.Error)
+	assert.Equal(t, stdout, result.Output)
+}
+
 func TestCodexExecutor_Run_StartError(t *testing.T) {
 	mock := &mockCodexRunner{
 		runFunc: func(_ context.Context, _ string, _ ...string) (CodexStreams, func() error, error) {
@@ -269,6 +308,68 @@ func TestCodexExecutor_Run_CustomSettings(t *testing.T) {
 	assert.NotContains(t, capturedArgs, "test", "prompt should be passed via stdin, not as CLI arg")
 }
 
+func TestCodexExecutor_Run_ExtraConfig(t *testing.T) {
+	t.Setenv("RALPHEX_DOCKER", "")
+
+	var capturedArgs []string
+	mock := &mockCodexRunner{
+		runFunc: func(_ context.Context, _ string, args ...string) (CodexStreams, func() error, error) {
+			capturedArgs = args
+			return mockStreams("", "result"), mockWait(), nil
+		},
+	}
+	e := &CodexExecutor{
+		runner:      mock,
+		ProjectDoc:  "/path/to/doc.md",
+		ExtraConfig: []string{"model_verbosity=high", `model="o3"`},
+	}
+
+	result := e.Run(context.Background(), "test")
+	require.NoError(t, result.Error)
+
+	// extra overrides are appended after all built-in ones, so codex applies them last
+	require.GreaterOrEqual(t, len(capturedArgs), 4)
+	assert.Equal(t, []string{"-c", "model_verbosity=high", "-c", `model="o3"`}, capturedArgs[len(capturedArgs)-4:])
+	assert.Less(t, slices.Index(capturedArgs, `project_doc="/path/to/doc.md"`), slices.Index(capturedArgs, "model_verbosity=high"))
+}
+
+func TestValidateCodexOverride(t *testing.T) {
+	tests := []struct {
+		name        string
+		kv          string
+		force       bool
+		wantErr     string
+		wantManaged bool
+	}{
+		{name: "plain override", kv: "model_verbosity=high"},
+		{name: "dotted key", kv: "features.web_search=true"},
+		{name: "quoted value with equals", kv: `notify="a=b"`},
+		{name: "missing equals", kv: "model_verbosity", wantErr: "expected key=value"},
+		{name: "empty key", kv: "=high", wantErr: "expected key=value"},
+		{name: "empty value", kv: "model_verbosity=", wantErr: "expected key=value"},
+		{name: "whitespace in key", kv: "model verbosity=high", wantErr: "must not contain whitespace"},
+		{name: "managed model", kv: "model=o3", wantErr: "managed by ralphex", wantManaged: true},
+		{name: "managed timeout", kv: "stream_idle_timeout_ms=10", wantErr: "managed by ralphex", wantManaged: true},
+		{name: "managed sandbox mode", kv: "sandbox_mode=workspace-write", wantErr: "managed by ralphex", wantManaged: true},
+		{name: "managed sandbox table", kv: "sandbox_workspace_write.network_access=true", wantErr: "managed by ralphex", wantManaged: true},
+		{name: "managed key with force", kv: "model=o3", force: true},
+		{name: "force does not skip format check", kv: "model", force: true, wantErr: "expected key=value"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateCodexOverride(tc.kv, tc.force)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+			assert.Equal(t, tc.wantManaged, errors.Is(err, ErrCodexManagedKey))
+		})
+	}
+}
+
 func TestCodexExecutor_shouldDisplay_headerBlock(t *testing.T) {
 	e := &CodexExecutor{}
 
@@ -439,6 +540,35 @@ func TestCodexExecutor_Run_NoOutputHandler(t *testing.T) {
 	assert.Equal(t, "actual output", result.Output)
 }
 
+func TestCodexExecutor_AddOutputHandler(t *testing.T) {
+	mock := &mockCodexRunner{
+		runFunc: func(_ context.Context, _ string, _ ...string) (CodexStreams, func() error, error) {
+			return mockStreams("--------\nmodel: gpt-5\n--------\n**Reviewing files**", "actual output"), mockWait(), nil
+		},
+	}
+
+	var primary, dashboard, file []string
+	e := &CodexExecutor{runner: mock, OutputHandler: func(text string) { primary = append(primary, text) }}
+	e.AddOutputHandler(func(text string) { dashboard = append(dashboard, text) })
+	e.AddOutputHandler(nil) // ignored
+	e.AddOutputHandler(func(text string) { file = append(file, text) })
+
+	result := e.Run(context.Background(), "analyze code")
+	require.NoError(t, result.Error)
+	require.NotEmpty(t, primary)
+	assert.Equal(t, primary, dashboard, "every sink should get the same lines")
+	assert.Equal(t, primary, file, "every sink should get the same lines")
+
+	t.Run("works without primary handler", func(t *testing.T) {
+		var only []string
+		e := &CodexExecutor{runner: mock}
+		e.AddOutputHandler(func(text string) { only = append(only, text) })
+		result := e.Run(context.Background(), "analyze code")
+		require.NoError(t, result.Error)
+		assert.Equal(t, primary, only)
+	})
+}
+
 func TestCodexExecutor_processStderr_contextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -54,6 +54,7 @@ type CustomExecutor struct {
 	OutputHandler func(text string) // called for each output line, can be nil
 	ErrorPatterns []string          // patterns to detect in output (e.g., rate limit messages)
 	LimitPatterns []string          // patterns to detect rate limits (checked before error patterns)
+	StripANSI     bool              // remove ANSI escape sequences from Result.Output, the display keeps them
 	runner        CustomRunner      // for testing, nil uses default
 }
 
@@ -96,8 +97,12 @@ func (e *CustomExecutor) Run(ctx context.Context, promptContent string) Result {
 		return Result{Error: fmt.Errorf("start custom script: %w", err)}
 	}
 
-	// process stdout for output and signal detection
-	output, signal, streamErr := e.processOutput(ctx, stdout)
+	// process stdout for output, then detect signals on the full output
+	output, streamErr := e.processOutput(ctx, stdout)
+	if e.StripANSI {
+		output = stripANSI(output)
+	}
+	signal, ambiguous := detectSignal(output)
 
 	// wait for command completion
 	waitErr := wait()
@@ -122,29 +127,30 @@ func (e *CustomExecutor) Run(ctx context.Context, promptContent string) Result {
 		// check limit patterns first (higher priority)
 		if pattern := matchPattern(output, e.LimitPatterns); pattern != "" {
 			return Result{
-				Output: output,
-				Signal: signal,
-				Error:  &LimitPatternError{Pattern: pattern, HelpCmd: e.Script + " --help"},
+				Output:          output,
+				Signal:          signal,
+				SignalAmbiguous: ambiguous,
+				Error:           &LimitPatternError{Pattern: pattern, HelpCmd: e.Script + " --help"},
 			}
 		}
 
 		// check for error patterns in output
 		if pattern := matchPattern(output, e.ErrorPatterns); pattern != "" {
 			return Result{
-				Output: output,
-				Signal: signal,
-				Error:  &PatternMatchError{Pattern: pattern, HelpCmd: e.Script + " --help"},
+				Output:          output,
+				Signal:          signal,
+				SignalAmbiguous: ambiguous,
+				Error:           &PatternMatchError{Pattern: pattern, HelpCmd: e.Script + " --help"},
 			}
 		}
 	}
 
-	return Result{Output: output, Signal: signal, Error: finalErr}
+	return Result{Output: output, Signal: signal, SignalAmbiguous: ambiguous, Error: finalErr}
 }
 
-// processOutput reads stdout line-by-line, streams to OutputHandler, and detects signals.
-func (e *CustomExecutor) processOutput(ctx context.Context, r io.Reader) (output, signal string, err error) {
+// processOutput reads stdout line-by-line and streams to OutputHandler.
+func (e *CustomExecutor) processOutput(ctx context.Context, r io.Reader) (string, error) {
 	var outputBuf []byte
-	var sig string
 
 	readErr := readLines(ctx, r, func(line string) {
 		outputBuf = append(outputBuf, line...)
@@ -153,15 +159,10 @@ func (e *CustomExecutor) processOutput(ctx context.Context, r io.Reader) (output
 		if e.OutputHandler != nil {
 			e.OutputHandler(line + "\n")
 		}
-
-		// check for signals in each line
-		if s := detectSignal(line); s != "" {
-			sig = s
-		}
 	})
 
 	if readErr != nil {
-		return string(outputBuf), sig, fmt.Errorf("read output: %w", readErr)
+		return string(outputBuf), fmt.Errorf("read output: %w", readErr)
 	}
-	return string(outputBuf), sig, nil
+	return string(outputBuf), nil
 }
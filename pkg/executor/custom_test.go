@@ -36,6 +36,26 @@ func TestCustomExecutor_Run_Success(t *testing.T) {
 	assert.Equal(t, "<<<RALPHEX:CODEX_REVIEW_DONE>>>", result.Signal)
 }
 
+func TestCustomExecutor_Run_StripANSI(t *testing.T) {
+	mock := &mockCustomRunner{
+		runFunc: func(_ context.Context, _, _ string) (io.Reader, func() error, error) {
+			output := "\x1b[31mFound issue at main.go:42\x1b[0m\n<<<RALPHEX:CODEX_REVIEW_DONE>>>"
+			return strings.NewReader(output), func() error { return nil }, nil
+		},
+	}
+	var streamed []string
+	e := &CustomExecutor{Script: "/path/to/script.sh", runner: mock, StripANSI: true,
+		OutputHandler: func(text string) { streamed = append(streamed, text) }}
+
+	result := e.Run(context.Background(), "review this code")
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, "Found issue at main.go:42\n<<<RALPHEX:CODEX_REVIEW_DONE>>>\n", result.Output)
+	assert.Equal(t, "<<<RALPHEX:CODEX_REVIEW_DONE>>>", result.Signal)
+	require.NotEmpty(t, streamed)
+	assert.Contains(t, streamed[0], "\x1b[31m", "display keeps colors")
+}
+
 func TestCustomExecutor_Run_StreamsOutput(t *testing.T) {
 	output := `Starting review...
 Found issue at main.go:42
@@ -273,12 +293,11 @@ func TestCustomExecutor_processOutput_readError(t *testing.T) {
 	e := &CustomExecutor{Script: "/path/to/script.sh"}
 	errReader := &failingReader{err: errors.New("read failed")}
 
-	output, signal, err := e.processOutput(context.Background(), errReader)
+	output, err := e.processOutput(context.Background(), errReader)
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "read output")
 	assert.Empty(t, output)
-	assert.Empty(t, signal)
 }
 
 func TestCustomExecutor_processOutput_contextCancellation(t *testing.T) {
@@ -295,7 +314,7 @@ func TestCustomExecutor_processOutput_contextCancellation(t *testing.T) {
 	}()
 
 	e := &CustomExecutor{Script: "/path/to/script.sh"}
-	_, _, err := e.processOutput(ctx, pr)
+	_, err := e.processOutput(ctx, pr)
 
 	// should return context.Canceled or nil (depending on timing)
 	if err != nil {
@@ -4,13 +4,19 @@ package executor
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
+	"regexp"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/umputun/ralphex/pkg/cmdargs"
 	"github.com/umputun/ralphex/pkg/status"
 )
 
@@ -18,9 +24,10 @@ import (
 
 // Result holds execution result with output and detected signal.
 type Result struct {
-	Output string // accumulated text output
-	Signal string // detected signal (COMPLETED, FAILED, etc.) or empty
-	Error  error  // execution error if any
+	Output          string // accumulated text output
+	Signal          string // detected signal (COMPLETED, FAILED, etc.) or empty
+	SignalAmbiguous bool   // multiple distinct signals found in output, Signal holds the last one
+	Error           error  // execution error if any
 }
 
 // PatternMatchError is returned when a configured error pattern is detected in output.
@@ -33,6 +40,10 @@ func (e *PatternMatchError) Error() string {
 	return fmt.Sprintf("detected error pattern: %q", e.Pattern)
 }
 
+// ErrUsageLimited indicates the tool reported a usage or rate limit instead of doing work.
+// LimitPatternError wraps it, so callers can check with errors.Is.
+var ErrUsageLimited = errors.New("usage limited")
+
 // LimitPatternError is returned when a configured rate limit pattern is detected in output.
 // when wait-on-limit is configured, the caller retries instead of exiting.
 type LimitPatternError struct {
@@ -44,6 +55,15 @@ func (e *LimitPatternError) Error() string {
 	return fmt.Sprintf("detected limit pattern: %q", e.Pattern)
 }
 
+// Unwrap returns ErrUsageLimited.
+func (e *LimitPatternError) Unwrap() error {
+	return ErrUsageLimited
+}
+
+// ErrIdleTimeout is returned when claude produces no output for longer than ClaudeExecutor.IdleTimeout.
+// the session is killed; callers can retry the call.
+var ErrIdleTimeout = errors.New("claude idle timeout")
+
 // CommandRunner abstracts command execution for testing.
 // Returns an io.Reader for streaming output and a wait function for completion.
 type CommandRunner interface {
@@ -94,54 +114,20 @@ func (r *execClaudeRunner) Run(ctx context.Context, name string, args ...string)
 	return stdout, cleanup.Wait, nil
 }
 
-// splitArgs splits a space-separated argument string into a slice.
-// handles quoted strings (both single and double quotes).
-func splitArgs(s string) []string {
-	var args []string
-	var current strings.Builder
-	var inQuote rune
-	var escaped bool
-
-	for _, r := range s {
-		if escaped {
-			current.WriteRune(r)
-			escaped = false
-			continue
-		}
-
-		if r == '\\' {
-			escaped = true
-			continue
-		}
-
-		if r == '"' || r == '\'' {
-			switch { //nolint:staticcheck // cannot use tagged switch because we compare with both inQuote and r
-			case inQuote == 0:
-				inQuote = r
-			case inQuote == r:
-				inQuote = 0
-			default:
-				current.WriteRune(r)
-			}
-			continue
-		}
-
-		if r == ' ' && inQuote == 0 {
-			if current.Len() > 0 {
-				args = append(args, current.String())
-				current.Reset()
-			}
-			continue
+// withModelArg sets the --model argument to the given model, replacing any existing
+// "--model value" or "--model=value" form in args, or appending it when absent.
+func withModelArg(args []string, model string) []string {
+	res := make([]string, 0, len(args)+2)
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--model":
+			i++ // skip the value as well
+		case strings.HasPrefix(args[i], "--model="):
+		default:
+			res = append(res, args[i])
 		}
-
-		current.WriteRune(r)
-	}
-
-	if current.Len() > 0 {
-		args = append(args, current.String())
 	}
-
-	return args
+	return append(res, "--model", model)
 }
 
 // filterEnv returns a copy of env with specified keys removed.
@@ -186,10 +172,14 @@ type streamEvent struct {
 type ClaudeExecutor struct {
 	Command       string            // command to execute, defaults to "claude"
 	Args          string            // additional arguments (space-separated), defaults to standard args
+	Model         string            // model passed as --model, overrides a --model in Args; empty keeps Args as is
 	OutputHandler func(text string) // called for each text chunk, can be nil
 	Debug         bool              // enable debug output
 	ErrorPatterns []string          // patterns to detect in output (e.g., rate limit messages)
 	LimitPatterns []string          // patterns to detect rate limits (checked before error patterns)
+	OutputFilter  bool              // hide tool-call lead-ins and collapse repeats in displayed output
+	StripANSI     bool              // remove ANSI escape sequences from Result.Output, the display keeps them
+	IdleTimeout   time.Duration     // kill the session when no output line arrives for this long, 0 disables
 	cmdRunner     CommandRunner     // for testing, nil uses default
 }
 
@@ -203,7 +193,7 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 	// build args from configured string or use defaults
 	var args []string
 	if e.Args != "" {
-		args = splitArgs(e.Args)
+		args = cmdargs.Split(e.Args)
 	} else {
 		args = []string{
 			"--dangerously-skip-permissions",
@@ -211,6 +201,9 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 			"--verbose",
 		}
 	}
+	if e.Model != "" {
+		args = withModelArg(args, e.Model)
+	}
 	// always append --print to enable non-interactive mode; mirrors old -p flag that was
 	// always appended. wrapper scripts ignore unknown flags via '*) shift ;;' catch-all.
 	args = append(args, "--print")
@@ -224,17 +217,36 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 		runner = &execClaudeRunner{stdin: stdinReader}
 	}
 
-	stdout, wait, err := runner.Run(ctx, cmd, args...)
+	// the idle watchdog cancels runCtx, which kills the process group and unblocks the stream read
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var idle *idleWatchdog
+	if e.IdleTimeout > 0 {
+		idle = newIdleWatchdog(e.IdleTimeout, cancel)
+		defer idle.stop()
+	}
+
+	stdout, wait, err := runner.Run(runCtx, cmd, args...)
 	if err != nil {
 		return Result{Error: err}
 	}
 
-	result := e.parseStream(ctx, stdout)
+	result := e.parseStream(runCtx, stdout, idle)
+	idle.stop()
+
+	waitErr := wait()
+	if idle.fired() && ctx.Err() == nil {
+		// partial output of a killed session can't be trusted, drop its signal
+		result.Signal, result.SignalAmbiguous = "", false
+		result.Error = fmt.Errorf("%w: no output for %s", ErrIdleTimeout, e.IdleTimeout)
+		return result
+	}
 
-	if err := wait(); err != nil {
+	if err := waitErr; err != nil {
 		// check if it was context cancellation
 		if ctx.Err() != nil {
-			return Result{Output: result.Output, Signal: result.Signal, Error: ctx.Err()}
+			result.Error = ctx.Err()
+			return result
 		}
 		if result.Output == "" {
 			return Result{Error: fmt.Errorf("claude exited with error: %w", err)}
@@ -248,20 +260,14 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 
 	// check limit patterns first (higher priority)
 	if pattern := matchPattern(result.Output, e.LimitPatterns); pattern != "" {
-		return Result{
-			Output: result.Output,
-			Signal: result.Signal,
-			Error:  &LimitPatternError{Pattern: pattern, HelpCmd: "claude /usage"},
-		}
+		result.Error = &LimitPatternError{Pattern: pattern, HelpCmd: "claude /usage"}
+		return result
 	}
 
 	// check for error patterns in output
 	if pattern := matchPattern(result.Output, e.ErrorPatterns); pattern != "" {
-		return Result{
-			Output: result.Output,
-			Signal: result.Signal,
-			Error:  &PatternMatchError{Pattern: pattern, HelpCmd: "claude /usage"},
-		}
+		result.Error = &PatternMatchError{Pattern: pattern, HelpCmd: "claude /usage"}
+		return result
 	}
 
 	return result
@@ -270,11 +276,16 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 // parseStream reads and parses the JSON stream from claude CLI.
 // uses readLines internally, so there is no line length limit.
 // checks ctx.Done() between reads so cancellation is not blocked by slow pipe reads.
-func (e *ClaudeExecutor) parseStream(ctx context.Context, r io.Reader) Result {
+// every line read, including empty ones, restarts the idle watchdog (nil when disabled).
+func (e *ClaudeExecutor) parseStream(ctx context.Context, r io.Reader, idle *idleWatchdog) Result {
 	var output strings.Builder
-	var signal string
+	var filter *claudeOutputFilter
+	if e.OutputFilter {
+		filter = &claudeOutputFilter{}
+	}
 
 	err := readLines(ctx, r, func(line string) {
+		idle.reset()
 		if line == "" {
 			return
 		}
@@ -287,31 +298,85 @@ func (e *ClaudeExecutor) parseStream(ctx context.Context, r io.Reader) Result {
 			}
 			output.WriteString(line)
 			output.WriteString("\n")
-			if e.OutputHandler != nil {
-				e.OutputHandler(line + "\n")
-			}
+			e.display(filter, line+"\n")
 			return
 		}
 
 		text := e.extractText(&event)
 		if text != "" {
 			output.WriteString(text)
-			if e.OutputHandler != nil {
-				e.OutputHandler(text)
-			}
-
-			// check for signals in text
-			if sig := detectSignal(text); sig != "" {
-				signal = sig
-			}
+			e.display(filter, text)
 		}
 	})
 
+	// flush buffered filter output even on cancellation, so partial lines are not lost
+	if filter != nil && e.OutputHandler != nil {
+		if rest := filter.flush(); rest != "" {
+			e.OutputHandler(rest)
+		}
+	}
+
+	// strip and detect signals on the full output, text deltas may split lines and escape sequences across events
+	text := output.String()
+	if e.StripANSI {
+		text = stripANSI(text)
+	}
+	signal, ambiguous := detectSignal(text)
+	result := Result{Output: text, Signal: signal, SignalAmbiguous: ambiguous}
 	if err != nil {
-		return Result{Output: output.String(), Signal: signal, Error: fmt.Errorf("stream read: %w", err)}
+		result.Error = fmt.Errorf("stream read: %w", err)
 	}
+	return result
+}
 
-	return Result{Output: output.String(), Signal: signal}
+// idleWatchdog cancels a claude session when no output arrives within the timeout.
+// the timer restarts on every output line. a nil watchdog is disabled and all its methods are no-ops.
+type idleWatchdog struct {
+	timeout time.Duration
+	timer   *time.Timer
+	expired atomic.Bool
+}
+
+// newIdleWatchdog starts a watchdog that calls cancel after timeout without a reset.
+func newIdleWatchdog(timeout time.Duration, cancel context.CancelFunc) *idleWatchdog {
+	w := &idleWatchdog{timeout: timeout}
+	w.timer = time.AfterFunc(timeout, func() {
+		w.expired.Store(true)
+		cancel()
+	})
+	return w
+}
+
+// reset restarts the idle window, called for each output line.
+func (w *idleWatchdog) reset() {
+	if w != nil {
+		w.timer.Reset(w.timeout)
+	}
+}
+
+// stop disarms the watchdog once the stream is done.
+func (w *idleWatchdog) stop() {
+	if w != nil {
+		w.timer.Stop()
+	}
+}
+
+// fired reports whether the watchdog canceled the session.
+func (w *idleWatchdog) fired() bool {
+	return w != nil && w.expired.Load()
+}
+
+// display passes text to OutputHandler, through the output filter when enabled.
+func (e *ClaudeExecutor) display(filter *claudeOutputFilter, text string) {
+	if e.OutputHandler == nil {
+		return
+	}
+	if filter != nil {
+		text = filter.write(text)
+	}
+	if text != "" {
+		e.OutputHandler(text)
+	}
 }
 
 // extractText extracts text content from various event types.
@@ -358,22 +423,81 @@ func (e *ClaudeExecutor) extractText(event *streamEvent) string {
 	return ""
 }
 
-// detectSignal checks text for completion status.
-// looks for <<<RALPHEX:...>>> format status.
-func detectSignal(text string) string {
-	knownSignals := []string{
-		status.Completed,
-		status.Failed,
-		status.ReviewDone,
-		status.CodexDone,
-		status.PlanReady,
+// knownSignals lists the <<<RALPHEX:...>>> signals detected in executor output.
+var knownSignals = []string{
+	status.Completed,
+	status.Failed,
+	status.ReviewDone,
+	status.CodexDone,
+	status.PlanReady,
+}
+
+// detectSignal checks text for completion status in <<<RALPHEX:...>>> format.
+// a signal is accepted only if it stands on its own line or is part of the last meaningful line,
+// and it is ignored inside fenced code blocks, quoted lines ("> ...") and inline quotes (`sig`, "sig").
+// this prevents early detection when the model cites or explains a signal instead of emitting it.
+// if several distinct signals qualify, the last one wins and ambiguous is set.
+func detectSignal(text string) (signal string, ambiguous bool) {
+	lines := strings.Split(text, "\n")
+	lastIdx := len(lines) - 1
+	for lastIdx > 0 && strings.TrimSpace(lines[lastIdx]) == "" {
+		lastIdx--
+	}
+
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		if i != lastIdx && !slices.Contains(knownSignals, trimmed) {
+			continue // signal must be on its own line or on the last meaningful line
+		}
+		sig, multiple := lastSignalInLine(trimmed)
+		if sig == "" {
+			continue
+		}
+		if multiple || (signal != "" && signal != sig) {
+			ambiguous = true
+		}
+		signal = sig
 	}
+	return signal, ambiguous
+}
+
+// lastSignalInLine returns the right-most unquoted signal in line and whether
+// more than one distinct signal was found in it.
+func lastSignalInLine(line string) (signal string, multiple bool) {
+	bestPos := -1
 	for _, sig := range knownSignals {
-		if strings.Contains(text, sig) {
-			return sig
+		pos := strings.LastIndex(line, sig)
+		if pos < 0 || (pos > 0 && (line[pos-1] == '`' || line[pos-1] == '"')) {
+			continue
+		}
+		if signal != "" {
+			multiple = true
+		}
+		if pos > bestPos {
+			signal, bestPos = sig, pos
 		}
 	}
-	return ""
+	return signal, multiple
+}
+
+// ansiPattern matches ANSI escape sequences: CSI (colors, cursor moves), OSC (titles, links)
+// terminated by BEL or ST, and two-byte escapes.
+var ansiPattern = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[@-Z\\-_])`)
+
+// stripANSI removes ANSI escape sequences from s.
+func stripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	return ansiPattern.ReplaceAllString(s, "")
 }
 
 // matchPattern checks output for configured patterns.
@@ -9,6 +9,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -34,6 +35,42 @@ func TestClaudeExecutor_Run_Success(t *testing.T) {
 	assert.Equal(t, "<<<RALPHEX:ALL_TASKS_DONE>>>", result.Signal)
 }
 
+func TestClaudeExecutor_Run_AmbiguousSignal(t *testing.T) {
+	jsonStream := `{"type":"content_block_delta","delta":{"type":"text_delta","text":"<<<RALPHEX:TASK_FAILED>>>\nretrying\n"}}
+{"type":"content_block_delta","delta":{"type":"text_delta","text":"fixed <<<RALPHEX:ALL_TASKS_DONE>>>"}}`
+
+	mock := &mocks.CommandRunnerMock{
+		RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+			return strings.NewReader(jsonStream), func() error { return nil }, nil
+		},
+	}
+	e := &ClaudeExecutor{cmdRunner: mock}
+
+	result := e.Run(context.Background(), "test prompt")
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, status.Completed, result.Signal)
+	assert.True(t, result.SignalAmbiguous)
+}
+
+func TestClaudeExecutor_Run_SignalSplitAcrossDeltas(t *testing.T) {
+	jsonStream := `{"type":"content_block_delta","delta":{"type":"text_delta","text":"done\n<<<RALPHEX:ALL_"}}
+{"type":"content_block_delta","delta":{"type":"text_delta","text":"TASKS_DONE>>>"}}`
+
+	mock := &mocks.CommandRunnerMock{
+		RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+			return strings.NewReader(jsonStream), func() error { return nil }, nil
+		},
+	}
+	e := &ClaudeExecutor{cmdRunner: mock}
+
+	result := e.Run(context.Background(), "test prompt")
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, status.Completed, result.Signal)
+	assert.False(t, result.SignalAmbiguous)
+}
+
 func TestClaudeExecutor_Run_StartError(t *testing.T) {
 	mock := &mocks.CommandRunnerMock{
 		RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
@@ -114,6 +151,168 @@ func TestClaudeExecutor_Run_ContextCanceled(t *testing.T) {
 	require.ErrorIs(t, result.Error, context.Canceled)
 }
 
+func TestClaudeExecutor_Run_IdleTimeout(t *testing.T) {
+	// stallingRunner writes the given lines to a pipe and then stalls until the context is canceled,
+	// closing the pipe like the real runner does when it kills the process group
+	stallingRunner := func(lines ...string) *mocks.CommandRunnerMock {
+		return &mocks.CommandRunnerMock{
+			RunFunc: func(ctx context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+				pr, pw := io.Pipe()
+				go func() {
+					for _, line := range lines {
+						_, _ = pw.Write([]byte(line + "\n"))
+						time.Sleep(20 * time.Millisecond)
+					}
+					<-ctx.Done()
+					_ = pw.CloseWithError(ctx.Err())
+				}()
+				return pr, func() error { <-ctx.Done(); return errors.New("signal: killed") }, nil
+			},
+		}
+	}
+
+	t.Run("fires on stalled output", func(t *testing.T) {
+		e := &ClaudeExecutor{cmdRunner: stallingRunner(
+			`{"type":"content_block_delta","delta":{"type":"text_delta","text":"working\n"}}`,
+			`{"type":"content_block_delta","delta":{"type":"text_delta","text":"\n<<<RALPHEX:ALL_TASKS_DONE>>>"}}`,
+		), IdleTimeout: 100 * time.Millisecond}
+
+		start := time.Now()
+		result := e.Run(t.Context(), "test prompt")
+
+		require.ErrorIs(t, result.Error, ErrIdleTimeout)
+		assert.Contains(t, result.Error.Error(), "no output for 100ms")
+		assert.Contains(t, result.Output, "working")
+		assert.Empty(t, result.Signal, "signal of a killed session is dropped")
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("resets on each line", func(t *testing.T) {
+		lines := make([]string, 10) // 10 lines 20ms apart outlast the 50ms window unless it restarts
+		for i := range lines {
+			lines[i] = `{"type":"content_block_delta","delta":{"type":"text_delta","text":"."}}`
+		}
+		e := &ClaudeExecutor{cmdRunner: stallingRunner(lines...), IdleTimeout: 50 * time.Millisecond}
+
+		result := e.Run(t.Context(), "test prompt")
+
+		require.ErrorIs(t, result.Error, ErrIdleTimeout)
+		assert.Equal(t, "..........", result.Output, "all lines read before the watchdog fired")
+	})
+
+	t.Run("parent cancellation is not an idle timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+		e := &ClaudeExecutor{cmdRunner: stallingRunner(), IdleTimeout: time.Hour}
+
+		result := e.Run(ctx, "test prompt")
+
+		require.ErrorIs(t, result.Error, context.DeadlineExceeded)
+		assert.NotErrorIs(t, result.Error, ErrIdleTimeout)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		mock := &mocks.CommandRunnerMock{
+			RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+				return strings.NewReader(`{"type":"content_block_delta","delta":{"type":"text_delta","text":"done"}}`),
+					func() error { return nil }, nil
+			},
+		}
+		result := (&ClaudeExecutor{cmdRunner: mock}).Run(t.Context(), "test prompt")
+		require.NoError(t, result.Error)
+		assert.Equal(t, "done", result.Output)
+	})
+}
+
+func TestClaudeExecutor_Run_OutputFilter(t *testing.T) {
+	// representative stream: narration before tool calls, tool_use/tool_result events, repeated status lines
+	jsonStream := `{"type":"system","subtype":"init","session_id":"abc"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Let me check the existing tests:\n"},{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"a_test.go"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"package a"}]}}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Now let me run the tests:\n"},{"type":"tool_use","id":"t2","name":"Bash","input":{"command":"go test ./..."}}]}}
+{"type":"content_block_delta","delta":{"type":"text_delta","text":"Tests pass.\nTests pass.\n\n\n"}}
+{"type":"content_block_delta","delta":{"type":"text_delta","text":"Added validation for empty names.\n<<<RALPHEX:ALL_TASKS_DONE>>>"}}
+{"type":"result","result":"session summary"}`
+
+	run := func(filter bool) (Result, string) {
+		mock := &mocks.CommandRunnerMock{
+			RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+				return strings.NewReader(jsonStream), func() error { return nil }, nil
+			},
+		}
+		var shown strings.Builder
+		e := &ClaudeExecutor{cmdRunner: mock, OutputFilter: filter, OutputHandler: func(text string) { shown.WriteString(text) }}
+		return e.Run(context.Background(), "test prompt"), shown.String()
+	}
+
+	t.Run("filter enabled", func(t *testing.T) {
+		result, shown := run(true)
+		require.NoError(t, result.Error)
+		assert.Equal(t, status.Completed, result.Signal)
+		assert.Equal(t, "Tests pass.\n(previous line repeated 1x)\n\nAdded validation for empty names.\n<<<RALPHEX:ALL_TASKS_DONE>>>\n", shown)
+		assert.Contains(t, result.Output, "Let me check the existing tests:", "result output stays raw")
+	})
+
+	t.Run("filter disabled", func(t *testing.T) {
+		result, shown := run(false)
+		require.NoError(t, result.Error)
+		assert.Equal(t, status.Completed, result.Signal)
+		assert.Equal(t, result.Output, shown)
+		assert.Contains(t, shown, "Let me check the existing tests:")
+	})
+}
+
+func TestClaudeExecutor_Run_StripANSI(t *testing.T) {
+	// color codes split across deltas and wrapped around the signal
+	jsonStream := `{"type":"content_block_delta","delta":{"type":"text_delta","text":"\u001b[32mtests pass\u001b"}}
+{"type":"content_block_delta","delta":{"type":"text_delta","text":"[0m\n\u001b[1m<<<RALPHEX:ALL_TASKS_DONE>>>\u001b[0m"}}`
+
+	run := func(strip bool) (Result, string) {
+		mock := &mocks.CommandRunnerMock{
+			RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+				return strings.NewReader(jsonStream), func() error { return nil }, nil
+			},
+		}
+		var shown strings.Builder
+		e := &ClaudeExecutor{cmdRunner: mock, StripANSI: strip, OutputHandler: func(text string) { shown.WriteString(text) }}
+		return e.Run(context.Background(), "test prompt"), shown.String()
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		result, shown := run(true)
+		require.NoError(t, result.Error)
+		assert.Equal(t, "tests pass\n<<<RALPHEX:ALL_TASKS_DONE>>>", result.Output)
+		assert.Equal(t, status.Completed, result.Signal)
+		assert.Contains(t, shown, "\x1b[32mtests pass", "display keeps colors")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		result, _ := run(false)
+		require.NoError(t, result.Error)
+		assert.Contains(t, result.Output, "\x1b[32m")
+		assert.Equal(t, status.Completed, result.Signal)
+	})
+}
+
+func TestClaudeExecutor_parseStream_OutputFilterFlushesOnCancel(t *testing.T) {
+	jsonStream := `{"type":"content_block_delta","delta":{"type":"text_delta","text":"first line\nunfinished"}}
+{"type":"content_block_delta","delta":{"type":"text_delta","text":" never read\n"}}`
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var shown []string
+	e := &ClaudeExecutor{OutputFilter: true, OutputHandler: func(text string) {
+		shown = append(shown, text)
+		cancel() // cancel after first displayed chunk
+	}}
+
+	result := e.parseStream(ctx, strings.NewReader(jsonStream), nil)
+
+	require.ErrorIs(t, result.Error, context.Canceled)
+	assert.Equal(t, []string{"first line\n", "unfinished\n"}, shown)
+}
+
 func TestClaudeExecutor_Run_WithOutputHandler(t *testing.T) {
 	jsonStream := `{"type":"content_block_delta","delta":{"type":"text_delta","text":"chunk1"}}
 {"type":"content_block_delta","delta":{"type":"text_delta","text":"chunk2"}}`
@@ -221,7 +420,7 @@ func TestClaudeExecutor_parseStream(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			e := &ClaudeExecutor{}
-			result := e.parseStream(context.Background(), strings.NewReader(tc.input))
+			result := e.parseStream(context.Background(), strings.NewReader(tc.input), nil)
 
 			assert.Equal(t, tc.wantOutput, result.Output)
 			assert.Equal(t, tc.wantSignal, result.Signal)
@@ -240,7 +439,7 @@ func TestClaudeExecutor_parseStream_withHandler(t *testing.T) {
 		},
 	}
 
-	result := e.parseStream(context.Background(), strings.NewReader(input))
+	result := e.parseStream(context.Background(), strings.NewReader(input), nil)
 
 	assert.Equal(t, "chunk1chunk2", result.Output)
 	assert.Equal(t, []string{"chunk1", "chunk2"}, chunks)
@@ -251,7 +450,7 @@ func TestClaudeExecutor_parseStream_withDebug(t *testing.T) {
 	input := "not json\n" + `{"type":"content_block_delta","delta":{"type":"text_delta","text":"valid"}}`
 
 	e := &ClaudeExecutor{Debug: true}
-	result := e.parseStream(context.Background(), strings.NewReader(input))
+	result := e.parseStream(context.Background(), strings.NewReader(input), nil)
 
 	assert.Equal(t, "not json\nvalid", result.Output)
 }
@@ -344,22 +543,39 @@ func TestClaudeExecutor_extractText(t *testing.T) {
 
 func TestDetectSignal(t *testing.T) {
 	tests := []struct {
-		text string
-		want string
+		name          string
+		text          string
+		want          string
+		wantAmbiguous bool
 	}{
-		{"some text", ""},
-		{"task done " + status.Completed, status.Completed},
-		{status.Failed + " error", status.Failed},
-		{"review complete " + status.ReviewDone, status.ReviewDone},
-		{status.CodexDone + " analysis done", status.CodexDone},
-		{"plan complete " + status.PlanReady, status.PlanReady},
-		{"no signal here", ""},
+		{name: "no signal", text: "some text", want: ""},
+		{name: "completed at end", text: "task done " + status.Completed, want: status.Completed},
+		{name: "failed single line", text: status.Failed + " error", want: status.Failed},
+		{name: "review done", text: "review complete " + status.ReviewDone, want: status.ReviewDone},
+		{name: "codex done", text: status.CodexDone + " analysis done", want: status.CodexDone},
+		{name: "plan ready", text: "plan complete " + status.PlanReady, want: status.PlanReady},
+		{name: "no signal here", text: "no signal here", want: ""},
+		{name: "own line followed by summary", text: "work done\n" + status.Completed + "\n\nsummary of changes", want: status.Completed},
+		{name: "last line with trailing blank lines", text: "intro\nall good " + status.ReviewDone + "\n\n  \n", want: status.ReviewDone},
+		{name: "mid-line mention ignored", text: "I will emit " + status.Completed + " when finished\nstill working", want: ""},
+		{name: "inside code block ignored",
+			text: "example:\n```\n" + status.Completed + "\n```\ncontinuing work", want: ""},
+		{name: "quoted line ignored", text: "> " + status.Failed + "\nnot failed", want: ""},
+		{name: "inline backticks ignored", text: "next step outputs `" + status.Completed + "`", want: ""},
+		{name: "double quotes ignored", text: `prompt says "` + status.Completed + `"`, want: ""},
+		{name: "multiple distinct signals pick last",
+			text: status.Failed + "\nretried\n" + status.Completed, want: status.Completed, wantAmbiguous: true},
+		{name: "same signal repeated not ambiguous",
+			text: status.Completed + "\n" + status.Completed, want: status.Completed},
+		{name: "two signals on last line pick right-most",
+			text: "result: " + status.Completed + " " + status.Failed, want: status.Failed, wantAmbiguous: true},
 	}
 
 	for _, tc := range tests {
-		t.Run(tc.text, func(t *testing.T) {
-			got := detectSignal(tc.text)
+		t.Run(tc.name, func(t *testing.T) {
+			got, ambiguous := detectSignal(tc.text)
 			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantAmbiguous, ambiguous)
 		})
 	}
 }
@@ -430,27 +646,35 @@ func TestClaudeExecutor_Run_WithCustomCommandAndArgs(t *testing.T) {
 	assert.Equal(t, []string{"--skip-perms", "--verbose", "--print"}, capturedArgs)
 }
 
-func TestSplitArgs(t *testing.T) {
+func TestClaudeExecutor_Run_WithModel(t *testing.T) {
 	tests := []struct {
-		name  string
-		input string
-		want  []string
+		name string
+		args string
+		want []string
 	}{
-		{name: "simple args", input: "--flag1 --flag2 value", want: []string{"--flag1", "--flag2", "value"}},
-		{name: "double quoted", input: `--flag "value with spaces"`, want: []string{"--flag", "value with spaces"}},
-		{name: "single quoted", input: `--flag 'value with spaces'`, want: []string{"--flag", "value with spaces"}},
-		{name: "empty string", input: "", want: nil},
-		{name: "only spaces", input: "   ", want: nil},
-		{name: "multiple spaces between", input: "arg1   arg2", want: []string{"arg1", "arg2"}},
-		{name: "mixed quotes", input: `--a "b" --c 'd'`, want: []string{"--a", "b", "--c", "d"}},
-		{name: "escaped quote", input: `--flag \"quoted\"`, want: []string{"--flag", `"quoted"`}},
-		{name: "real claude args", input: "--dangerously-skip-permissions --output-format stream-json --verbose", want: []string{"--dangerously-skip-permissions", "--output-format", "stream-json", "--verbose"}},
+		{name: "default args", want: []string{"--dangerously-skip-permissions", "--output-format", "stream-json", "--verbose",
+			"--model", "opus", "--print"}},
+		{name: "custom args without model", args: "--verbose", want: []string{"--verbose", "--model", "opus", "--print"}},
+		{name: "replaces separate model value", args: "--model sonnet --verbose",
+			want: []string{"--verbose", "--model", "opus", "--print"}},
+		{name: "replaces model with equals", args: "--verbose --model=sonnet",
+			want: []string{"--verbose", "--model", "opus", "--print"}},
 	}
-
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := splitArgs(tc.input)
-			assert.Equal(t, tc.want, got)
+			var capturedArgs []string
+			mock := &mocks.CommandRunnerMock{
+				RunFunc: func(_ context.Context, _ string, args ...string) (io.Reader, func() error, error) {
+					capturedArgs = args
+					return strings.NewReader(`{"type":"content_block_delta","delta":{"type":"text_delta","text":"ok"}}`), func() error { return nil }, nil
+				},
+			}
+			e := &ClaudeExecutor{cmdRunner: mock, Args: tc.args, Model: "opus"}
+
+			result := e.Run(context.Background(), "test prompt")
+
+			require.NoError(t, result.Error)
+			assert.Equal(t, tc.want, capturedArgs)
 		})
 	}
 }
@@ -532,7 +756,7 @@ func TestClaudeExecutor_parseStream_largeLines(t *testing.T) {
 			jsonLine := `{"type":"content_block_delta","delta":{"type":"text_delta","text":"` + largeText + `"}}`
 
 			e := &ClaudeExecutor{}
-			result := e.parseStream(context.Background(), strings.NewReader(jsonLine))
+			result := e.parseStream(context.Background(), strings.NewReader(jsonLine), nil)
 
 			require.NoError(t, result.Error, "should handle %d byte line without error", tc.size)
 			assert.Len(t, result.Output, tc.size, "output should contain full text")
@@ -553,7 +777,7 @@ func TestClaudeExecutor_parseStream_multipleLargeLines(t *testing.T) {
 	input := strings.Join(lines, "\n")
 
 	e := &ClaudeExecutor{}
-	result := e.parseStream(context.Background(), strings.NewReader(input))
+	result := e.parseStream(context.Background(), strings.NewReader(input), nil)
 
 	require.NoError(t, result.Error)
 	assert.Len(t, result.Output, lineSize*numLines, "should contain all output from all lines")
@@ -564,6 +788,26 @@ func TestPatternMatchError_Error(t *testing.T) {
 	assert.Equal(t, `detected error pattern: "rate limit exceeded"`, err.Error())
 }
 
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{name: "plain text", in: "no escapes here", want: "no escapes here"},
+		{name: "sgr colors", in: "\x1b[1;31merror\x1b[0m: failed", want: "error: failed"},
+		{name: "cursor movement", in: "\x1b[2K\x1b[1Gprogress 50%", want: "progress 50%"},
+		{name: "private mode", in: "\x1b[?25lhidden cursor\x1b[?25h", want: "hidden cursor"},
+		{name: "osc hyperlink", in: "\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", want: "link"},
+		{name: "osc title with bel", in: "\x1b]0;title\x07text", want: "text"},
+		{name: "two-byte escape", in: "\x1bMline", want: "line"},
+		{name: "multiline", in: "\x1b[32mok\x1b[0m\n\x1b[33mwarn\x1b[0m\n", want: "ok\nwarn\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, stripANSI(tc.in))
+		})
+	}
+}
+
 func TestMatchPattern(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -756,6 +1000,12 @@ func TestLimitPatternError_Error(t *testing.T) {
 	assert.Equal(t, `detected limit pattern: "You've hit your limit"`, err.Error())
 }
 
+func TestLimitPatternError_IsUsageLimited(t *testing.T) {
+	err := fmt.Errorf("run: %w", &LimitPatternError{Pattern: "rate limit", HelpCmd: "claude /usage"})
+	require.ErrorIs(t, err, ErrUsageLimited)
+	require.NotErrorIs(t, &PatternMatchError{Pattern: "API Error:"}, ErrUsageLimited)
+}
+
 // printFlag is registered so the test binary accepts --print without erroring.
 // ClaudeExecutor.Run() always appends --print to the command args; when the test
 // binary is used as the subprocess command, this flag must be registered.
@@ -0,0 +1,109 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// execHookRunner is the default command runner for hooks using os/exec.
+// env is appended to the current process environment when non-empty.
+type execHookRunner struct {
+	env []string
+}
+
+func (r *execHookRunner) Run(ctx context.Context, name string, args ...string) (io.Reader, func() error, error) {
+	// check context before starting to avoid spawning a process that will be immediately killed
+	if err := ctx.Err(); err != nil {
+		return nil, nil, fmt.Errorf("context already canceled: %w", err)
+	}
+
+	// use exec.Command (not CommandContext) because we handle cancellation ourselves
+	// to ensure the entire process group is killed, not just the direct child
+	cmd := exec.Command(name, args...) //nolint:noctx // intentional: we handle context cancellation via process group kill
+	if len(r.env) > 0 {
+		cmd.Env = append(os.Environ(), r.env...)
+	}
+
+	// create new process group so we can kill all descendants on cleanup
+	setupProcessGroup(cmd)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("stdout pipe: %w", err)
+	}
+	// merge stderr into stdout
+	cmd.Stderr = cmd.Stdout
+
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("start command: %w", err)
+	}
+
+	// setup process group cleanup with graceful shutdown on context cancellation
+	cleanup := newProcessGroupCleanup(cmd, ctx.Done())
+
+	return stdout, cleanup.Wait, nil
+}
+
+// HookExecutor runs a user-defined shell command or script, used for run hooks and hooks directory scripts.
+// the command is passed to the system shell (sh -c, or cmd /C on windows), so pipes and && work.
+// a script is executed directly and takes precedence over the command.
+type HookExecutor struct {
+	Command       string            // shell command line to run
+	Script        string            // path of an executable to run directly instead of Command
+	Env           []string          // extra environment variables as KEY=VALUE, added to the current environment
+	OutputHandler func(text string) // called for each output line, can be nil
+	runner        CommandRunner     // for testing, nil uses default
+}
+
+// SetRunner sets the command runner for testing purposes.
+func (e *HookExecutor) SetRunner(r CommandRunner) {
+	e.runner = r
+}
+
+// Run executes the hook script or command, streaming combined stdout/stderr to OutputHandler.
+// returns an error if the command can't be started or exits with non-zero status.
+func (e *HookExecutor) Run(ctx context.Context) error {
+	if e.Command == "" && e.Script == "" {
+		return errors.New("hook command not configured")
+	}
+
+	runner := e.runner
+	if runner == nil {
+		runner = &execHookRunner{env: e.Env}
+	}
+
+	name, args := e.Script, []string(nil)
+	if e.Script == "" {
+		name, args = "sh", []string{"-c", e.Command}
+		if runtime.GOOS == "windows" {
+			name, args = "cmd", []string{"/C", e.Command}
+		}
+	}
+
+	stdout, wait, err := runner.Run(ctx, name, args...)
+	if err != nil {
+		return fmt.Errorf("start hook: %w", err)
+	}
+
+	streamErr := readLines(ctx, stdout, func(line string) {
+		if e.OutputHandler != nil {
+			e.OutputHandler(line + "\n")
+		}
+	})
+	waitErr := wait()
+
+	switch {
+	case ctx.Err() != nil:
+		return fmt.Errorf("context error: %w", ctx.Err())
+	case waitErr != nil:
+		return fmt.Errorf("hook exited with error: %w", waitErr)
+	case streamErr != nil:
+		return fmt.Errorf("read hook output: %w", streamErr)
+	}
+	return nil
+}
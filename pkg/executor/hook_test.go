@@ -0,0 +1,110 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor/mocks"
+)
+
+func TestHookExecutor_Run(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		waitErr  error
+		startErr error
+		wantErr  string
+		wantOut  []string
+	}{
+		{name: "success streams output", output: "installing\ndone\n", wantOut: []string{"installing\n", "done\n"}},
+		{name: "non-zero exit", output: "boom\n", waitErr: errors.New("exit status 1"),
+			wantErr: "hook exited with error: exit status 1", wantOut: []string{"boom\n"}},
+		{name: "start failure", startErr: errors.New("no shell"), wantErr: "start hook: no shell"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mocks.CommandRunnerMock{
+				RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+					if tc.startErr != nil {
+						return nil, nil, tc.startErr
+					}
+					return strings.NewReader(tc.output), func() error { return tc.waitErr }, nil
+				},
+			}
+			var out []string
+			e := &HookExecutor{Command: "make deps && make up", OutputHandler: func(s string) { out = append(out, s) }}
+			e.SetRunner(mock)
+
+			err := e.Run(context.Background())
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantOut, out)
+
+			require.Len(t, mock.RunCalls(), 1)
+			assert.Equal(t, "make deps && make up", mock.RunCalls()[0].Args[len(mock.RunCalls()[0].Args)-1])
+		})
+	}
+}
+
+func TestHookExecutor_Run_Script(t *testing.T) {
+	mock := &mocks.CommandRunnerMock{
+		RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+			return strings.NewReader("ok\n"), func() error { return nil }, nil
+		},
+	}
+	e := &HookExecutor{Command: "ignored", Script: "/repo/.ralphex/hooks/pre-task"}
+	e.SetRunner(mock)
+
+	require.NoError(t, e.Run(t.Context()))
+	require.Len(t, mock.RunCalls(), 1)
+	assert.Equal(t, "/repo/.ralphex/hooks/pre-task", mock.RunCalls()[0].Name)
+	assert.Empty(t, mock.RunCalls()[0].Args)
+}
+
+func TestHookExecutor_Run_ScriptEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh script")
+	}
+
+	script := filepath.Join(t.TempDir(), "hook")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho \"phase=$RALPHEX_PHASE\"\n"), 0o700))
+
+	var out strings.Builder
+	e := &HookExecutor{Script: script, Env: []string{"RALPHEX_PHASE=review"}, OutputHandler: func(s string) { out.WriteString(s) }}
+	require.NoError(t, e.Run(t.Context()))
+	assert.Equal(t, "phase=review\n", out.String())
+}
+
+func TestHookExecutor_Run_NoCommand(t *testing.T) {
+	e := &HookExecutor{}
+	require.EqualError(t, e.Run(context.Background()), "hook command not configured")
+}
+
+func TestHookExecutor_Run_Shell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh syntax")
+	}
+
+	var out strings.Builder
+	e := &HookExecutor{Command: "echo one && echo two >&2", OutputHandler: func(s string) { out.WriteString(s) }}
+	require.NoError(t, e.Run(t.Context()))
+	assert.Equal(t, "one\ntwo\n", out.String())
+
+	e = &HookExecutor{Command: "exit 3"}
+	err := e.Run(t.Context())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exit status 3")
+}
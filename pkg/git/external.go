@@ -1,6 +1,7 @@
 package git
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -11,6 +12,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // externalBackend implements the backend interface by shelling out to the git CLI.
@@ -54,8 +56,16 @@ func newExternalBackend(path, command string) (*externalBackend, error) {
 // leading whitespace is preserved (important for porcelain format parsing).
 // on failure, returns error with the combined output for diagnostics.
 func (e *externalBackend) run(args ...string) (string, error) {
+	return e.runEnv(nil, args...)
+}
+
+// runEnv is run with extra environment variables, e.g. GIT_INDEX_FILE, added to the inherited environment.
+func (e *externalBackend) runEnv(env []string, args ...string) (string, error) {
 	cmd := exec.CommandContext(context.Background(), e.command, args...)
 	cmd.Dir = e.path
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		msg := strings.TrimSpace(string(out))
@@ -166,6 +176,47 @@ func (e *externalBackend) currentBranch() (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// upstream returns the short name of the branch's upstream (e.g. "origin/feature"), or empty if none is configured.
+func (e *externalBackend) upstream(branch string) (string, error) {
+	out, err := e.run("for-each-ref", "--format=%(upstream:short)", "refs/heads/"+branch)
+	if err != nil {
+		return "", fmt.Errorf("get upstream: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// remotes returns the names of the configured remotes.
+func (e *externalBackend) remotes() ([]string, error) {
+	out, err := e.run("remote")
+	if err != nil {
+		return nil, fmt.Errorf("list remotes: %w", err)
+	}
+	return strings.Fields(out), nil
+}
+
+// remoteURL returns the fetch URL of the named remote.
+func (e *externalBackend) remoteURL(name string) (string, error) {
+	out, err := e.run("remote", "get-url", name)
+	if err != nil {
+		return "", fmt.Errorf("get remote url: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// push pushes refspec to remote without prompting for credentials.
+// updates the remote refuses, e.g. non-fast-forward ones, are reported as ErrPushRejected.
+func (e *externalBackend) push(remote, refspec string) error {
+	if _, err := e.runEnv([]string{"GIT_TERMINAL_PROMPT=0"}, "push", remote, refspec); err != nil {
+		msg := err.Error()
+		if strings.Contains(msg, "[rejected]") || strings.Contains(msg, "[remote rejected]") ||
+			strings.Contains(msg, "non-fast-forward") {
+			return fmt.Errorf("%w: %w", ErrPushRejected, err)
+		}
+		return fmt.Errorf("push: %w", err)
+	}
+	return nil
+}
+
 // getDefaultBranch returns the default branch name.
 // detects from origin/HEAD symbolic reference, falls back to checking common branch names.
 func (e *externalBackend) getDefaultBranch() string {
@@ -221,6 +272,30 @@ func (e *externalBackend) checkoutBranch(name string) error {
 	return nil
 }
 
+// isAncestor checks if ancestor is reachable from ref, i.e. all its commits are already in ref.
+func (e *externalBackend) isAncestor(ancestor, ref string) (bool, error) {
+	cmd := exec.CommandContext(context.Background(), e.command, "merge-base", "--is-ancestor", ancestor, ref)
+	cmd.Dir = e.path
+	err := cmd.Run()
+	if err == nil {
+		return true, nil
+	}
+	// exit 1 = not an ancestor, other codes = error
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, fmt.Errorf("merge-base: %w", err)
+}
+
+// resetBranch points an existing branch that is not checked out at ref.
+func (e *externalBackend) resetBranch(name, ref string) error {
+	if _, err := e.run("branch", "-f", name, ref); err != nil {
+		return fmt.Errorf("reset branch: %w", err)
+	}
+	return nil
+}
+
 // isDirty returns true if the worktree has uncommitted changes (staged or modified tracked files).
 func (e *externalBackend) isDirty() (bool, error) {
 	out, err := e.run("status", "--porcelain")
@@ -262,11 +337,14 @@ func (e *externalBackend) fileHasChanges(path string) (bool, error) {
 
 // hasChangesOtherThan returns the list of dirty file paths (excluding the given file).
 // this includes modified/deleted tracked files, staged changes, and untracked files (excluding gitignored).
-// an empty slice means no other changes.
+// an empty path excludes nothing. an empty slice means no other changes.
 func (e *externalBackend) hasChangesOtherThan(path string) ([]string, error) {
-	rel, err := e.toRelative(path)
-	if err != nil {
-		return nil, err
+	var rel string
+	if path != "" {
+		var err error
+		if rel, err = e.toRelative(path); err != nil {
+			return nil, err
+		}
 	}
 
 	// use -uall to list individual files, not collapsed directories
@@ -294,6 +372,68 @@ func (e *externalBackend) hasChangesOtherThan(path string) ([]string, error) {
 	return dirty, nil
 }
 
+// workingTreeStatus returns uncommitted changes with per-file line counts.
+// tracked files are counted against HEAD (staged and unstaged together), untracked files by their line count.
+func (e *externalBackend) workingTreeStatus() ([]FileChange, error) {
+	// use -uall to list individual files, not collapsed directories
+	out, err := e.run("status", "--porcelain", "-uall")
+	if err != nil {
+		return nil, fmt.Errorf("get status: %w", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+
+	// line counts for tracked files; an unborn HEAD has nothing to diff against
+	counts := map[string][2]int{}
+	if hasCommits, _ := e.hasCommits(); hasCommits {
+		numstat, numErr := e.run("diff", "--numstat", "--no-renames", "HEAD")
+		if numErr != nil {
+			return nil, fmt.Errorf("diff numstat: %w", numErr)
+		}
+		for line := range strings.SplitSeq(numstat, "\n") {
+			parts := strings.SplitN(line, "\t", 3)
+			if len(parts) < 3 {
+				continue
+			}
+			// binary files show "-" for additions/deletions and stay at zero
+			additions, _ := strconv.Atoi(parts[0])
+			deletions, _ := strconv.Atoi(parts[1])
+			counts[parts[2]] = [2]int{additions, deletions}
+		}
+	}
+
+	var changes []FileChange
+	for line := range strings.SplitSeq(out, "\n") {
+		path := e.extractPathFromPorcelain(line)
+		if path == "" {
+			continue
+		}
+		change := FileChange{Path: path, Status: strings.TrimSpace(line[:2])}
+		if change.Status == "??" {
+			change.Additions = countLines(filepath.Join(e.path, path))
+		} else {
+			change.Additions, change.Deletions = counts[path][0], counts[path][1]
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// countLines returns the number of lines in a text file.
+// returns 0 for binary files (containing NUL bytes) and files that can't be read.
+func countLines(path string) int {
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from git status of the repository
+	if err != nil || len(data) == 0 || bytes.IndexByte(data, 0) >= 0 {
+		return 0
+	}
+	n := bytes.Count(data, []byte{'\n'})
+	if data[len(data)-1] != '\n' {
+		n++ // last line without trailing newline
+	}
+	return n
+}
+
 // isIgnored checks if a path is ignored by gitignore rules.
 func (e *externalBackend) isIgnored(path string) (bool, error) {
 	cmd := exec.CommandContext(context.Background(), e.command, "check-ignore", "-q", "--", path)
@@ -371,6 +511,144 @@ func (e *externalBackend) commitFiles(msg string, paths ...string) error {
 	return nil
 }
 
+// commitToOrphanBranch commits the files to a branch on top of its own history, using a temporary index
+// so HEAD, the index and the working tree stay untouched. the files keep their repository-relative paths,
+// other files already on the branch are kept. a missing branch is created as an orphan (no parent commit).
+// returns false if the branch already has the files with the same content.
+func (e *externalBackend) commitToOrphanBranch(branch, msg string, paths ...string) (bool, error) {
+	tmpDir, err := os.MkdirTemp("", "ralphex-index-")
+	if err != nil {
+		return false, fmt.Errorf("create temp index dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmpDir, "index")}
+
+	ref := "refs/heads/" + branch
+	parent := ""
+	if e.refExists(ref) {
+		if parent, err = e.run("rev-parse", "--verify", ref+"^{commit}"); err != nil {
+			return false, fmt.Errorf("resolve branch: %w", err)
+		}
+		if _, err = e.runEnv(env, "read-tree", parent); err != nil {
+			return false, fmt.Errorf("read branch tree: %w", err)
+		}
+	}
+
+	for _, p := range paths {
+		rel, relErr := e.toRelative(p)
+		if relErr != nil {
+			return false, relErr
+		}
+		blob, hashErr := e.run("hash-object", "-w", "--", rel)
+		if hashErr != nil {
+			return false, fmt.Errorf("store %s: %w", rel, hashErr)
+		}
+		if _, err = e.runEnv(env, "update-index", "--add", "--cacheinfo", "100644,"+blob+","+filepath.ToSlash(rel)); err != nil {
+			return false, fmt.Errorf("stage %s: %w", rel, err)
+		}
+	}
+
+	tree, err := e.runEnv(env, "write-tree")
+	if err != nil {
+		return false, fmt.Errorf("write tree: %w", err)
+	}
+	args := []string{"commit-tree", tree, "-m", msg}
+	if parent != "" {
+		parentTree, treeErr := e.run("rev-parse", parent+"^{tree}")
+		if treeErr != nil {
+			return false, fmt.Errorf("resolve branch tree: %w", treeErr)
+		}
+		if parentTree == tree {
+			return false, nil
+		}
+		args = append(args, "-p", parent)
+	}
+	commit, err := e.run(args...)
+	if err != nil {
+		return false, fmt.Errorf("commit tree: %w", err)
+	}
+
+	// the old value makes the update fail if the branch moved (or appeared) since it was read
+	if _, err := e.run("update-ref", "-m", msg, ref, commit, parent); err != nil {
+		return false, fmt.Errorf("update branch: %w", err)
+	}
+	return true, nil
+}
+
+// tagExists checks if a tag with the given name exists.
+func (e *externalBackend) tagExists(name string) bool {
+	return e.refExists("refs/tags/" + name)
+}
+
+// createTag creates an annotated tag on HEAD.
+func (e *externalBackend) createTag(name, message string) error {
+	if _, err := e.run("tag", "-a", name, "-m", message); err != nil {
+		return fmt.Errorf("tag: %w", err)
+	}
+	return nil
+}
+
+// latestTagMatching returns the tag matching the glob pattern that is nearest to HEAD in its history.
+// returns empty string if no matching tag is reachable from HEAD.
+func (e *externalBackend) latestTagMatching(pattern string) (string, error) {
+	// describe fails when nothing matches, list first to tell that apart from real errors
+	out, err := e.run("tag", "--list", pattern, "--merged", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("list tags: %w", err)
+	}
+	if strings.TrimSpace(out) == "" {
+		return "", nil
+	}
+	tag, err := e.run("describe", "--tags", "--abbrev=0", "--match", pattern, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("describe: %w", err)
+	}
+	return strings.TrimSpace(tag), nil
+}
+
+// stashPush stashes uncommitted changes, including untracked files, except the excluded paths.
+// returns false if there was nothing to stash.
+func (e *externalBackend) stashPush(message string, exclude ...string) (bool, error) {
+	before := e.stashHash()
+	args := []string{"stash", "push", "--include-untracked", "-m", message}
+	if len(exclude) > 0 {
+		args = append(args, "--")
+		for _, path := range exclude {
+			rel, err := e.toRelative(path)
+			if err != nil {
+				return false, err
+			}
+			args = append(args, ":(exclude)"+rel)
+		}
+	}
+	if _, err := e.run(args...); err != nil {
+		return false, fmt.Errorf("stash push: %w", err)
+	}
+	// git exits 0 with "No local changes to save" when nothing is stashed, so compare stash refs
+	return e.stashHash() != before, nil
+}
+
+// stashPop applies the latest stash entry and drops it.
+// on conflict git keeps the entry, so the changes are never lost.
+func (e *externalBackend) stashPop() error {
+	if e.stashHash() == "" {
+		return ErrNoStash
+	}
+	if _, err := e.run("stash", "pop"); err != nil {
+		return fmt.Errorf("stash pop: %w", err)
+	}
+	return nil
+}
+
+// stashHash returns the hash of the latest stash entry, or empty string if the stash is empty.
+func (e *externalBackend) stashHash() string {
+	out, err := e.run("rev-parse", "-q", "--verify", "refs/stash")
+	if err != nil {
+		return ""
+	}
+	return out
+}
+
 // createInitialCommit stages all non-ignored files and creates an initial commit.
 func (e *externalBackend) createInitialCommit(msg string) error {
 	// git add -A respects .gitignore natively
@@ -395,41 +673,82 @@ func (e *externalBackend) createInitialCommit(msg string) error {
 	return nil
 }
 
-// diffStats returns change statistics between baseBranch and HEAD.
-// returns zero stats if baseBranch doesn't exist or HEAD equals baseBranch.
-func (e *externalBackend) diffStats(baseBranch string) (DiffStats, error) {
+// diffBaseRef resolves baseBranch to a ref usable for baseRef...HEAD diffs.
+// returns empty string if baseBranch doesn't exist, HEAD is missing, or HEAD equals baseBranch.
+func (e *externalBackend) diffBaseRef(baseBranch string) string {
 	// check if base branch exists (try local, remote, origin/ prefix)
 	baseRef := e.resolveRef(baseBranch)
 	if baseRef == "" {
-		return DiffStats{}, nil
+		return ""
 	}
 
 	// check if HEAD equals base
 	headHash, err := e.headHash()
 	if err != nil {
-		return DiffStats{}, nil //nolint:nilerr // no HEAD means no stats
+		return "" // no HEAD means no stats
 	}
 
 	baseCmd := exec.CommandContext(context.Background(), e.command, "rev-parse", baseRef)
 	baseCmd.Dir = e.path
 	baseOut, err := baseCmd.Output()
-	if err != nil {
-		return DiffStats{}, nil //nolint:nilerr // can't resolve base, return zero
+	if err != nil || strings.TrimSpace(string(baseOut)) == headHash {
+		return ""
 	}
-	if strings.TrimSpace(string(baseOut)) == headHash {
+	return baseRef
+}
+
+// diffStats returns change statistics between baseBranch and HEAD, without the exclude paths.
+// returns zero stats if baseBranch doesn't exist or HEAD equals baseBranch.
+func (e *externalBackend) diffStats(baseBranch string, exclude ...string) (DiffStats, error) {
+	baseRef := e.diffBaseRef(baseBranch)
+	if baseRef == "" {
 		return DiffStats{}, nil
 	}
 
 	// get numstat
-	out, err := e.run("diff", "--numstat", baseRef+"...HEAD")
+	out, err := e.run(append([]string{"diff", "--numstat", baseRef + "...HEAD"}, e.excludePathspec(exclude)...)...)
 	if err != nil {
 		return DiffStats{}, fmt.Errorf("diff numstat: %w", err)
 	}
+	return parseNumstat(out), nil
+}
 
-	if out == "" {
+// diffStatsRange returns change statistics between two commits, e.g. the commits before and after a task.
+// an empty to compares from against the working tree, staged and unstaged changes included.
+func (e *externalBackend) diffStatsRange(from, to string, exclude ...string) (DiffStats, error) {
+	if from == to {
 		return DiffStats{}, nil
 	}
+	args := []string{"diff", "--numstat", from}
+	if to != "" {
+		args = append(args, to)
+	}
+	out, err := e.run(append(args, e.excludePathspec(exclude)...)...)
+	if err != nil {
+		return DiffStats{}, fmt.Errorf("diff numstat %s..%s: %w", from, to, err)
+	}
+	return parseNumstat(out), nil
+}
 
+// excludePathspec returns the "--" separator followed by pathspecs that select the whole tree except
+// the exclude paths. paths outside the repository can't show up in a diff and are skipped.
+func (e *externalBackend) excludePathspec(exclude []string) []string {
+	res := []string{"--", ":/"}
+	for _, path := range exclude {
+		rel, err := e.toRelative(path)
+		if err != nil || rel == "." {
+			continue
+		}
+		res = append(res, ":(exclude)"+rel)
+	}
+	if len(res) == 2 {
+		return res[:1]
+	}
+	return res
+}
+
+// parseNumstat sums up "git diff --numstat" output. binary files count as changed files with no lines.
+func parseNumstat(out string) DiffStats {
 	var result DiffStats
 	for line := range strings.SplitSeq(out, "\n") {
 		if line == "" {
@@ -450,9 +769,255 @@ func (e *externalBackend) diffStats(baseBranch string) (DiffStats, error) {
 		result.Additions += additions
 		result.Deletions += deletions
 	}
+	return result
+}
+
+// diffStatsPerFile returns per-file change statistics between baseBranch and HEAD, in git's path order.
+// renames are detected and reported with OldPath set, binary files have Binary set and zero line counts.
+// returns nil if baseBranch doesn't exist or HEAD equals baseBranch.
+func (e *externalBackend) diffStatsPerFile(baseBranch string, exclude ...string) ([]FileDiffStat, error) {
+	baseRef := e.diffBaseRef(baseBranch)
+	if baseRef == "" {
+		return nil, nil
+	}
+
+	// -z keeps paths verbatim; a rename is "adds\tdels\t\0old\0new\0", other entries are "adds\tdels\tpath\0"
+	args := []string{"diff", "--numstat", "-z", "-M", baseRef + "...HEAD"}
+	out, err := e.run(append(args, e.excludePathspec(exclude)...)...)
+	if err != nil {
+		return nil, fmt.Errorf("diff numstat: %w", err)
+	}
+
+	var result []FileDiffStat
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		stat := FileDiffStat{Path: parts[2]}
+		if stat.Path == "" && i+2 < len(fields) { // rename: old and new paths follow as separate fields
+			stat.OldPath, stat.Path = fields[i+1], fields[i+2]
+			i += 2
+		}
+		// binary files show "-" for additions/deletions
+		if parts[0] == "-" || parts[1] == "-" {
+			stat.Binary = true
+		} else {
+			stat.Additions, _ = strconv.Atoi(parts[0])
+			stat.Deletions, _ = strconv.Atoi(parts[1])
+		}
+		result = append(result, stat)
+	}
+	return result, nil
+}
+
+// changedFiles returns the files changed between baseBranch and HEAD with their status.
+// returns nil if baseBranch doesn't exist or HEAD equals baseBranch.
+func (e *externalBackend) changedFiles(baseBranch string) ([]ChangedFile, error) {
+	baseRef := e.diffBaseRef(baseBranch)
+	if baseRef == "" {
+		return nil, nil
+	}
+
+	// -z keeps paths verbatim; a rename is "R<score>\0old\0new\0", other entries are "<status>\0path\0"
+	out, err := e.run("diff", "--name-status", "-z", "-M", baseRef+"...HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("diff name-status: %w", err)
+	}
+
+	var result []ChangedFile
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		code := fields[i]
+		change := ChangedFile{Path: fields[i+1], Status: StatusModified}
+		switch {
+		case strings.HasPrefix(code, "R") && i+2 < len(fields):
+			change = ChangedFile{Path: fields[i+2], OldPath: fields[i+1], Status: StatusRenamed}
+			i++
+		case strings.HasPrefix(code, "C") && i+2 < len(fields): // copies are reported as additions of the new path
+			change = ChangedFile{Path: fields[i+2], Status: StatusAdded}
+			i++
+		case code == "A":
+			change.Status = StatusAdded
+		case code == "D":
+			change.Status = StatusDeleted
+		}
+		result = append(result, change)
+	}
 	return result, nil
 }
 
+// countAheadBehind counts commits on each side of baseBranch...HEAD.
+// uses the same ref resolution as diffStats, so a missing base or HEAD equal to base yields zeros.
+func (e *externalBackend) countAheadBehind(baseBranch string) (ahead, behind int, err error) {
+	baseRef := e.diffBaseRef(baseBranch)
+	if baseRef == "" {
+		return 0, 0, nil
+	}
+
+	// left side counts commits only in base (behind), right side commits only in HEAD (ahead)
+	out, err := e.run("rev-list", "--left-right", "--count", baseRef+"...HEAD")
+	if err != nil {
+		return 0, 0, fmt.Errorf("rev-list: %w", err)
+	}
+	parts := strings.Fields(out)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output %q", out)
+	}
+	if behind, err = strconv.Atoi(parts[0]); err != nil {
+		return 0, 0, fmt.Errorf("parse behind count: %w", err)
+	}
+	if ahead, err = strconv.Atoi(parts[1]); err != nil {
+		return 0, 0, fmt.Errorf("parse ahead count: %w", err)
+	}
+	return ahead, behind, nil
+}
+
+// commitLog returns "<short-hash> <subject>" lines for commits in baseBranch..HEAD, newest first.
+// returns nil if the base branch can't be resolved or the repository has no commits.
+func (e *externalBackend) commitLog(baseBranch string) ([]string, error) {
+	baseRef := e.resolveRef(baseBranch)
+	if baseRef == "" {
+		return nil, nil
+	}
+
+	hasCommits, err := e.hasCommits()
+	if err != nil {
+		return nil, fmt.Errorf("check commits: %w", err)
+	}
+	if !hasCommits {
+		return nil, nil
+	}
+
+	out, err := e.run("log", "--oneline", "--no-decorate", baseRef+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("git log: %w", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+
+	var lines []string
+	for line := range strings.SplitSeq(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// inProgressOperation detects an interrupted rebase, merge or cherry-pick via state files in the git dir
+// and returns the modification time of the state file, which is never earlier than the start of the operation.
+// rebase-apply with an "applying" marker belongs to git am and is ignored.
+func (e *externalBackend) inProgressOperation() (op string, started time.Time, err error) {
+	checks := []struct {
+		path string
+		op   string
+	}{
+		{path: "rebase-merge", op: OpRebase},
+		{path: "rebase-apply", op: OpRebase},
+		{path: "MERGE_HEAD", op: OpMerge},
+		{path: "CHERRY_PICK_HEAD", op: OpCherryPick},
+	}
+	for _, c := range checks {
+		path, pathErr := e.gitPath(c.path)
+		if pathErr != nil {
+			return "", time.Time{}, pathErr
+		}
+		info, statErr := os.Stat(path)
+		if statErr != nil {
+			continue
+		}
+		if c.path == "rebase-apply" {
+			if _, err := os.Stat(filepath.Join(path, "applying")); err == nil {
+				continue // git am session, not a rebase
+			}
+		}
+		return c.op, info.ModTime(), nil
+	}
+	return "", time.Time{}, nil
+}
+
+// isShallow reports whether the repository is a shallow clone, detected by the shallow file in the git dir.
+func (e *externalBackend) isShallow() (bool, error) {
+	path, err := e.gitPath("shallow")
+	if err != nil {
+		return false, err
+	}
+	_, statErr := os.Stat(path)
+	return statErr == nil, nil
+}
+
+// unshallow fetches the missing history of a shallow clone.
+func (e *externalBackend) unshallow() error {
+	if _, err := e.run("fetch", "--unshallow"); err != nil {
+		return fmt.Errorf("fetch --unshallow: %w", err)
+	}
+	return nil
+}
+
+// blameLine returns the author and short hash of the commit that last changed a line of file.
+// uncommitted lines, reported by git with an all-zero hash, return empty author and commit.
+func (e *externalBackend) blameLine(file string, line int) (author, commit string, err error) {
+	if line < 1 {
+		return "", "", fmt.Errorf("invalid line %d", line)
+	}
+	out, err := e.run("blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", line, line), "--", file)
+	if err != nil {
+		return "", "", fmt.Errorf("blame %s:%d: %w", file, line, err)
+	}
+	lines := strings.Split(out, "\n")
+	hash, _, _ := strings.Cut(lines[0], " ")
+	if strings.Trim(hash, "0") == "" {
+		return "", "", nil
+	}
+	for _, l := range lines[1:] {
+		if name, ok := strings.CutPrefix(l, "author "); ok {
+			author = name
+			break
+		}
+	}
+	return author, hash[:min(len(hash), 7)], nil
+}
+
+// abortOperation runs "<op> --abort" for a rebase, merge or cherry-pick.
+func (e *externalBackend) abortOperation(op string) error {
+	switch op {
+	case OpRebase, OpMerge, OpCherryPick:
+	default:
+		return fmt.Errorf("unsupported operation %q", op)
+	}
+	if _, err := e.run(op, "--abort"); err != nil {
+		return fmt.Errorf("%s --abort: %w", op, err)
+	}
+	return nil
+}
+
+// resetHard resolves ref to a commit and resets HEAD, index and working tree to it.
+func (e *externalBackend) resetHard(ref string) error {
+	hash, err := e.run("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("unknown commit %q", ref)
+	}
+	if _, err := e.run("reset", "--hard", hash); err != nil {
+		return fmt.Errorf("reset: %w", err)
+	}
+	return nil
+}
+
+// gitPath resolves a path inside the git directory (works for worktrees and custom GIT_DIR).
+func (e *externalBackend) gitPath(name string) (string, error) {
+	out, err := e.run("rev-parse", "--git-path", name)
+	if err != nil {
+		return "", fmt.Errorf("resolve git path %s: %w", name, err)
+	}
+	if !filepath.IsAbs(out) {
+		out = filepath.Join(e.path, out)
+	}
+	return out, nil
+}
+
 // resolveRef tries to resolve a branch name to a valid git ref.
 // checks local branch, remote tracking (origin/<name>), "origin/" prefixed names,
 // and finally arbitrary refs like commit hashes or tags via rev-parse.
@@ -491,6 +491,18 @@ func TestExternalBackend_HasChangesOtherThan(t *testing.T) {
 		assert.Empty(t, dirty)
 	})
 
+	t.Run("empty path excludes nothing", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		eb, err := newExternalBackend(dir, "git")
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "feature.md"), []byte("# Plan"), 0o600))
+
+		dirty, err := eb.hasChangesOtherThan("")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"feature.md"}, dirty)
+	})
+
 	t.Run("returns dirty file when other file is untracked", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
 		eb, err := newExternalBackend(dir, "git")
@@ -525,6 +537,80 @@ func TestExternalBackend_HasChangesOtherThan(t *testing.T) {
 	})
 }
 
+func TestExternalBackend_WorkingTreeStatus(t *testing.T) {
+	t.Run("returns nil for clean tree", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		eb, err := newExternalBackend(dir, "git")
+		require.NoError(t, err)
+
+		changes, err := eb.workingTreeStatus()
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("counts lines for tracked, staged and untracked files", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		eb, err := newExternalBackend(dir, "git")
+		require.NoError(t, err)
+
+		// README.md: "# Test" replaced by two new lines -> +2 -1
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed\nmore\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "staged.go"), []byte("package x\n\nfunc f() {}\n"), 0o600))
+		runGit(t, dir, "add", "staged.go")
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "new.txt"), []byte("a\nb"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.log\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "debug.log"), []byte("ignored\n"), 0o600))
+
+		changes, err := eb.workingTreeStatus()
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []FileChange{
+			{Path: "README.md", Status: "M", Additions: 2, Deletions: 1},
+			{Path: "staged.go", Status: "A", Additions: 3},
+			{Path: "sub/new.txt", Status: "??", Additions: 2},
+			{Path: ".gitignore", Status: "??", Additions: 1},
+		}, changes)
+	})
+
+	t.Run("works in repo without commits", func(t *testing.T) {
+		dir := t.TempDir()
+		runGit(t, dir, "init")
+		eb, err := newExternalBackend(dir, "git")
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("one\n"), 0o600))
+		changes, err := eb.workingTreeStatus()
+		require.NoError(t, err)
+		assert.Equal(t, []FileChange{{Path: "a.txt", Status: "??", Additions: 1}}, changes)
+	})
+}
+
+func TestCountLines(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content []byte
+		want    int
+	}{
+		{name: "empty", content: nil, want: 0},
+		{name: "trailing newline", content: []byte("a\nb\n"), want: 2},
+		{name: "no trailing newline", content: []byte("a\nb"), want: 2},
+		{name: "binary", content: []byte("a\x00b\n"), want: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.name)
+			require.NoError(t, os.WriteFile(path, tc.content, 0o600))
+			assert.Equal(t, tc.want, countLines(path))
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		assert.Zero(t, countLines(filepath.Join(dir, "missing")))
+	})
+}
+
 func TestExternalBackend_IsIgnored(t *testing.T) {
 	t.Run("returns false for non-ignored file", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
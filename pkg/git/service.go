@@ -6,7 +6,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/umputun/ralphex/pkg/plan"
 )
@@ -26,26 +28,59 @@ type backend interface {
 	headHash() (string, error)
 	hasCommits() (bool, error)
 	currentBranch() (string, error)
+	upstream(branch string) (string, error)
+	remotes() ([]string, error)
+	remoteURL(name string) (string, error)
+	push(remote, refspec string) error
 	getDefaultBranch() string
 	branchExists(name string) bool
 	createBranch(name string) error
 	checkoutBranch(name string) error
+	isAncestor(ancestor, ref string) (bool, error)
+	resetBranch(name, ref string) error
 	diffFingerprint() (string, error)
 	isDirty() (bool, error)
 	fileHasChanges(path string) (bool, error)
 	hasChangesOtherThan(path string) ([]string, error)
+	workingTreeStatus() ([]FileChange, error)
 	isIgnored(path string) (bool, error)
 	add(path string) error
 	moveFile(src, dst string) error
 	commit(msg string) error
 	commitFiles(msg string, paths ...string) error
+	commitToOrphanBranch(branch, msg string, paths ...string) (bool, error)
 	createInitialCommit(msg string) error
-	diffStats(baseBranch string) (DiffStats, error)
+	diffStats(baseBranch string, exclude ...string) (DiffStats, error)
+	diffStatsRange(from, to string, exclude ...string) (DiffStats, error)
+	diffStatsPerFile(baseBranch string, exclude ...string) ([]FileDiffStat, error)
+	changedFiles(baseBranch string) ([]ChangedFile, error)
+	commitLog(baseBranch string) ([]string, error)
+	countAheadBehind(baseBranch string) (ahead, behind int, err error)
+	blameLine(file string, line int) (author, commit string, err error)
+	inProgressOperation() (op string, started time.Time, err error)
+	isShallow() (bool, error)
+	unshallow() error
+	abortOperation(op string) error
+	resetHard(ref string) error
+	tagExists(name string) bool
+	createTag(name, message string) error
+	latestTagMatching(pattern string) (string, error)
+	stashPush(message string, exclude ...string) (bool, error)
+	stashPop() error
 	addWorktree(path, branch string, createBranch bool) error
 	removeWorktree(path string) error
 	pruneWorktrees() error
 }
 
+// in-progress operations detected by InProgressOperation.
+// these are the operations claude can leave behind during a run, e.g. a rebase in finalize;
+// git am, revert and bisect are never reported.
+const (
+	OpRebase     = "rebase"
+	OpMerge      = "merge"
+	OpCherryPick = "cherry-pick"
+)
+
 // DiffStats holds statistics about changes between two commits.
 type DiffStats struct {
 	Files     int // number of files changed
@@ -53,11 +88,45 @@ type DiffStats struct {
 	Deletions int // lines deleted
 }
 
+// FileDiffStat holds change statistics for a single file between two commits.
+type FileDiffStat struct {
+	Path      string // path relative to repository root (new path for renames)
+	OldPath   string // previous path for renamed files, empty otherwise
+	Additions int    // lines added, 0 for binary files
+	Deletions int    // lines deleted, 0 for binary files
+	Binary    bool   // binary file, line counts are not available
+}
+
+// change statuses of a ChangedFile.
+const (
+	StatusAdded    = "added"
+	StatusModified = "modified"
+	StatusDeleted  = "deleted"
+	StatusRenamed  = "renamed"
+)
+
+// ChangedFile is a file changed between a base branch and HEAD.
+type ChangedFile struct {
+	Path    string // path relative to repository root (new path for renames)
+	OldPath string // previous path for renamed files, empty otherwise
+	Status  string // StatusAdded, StatusModified, StatusDeleted or StatusRenamed
+}
+
+// FileChange describes a single uncommitted change in the working tree.
+type FileChange struct {
+	Path      string // path relative to repository root
+	Status    string // porcelain status code, e.g. "M", "A", "??"
+	Additions int    // lines added (for untracked files, the file's line count)
+	Deletions int    // lines deleted
+}
+
 // Service provides git operations for ralphex workflows.
 // It is the single public API for the git package.
 type Service struct {
-	repo backend
-	log  Logger
+	repo        backend
+	log         Logger
+	branchRules plan.BranchNameRules // how branch names are derived from plan files
+	trailer     string               // "Key: value" trailer added to every commit message, empty = none
 }
 
 // NewService opens a git repository and returns a Service.
@@ -76,6 +145,26 @@ func NewService(path string, log Logger, vcsCmd ...string) (*Service, error) {
 	return &Service{repo: b, log: log}, nil
 }
 
+// SetBranchNameRules sets the rules for deriving a branch name from a plan file,
+// used by CreateBranchForPlan, CreateWorktreeForPlan and CommitPlanFile.
+func (s *Service) SetBranchNameRules(rules plan.BranchNameRules) {
+	s.branchRules = rules
+}
+
+// SetCommitTrailer sets a "Key: value" git trailer appended to the message of every commit the service makes,
+// e.g. "Generated-by: ralphex v1.2.0". empty disables it.
+func (s *Service) SetCommitTrailer(trailer string) {
+	s.trailer = strings.TrimSpace(trailer)
+}
+
+// withTrailer appends the commit trailer to msg after a blank line, so git recognizes it as a trailer.
+func (s *Service) withTrailer(msg string) string {
+	if s.trailer == "" {
+		return msg
+	}
+	return msg + "\n\n" + s.trailer
+}
+
 // Root returns the absolute path to the repository root.
 func (s *Service) Root() string {
 	return s.repo.root()
@@ -101,6 +190,95 @@ func (s *Service) CurrentBranch() (string, error) {
 	return branch, nil
 }
 
+// DefaultRemote is the remote pushed to when push_remote is not configured.
+const DefaultRemote = "origin"
+
+// RemoteExists reports whether a remote with the given name is configured.
+func (s *Service) RemoteExists(name string) (bool, error) {
+	names, err := s.repo.remotes()
+	if err != nil {
+		return false, fmt.Errorf("remote exists: %w", err)
+	}
+	return slices.Contains(names, name), nil
+}
+
+// RemoteURL returns the URL of the named remote.
+// returns an error like "no 'origin' remote configured" when the remote doesn't exist.
+func (s *Service) RemoteURL(name string) (string, error) {
+	exists, err := s.RemoteExists(name)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("no '%s' remote configured", name)
+	}
+	url, err := s.repo.remoteURL(name)
+	if err != nil {
+		return "", fmt.Errorf("remote url: %w", err)
+	}
+	return url, nil
+}
+
+// ErrPushRejected is returned by Push when the remote refuses the update, e.g. a non-fast-forward push.
+var ErrPushRejected = errors.New("push rejected")
+
+// Push pushes refspec, as built by PushRefspec, to the named remote.
+// returns an error wrapping ErrPushRejected when the remote refuses the update.
+func (s *Service) Push(remote, refspec string) error {
+	if err := s.repo.push(remote, refspec); err != nil {
+		if errors.Is(err, ErrPushRejected) {
+			return err
+		}
+		return fmt.Errorf("push: %w", err)
+	}
+	s.log.Printf("pushed %s to %s\n", refspec, remote)
+	return nil
+}
+
+// PushRefspec builds the refspec pushing branch to the ref named by template, {{BRANCH}} = branch.
+// a template without the refs/ prefix names a branch, e.g. "review/{{BRANCH}}" pushes to refs/heads/review/<branch>,
+// other namespaces such as refs/for/{{BRANCH}} are given in full. an empty template pushes to the branch of the same name.
+func PushRefspec(template, branch string) (string, error) {
+	if err := ValidateBranchName(branch); err != nil {
+		return "", fmt.Errorf("push refspec: %w", err)
+	}
+	if template == "" {
+		template = "{{BRANCH}}"
+	}
+	dst := strings.ReplaceAll(template, "{{BRANCH}}", branch)
+	if strings.Contains(dst, "{{") || strings.Contains(dst, "}}") {
+		return "", fmt.Errorf("push refspec %q: unknown placeholder, only {{BRANCH}} is supported", template)
+	}
+	if !strings.HasPrefix(dst, "refs/") {
+		dst = "refs/heads/" + dst
+	}
+	name := strings.TrimPrefix(dst, "refs/")
+	if !strings.Contains(name, "/") {
+		return "", fmt.Errorf("push refspec %q: ref %q has no name after its namespace", template, dst)
+	}
+	if err := ValidateBranchName(name); err != nil {
+		return "", fmt.Errorf("push refspec %q: %w", template, err)
+	}
+	return "refs/heads/" + branch + ":" + dst, nil
+}
+
+// CurrentUpstream returns the upstream tracking branch of the current branch (e.g. "origin/feature"),
+// or empty string when no upstream is configured or HEAD is detached.
+func (s *Service) CurrentUpstream() (string, error) {
+	branch, err := s.repo.currentBranch()
+	if err != nil {
+		return "", fmt.Errorf("current upstream: %w", err)
+	}
+	if branch == "" {
+		return "", nil
+	}
+	upstream, err := s.repo.upstream(branch)
+	if err != nil {
+		return "", fmt.Errorf("current upstream: %w", err)
+	}
+	return upstream, nil
+}
+
 // IsDefaultBranch returns true if the current branch matches the given default branch.
 // strips "origin/" prefix from defaultBranch for comparison (auto-detect may return "origin/main").
 // when defaultBranch is empty, falls back to checking "main" and "master".
@@ -146,12 +324,12 @@ func (s *Service) CreateBranch(name string) error {
 	return nil
 }
 
-// preparePlanBranch validates state, extracts branch name, and checks plan file status.
+// preparePlanBranch validates state, resolves branch name, and checks plan file status.
 // returns branch name and whether the plan file has uncommitted changes.
 // when requireDefault is true, returns error if not on the default branch.
 // when requireDefault is false, returns empty branch name if not on the default branch (caller should skip).
 // defaultBranch is the resolved default branch name (e.g. "main", "develop", "origin/main").
-func (s *Service) preparePlanBranch(planFile string, requireDefault bool, defaultBranch string) (string, bool, error) {
+func (s *Service) preparePlanBranch(planFile, branchName string, requireDefault bool, defaultBranch string) (string, bool, error) {
 	currentBranch, err := s.repo.currentBranch()
 	if err != nil {
 		return "", false, fmt.Errorf("check current branch: %w", err)
@@ -168,10 +346,18 @@ func (s *Service) preparePlanBranch(planFile string, requireDefault bool, defaul
 		return "", false, nil // already on feature branch, caller should skip
 	}
 
-	branchName := plan.ExtractBranchName(planFile)
+	if branchName, err = s.planBranchName(planFile, branchName); err != nil {
+		return "", false, err
+	}
+
+	// a plan outside the repository is not part of the working tree, nothing to exclude or commit
+	repoPlan := planFile
+	if !s.inRepo(planFile) {
+		repoPlan = ""
+	}
 
 	// check for uncommitted changes to files other than the plan
-	dirtyFiles, err := s.repo.hasChangesOtherThan(planFile)
+	dirtyFiles, err := s.repo.hasChangesOtherThan(repoPlan)
 	if err != nil {
 		return "", false, fmt.Errorf("check uncommitted files: %w", err)
 	}
@@ -185,10 +371,15 @@ func (s *Service) preparePlanBranch(planFile string, requireDefault bool, defaul
 			"uncommitted files:\n%s\n\n"+
 			"ralphex needs to create a feature branch from %s to isolate plan work.\n\n"+
 			"options:\n"+
+			"  ralphex --autostash %s                     # stash changes and restore them on the new branch\n"+
 			"  git stash && ralphex %s && git stash pop   # stash changes temporarily\n"+
 			"  git commit -am \"wip\"                       # commit changes first\n"+
 			"  ralphex --review                           # skip branch creation (review-only mode)",
-			branchName, fileList, currentBranch, planFile)
+			branchName, fileList, currentBranch, planFile, planFile)
+	}
+
+	if repoPlan == "" {
+		return branchName, false, nil
 	}
 
 	// check if plan file needs to be committed (untracked, modified, or staged)
@@ -205,8 +396,9 @@ func (s *Service) preparePlanBranch(planFile string, requireDefault bool, defaul
 // If on the default branch, extracts branch name from plan file and creates/switches to it.
 // If plan file has uncommitted changes and is the only dirty file, auto-commits it.
 // defaultBranch is the resolved default branch name (e.g. "main", "develop").
-func (s *Service) CreateBranchForPlan(planFile, defaultBranch string) error {
-	branchName, planHasChanges, err := s.preparePlanBranch(planFile, false, defaultBranch)
+// branchName overrides the name derived from the plan file when non-empty.
+func (s *Service) CreateBranchForPlan(planFile, defaultBranch, branchName string) error {
+	branchName, planHasChanges, err := s.preparePlanBranch(planFile, branchName, false, defaultBranch)
 	if err != nil {
 		return err
 	}
@@ -233,7 +425,7 @@ func (s *Service) CreateBranchForPlan(planFile, defaultBranch string) error {
 		if err := s.repo.add(planFile); err != nil {
 			return fmt.Errorf("stage plan file: %w", err)
 		}
-		if err := s.repo.commit("add plan: " + branchName); err != nil {
+		if err := s.repo.commit(s.withTrailer("add plan: " + branchName)); err != nil {
 			return fmt.Errorf("commit plan file: %w", err)
 		}
 	}
@@ -241,6 +433,41 @@ func (s *Service) CreateBranchForPlan(planFile, defaultBranch string) error {
 	return nil
 }
 
+// RecreatePlanBranch restarts the branch of a reopened plan from the current default branch.
+// the branch left by the earlier run is usually merged already, starting from it would run the plan on old code,
+// so a fully merged branch is moved to HEAD. a branch with unmerged commits is kept and the run continues on it.
+// no-op when the branch doesn't exist (CreateBranchForPlan and CreateWorktreeForPlan create it from the
+// default branch) or when not on the default branch.
+func (s *Service) RecreatePlanBranch(planFile, defaultBranch, branchName string) error {
+	currentBranch, err := s.repo.currentBranch()
+	if err != nil {
+		return fmt.Errorf("check current branch: %w", err)
+	}
+	if !s.matchesDefaultBranch(currentBranch, defaultBranch) {
+		return nil // already on feature branch, the plan is reopened there
+	}
+	if branchName, err = s.planBranchName(planFile, branchName); err != nil {
+		return err
+	}
+	if !s.repo.branchExists(branchName) {
+		return nil
+	}
+
+	merged, err := s.repo.isAncestor(branchName, "HEAD")
+	if err != nil {
+		return fmt.Errorf("check branch %s: %w", branchName, err)
+	}
+	if !merged {
+		s.log.Printf("branch %s has commits not merged into %s, continuing on it\n", branchName, currentBranch)
+		return nil
+	}
+	s.log.Printf("recreating merged branch %s from %s\n", branchName, currentBranch)
+	if err := s.repo.resetBranch(branchName, "HEAD"); err != nil {
+		return fmt.Errorf("recreate branch %s: %w", branchName, err)
+	}
+	return nil
+}
+
 // CreateWorktreeForPlan creates an isolated git worktree for plan execution.
 // must be called from the default branch (same guard as CreateBranchForPlan).
 // derives branch name from plan file, creates worktree at .ralphex/worktrees/<branch>.
@@ -248,10 +475,14 @@ func (s *Service) CreateBranchForPlan(planFile, defaultBranch string) error {
 // must commit the plan file in the worktree context (via CommitPlanFile on the worktree's
 // git service) so the commit lands on the feature branch rather than the default branch.
 // defaultBranch is the resolved default branch name (e.g. "main", "develop").
-func (s *Service) CreateWorktreeForPlan(planFile, defaultBranch string) (string, bool, error) {
+// branchName overrides the name derived from the plan file (for both branch and worktree path) when non-empty.
+func (s *Service) CreateWorktreeForPlan(planFile, defaultBranch, branchName string) (string, bool, error) {
 	// check worktree existence early, before preparePlanBranch runs hasChangesOtherThan
 	// (an existing worktree dir would show up as untracked and fail the dirty check)
-	earlyBranch := plan.ExtractBranchName(planFile)
+	earlyBranch, err := s.planBranchName(planFile, branchName)
+	if err != nil {
+		return "", false, err
+	}
 	wtPath := filepath.Join(s.repo.root(), ".ralphex", "worktrees", earlyBranch)
 
 	// prune stale worktree entries first
@@ -264,7 +495,7 @@ func (s *Service) CreateWorktreeForPlan(planFile, defaultBranch string) (string,
 		return "", false, fmt.Errorf("worktree already exists at %s, another instance may be running", wtPath)
 	}
 
-	branchName, planHasChanges, err := s.preparePlanBranch(planFile, true, defaultBranch)
+	branchName, planHasChanges, err := s.preparePlanBranch(planFile, branchName, true, defaultBranch)
 	if err != nil {
 		return "", false, err
 	}
@@ -294,11 +525,54 @@ func (s *Service) CreateWorktreeForPlan(planFile, defaultBranch string) (string,
 	return wtPath, planHasChanges, nil
 }
 
+// planBranchName returns override if set, otherwise the branch name derived from the plan file
+// with the service's branch name rules. a derived name that is not a legal branch name is an error.
+func (s *Service) planBranchName(planFile, override string) (string, error) {
+	if override != "" {
+		return override, nil
+	}
+	name := plan.ExtractBranchNameWith(planFile, s.branchRules)
+	if err := ValidateBranchName(name); err != nil {
+		return "", fmt.Errorf("branch name for plan %s: %w", filepath.Base(planFile), err)
+	}
+	return name, nil
+}
+
+// ValidateBranchName checks that name is a legal git branch name,
+// following the rules of git check-ref-format --branch.
+func ValidateBranchName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("branch name is empty")
+	case name == "@":
+		return errors.New("branch name can't be \"@\"")
+	case strings.HasPrefix(name, "-"):
+		return fmt.Errorf("branch name %q can't start with \"-\"", name)
+	case strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/"):
+		return fmt.Errorf("branch name %q can't start or end with \"/\"", name)
+	case strings.HasSuffix(name, "."):
+		return fmt.Errorf("branch name %q can't end with \".\"", name)
+	case strings.Contains(name, ".."), strings.Contains(name, "//"), strings.Contains(name, "@{"):
+		return fmt.Errorf("branch name %q can't contain \"..\", \"//\" or \"@{\"", name)
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r) {
+			return fmt.Errorf("branch name %q contains invalid character %q", name, r)
+		}
+	}
+	for part := range strings.SplitSeq(name, "/") {
+		if strings.HasPrefix(part, ".") || strings.HasSuffix(part, ".lock") {
+			return fmt.Errorf("branch name %q has invalid component %q", name, part)
+		}
+	}
+	return nil
+}
+
 // CommitPlanFile stages and commits a plan file on the current branch.
 // mainRepoRoot is the root of the main repository, used to compute the plan file's
 // relative path when the service operates inside a worktree.
 func (s *Service) CommitPlanFile(planFile, mainRepoRoot string) error {
-	branchName := plan.ExtractBranchName(planFile)
+	branchName := plan.ExtractBranchNameWith(planFile, s.branchRules)
 	s.log.Printf("committing plan file: %s\n", filepath.Base(planFile))
 
 	// compute the plan file's relative path from the main repo root, then resolve
@@ -321,12 +595,41 @@ func (s *Service) CommitPlanFile(planFile, mainRepoRoot string) error {
 	if err := s.repo.add(localPlan); err != nil {
 		return fmt.Errorf("stage plan file: %w", err)
 	}
-	if err := s.repo.commit("add plan: " + branchName); err != nil {
+	if err := s.repo.commit(s.withTrailer("add plan: " + branchName)); err != nil {
 		return fmt.Errorf("commit plan file: %w", err)
 	}
 	return nil
 }
 
+// CommitToOrphanBranch commits files to a branch with a history of its own, e.g. "ralphex-logs", creating
+// the branch without a parent commit if it doesn't exist. HEAD, the index and the working tree are left alone,
+// so it works from any branch. files keep their paths relative to the repository root, and committing
+// unchanged files does nothing. the branch can't be the checked out one.
+func (s *Service) CommitToOrphanBranch(branch string, files ...string) error {
+	if len(files) == 0 {
+		return errors.New("commit to orphan branch: no files provided")
+	}
+	if err := ValidateBranchName(branch); err != nil {
+		return fmt.Errorf("commit to orphan branch: %w", err)
+	}
+	if current, err := s.repo.currentBranch(); err == nil && current == branch {
+		return fmt.Errorf("commit to orphan branch: %s is the checked out branch", branch)
+	}
+
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, filepath.Base(f))
+	}
+	committed, err := s.repo.commitToOrphanBranch(branch, s.withTrailer("update "+strings.Join(names, ", ")), files...)
+	if err != nil {
+		return fmt.Errorf("commit to orphan branch %s: %w", branch, err)
+	}
+	if committed {
+		s.log.Printf("committed %d file(s) to branch %s\n", len(files), branch)
+	}
+	return nil
+}
+
 // copyToWorktree copies a file from the main repo working tree into the worktree,
 // preserving its relative path from the repo root.
 func (s *Service) copyToWorktree(srcPath, wtPath string) error {
@@ -381,10 +684,26 @@ func (s *Service) RemoveWorktree(path string) error {
 }
 
 // MovePlanToCompleted moves a plan file to the completed/ subdirectory and commits.
+// The commit is restricted to the plan files, other staged changes stay staged.
 // Creates the completed/ directory if it doesn't exist.
 // Uses git mv if the file is tracked, falls back to os.Rename for untracked files.
 // If the source file doesn't exist but the destination does, logs a message and returns nil.
 func (s *Service) MovePlanToCompleted(planFile string) error {
+	return s.MovePlanToCompletedWithSummary(planFile, nil)
+}
+
+// SummaryPath returns where the run summary of a plan is stored, next to the completed plan,
+// e.g. docs/plans/feature.md -> docs/plans/completed/feature.summary.json.
+func SummaryPath(planFile string) string {
+	name := strings.TrimSuffix(filepath.Base(planFile), filepath.Ext(planFile))
+	return filepath.Join(filepath.Dir(planFile), "completed", name+".summary.json")
+}
+
+// MovePlanToCompletedWithSummary moves a plan file like MovePlanToCompleted and writes summary
+// to SummaryPath, committed together with the move. a nil summary writes no file.
+// if the plan was already moved, the summary is committed on its own.
+// plans outside the repository move to completed/ next to them as well, but nothing is committed.
+func (s *Service) MovePlanToCompletedWithSummary(planFile string, summary []byte) error {
 	// create completed directory
 	completedDir := filepath.Join(filepath.Dir(planFile), "completed")
 	if err := os.MkdirAll(completedDir, 0o750); err != nil {
@@ -394,16 +713,31 @@ func (s *Service) MovePlanToCompleted(planFile string) error {
 	// destination path
 	destPath := filepath.Join(completedDir, filepath.Base(planFile))
 
+	if !s.inRepo(planFile) {
+		return s.moveExternalPlan(planFile, destPath, summary)
+	}
+
 	// check if already moved (source missing, dest exists)
 	if _, err := os.Stat(planFile); os.IsNotExist(err) {
 		if _, destErr := os.Stat(destPath); destErr == nil {
 			s.log.Printf("plan already in completed/\n")
+			if summary == nil {
+				return nil
+			}
+			if err := s.writeSummary(planFile, summary); err != nil {
+				return err
+			}
+			if err := s.repo.commitFiles(s.withTrailer("add run summary: "+filepath.Base(planFile)), SummaryPath(planFile)); err != nil {
+				return fmt.Errorf("commit run summary: %w", err)
+			}
 			return nil
 		}
 	}
 
-	// use git mv
+	// use git mv, the commit below then includes the removal of planFile
+	commitPaths := []string{planFile, destPath}
 	if err := s.repo.moveFile(planFile, destPath); err != nil {
+		commitPaths = []string{destPath}
 		// fallback to regular move for untracked files
 		if renameErr := os.Rename(planFile, destPath); renameErr != nil {
 			return fmt.Errorf("move plan: %w", renameErr)
@@ -414,9 +748,16 @@ func (s *Service) MovePlanToCompleted(planFile string) error {
 		}
 	}
 
-	// commit the move
+	if summary != nil {
+		if err := s.writeSummary(planFile, summary); err != nil {
+			return err
+		}
+		commitPaths = append(commitPaths, SummaryPath(planFile))
+	}
+
+	// commit only the move, other staged changes (e.g. from finalize_no_commit) stay staged
 	commitMsg := "move completed plan: " + filepath.Base(planFile)
-	if err := s.repo.commit(commitMsg); err != nil {
+	if err := s.repo.commitFiles(s.withTrailer(commitMsg), commitPaths...); err != nil {
 		return fmt.Errorf("commit plan move: %w", err)
 	}
 
@@ -424,6 +765,102 @@ func (s *Service) MovePlanToCompleted(planFile string) error {
 	return nil
 }
 
+// moveExternalPlan moves a plan located outside the repository to destPath and writes its summary,
+// without staging or committing anything.
+func (s *Service) moveExternalPlan(planFile, destPath string, summary []byte) error {
+	_, srcErr := os.Stat(planFile)
+	_, destErr := os.Stat(destPath)
+	if os.IsNotExist(srcErr) && destErr == nil {
+		s.log.Printf("plan already in completed/\n")
+	} else {
+		if err := os.Rename(planFile, destPath); err != nil {
+			return fmt.Errorf("move plan: %w", err)
+		}
+		s.log.Printf("moved plan to %s (outside the repository, not committed)\n", destPath)
+	}
+
+	if summary != nil {
+		if err := os.WriteFile(SummaryPath(planFile), summary, 0o600); err != nil {
+			return fmt.Errorf("write run summary: %w", err)
+		}
+	}
+	return nil
+}
+
+// ReopenPlan moves a plan from a completed/ directory back to the plans directory above it and commits,
+// the inverse of MovePlanToCompleted, so the plan can run again. returns the reopened plan path.
+// the commit lands on the current branch, callers reopen on the plan's feature branch after CreateBranchForPlan.
+// the run summary stays in completed/. fails if the path isn't in a completed/ directory or
+// a plan with the same name already exists in the plans directory.
+// plans outside the repository are moved back as well, but nothing is committed.
+func (s *Service) ReopenPlan(completedPath string) (string, error) {
+	completedDir := filepath.Dir(completedPath)
+	if filepath.Base(completedDir) != "completed" {
+		return "", fmt.Errorf("plan %s is not in a completed/ directory", completedPath)
+	}
+	destPath := filepath.Join(filepath.Dir(completedDir), filepath.Base(completedPath))
+	if _, err := os.Stat(destPath); err == nil {
+		return "", fmt.Errorf("can't reopen plan, %s already exists", destPath)
+	}
+
+	if !s.inRepo(completedPath) {
+		if err := os.Rename(completedPath, destPath); err != nil {
+			return "", fmt.Errorf("move plan: %w", err)
+		}
+		s.log.Printf("reopened plan %s (outside the repository, not committed)\n", destPath)
+		return destPath, nil
+	}
+
+	// use git mv, the commit below then includes the removal of completedPath
+	commitPaths := []string{completedPath, destPath}
+	if err := s.repo.moveFile(completedPath, destPath); err != nil {
+		commitPaths = []string{destPath}
+		// fallback to regular move for untracked files
+		if renameErr := os.Rename(completedPath, destPath); renameErr != nil {
+			return "", fmt.Errorf("move plan: %w", renameErr)
+		}
+		if addErr := s.repo.add(destPath); addErr != nil {
+			return "", fmt.Errorf("stage reopened plan: %w", addErr)
+		}
+	}
+
+	if err := s.repo.commitFiles(s.withTrailer("reopen plan: "+filepath.Base(destPath)), commitPaths...); err != nil {
+		return "", fmt.Errorf("commit plan reopen: %w", err)
+	}
+	s.log.Printf("reopened plan %s\n", destPath)
+	return destPath, nil
+}
+
+// inRepo reports whether path is inside the repository working tree.
+// symlinks are resolved to match s.repo.root(), which is resolved in NewService.
+func (s *Service) inRepo(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	// the plan may already be moved, resolve its directory which still exists
+	if dir, dirErr := filepath.EvalSymlinks(filepath.Dir(abs)); dirErr == nil {
+		abs = filepath.Join(dir, filepath.Base(abs))
+	}
+	rel, err := filepath.Rel(s.repo.root(), abs)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// writeSummary writes the run summary of planFile to SummaryPath and stages it.
+func (s *Service) writeSummary(planFile string, summary []byte) error {
+	path := SummaryPath(planFile)
+	if err := os.WriteFile(path, summary, 0o600); err != nil {
+		return fmt.Errorf("write run summary: %w", err)
+	}
+	if err := s.repo.add(path); err != nil {
+		return fmt.Errorf("stage run summary: %w", err)
+	}
+	return nil
+}
+
 // EnsureHasCommits checks that the repository has at least one commit.
 // If the repository is empty, calls promptFn to ask user whether to create initial commit.
 // promptFn should return true to create the commit, false to abort.
@@ -443,16 +880,227 @@ func (s *Service) EnsureHasCommits(promptFn func() bool) error {
 	}
 
 	// create the commit
-	if err := s.repo.createInitialCommit("initial commit"); err != nil {
+	if err := s.repo.createInitialCommit(s.withTrailer("initial commit")); err != nil {
 		return fmt.Errorf("create initial commit: %w", err)
 	}
 	return nil
 }
 
-// DiffStats returns change statistics between baseBranch and HEAD.
+// DiffStats returns change statistics between baseBranch and HEAD, leaving out the exclude paths
+// (files or directories, e.g. the plan file). exclude paths outside the repository are ignored.
 // returns zero stats if baseBranch doesn't exist or HEAD equals baseBranch.
-func (s *Service) DiffStats(baseBranch string) (DiffStats, error) {
-	return s.repo.diffStats(baseBranch)
+func (s *Service) DiffStats(baseBranch string, exclude ...string) (DiffStats, error) {
+	return s.repo.diffStats(baseBranch, exclude...)
+}
+
+// DiffStatsRange returns change statistics between two commits, e.g. HEAD before and after a task.
+// an empty to compares from against the working tree (uncommitted changes, staged and unstaged).
+// exclude paths are left out as in DiffStats. returns zero stats if both refer to the same commit.
+func (s *Service) DiffStatsRange(from, to string, exclude ...string) (DiffStats, error) {
+	return s.repo.diffStatsRange(from, to, exclude...)
+}
+
+// DiffStatsPerFile returns per-file change statistics between baseBranch and HEAD.
+// returns nil if baseBranch doesn't exist or HEAD equals baseBranch.
+// exclude paths are left out as in DiffStats.
+func (s *Service) DiffStatsPerFile(baseBranch string, exclude ...string) ([]FileDiffStat, error) {
+	return s.repo.diffStatsPerFile(baseBranch, exclude...)
+}
+
+// ChangedFiles returns the paths of files changed between baseBranch and HEAD, like git diff --name-only base...HEAD.
+// renamed files are listed under their new path. returns nil if baseBranch doesn't exist or HEAD equals baseBranch.
+func (s *Service) ChangedFiles(baseBranch string) ([]string, error) {
+	changes, err := s.repo.changedFiles(baseBranch)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	res := make([]string, 0, len(changes))
+	for _, c := range changes {
+		res = append(res, c.Path)
+	}
+	return res, nil
+}
+
+// ChangedFilesWithStatus returns the files changed between baseBranch and HEAD with their change status.
+// returns nil if baseBranch doesn't exist or HEAD equals baseBranch.
+func (s *Service) ChangedFilesWithStatus(baseBranch string) ([]ChangedFile, error) {
+	return s.repo.changedFiles(baseBranch)
+}
+
+// InProgressOperation returns the name of an interrupted rebase, merge or cherry-pick
+// left in the repository (OpRebase, OpMerge, OpCherryPick), or empty string if there is none.
+// other states (git am, revert, bisect) are never reported.
+func (s *Service) InProgressOperation() (string, error) {
+	op, _, err := s.InProgressOperationStarted()
+	return op, err
+}
+
+// InProgressOperationStarted is InProgressOperation with the modification time of the operation's
+// state file in the git dir, never earlier than its start. the time is zero when no operation is in progress.
+func (s *Service) InProgressOperationStarted() (string, time.Time, error) {
+	op, started, err := s.repo.inProgressOperation()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("detect in-progress operation: %w", err)
+	}
+	return op, started, nil
+}
+
+// AbortInProgress aborts the interrupted operation reported by InProgressOperation,
+// restoring the repository to its state before the operation started.
+// does nothing if no operation is in progress. callers must confirm with the user first,
+// since aborting discards any conflict resolution done so far.
+func (s *Service) AbortInProgress() error {
+	op, err := s.InProgressOperation()
+	if err != nil {
+		return err
+	}
+	if op == "" {
+		return nil
+	}
+	if err := s.repo.abortOperation(op); err != nil {
+		return fmt.Errorf("abort %s: %w", op, err)
+	}
+	s.log.Printf("aborted in-progress %s\n", op)
+	return nil
+}
+
+// ResetHard resets the current branch, index and working tree to ref, discarding uncommitted changes
+// and any commits after ref. refuses to run on main/master, defaultBranch (the configured default branch,
+// may be empty), the detected default branch or a detached HEAD, so it can only rewind a feature branch.
+// callers must confirm with the user first.
+func (s *Service) ResetHard(ref, defaultBranch string) error {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid ref %q", ref)
+	}
+	branch, err := s.repo.currentBranch()
+	if err != nil {
+		return fmt.Errorf("check current branch: %w", err)
+	}
+	if branch == "" {
+		return errors.New("refusing to reset a detached HEAD, check out the feature branch first")
+	}
+	if s.matchesDefaultBranch(branch, "") || s.matchesDefaultBranch(branch, defaultBranch) ||
+		s.matchesDefaultBranch(branch, s.repo.getDefaultBranch()) {
+		return fmt.Errorf("refusing to reset default branch %q", branch)
+	}
+	if err := s.repo.resetHard(ref); err != nil {
+		return fmt.Errorf("reset %s to %s: %w", branch, ref, err)
+	}
+	s.log.Printf("reset %s to %s\n", branch, ref)
+	return nil
+}
+
+// CommitLog returns one-line summaries of commits reachable from HEAD but not from baseBranch,
+// newest first. returns nil if baseBranch doesn't exist or there are no such commits.
+func (s *Service) CommitLog(baseBranch string) ([]string, error) {
+	lines, err := s.repo.commitLog(baseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("commit log: %w", err)
+	}
+	return lines, nil
+}
+
+// CountAheadBehind returns how many commits HEAD has that baseBranch doesn't (ahead)
+// and how many baseBranch has that HEAD doesn't (behind).
+// returns zeros if baseBranch doesn't exist or HEAD equals baseBranch.
+func (s *Service) CountAheadBehind(baseBranch string) (ahead, behind int, err error) {
+	ahead, behind, err = s.repo.countAheadBehind(baseBranch)
+	if err != nil {
+		return 0, 0, fmt.Errorf("count ahead/behind: %w", err)
+	}
+	return ahead, behind, nil
+}
+
+// maxTagAttempts limits the counter suffix tried by CreateTag when the tag name is taken.
+const maxTagAttempts = 100
+
+// CreateTag creates an annotated tag on HEAD with the given message.
+// if a tag with this name already exists, a counter is appended (name-2, name-3, ...).
+func (s *Service) CreateTag(name, message string) error {
+	tag := name
+	for i := 2; s.repo.tagExists(tag); i++ {
+		if i > maxTagAttempts {
+			return fmt.Errorf("create tag: no free name for %s after %d attempts", name, maxTagAttempts)
+		}
+		tag = fmt.Sprintf("%s-%d", name, i)
+	}
+	if err := s.repo.createTag(tag, message); err != nil {
+		return fmt.Errorf("create tag %s: %w", tag, err)
+	}
+	s.log.Printf("created tag %s\n", tag)
+	return nil
+}
+
+// LatestTagMatching returns the most recent tag matching the glob pattern, e.g. "ralphex/*", that is
+// the matching tag nearest to HEAD in its history. only tags reachable from HEAD are considered,
+// so the tag can serve as a review base. returns empty string if no tag matches.
+func (s *Service) LatestTagMatching(pattern string) (string, error) {
+	tag, err := s.repo.latestTagMatching(pattern)
+	if err != nil {
+		return "", fmt.Errorf("latest tag matching %s: %w", pattern, err)
+	}
+	return tag, nil
+}
+
+// IsShallow reports whether the repository is a shallow clone (e.g. CI checkout with fetch-depth 1).
+// history missing from a shallow clone makes diffs against the default branch incomplete.
+func (s *Service) IsShallow() (bool, error) {
+	shallow, err := s.repo.isShallow()
+	if err != nil {
+		return false, fmt.Errorf("is shallow: %w", err)
+	}
+	return shallow, nil
+}
+
+// Unshallow fetches the history missing from a shallow clone.
+func (s *Service) Unshallow() error {
+	s.log.Printf("fetching full history of shallow clone\n")
+	if err := s.repo.unshallow(); err != nil {
+		return fmt.Errorf("unshallow: %w", err)
+	}
+	return nil
+}
+
+// BlameLine returns the author and short commit hash of the last commit that changed the given line of file.
+// both are empty when the line is not committed yet. file is relative to the repository root.
+func (s *Service) BlameLine(file string, line int) (author, commit string, err error) {
+	author, commit, err = s.repo.blameLine(file, line)
+	if err != nil {
+		return "", "", fmt.Errorf("blame line: %w", err)
+	}
+	return author, commit, nil
+}
+
+// ErrNoStash is returned by StashPop when there is no stash entry to restore.
+var ErrNoStash = errors.New("no stash entries to restore")
+
+// StashPush stashes uncommitted changes, including untracked files, leaving the exclude paths in place.
+// returns false if the working tree had nothing to stash.
+func (s *Service) StashPush(message string, exclude ...string) (bool, error) {
+	stashed, err := s.repo.stashPush(message, exclude...)
+	if err != nil {
+		return false, err
+	}
+	if stashed {
+		s.log.Printf("stashed uncommitted changes\n")
+	}
+	return stashed, nil
+}
+
+// StashPop restores the latest stash entry. returns ErrNoStash if the stash is empty.
+// if the changes conflict with the working tree, the entry stays in the stash and an error is returned.
+func (s *Service) StashPop() error {
+	if err := s.repo.stashPop(); err != nil {
+		if errors.Is(err, ErrNoStash) {
+			return err
+		}
+		return fmt.Errorf("%w; changes are kept in the stash, resolve and run git stash drop, or restore with git stash pop", err)
+	}
+	s.log.Printf("restored stashed changes\n")
+	return nil
 }
 
 // EnsureIgnored ensures a pattern is in .gitignore.
@@ -498,6 +1146,16 @@ func (s *Service) FileHasChanges(path string) (bool, error) {
 	return changed, nil
 }
 
+// WorkingTreeStatus returns uncommitted changes (staged, unstaged and untracked) with rough line counts.
+// gitignored files are not included. an empty slice means the working tree is clean.
+func (s *Service) WorkingTreeStatus() ([]FileChange, error) {
+	changes, err := s.repo.workingTreeStatus()
+	if err != nil {
+		return nil, fmt.Errorf("working tree status: %w", err)
+	}
+	return changes, nil
+}
+
 // CommitIgnoreChanges stages and commits .gitignore if it has uncommitted changes.
 // no-op if .gitignore is clean. used to prevent dirty state from blocking branch/worktree creation
 // after EnsureIgnored has modified .gitignore.
@@ -512,7 +1170,7 @@ func (s *Service) CommitIgnoreChanges() error {
 	if err := s.repo.add(".gitignore"); err != nil {
 		return fmt.Errorf("stage .gitignore: %w", err)
 	}
-	if err := s.repo.commitFiles("add ralphex entries to .gitignore", ".gitignore"); err != nil {
+	if err := s.repo.commitFiles(s.withTrailer("add ralphex entries to .gitignore"), ".gitignore"); err != nil {
 		return fmt.Errorf("commit .gitignore: %w", err)
 	}
 	s.log.Printf("committed .gitignore changes\n")
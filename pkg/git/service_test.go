@@ -3,12 +3,16 @@ package git
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/plan"
 )
 
 // mockLogger implements Logger interface for testing.
@@ -183,6 +187,180 @@ func TestService_IsDefaultBranch(t *testing.T) {
 	})
 }
 
+func TestService_CurrentUpstream(t *testing.T) {
+	t.Run("no upstream configured", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		upstream, err := svc.CurrentUpstream()
+		require.NoError(t, err)
+		assert.Empty(t, upstream)
+	})
+
+	t.Run("tracking remote branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		remote := t.TempDir()
+		runGit(t, remote, "init", "--bare")
+		runGit(t, dir, "remote", "add", "origin", remote)
+		runGit(t, dir, "checkout", "-b", "feature")
+		runGit(t, dir, "push", "-u", "origin", "feature")
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		upstream, err := svc.CurrentUpstream()
+		require.NoError(t, err)
+		assert.Equal(t, "origin/feature", upstream)
+	})
+
+	t.Run("detached head", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		runGit(t, dir, "checkout", "--detach")
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		upstream, err := svc.CurrentUpstream()
+		require.NoError(t, err)
+		assert.Empty(t, upstream)
+	})
+}
+
+func TestService_Remote(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	remote := t.TempDir()
+	runGit(t, dir, "remote", "add", "upstream", remote)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+
+	exists, err := svc.RemoteExists("upstream")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	url, err := svc.RemoteURL("upstream")
+	require.NoError(t, err)
+	assert.Equal(t, remote, url)
+
+	exists, err = svc.RemoteExists(DefaultRemote)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	_, err = svc.RemoteURL(DefaultRemote)
+	require.EqualError(t, err, "no 'origin' remote configured")
+}
+
+func TestService_Push(t *testing.T) {
+	t.Run("pushes to a namespaced ref", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		remote := t.TempDir()
+		runGit(t, remote, "init", "--bare")
+		runGit(t, dir, "remote", "add", "origin", remote)
+		runGit(t, dir, "checkout", "-b", "feature")
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		refspec, err := PushRefspec("review/{{BRANCH}}", "feature")
+		require.NoError(t, err)
+		require.NoError(t, svc.Push("origin", refspec))
+		assert.Equal(t, runGit(t, dir, "rev-parse", "HEAD"), runGit(t, remote, "rev-parse", "refs/heads/review/feature"))
+		assert.Empty(t, strings.TrimSpace(runGit(t, remote, "branch", "--list", "feature")), "local branch name not pushed")
+	})
+
+	t.Run("non-fast-forward is rejected", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		remote := t.TempDir()
+		runGit(t, remote, "init", "--bare")
+		runGit(t, dir, "remote", "add", "origin", remote)
+		runGit(t, dir, "checkout", "-b", "feature")
+		runGit(t, dir, "commit", "--allow-empty", "-m", "remote only")
+		runGit(t, dir, "push", "origin", "feature:review/feature")
+		runGit(t, dir, "reset", "--hard", "HEAD~1")
+		runGit(t, dir, "commit", "--allow-empty", "-m", "local only")
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		err = svc.Push("origin", "refs/heads/feature:refs/heads/review/feature")
+		require.ErrorIs(t, err, ErrPushRejected)
+		assert.Contains(t, err.Error(), "rejected")
+	})
+
+	t.Run("unknown remote", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		err = svc.Push("nowhere", "refs/heads/master:refs/heads/review/master")
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrPushRejected)
+	})
+}
+
+func TestPushRefspec(t *testing.T) {
+	tests := []struct {
+		name, template, branch string
+		want                   string
+		wantErr                string
+	}{
+		{name: "empty template", branch: "feature", want: "refs/heads/feature:refs/heads/feature"},
+		{name: "branch namespace", template: "review/{{BRANCH}}", branch: "feature/auth",
+			want: "refs/heads/feature/auth:refs/heads/review/feature/auth"},
+		{name: "full ref", template: "refs/heads/review/{{BRANCH}}", branch: "feature",
+			want: "refs/heads/feature:refs/heads/review/feature"},
+		{name: "other namespace", template: "refs/for/{{BRANCH}}", branch: "feature", want: "refs/heads/feature:refs/for/feature"},
+		{name: "fixed ref", template: "refs/for/master", branch: "feature", want: "refs/heads/feature:refs/for/master"},
+		{name: "unknown placeholder", template: "review/{{SLUG}}", branch: "feature", wantErr: "unknown placeholder"},
+		{name: "invalid ref", template: "review..{{BRANCH}}", branch: "feature", wantErr: `can't contain ".."`},
+		{name: "colon in template", template: "a:{{BRANCH}}", branch: "feature", wantErr: "invalid character"},
+		{name: "namespace only", template: "refs/{{BRANCH}}", branch: "feature", wantErr: "no name after its namespace"},
+		{name: "invalid branch", template: "review/{{BRANCH}}", branch: "-x", wantErr: `can't start with "-"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := PushRefspec(tc.template, tc.branch)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestService_BlameLine(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	head := strings.TrimSpace(runGit(t, dir, "rev-parse", "--short=7", "HEAD"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Test\nnew line\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main\n"), 0o600))
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		file       string
+		line       int
+		wantAuthor string
+		wantCommit string
+		wantErr    bool
+	}{
+		{name: "committed line", file: "README.md", line: 1, wantAuthor: "test", wantCommit: head},
+		{name: "uncommitted line", file: "README.md", line: 2},
+		{name: "untracked file", file: "new.go", line: 1, wantErr: true},
+		{name: "line out of range", file: "README.md", line: 10, wantErr: true},
+		{name: "invalid line", file: "README.md", line: 0, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			author, commit, err := svc.BlameLine(tc.file, tc.line)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantAuthor, author)
+			assert.Equal(t, tc.wantCommit, commit)
+		})
+	}
+}
+
 func TestService_CreateBranchForPlan(t *testing.T) {
 	t.Run("returns nil on feature branch", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
@@ -196,7 +374,7 @@ func TestService_CreateBranchForPlan(t *testing.T) {
 		log := &mockLogger{}
 		svc.log = log
 
-		err = svc.CreateBranchForPlan(filepath.Join(dir, "docs", "plans", "feature.md"), "master")
+		err = svc.CreateBranchForPlan(filepath.Join(dir, "docs", "plans", "feature.md"), "master", "")
 		require.NoError(t, err)
 
 		// should not have logged anything (no branch created)
@@ -208,6 +386,98 @@ func TestService_CreateBranchForPlan(t *testing.T) {
 		assert.Equal(t, "feature-test", branch)
 	})
 
+	t.Run("applies branch name rules", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		svc.SetBranchNameRules(plan.BranchNameRules{Template: "feature/{{SLUG}}"})
+
+		plansDir := filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(plansDir, 0o750))
+		planFile := filepath.Join(plansDir, "2026-01-22-add-feature.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+
+		require.NoError(t, svc.CreateBranchForPlan(planFile, "master", ""))
+
+		branch, err := svc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "feature/add-feature", branch)
+	})
+
+	t.Run("rejects illegal derived branch name", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		svc.SetBranchNameRules(plan.BranchNameRules{Template: "feature..{{SLUG}}"})
+
+		plansDir := filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(plansDir, 0o750))
+		planFile := filepath.Join(plansDir, "add-feature.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+
+		err = svc.CreateBranchForPlan(planFile, "master", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "branch name for plan")
+
+		branch, err := svc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "master", branch)
+	})
+
+	t.Run("external plan in repo is committed on the new branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		notesDir := filepath.Join(dir, "notes")
+		require.NoError(t, os.MkdirAll(notesDir, 0o750))
+		planFile := filepath.Join(notesDir, "2026-01-22-add-feature.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+
+		require.NoError(t, svc.CreateBranchForPlan(planFile, "master", ""))
+
+		branch, err := svc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "add-feature", branch)
+		assert.Equal(t, "add plan: add-feature\n", runGit(t, dir, "log", "-1", "--format=%s"))
+		assert.Empty(t, runGit(t, dir, "status", "--porcelain"))
+	})
+
+	t.Run("external plan outside repo creates branch without commit", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		planFile := filepath.Join(t.TempDir(), "add-feature.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+		head := runGit(t, dir, "rev-parse", "HEAD")
+
+		require.NoError(t, svc.CreateBranchForPlan(planFile, "master", ""))
+
+		branch, err := svc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "add-feature", branch)
+		assert.Equal(t, head, runGit(t, dir, "rev-parse", "HEAD"), "plan outside the repo is not committed")
+		assert.FileExists(t, planFile)
+	})
+
+	t.Run("uses branch name override", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		plansDir := filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(plansDir, 0o750))
+		planFile := filepath.Join(plansDir, "2026-01-22-add-feature.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+
+		require.NoError(t, svc.CreateBranchForPlan(planFile, "master", "feat/login"))
+
+		branch, err := svc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "feat/login", branch)
+	})
+
 	t.Run("creates branch from plan file name", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
 		log := &mockLogger{}
@@ -220,7 +490,7 @@ func TestService_CreateBranchForPlan(t *testing.T) {
 		planFile := filepath.Join(plansDir, "add-feature.md")
 		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
 
-		err = svc.CreateBranchForPlan(planFile, "master")
+		err = svc.CreateBranchForPlan(planFile, "master", "")
 		require.NoError(t, err)
 
 		// should have created branch
@@ -252,7 +522,7 @@ func TestService_CreateBranchForPlan(t *testing.T) {
 		planFile := filepath.Join(plansDir, "existing-feature.md")
 		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
 
-		err = svc.CreateBranchForPlan(planFile, "master")
+		err = svc.CreateBranchForPlan(planFile, "master", "")
 		require.NoError(t, err)
 
 		// should have switched to existing branch
@@ -279,7 +549,7 @@ func TestService_CreateBranchForPlan(t *testing.T) {
 		otherFile := filepath.Join(dir, "other.txt")
 		require.NoError(t, os.WriteFile(otherFile, []byte("other content"), 0o600))
 
-		err = svc.CreateBranchForPlan(planFile, "master")
+		err = svc.CreateBranchForPlan(planFile, "master", "")
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "worktree has uncommitted changes")
 		assert.Contains(t, err.Error(), "other.txt")
@@ -297,7 +567,7 @@ func TestService_CreateBranchForPlan(t *testing.T) {
 		planFile := filepath.Join(plansDir, "new-feature.md")
 		require.NoError(t, os.WriteFile(planFile, []byte("# New Feature Plan"), 0o600))
 
-		err = svc.CreateBranchForPlan(planFile, "master")
+		err = svc.CreateBranchForPlan(planFile, "master", "")
 		require.NoError(t, err)
 
 		// should have created branch and committed plan
@@ -326,7 +596,7 @@ func TestService_CreateBranchForPlan(t *testing.T) {
 		log := &mockLogger{}
 		svc.log = log
 
-		err = svc.CreateBranchForPlan(planFile, "master")
+		err = svc.CreateBranchForPlan(planFile, "master", "")
 		require.NoError(t, err)
 
 		// should only have one log (creating branch, no committing)
@@ -345,7 +615,7 @@ func TestService_CreateBranchForPlan(t *testing.T) {
 		planFile := filepath.Join(plansDir, "2024-01-15-add-auth.md")
 		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
 
-		err = svc.CreateBranchForPlan(planFile, "master")
+		err = svc.CreateBranchForPlan(planFile, "master", "")
 		require.NoError(t, err)
 
 		// branch name should not have date prefix
@@ -370,7 +640,7 @@ func TestService_CreateBranchForPlan(t *testing.T) {
 		log := &mockLogger{}
 		svc.log = log
 
-		err = svc.CreateBranchForPlan(planFile, "develop")
+		err = svc.CreateBranchForPlan(planFile, "develop", "")
 		require.NoError(t, err)
 
 		branch, err := svc.CurrentBranch()
@@ -391,12 +661,84 @@ func TestService_CreateBranchForPlan(t *testing.T) {
 		svc.log = log
 
 		// default branch is "origin/master" but we're on feature-x, should skip
-		err = svc.CreateBranchForPlan(filepath.Join(dir, "docs", "plans", "feature.md"), "origin/master")
+		err = svc.CreateBranchForPlan(filepath.Join(dir, "docs", "plans", "feature.md"), "origin/master", "")
 		require.NoError(t, err)
 		assert.Empty(t, log.logs) // no branch created
 	})
 }
 
+func TestService_MovePlanToCompletedWithSummary(t *testing.T) {
+	t.Run("commits summary with the move", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		plansDir := filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(plansDir, 0o750))
+		planFile := filepath.Join(plansDir, "feature.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+		require.NoError(t, svc.repo.add(planFile))
+		require.NoError(t, svc.repo.commit("add plan"))
+
+		require.NoError(t, svc.MovePlanToCompletedWithSummary(planFile, []byte(`{"status":"success"}`)))
+
+		summaryPath := filepath.Join(plansDir, "completed", "feature.summary.json")
+		assert.Equal(t, summaryPath, SummaryPath(planFile))
+		data, err := os.ReadFile(summaryPath) //nolint:gosec // test path
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"status":"success"}`, string(data))
+
+		dirty, err := svc.repo.isDirty()
+		require.NoError(t, err)
+		assert.False(t, dirty, "summary and move should be in one commit")
+		lines, err := svc.CommitLog("HEAD~1")
+		require.NoError(t, err)
+		assert.Len(t, lines, 1)
+	})
+
+	t.Run("leaves other staged changes out of the move commit", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		plansDir := filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(plansDir, 0o750))
+		planFile := filepath.Join(plansDir, "feature.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+		require.NoError(t, svc.repo.add(planFile))
+		require.NoError(t, svc.repo.commit("add plan"))
+
+		// staged but uncommitted change, e.g. left by finalize_no_commit
+		staged := filepath.Join(dir, "CHANGELOG.md")
+		require.NoError(t, os.WriteFile(staged, []byte("changes"), 0o600))
+		require.NoError(t, svc.repo.add(staged))
+
+		require.NoError(t, svc.MovePlanToCompletedWithSummary(planFile, []byte(`{}`)))
+
+		out := runGit(t, dir, "status", "--porcelain")
+		assert.Equal(t, "A  CHANGELOG.md", strings.TrimSpace(out), "only the staged change should remain")
+	})
+
+	t.Run("commits summary when plan already moved", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		plansDir := filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(filepath.Join(plansDir, "completed"), 0o750))
+		planFile := filepath.Join(plansDir, "feature.md")
+		require.NoError(t, os.WriteFile(filepath.Join(plansDir, "completed", "feature.md"), []byte("# Plan"), 0o600))
+		require.NoError(t, svc.repo.add(filepath.Join(plansDir, "completed", "feature.md")))
+		require.NoError(t, svc.repo.commit("add completed plan"))
+
+		require.NoError(t, svc.MovePlanToCompletedWithSummary(planFile, []byte(`{}`)))
+		assert.FileExists(t, SummaryPath(planFile))
+		dirty, err := svc.repo.isDirty()
+		require.NoError(t, err)
+		assert.False(t, dirty)
+	})
+}
+
 func TestService_MovePlanToCompleted(t *testing.T) {
 	t.Run("moves tracked file", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
@@ -431,6 +773,52 @@ func TestService_MovePlanToCompleted(t *testing.T) {
 		assert.Contains(t, log.logs[0], "moved plan")
 	})
 
+	t.Run("moves external plan to completed next to it", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		notesDir := filepath.Join(dir, "notes")
+		require.NoError(t, os.MkdirAll(notesDir, 0o750))
+		planFile := filepath.Join(notesDir, "feature.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+		require.NoError(t, svc.repo.add(planFile))
+		require.NoError(t, svc.repo.commit("add plan"))
+
+		require.NoError(t, svc.MovePlanToCompleted(planFile))
+
+		assert.NoFileExists(t, planFile)
+		assert.FileExists(t, filepath.Join(notesDir, "completed", "feature.md"))
+		assert.Equal(t, "move completed plan: feature.md\n", runGit(t, dir, "log", "-1", "--format=%s"))
+		assert.Empty(t, runGit(t, dir, "status", "--porcelain"))
+	})
+
+	t.Run("moves plan outside repo without commit", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		log := &mockLogger{}
+		svc, err := NewService(dir, log)
+		require.NoError(t, err)
+
+		extDir := t.TempDir()
+		planFile := filepath.Join(extDir, "feature.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+		head := runGit(t, dir, "rev-parse", "HEAD")
+
+		require.NoError(t, svc.MovePlanToCompletedWithSummary(planFile, []byte(`{"status":"success"}`)))
+
+		assert.NoFileExists(t, planFile)
+		assert.FileExists(t, filepath.Join(extDir, "completed", "feature.md"))
+		assert.FileExists(t, filepath.Join(extDir, "completed", "feature.summary.json"))
+		assert.Equal(t, head, runGit(t, dir, "rev-parse", "HEAD"), "nothing is committed")
+		assert.Empty(t, runGit(t, dir, "status", "--porcelain"))
+		require.NotEmpty(t, log.logs)
+		assert.Contains(t, log.logs[len(log.logs)-1], "not committed")
+
+		// a second call finds the plan already moved
+		require.NoError(t, svc.MovePlanToCompleted(planFile))
+		assert.Contains(t, log.logs[len(log.logs)-1], "already in completed")
+	})
+
 	t.Run("moves untracked file", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
 		svc, err := NewService(dir, noopServiceLogger())
@@ -510,6 +898,156 @@ func TestService_MovePlanToCompleted(t *testing.T) {
 	})
 }
 
+func TestService_ReopenPlan(t *testing.T) {
+	t.Run("moves tracked plan back and commits", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		plansDir := filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(plansDir, 0o750))
+		planFile := filepath.Join(plansDir, "feature.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+		require.NoError(t, svc.repo.add(planFile))
+		require.NoError(t, svc.repo.commit("add plan"))
+		require.NoError(t, svc.MovePlanToCompletedWithSummary(planFile, []byte("{}")))
+
+		log := &mockLogger{}
+		svc.log = log
+		reopened, err := svc.ReopenPlan(filepath.Join(plansDir, "completed", "feature.md"))
+		require.NoError(t, err)
+
+		assert.Equal(t, planFile, reopened)
+		assert.FileExists(t, planFile)
+		assert.NoFileExists(t, filepath.Join(plansDir, "completed", "feature.md"))
+		assert.FileExists(t, SummaryPath(planFile), "run summary stays in completed/")
+		assert.Equal(t, "reopen plan: feature.md\n", runGit(t, dir, "log", "-1", "--format=%s"))
+		assert.Empty(t, runGit(t, dir, "status", "--porcelain"))
+		require.Len(t, log.logs, 1)
+		assert.Contains(t, log.logs[0], "reopened plan")
+	})
+
+	t.Run("untracked plan", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		completedDir := filepath.Join(dir, "docs", "plans", "completed")
+		require.NoError(t, os.MkdirAll(completedDir, 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(completedDir, "feature.md"), []byte("# Plan"), 0o600))
+
+		reopened, err := svc.ReopenPlan(filepath.Join(completedDir, "feature.md"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "docs", "plans", "feature.md"), reopened)
+		assert.Equal(t, "reopen plan: feature.md\n", runGit(t, dir, "log", "-1", "--format=%s"))
+		assert.Empty(t, runGit(t, dir, "status", "--porcelain"))
+	})
+
+	t.Run("plan outside repo is moved without commit", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		head := runGit(t, dir, "rev-parse", "HEAD")
+
+		completedDir := filepath.Join(t.TempDir(), "completed")
+		require.NoError(t, os.MkdirAll(completedDir, 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(completedDir, "feature.md"), []byte("# Plan"), 0o600))
+
+		reopened, err := svc.ReopenPlan(filepath.Join(completedDir, "feature.md"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(filepath.Dir(completedDir), "feature.md"), reopened)
+		assert.FileExists(t, reopened)
+		assert.Equal(t, head, runGit(t, dir, "rev-parse", "HEAD"))
+	})
+
+	t.Run("not in completed dir", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		_, err = svc.ReopenPlan(filepath.Join(dir, "docs", "plans", "feature.md"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not in a completed/ directory")
+	})
+
+	t.Run("plan with the same name exists", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		completedDir := filepath.Join(dir, "docs", "plans", "completed")
+		require.NoError(t, os.MkdirAll(completedDir, 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(completedDir, "feature.md"), []byte("# Old"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "plans", "feature.md"), []byte("# New"), 0o600))
+
+		_, err = svc.ReopenPlan(filepath.Join(completedDir, "feature.md"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already exists")
+		assert.FileExists(t, filepath.Join(completedDir, "feature.md"))
+	})
+}
+
+func TestService_RecreatePlanBranch(t *testing.T) {
+	setup := func(t *testing.T) (svc *Service, dir, planFile, defaultBranch string) {
+		t.Helper()
+		dir = setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		defaultBranch, err = svc.CurrentBranch()
+		require.NoError(t, err)
+		planFile = filepath.Join(dir, "docs", "plans", "completed", "feature.md")
+		require.NoError(t, os.MkdirAll(filepath.Dir(planFile), 0o750))
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+		runGit(t, dir, "add", ".")
+		runGit(t, dir, "commit", "-m", "complete plan")
+		return svc, dir, planFile, defaultBranch
+	}
+
+	t.Run("merged branch moves to HEAD", func(t *testing.T) {
+		svc, dir, planFile, defaultBranch := setup(t)
+		runGit(t, dir, "branch", "feature", "HEAD~1")
+		log := &mockLogger{}
+		svc.log = log
+
+		require.NoError(t, svc.RecreatePlanBranch(planFile, defaultBranch, ""))
+		assert.Equal(t, runGit(t, dir, "rev-parse", "HEAD"), runGit(t, dir, "rev-parse", "feature"))
+		require.Len(t, log.logs, 1)
+		assert.Contains(t, log.logs[0], "recreating merged branch feature")
+	})
+
+	t.Run("branch with unmerged commits is kept", func(t *testing.T) {
+		svc, dir, planFile, defaultBranch := setup(t)
+		runGit(t, dir, "checkout", "-b", "feature")
+		runGit(t, dir, "commit", "--allow-empty", "-m", "unmerged work")
+		runGit(t, dir, "checkout", defaultBranch)
+		head := runGit(t, dir, "rev-parse", "feature")
+
+		require.NoError(t, svc.RecreatePlanBranch(planFile, defaultBranch, ""))
+		assert.Equal(t, head, runGit(t, dir, "rev-parse", "feature"))
+	})
+
+	t.Run("missing branch and branch name override", func(t *testing.T) {
+		svc, dir, planFile, defaultBranch := setup(t)
+		runGit(t, dir, "branch", "custom", "HEAD~1")
+
+		require.NoError(t, svc.RecreatePlanBranch(planFile, defaultBranch, ""))
+		assert.False(t, svc.repo.branchExists("feature"), "missing branch is left to CreateBranchForPlan")
+
+		require.NoError(t, svc.RecreatePlanBranch(planFile, defaultBranch, "custom"))
+		assert.Equal(t, runGit(t, dir, "rev-parse", "HEAD"), runGit(t, dir, "rev-parse", "custom"))
+	})
+
+	t.Run("no-op on feature branch", func(t *testing.T) {
+		svc, dir, planFile, defaultBranch := setup(t)
+		runGit(t, dir, "branch", "feature", "HEAD~1")
+		old := runGit(t, dir, "rev-parse", "feature")
+		runGit(t, dir, "checkout", "-b", "other")
+
+		require.NoError(t, svc.RecreatePlanBranch(planFile, defaultBranch, ""))
+		assert.Equal(t, old, runGit(t, dir, "rev-parse", "feature"))
+	})
+}
+
 func TestService_EnsureHasCommits(t *testing.T) {
 	t.Run("returns nil when repo has commits", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
@@ -787,6 +1325,788 @@ func TestService_DiffStats(t *testing.T) {
 		assert.Equal(t, 3, stats.Additions)
 		assert.Equal(t, 0, stats.Deletions)
 	})
+
+	t.Run("leaves out excluded paths", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		require.NoError(t, svc.CreateBranch("feature"))
+
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs", "plans"), 0o750))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, ".ralphex", "progress"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "plans", "plan.md"), []byte("# plan\n- [x] task\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".ralphex", "progress", "progress-plan.txt"), []byte("log\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "code.go"), []byte("package main\n"), 0o600))
+		runGit(t, dir, "add", "-A")
+		require.NoError(t, svc.repo.commit("add plan and code"))
+
+		stats, err := svc.DiffStats("master")
+		require.NoError(t, err)
+		assert.Equal(t, DiffStats{Files: 3, Additions: 4}, stats)
+
+		stats, err = svc.DiffStats("master", filepath.Join(dir, "docs", "plans", "plan.md"), ".ralphex/progress")
+		require.NoError(t, err)
+		assert.Equal(t, DiffStats{Files: 1, Additions: 1}, stats, "plan and progress dir excluded, absolute and relative")
+
+		stats, err = svc.DiffStats("master", "/outside/repo/plan.md")
+		require.NoError(t, err)
+		assert.Equal(t, 3, stats.Files, "paths outside the repository are ignored")
+
+		files, err := svc.DiffStatsPerFile("master", "docs/plans/plan.md", ".ralphex/progress")
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, "code.go", files[0].Path)
+	})
+}
+
+func TestService_DiffStatsRange(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+
+	before, err := svc.HeadHash()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "task1.txt"), []byte("line1\nline2\n"), 0o600))
+	require.NoError(t, svc.repo.add("task1.txt"))
+	require.NoError(t, svc.repo.commit("task 1"))
+	middle, err := svc.HeadHash()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "task2.txt"), []byte("line1\n"), 0o600))
+	require.NoError(t, svc.repo.add("task2.txt"))
+	require.NoError(t, svc.repo.commit("task 2"))
+	after, err := svc.HeadHash()
+	require.NoError(t, err)
+
+	stats, err := svc.DiffStatsRange(middle, after)
+	require.NoError(t, err)
+	assert.Equal(t, DiffStats{Files: 1, Additions: 1}, stats, "only the second task's commit")
+
+	stats, err = svc.DiffStatsRange(before, after)
+	require.NoError(t, err)
+	assert.Equal(t, DiffStats{Files: 2, Additions: 3}, stats)
+
+	stats, err = svc.DiffStatsRange(after, after)
+	require.NoError(t, err)
+	assert.Equal(t, DiffStats{}, stats)
+
+	_, err = svc.DiffStatsRange("0000000000000000000000000000000000000000", after)
+	require.Error(t, err)
+
+	// empty to compares against the working tree, staged and unstaged
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "task2.txt"), []byte("line1\nline2\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "staged.txt"), []byte("new\n"), 0o600))
+	require.NoError(t, svc.repo.add("staged.txt"))
+	stats, err = svc.DiffStatsRange("HEAD", "")
+	require.NoError(t, err)
+	assert.Equal(t, DiffStats{Files: 2, Additions: 2}, stats)
+}
+
+func TestService_DiffStatsPerFile(t *testing.T) {
+	t.Run("returns nil when on same branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		files, err := svc.DiffStatsPerFile("master")
+		require.NoError(t, err)
+		assert.Nil(t, files)
+	})
+
+	t.Run("returns nil for nonexistent branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		files, err := svc.DiffStatsPerFile("nonexistent")
+		require.NoError(t, err)
+		assert.Nil(t, files)
+	})
+
+	t.Run("reports text, binary and renamed files", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		content := "alpha\nbeta\ngamma\ndelta\nepsilon\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "old name.txt"), []byte(content), 0o600))
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "commit", "-m", "add file to rename")
+		runGit(t, dir, "branch", "base")
+
+		require.NoError(t, svc.CreateBranch("feature"))
+		runGit(t, dir, "mv", "old name.txt", "new name.txt")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "new name.txt"), []byte(content+"zeta\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "image.bin"), []byte{0x00, 0x01, 0x02, 0xff}, 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "code.go"), []byte("package main\n\nfunc main() {}\n"), 0o600))
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "commit", "-m", "feature changes")
+
+		files, err := svc.DiffStatsPerFile("base")
+		require.NoError(t, err)
+		assert.Equal(t, []FileDiffStat{
+			{Path: "code.go", Additions: 3},
+			{Path: "image.bin", Binary: true},
+			{Path: "new name.txt", OldPath: "old name.txt", Additions: 1},
+		}, files)
+	})
+}
+
+func TestService_ChangedFiles(t *testing.T) {
+	t.Run("returns nil when on same branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		files, err := svc.ChangedFiles("master")
+		require.NoError(t, err)
+		assert.Nil(t, files)
+		changes, err := svc.ChangedFilesWithStatus("master")
+		require.NoError(t, err)
+		assert.Nil(t, changes)
+	})
+
+	t.Run("returns nil for nonexistent branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		files, err := svc.ChangedFiles("nonexistent")
+		require.NoError(t, err)
+		assert.Nil(t, files)
+		changes, err := svc.ChangedFilesWithStatus("nonexistent")
+		require.NoError(t, err)
+		assert.Nil(t, changes)
+	})
+
+	t.Run("reports added, modified, deleted and renamed files", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		content := "alpha\nbeta\ngamma\ndelta\nepsilon\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "old name.txt"), []byte(content), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "gone.txt"), []byte("bye\n"), 0o600))
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "commit", "-m", "add files")
+		runGit(t, dir, "branch", "base")
+
+		require.NoError(t, svc.CreateBranch("feature"))
+		runGit(t, dir, "mv", "old name.txt", "new name.txt")
+		runGit(t, dir, "rm", "-q", "gone.txt")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("changed\n"), 0o600))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "code.go"), []byte("package pkg\n"), 0o600))
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "commit", "-m", "feature changes")
+
+		changes, err := svc.ChangedFilesWithStatus("base")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []ChangedFile{
+			{Path: "README.md", Status: StatusModified},
+			{Path: "gone.txt", Status: StatusDeleted},
+			{Path: "new name.txt", OldPath: "old name.txt", Status: StatusRenamed},
+			{Path: "pkg/code.go", Status: StatusAdded},
+		}, changes)
+
+		files, err := svc.ChangedFiles("base")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"README.md", "gone.txt", "new name.txt", "pkg/code.go"}, files)
+	})
+
+	t.Run("ignores changes on the base branch after the fork point", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		require.NoError(t, svc.CreateBranch("feature"))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "feature.txt"), []byte("feature\n"), 0o600))
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "commit", "-m", "feature change")
+		runGit(t, dir, "checkout", "-q", "master")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "master.txt"), []byte("master\n"), 0o600))
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "commit", "-m", "master change")
+		runGit(t, dir, "checkout", "-q", "feature")
+
+		files, err := svc.ChangedFiles("master")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"feature.txt"}, files)
+	})
+}
+
+func TestService_InProgressOperation(t *testing.T) {
+	// setupConflict creates a "feature" branch and a diverging master commit touching the same line,
+	// then runs the given git command expected to stop with a conflict.
+	setupConflict := func(t *testing.T, args ...string) string {
+		t.Helper()
+		dir := setupExternalTestRepo(t)
+		runGit(t, dir, "checkout", "-b", "feature")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("feature\n"), 0o600))
+		runGit(t, dir, "commit", "-am", "feature change")
+		runGit(t, dir, "checkout", "master")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("master\n"), 0o600))
+		runGit(t, dir, "commit", "-am", "master change")
+
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.Error(t, err, "expected conflict, got: %s", string(out))
+		return dir
+	}
+
+	t.Run("clean repo has no operation", func(t *testing.T) {
+		svc, err := NewService(setupExternalTestRepo(t), noopServiceLogger())
+		require.NoError(t, err)
+
+		op, err := svc.InProgressOperation()
+		require.NoError(t, err)
+		assert.Empty(t, op)
+		require.NoError(t, svc.AbortInProgress(), "abort without operation is a no-op")
+	})
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "merge conflict", args: []string{"merge", "feature"}, want: OpMerge},
+		{name: "rebase conflict", args: []string{"rebase", "feature"}, want: OpRebase},
+		{name: "cherry-pick conflict", args: []string{"cherry-pick", "feature"}, want: OpCherryPick},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			before := time.Now().Add(-time.Second) // file times can be coarser than the clock
+			dir := setupConflict(t, tc.args...)
+			log := &mockLogger{}
+			svc, err := NewService(dir, log)
+			require.NoError(t, err)
+
+			op, started, err := svc.InProgressOperationStarted()
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, op)
+			assert.True(t, started.After(before), "started %v, before %v", started, before)
+
+			require.NoError(t, svc.AbortInProgress())
+			op, err = svc.InProgressOperation()
+			require.NoError(t, err)
+			assert.Empty(t, op)
+
+			content, err := os.ReadFile(filepath.Join(dir, "README.md")) //nolint:gosec // test file
+			require.NoError(t, err)
+			assert.Equal(t, "master\n", string(content), "abort should restore pre-operation state")
+			assert.Contains(t, strings.Join(log.logs, ""), "aborted in-progress "+tc.want)
+		})
+	}
+
+	t.Run("git am session is ignored", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		amDir := filepath.Join(dir, ".git", "rebase-apply")
+		require.NoError(t, os.MkdirAll(amDir, 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(amDir, "applying"), nil, 0o600))
+
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		op, err := svc.InProgressOperation()
+		require.NoError(t, err)
+		assert.Empty(t, op)
+	})
+}
+
+func TestService_IsShallow(t *testing.T) {
+	t.Run("full clone", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		shallow, err := svc.IsShallow()
+		require.NoError(t, err)
+		assert.False(t, shallow)
+	})
+
+	t.Run("shallow clone and unshallow", func(t *testing.T) {
+		src := setupExternalTestRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(src, "a.txt"), []byte("a\n"), 0o600))
+		runGit(t, src, "add", "a.txt")
+		runGit(t, src, "commit", "-m", "second commit")
+
+		dir := filepath.Join(t.TempDir(), "clone")
+		runGit(t, t.TempDir(), "clone", "--depth", "1", "file://"+src, dir)
+		log := &mockLogger{}
+		svc, err := NewService(dir, log)
+		require.NoError(t, err)
+
+		shallow, err := svc.IsShallow()
+		require.NoError(t, err)
+		assert.True(t, shallow)
+
+		require.NoError(t, svc.Unshallow())
+		shallow, err = svc.IsShallow()
+		require.NoError(t, err)
+		assert.False(t, shallow)
+		assert.Contains(t, log.logs, "fetching full history of shallow clone\n")
+	})
+}
+
+func TestService_CommitLog(t *testing.T) {
+	t.Run("returns nil when on same branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		lines, err := svc.CommitLog("master")
+		require.NoError(t, err)
+		assert.Empty(t, lines)
+	})
+
+	t.Run("returns nil for nonexistent branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		lines, err := svc.CommitLog("nonexistent")
+		require.NoError(t, err)
+		assert.Empty(t, lines)
+	})
+
+	t.Run("returns commits on feature branch newest first", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		require.NoError(t, svc.CreateBranch("feature"))
+		for i, name := range []string{"first.txt", "second.txt"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("content\n"), 0o600))
+			require.NoError(t, svc.repo.add(name))
+			require.NoError(t, svc.repo.commit(fmt.Sprintf("add file %d", i+1)))
+		}
+
+		lines, err := svc.CommitLog("master")
+		require.NoError(t, err)
+		require.Len(t, lines, 2)
+		assert.True(t, strings.HasSuffix(lines[0], " add file 2"), "got %q", lines[0])
+		assert.True(t, strings.HasSuffix(lines[1], " add file 1"), "got %q", lines[1])
+	})
+}
+
+func TestService_CountAheadBehind(t *testing.T) {
+	commitFile := func(t *testing.T, svc *Service, dir, name string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("content\n"), 0o600))
+		require.NoError(t, svc.repo.add(name))
+		require.NoError(t, svc.repo.commit("add "+name))
+	}
+
+	t.Run("zeros when on same branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		ahead, behind, err := svc.CountAheadBehind("master")
+		require.NoError(t, err)
+		assert.Zero(t, ahead)
+		assert.Zero(t, behind)
+	})
+
+	t.Run("zeros for nonexistent branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		ahead, behind, err := svc.CountAheadBehind("nonexistent")
+		require.NoError(t, err)
+		assert.Zero(t, ahead)
+		assert.Zero(t, behind)
+	})
+
+	t.Run("counts both sides of diverged branches", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		require.NoError(t, svc.CreateBranch("feature"))
+		commitFile(t, svc, dir, "first.txt")
+		commitFile(t, svc, dir, "second.txt")
+		commitFile(t, svc, dir, "third.txt")
+		require.NoError(t, svc.repo.checkoutBranch("master"))
+		commitFile(t, svc, dir, "upstream.txt")
+		require.NoError(t, svc.repo.checkoutBranch("feature"))
+
+		ahead, behind, err := svc.CountAheadBehind("master")
+		require.NoError(t, err)
+		assert.Equal(t, 3, ahead)
+		assert.Equal(t, 1, behind)
+	})
+}
+
+func TestValidateBranchName(t *testing.T) {
+	tests := []struct {
+		name    string
+		branch  string
+		wantErr string
+	}{
+		{name: "simple", branch: "feature"},
+		{name: "with slash", branch: "feat/login-form"},
+		{name: "with dots", branch: "release-1.2"},
+		{name: "empty", branch: "", wantErr: "empty"},
+		{name: "at sign only", branch: "@", wantErr: "can't be"},
+		{name: "leading dash", branch: "-feature", wantErr: "start with"},
+		{name: "leading slash", branch: "/feature", wantErr: "start or end"},
+		{name: "trailing slash", branch: "feature/", wantErr: "start or end"},
+		{name: "trailing dot", branch: "feature.", wantErr: "end with"},
+		{name: "double dot", branch: "a..b", wantErr: "can't contain"},
+		{name: "double slash", branch: "a//b", wantErr: "can't contain"},
+		{name: "reflog syntax", branch: "a@{1}", wantErr: "can't contain"},
+		{name: "space", branch: "my feature", wantErr: "invalid character"},
+		{name: "colon", branch: "a:b", wantErr: "invalid character"},
+		{name: "tilde", branch: "a~1", wantErr: "invalid character"},
+		{name: "backslash", branch: `a\b`, wantErr: "invalid character"},
+		{name: "control char", branch: "a\tb", wantErr: "invalid character"},
+		{name: "component starts with dot", branch: "feat/.hidden", wantErr: "invalid component"},
+		{name: "component ends with lock", branch: "feat.lock/x", wantErr: "invalid component"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateBranchName(tc.branch)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				// cross-check with git itself
+				out, gitErr := exec.Command("git", "check-ref-format", "--branch", tc.branch).CombinedOutput()
+				require.NoError(t, gitErr, string(out))
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestService_CreateTag(t *testing.T) {
+	t.Run("creates annotated tag on head", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		require.NoError(t, svc.CreateTag("ralphex/feature-2026-01-22", "completed plan feature.md"))
+		assert.True(t, svc.repo.tagExists("ralphex/feature-2026-01-22"))
+
+		msg, err := exec.Command("git", "-C", dir, "tag", "-l", "--format=%(contents:subject)", "ralphex/feature-2026-01-22").Output()
+		require.NoError(t, err)
+		assert.Equal(t, "completed plan feature.md", strings.TrimSpace(string(msg)))
+	})
+
+	t.Run("appends counter when tag exists", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		for range 3 {
+			require.NoError(t, svc.CreateTag("ralphex/feature-2026-01-22", "msg"))
+		}
+		assert.True(t, svc.repo.tagExists("ralphex/feature-2026-01-22"))
+		assert.True(t, svc.repo.tagExists("ralphex/feature-2026-01-22-2"))
+		assert.True(t, svc.repo.tagExists("ralphex/feature-2026-01-22-3"))
+	})
+
+	t.Run("invalid tag name returns error", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		err = svc.CreateTag("bad..name", "msg")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "create tag bad..name")
+	})
+}
+
+func TestService_CommitToOrphanBranch(t *testing.T) {
+	t.Run("creates orphan branch without touching working tree", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		logPath := filepath.Join(dir, ".ralphex", "progress", "progress-feature.txt")
+		require.NoError(t, os.MkdirAll(filepath.Dir(logPath), 0o750))
+		require.NoError(t, os.WriteFile(logPath, []byte("run 1\n"), 0o600))
+		headBefore := runGit(t, dir, "rev-parse", "HEAD")
+
+		require.NoError(t, svc.CommitToOrphanBranch("ralphex-logs", logPath))
+
+		assert.Equal(t, headBefore, runGit(t, dir, "rev-parse", "HEAD"), "HEAD must not move")
+		assert.Equal(t, "master\n", runGit(t, dir, "branch", "--show-current"))
+		assert.Equal(t, "?? .ralphex/\n", runGit(t, dir, "status", "--porcelain"), "log stays untracked on master")
+		assert.Equal(t, "run 1\n", runGit(t, dir, "show", "ralphex-logs:.ralphex/progress/progress-feature.txt"))
+		assert.Empty(t, strings.TrimSpace(runGit(t, dir, "log", "--format=%P", "ralphex-logs")), "first commit has no parent")
+		assert.Equal(t, ".ralphex/progress/progress-feature.txt\n", runGit(t, dir, "ls-tree", "-r", "--name-only", "ralphex-logs"),
+			"branch shares no files with master")
+	})
+
+	t.Run("appends to existing branch and skips unchanged files", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		first := filepath.Join(dir, "progress-a.txt")
+		second := filepath.Join(dir, "progress-b.txt")
+		require.NoError(t, os.WriteFile(first, []byte("a\n"), 0o600))
+		require.NoError(t, os.WriteFile(second, []byte("b\n"), 0o600))
+
+		require.NoError(t, svc.CommitToOrphanBranch("ralphex-logs", first))
+		require.NoError(t, svc.CommitToOrphanBranch("ralphex-logs", second))
+		require.NoError(t, svc.CommitToOrphanBranch("ralphex-logs", second), "unchanged file is a no-op")
+
+		require.NoError(t, os.WriteFile(first, []byte("a\nmore\n"), 0o600))
+		require.NoError(t, svc.CommitToOrphanBranch("ralphex-logs", first))
+
+		assert.Equal(t, "3\n", runGit(t, dir, "rev-list", "--count", "ralphex-logs"))
+		assert.Equal(t, "progress-a.txt\nprogress-b.txt\n", runGit(t, dir, "ls-tree", "--name-only", "ralphex-logs"))
+		assert.Equal(t, "a\nmore\n", runGit(t, dir, "show", "ralphex-logs:progress-a.txt"))
+		assert.Equal(t, "update progress-a.txt\n", runGit(t, dir, "log", "-1", "--format=%s", "ralphex-logs"))
+	})
+
+	t.Run("rejects checked out branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		err = svc.CommitToOrphanBranch("master", filepath.Join(dir, "README.md"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checked out")
+	})
+
+	t.Run("rejects invalid branch name and missing files", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		require.Error(t, svc.CommitToOrphanBranch("bad..name", filepath.Join(dir, "README.md")))
+		require.Error(t, svc.CommitToOrphanBranch("ralphex-logs"))
+		err = svc.CommitToOrphanBranch("ralphex-logs", filepath.Join(dir, "missing.txt"))
+		require.Error(t, err)
+		assert.False(t, svc.repo.branchExists("ralphex-logs"))
+	})
+}
+
+func TestService_LatestTagMatching(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+
+	commitFile := func(name string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("content\n"), 0o600))
+		require.NoError(t, svc.repo.add(name))
+		require.NoError(t, svc.repo.commit("add "+name))
+	}
+
+	tag, err := svc.LatestTagMatching("ralphex/*")
+	require.NoError(t, err)
+	assert.Empty(t, tag, "no tags yet")
+
+	require.NoError(t, svc.CreateTag("ralphex/first-2026-01-20", "first"))
+	commitFile("second.txt")
+	require.NoError(t, svc.CreateTag("ralphex/second-2026-01-21", "second"))
+	require.NoError(t, svc.CreateTag("v1.0.0", "release"))
+	commitFile("third.txt")
+
+	tag, err = svc.LatestTagMatching("ralphex/*")
+	require.NoError(t, err)
+	assert.Equal(t, "ralphex/second-2026-01-21", tag, "nearest matching tag, other tags ignored")
+
+	// a tag on another branch is not reachable from HEAD
+	require.NoError(t, svc.CreateBranch("other"))
+	commitFile("other.txt")
+	require.NoError(t, svc.CreateTag("ralphex/other-2026-01-22", "other"))
+	require.NoError(t, svc.repo.checkoutBranch("master"))
+
+	tag, err = svc.LatestTagMatching("ralphex/*")
+	require.NoError(t, err)
+	assert.Equal(t, "ralphex/second-2026-01-21", tag)
+}
+
+func TestService_Stash(t *testing.T) {
+	t.Run("nothing to stash", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		stashed, err := svc.StashPush("ralphex autostash")
+		require.NoError(t, err)
+		assert.False(t, stashed)
+	})
+
+	t.Run("stashes and restores changes except excluded paths", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("scratch\n"), 0o600))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs", "plans"), 0o750))
+		planFile := filepath.Join(dir, "docs", "plans", "feature.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n"), 0o600))
+
+		stashed, err := svc.StashPush("ralphex autostash", planFile)
+		require.NoError(t, err)
+		assert.True(t, stashed)
+
+		dirty, err := svc.repo.hasChangesOtherThan(planFile)
+		require.NoError(t, err)
+		assert.Empty(t, dirty, "only the excluded plan file should remain")
+		assert.FileExists(t, planFile)
+		assert.NoFileExists(t, filepath.Join(dir, "notes.txt"))
+
+		require.NoError(t, svc.StashPop())
+		data, err := os.ReadFile(filepath.Join(dir, "README.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "# Changed\n", string(data))
+		assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+	})
+
+	t.Run("pop with empty stash", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		require.ErrorIs(t, svc.StashPop(), ErrNoStash)
+	})
+
+	t.Run("pop conflict keeps stash entry", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Stashed\n"), 0o600))
+		stashed, err := svc.StashPush("ralphex autostash")
+		require.NoError(t, err)
+		require.True(t, stashed)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Committed\n"), 0o600))
+		runGit(t, dir, "commit", "-am", "conflicting change")
+
+		err = svc.StashPop()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "kept in the stash")
+		assert.Contains(t, runGit(t, dir, "stash", "list"), "ralphex autostash")
+	})
+}
+
+func TestService_ResetHard(t *testing.T) {
+	// setupFeatureBranch creates a feature branch with two commits on top of master, returns dir and first commit hash.
+	setupFeatureBranch := func(t *testing.T) (string, string) {
+		t.Helper()
+		dir := setupExternalTestRepo(t)
+		runGit(t, dir, "checkout", "-b", "feature")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("good\n"), 0o600))
+		runGit(t, dir, "add", "a.txt")
+		runGit(t, dir, "commit", "-m", "good change")
+		good := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("bad\n"), 0o600))
+		runGit(t, dir, "commit", "-am", "bad change")
+		return dir, good
+	}
+
+	t.Run("resets feature branch to commit", func(t *testing.T) {
+		dir, good := setupFeatureBranch(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("dirty\n"), 0o600))
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		require.NoError(t, svc.ResetHard(good, ""))
+
+		head, err := svc.HeadHash()
+		require.NoError(t, err)
+		assert.Equal(t, good, head)
+		data, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "good\n", string(data))
+		data, err = os.ReadFile(filepath.Join(dir, "README.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "# Test\n", string(data), "uncommitted changes discarded")
+	})
+
+	t.Run("accepts relative ref", func(t *testing.T) {
+		dir, good := setupFeatureBranch(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		require.NoError(t, svc.ResetHard("HEAD~1", ""))
+		head, err := svc.HeadHash()
+		require.NoError(t, err)
+		assert.Equal(t, good, head)
+	})
+
+	t.Run("refuses default branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		err = svc.ResetHard("HEAD", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `refusing to reset default branch "master"`)
+	})
+
+	t.Run("refuses main even when not detected default", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		runGit(t, dir, "checkout", "-b", "main")
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		err = svc.ResetHard("HEAD", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `refusing to reset default branch "main"`)
+	})
+
+	t.Run("refuses configured default branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		runGit(t, dir, "checkout", "-b", "release")
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		err = svc.ResetHard("HEAD", "release")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `refusing to reset default branch "release"`)
+
+		err = svc.ResetHard("HEAD", "origin/release")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `refusing to reset default branch "release"`)
+	})
+
+	t.Run("refuses detached head", func(t *testing.T) {
+		dir, good := setupFeatureBranch(t)
+		runGit(t, dir, "checkout", "--detach", good)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		err = svc.ResetHard("HEAD~1", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "detached HEAD")
+	})
+
+	t.Run("unknown ref leaves branch intact", func(t *testing.T) {
+		dir, _ := setupFeatureBranch(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		before, err := svc.HeadHash()
+		require.NoError(t, err)
+
+		err = svc.ResetHard("no-such-ref", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown commit "no-such-ref"`)
+		after, err := svc.HeadHash()
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("rejects option-like ref", func(t *testing.T) {
+		dir, _ := setupFeatureBranch(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		err = svc.ResetHard("--hard", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid ref")
+	})
 }
 
 func TestService_CreateWorktreeForPlan(t *testing.T) {
@@ -802,7 +2122,7 @@ func TestService_CreateWorktreeForPlan(t *testing.T) {
 		planFile := filepath.Join(plansDir, "add-worktree.md")
 		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
 
-		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master")
+		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master", "")
 		require.NoError(t, err)
 		assert.True(t, planNeedsCommit, "untracked plan file should need commit")
 		assert.Contains(t, wtPath, filepath.Join(".ralphex", "worktrees", "add-worktree"))
@@ -818,6 +2138,29 @@ func TestService_CreateWorktreeForPlan(t *testing.T) {
 		require.NoError(t, svc.RemoveWorktree(wtPath))
 	})
 
+	t.Run("uses branch name override for branch and path", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		plansDir := filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(plansDir, 0o750))
+		planFile := filepath.Join(plansDir, "2026-01-22-add-worktree.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+
+		wtPath, _, err := svc.CreateWorktreeForPlan(planFile, "master", "custom-name")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(svc.Root(), ".ralphex", "worktrees", "custom-name"), wtPath)
+
+		wtSvc, err := NewService(wtPath, noopServiceLogger())
+		require.NoError(t, err)
+		branch, err := wtSvc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "custom-name", branch)
+
+		require.NoError(t, svc.RemoveWorktree(wtPath))
+	})
+
 	t.Run("creates worktree with existing branch", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
 		svc, err := NewService(dir, noopServiceLogger())
@@ -838,7 +2181,7 @@ func TestService_CreateWorktreeForPlan(t *testing.T) {
 		log := &mockLogger{}
 		svc.log = log
 
-		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master")
+		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master", "")
 		require.NoError(t, err)
 		assert.False(t, planNeedsCommit, "already-committed plan file should not need commit")
 
@@ -864,7 +2207,7 @@ func TestService_CreateWorktreeForPlan(t *testing.T) {
 		require.NoError(t, svc.CreateBranch("feature"))
 
 		planFile := filepath.Join(dir, "docs", "plans", "feature.md")
-		_, _, err = svc.CreateWorktreeForPlan(planFile, "master")
+		_, _, err = svc.CreateWorktreeForPlan(planFile, "master", "")
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "requires master branch")
 	})
@@ -878,7 +2221,7 @@ func TestService_CreateWorktreeForPlan(t *testing.T) {
 		require.NoError(t, svc.CreateBranch("feature"))
 
 		planFile := filepath.Join(dir, "docs", "plans", "feature.md")
-		_, _, err = svc.CreateWorktreeForPlan(planFile, "")
+		_, _, err = svc.CreateWorktreeForPlan(planFile, "", "")
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "requires main/master branch")
 	})
@@ -892,7 +2235,7 @@ func TestService_CreateWorktreeForPlan(t *testing.T) {
 		require.NoError(t, svc.CreateBranch("feature"))
 
 		planFile := filepath.Join(dir, "docs", "plans", "feature.md")
-		_, _, err = svc.CreateWorktreeForPlan(planFile, "develop")
+		_, _, err = svc.CreateWorktreeForPlan(planFile, "develop", "")
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "requires develop branch")
 	})
@@ -910,7 +2253,7 @@ func TestService_CreateWorktreeForPlan(t *testing.T) {
 		planFile := filepath.Join(plansDir, "develop-feature.md")
 		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
 
-		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "develop")
+		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "develop", "")
 		require.NoError(t, err)
 		assert.Contains(t, wtPath, "develop-feature")
 		assert.True(t, planNeedsCommit, "untracked plan file should need commit")
@@ -933,7 +2276,7 @@ func TestService_CreateWorktreeForPlan(t *testing.T) {
 		// create another uncommitted file
 		require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("other"), 0o600))
 
-		_, _, err = svc.CreateWorktreeForPlan(planFile, "master")
+		_, _, err = svc.CreateWorktreeForPlan(planFile, "master", "")
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "cannot create worktree")
 		assert.Contains(t, err.Error(), "uncommitted changes")
@@ -952,7 +2295,7 @@ func TestService_CreateWorktreeForPlan(t *testing.T) {
 		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
 
 		// create first worktree
-		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master")
+		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master", "")
 		require.NoError(t, err)
 		assert.True(t, planNeedsCommit, "untracked plan file should need commit")
 
@@ -960,7 +2303,7 @@ func TestService_CreateWorktreeForPlan(t *testing.T) {
 		require.NoError(t, svc.repo.checkoutBranch("master"))
 
 		// second attempt should fail
-		_, _, err = svc.CreateWorktreeForPlan(planFile, "master")
+		_, _, err = svc.CreateWorktreeForPlan(planFile, "master", "")
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "worktree already exists")
 
@@ -980,7 +2323,7 @@ func TestService_CreateWorktreeForPlan(t *testing.T) {
 		planFile := filepath.Join(plansDir, "new-feature.md")
 		require.NoError(t, os.WriteFile(planFile, []byte("# New Feature"), 0o600))
 
-		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master")
+		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master", "")
 		require.NoError(t, err)
 		assert.True(t, planNeedsCommit, "untracked plan file should need commit")
 
@@ -1008,7 +2351,7 @@ func TestService_CreateWorktreeForPlan(t *testing.T) {
 		planFile := filepath.Join(plansDir, "no-commit-on-main.md")
 		require.NoError(t, os.WriteFile(planFile, []byte("# Regression Test"), 0o600))
 
-		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master")
+		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master", "")
 		require.NoError(t, err)
 		assert.True(t, planNeedsCommit)
 
@@ -1032,7 +2375,7 @@ func TestService_CreateWorktreeForPlan(t *testing.T) {
 		planFile := filepath.Join(plansDir, "branch-conflict.md")
 		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
 
-		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master")
+		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master", "")
 		require.NoError(t, err)
 		assert.True(t, planNeedsCommit, "untracked plan file should need commit")
 		defer svc.RemoveWorktree(wtPath) //nolint:errcheck // cleanup
@@ -1055,7 +2398,7 @@ func TestService_CreateWorktreeForPlan(t *testing.T) {
 		planFile := filepath.Join(plansDir, "2024-01-15-add-auth.md")
 		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
 
-		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master")
+		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master", "")
 		require.NoError(t, err)
 		assert.True(t, planNeedsCommit, "untracked plan file should need commit")
 		assert.Contains(t, wtPath, "add-auth")
@@ -1086,7 +2429,7 @@ func TestService_CommitPlanFile(t *testing.T) {
 		require.NoError(t, os.WriteFile(planFile, []byte("# Commit Test Plan"), 0o600))
 
 		// create worktree (plan is copied in)
-		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master")
+		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master", "")
 		require.NoError(t, err)
 		assert.True(t, planNeedsCommit)
 
@@ -1124,7 +2467,7 @@ func TestService_RemoveWorktree(t *testing.T) {
 		planFile := filepath.Join(plansDir, "rm-test.md")
 		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
 
-		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master")
+		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master", "")
 		require.NoError(t, err)
 		assert.True(t, planNeedsCommit)
 
@@ -1160,7 +2503,7 @@ func TestService_RemoveWorktree(t *testing.T) {
 		planFile := filepath.Join(plansDir, "preserve-branch.md")
 		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
 
-		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master")
+		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planFile, "master", "")
 		require.NoError(t, err)
 		assert.True(t, planNeedsCommit)
 
@@ -1201,6 +2544,21 @@ func TestService_CommitIgnoreChanges(t *testing.T) {
 		assert.GreaterOrEqual(t, len(log.logs), 2, "should have log for EnsureIgnored and CommitIgnoreChanges")
 	})
 
+	t.Run("appends commit trailer", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, &mockLogger{})
+		require.NoError(t, err)
+		svc.SetCommitTrailer("Generated-by: ralphex v1.2.3")
+
+		require.NoError(t, svc.EnsureIgnored(".ralphex/progress/", ".ralphex/progress/progress-test.txt"))
+		require.NoError(t, svc.CommitIgnoreChanges())
+
+		msg := runGit(t, dir, "log", "-1", "--format=%B")
+		assert.Equal(t, "add ralphex entries to .gitignore\n\nGenerated-by: ralphex v1.2.3\n\n", msg)
+		trailers := runGit(t, dir, "log", "-1", "--format=%(trailers:key=Generated-by,valueonly)")
+		assert.Equal(t, "ralphex v1.2.3", strings.TrimSpace(trailers))
+	})
+
 	t.Run("no-op when gitignore is clean", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
 		log := &mockLogger{}
@@ -1285,6 +2643,21 @@ func TestService_FileHasChanges(t *testing.T) {
 	})
 }
 
+func TestService_WorkingTreeStatus(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, &mockLogger{})
+	require.NoError(t, err)
+
+	changes, err := svc.WorkingTreeStatus()
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Test\nline\n"), 0o600))
+	changes, err = svc.WorkingTreeStatus()
+	require.NoError(t, err)
+	assert.Equal(t, []FileChange{{Path: "README.md", Status: "M", Additions: 1}}, changes)
+}
+
 func TestService_formatDirtyFiles(t *testing.T) {
 	svc := &Service{}
 
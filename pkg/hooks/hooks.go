@@ -0,0 +1,118 @@
+// Package hooks runs user lifecycle scripts from a hooks directory (.ralphex/hooks/ by default).
+// scripts are named after the lifecycle point they attach to, e.g. pre-task or post-review.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// Point is a lifecycle point where a hook script can run. the value is the script file name.
+type Point string
+
+// lifecycle points, in pipeline order.
+const (
+	PreRun       Point = "pre-run"       // before execution starts, after branch/worktree setup
+	PreTask      Point = "pre-task"      // before the task phase
+	PostTask     Point = "post-task"     // after the task phase completed
+	PreReview    Point = "pre-review"    // before each claude review stage
+	PostReview   Point = "post-review"   // after each claude review stage completed
+	PreCodex     Point = "pre-codex"     // before the external review loop
+	PostCodex    Point = "post-codex"    // after the external review loop completed
+	PreFinalize  Point = "pre-finalize"  // before the finalize step, only when finalize is enabled
+	PostFinalize Point = "post-finalize" // after the finalize step
+	PostRun      Point = "post-run"      // after execution ends, whether it succeeded or not
+)
+
+// AllPoints lists all lifecycle points in pipeline order.
+var AllPoints = []Point{PreRun, PreTask, PostTask, PreReview, PostReview, PreCodex, PostCodex, PreFinalize, PostFinalize, PostRun}
+
+// IsKnown reports whether p is one of the defined lifecycle points.
+func (p Point) IsKnown() bool {
+	return slices.Contains(AllPoints, p)
+}
+
+// Failure controls what a failing hook does to the run.
+type Failure string
+
+const (
+	FailureFatal Failure = "fatal" // abort the run
+	FailureWarn  Failure = "warn"  // log a warning and continue
+)
+
+// DefaultFailure returns the failure mode used when none is configured for p.
+// pre hooks prepare the environment, so their failure is fatal. post hooks only warn.
+func DefaultFailure(p Point) Failure {
+	if strings.HasPrefix(string(p), "pre-") {
+		return FailureFatal
+	}
+	return FailureWarn
+}
+
+// Logger is the subset of the progress logger used to report hook runs.
+type Logger interface {
+	Print(format string, args ...any)
+	PrintAligned(text string)
+}
+
+// Runner discovers and runs hook scripts. a nil Runner or one with an empty Dir runs nothing.
+type Runner struct {
+	Dir     string            // directory with hook scripts
+	Failure map[Point]Failure // per-point failure mode, unset points use DefaultFailure
+	Env     []string          // extra environment for every hook as KEY=VALUE, e.g. RALPHEX_PLAN_FILE
+	Log     Logger            // receives the run notice, script output and warnings
+}
+
+// Run executes the script for p if it exists in Dir. phase is passed to the script as RALPHEX_PHASE.
+// returns an error only when the script fails and its failure mode is fatal, otherwise logs a warning.
+func (r *Runner) Run(ctx context.Context, p Point, phase string) error {
+	if r == nil || r.Dir == "" {
+		return nil
+	}
+	script := filepath.Join(r.Dir, string(p))
+	info, err := os.Stat(script)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+		return nil
+	}
+
+	env := append(slices.Clone(r.Env), "RALPHEX_HOOK="+string(p), "RALPHEX_PHASE="+phase)
+	runErr := err
+	if runErr == nil {
+		runErr = r.exec(ctx, script, info, env)
+	}
+	if runErr == nil {
+		return nil
+	}
+
+	failure := DefaultFailure(p)
+	if f, ok := r.Failure[p]; ok {
+		failure = f
+	}
+	if failure == FailureFatal {
+		return fmt.Errorf("%s hook: %w", p, runErr)
+	}
+	r.Log.Print("warning: %s hook: %v", p, runErr)
+	return nil
+}
+
+// exec runs a discovered script, rejecting files without the executable bit on unix.
+func (r *Runner) exec(ctx context.Context, script string, info fs.FileInfo, env []string) error {
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0 {
+		return fmt.Errorf("%s is not executable", script)
+	}
+	r.Log.Print("running %s hook: %s", filepath.Base(script), script)
+	hook := &executor.HookExecutor{Script: script, Env: env, OutputHandler: r.Log.PrintAligned}
+	if err := hook.Run(ctx); err != nil {
+		return fmt.Errorf("run %s: %w", script, err)
+	}
+	return nil
+}
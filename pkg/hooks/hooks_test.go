@@ -0,0 +1,135 @@
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLogger records printed lines.
+type testLogger struct {
+	out strings.Builder
+}
+
+func (l *testLogger) Print(format string, args ...any) { fmt.Fprintf(&l.out, format+"\n", args...) }
+func (l *testLogger) PrintAligned(text string)         { l.out.WriteString(text) }
+
+// writeHook creates an executable hook script in dir.
+func writeHook(t *testing.T, dir string, p Point, body string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, string(p)), []byte("#!/bin/sh\n"+body+"\n"), 0o700))
+}
+
+func TestPoint_IsKnown(t *testing.T) {
+	for _, p := range AllPoints {
+		assert.True(t, p.IsKnown(), p)
+	}
+	assert.False(t, Point("pre-commit").IsKnown())
+	assert.False(t, Point("").IsKnown())
+}
+
+func TestDefaultFailure(t *testing.T) {
+	tests := []struct {
+		point Point
+		want  Failure
+	}{
+		{PreRun, FailureFatal},
+		{PreTask, FailureFatal},
+		{PreFinalize, FailureFatal},
+		{PostTask, FailureWarn},
+		{PostReview, FailureWarn},
+		{PostRun, FailureWarn},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.point), func(t *testing.T) {
+			assert.Equal(t, tc.want, DefaultFailure(tc.point))
+		})
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh scripts")
+	}
+
+	t.Run("nil runner is a no-op", func(t *testing.T) {
+		var r *Runner
+		require.NoError(t, r.Run(t.Context(), PreTask, "task"))
+	})
+
+	t.Run("missing script is skipped", func(t *testing.T) {
+		log := &testLogger{}
+		r := &Runner{Dir: t.TempDir(), Log: log}
+		require.NoError(t, r.Run(t.Context(), PreTask, "task"))
+		assert.Empty(t, log.out.String())
+	})
+
+	t.Run("missing dir is skipped", func(t *testing.T) {
+		r := &Runner{Dir: filepath.Join(t.TempDir(), "nope"), Log: &testLogger{}}
+		require.NoError(t, r.Run(t.Context(), PreTask, "task"))
+	})
+
+	t.Run("runs script with env and streams output", func(t *testing.T) {
+		dir := t.TempDir()
+		writeHook(t, dir, PostReview, `echo "$RALPHEX_HOOK $RALPHEX_PHASE $RALPHEX_BRANCH"`)
+		log := &testLogger{}
+		r := &Runner{Dir: dir, Env: []string{"RALPHEX_BRANCH=feature"}, Log: log}
+
+		require.NoError(t, r.Run(t.Context(), PostReview, "review"))
+		assert.Contains(t, log.out.String(), "running post-review hook: "+filepath.Join(dir, "post-review"))
+		assert.Contains(t, log.out.String(), "post-review review feature\n")
+	})
+
+	t.Run("pre hook failure is fatal by default", func(t *testing.T) {
+		dir := t.TempDir()
+		writeHook(t, dir, PreTask, "echo broken; exit 1")
+		log := &testLogger{}
+		r := &Runner{Dir: dir, Log: log}
+
+		err := r.Run(t.Context(), PreTask, "task")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pre-task hook:")
+		assert.Contains(t, err.Error(), "exit status 1")
+		assert.Contains(t, log.out.String(), "broken\n")
+	})
+
+	t.Run("post hook failure warns by default", func(t *testing.T) {
+		dir := t.TempDir()
+		writeHook(t, dir, PostTask, "exit 1")
+		log := &testLogger{}
+		r := &Runner{Dir: dir, Log: log}
+
+		require.NoError(t, r.Run(t.Context(), PostTask, "task"))
+		assert.Contains(t, log.out.String(), "warning: post-task hook:")
+	})
+
+	t.Run("configured failure mode overrides default", func(t *testing.T) {
+		dir := t.TempDir()
+		writeHook(t, dir, PreTask, "exit 1")
+		writeHook(t, dir, PostTask, "exit 1")
+		log := &testLogger{}
+		r := &Runner{Dir: dir, Log: log, Failure: map[Point]Failure{PreTask: FailureWarn, PostTask: FailureFatal}}
+
+		require.NoError(t, r.Run(t.Context(), PreTask, "task"))
+		assert.Contains(t, log.out.String(), "warning: pre-task hook:")
+		require.Error(t, r.Run(t.Context(), PostTask, "task"))
+	})
+
+	t.Run("non-executable script fails", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "pre-codex"), []byte("#!/bin/sh\necho hi\n"), 0o600))
+		log := &testLogger{}
+		r := &Runner{Dir: dir, Log: log}
+
+		err := r.Run(t.Context(), PreCodex, "codex")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not executable")
+		assert.NotContains(t, log.out.String(), "hi")
+	})
+}
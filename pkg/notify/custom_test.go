@@ -118,5 +118,6 @@ func TestCustomChannel_Send(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "failure", got.Status)
 		assert.Equal(t, "task phase: max iterations reached", got.Error)
+		assert.NotContains(t, string(data), "tests_passed", "omitted when no test command ran")
 	})
 }
@@ -38,8 +38,9 @@ type Params struct {
 
 // Service orchestrates sending notifications through configured channels.
 type Service struct {
-	channels   []channel      // paired notifier + destination
-	custom     *customChannel // optional custom script channel
+	channels   []channel       // paired notifier + destination
+	custom     *customChannel  // optional custom script channel
+	disabled   []ChannelResult // configured channels skipped at creation, reported by SendTest
 	onError    bool
 	onComplete bool
 	timeoutMs  int
@@ -51,7 +52,14 @@ type Service struct {
 type channel struct {
 	notifier   ntfy.Notifier
 	dest       string
-	htmlEscape bool // true for channels that use HTML parse mode (e.g., telegram)
+	name       string // channel label for reports, without secrets (e.g., "slack" or "webhook example.com")
+	htmlEscape bool   // true for channels that use HTML parse mode (e.g., telegram)
+}
+
+// ChannelResult reports the outcome of a test notification for a single channel.
+type ChannelResult struct {
+	Channel string // channel label, e.g. "telegram" or "webhook example.com"
+	Err     error  // nil when the notification was sent
 }
 
 // logger interface for dependency injection.
@@ -70,6 +78,14 @@ type Result struct {
 	Additions int    `json:"additions"`
 	Deletions int    `json:"deletions"`
 	Error     string `json:"error,omitempty"`
+	RunID     string `json:"run_id,omitempty"` // run identifier, also in the progress log header and dashboard title
+
+	// test results of the run with report_test_results, tests_run and tests_passed are omitted when no test command ran
+	TestsRun    bool    `json:"tests_run,omitempty"`
+	TestsPassed *bool   `json:"tests_passed,omitempty"` // nil when no test command ran
+	Coverage    float64 `json:"coverage,omitempty"`     // total coverage percent, 0 when not reported by the test command
+
+	DashboardURL string `json:"dashboard_url,omitempty"` // web dashboard link, set when the run was started with --serve
 }
 
 // New creates a notification Service from the given Params.
@@ -113,6 +129,7 @@ func New(p Params, log logger) (*Service, error) {
 				// redact the token from the error to avoid leaking it in logs
 				errMsg := strings.ReplaceAll(cErr.Error(), p.TelegramToken, "[REDACTED]")
 				log.Print("[WARN] telegram channel disabled: %s", errMsg)
+				svc.disabled = append(svc.disabled, ChannelResult{Channel: "telegram", Err: errors.New("disabled: " + errMsg)})
 				continue
 			}
 			svc.channels = append(svc.channels, c)
@@ -192,13 +209,49 @@ func (s *Service) Send(ctx context.Context, r Result) {
 	}
 }
 
+// SendTest sends a synthetic notification to every configured channel and returns one result per channel,
+// in configuration order, followed by channels disabled at creation. on_error/on_complete are ignored,
+// the message is sent with the same timeout as Send. nil-safe, returns nil when no channels are configured.
+func (s *Service) SendTest(ctx context.Context) []ChannelResult {
+	if s == nil {
+		return nil
+	}
+
+	r := Result{Status: "test", Mode: "test", Branch: "notification-test", Duration: "0s"}
+	msg := s.formatMessage(r)
+
+	timeout := time.Duration(s.timeoutMs) * time.Millisecond
+	sendCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	results := make([]ChannelResult, 0, len(s.channels)+len(s.disabled)+1)
+	for _, ch := range s.channels {
+		text := msg
+		if ch.htmlEscape {
+			text = html.EscapeString(msg)
+		}
+		name := ch.name
+		if name == "" {
+			name = fmt.Sprint(ch.notifier)
+		}
+		results = append(results, ChannelResult{Channel: name, Err: ch.notifier.Send(sendCtx, ch.dest, text)})
+	}
+	if s.custom != nil {
+		results = append(results, ChannelResult{Channel: "custom", Err: s.custom.send(sendCtx, r)})
+	}
+	return append(results, s.disabled...)
+}
+
 // formatMessage creates a plain text notification message from the result.
 func (s *Service) formatMessage(r Result) string {
 	var b strings.Builder
 
-	if r.Status == "success" {
+	switch r.Status {
+	case "success":
 		fmt.Fprintf(&b, "ralphex completed on %s\n", s.hostname)
-	} else {
+	case "test":
+		fmt.Fprintf(&b, "ralphex test notification from %s\n", s.hostname)
+	default:
 		fmt.Fprintf(&b, "ralphex failed on %s\n", s.hostname)
 	}
 
@@ -216,18 +269,41 @@ func (s *Service) formatMessage(r Result) string {
 	if r.Duration != "" {
 		fmt.Fprintf(&b, "duration: %s\n", r.Duration)
 	}
+	if r.RunID != "" {
+		fmt.Fprintf(&b, "run:      %s\n", r.RunID)
+	}
 
 	if r.Status == "success" {
 		fmt.Fprintf(&b, "changes:  %d files (+%d/-%d lines)\n", r.Files, r.Additions, r.Deletions)
 	}
 
+	if r.TestsRun {
+		fmt.Fprintf(&b, "tests:    %s\n", formatTests(r))
+	}
+
 	if r.Error != "" {
 		fmt.Fprintf(&b, "error:    %s\n", r.Error)
 	}
 
+	if r.DashboardURL != "" {
+		fmt.Fprintf(&b, "dashboard: %s\n", r.DashboardURL)
+	}
+
 	return b.String()
 }
 
+// formatTests describes the test results of a run, e.g. "passed, coverage 81.3%".
+func formatTests(r Result) string {
+	res := "failed"
+	if r.TestsPassed != nil && *r.TestsPassed {
+		res = "passed"
+	}
+	if r.Coverage > 0 {
+		res += fmt.Sprintf(", coverage %.1f%%", r.Coverage)
+	}
+	return res
+}
+
 // telegramChannelMaker creates a telegram notifier and destination.
 // overridden in tests to avoid live API calls.
 var telegramChannelMaker = makeTelegramChannel
@@ -242,7 +318,7 @@ func makeTelegramChannel(p Params) (channel, error) {
 	}
 
 	dest := fmt.Sprintf("telegram:%s?parseMode=HTML", p.TelegramChat)
-	return channel{notifier: tg, dest: dest, htmlEscape: true}, nil
+	return channel{notifier: tg, dest: dest, name: "telegram", htmlEscape: true}, nil
 }
 
 // makeEmailChannel creates an email notifier and destination.
@@ -273,7 +349,7 @@ func makeEmailChannel(p Params) (channel, error) {
 		url.QueryEscape("ralphex notification"),
 	)
 
-	return channel{notifier: em, dest: dest}, nil
+	return channel{notifier: em, dest: dest, name: "email"}, nil
 }
 
 // makeSlackChannel creates a slack notifier and destination.
@@ -287,7 +363,7 @@ func makeSlackChannel(p Params) (channel, error) {
 
 	sl := ntfy.NewSlack(p.SlackToken)
 	dest := "slack:" + p.SlackChannel
-	return channel{notifier: sl, dest: dest}, nil
+	return channel{notifier: sl, dest: dest, name: "slack"}, nil
 }
 
 // makeWebhookChannels creates webhook notifiers for each configured URL.
@@ -299,7 +375,11 @@ func makeWebhookChannels(p Params) ([]channel, error) {
 	wh := ntfy.NewWebhook(ntfy.WebhookParams{})
 	var channels []channel
 	for _, u := range p.WebhookURLs {
-		channels = append(channels, channel{notifier: wh, dest: u})
+		name := "webhook"
+		if parsed, err := url.Parse(u); err == nil && parsed.Host != "" {
+			name += " " + parsed.Host // host only, paths and queries often carry tokens
+		}
+		channels = append(channels, channel{notifier: wh, dest: u, name: name})
 	}
 	return channels, nil
 }
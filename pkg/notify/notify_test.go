@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
 	"strings"
 	"sync"
 	"testing"
@@ -405,6 +406,64 @@ func TestService_Send(t *testing.T) {
 	})
 }
 
+func TestService_SendTest(t *testing.T) {
+	t.Run("nil receiver returns nil", func(t *testing.T) {
+		var svc *Service
+		assert.Nil(t, svc.SendTest(context.Background()))
+	})
+
+	t.Run("reports each channel ignoring on_complete and on_error", func(t *testing.T) {
+		okMock := &mockNotifier{schema: "slack"}
+		failMock := &mockNotifier{schema: "http", err: errors.New("404 not found")}
+		tgMock := &mockNotifier{schema: "telegram"}
+		svc := &Service{
+			channels: []channel{
+				{notifier: okMock, dest: "slack:general", name: "slack"},
+				{notifier: failMock, dest: "https://hooks.example.com/secret", name: "webhook hooks.example.com"},
+				{notifier: tgMock, dest: "telegram:-100123?parseMode=HTML", htmlEscape: true},
+			},
+			disabled:  []ChannelResult{{Channel: "telegram", Err: errors.New("disabled: 401 Unauthorized")}},
+			timeoutMs: 5000,
+			hostname:  "test-host",
+			log:       &mockLogger{},
+		}
+
+		results := svc.SendTest(context.Background())
+		require.Len(t, results, 4)
+		assert.Equal(t, ChannelResult{Channel: "slack"}, results[0])
+		assert.Equal(t, "webhook hooks.example.com", results[1].Channel)
+		require.EqualError(t, results[1].Err, "404 not found")
+		assert.Equal(t, ChannelResult{Channel: "mock-telegram"}, results[2], "unnamed channel falls back to notifier name")
+		assert.Equal(t, "telegram", results[3].Channel)
+		require.Error(t, results[3].Err)
+
+		calls := okMock.getCalls()
+		require.Len(t, calls, 1)
+		assert.Contains(t, calls[0].text, "ralphex test notification from test-host")
+		assert.Len(t, failMock.getCalls(), 1)
+		assert.Len(t, tgMock.getCalls(), 1)
+	})
+
+	t.Run("custom script channel", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("shell scripts not supported on windows")
+		}
+		svc := &Service{custom: newCustomChannel("testdata/fail.sh"), timeoutMs: 5000, hostname: "test-host", log: &mockLogger{}}
+		results := svc.SendTest(context.Background())
+		require.Len(t, results, 1)
+		assert.Equal(t, "custom", results[0].Channel)
+		require.Error(t, results[0].Err)
+	})
+}
+
+func TestMakeWebhookChannels_Names(t *testing.T) {
+	chs, err := makeWebhookChannels(Params{WebhookURLs: []string{"https://hooks.example.com/T00/B00/secret", "not a url"}})
+	require.NoError(t, err)
+	require.Len(t, chs, 2)
+	assert.Equal(t, "webhook hooks.example.com", chs[0].name, "path with token is not part of the name")
+	assert.Equal(t, "webhook", chs[1].name)
+}
+
 func TestService_FormatMessage(t *testing.T) {
 	svc := &Service{hostname: "build-server"}
 
@@ -428,6 +487,18 @@ func TestService_FormatMessage(t *testing.T) {
 		assert.NotContains(t, msg, "error:")
 	})
 
+	t.Run("test results", func(t *testing.T) {
+		passed, failed := true, false
+		msg := svc.formatMessage(Result{Status: "success", TestsRun: true, TestsPassed: &passed, Coverage: 81.25})
+		assert.Contains(t, msg, "tests:    passed, coverage 81.2%")
+
+		msg = svc.formatMessage(Result{Status: "failure", TestsRun: true, TestsPassed: &failed})
+		assert.Contains(t, msg, "tests:    failed\n")
+
+		msg = svc.formatMessage(Result{Status: "success", Coverage: 50})
+		assert.NotContains(t, msg, "tests:", "omitted when no test command ran")
+	})
+
 	t.Run("failure message", func(t *testing.T) {
 		msg := svc.formatMessage(Result{
 			Status:   "failure",
@@ -453,6 +524,22 @@ func TestService_FormatMessage(t *testing.T) {
 		assert.Contains(t, msg, "changes:  0 files (+0/-0 lines)")
 	})
 
+	t.Run("run id", func(t *testing.T) {
+		msg := svc.formatMessage(Result{Status: "success", Duration: "1m", RunID: "20260115-103000-4f2a9c"})
+		assert.Contains(t, msg, "duration: 1m\nrun:      20260115-103000-4f2a9c\n")
+
+		msg = svc.formatMessage(Result{Status: "success"})
+		assert.NotContains(t, msg, "run:")
+	})
+
+	t.Run("dashboard link", func(t *testing.T) {
+		msg := svc.formatMessage(Result{Status: "failure", Error: "boom", DashboardURL: "http://build-box:8080"})
+		assert.True(t, strings.HasSuffix(msg, "error:    boom\ndashboard: http://build-box:8080\n"), "got %q", msg)
+
+		msg = svc.formatMessage(Result{Status: "success"})
+		assert.NotContains(t, msg, "dashboard:")
+	})
+
 	t.Run("message line count", func(t *testing.T) {
 		msg := svc.formatMessage(Result{
 			Status:    "success",
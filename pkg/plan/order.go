@@ -0,0 +1,92 @@
+package plan
+
+import "slices"
+
+// TaskOrder selects which unfinished task of a plan is worked on next.
+type TaskOrder string
+
+// task order constants.
+const (
+	// TaskOrderSequential picks the first unfinished task from the top, earlier tasks are prerequisites of later ones
+	TaskOrderSequential TaskOrder = "sequential"
+	// TaskOrderAsListed picks the first unfinished task after the last finished one, wrapping around to skipped tasks
+	TaskOrderAsListed TaskOrder = "as-listed"
+	// TaskOrderByNumber picks the lowest task number, unnumbered tasks (e.g. Task 2.5) follow the numbered task above them
+	TaskOrderByNumber TaskOrder = "by-number"
+)
+
+// NextTask returns the index in p.Tasks of the next task with uncompleted actionable work, chosen by order.
+// returns -1 if no such task exists. an empty or unknown order is treated as sequential.
+func (p *Plan) NextTask(order TaskOrder) int {
+	switch order {
+	case TaskOrderAsListed:
+		return p.nextTaskAsListed()
+	case TaskOrderByNumber:
+		return p.nextTaskByNumber()
+	default:
+		return p.firstUnfinished(0, len(p.Tasks))
+	}
+}
+
+// firstUnfinished returns the index of the first task in [from, to) with uncompleted actionable work, or -1.
+func (p *Plan) firstUnfinished(from, to int) int {
+	for i := from; i < to; i++ {
+		if p.Tasks[i].HasUncompletedActionableWork() {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextTaskAsListed continues after the last finished task, so a task skipped earlier is picked up only
+// once the tasks below it are done.
+func (p *Plan) nextTaskAsListed() int {
+	lastDone := -1
+	for i, t := range p.Tasks {
+		if t.Status == TaskStatusDone {
+			lastDone = i
+		}
+	}
+	if idx := p.firstUnfinished(lastDone+1, len(p.Tasks)); idx >= 0 {
+		return idx
+	}
+	return p.firstUnfinished(0, lastDone+1)
+}
+
+// nextTaskByNumber sorts tasks by number and returns the first unfinished one. a task without a number
+// takes the number of the closest numbered task above it and sorts right after it.
+func (p *Plan) nextTaskByNumber() int {
+	type entry struct {
+		idx, num   int
+		unnumbered bool
+	}
+	entries := make([]entry, 0, len(p.Tasks))
+	prev := 0
+	for i, t := range p.Tasks {
+		if t.Number > 0 {
+			prev = t.Number
+			entries = append(entries, entry{idx: i, num: t.Number})
+			continue
+		}
+		entries = append(entries, entry{idx: i, num: prev, unnumbered: true})
+	}
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		if a.num != b.num {
+			return a.num - b.num
+		}
+		switch {
+		case a.unnumbered == b.unnumbered:
+			return 0
+		case a.unnumbered:
+			return 1
+		default:
+			return -1
+		}
+	})
+	for _, e := range entries {
+		if p.Tasks[e.idx].HasUncompletedActionableWork() {
+			return e.idx
+		}
+	}
+	return -1
+}
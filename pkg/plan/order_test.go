@@ -0,0 +1,92 @@
+package plan_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+func TestPlan_NextTask(t *testing.T) {
+	const skipped = `# Plan
+
+### Task 1: first
+- [x] done
+
+### Task 2: second
+- [ ] open
+
+### Task 3: third
+- [x] done
+
+### Task 4: fourth
+- [ ] open
+`
+	const reordered = `# Plan
+
+### Task 3: third
+- [ ] open
+
+### Task 1: first
+- [x] done
+
+### Task 2.5: inserted
+- [ ] open
+
+### Task 2: second
+- [ ] open
+`
+	const inserted = `# Plan
+
+### Task 1: first
+- [x] done
+
+### Task 1.5: inserted
+- [ ] open
+
+### Task 2: second
+- [ ] open
+`
+	const finished = `# Plan
+
+### Task 1: first
+- [x] done
+`
+
+	tests := []struct {
+		name    string
+		content string
+		order   plan.TaskOrder
+		want    int
+	}{
+		{"sequential picks first open task", skipped, plan.TaskOrderSequential, 1},
+		{"empty order is sequential", skipped, "", 1},
+		{"unknown order is sequential", skipped, "random", 1},
+		{"as-listed continues after last done task", skipped, plan.TaskOrderAsListed, 3},
+		{"by-number sorts reordered tasks", reordered, plan.TaskOrderByNumber, 2},
+		{"sequential ignores numbers", reordered, plan.TaskOrderSequential, 0},
+		{"by-number puts unnumbered task after the one above", inserted, plan.TaskOrderByNumber, 1},
+		{"no open tasks", finished, plan.TaskOrderByNumber, -1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := plan.ParsePlan(tc.content)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, p.NextTask(tc.order))
+		})
+	}
+
+	t.Run("as-listed wraps around to skipped tasks", func(t *testing.T) {
+		p, err := plan.ParsePlan("### Task 1: a\n- [ ] open\n\n### Task 2: b\n- [x] done\n")
+		require.NoError(t, err)
+		assert.Equal(t, 0, p.NextTask(plan.TaskOrderAsListed))
+	})
+
+	t.Run("by-number keeps unnumbered task with its anchor", func(t *testing.T) {
+		p, err := plan.ParsePlan("### Task 2: b\n- [x] done\n\n### Task 2.5: c\n- [ ] open\n\n### Task 1: a\n- [ ] open\n")
+		require.NoError(t, err)
+		assert.Equal(t, 2, p.NextTask(plan.TaskOrderByNumber))
+	})
+}
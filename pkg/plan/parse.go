@@ -8,6 +8,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"unicode/utf8"
 )
 
 // TaskStatus represents the execution status of a task.
@@ -22,9 +24,11 @@ const (
 )
 
 // Checkbox represents a single checkbox item in a task.
+// an in-progress item ("- [~] item") is neither checked nor pending, it counts as unfinished work.
 type Checkbox struct {
-	Text    string `json:"text"`
-	Checked bool   `json:"checked"`
+	Text       string `json:"text"`
+	Checked    bool   `json:"checked"`
+	InProgress bool   `json:"in_progress,omitempty"`
 }
 
 // Task represents a task section in a plan.
@@ -39,22 +43,76 @@ type Task struct {
 type Plan struct {
 	Title string `json:"title"`
 	Tasks []Task `json:"tasks"`
+
+	strayCheckboxes []Checkbox // checkboxes found outside task sections, reported by Validate
+	size            int        // content length in bytes, reported by ValidateSize
 }
 
 // patterns for parsing plan markdown.
 var (
 	taskHeaderPattern = regexp.MustCompile(`^###\s+(?:Task|Iteration)\s+([^:]+?):\s*(.*)$`)
-	// allow leading whitespace for indented sub-items (e.g. "  - [ ] Unit tests")
-	checkboxPattern = regexp.MustCompile(`^\s*-\s+\[([ xX])\]\s*(.*)$`)
-	titlePattern    = regexp.MustCompile(`^#\s+(.*)$`)
+	titlePattern      = regexp.MustCompile(`^#\s+(.*)$`)
 	// formatInText matches [ ] or [x] in checkbox text — description/example, not actionable for completion check.
 	formatInText = regexp.MustCompile(`\[\s*[ xX]?\s*\]`)
 )
 
+// DefaultInProgressMarker is the checkbox mark of an in-progress item, "- [~] item".
+const DefaultInProgressMarker = "~"
+
+// checkboxSyntax is the in-progress mark and the checkbox regexp matching [ ], [x], [X] and that mark.
+type checkboxSyntax struct {
+	inProgress string
+	pattern    *regexp.Regexp // group 1 is the mark, group 2 the item text
+}
+
+// currentCheckboxSyntax is set with SetInProgressMarker, swapped as a whole so parsing never sees a mix.
+var currentCheckboxSyntax atomic.Pointer[checkboxSyntax]
+
+func init() {
+	if err := SetInProgressMarker(DefaultInProgressMarker); err != nil {
+		panic(err)
+	}
+}
+
+// SetInProgressMarker sets the checkbox mark recognized as an in-progress item, e.g. "~" for "- [~] item".
+// empty resets to DefaultInProgressMarker. the mark must be a single character other than space, x, X, [ and ].
+// it applies to all plans parsed afterwards.
+func SetInProgressMarker(marker string) error {
+	if marker == "" {
+		marker = DefaultInProgressMarker
+	}
+	if utf8.RuneCountInString(marker) != 1 || strings.ContainsAny(marker, " \txX[]") {
+		return fmt.Errorf("invalid in-progress marker %q: must be a single character other than space, x, X, [ and ]", marker)
+	}
+	// allow leading whitespace for indented sub-items (e.g. "  - [ ] Unit tests") and any list marker (-, *, +)
+	re, err := regexp.Compile(`^\s*[-*+]\s+\[([ xX]|` + regexp.QuoteMeta(marker) + `)\]\s*(.*)$`)
+	if err != nil {
+		return fmt.Errorf("in-progress marker pattern: %w", err)
+	}
+	currentCheckboxSyntax.Store(&checkboxSyntax{inProgress: marker, pattern: re})
+	return nil
+}
+
+// parseCheckbox returns the checkbox on the line, false if the line is not a checkbox item.
+func parseCheckbox(line string) (Checkbox, bool) {
+	syntax := currentCheckboxSyntax.Load()
+	matches := syntax.pattern.FindStringSubmatch(line)
+	if matches == nil {
+		return Checkbox{}, false
+	}
+	return Checkbox{
+		Text:       strings.TrimSpace(matches[2]),
+		Checked:    matches[1] == "x" || matches[1] == "X",
+		InProgress: matches[1] == syntax.inProgress,
+	}, true
+}
+
 // ParsePlan parses plan markdown content into a structured Plan.
 func ParsePlan(content string) (*Plan, error) {
+	content = normalizeContent(content)
 	p := &Plan{
 		Tasks: make([]Task, 0),
+		size:  len(content),
 	}
 
 	scanner := bufio.NewScanner(strings.NewReader(content))
@@ -103,14 +161,12 @@ func ParsePlan(content string) (*Plan, error) {
 			continue
 		}
 
-		// check for checkbox (only if inside a task)
-		if currentTask != nil {
-			if matches := checkboxPattern.FindStringSubmatch(line); matches != nil {
-				checked := matches[1] == "x" || matches[1] == "X"
-				currentTask.Checkboxes = append(currentTask.Checkboxes, Checkbox{
-					Text:    strings.TrimSpace(matches[2]),
-					Checked: checked,
-				})
+		// check for checkbox; outside a task it is not part of any task but remembered for Validate
+		if cb, ok := parseCheckbox(line); ok {
+			if currentTask != nil {
+				currentTask.Checkboxes = append(currentTask.Checkboxes, cb)
+			} else {
+				p.strayCheckboxes = append(p.strayCheckboxes, cb)
 			}
 		}
 	}
@@ -137,7 +193,8 @@ func ParsePlanFile(path string) (*Plan, error) {
 	return ParsePlan(string(content))
 }
 
-// FileHasUncompletedCheckbox returns true if the file contains any uncompleted actionable checkbox (- [ ]).
+// FileHasUncompletedCheckbox returns true if the file contains any uncompleted actionable checkbox (- [ ], * [ ] or + [ ]),
+// in-progress items (- [~]) included.
 // used for malformed plans (no task headers) to avoid treating them as complete.
 // ignores format-description checkboxes (text containing [ ] or [x]) to match HasUncompletedActionableWork behavior.
 func FileHasUncompletedCheckbox(path string) (bool, error) {
@@ -146,13 +203,12 @@ func FileHasUncompletedCheckbox(path string) (bool, error) {
 		return false, fmt.Errorf("read plan file: %w", err)
 	}
 	// scan lines for uncompleted checkboxes; only count actionable ones (text without [ ] or [x])
-	for line := range strings.SplitSeq(string(content), "\n") {
-		matches := checkboxPattern.FindStringSubmatch(line)
-		if len(matches) < 3 || matches[1] == "x" || matches[1] == "X" {
+	for line := range strings.SplitSeq(normalizeContent(string(content)), "\n") {
+		cb, ok := parseCheckbox(line)
+		if !ok || cb.Checked {
 			continue
 		}
-		text := strings.TrimSpace(matches[2])
-		if formatInText.MatchString(text) {
+		if !cb.IsActionable() {
 			continue // format description, not actionable
 		}
 		return true, nil
@@ -160,6 +216,17 @@ func FileHasUncompletedCheckbox(path string) (bool, error) {
 	return false, nil
 }
 
+// normalizeContent strips a leading UTF-8 BOM and converts CRLF and lone CR line endings to LF,
+// so plans saved by editors on other platforms parse the same as clean UTF-8 files.
+func normalizeContent(content string) string {
+	content = strings.TrimPrefix(content, "\ufeff")
+	if !strings.Contains(content, "\r") {
+		return content
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	return strings.ReplaceAll(content, "\r", "\n")
+}
+
 // JSON returns the plan as JSON bytes.
 func (p *Plan) JSON() ([]byte, error) {
 	data, err := json.Marshal(p)
@@ -169,6 +236,65 @@ func (p *Plan) JSON() ([]byte, error) {
 	return data, nil
 }
 
+// Summary renders a short plain-text overview of the plan: title, overall progress
+// and each task with its checkboxes. used by the plan selector preview.
+func (p *Plan) Summary() string {
+	var sb strings.Builder
+	title := p.Title
+	if title == "" {
+		title = "(untitled plan)"
+	}
+	sb.WriteString(title + "\n")
+
+	if len(p.Tasks) == 0 {
+		sb.WriteString("\nno tasks found\n")
+		return sb.String()
+	}
+
+	done := 0
+	for _, t := range p.Tasks {
+		if t.Status == TaskStatusDone {
+			done++
+		}
+	}
+	fmt.Fprintf(&sb, "\n%d/%d tasks done\n", done, len(p.Tasks))
+
+	for _, t := range p.Tasks {
+		checked := 0
+		for _, cb := range t.Checkboxes {
+			if cb.Checked {
+				checked++
+			}
+		}
+		fmt.Fprintf(&sb, "\n[%s] Task %d: %s (%d/%d)\n", statusMarker(t.Status), t.Number, t.Title, checked, len(t.Checkboxes))
+		for _, cb := range t.Checkboxes {
+			mark := " "
+			switch {
+			case cb.Checked:
+				mark = "x"
+			case cb.InProgress:
+				mark = "~"
+			}
+			fmt.Fprintf(&sb, "    - [%s] %s\n", mark, cb.Text)
+		}
+	}
+	return sb.String()
+}
+
+// statusMarker returns a single-character marker for the task status.
+func statusMarker(s TaskStatus) string {
+	switch s {
+	case TaskStatusDone:
+		return "x"
+	case TaskStatusActive:
+		return "~"
+	case TaskStatusFailed:
+		return "!"
+	default:
+		return " "
+	}
+}
+
 // parseTaskNum extracts task number from string.
 // returns 0 for non-integer values (e.g. "2.5", "2a").
 func parseTaskNum(s string) int {
@@ -186,7 +312,8 @@ func (cb Checkbox) IsActionable() bool {
 	return !formatInText.MatchString(cb.Text)
 }
 
-// HasUncompletedActionableWork returns true if the task has any unchecked actionable checkbox.
+// HasUncompletedActionableWork returns true if the task has any unchecked actionable checkbox,
+// in-progress items included.
 // checkboxes whose text contains [ ] or [x] (format description) are ignored.
 func (t *Task) HasUncompletedActionableWork() bool {
 	for _, cb := range t.Checkboxes {
@@ -198,22 +325,26 @@ func (t *Task) HasUncompletedActionableWork() bool {
 }
 
 // DetermineTaskStatus calculates task status based on checkbox states.
+// any in-progress item makes the task active.
 func DetermineTaskStatus(checkboxes []Checkbox) TaskStatus {
 	if len(checkboxes) == 0 {
 		return TaskStatusPending
 	}
 
-	checkedCount := 0
+	checkedCount, inProgressCount := 0, 0
 	for _, cb := range checkboxes {
-		if cb.Checked {
+		switch {
+		case cb.Checked:
 			checkedCount++
+		case cb.InProgress:
+			inProgressCount++
 		}
 	}
 
 	switch {
 	case checkedCount == len(checkboxes):
 		return TaskStatusDone
-	case checkedCount > 0:
+	case checkedCount > 0 || inProgressCount > 0:
 		return TaskStatusActive
 	default:
 		return TaskStatusPending
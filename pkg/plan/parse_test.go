@@ -2,8 +2,10 @@ package plan_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -372,6 +374,119 @@ func TestParsePlanFile(t *testing.T) {
 	})
 }
 
+func TestParsePlan_EncodingVariants(t *testing.T) {
+	plain := "# Encoded Plan\n\n## Overview\n\n- [ ] stray item\n\n### Task 1: First\n\n- [x] done item\n- [ ] open item\n\n" +
+		"### Task 2: Second\n\n- [ ] another item\n"
+	want, err := plan.ParsePlan(plain)
+	require.NoError(t, err)
+	require.Equal(t, "Encoded Plan", want.Title)
+	require.Len(t, want.Tasks, 2)
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "utf-8 bom", content: "\ufeff" + plain},
+		{name: "crlf line endings", content: strings.ReplaceAll(plain, "\n", "\r\n")},
+		{name: "bom and crlf", content: "\ufeff" + strings.ReplaceAll(plain, "\n", "\r\n")},
+		{name: "lone cr line endings", content: strings.ReplaceAll(plain, "\n", "\r")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := plan.ParsePlan(tc.content)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+
+			path := filepath.Join(t.TempDir(), "plan.md")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+			fromFile, err := plan.ParsePlanFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, want, fromFile)
+
+			hasOpen, err := plan.FileHasUncompletedCheckbox(path)
+			require.NoError(t, err)
+			assert.True(t, hasOpen)
+		})
+	}
+}
+
+func TestParsePlan_ListMarkers(t *testing.T) {
+	const tmpl = "# Marker Plan\n\n### Task 1: First\n\n%[1]s [x] done item\n%[1]s [ ] open item\n  %[1]s [ ] nested item\n\n" +
+		"### Task 2: Second\n\n%[1]s [X] all done\n"
+	want, err := plan.ParsePlan(fmt.Sprintf(tmpl, "-"))
+	require.NoError(t, err)
+	require.Len(t, want.Tasks, 2)
+	require.Len(t, want.Tasks[0].Checkboxes, 3)
+
+	for _, marker := range []string{"*", "+"} {
+		t.Run(marker, func(t *testing.T) {
+			content := fmt.Sprintf(tmpl, marker)
+			got, err := plan.ParsePlan(content)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+			assert.True(t, got.Tasks[0].HasUncompletedActionableWork())
+
+			path := filepath.Join(t.TempDir(), "plan.md")
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+			hasOpen, err := plan.FileHasUncompletedCheckbox(path)
+			require.NoError(t, err)
+			assert.True(t, hasOpen)
+		})
+	}
+}
+
+func TestParsePlan_InProgress(t *testing.T) {
+	content := "# Plan\n\n### Task 1: Started\n- [~] half done\n- [ ] not started\n\n" +
+		"### Task 2: Almost\n- [x] done\n- [~] in flight\n\n### Task 3: Finished\n- [x] done\n"
+	p, err := plan.ParsePlan(content)
+	require.NoError(t, err)
+	require.Len(t, p.Tasks, 3)
+
+	assert.Equal(t, []plan.Checkbox{{Text: "half done", InProgress: true}, {Text: "not started"}}, p.Tasks[0].Checkboxes)
+	assert.Equal(t, plan.TaskStatusActive, p.Tasks[0].Status)
+	assert.True(t, p.Tasks[0].HasUncompletedActionableWork())
+	assert.Equal(t, plan.TaskStatusActive, p.Tasks[1].Status)
+	assert.True(t, p.Tasks[1].HasUncompletedActionableWork())
+	assert.Equal(t, plan.TaskStatusDone, p.Tasks[2].Status)
+	assert.Contains(t, p.Summary(), "    - [~] in flight\n")
+
+	path := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(path, []byte("# Plan\n- [x] done\n- [~] started\n"), 0o600))
+	hasOpen, err := plan.FileHasUncompletedCheckbox(path)
+	require.NoError(t, err)
+	assert.True(t, hasOpen)
+}
+
+func TestSetInProgressMarker(t *testing.T) {
+	t.Cleanup(func() { _ = plan.SetInProgressMarker("") })
+
+	t.Run("custom marker", func(t *testing.T) {
+		require.NoError(t, plan.SetInProgressMarker("/"))
+		p, err := plan.ParsePlan("### Task 1: One\n- [/] started\n- [~] literal\n")
+		require.NoError(t, err)
+		require.Len(t, p.Tasks, 1)
+		require.Len(t, p.Tasks[0].Checkboxes, 1, "default marker is not recognized once replaced")
+		assert.True(t, p.Tasks[0].Checkboxes[0].InProgress)
+		assert.Contains(t, p.Summary(), "- [~] started", "summary uses the canonical mark")
+	})
+
+	t.Run("empty resets to default", func(t *testing.T) {
+		require.NoError(t, plan.SetInProgressMarker(""))
+		p, err := plan.ParsePlan("### Task 1: One\n- [~] started\n")
+		require.NoError(t, err)
+		require.Len(t, p.Tasks[0].Checkboxes, 1)
+		assert.True(t, p.Tasks[0].Checkboxes[0].InProgress)
+	})
+
+	for _, marker := range []string{"x", "X", " ", "[", "]", "ab", "\t"} {
+		t.Run(fmt.Sprintf("invalid %q", marker), func(t *testing.T) {
+			err := plan.SetInProgressMarker(marker)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid in-progress marker")
+		})
+	}
+}
+
 func TestFileHasUncompletedCheckbox(t *testing.T) {
 	t.Run("returns true when file has uncompleted checkbox", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -437,6 +552,43 @@ func TestPlan_JSON(t *testing.T) {
 	require.Len(t, tasks, 1)
 }
 
+func TestPlan_Summary(t *testing.T) {
+	t.Run("plan with tasks", func(t *testing.T) {
+		content := `# Add Feature
+
+### Task 1: Setup
+- [x] create package
+- [x] add config
+
+### Task 2: Implement
+- [x] write code
+- [ ] write tests
+`
+		p, err := plan.ParsePlan(content)
+		require.NoError(t, err)
+
+		expected := `Add Feature
+
+1/2 tasks done
+
+[x] Task 1: Setup (2/2)
+    - [x] create package
+    - [x] add config
+
+[~] Task 2: Implement (1/2)
+    - [x] write code
+    - [ ] write tests
+`
+		assert.Equal(t, expected, p.Summary())
+	})
+
+	t.Run("plan without tasks", func(t *testing.T) {
+		p, err := plan.ParsePlan("just some notes\n")
+		require.NoError(t, err)
+		assert.Equal(t, "(untitled plan)\n\nno tasks found\n", p.Summary())
+	})
+}
+
 func TestDetermineTaskStatus(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -449,6 +601,7 @@ func TestDetermineTaskStatus(t *testing.T) {
 		{"mixed", []plan.Checkbox{{Checked: true}, {Checked: false}}, plan.TaskStatusActive},
 		{"single checked", []plan.Checkbox{{Checked: true}}, plan.TaskStatusDone},
 		{"single unchecked", []plan.Checkbox{{Checked: false}}, plan.TaskStatusPending},
+		{"in progress", []plan.Checkbox{{InProgress: true}, {Checked: false}}, plan.TaskStatusActive},
 	}
 
 	for _, tt := range tests {
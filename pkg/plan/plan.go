@@ -11,9 +11,13 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/umputun/ralphex/pkg/cmdargs"
 	"github.com/umputun/ralphex/pkg/input"
 	"github.com/umputun/ralphex/pkg/progress"
 )
@@ -26,8 +30,14 @@ var ErrNoPlansFound = errors.New("no plans found")
 
 // Selector handles plan file selection and resolution.
 type Selector struct {
-	PlansDir string
-	Colors   *progress.Colors
+	PlansDir   string
+	Colors     *progress.Colors
+	FzfCommand string // fzf binary, defaults to "fzf"
+	FzfArgs    string // extra fzf arguments (space-separated, quotes supported), appended after built-in ones
+	Recent     int    // offer only the N most recently modified plans in interactive selection, 0 = all plans
+
+	stdin  io.Reader // for testing, nil uses os.Stdin
+	stdout io.Writer // for testing, nil uses os.Stdout
 }
 
 // NewSelector creates a new Selector with the given plans directory and colors.
@@ -41,7 +51,7 @@ func NewSelector(plansDir string, colors *progress.Colors) *Selector {
 // Select selects and prepares a plan file.
 // if planFile is provided, validates it exists and returns absolute path.
 // if planFile is empty and optional is true, returns empty string without error.
-// if planFile is empty and optional is false, uses fzf for selection.
+// if planFile is empty and optional is false, uses fzf for selection, or a numbered menu if fzf is unavailable.
 func (s *Selector) Select(ctx context.Context, planFile string, optional bool) (string, error) {
 	selected, err := s.selectPlan(ctx, planFile, optional)
 	if err != nil {
@@ -75,42 +85,123 @@ func (s *Selector) selectPlan(ctx context.Context, planFile string, optional boo
 		return "", nil
 	}
 
-	// use fzf to select plan
-	return s.selectWithFzf(ctx)
+	return s.selectInteractive(ctx)
+}
+
+// selectInteractive lets the user pick a plan file from the plans directory, or from its Recent plans if set.
+// a single plan is auto-selected; otherwise fzf is used, falling back to a numbered menu without it.
+// returns ErrNoPlansFound if the directory is missing or has no plans.
+func (s *Selector) selectInteractive(ctx context.Context) (string, error) {
+	plans, err := s.RecentPlans(s.Recent)
+	if err != nil {
+		return "", err
+	}
+
+	// auto-select if single plan (no fzf needed)
+	if len(plans) == 1 {
+		s.Colors.Info().Printf("auto-selected: %s\n", plans[0])
+		return plans[0], nil
+	}
+
+	if _, lookupErr := exec.LookPath(s.fzfCommand()); lookupErr != nil {
+		return s.selectWithNumbers(ctx, plans)
+	}
+	return s.selectWithFzf(ctx, plans)
 }
 
-// selectWithFzf uses fzf to interactively select a plan file from the plans directory.
-func (s *Selector) selectWithFzf(ctx context.Context) (string, error) {
+// listPlans returns plan files in the plans directory (excluding completed/).
+func (s *Selector) listPlans() ([]string, error) {
 	if _, err := os.Stat(s.PlansDir); err != nil {
 		if os.IsNotExist(err) {
-			return "", fmt.Errorf("%w: %s (directory missing)", ErrNoPlansFound, s.PlansDir)
+			return nil, fmt.Errorf("%w: plans directory %s does not exist; create it and add a plan file, "+
+				"pass a plan file explicitly, or run 'ralphex --plan \"<description>\"' to create one (set plans_dir to use another location)",
+				ErrNoPlansFound, s.PlansDir)
 		}
-		return "", fmt.Errorf("cannot access plans directory %s: %w", s.PlansDir, err)
+		return nil, fmt.Errorf("cannot access plans directory %s: %w", s.PlansDir, err)
 	}
 
-	// find plan files (excluding completed/)
 	plans, err := filepath.Glob(filepath.Join(s.PlansDir, "*.md"))
 	if err != nil || len(plans) == 0 {
-		return "", fmt.Errorf("%w: %s", ErrNoPlansFound, s.PlansDir)
+		return nil, fmt.Errorf("%w: %s", ErrNoPlansFound, s.PlansDir)
 	}
+	return plans, nil
+}
 
-	// auto-select if single plan (no fzf needed)
-	if len(plans) == 1 {
-		s.Colors.Info().Printf("auto-selected: %s\n", plans[0])
-		return plans[0], nil
+// RecentPlans returns up to n plan files from the plans directory (excluding completed/), most recently
+// modified first. n <= 0 returns all plans in directory order. returns ErrNoPlansFound if the directory
+// is missing or has no plans.
+func (s *Selector) RecentPlans(n int) ([]string, error) {
+	plans, err := s.listPlans()
+	if err != nil || n <= 0 {
+		return plans, err
 	}
+	sortByModTime(plans)
+	return plans[:min(n, len(plans))], nil
+}
 
-	// multiple plans require fzf
-	if _, lookupErr := exec.LookPath("fzf"); lookupErr != nil {
-		return "", errors.New("fzf not found, please provide plan file as argument")
+// sortByModTime sorts plan files by modification time, newest first.
+// files that can't be stat'ed sort last.
+func sortByModTime(plans []string) {
+	modTimes := make(map[string]time.Time, len(plans))
+	for _, p := range plans {
+		if info, err := os.Stat(p); err == nil {
+			modTimes[p] = info.ModTime()
+		}
 	}
+	slices.SortStableFunc(plans, func(a, b string) int { return modTimes[b].Compare(modTimes[a]) })
+}
 
-	// use fzf for selection
-	cmd := exec.CommandContext(ctx, "fzf",
-		"--prompt=select plan: ",
-		"--preview=head -50 {}",
-		"--preview-window=right:60%",
-	)
+// EnsurePlansDir makes sure the plans directory exists before a plan is created in it.
+// when the directory is missing, asks the user whether to create it; declining returns an error.
+func (s *Selector) EnsurePlansDir(ctx context.Context) error {
+	info, err := os.Stat(s.PlansDir)
+	if err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("plans directory %s is not a directory", s.PlansDir)
+		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return fmt.Errorf("cannot access plans directory %s: %w", s.PlansDir, err)
+	}
+
+	prompt := fmt.Sprintf("plans directory %s does not exist, create it?", s.PlansDir)
+	if !input.AskYesNo(ctx, prompt, s.getStdin(), s.getStdout()) {
+		return fmt.Errorf("plans directory %s does not exist (set plans_dir to use another location)", s.PlansDir)
+	}
+	if err := os.MkdirAll(s.PlansDir, 0o750); err != nil {
+		return fmt.Errorf("create plans directory: %w", err)
+	}
+	s.Colors.Info().Printf("created plans directory %s\n", s.PlansDir)
+	return nil
+}
+
+// InPlansDir reports whether planFile is located inside the plans directory, including its subdirectories.
+// both paths are resolved to absolute paths with symlinks evaluated where they exist.
+func (s *Selector) InPlansDir(planFile string) bool {
+	absPlan, absDir := resolvePath(planFile), resolvePath(s.PlansDir)
+	rel, err := filepath.Rel(absDir, absPlan)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// resolvePath returns the absolute path with symlinks evaluated, falling back to the unresolved path.
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// selectWithFzf uses fzf to interactively select one of the given plan files.
+func (s *Selector) selectWithFzf(ctx context.Context, plans []string) (string, error) {
+	cmd := exec.CommandContext(ctx, s.fzfCommand(), s.fzfArgs()...)
 	cmd.Stdin = strings.NewReader(strings.Join(plans, "\n"))
 	cmd.Stderr = os.Stderr
 
@@ -122,6 +213,80 @@ func (s *Selector) selectWithFzf(ctx context.Context) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// selectWithNumbers presents a numbered list of plan files and reads the choice from stdin.
+// used when fzf is not available. an empty choice (or EOF) returns an error.
+func (s *Selector) selectWithNumbers(ctx context.Context, plans []string) (string, error) {
+	stdout := s.getStdout()
+	s.Colors.Info().Fprintf(stdout, "select plan:\n")
+	for i, p := range plans {
+		_, _ = fmt.Fprintf(stdout, "  %d) %s\n", i+1, filepath.Base(p))
+	}
+	_, _ = fmt.Fprintf(stdout, "Enter number (1-%d): ", len(plans))
+
+	line, err := input.ReadLineWithContext(ctx, bufio.NewReader(s.getStdin()))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read plan choice: %w", err)
+	}
+
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", errors.New("no plan selected")
+	}
+
+	num, convErr := strconv.Atoi(line)
+	if convErr != nil || num < 1 || num > len(plans) {
+		return "", fmt.Errorf("invalid plan choice %q (must be 1-%d)", line, len(plans))
+	}
+	return plans[num-1], nil
+}
+
+func (s *Selector) getStdin() io.Reader {
+	if s.stdin != nil {
+		return s.stdin
+	}
+	return os.Stdin
+}
+
+func (s *Selector) getStdout() io.Writer {
+	if s.stdout != nil {
+		return s.stdout
+	}
+	return os.Stdout
+}
+
+// fzfCommand returns the configured fzf binary or "fzf" if not set.
+func (s *Selector) fzfCommand() string {
+	if s.FzfCommand == "" {
+		return "fzf"
+	}
+	return s.FzfCommand
+}
+
+// fzfArgs returns built-in fzf arguments followed by user-configured ones.
+// fzf lets later options override earlier ones, so a user --preview replaces the default.
+func (s *Selector) fzfArgs() []string {
+	args := []string{
+		"--prompt=select plan: ",
+		"--preview=" + previewCommand(),
+		"--preview-window=right:60%",
+	}
+	return append(args, cmdargs.SplitShell(s.FzfArgs)...)
+}
+
+// previewCommand returns the fzf preview command that renders the parsed plan summary
+// by calling ralphex itself with --plan-summary. falls back to the raw file head
+// if the executable path can't be resolved.
+func previewCommand() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "head -50 {}"
+	}
+	if runtime.GOOS == "windows" {
+		return `"` + exe + `" --plan-summary {}`
+	}
+	return "'" + strings.ReplaceAll(exe, "'", `'\''`) + "' --plan-summary {}"
+}
+
 // FindRecent finds the most recently modified plan file in the plans directory
 // that was modified after the given start time.
 func (s *Selector) FindRecent(startTime time.Time) string {
@@ -157,12 +322,36 @@ func (s *Selector) FindRecent(startTime time.Time) string {
 // ExtractBranchName derives a branch name from a plan file path.
 // removes the .md extension and strips any leading date prefix (e.g., "2024-01-15-").
 func ExtractBranchName(planFile string) string {
+	return ExtractBranchNameWith(planFile, BranchNameRules{})
+}
+
+// BranchNameRules customizes how ExtractBranchNameWith turns a plan file into a branch name.
+// the zero value gives the ExtractBranchName behavior.
+type BranchNameRules struct {
+	Template    string         // branch name with {{SLUG}} replaced by the slug, e.g. "feature/{{SLUG}}"; empty = "{{SLUG}}"
+	StripPrefix *regexp.Regexp // removed from the start of the file name before the slug is taken; nil = date prefix
+}
+
+// ExtractBranchNameWith derives a branch name from a plan file path using rules.
+// the slug is the file name without the .md extension and the matched prefix, with leading dashes trimmed;
+// the whole name is used when nothing is left. the result is not validated as a git ref.
+func ExtractBranchNameWith(planFile string, rules BranchNameRules) string {
 	name := strings.TrimSuffix(filepath.Base(planFile), ".md")
-	branchName := strings.TrimLeft(datePrefixRe.ReplaceAllString(name, ""), "-")
-	if branchName == "" {
-		return name
+	prefixRe := rules.StripPrefix
+	if prefixRe == nil {
+		prefixRe = datePrefixRe
+	}
+	slug := name
+	if loc := prefixRe.FindStringIndex(name); loc != nil && loc[0] == 0 {
+		slug = strings.TrimLeft(name[loc[1]:], "-")
+	}
+	if slug == "" {
+		slug = name
+	}
+	if rules.Template == "" {
+		return slug
 	}
-	return branchName
+	return strings.ReplaceAll(rules.Template, "{{SLUG}}", slug)
 }
 
 // PromptDescription prompts the user to enter a plan description.
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
@@ -62,6 +63,19 @@ func TestSelector_Select(t *testing.T) {
 		assert.Empty(t, result)
 	})
 
+	t.Run("optional plan is never picked interactively", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		for _, name := range []string{"a.md", "b.md"} {
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("# Plan"), 0o600))
+		}
+		var out strings.Builder
+		sel := &Selector{PlansDir: tmpDir, Colors: colors, FzfCommand: "nonexistent-fzf", stdin: strings.NewReader("1\n"), stdout: &out}
+		result, err := sel.Select(context.Background(), "", true)
+		require.NoError(t, err)
+		assert.Empty(t, result)
+		assert.Empty(t, out.String(), "no menu shown")
+	})
+
 	t.Run("empty planFile without optional returns error", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		sel := NewSelector(tmpDir, colors)
@@ -71,7 +85,7 @@ func TestSelector_Select(t *testing.T) {
 	})
 }
 
-func TestSelector_SelectWithFzf(t *testing.T) {
+func TestSelector_SelectInteractive(t *testing.T) {
 	colors := progress.NewColors(config.ColorConfig{
 		Task: "0,255,0", Review: "255,255,0", Codex: "255,165,0",
 		ClaudeEval: "0,255,255", Warn: "255,165,0", Error: "255,0,0",
@@ -80,15 +94,17 @@ func TestSelector_SelectWithFzf(t *testing.T) {
 
 	t.Run("missing directory returns error", func(t *testing.T) {
 		sel := NewSelector("/nonexistent", colors)
-		_, err := sel.selectWithFzf(context.Background())
+		_, err := sel.selectInteractive(context.Background())
 		require.Error(t, err)
 		assert.ErrorIs(t, err, ErrNoPlansFound)
+		assert.Contains(t, err.Error(), "plans directory /nonexistent does not exist")
+		assert.Contains(t, err.Error(), "ralphex --plan")
 	})
 
 	t.Run("empty directory returns error", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		sel := NewSelector(tmpDir, colors)
-		_, err := sel.selectWithFzf(context.Background())
+		_, err := sel.selectInteractive(context.Background())
 		require.Error(t, err)
 		assert.ErrorIs(t, err, ErrNoPlansFound)
 	})
@@ -99,10 +115,182 @@ func TestSelector_SelectWithFzf(t *testing.T) {
 		require.NoError(t, os.WriteFile(planFile, []byte("# Test"), 0o600))
 
 		sel := NewSelector(tmpDir, colors)
-		result, err := sel.selectWithFzf(context.Background())
+		result, err := sel.selectInteractive(context.Background())
 		require.NoError(t, err)
 		assert.Equal(t, planFile, result)
 	})
+
+	t.Run("multiple plans without fzf use numbered menu", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		for _, name := range []string{"a.md", "b.md"} {
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("# Test"), 0o600))
+		}
+
+		var out strings.Builder
+		sel := NewSelector(tmpDir, colors)
+		sel.FzfCommand = "nonexistent-fzf-binary"
+		sel.stdin = strings.NewReader("2\n")
+		sel.stdout = &out
+		result, err := sel.selectInteractive(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(tmpDir, "b.md"), result)
+		assert.Contains(t, out.String(), "1) a.md")
+		assert.Contains(t, out.String(), "2) b.md")
+	})
+}
+
+func TestSelector_EnsurePlansDir(t *testing.T) {
+	colors := progress.NewColors(config.ColorConfig{
+		Task: "0,255,0", Review: "255,255,0", Codex: "255,165,0",
+		ClaudeEval: "0,255,255", Warn: "255,165,0", Error: "255,0,0",
+		Signal: "255,0,255", Timestamp: "128,128,128", Info: "255,255,255",
+	})
+
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T, dir string)
+		answer      string
+		wantErr     string
+		wantPrompt  bool
+		wantCreated bool
+	}{
+		{name: "existing directory", setup: func(t *testing.T, dir string) {
+			t.Helper()
+			require.NoError(t, os.MkdirAll(dir, 0o750))
+		}, wantCreated: true},
+		{name: "missing directory, user accepts", answer: "y\n", wantPrompt: true, wantCreated: true},
+		{name: "missing directory, user declines", answer: "n\n", wantPrompt: true, wantErr: "does not exist"},
+		{name: "missing directory, no input", wantPrompt: true, wantErr: "does not exist"},
+		{name: "path is a file", setup: func(t *testing.T, dir string) {
+			t.Helper()
+			require.NoError(t, os.WriteFile(dir, []byte("x"), 0o600))
+		}, wantErr: "is not a directory"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "docs", "plans")
+			if tc.setup != nil {
+				require.NoError(t, os.MkdirAll(filepath.Dir(dir), 0o750))
+				tc.setup(t, dir)
+			}
+			var out strings.Builder
+			sel := NewSelector(dir, colors)
+			sel.stdin = strings.NewReader(tc.answer)
+			sel.stdout = &out
+
+			err := sel.EnsurePlansDir(context.Background())
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantPrompt, strings.Contains(out.String(), "create it?"))
+			info, statErr := os.Stat(dir)
+			assert.Equal(t, tc.wantCreated, statErr == nil && info.IsDir())
+		})
+	}
+}
+
+func TestSelector_InPlansDir(t *testing.T) {
+	root := t.TempDir()
+	plansDir := filepath.Join(root, "docs", "plans")
+	require.NoError(t, os.MkdirAll(plansDir, 0o750))
+	sel := NewSelector(plansDir, nil)
+
+	tests := []struct {
+		name     string
+		planFile string
+		want     bool
+	}{
+		{name: "plan in plans dir", planFile: filepath.Join(plansDir, "feature.md"), want: true},
+		{name: "plan in subdirectory", planFile: filepath.Join(plansDir, "completed", "feature.md"), want: true},
+		{name: "plan in parent dir", planFile: filepath.Join(root, "docs", "feature.md"), want: false},
+		{name: "sibling with common prefix", planFile: filepath.Join(root, "docs", "plans2", "feature.md"), want: false},
+		{name: "unrelated dir", planFile: filepath.Join(t.TempDir(), "feature.md"), want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, sel.InPlansDir(tc.planFile))
+		})
+	}
+
+	t.Run("relative plans dir", func(t *testing.T) {
+		t.Chdir(root)
+		rel := NewSelector(filepath.Join("docs", "plans"), nil)
+		assert.True(t, rel.InPlansDir(filepath.Join(plansDir, "feature.md")))
+		assert.False(t, rel.InPlansDir(filepath.Join(root, "feature.md")))
+	})
+}
+
+func TestSelector_selectWithNumbers(t *testing.T) {
+	colors := progress.NewColors(config.ColorConfig{
+		Task: "0,255,0", Review: "255,255,0", Codex: "255,165,0",
+		ClaudeEval: "0,255,255", Warn: "255,165,0", Error: "255,0,0",
+		Signal: "255,0,255", Timestamp: "128,128,128", Info: "255,255,255",
+	})
+	plans := []string{"/plans/first.md", "/plans/second.md", "/plans/third.md"}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "valid choice", input: "3\n", want: "/plans/third.md"},
+		{name: "choice without newline", input: "1", want: "/plans/first.md"},
+		{name: "surrounding spaces", input: "  2  \n", want: "/plans/second.md"},
+		{name: "empty choice", input: "\n", wantErr: "no plan selected"},
+		{name: "eof", input: "", wantErr: "no plan selected"},
+		{name: "out of range", input: "4\n", wantErr: "invalid plan choice"},
+		{name: "zero", input: "0\n", wantErr: "invalid plan choice"},
+		{name: "not a number", input: "abc\n", wantErr: "invalid plan choice"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out strings.Builder
+			sel := &Selector{Colors: colors, stdin: strings.NewReader(tc.input), stdout: &out}
+			result, err := sel.selectWithNumbers(context.Background(), plans)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, result)
+		})
+	}
+
+	t.Run("canceled context returns error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		sel := &Selector{Colors: colors, stdin: strings.NewReader("1\n"), stdout: &strings.Builder{}}
+		_, err := sel.selectWithNumbers(ctx, plans)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestSelector_fzfArgs(t *testing.T) {
+	t.Run("default command and args", func(t *testing.T) {
+		sel := &Selector{}
+		assert.Equal(t, "fzf", sel.fzfCommand())
+		args := sel.fzfArgs()
+		require.Len(t, args, 3)
+		assert.Equal(t, "--prompt=select plan: ", args[0])
+		assert.True(t, strings.HasPrefix(args[1], "--preview="), "got %q", args[1])
+		assert.Contains(t, args[1], "--plan-summary {}")
+		assert.Equal(t, "--preview-window=right:60%", args[2])
+	})
+
+	t.Run("custom command and extra args appended", func(t *testing.T) {
+		sel := &Selector{FzfCommand: "/opt/bin/fzf", FzfArgs: `--height=40% --preview "bat --color=always {}"`}
+		assert.Equal(t, "/opt/bin/fzf", sel.fzfCommand())
+		args := sel.fzfArgs()
+		require.Len(t, args, 6)
+		assert.Equal(t, []string{"--height=40%", "--preview", "bat --color=always {}"}, args[3:])
+	})
 }
 
 func TestSelector_FindRecent(t *testing.T) {
@@ -154,6 +342,87 @@ func TestSelector_FindRecent(t *testing.T) {
 	})
 }
 
+func TestSelector_RecentPlans(t *testing.T) {
+	colors := progress.NewColors(config.ColorConfig{
+		Task: "0,255,0", Review: "255,255,0", Codex: "255,165,0",
+		ClaudeEval: "0,255,255", Warn: "255,165,0", Error: "255,0,0",
+		Signal: "255,0,255", Timestamp: "128,128,128", Info: "255,255,255",
+	})
+
+	// writePlans creates plans with modification times in the given order, oldest first
+	writePlans := func(t *testing.T, dir string, names ...string) {
+		t.Helper()
+		base := time.Now().Add(-time.Hour)
+		for i, name := range names {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.WriteFile(path, []byte("# Plan"), 0o600))
+			mtime := base.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, os.Chtimes(path, mtime, mtime))
+		}
+	}
+
+	t.Run("returns n most recent first", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writePlans(t, tmpDir, "b.md", "c.md", "a.md", "d.md")
+
+		sel := NewSelector(tmpDir, colors)
+		plans, err := sel.RecentPlans(3)
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(tmpDir, "d.md"), filepath.Join(tmpDir, "a.md"), filepath.Join(tmpDir, "c.md")}, plans)
+	})
+
+	t.Run("n larger than plan count returns all", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writePlans(t, tmpDir, "a.md", "b.md")
+
+		plans, err := NewSelector(tmpDir, colors).RecentPlans(10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(tmpDir, "b.md"), filepath.Join(tmpDir, "a.md")}, plans)
+	})
+
+	t.Run("zero returns all plans in directory order", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writePlans(t, tmpDir, "b.md", "a.md")
+
+		plans, err := NewSelector(tmpDir, colors).RecentPlans(0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(tmpDir, "a.md"), filepath.Join(tmpDir, "b.md")}, plans)
+	})
+
+	t.Run("no plans returns error", func(t *testing.T) {
+		_, err := NewSelector(t.TempDir(), colors).RecentPlans(3)
+		require.ErrorIs(t, err, ErrNoPlansFound)
+	})
+
+	t.Run("recent limits interactive selection", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writePlans(t, tmpDir, "a.md", "b.md", "c.md")
+
+		var out strings.Builder
+		sel := NewSelector(tmpDir, colors)
+		sel.Recent = 2
+		sel.FzfCommand = "nonexistent-fzf-binary"
+		sel.stdin = strings.NewReader("2\n")
+		sel.stdout = &out
+		result, err := sel.selectInteractive(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(tmpDir, "b.md"), result)
+		assert.Contains(t, out.String(), "1) c.md")
+		assert.NotContains(t, out.String(), "a.md")
+	})
+
+	t.Run("recent 1 picks the most recent plan", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		writePlans(t, tmpDir, "a.md", "b.md")
+
+		sel := NewSelector(tmpDir, colors)
+		sel.Recent = 1
+		result, err := sel.selectInteractive(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(tmpDir, "b.md"), result)
+	})
+}
+
 func TestExtractBranchName(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -195,6 +464,33 @@ func TestExtractBranchName(t *testing.T) {
 	}
 }
 
+func TestExtractBranchNameWith(t *testing.T) {
+	tests := []struct {
+		name     string
+		planFile string
+		rules    BranchNameRules
+		want     string
+	}{
+		{name: "zero rules match ExtractBranchName", planFile: "/p/2024-01-15-feature.md", want: "feature"},
+		{name: "template", planFile: "/p/2024-01-15-add-auth.md", rules: BranchNameRules{Template: "feature/{{SLUG}}"},
+			want: "feature/add-auth"},
+		{name: "custom strip pattern", planFile: "/p/JIRA-123-add-auth.md",
+			rules: BranchNameRules{StripPrefix: regexp.MustCompile(`^JIRA-\d+`)}, want: "add-auth"},
+		{name: "custom pattern keeps date", planFile: "/p/2024-01-15-add-auth.md",
+			rules: BranchNameRules{StripPrefix: regexp.MustCompile(`^JIRA-\d+`)}, want: "2024-01-15-add-auth"},
+		{name: "match not at start is ignored", planFile: "/p/add-JIRA-1-auth.md",
+			rules: BranchNameRules{StripPrefix: regexp.MustCompile(`JIRA-\d+`)}, want: "add-JIRA-1-auth"},
+		{name: "everything stripped falls back to full name", planFile: "/p/JIRA-1.md",
+			rules: BranchNameRules{Template: "fix/{{SLUG}}", StripPrefix: regexp.MustCompile(`^JIRA-\d+`)}, want: "fix/JIRA-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ExtractBranchNameWith(tt.planFile, tt.rules))
+		})
+	}
+}
+
 func TestPromptDescription(t *testing.T) {
 	colors := progress.NewColors(config.ColorConfig{
 		Task: "0,255,0", Review: "255,255,0", Codex: "255,165,0",
@@ -0,0 +1,92 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WarningCode identifies the kind of plan authoring issue reported by Validate.
+type WarningCode string
+
+// warning code constants.
+const (
+	WarnEmptyTitle          WarningCode = "empty_title"             // plan has no "# Title" header
+	WarnNoTasks             WarningCode = "no_tasks"                // plan has no task sections
+	WarnTaskNoCheckboxes    WarningCode = "task_without_checkboxes" // task section has no checkboxes
+	WarnDuplicateTaskNumber WarningCode = "duplicate_task_number"   // two task sections share a number
+	WarnCheckboxOutsideTask WarningCode = "checkbox_outside_task"   // checkbox not inside any task section
+	WarnTooManyTasks        WarningCode = "too_many_tasks"          // more tasks than the configured limit
+	WarnPlanTooLarge        WarningCode = "plan_too_large"          // plan file exceeds LargePlanBytes
+)
+
+// LargePlanBytes is the plan size above which ValidateSize reports WarnPlanTooLarge.
+const LargePlanBytes = 100 * 1024
+
+// PlanWarning is a single lint warning about a plan file.
+type PlanWarning struct {
+	Code    WarningCode `json:"code"`
+	Message string      `json:"message"`
+}
+
+// String returns the warning formatted as "code: message".
+func (w PlanWarning) String() string {
+	return string(w.Code) + ": " + w.Message
+}
+
+// Validate checks the plan for common authoring mistakes and returns warnings in document order.
+// returns nil if no issues were found. warnings don't prevent execution.
+func (p *Plan) Validate() []PlanWarning {
+	var warnings []PlanWarning
+
+	if strings.TrimSpace(p.Title) == "" {
+		warnings = append(warnings, PlanWarning{Code: WarnEmptyTitle,
+			Message: `plan has no title, add a "# Title" line at the top`})
+	}
+
+	if len(p.Tasks) == 0 {
+		warnings = append(warnings, PlanWarning{Code: WarnNoTasks,
+			Message: `plan has no task sections, add "### Task N: title" headers with checkboxes`})
+	}
+
+	seen := make(map[int]bool, len(p.Tasks))
+	for _, t := range p.Tasks {
+		if len(t.Checkboxes) == 0 {
+			warnings = append(warnings, PlanWarning{Code: WarnTaskNoCheckboxes,
+				Message: fmt.Sprintf("task %d (%s) has no checkboxes, it can't be tracked or completed", t.Number, t.Title)})
+		}
+		// non-integer task numbers (e.g. "2.5") parse as 0 and can't be compared
+		if t.Number == 0 {
+			continue
+		}
+		if seen[t.Number] {
+			warnings = append(warnings, PlanWarning{Code: WarnDuplicateTaskNumber,
+				Message: fmt.Sprintf("task number %d is used more than once (%s)", t.Number, t.Title)})
+		}
+		seen[t.Number] = true
+	}
+
+	if n := len(p.strayCheckboxes); n > 0 {
+		warnings = append(warnings, PlanWarning{Code: WarnCheckboxOutsideTask,
+			Message: fmt.Sprintf("%d checkbox(es) outside task sections are ignored when tracking progress, first: %q",
+				n, p.strayCheckboxes[0].Text)})
+	}
+
+	return warnings
+}
+
+// ValidateSize reports plans that are likely too big for a single run: more than maxTasks tasks
+// (0 disables the check) or content over LargePlanBytes. returns nil if the plan is within limits.
+// like Validate, the warnings are advisory and don't prevent execution.
+func (p *Plan) ValidateSize(maxTasks int) []PlanWarning {
+	var warnings []PlanWarning
+	if maxTasks > 0 && len(p.Tasks) > maxTasks {
+		warnings = append(warnings, PlanWarning{Code: WarnTooManyTasks,
+			Message: fmt.Sprintf("plan has %d tasks (limit %d), consider splitting it into smaller plans", len(p.Tasks), maxTasks)})
+	}
+	if p.size > LargePlanBytes {
+		warnings = append(warnings, PlanWarning{Code: WarnPlanTooLarge,
+			Message: fmt.Sprintf("plan is %d KB (limit %d KB), consider splitting it into smaller plans",
+				p.size/1024, LargePlanBytes/1024)})
+	}
+	return warnings
+}
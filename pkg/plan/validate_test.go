@@ -0,0 +1,174 @@
+package plan_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+func TestPlan_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []plan.WarningCode
+	}{
+		{
+			name: "valid plan",
+			content: `# Plan
+
+### Task 1: First
+- [ ] one
+
+### Task 2: Second
+- [x] two
+`,
+			want: nil,
+		},
+		{
+			name:    "empty plan",
+			content: "",
+			want:    []plan.WarningCode{plan.WarnEmptyTitle, plan.WarnNoTasks},
+		},
+		{
+			name: "missing title",
+			content: `### Task 1: First
+- [ ] one
+`,
+			want: []plan.WarningCode{plan.WarnEmptyTitle},
+		},
+		{
+			name: "task without checkboxes",
+			content: `# Plan
+
+### Task 1: First
+just prose, nothing to check
+
+### Task 2: Second
+- [ ] two
+`,
+			want: []plan.WarningCode{plan.WarnTaskNoCheckboxes},
+		},
+		{
+			name: "duplicate task numbers",
+			content: `# Plan
+
+### Task 1: First
+- [ ] one
+
+### Task 1: Again
+- [ ] again
+`,
+			want: []plan.WarningCode{plan.WarnDuplicateTaskNumber},
+		},
+		{
+			name: "non-integer task numbers are not duplicates",
+			content: `# Plan
+
+### Task 2.5: First
+- [ ] one
+
+### Task 2a: Second
+- [ ] two
+`,
+			want: nil,
+		},
+		{
+			name: "checkboxes outside tasks",
+			content: `# Plan
+
+- [ ] before any task
+
+### Task 1: First
+- [ ] one
+
+## Success criteria
+- [ ] manual check
+`,
+			want: []plan.WarningCode{plan.WarnCheckboxOutsideTask},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := plan.ParsePlan(tc.content)
+			require.NoError(t, err)
+
+			var codes []plan.WarningCode
+			for _, w := range p.Validate() {
+				assert.NotEmpty(t, w.Message)
+				codes = append(codes, w.Code)
+			}
+			assert.Equal(t, tc.want, codes)
+		})
+	}
+}
+
+func TestPlan_Validate_Messages(t *testing.T) {
+	p, err := plan.ParsePlan(`# Plan
+
+- [ ] stray item
+- [ ] another stray
+
+### Task 3: Setup
+
+### Task 3: Build
+- [ ] compile
+`)
+	require.NoError(t, err)
+
+	warnings := p.Validate()
+	require.Len(t, warnings, 3)
+	assert.Equal(t, "task_without_checkboxes: task 3 (Setup) has no checkboxes, it can't be tracked or completed", warnings[0].String())
+	assert.Equal(t, "task number 3 is used more than once (Build)", warnings[1].Message)
+	assert.Equal(t, `2 checkbox(es) outside task sections are ignored when tracking progress, first: "stray item"`, warnings[2].Message)
+}
+
+func TestPlan_ValidateSize(t *testing.T) {
+	planWithTasks := func(n, padding int) string { // padding adds about that many bytes of note lines
+		var sb strings.Builder
+		sb.WriteString("# Plan\n\n")
+		for i := 1; i <= n; i++ {
+			fmt.Fprintf(&sb, "### Task %d: Step %d\n- [ ] do it\n\n", i, i)
+		}
+		sb.WriteString(strings.Repeat("note\n", padding/5+1))
+		return sb.String()
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		maxTasks int
+		want     []plan.WarningCode
+	}{
+		{name: "within limit", content: planWithTasks(3, 0), maxTasks: 3, want: nil},
+		{name: "too many tasks", content: planWithTasks(4, 0), maxTasks: 3, want: []plan.WarningCode{plan.WarnTooManyTasks}},
+		{name: "task limit disabled", content: planWithTasks(40, 0), maxTasks: 0, want: nil},
+		{name: "file too large", content: planWithTasks(1, plan.LargePlanBytes), maxTasks: 3,
+			want: []plan.WarningCode{plan.WarnPlanTooLarge}},
+		{name: "both", content: planWithTasks(5, plan.LargePlanBytes), maxTasks: 2,
+			want: []plan.WarningCode{plan.WarnTooManyTasks, plan.WarnPlanTooLarge}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := plan.ParsePlan(tc.content)
+			require.NoError(t, err)
+			var codes []plan.WarningCode
+			for _, w := range p.ValidateSize(tc.maxTasks) {
+				assert.Contains(t, w.Message, "consider splitting it")
+				codes = append(codes, w.Code)
+			}
+			assert.Equal(t, tc.want, codes)
+		})
+	}
+
+	p, err := plan.ParsePlan(planWithTasks(4, 0))
+	require.NoError(t, err)
+	warnings := p.ValidateSize(3)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "too_many_tasks: plan has 4 tasks (limit 3), consider splitting it into smaller plans", warnings[0].String())
+}
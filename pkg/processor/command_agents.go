@@ -0,0 +1,89 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// maxCommandAgentOutput caps the output of a command agent included in a review prompt, in runes.
+const maxCommandAgentOutput = 20000
+
+// buildReviewPrompt replaces all variables in a review prompt, running the command agents it references
+// and expanding them to their output. the commands run for every prompt built, so each review iteration
+// sees the current state of the code.
+func (r *Runner) buildReviewPrompt(ctx context.Context, prompt string) string {
+	result := r.replaceBaseVariables(prompt)
+	return r.expandAgentReferences(result, r.runCommandAgents(ctx, result))
+}
+
+// runCommandAgents runs each command agent referenced in prompt once and returns the formatted output by agent name.
+// unknown agents, prompt agents and agents scoped out of the current mode via agent_modes are skipped.
+func (r *Runner) runCommandAgents(ctx context.Context, prompt string) map[string]string {
+	if r.cfg.AppConfig == nil {
+		return nil
+	}
+	var res map[string]string
+	for _, m := range agentRefPattern.FindAllStringSubmatch(prompt, -1) {
+		name := m[1]
+		if _, done := res[name]; done {
+			continue
+		}
+		idx := slices.IndexFunc(r.cfg.AppConfig.CustomAgents, func(a config.CustomAgent) bool { return a.Name == name })
+		if idx < 0 {
+			continue
+		}
+		agent := r.cfg.AppConfig.CustomAgents[idx]
+		if !agent.IsCommand() || !r.cfg.AppConfig.AgentModes.Allows(name, string(r.cfg.Mode)) {
+			continue
+		}
+		if res == nil {
+			res = map[string]string{}
+		}
+		res[name] = r.runCommandAgent(ctx, agent)
+	}
+	return res
+}
+
+// runCommandAgent runs the command of a command agent through the system shell and returns its combined output
+// and exit status, formatted for the review prompt. a failing command is reported to claude, not to the run:
+// linters and type checkers exit non-zero when they find problems.
+func (r *Runner) runCommandAgent(ctx context.Context, agent config.CustomAgent) string {
+	command := r.replaceBaseVariables(agent.Prompt)
+	r.log.Print("agent %q: running %s", agent.Name, command)
+
+	var out strings.Builder
+	hook := &executor.HookExecutor{Command: command, OutputHandler: func(text string) { out.WriteString(text) }}
+	if r.cmdRunner != nil {
+		hook.SetRunner(r.cmdRunner)
+	}
+
+	result := "exited successfully"
+	if err := hook.Run(ctx); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result = fmt.Sprintf("exited with status %d", exitErr.ExitCode())
+		} else {
+			result = fmt.Sprintf("failed: %v", err)
+		}
+		r.log.Print("agent %q: %s", agent.Name, result)
+	}
+
+	output := strings.TrimSpace(out.String())
+	// limit to maxCommandAgentOutput runes to avoid splitting multi-byte characters
+	if runes := []rune(output); len(runes) > maxCommandAgentOutput {
+		output = string(runes[:maxCommandAgentOutput]) + "\n... (output truncated)"
+	}
+	if output == "" {
+		output = "(no output)"
+	}
+
+	return fmt.Sprintf("The %s check ran `%s` and %s. Its output:\n```\n%s\n```\n"+
+		"Verify each problem it reports like a finding from the review agents.", agent.Name, command, result, output)
+}
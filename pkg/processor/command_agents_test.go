@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	execmocks "github.com/umputun/ralphex/pkg/executor/mocks"
+)
+
+func TestRunner_buildReviewPrompt(t *testing.T) {
+	lint := config.CustomAgent{Name: "lint", Prompt: "golangci-lint run {{DEFAULT_BRANCH}}", Options: config.Options{Kind: config.AgentKindCommand}}
+	quality := config.CustomAgent{Name: "quality", Prompt: "check quality"}
+	newRunner := func(output string, waitErr error) (*Runner, *execmocks.CommandRunnerMock) {
+		appCfg := &config.Config{CustomAgents: []config.CustomAgent{lint, quality}}
+		cmdMock := &execmocks.CommandRunnerMock{
+			RunFunc: func(context.Context, string, ...string) (io.Reader, func() error, error) {
+				return strings.NewReader(output), func() error { return waitErr }, nil
+			},
+		}
+		r := &Runner{cfg: Config{DefaultBranch: "main", AppConfig: appCfg}, log: newMockLogger(""), cmdRunner: cmdMock}
+		return r, cmdMock
+	}
+	const template = "{{agent:quality}}\n{{agent:lint}}\n{{agent:lint}}"
+
+	t.Run("output goes into the prompt", func(t *testing.T) {
+		r, cmdMock := newRunner("main.go:3: unused variable x\n", errors.New("boom"))
+		result := r.buildReviewPrompt(context.Background(), template)
+
+		require.Len(t, cmdMock.RunCalls(), 1, "command runs once per prompt")
+		assert.Equal(t, "golangci-lint run main", cmdMock.RunCalls()[0].Args[len(cmdMock.RunCalls()[0].Args)-1])
+		assert.Contains(t, result, "check quality")
+		assert.Contains(t, result, "The lint check ran `golangci-lint run main` and failed: hook exited with error: boom. Its output:\n"+
+			"```\nmain.go:3: unused variable x\n```\n")
+		assert.Equal(t, 2, strings.Count(result, "The lint check ran"))
+
+		r.buildReviewPrompt(context.Background(), template)
+		assert.Len(t, cmdMock.RunCalls(), 2, "each prompt runs the command again")
+	})
+
+	t.Run("empty output", func(t *testing.T) {
+		r, _ := newRunner("", nil)
+		result := r.buildReviewPrompt(context.Background(), "{{agent:lint}}")
+		assert.Contains(t, result, "and exited successfully. Its output:\n```\n(no output)\n```")
+	})
+
+	t.Run("skipped when scoped out of the mode", func(t *testing.T) {
+		r, cmdMock := newRunner("ignored", nil)
+		r.cfg.Mode = ModeReview
+		r.cfg.AppConfig.AgentModes = config.AgentModes{"lint": {"full"}}
+		result := r.buildReviewPrompt(context.Background(), template)
+		assert.Empty(t, cmdMock.RunCalls())
+		assert.NotContains(t, result, "lint")
+	})
+
+	t.Run("outside review prompts the reference is dropped", func(t *testing.T) {
+		r, cmdMock := newRunner("ignored", nil)
+		assert.Equal(t, "check: .", r.replacePromptVariables("check: {{agent:lint}}."))
+		assert.Empty(t, cmdMock.RunCalls())
+	})
+}
+
+func TestRunner_runCommandAgent_ExitStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	r := &Runner{cfg: Config{AppConfig: &config.Config{}}, log: newMockLogger("")}
+	agent := config.CustomAgent{Name: "vet", Prompt: "echo 'pkg/a.go:1: bad'; exit 3", Options: config.Options{Kind: config.AgentKindCommand}}
+	result := r.runCommandAgent(context.Background(), agent)
+	assert.Contains(t, result, "and exited with status 3. Its output:\n```\npkg/a.go:1: bad\n```")
+}
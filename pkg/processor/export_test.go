@@ -10,17 +10,19 @@ import (
 // TestRunnerConfig provides test access to runner's internal configuration.
 // this file is only compiled during test builds (`go test`).
 type TestRunnerConfig struct {
-	IterationDelay time.Duration
-	TaskRetryCount int
-	WaitOnLimit    time.Duration
+	IterationDelay  time.Duration
+	TaskRetryCount  int
+	WaitOnLimit     time.Duration
+	MaxLimitRetries int
 }
 
 // TestConfig returns internal configuration values for testing.
 func (r *Runner) TestConfig() TestRunnerConfig {
 	return TestRunnerConfig{
-		IterationDelay: r.iterationDelay,
-		TaskRetryCount: r.taskRetryCount,
-		WaitOnLimit:    r.waitOnLimit,
+		IterationDelay:  r.iterationDelay,
+		TaskRetryCount:  r.taskRetryCount,
+		WaitOnLimit:     r.waitOnLimit,
+		MaxLimitRetries: r.maxLimitRetries,
 	}
 }
 
@@ -0,0 +1,74 @@
+package processor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// codexFindingRe matches a codex finding header line, e.g. "- [P1] Handle nil config — pkg/config/config.go:42-50"
+var codexFindingRe = regexp.MustCompile(`^\s*(?:[-*]\s+)?\[P([0-3])\]\s+(.+)$`)
+
+// codexFinding is a single finding parsed from codex review output.
+type codexFinding struct {
+	Priority int    // 0 (most severe) to 3, from the [Pn] marker
+	Title    string // rest of the header line, usually title and location
+	Body     string // indented explanation lines following the header
+}
+
+// String formats the finding as "[Pn] title" followed by the body, if any.
+func (f codexFinding) String() string {
+	if f.Body == "" {
+		return fmt.Sprintf("[P%d] %s", f.Priority, f.Title)
+	}
+	return fmt.Sprintf("[P%d] %s\n%s", f.Priority, f.Title, f.Body)
+}
+
+// parseCodexFindings extracts [Pn] findings from codex review output.
+// a finding's body is the run of indented lines after its header, up to a blank line or the next finding.
+func parseCodexFindings(output string) []codexFinding {
+	var findings []codexFinding
+	var body []string
+	inBody := false
+
+	flush := func() {
+		if len(findings) > 0 && len(body) > 0 {
+			findings[len(findings)-1].Body = strings.Join(body, "\n")
+		}
+		body = nil
+	}
+
+	for line := range strings.SplitSeq(output, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if m := codexFindingRe.FindStringSubmatch(line); m != nil {
+			flush()
+			priority, _ := strconv.Atoi(m[1]) // regex guarantees a single digit
+			findings = append(findings, codexFinding{Priority: priority, Title: strings.TrimSpace(m[2])})
+			inBody = true
+			continue
+		}
+		if !inBody {
+			continue
+		}
+		if line == "" || (line[0] != ' ' && line[0] != '\t') {
+			flush()
+			inBody = false
+			continue
+		}
+		body = append(body, strings.TrimSpace(line))
+	}
+	flush()
+	return findings
+}
+
+// blockingFindings returns findings at or above the given priority (lower number = more severe).
+func blockingFindings(findings []codexFinding, maxPriority int) []codexFinding {
+	var res []codexFinding
+	for _, f := range findings {
+		if f.Priority <= maxPriority {
+			res = append(res, f)
+		}
+	}
+	return res
+}
@@ -0,0 +1,81 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCodexFindings(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []codexFinding
+	}{
+		{name: "empty", output: "", want: nil},
+		{name: "no findings", output: "looks good, nothing to report", want: nil},
+		{
+			name: "findings with bodies",
+			output: "Review summary\n\n" +
+				"- [P1] Handle nil config — pkg/config/config.go:42-50\n" +
+				"  Load returns nil on a missing file,\n" +
+				"  callers dereference it.\n" +
+				"- [P2] Missing test — pkg/config/config_test.go:1\n" +
+				"\n" +
+				"trailing text\n",
+			want: []codexFinding{
+				{Priority: 1, Title: "Handle nil config — pkg/config/config.go:42-50",
+					Body: "Load returns nil on a missing file,\ncallers dereference it."},
+				{Priority: 2, Title: "Missing test — pkg/config/config_test.go:1"},
+			},
+		},
+		{
+			name:   "unindented text ends body",
+			output: "[P0] Data loss on retry\nnot part of the finding\n* [P3] style nit\r\n",
+			want: []codexFinding{
+				{Priority: 0, Title: "Data loss on retry"},
+				{Priority: 3, Title: "style nit"},
+			},
+		},
+		{name: "marker mid-line is not a finding", output: "see [P1] above", want: nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseCodexFindings(tc.output))
+		})
+	}
+}
+
+func TestBlockingFindings(t *testing.T) {
+	findings := []codexFinding{{Priority: 0, Title: "a"}, {Priority: 1, Title: "b"}, {Priority: 2, Title: "c"}}
+	assert.Equal(t, []codexFinding{{Priority: 0, Title: "a"}, {Priority: 1, Title: "b"}}, blockingFindings(findings, 1))
+	assert.Empty(t, blockingFindings(findings[2:], 1))
+}
+
+func TestCodexFinding_String(t *testing.T) {
+	assert.Equal(t, "[P1] title", codexFinding{Priority: 1, Title: "title"}.String())
+	assert.Equal(t, "[P2] title\nbody", codexFinding{Priority: 2, Title: "title", Body: "body"}.String())
+}
+
+func TestMatchesAnyPattern(t *testing.T) {
+	tests := []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "pkg/auth/token.go", true},
+		{"*.go", "pkg/auth/token.md", false},
+		{"auth/**/*.go", "auth/token.go", true},
+		{"auth/**/*.go", "auth/jwt/sign/token.go", true},
+		{"auth/**/*.go", "pkg/auth/token.go", false},
+		{"**/auth/*.go", "pkg/auth/token.go", true},
+		{"pkg/*/handler.go", "pkg/api/handler.go", true},
+		{"pkg/*/handler.go", "pkg/api/v1/handler.go", false},
+		{"migrations/**", "migrations/001_init.sql", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.pattern+" "+tc.file, func(t *testing.T) {
+			assert.Equal(t, tc.want, matchesAnyPattern([]string{tc.pattern}, tc.file))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// IterationRecord describes the outcome of a single executor run within the pipeline.
+// one record is added per claude, codex or custom run, including rate limit retries.
+type IterationRecord struct {
+	Num      int           // 1-based sequence number across the whole run
+	Phase    status.Phase  // phase active during the run
+	Tool     string        // executor name: claude, codex or custom
+	Signal   string        // detected signal name without markers, e.g. COMPLETED
+	Duration time.Duration // wall time of the run
+	Retry    bool          // run repeated a previous attempt (task retry, session timeout or rate limit)
+	Error    string        // error message, empty on success
+}
+
+// recordIteration appends an iteration record for a finished executor run.
+// consumes the pending retry flag set by loops that repeat an iteration.
+func (r *Runner) recordIteration(toolName string, result executor.Result, started time.Time, retry bool) {
+	rec := IterationRecord{
+		Num:      len(r.iterations) + 1,
+		Tool:     toolName,
+		Signal:   signalName(result.Signal),
+		Duration: time.Since(started),
+		Retry:    retry || r.retryPending,
+	}
+	if r.phaseHolder != nil {
+		rec.Phase = r.phaseHolder.Get()
+	}
+	if result.Error != nil {
+		rec.Error = result.Error.Error()
+	}
+	r.retryPending = false
+	r.iterations = append(r.iterations, rec)
+}
+
+// Iterations returns records of all executor runs made so far, in execution order.
+func (r *Runner) Iterations() []IterationRecord {
+	return append([]IterationRecord(nil), r.iterations...)
+}
+
+// signalName strips the <<<RALPHEX:...>>> markers from a signal.
+func signalName(signal string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(signal, "<<<RALPHEX:"), ">>>")
+}
+
+// FormatIterationsReport renders iteration records as an aligned table.
+// returns empty string if there are no records.
+func FormatIterationsReport(records []IterationRecord) (string, error) {
+	if len(records) == 0 {
+		return "", nil
+	}
+	var sb strings.Builder
+	sb.WriteString("iterations report:\n")
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  #\tphase\ttool\tsignal\tduration\tretry")
+	for _, rec := range records {
+		sig := rec.Signal
+		switch {
+		case rec.Error != "":
+			sig = "error: " + rec.Error
+		case sig == "":
+			sig = "-"
+		}
+		retry := ""
+		if rec.Retry {
+			retry = "yes"
+		}
+		fmt.Fprintf(tw, "  %d\t%s\t%s\t%s\t%s\t%s\n", rec.Num, rec.Phase, rec.Tool, sig,
+			rec.Duration.Round(time.Second), retry)
+	}
+	if err := tw.Flush(); err != nil {
+		return "", fmt.Errorf("flush iterations report: %w", err)
+	}
+	return sb.String(), nil
+}
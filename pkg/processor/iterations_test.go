@@ -0,0 +1,43 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestFormatIterationsReport(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		report, err := FormatIterationsReport(nil)
+		require.NoError(t, err)
+		assert.Empty(t, report)
+	})
+
+	t.Run("table", func(t *testing.T) {
+		records := []IterationRecord{
+			{Num: 1, Phase: status.PhaseTask, Tool: "claude", Duration: 62 * time.Second},
+			{Num: 2, Phase: status.PhaseTask, Tool: "claude", Signal: "TASK_FAILED", Duration: 3 * time.Second},
+			{Num: 3, Phase: status.PhaseTask, Tool: "claude", Signal: "COMPLETED", Duration: 1500 * time.Millisecond, Retry: true},
+			{Num: 4, Phase: status.PhaseCodex, Tool: "codex", Error: "detected limit pattern: \"rate limit\""},
+		}
+		want := "iterations report:\n" +
+			"  #  phase  tool    signal                                       duration  retry\n" +
+			"  1  task   claude  -                                            1m2s      \n" +
+			"  2  task   claude  TASK_FAILED                                  3s        \n" +
+			"  3  task   claude  COMPLETED                                    2s        yes\n" +
+			"  4  codex  codex   error: detected limit pattern: \"rate limit\"  0s        \n"
+		report, err := FormatIterationsReport(records)
+		require.NoError(t, err)
+		assert.Equal(t, want, report)
+	})
+}
+
+func TestSignalName(t *testing.T) {
+	assert.Equal(t, "ALL_TASKS_DONE", signalName(status.Completed))
+	assert.Equal(t, "REVIEW_DONE", signalName(status.ReviewDone))
+	assert.Empty(t, signalName(""))
+}
@@ -5,6 +5,8 @@ package mocks
 
 import (
 	"sync"
+
+	"github.com/umputun/ralphex/pkg/git"
 )
 
 // GitCheckerMock is a mock implementation of processor.GitChecker.
@@ -13,9 +15,27 @@ import (
 //
 //		// make and configure a mocked processor.GitChecker
 //		mockedGitChecker := &GitCheckerMock{
+//			BlameLineFunc: func(file string, line int) (string, string, error) {
+//				panic("mock out the BlameLine method")
+//			},
+//			ChangedFilesFunc: func(baseBranch string) ([]string, error) {
+//				panic("mock out the ChangedFiles method")
+//			},
+//			CommitLogFunc: func(baseBranch string) ([]string, error) {
+//				panic("mock out the CommitLog method")
+//			},
 //			DiffFingerprintFunc: func() (string, error) {
 //				panic("mock out the DiffFingerprint method")
 //			},
+//			DiffStatsFunc: func(baseBranch string, exclude ...string) (git.DiffStats, error) {
+//				panic("mock out the DiffStats method")
+//			},
+//			DiffStatsPerFileFunc: func(baseBranch string, exclude ...string) ([]git.FileDiffStat, error) {
+//				panic("mock out the DiffStatsPerFile method")
+//			},
+//			DiffStatsRangeFunc: func(from string, to string, exclude ...string) (git.DiffStats, error) {
+//				panic("mock out the DiffStatsRange method")
+//			},
 //			HeadHashFunc: func() (string, error) {
 //				panic("mock out the HeadHash method")
 //			},
@@ -26,23 +46,187 @@ import (
 //
 //	}
 type GitCheckerMock struct {
+	// BlameLineFunc mocks the BlameLine method.
+	BlameLineFunc func(file string, line int) (string, string, error)
+
+	// ChangedFilesFunc mocks the ChangedFiles method.
+	ChangedFilesFunc func(baseBranch string) ([]string, error)
+
+	// CommitLogFunc mocks the CommitLog method.
+	CommitLogFunc func(baseBranch string) ([]string, error)
+
 	// DiffFingerprintFunc mocks the DiffFingerprint method.
 	DiffFingerprintFunc func() (string, error)
 
+	// DiffStatsFunc mocks the DiffStats method.
+	DiffStatsFunc func(baseBranch string, exclude ...string) (git.DiffStats, error)
+
+	// DiffStatsPerFileFunc mocks the DiffStatsPerFile method.
+	DiffStatsPerFileFunc func(baseBranch string, exclude ...string) ([]git.FileDiffStat, error)
+
+	// DiffStatsRangeFunc mocks the DiffStatsRange method.
+	DiffStatsRangeFunc func(from string, to string, exclude ...string) (git.DiffStats, error)
+
 	// HeadHashFunc mocks the HeadHash method.
 	HeadHashFunc func() (string, error)
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// BlameLine holds details about calls to the BlameLine method.
+		BlameLine []struct {
+			// File is the file argument value.
+			File string
+			// Line is the line argument value.
+			Line int
+		}
+		// ChangedFiles holds details about calls to the ChangedFiles method.
+		ChangedFiles []struct {
+			// BaseBranch is the baseBranch argument value.
+			BaseBranch string
+		}
+		// CommitLog holds details about calls to the CommitLog method.
+		CommitLog []struct {
+			// BaseBranch is the baseBranch argument value.
+			BaseBranch string
+		}
 		// DiffFingerprint holds details about calls to the DiffFingerprint method.
 		DiffFingerprint []struct {
 		}
+		// DiffStats holds details about calls to the DiffStats method.
+		DiffStats []struct {
+			// BaseBranch is the baseBranch argument value.
+			BaseBranch string
+			// Exclude is the exclude argument value.
+			Exclude []string
+		}
+		// DiffStatsPerFile holds details about calls to the DiffStatsPerFile method.
+		DiffStatsPerFile []struct {
+			// BaseBranch is the baseBranch argument value.
+			BaseBranch string
+			// Exclude is the exclude argument value.
+			Exclude []string
+		}
+		// DiffStatsRange holds details about calls to the DiffStatsRange method.
+		DiffStatsRange []struct {
+			// From is the from argument value.
+			From string
+			// To is the to argument value.
+			To string
+			// Exclude is the exclude argument value.
+			Exclude []string
+		}
 		// HeadHash holds details about calls to the HeadHash method.
 		HeadHash []struct {
 		}
 	}
-	lockDiffFingerprint sync.RWMutex
-	lockHeadHash        sync.RWMutex
+	lockBlameLine        sync.RWMutex
+	lockChangedFiles     sync.RWMutex
+	lockCommitLog        sync.RWMutex
+	lockDiffFingerprint  sync.RWMutex
+	lockDiffStats        sync.RWMutex
+	lockDiffStatsPerFile sync.RWMutex
+	lockDiffStatsRange   sync.RWMutex
+	lockHeadHash         sync.RWMutex
+}
+
+// BlameLine calls BlameLineFunc.
+func (mock *GitCheckerMock) BlameLine(file string, line int) (string, string, error) {
+	if mock.BlameLineFunc == nil {
+		panic("GitCheckerMock.BlameLineFunc: method is nil but GitChecker.BlameLine was just called")
+	}
+	callInfo := struct {
+		File string
+		Line int
+	}{
+		File: file,
+		Line: line,
+	}
+	mock.lockBlameLine.Lock()
+	mock.calls.BlameLine = append(mock.calls.BlameLine, callInfo)
+	mock.lockBlameLine.Unlock()
+	return mock.BlameLineFunc(file, line)
+}
+
+// BlameLineCalls gets all the calls that were made to BlameLine.
+// Check the length with:
+//
+//	len(mockedGitChecker.BlameLineCalls())
+func (mock *GitCheckerMock) BlameLineCalls() []struct {
+	File string
+	Line int
+} {
+	var calls []struct {
+		File string
+		Line int
+	}
+	mock.lockBlameLine.RLock()
+	calls = mock.calls.BlameLine
+	mock.lockBlameLine.RUnlock()
+	return calls
+}
+
+// ChangedFiles calls ChangedFilesFunc.
+func (mock *GitCheckerMock) ChangedFiles(baseBranch string) ([]string, error) {
+	if mock.ChangedFilesFunc == nil {
+		panic("GitCheckerMock.ChangedFilesFunc: method is nil but GitChecker.ChangedFiles was just called")
+	}
+	callInfo := struct {
+		BaseBranch string
+	}{
+		BaseBranch: baseBranch,
+	}
+	mock.lockChangedFiles.Lock()
+	mock.calls.ChangedFiles = append(mock.calls.ChangedFiles, callInfo)
+	mock.lockChangedFiles.Unlock()
+	return mock.ChangedFilesFunc(baseBranch)
+}
+
+// ChangedFilesCalls gets all the calls that were made to ChangedFiles.
+// Check the length with:
+//
+//	len(mockedGitChecker.ChangedFilesCalls())
+func (mock *GitCheckerMock) ChangedFilesCalls() []struct {
+	BaseBranch string
+} {
+	var calls []struct {
+		BaseBranch string
+	}
+	mock.lockChangedFiles.RLock()
+	calls = mock.calls.ChangedFiles
+	mock.lockChangedFiles.RUnlock()
+	return calls
+}
+
+// CommitLog calls CommitLogFunc.
+func (mock *GitCheckerMock) CommitLog(baseBranch string) ([]string, error) {
+	if mock.CommitLogFunc == nil {
+		panic("GitCheckerMock.CommitLogFunc: method is nil but GitChecker.CommitLog was just called")
+	}
+	callInfo := struct {
+		BaseBranch string
+	}{
+		BaseBranch: baseBranch,
+	}
+	mock.lockCommitLog.Lock()
+	mock.calls.CommitLog = append(mock.calls.CommitLog, callInfo)
+	mock.lockCommitLog.Unlock()
+	return mock.CommitLogFunc(baseBranch)
+}
+
+// CommitLogCalls gets all the calls that were made to CommitLog.
+// Check the length with:
+//
+//	len(mockedGitChecker.CommitLogCalls())
+func (mock *GitCheckerMock) CommitLogCalls() []struct {
+	BaseBranch string
+} {
+	var calls []struct {
+		BaseBranch string
+	}
+	mock.lockCommitLog.RLock()
+	calls = mock.calls.CommitLog
+	mock.lockCommitLog.RUnlock()
+	return calls
 }
 
 // DiffFingerprint calls DiffFingerprintFunc.
@@ -72,6 +256,118 @@ func (mock *GitCheckerMock) DiffFingerprintCalls() []struct {
 	return calls
 }
 
+// DiffStats calls DiffStatsFunc.
+func (mock *GitCheckerMock) DiffStats(baseBranch string, exclude ...string) (git.DiffStats, error) {
+	if mock.DiffStatsFunc == nil {
+		panic("GitCheckerMock.DiffStatsFunc: method is nil but GitChecker.DiffStats was just called")
+	}
+	callInfo := struct {
+		BaseBranch string
+		Exclude    []string
+	}{
+		BaseBranch: baseBranch,
+		Exclude:    exclude,
+	}
+	mock.lockDiffStats.Lock()
+	mock.calls.DiffStats = append(mock.calls.DiffStats, callInfo)
+	mock.lockDiffStats.Unlock()
+	return mock.DiffStatsFunc(baseBranch, exclude...)
+}
+
+// DiffStatsCalls gets all the calls that were made to DiffStats.
+// Check the length with:
+//
+//	len(mockedGitChecker.DiffStatsCalls())
+func (mock *GitCheckerMock) DiffStatsCalls() []struct {
+	BaseBranch string
+	Exclude    []string
+} {
+	var calls []struct {
+		BaseBranch string
+		Exclude    []string
+	}
+	mock.lockDiffStats.RLock()
+	calls = mock.calls.DiffStats
+	mock.lockDiffStats.RUnlock()
+	return calls
+}
+
+// DiffStatsPerFile calls DiffStatsPerFileFunc.
+func (mock *GitCheckerMock) DiffStatsPerFile(baseBranch string, exclude ...string) ([]git.FileDiffStat, error) {
+	if mock.DiffStatsPerFileFunc == nil {
+		panic("GitCheckerMock.DiffStatsPerFileFunc: method is nil but GitChecker.DiffStatsPerFile was just called")
+	}
+	callInfo := struct {
+		BaseBranch string
+		Exclude    []string
+	}{
+		BaseBranch: baseBranch,
+		Exclude:    exclude,
+	}
+	mock.lockDiffStatsPerFile.Lock()
+	mock.calls.DiffStatsPerFile = append(mock.calls.DiffStatsPerFile, callInfo)
+	mock.lockDiffStatsPerFile.Unlock()
+	return mock.DiffStatsPerFileFunc(baseBranch, exclude...)
+}
+
+// DiffStatsPerFileCalls gets all the calls that were made to DiffStatsPerFile.
+// Check the length with:
+//
+//	len(mockedGitChecker.DiffStatsPerFileCalls())
+func (mock *GitCheckerMock) DiffStatsPerFileCalls() []struct {
+	BaseBranch string
+	Exclude    []string
+} {
+	var calls []struct {
+		BaseBranch string
+		Exclude    []string
+	}
+	mock.lockDiffStatsPerFile.RLock()
+	calls = mock.calls.DiffStatsPerFile
+	mock.lockDiffStatsPerFile.RUnlock()
+	return calls
+}
+
+// DiffStatsRange calls DiffStatsRangeFunc.
+func (mock *GitCheckerMock) DiffStatsRange(from string, to string, exclude ...string) (git.DiffStats, error) {
+	if mock.DiffStatsRangeFunc == nil {
+		panic("GitCheckerMock.DiffStatsRangeFunc: method is nil but GitChecker.DiffStatsRange was just called")
+	}
+	callInfo := struct {
+		From    string
+		To      string
+		Exclude []string
+	}{
+		From:    from,
+		To:      to,
+		Exclude: exclude,
+	}
+	mock.lockDiffStatsRange.Lock()
+	mock.calls.DiffStatsRange = append(mock.calls.DiffStatsRange, callInfo)
+	mock.lockDiffStatsRange.Unlock()
+	return mock.DiffStatsRangeFunc(from, to, exclude...)
+}
+
+// DiffStatsRangeCalls gets all the calls that were made to DiffStatsRange.
+// Check the length with:
+//
+//	len(mockedGitChecker.DiffStatsRangeCalls())
+func (mock *GitCheckerMock) DiffStatsRangeCalls() []struct {
+	From    string
+	To      string
+	Exclude []string
+} {
+	var calls []struct {
+		From    string
+		To      string
+		Exclude []string
+	}
+	mock.lockDiffStatsRange.RLock()
+	calls = mock.calls.DiffStatsRange
+	mock.lockDiffStatsRange.RUnlock()
+	return calls
+}
+
 // HeadHash calls HeadHashFunc.
 func (mock *GitCheckerMock) HeadHash() (string, error) {
 	if mock.HeadHashFunc == nil {
@@ -39,6 +39,9 @@ import (
 //			PrintSectionFunc: func(section status.Section)  {
 //				panic("mock out the PrintSection method")
 //			},
+//			StartTaskFunc: func(task int)  {
+//				panic("mock out the StartTask method")
+//			},
 //		}
 //
 //		// use mockedLogger in code that requires processor.Logger
@@ -70,6 +73,9 @@ type LoggerMock struct {
 	// PrintSectionFunc mocks the PrintSection method.
 	PrintSectionFunc func(section status.Section)
 
+	// StartTaskFunc mocks the StartTask method.
+	StartTaskFunc func(task int)
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// LogAnswer holds details about calls to the LogAnswer method.
@@ -118,6 +124,11 @@ type LoggerMock struct {
 			// Section is the section argument value.
 			Section status.Section
 		}
+		// StartTask holds details about calls to the StartTask method.
+		StartTask []struct {
+			// Task is the task argument value.
+			Task int
+		}
 	}
 	lockLogAnswer      sync.RWMutex
 	lockLogDraftReview sync.RWMutex
@@ -127,6 +138,7 @@ type LoggerMock struct {
 	lockPrintAligned   sync.RWMutex
 	lockPrintRaw       sync.RWMutex
 	lockPrintSection   sync.RWMutex
+	lockStartTask      sync.RWMutex
 }
 
 // LogAnswer calls LogAnswerFunc.
@@ -395,3 +407,35 @@ func (mock *LoggerMock) PrintSectionCalls() []struct {
 	mock.lockPrintSection.RUnlock()
 	return calls
 }
+
+// StartTask calls StartTaskFunc.
+func (mock *LoggerMock) StartTask(task int) {
+	if mock.StartTaskFunc == nil {
+		panic("LoggerMock.StartTaskFunc: method is nil but Logger.StartTask was just called")
+	}
+	callInfo := struct {
+		Task int
+	}{
+		Task: task,
+	}
+	mock.lockStartTask.Lock()
+	mock.calls.StartTask = append(mock.calls.StartTask, callInfo)
+	mock.lockStartTask.Unlock()
+	mock.StartTaskFunc(task)
+}
+
+// StartTaskCalls gets all the calls that were made to StartTask.
+// Check the length with:
+//
+//	len(mockedLogger.StartTaskCalls())
+func (mock *LoggerMock) StartTaskCalls() []struct {
+	Task int
+} {
+	var calls []struct {
+		Task int
+	}
+	mock.lockStartTask.RLock()
+	calls = mock.calls.StartTask
+	mock.lockStartTask.RUnlock()
+	return calls
+}
@@ -1,10 +1,13 @@
 package processor
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/umputun/ralphex/pkg/config"
@@ -13,6 +16,15 @@ import (
 // agentRefPattern matches {{agent:name}} template syntax
 var agentRefPattern = regexp.MustCompile(`\{\{agent:([a-zA-Z0-9_-]+)\}\}`)
 
+// agentRefLinePattern matches an {{agent:name}} reference with the line break that follows it
+var agentRefLinePattern = regexp.MustCompile(`\{\{agent:([a-zA-Z0-9_-]+)\}\}\n?`)
+
+// maxCommitLogEntries limits how many commits are listed in {{COMMIT_LOG}}
+const maxCommitLogEntries = 20
+
+// maxPlanContentBytes caps the plan text embedded by {{PLAN_CONTENT}}
+const maxPlanContentBytes = 64 * 1024
+
 // getGoal returns the goal string based on whether a plan file is configured.
 func (r *Runner) getGoal() string {
 	if r.cfg.PlanFile == "" {
@@ -65,28 +77,203 @@ func (r *Runner) getProgressFileRef() string {
 }
 
 // replaceBaseVariables replaces common template variables in prompts.
-// supported: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}, {{PLANS_DIR}}
-// this is the core replacement function used by all prompt builders.
+// supported: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}, {{DIFF_RANGE}}, {{PLANS_DIR}}, {{TEST_COMMAND}}, {{COMMIT_LOG}},
+// {{PLAN_CONTENT}}, {{NEXT_TASK}}. this is the core replacement function used by all prompt builders.
 func (r *Runner) replaceBaseVariables(prompt string) string {
 	result := prompt
 	result = strings.ReplaceAll(result, "{{PLAN_FILE}}", r.getPlanFileRef())
 	result = strings.ReplaceAll(result, "{{PROGRESS_FILE}}", r.getProgressFileRef())
 	result = strings.ReplaceAll(result, "{{GOAL}}", r.getGoal())
+	result = strings.ReplaceAll(result, "{{DIFF_RANGE}}", r.getDiffRange())
 	result = strings.ReplaceAll(result, "{{DEFAULT_BRANCH}}", r.getDefaultBranch())
 	result = strings.ReplaceAll(result, "{{PLANS_DIR}}", r.getPlansDir())
+	result = strings.ReplaceAll(result, "{{TEST_COMMAND}}", r.cfg.TestCommand)
+	result = strings.ReplaceAll(result, "{{COMMIT_TRAILER}}", r.getCommitTrailer())
+	if strings.Contains(result, "{{COMMIT_LOG}}") {
+		result = strings.ReplaceAll(result, "{{COMMIT_LOG}}", r.getCommitLog())
+	}
+	if strings.Contains(result, "{{PLAN_CONTENT}}") {
+		result = strings.ReplaceAll(result, "{{PLAN_CONTENT}}", r.getPlanContent())
+	}
+	if strings.Contains(result, "{{NEXT_TASK}}") {
+		result = strings.ReplaceAll(result, "{{NEXT_TASK}}", r.getNextTask())
+	}
+	if strings.Contains(result, "{{REVIEW_FOCUS}}") {
+		result = strings.ReplaceAll(result, "{{REVIEW_FOCUS}}", r.getReviewFocus())
+	}
 	return result
 }
 
+// getNextTask names the plan task to work on next, picked from the current plan state by task_order.
+// returns empty string if there is no plan file, it can't be parsed, or no task has uncompleted work.
+func (r *Runner) getNextTask() string {
+	if r.cfg.PlanFile == "" {
+		return ""
+	}
+	p, err := r.parsePlan(r.resolvePlanFilePath())
+	if err != nil {
+		r.log.Print("warning: failed to parse plan for {{NEXT_TASK}}: %v", err)
+		return ""
+	}
+	idx := p.NextTask(r.taskOrder())
+	if idx < 0 {
+		return ""
+	}
+	t := p.Tasks[idx]
+	name := fmt.Sprintf("the Task section titled %q", t.Title)
+	if t.Number > 0 {
+		name = fmt.Sprintf("Task %d (%s)", t.Number, t.Title)
+	}
+	return fmt.Sprintf("The next task to work on is %s. Work on this task only, do not pick another one.", name)
+}
+
+// getPlanContent returns the current plan file text, framed for embedding in a prompt.
+// returns empty string if embed_plan_in_prompt is disabled, there is no plan file, or it can't be read.
+// text over maxPlanContentBytes is truncated with a note pointing to the file; the warning is logged once per run.
+func (r *Runner) getPlanContent() string {
+	if r.cfg.PlanFile == "" || r.cfg.AppConfig == nil || !r.cfg.AppConfig.EmbedPlanInPrompt {
+		return ""
+	}
+	path := r.resolvePlanFilePath()
+	data, err := os.ReadFile(path) //nolint:gosec // plan path comes from the user's selection
+	if err != nil {
+		r.log.Print("warning: failed to read plan for {{PLAN_CONTENT}}: %v", err)
+		return ""
+	}
+
+	content := strings.TrimRight(string(data), "\n")
+	if len(content) > maxPlanContentBytes {
+		if !r.planContentWarned {
+			r.log.Print("warning: plan is %d bytes, only the first %d are embedded in the prompt", len(content), maxPlanContentBytes)
+			r.planContentWarned = true
+		}
+		// cut on a rune boundary, a partial trailing rune is dropped
+		content = strings.ToValidUTF8(content[:maxPlanContentBytes], "") + "\n... (truncated, read " + path + " for the rest)"
+	}
+	return fmt.Sprintf("Current content of %s (read the file again before editing it):\n<plan>\n%s\n</plan>", path, content)
+}
+
+// getCommitTrailer returns the instruction to end commit messages with the commit_trailer line,
+// empty string if no trailer is configured.
+func (r *Runner) getCommitTrailer() string {
+	if r.cfg.CommitTrailer == "" {
+		return ""
+	}
+	return fmt.Sprintf("End every commit message with a blank line followed by this trailer line: %s", r.cfg.CommitTrailer)
+}
+
+// getReviewFocus returns the review_focus snippets whose patterns match a file changed since the default branch,
+// each snippet once and in config order. returns empty string if review_focus is not set, git is unavailable,
+// or no changed file matches. files are matched the same way as codex_reviewer_paths.
+func (r *Runner) getReviewFocus() string {
+	if r.git == nil || r.cfg.AppConfig == nil || len(r.cfg.AppConfig.ReviewFocus) == 0 {
+		return ""
+	}
+	files, err := r.git.ChangedFiles(r.getDefaultBranch())
+	if err != nil {
+		r.log.Print("warning: failed to get changed files for {{REVIEW_FOCUS}}: %v", err)
+		return ""
+	}
+
+	var snippets []string
+	added := make(map[string]bool)
+	for _, f := range r.cfg.AppConfig.ReviewFocus {
+		if added[f.Name] {
+			continue
+		}
+		patterns := []string{f.Pattern}
+		if !slices.ContainsFunc(files, func(file string) bool { return matchesAnyPattern(patterns, file) }) {
+			continue
+		}
+		added[f.Name] = true
+		snippets = append(snippets, strings.TrimSpace(f.Prompt))
+	}
+	if len(snippets) == 0 {
+		return ""
+	}
+	return "Additional review focus for the files changed on this branch (include it in each agent prompt):\n\n" +
+		strings.Join(snippets, "\n\n")
+}
+
+// getCommitLog returns a formatted list of commits on the branch since the default branch.
+// returns empty string if include_commit_log is disabled, git is unavailable, or there are no commits.
+// the list is capped at maxCommitLogEntries, with a note about how many older commits were omitted.
+func (r *Runner) getCommitLog() string {
+	if r.git == nil || r.cfg.AppConfig == nil || !r.cfg.AppConfig.IncludeCommitLog {
+		return ""
+	}
+	lines, err := r.git.CommitLog(r.getDefaultBranch())
+	if err != nil {
+		r.log.Print("warning: failed to get commit log: %v", err)
+		return ""
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Commits on this branch since %s (newest first):\n", r.getDefaultBranch())
+	for i, line := range lines {
+		if i == maxCommitLogEntries {
+			fmt.Fprintf(&sb, "- ... and %d more\n", len(lines)-maxCommitLogEntries)
+			break
+		}
+		sb.WriteString("- " + line + "\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
 // getDiffInstruction returns the appropriate git diff command based on iteration.
-// first iteration: compares default branch to HEAD (all changes in feature branch)
+// first iteration: the review diff range (all changes in feature branch, or uncommitted ones with review_working_tree)
 // subsequent iterations: shows uncommitted changes only (fixes from previous iteration)
 func (r *Runner) getDiffInstruction(isFirstIteration bool) string {
 	if isFirstIteration {
-		return fmt.Sprintf("git diff %s...HEAD", r.getDefaultBranch())
+		return "git diff " + r.getDiffRange()
 	}
 	return "git diff"
 }
 
+// getDiffRange returns the range reviews diff: default branch to HEAD,
+// or HEAD alone (staged and unstaged changes) when review_working_tree is enabled.
+// the range is followed by pathspecs leaving out reviewExcludes, if any.
+func (r *Runner) getDiffRange() string {
+	rng := r.getDefaultBranch() + "...HEAD"
+	if r.reviewWorkingTree() {
+		rng = "HEAD"
+	}
+	excludes := r.reviewExcludes()
+	if len(excludes) == 0 {
+		return rng
+	}
+	var sb strings.Builder
+	sb.WriteString(rng + " -- ':/'")
+	for _, path := range excludes {
+		sb.WriteString(" '" + strings.ReplaceAll(":(exclude)"+path, "'", `'\''`) + "'")
+	}
+	return sb.String()
+}
+
+// reviewExcludes returns the bookkeeping paths left out of review diffs and diff stats,
+// the plan file and the progress directory. returns nil if review_include_plan is set.
+func (r *Runner) reviewExcludes() []string {
+	if r.cfg.AppConfig != nil && r.cfg.AppConfig.ReviewIncludePlan {
+		return nil
+	}
+	var res []string
+	if r.cfg.PlanFile != "" {
+		res = append(res, r.resolvePlanFilePath())
+	}
+	if dir := filepath.Dir(r.cfg.ProgressPath); r.cfg.ProgressPath != "" && dir != "." {
+		res = append(res, dir)
+	}
+	return res
+}
+
+// reviewWorkingTree reports whether reviews target uncommitted changes instead of the branch diff.
+func (r *Runner) reviewWorkingTree() bool {
+	return r.cfg.AppConfig != nil && r.cfg.AppConfig.ReviewWorkingTree
+}
+
 // buildPreviousContext returns the PREVIOUS REVIEW CONTEXT block for external review prompts.
 // returns empty string on first iteration (no prior response), formatted context block on subsequent iterations.
 func (r *Runner) buildPreviousContext(claudeResponse string) string {
@@ -104,13 +291,13 @@ If Claude's arguments are invalid, explain why the issues still exist.`, claudeR
 }
 
 // replaceVariablesWithIteration replaces all template variables including iteration-aware ones.
-// supported: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}, {{PLANS_DIR}},
+// supported: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}, {{DIFF_RANGE}}, {{PLANS_DIR}},
 // {{DIFF_INSTRUCTION}}, {{PREVIOUS_REVIEW_CONTEXT}}, {{agent:name}}
 // this variant is used when iteration context is needed (e.g., external review prompts).
 func (r *Runner) replaceVariablesWithIteration(prompt string, isFirstIteration bool, claudeResponse string) string {
 	result := r.replaceBaseVariables(prompt)
 	result = strings.ReplaceAll(result, "{{DIFF_INSTRUCTION}}", r.getDiffInstruction(isFirstIteration))
-	result = r.expandAgentReferences(result) // expand agents before inserting external content
+	result = r.expandAgentReferences(result, nil) // expand agents before inserting external content
 	result = strings.ReplaceAll(result, "{{PREVIOUS_REVIEW_CONTEXT}}", r.buildPreviousContext(claudeResponse))
 	return result
 }
@@ -134,9 +321,11 @@ Report findings only - no positive observations.`, modelClause, subagent, prompt
 }
 
 // expandAgentReferences replaces {{agent:name}} patterns with Task tool instructions.
+// command agents expand to their entry in commandOutputs, the output of runCommandAgents for review prompts.
 // returns prompt unchanged if AppConfig is nil or no agents are configured.
 // missing agents log a warning and leave the reference as-is for visibility.
-func (r *Runner) expandAgentReferences(prompt string) string {
+// agents scoped out of the current mode via agent_modes are removed from the prompt.
+func (r *Runner) expandAgentReferences(prompt string, commandOutputs map[string]string) string {
 	if r.cfg.AppConfig == nil {
 		return prompt
 	}
@@ -161,6 +350,19 @@ func (r *Runner) expandAgentReferences(prompt string) string {
 			return match
 		}
 
+		if !r.cfg.AppConfig.AgentModes.Allows(name, string(r.cfg.Mode)) {
+			r.log.Print("agent %q skipped, not enabled for %s mode", name, r.cfg.Mode)
+			return ""
+		}
+
+		if agent.IsCommand() {
+			output, ran := commandOutputs[name]
+			if !ran {
+				r.log.Print("[WARN] command agent %q only runs in review prompts, reference dropped", name)
+			}
+			return output
+		}
+
 		r.log.Print("agent %q: %s", name, agent.Options)
 
 		// expand variables in agent content (no agent expansion to avoid recursion)
@@ -175,7 +377,7 @@ func (r *Runner) expandAgentReferences(prompt string) string {
 // note: {{CODEX_OUTPUT}} and {{PLAN_DESCRIPTION}} are handled by specific build functions.
 func (r *Runner) replacePromptVariables(prompt string) string {
 	result := r.replaceBaseVariables(prompt)
-	result = r.expandAgentReferences(result)
+	result = r.expandAgentReferences(result, nil)
 	return result
 }
 
@@ -195,6 +397,60 @@ func (r *Runner) getPlansDir() string {
 	return r.cfg.AppConfig.PlansDir
 }
 
+// buildFirstReviewPrompt creates the prompt for the first (comprehensive) review pass.
+// {{REVIEW_AGENTS}} expands to references to the agents from review_first_agents, then all variables are replaced.
+func (r *Runner) buildFirstReviewPrompt(ctx context.Context) string {
+	return r.buildReviewPrompt(ctx, r.reviewTemplate(r.cfg.AppConfig.ReviewFirstPrompt, r.cfg.AppConfig.ReviewFirstAgents))
+}
+
+// buildSecondReviewPrompt creates the prompt for the second (critical/major) review pass.
+// {{REVIEW_AGENTS}} expands to references to the agents from review_second_agents, then all variables are replaced.
+func (r *Runner) buildSecondReviewPrompt(ctx context.Context) string {
+	return r.buildReviewPrompt(ctx, r.reviewTemplate(r.cfg.AppConfig.ReviewSecondPrompt, r.cfg.AppConfig.ReviewSecondAgents))
+}
+
+// buildTaskReviewPrompt creates the prompt for the review of a single task's commits, from..to.
+// {{REVIEW_AGENTS}} expands to references to the agents from review_second_agents, like the second review pass.
+func (r *Runner) buildTaskReviewPrompt(ctx context.Context, taskNum int, from, to string) string {
+	prompt := r.reviewTemplate(r.cfg.AppConfig.TaskReviewPrompt, r.cfg.AppConfig.ReviewSecondAgents)
+	prompt = strings.ReplaceAll(prompt, "{{TASK_NUMBER}}", strconv.Itoa(taskNum))
+	prompt = strings.ReplaceAll(prompt, "{{TASK_DIFF_RANGE}}", from+".."+to)
+	return r.buildReviewPrompt(ctx, prompt)
+}
+
+// reviewTemplate expands {{REVIEW_AGENTS}} in a review prompt to references to the given agents and drops
+// references to disabled review agents. other variables are left for replacePromptVariables.
+func (r *Runner) reviewTemplate(prompt string, agents []string) string {
+	prompt = strings.ReplaceAll(prompt, "{{REVIEW_AGENTS}}", reviewAgentRefs(agents))
+	return r.dropDisabledReviewAgents(prompt)
+}
+
+// reviewAgentRefs returns one {{agent:name}} reference per line for the given agent names.
+func reviewAgentRefs(names []string) string {
+	refs := make([]string, 0, len(names))
+	for _, name := range names {
+		refs = append(refs, "{{agent:"+name+"}}")
+	}
+	return strings.Join(refs, "\n")
+}
+
+// dropDisabledReviewAgents removes {{agent:name}} references to agents listed in disabled_review_agents,
+// along with the line break after them, so the remaining review agents are expanded as usual.
+func (r *Runner) dropDisabledReviewAgents(prompt string) string {
+	disabled := r.cfg.AppConfig.DisabledReviewAgents
+	if len(disabled) == 0 {
+		return prompt
+	}
+	return agentRefLinePattern.ReplaceAllStringFunc(prompt, func(match string) string {
+		name := agentRefLinePattern.FindStringSubmatch(match)[1]
+		if !slices.Contains(disabled, name) {
+			return match
+		}
+		r.log.Print("agent %q skipped, disabled in review", name)
+		return ""
+	})
+}
+
 // buildCodexEvaluationPrompt creates the prompt for claude to evaluate codex review output.
 // uses the codex prompt loaded from config (either user-provided or embedded default).
 // agent references ({{agent:name}}) are expanded via replacePromptVariables.
@@ -1,15 +1,19 @@
 package processor
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
 )
 
 func TestRunner_replacePromptVariables_TaskPrompt(t *testing.T) {
@@ -25,15 +29,15 @@ func TestRunner_replacePromptVariables_TaskPrompt(t *testing.T) {
 	assert.Contains(t, prompt, "STOP HERE")
 }
 
-func TestRunner_replacePromptVariables_ReviewFirstPrompt(t *testing.T) {
+func TestRunner_buildFirstReviewPrompt(t *testing.T) {
 	t.Run("with plan file and progress path", func(t *testing.T) {
 		appCfg := testAppConfig(t)
 		r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", ProgressPath: "progress-test.txt", DefaultBranch: "main", AppConfig: appCfg}, log: newMockLogger("")}
-		prompt := r.replacePromptVariables(appCfg.ReviewFirstPrompt)
+		prompt := r.buildFirstReviewPrompt(t.Context())
 
 		assert.Contains(t, prompt, "docs/plans/test.md")
 		assert.Contains(t, prompt, "progress-test.txt") // progress file should be substituted
-		assert.Contains(t, prompt, "git diff main...HEAD")
+		assert.Contains(t, prompt, "`git diff main...HEAD -- ':/' ':(exclude)docs/plans/test.md'`", "plan excluded from diff")
 		assert.Contains(t, prompt, "<<<RALPHEX:REVIEW_DONE>>>")
 		assert.Contains(t, prompt, "<<<RALPHEX:TASK_FAILED>>>")
 		// verify expanded agent content from the 5 agents
@@ -48,7 +52,7 @@ func TestRunner_replacePromptVariables_ReviewFirstPrompt(t *testing.T) {
 	t.Run("without plan file uses default branch in goal", func(t *testing.T) {
 		appCfg := testAppConfig(t)
 		r := &Runner{cfg: Config{PlanFile: "", ProgressPath: "progress.txt", DefaultBranch: "trunk", AppConfig: appCfg}, log: newMockLogger("")}
-		prompt := r.replacePromptVariables(appCfg.ReviewFirstPrompt)
+		prompt := r.buildFirstReviewPrompt(t.Context())
 
 		assert.Contains(t, prompt, "current branch vs trunk")
 		assert.Contains(t, prompt, "progress.txt")
@@ -58,17 +62,40 @@ func TestRunner_replacePromptVariables_ReviewFirstPrompt(t *testing.T) {
 	t.Run("fallback to master when default branch not set", func(t *testing.T) {
 		appCfg := testAppConfig(t)
 		r := &Runner{cfg: Config{PlanFile: "", ProgressPath: "progress.txt", AppConfig: appCfg}, log: newMockLogger("")}
-		prompt := r.replacePromptVariables(appCfg.ReviewFirstPrompt)
+		prompt := r.buildFirstReviewPrompt(t.Context())
 
 		assert.Contains(t, prompt, "current branch vs master")
 	})
+
+	t.Run("disabled built-in agent is dropped", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.DisabledReviewAgents = []string{"testing"}
+		r := &Runner{cfg: Config{ProgressPath: "progress.txt", AppConfig: appCfg}, log: newMockLogger("")}
+		prompt := r.buildFirstReviewPrompt(t.Context())
+
+		assert.NotContains(t, prompt, "test coverage")         // testing agent disabled
+		assert.Contains(t, prompt, "security issues")          // quality agent still runs
+		assert.Contains(t, prompt, "achieves the stated goal") // implementation agent still runs
+	})
+
+	t.Run("working tree review uses uncommitted diff", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.ReviewWorkingTree = true
+		r := &Runner{cfg: Config{ProgressPath: "progress.txt", DefaultBranch: "main", AppConfig: appCfg}, log: newMockLogger("")}
+		for _, prompt := range []string{r.buildFirstReviewPrompt(t.Context()), r.buildSecondReviewPrompt(t.Context())} {
+			assert.Contains(t, prompt, "`git diff HEAD`")
+			assert.Contains(t, prompt, "`git diff --stat HEAD`")
+			assert.NotContains(t, prompt, "main...HEAD")
+			assert.NotContains(t, prompt, "{{DIFF_RANGE}}")
+		}
+	})
 }
 
-func TestRunner_replacePromptVariables_ReviewSecondPrompt(t *testing.T) {
+func TestRunner_buildSecondReviewPrompt(t *testing.T) {
 	t.Run("with plan file and progress path", func(t *testing.T) {
 		appCfg := testAppConfig(t)
 		r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", ProgressPath: "progress-test.txt", DefaultBranch: "main", AppConfig: appCfg}, log: newMockLogger("")}
-		prompt := r.replacePromptVariables(appCfg.ReviewSecondPrompt)
+		prompt := r.buildSecondReviewPrompt(t.Context())
 
 		assert.Contains(t, prompt, "docs/plans/test.md")
 		assert.Contains(t, prompt, "progress-test.txt") // progress file should be substituted
@@ -88,11 +115,50 @@ func TestRunner_replacePromptVariables_ReviewSecondPrompt(t *testing.T) {
 	t.Run("without plan file uses default branch in goal", func(t *testing.T) {
 		appCfg := testAppConfig(t)
 		r := &Runner{cfg: Config{PlanFile: "", ProgressPath: "progress.txt", DefaultBranch: "develop", AppConfig: appCfg}, log: newMockLogger("")}
-		prompt := r.replacePromptVariables(appCfg.ReviewSecondPrompt)
+		prompt := r.buildSecondReviewPrompt(t.Context())
 
 		assert.Contains(t, prompt, "current branch vs develop")
 		assert.Contains(t, prompt, "progress.txt")
 	})
+
+	t.Run("configured agent set", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.ReviewSecondAgents = []string{"testing"}
+		r := &Runner{cfg: Config{ProgressPath: "progress.txt", AppConfig: appCfg}, log: newMockLogger("")}
+		prompt := r.buildSecondReviewPrompt(t.Context())
+
+		assert.Contains(t, prompt, "test coverage")      // from testing agent
+		assert.NotContains(t, prompt, "security issues") // quality agent no longer listed
+		assert.NotContains(t, prompt, "{{REVIEW_AGENTS}}")
+	})
+}
+
+func TestReviewAgentRefs(t *testing.T) {
+	assert.Equal(t, "{{agent:quality}}\n{{agent:custom-go}}", reviewAgentRefs([]string{"quality", "custom-go"}))
+	assert.Empty(t, reviewAgentRefs(nil))
+}
+
+func TestRunner_dropDisabledReviewAgents(t *testing.T) {
+	tests := []struct {
+		name     string
+		disabled []string
+		prompt   string
+		want     string
+	}{
+		{name: "nothing disabled", prompt: "{{agent:quality}}\n{{agent:testing}}\n", want: "{{agent:quality}}\n{{agent:testing}}\n"},
+		{name: "agent list line removed", disabled: []string{"testing"},
+			prompt: "{{agent:quality}}\n{{agent:testing}}\n{{agent:documentation}}", want: "{{agent:quality}}\n{{agent:documentation}}"},
+		{name: "inline reference removed", disabled: []string{"testing", "quality"},
+			prompt: "run {{agent:quality}} and {{agent:testing}}", want: "run  and "},
+		{name: "other agents kept", disabled: []string{"simplification"},
+			prompt: "{{agent:quality}}\n{{agent:custom}}", want: "{{agent:quality}}\n{{agent:custom}}"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Runner{cfg: Config{AppConfig: &config.Config{DisabledReviewAgents: tc.disabled}}, log: newMockLogger("")}
+			assert.Equal(t, tc.want, r.dropDisabledReviewAgents(tc.prompt))
+		})
+	}
 }
 
 func TestRunner_replacePromptVariables_NoAgentWarningsInEmbeddedPrompts(t *testing.T) {
@@ -102,8 +168,8 @@ func TestRunner_replacePromptVariables_NoAgentWarningsInEmbeddedPrompts(t *testi
 	log := newMockLogger("")
 	r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", ProgressPath: "progress.txt", DefaultBranch: "main", AppConfig: appCfg}, log: log}
 
-	r.replacePromptVariables(appCfg.ReviewFirstPrompt)
-	r.replacePromptVariables(appCfg.ReviewSecondPrompt)
+	r.buildFirstReviewPrompt(t.Context())
+	r.buildSecondReviewPrompt(t.Context())
 
 	// verify no "not found" warnings were logged
 	for _, call := range log.PrintCalls() {
@@ -143,21 +209,21 @@ func TestRunner_replacePromptVariables_CustomReviewFirstPrompt(t *testing.T) {
 
 	t.Run("with plan file", func(t *testing.T) {
 		r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", AppConfig: appCfg}}
-		prompt := r.replacePromptVariables(appCfg.ReviewFirstPrompt)
+		prompt := r.buildFirstReviewPrompt(t.Context())
 
 		assert.Equal(t, "Custom first review for implementation of plan at docs/plans/test.md", prompt)
 	})
 
 	t.Run("without plan file uses default branch", func(t *testing.T) {
 		r := &Runner{cfg: Config{PlanFile: "", DefaultBranch: "main", AppConfig: appCfg}}
-		prompt := r.replacePromptVariables(appCfg.ReviewFirstPrompt)
+		prompt := r.buildFirstReviewPrompt(t.Context())
 
 		assert.Equal(t, "Custom first review for current branch vs main", prompt)
 	})
 
 	t.Run("without plan file fallback to master", func(t *testing.T) {
 		r := &Runner{cfg: Config{PlanFile: "", AppConfig: appCfg}}
-		prompt := r.replacePromptVariables(appCfg.ReviewFirstPrompt)
+		prompt := r.buildFirstReviewPrompt(t.Context())
 
 		assert.Equal(t, "Custom first review for current branch vs master", prompt)
 	})
@@ -168,7 +234,7 @@ func TestRunner_replacePromptVariables_CustomReviewSecondPrompt(t *testing.T) {
 		ReviewSecondPrompt: "Custom second review for {{GOAL}}",
 	}
 	r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", AppConfig: appCfg}}
-	prompt := r.replacePromptVariables(appCfg.ReviewSecondPrompt)
+	prompt := r.buildSecondReviewPrompt(t.Context())
 
 	assert.Equal(t, "Custom second review for implementation of plan at docs/plans/test.md", prompt)
 }
@@ -235,6 +301,269 @@ func TestRunner_replacePromptVariables_DefaultBranch(t *testing.T) {
 	})
 }
 
+func TestRunner_replacePromptVariables_TestCommand(t *testing.T) {
+	r := &Runner{cfg: Config{TestCommand: "go test ./..."}}
+	assert.Equal(t, "run `go test ./...`", r.replacePromptVariables("run `{{TEST_COMMAND}}`"))
+
+	r = &Runner{cfg: Config{}}
+	assert.Equal(t, "run ``", r.replacePromptVariables("run `{{TEST_COMMAND}}`"))
+}
+
+func TestRunner_replacePromptVariables_CommitLog(t *testing.T) {
+	manyCommits := make([]string, maxCommitLogEntries+3)
+	for i := range manyCommits {
+		manyCommits[i] = fmt.Sprintf("abc%04d commit %d", i, i)
+	}
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		commits    []string
+		commitErr  error
+		wantCalls  int
+		wantResult string
+	}{
+		{name: "disabled leaves empty", enabled: false, commits: []string{"abc1234 add parser"}, wantCalls: 0,
+			wantResult: "log: []"},
+		{name: "enabled with commits", enabled: true, commits: []string{"def5678 fix parser", "abc1234 add parser"},
+			wantCalls: 1, wantResult: "log: [Commits on this branch since main (newest first):\n- def5678 fix parser\n- abc1234 add parser]"},
+		{name: "enabled without commits", enabled: true, commits: nil, wantCalls: 1, wantResult: "log: []"},
+		{name: "enabled with git error", enabled: true, commitErr: errors.New("boom"), wantCalls: 1, wantResult: "log: []"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			appCfg := testAppConfig(t)
+			appCfg.IncludeCommitLog = tc.enabled
+			gitMock := &mocks.GitCheckerMock{
+				CommitLogFunc: func(string) ([]string, error) { return tc.commits, tc.commitErr },
+			}
+			r := &Runner{cfg: Config{DefaultBranch: "main", AppConfig: appCfg}, git: gitMock, log: newMockLogger("")}
+			result := r.replacePromptVariables("log: [{{COMMIT_LOG}}]")
+			assert.Equal(t, tc.wantResult, result)
+			require.Len(t, gitMock.CommitLogCalls(), tc.wantCalls)
+			if tc.wantCalls > 0 {
+				assert.Equal(t, "main", gitMock.CommitLogCalls()[0].BaseBranch)
+			}
+		})
+	}
+
+	t.Run("caps number of listed commits", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.IncludeCommitLog = true
+		gitMock := &mocks.GitCheckerMock{
+			CommitLogFunc: func(string) ([]string, error) { return manyCommits, nil },
+		}
+		r := &Runner{cfg: Config{DefaultBranch: "main", AppConfig: appCfg}, git: gitMock, log: newMockLogger("")}
+		result := r.replacePromptVariables("{{COMMIT_LOG}}")
+		assert.Contains(t, result, manyCommits[maxCommitLogEntries-1])
+		assert.NotContains(t, result, manyCommits[maxCommitLogEntries])
+		assert.True(t, strings.HasSuffix(result, "- ... and 3 more"), "got %q", result)
+	})
+
+	t.Run("no git checker leaves empty", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.IncludeCommitLog = true
+		r := &Runner{cfg: Config{AppConfig: appCfg}}
+		assert.Equal(t, "log: []", r.replacePromptVariables("log: [{{COMMIT_LOG}}]"))
+	})
+}
+
+func TestRunner_replacePromptVariables_ReviewFocus(t *testing.T) {
+	focus := []config.ReviewFocus{
+		{Pattern: "migrations/**", Name: "data-safety", Prompt: "check migrations are reversible\n"},
+		{Pattern: "*.md", Name: "docs", Prompt: "check docs match the code"},
+		{Pattern: "*.sql", Name: "data-safety", Prompt: "check migrations are reversible\n"},
+	}
+	const header = "Additional review focus for the files changed on this branch (include it in each agent prompt):\n\n"
+
+	tests := []struct {
+		name       string
+		files      []string
+		filesErr   error
+		wantResult string
+	}{
+		{name: "one match", files: []string{"main.go", "README.md"}, wantResult: "focus: [" + header + "check docs match the code]"},
+		{name: "snippet added once, in config order", files: []string{"docs/api.md", "db/seed.sql", "migrations/001_init.sql"},
+			wantResult: "focus: [" + header + "check migrations are reversible\n\ncheck docs match the code]"},
+		{name: "no match leaves empty", files: []string{"main.go"}, wantResult: "focus: []"},
+		{name: "git error leaves empty", filesErr: errors.New("boom"), wantResult: "focus: []"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			appCfg := testAppConfig(t)
+			appCfg.ReviewFocus = focus
+			gitMock := &mocks.GitCheckerMock{
+				ChangedFilesFunc: func(string) ([]string, error) { return tc.files, tc.filesErr },
+			}
+			r := &Runner{cfg: Config{DefaultBranch: "main", AppConfig: appCfg}, git: gitMock, log: newMockLogger("")}
+			assert.Equal(t, tc.wantResult, r.replacePromptVariables("focus: [{{REVIEW_FOCUS}}]"))
+			require.Len(t, gitMock.ChangedFilesCalls(), 1)
+			assert.Equal(t, "main", gitMock.ChangedFilesCalls()[0].BaseBranch)
+		})
+	}
+
+	t.Run("not configured skips git", func(t *testing.T) {
+		gitMock := &mocks.GitCheckerMock{}
+		r := &Runner{cfg: Config{AppConfig: testAppConfig(t)}, git: gitMock, log: newMockLogger("")}
+		assert.Equal(t, "focus: []", r.replacePromptVariables("focus: [{{REVIEW_FOCUS}}]"))
+	})
+}
+
+func TestRunner_replacePromptVariables_CommitTrailer(t *testing.T) {
+	t.Run("configured", func(t *testing.T) {
+		r := &Runner{cfg: Config{AppConfig: testAppConfig(t), CommitTrailer: "Generated-by: ralphex v1.2.3"}}
+		assert.Equal(t, "commit. End every commit message with a blank line followed by this trailer line: Generated-by: ralphex v1.2.3",
+			r.replacePromptVariables("commit. {{COMMIT_TRAILER}}"))
+	})
+
+	t.Run("not configured leaves empty", func(t *testing.T) {
+		r := &Runner{cfg: Config{AppConfig: testAppConfig(t)}}
+		assert.Equal(t, "commit. ", r.replacePromptVariables("commit. {{COMMIT_TRAILER}}"))
+	})
+}
+
+func TestRunner_replacePromptVariables_PlanContent(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n\n### Task 1: Parser\n- [ ] add parser\n"), 0o600))
+
+	t.Run("disabled leaves empty", func(t *testing.T) {
+		r := &Runner{cfg: Config{PlanFile: planFile, AppConfig: testAppConfig(t)}, log: newMockLogger("")}
+		assert.Equal(t, "plan: []", r.replacePromptVariables("plan: [{{PLAN_CONTENT}}]"))
+	})
+
+	t.Run("enabled embeds plan text", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.EmbedPlanInPrompt = true
+		r := &Runner{cfg: Config{PlanFile: planFile, AppConfig: appCfg}, log: newMockLogger("")}
+		want := "Current content of " + planFile + " (read the file again before editing it):\n" +
+			"<plan>\n# Plan\n\n### Task 1: Parser\n- [ ] add parser\n</plan>"
+		assert.Equal(t, want, r.replacePromptVariables("{{PLAN_CONTENT}}"))
+	})
+
+	t.Run("enabled without plan file leaves empty", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.EmbedPlanInPrompt = true
+		r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
+		assert.Empty(t, r.replacePromptVariables("{{PLAN_CONTENT}}"))
+	})
+
+	t.Run("unreadable plan warns and leaves empty", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.EmbedPlanInPrompt = true
+		log := newMockLogger("")
+		r := &Runner{cfg: Config{PlanFile: filepath.Join(t.TempDir(), "missing.md"), AppConfig: appCfg}, log: log}
+		assert.Empty(t, r.replacePromptVariables("{{PLAN_CONTENT}}"))
+		require.Len(t, log.PrintCalls(), 1)
+		assert.Contains(t, log.PrintCalls()[0].Format, "failed to read plan")
+	})
+
+	t.Run("large plan is truncated with one warning", func(t *testing.T) {
+		big := filepath.Join(t.TempDir(), "big.md")
+		require.NoError(t, os.WriteFile(big, []byte(strings.Repeat("é", maxPlanContentBytes)), 0o600))
+		appCfg := testAppConfig(t)
+		appCfg.EmbedPlanInPrompt = true
+		log := newMockLogger("")
+		r := &Runner{cfg: Config{PlanFile: big, AppConfig: appCfg}, log: log}
+
+		result := r.replacePromptVariables("{{PLAN_CONTENT}}")
+		assert.Contains(t, result, "... (truncated, read "+big+" for the rest)\n</plan>")
+		assert.Less(t, len(result), maxPlanContentBytes+1024)
+		assert.True(t, utf8.ValidString(result))
+		r.replacePromptVariables("{{PLAN_CONTENT}}")
+		require.Len(t, log.PrintCalls(), 1)
+		assert.Contains(t, log.PrintCalls()[0].Format, "only the first %d are embedded")
+	})
+
+	t.Run("default task prompt embeds plan when enabled", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.EmbedPlanInPrompt = true
+		r := &Runner{cfg: Config{PlanFile: planFile, AppConfig: appCfg}, log: newMockLogger("")}
+		result := r.replacePromptVariables(appCfg.TaskPrompt)
+		assert.Contains(t, result, "<plan>\n# Plan\n")
+		assert.NotContains(t, result, "{{PLAN_CONTENT}}")
+	})
+}
+
+func TestRunner_replacePromptVariables_NextTask(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	content := "# Plan\n\n### Task 2: api\n- [ ] add api\n\n### Task 1: parser\n- [ ] add parser\n\n### Task 1.5: lexer\n- [ ] add lexer\n"
+	require.NoError(t, os.WriteFile(planFile, []byte(content), 0o600))
+
+	tests := []struct {
+		name  string
+		order string
+		want  string
+	}{
+		{name: "sequential picks first open task", order: "",
+			want: "The next task to work on is Task 2 (api). Work on this task only, do not pick another one."},
+		{name: "by-number picks lowest number", order: "by-number",
+			want: "The next task to work on is Task 1 (parser). Work on this task only, do not pick another one."},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			appCfg := testAppConfig(t)
+			appCfg.TaskOrder = tc.order
+			r := &Runner{cfg: Config{PlanFile: planFile, AppConfig: appCfg}, log: newMockLogger("")}
+			assert.Equal(t, tc.want, r.replacePromptVariables("{{NEXT_TASK}}"))
+		})
+	}
+
+	t.Run("unnumbered task is named by title", func(t *testing.T) {
+		inserted := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(inserted, []byte("### Task 1: parser\n- [x] done\n\n### Task 1.5: lexer\n- [ ] add\n"), 0o600))
+		r := &Runner{cfg: Config{PlanFile: inserted, AppConfig: testAppConfig(t)}, log: newMockLogger("")}
+		assert.Contains(t, r.replacePromptVariables("{{NEXT_TASK}}"), `the Task section titled "lexer"`)
+	})
+
+	t.Run("no plan file leaves empty", func(t *testing.T) {
+		r := &Runner{cfg: Config{AppConfig: testAppConfig(t)}, log: newMockLogger("")}
+		assert.Equal(t, "next: []", r.replacePromptVariables("next: [{{NEXT_TASK}}]"))
+	})
+
+	t.Run("all tasks done leaves empty", func(t *testing.T) {
+		done := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(done, []byte("### Task 1: parser\n- [x] done\n"), 0o600))
+		r := &Runner{cfg: Config{PlanFile: done, AppConfig: testAppConfig(t)}, log: newMockLogger("")}
+		assert.Empty(t, r.replacePromptVariables("{{NEXT_TASK}}"))
+	})
+
+	t.Run("default task prompt names the task", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		r := &Runner{cfg: Config{PlanFile: planFile, AppConfig: appCfg}, log: newMockLogger("")}
+		result := r.replacePromptVariables(appCfg.TaskPrompt)
+		assert.Contains(t, result, "The next task to work on is Task 2 (api).")
+		assert.NotContains(t, result, "{{NEXT_TASK}}")
+	})
+}
+
+func TestRunner_buildFirstReviewPrompt_CommitLog(t *testing.T) {
+	appCfg := testAppConfig(t)
+	appCfg.IncludeCommitLog = true
+	gitMock := &mocks.GitCheckerMock{
+		CommitLogFunc: func(string) ([]string, error) { return []string{"def5678 fix parser", "abc1234 add parser"}, nil },
+	}
+	r := &Runner{cfg: Config{DefaultBranch: "main", AppConfig: appCfg}, git: gitMock, log: newMockLogger("")}
+
+	prompt := r.buildFirstReviewPrompt(t.Context())
+	assert.Contains(t, prompt, "Commits on this branch since main (newest first):\n- def5678 fix parser\n- abc1234 add parser")
+	assert.NotContains(t, prompt, "{{COMMIT_LOG}}")
+}
+
+func TestRunner_buildSecondReviewPrompt_CommitLog(t *testing.T) {
+	appCfg := testAppConfig(t)
+	appCfg.IncludeCommitLog = true
+	gitMock := &mocks.GitCheckerMock{
+		CommitLogFunc: func(string) ([]string, error) { return []string{"abc1234 add parser"}, nil },
+	}
+	r := &Runner{cfg: Config{DefaultBranch: "main", AppConfig: appCfg}, git: gitMock, log: newMockLogger("")}
+
+	prompt := r.buildSecondReviewPrompt(t.Context())
+	assert.Contains(t, prompt, "Commits on this branch since main (newest first):\n- abc1234 add parser")
+	assert.NotContains(t, prompt, "{{COMMIT_LOG}}")
+}
+
 func TestRunner_getPlanFileRef(t *testing.T) {
 	t.Run("with plan file", func(t *testing.T) {
 		r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md"}}
@@ -351,7 +680,7 @@ func TestRunner_expandAgentReferences_SingleAgent(t *testing.T) {
 	r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
 
 	prompt := "Check code:\n{{agent:security-scanner}}\nDone."
-	result := r.expandAgentReferences(prompt)
+	result := r.expandAgentReferences(prompt, nil)
 
 	assert.Contains(t, result, "Use the Task tool to launch a general-purpose agent with this prompt:")
 	assert.Contains(t, result, "scan for security vulnerabilities")
@@ -369,7 +698,7 @@ func TestRunner_expandAgentReferences_MultipleAgents(t *testing.T) {
 	r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
 
 	prompt := "Run {{agent:agent-a}} then {{agent:agent-b}}."
-	result := r.expandAgentReferences(prompt)
+	result := r.expandAgentReferences(prompt, nil)
 
 	assert.Contains(t, result, "first agent prompt")
 	assert.Contains(t, result, "second agent prompt")
@@ -385,7 +714,7 @@ func TestRunner_expandAgentReferences_MissingAgent(t *testing.T) {
 	r := &Runner{cfg: Config{AppConfig: appCfg}, log: log}
 
 	prompt := "Run {{agent:missing-agent}} now."
-	result := r.expandAgentReferences(prompt)
+	result := r.expandAgentReferences(prompt, nil)
 
 	// missing agent should remain unexpanded
 	assert.Contains(t, result, "{{agent:missing-agent}}")
@@ -398,10 +727,45 @@ func TestRunner_expandAgentReferences_MissingAgent(t *testing.T) {
 	assert.Contains(t, calls[0].Format, "not found")
 }
 
+func TestRunner_expandAgentReferences_AgentModes(t *testing.T) {
+	appCfg := &config.Config{
+		CustomAgents: []config.CustomAgent{
+			{Name: "quality", Prompt: "check quality"},
+			{Name: "testing", Prompt: "check test coverage"},
+		},
+		AgentModes: config.AgentModes{"testing": {"full"}},
+	}
+	prompt := "{{agent:quality}}\n{{agent:testing}}\n"
+
+	t.Run("included in allowed mode", func(t *testing.T) {
+		r := &Runner{cfg: Config{AppConfig: appCfg, Mode: ModeFull}, log: newMockLogger("")}
+		result := r.expandAgentReferences(prompt, nil)
+		assert.Contains(t, result, "check quality")
+		assert.Contains(t, result, "check test coverage")
+	})
+
+	t.Run("dropped in other mode", func(t *testing.T) {
+		log := newMockLogger("")
+		r := &Runner{cfg: Config{AppConfig: appCfg, Mode: ModeReview}, log: log}
+		result := r.expandAgentReferences(prompt, nil)
+		assert.Contains(t, result, "check quality")
+		assert.NotContains(t, result, "check test coverage")
+		assert.NotContains(t, result, "{{agent:testing}}")
+
+		var skipped bool
+		for _, c := range log.PrintCalls() {
+			if strings.Contains(c.Format, "skipped") {
+				skipped = true
+			}
+		}
+		assert.True(t, skipped, "skip should be logged")
+	})
+}
+
 func TestRunner_expandAgentReferences_NilAppConfig(t *testing.T) {
 	r := &Runner{cfg: Config{AppConfig: nil}}
 	prompt := "Run {{agent:test}} now."
-	result := r.expandAgentReferences(prompt)
+	result := r.expandAgentReferences(prompt, nil)
 	assert.Equal(t, prompt, result)
 }
 
@@ -410,7 +774,7 @@ func TestRunner_expandAgentReferences_EmptySlice(t *testing.T) {
 	r := &Runner{cfg: Config{AppConfig: appCfg}}
 
 	prompt := "Run {{agent:test}} now."
-	result := r.expandAgentReferences(prompt)
+	result := r.expandAgentReferences(prompt, nil)
 
 	// empty agents slice, prompt unchanged
 	assert.Equal(t, prompt, result)
@@ -421,7 +785,7 @@ func TestRunner_expandAgentReferences_NilAgentsSlice(t *testing.T) {
 	r := &Runner{cfg: Config{AppConfig: appCfg}}
 
 	prompt := "Run {{agent:some-agent}} now."
-	result := r.expandAgentReferences(prompt)
+	result := r.expandAgentReferences(prompt, nil)
 
 	// nil agents slice, prompt unchanged
 	assert.Equal(t, prompt, result)
@@ -434,7 +798,7 @@ func TestRunner_expandAgentReferences_NoReferences(t *testing.T) {
 	r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
 
 	prompt := "Plain prompt without agent references."
-	result := r.expandAgentReferences(prompt)
+	result := r.expandAgentReferences(prompt, nil)
 
 	assert.Equal(t, prompt, result)
 }
@@ -462,7 +826,7 @@ func TestRunner_expandAgentReferences_DuplicateReferences(t *testing.T) {
 	r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
 
 	prompt := "First: {{agent:scanner}}\nSecond: {{agent:scanner}}"
-	result := r.expandAgentReferences(prompt)
+	result := r.expandAgentReferences(prompt, nil)
 
 	// both references should be expanded
 	assert.NotContains(t, result, "{{agent:scanner}}")
@@ -480,7 +844,7 @@ func TestRunner_expandAgentReferences_SpecialCharactersInPrompt(t *testing.T) {
 	r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
 
 	prompt := "Run {{agent:regex-agent}} now."
-	result := r.expandAgentReferences(prompt)
+	result := r.expandAgentReferences(prompt, nil)
 
 	// prompt with special characters preserves newlines and tabs
 	assert.NotContains(t, result, "{{agent:regex-agent}}")
@@ -501,7 +865,7 @@ func TestRunner_expandAgentReferences_ExpandsVariablesInContent(t *testing.T) {
 		r := &Runner{cfg: Config{PlanFile: "docs/plan.md", DefaultBranch: "main", AppConfig: appCfg}, log: newMockLogger("")}
 
 		prompt := "Run {{agent:review}}"
-		result := r.expandAgentReferences(prompt)
+		result := r.expandAgentReferences(prompt, nil)
 
 		assert.Contains(t, result, "review changes on main")
 		assert.Contains(t, result, "plan: docs/plan.md")
@@ -520,7 +884,7 @@ func TestRunner_expandAgentReferences_ExpandsVariablesInContent(t *testing.T) {
 		r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
 
 		prompt := "Run {{agent:review}}"
-		result := r.expandAgentReferences(prompt)
+		result := r.expandAgentReferences(prompt, nil)
 
 		assert.Contains(t, result, "diff master..HEAD")
 	})
@@ -534,7 +898,7 @@ func TestRunner_expandAgentReferences_CaseSensitivity(t *testing.T) {
 	t.Run("lowercase reference does not match uppercase agent", func(t *testing.T) {
 		r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
 		prompt := "Run {{agent:scanner}} now."
-		result := r.expandAgentReferences(prompt)
+		result := r.expandAgentReferences(prompt, nil)
 
 		assert.Contains(t, result, "{{agent:scanner}}")
 		assert.NotContains(t, result, "uppercase name")
@@ -543,7 +907,7 @@ func TestRunner_expandAgentReferences_CaseSensitivity(t *testing.T) {
 	t.Run("exact case matches", func(t *testing.T) {
 		r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
 		prompt := "Run {{agent:Scanner}} now."
-		result := r.expandAgentReferences(prompt)
+		result := r.expandAgentReferences(prompt, nil)
 
 		assert.NotContains(t, result, "{{agent:Scanner}}")
 		assert.Contains(t, result, "uppercase name")
@@ -559,7 +923,7 @@ func TestRunner_expandAgentReferences_WithModelAndAgentType(t *testing.T) {
 		}
 		r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
 
-		result := r.expandAgentReferences("Launch {{agent:docs}}")
+		result := r.expandAgentReferences("Launch {{agent:docs}}", nil)
 		assert.Contains(t, result, "model=haiku")
 		assert.Contains(t, result, "code-reviewer")
 		assert.Contains(t, result, "Check docs.")
@@ -574,7 +938,7 @@ func TestRunner_expandAgentReferences_WithModelAndAgentType(t *testing.T) {
 		}
 		r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
 
-		result := r.expandAgentReferences("Run {{agent:lint}}")
+		result := r.expandAgentReferences("Run {{agent:lint}}", nil)
 		assert.Contains(t, result, "model=sonnet")
 		assert.Contains(t, result, "general-purpose")
 		assert.Contains(t, result, "Lint code.")
@@ -588,7 +952,7 @@ func TestRunner_expandAgentReferences_WithModelAndAgentType(t *testing.T) {
 		}
 		r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
 
-		result := r.expandAgentReferences("Run {{agent:review}}")
+		result := r.expandAgentReferences("Run {{agent:review}}", nil)
 		assert.NotContains(t, result, "model=")
 		assert.Contains(t, result, "code-reviewer")
 		assert.Contains(t, result, "Review code.")
@@ -602,7 +966,7 @@ func TestRunner_expandAgentReferences_WithModelAndAgentType(t *testing.T) {
 		}
 		r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
 
-		result := r.expandAgentReferences("Run {{agent:basic}}")
+		result := r.expandAgentReferences("Run {{agent:basic}}", nil)
 		assert.NotContains(t, result, "model=")
 		assert.Contains(t, result, "general-purpose")
 		assert.Contains(t, result, "Basic check.")
@@ -618,7 +982,7 @@ func TestRunner_expandAgentReferences_PercentInPrompt(t *testing.T) {
 	r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
 
 	prompt := "Run {{agent:perf}} now."
-	result := r.expandAgentReferences(prompt)
+	result := r.expandAgentReferences(prompt, nil)
 
 	assert.Contains(t, result, "80%")
 	assert.Contains(t, result, "90%")
@@ -723,6 +1087,31 @@ func TestRunner_getDiffInstruction(t *testing.T) {
 		result := r.getDiffInstruction(true)
 		assert.Equal(t, "git diff master...HEAD", result)
 	})
+
+	t.Run("plan file and progress dir excluded from first diff", func(t *testing.T) {
+		r := &Runner{cfg: Config{DefaultBranch: "main", PlanFile: "docs/plans/feature.md",
+			ProgressPath: ".ralphex/progress/progress-feature.txt", AppConfig: &config.Config{}}}
+		assert.Equal(t, "git diff main...HEAD -- ':/' ':(exclude)docs/plans/feature.md' ':(exclude).ralphex/progress'",
+			r.getDiffInstruction(true))
+		assert.Equal(t, "git diff", r.getDiffInstruction(false))
+	})
+
+	t.Run("review_include_plan keeps plan in diff", func(t *testing.T) {
+		r := &Runner{cfg: Config{DefaultBranch: "main", PlanFile: "docs/plans/feature.md",
+			ProgressPath: ".ralphex/progress/progress-feature.txt", AppConfig: &config.Config{ReviewIncludePlan: true}}}
+		assert.Equal(t, "git diff main...HEAD", r.getDiffInstruction(true))
+	})
+
+	t.Run("quotes excluded paths for the shell", func(t *testing.T) {
+		r := &Runner{cfg: Config{DefaultBranch: "main", PlanFile: "docs/plans/it's.md"}}
+		assert.Equal(t, `git diff main...HEAD -- ':/' ':(exclude)docs/plans/it'\''s.md'`, r.getDiffInstruction(true))
+	})
+
+	t.Run("working tree review diffs against HEAD", func(t *testing.T) {
+		r := &Runner{cfg: Config{DefaultBranch: "main", AppConfig: &config.Config{ReviewWorkingTree: true}}}
+		assert.Equal(t, "git diff HEAD", r.getDiffInstruction(true))
+		assert.Equal(t, "git diff", r.getDiffInstruction(false))
+	})
 }
 
 func TestRunner_replaceVariablesWithIteration(t *testing.T) {
@@ -6,11 +6,17 @@ import (
 	"errors"
 	"fmt"
 	"os/exec"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/umputun/ralphex/pkg/config"
 	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/hooks"
 	"github.com/umputun/ralphex/pkg/plan"
 	"github.com/umputun/ralphex/pkg/status"
 )
@@ -26,17 +32,83 @@ const (
 	minPlanIterations      = 5    // minimum plan creation iterations
 	planIterationDivisor   = 5    // plan iterations = max_iterations / divisor
 	maxCodexSummaryLen     = 5000 // max chars for codex output summary
+	maxBlameHints          = 10   // max file:line references annotated with blame per codex round
 )
 
+// ReviewIterationLimit returns the number of iterations a claude review loop may take for max_iterations,
+// also used for the review rounds of each task with review_per_task.
+func ReviewIterationLimit(maxIterations int) int {
+	return max(minReviewIterations, maxIterations/reviewIterationDivisor)
+}
+
+// ExternalIterationLimit returns the number of iterations the external review loop may take for max_iterations.
+// a positive override (max_external_iterations) replaces the derived limit.
+func ExternalIterationLimit(maxIterations, override int) int {
+	if override > 0 {
+		return override
+	}
+	return max(minCodexIterations, maxIterations/codexIterationDivisor)
+}
+
+// fileLineRefPattern matches file:line references in review output, e.g. "pkg/git/service.go:42"
+var fileLineRefPattern = regexp.MustCompile(`([\w./-]+\.[A-Za-z0-9]+):(\d+)`)
+
 // Mode represents the execution mode.
 type Mode string
 
 const (
-	ModeFull      Mode = "full"       // full execution: tasks + reviews + codex
-	ModeReview    Mode = "review"     // skip tasks, run full review pipeline
-	ModeCodexOnly Mode = "codex-only" // skip tasks and first review, run only codex loop
-	ModeTasksOnly Mode = "tasks-only" // run only task phase, skip all reviews
-	ModePlan      Mode = "plan"       // interactive plan creation mode
+	ModeFull         Mode = "full"          // full execution: tasks + reviews + codex
+	ModeReview       Mode = "review"        // skip tasks, run full review pipeline
+	ModeExternalOnly Mode = "external-only" // skip tasks and first review, run only the external review loop
+	ModeTasksOnly    Mode = "tasks-only"    // run only task phase, skip all reviews
+	ModePlan         Mode = "plan"          // interactive plan creation mode
+
+	// ModeCodexOnly is the old name of ModeExternalOnly.
+	//
+	// Deprecated: use ModeExternalOnly.
+	ModeCodexOnly = ModeExternalOnly
+)
+
+// AllModes lists the execution modes, run modes first and plan creation last.
+var AllModes = []Mode{ModeFull, ModeReview, ModeExternalOnly, ModeTasksOnly, ModePlan}
+
+// Description returns a one-line description of the mode, empty for an unknown mode.
+func (m Mode) Description() string {
+	switch m {
+	case ModeFull:
+		return "execute plan tasks, then claude review, external review loop and finalize"
+	case ModeReview:
+		return "skip tasks, run the full review pipeline on the branch"
+	case ModeExternalOnly:
+		return "skip tasks and the first claude review, run only the external review loop"
+	case ModeTasksOnly:
+		return "execute plan tasks only, skip all reviews"
+	case ModePlan:
+		return "create a plan interactively from a description"
+	default:
+		return ""
+	}
+}
+
+// StartPhase selects where the full mode pipeline begins.
+type StartPhase string
+
+const (
+	StartTasks    StartPhase = "tasks"    // run the whole pipeline (default)
+	StartReview   StartPhase = "review"   // skip task execution, begin with the first claude review
+	StartCodex    StartPhase = "codex"    // skip tasks and pre-codex reviews, begin with external review
+	StartFinalize StartPhase = "finalize" // run only the finalize step
+)
+
+// Phase identifies a single executor run that can be invoked on its own with RunPhase.
+type Phase string
+
+const (
+	PhaseTask           Phase = "task"            // one task iteration with the task prompt
+	PhaseFirstReview    Phase = "first-review"    // first claude review pass, all findings
+	PhaseSecondReview   Phase = "second-review"   // one claude review iteration, critical/major findings
+	PhaseExternalReview Phase = "external-review" // one run of the external review tool (codex or custom)
+	PhaseFinalize       Phase = "finalize"        // finalize step with the finalize prompt
 )
 
 // Config holds runner configuration.
@@ -48,13 +120,24 @@ type Config struct {
 	MaxIterations         int            // maximum iterations for task phase
 	MaxExternalIterations int            // override external review iteration limit (0 = auto)
 	ReviewPatience        int            // terminate external review after N unchanged rounds (0 = disabled)
+	MaxDiffLines          int            // refuse review phases on a diff with more added+deleted lines (0 = no limit)
+	InteractiveGates      bool           // ask before each phase after the first, declining stops the run
 	Debug                 bool           // enable debug output
+	StreamRaw             bool           // show codex output unfiltered, for debugging the output filter
 	NoColor               bool           // disable color output
 	IterationDelayMs      int            // delay between iterations in milliseconds
 	TaskRetryCount        int            // number of times to retry failed tasks
+	ReviewRetryCount      int            // number of times to retry a claude review iteration that failed (0 = fail at once)
+	EmptyIterationLimit   int            // fail task phase after N consecutive iterations without output (0 = disabled)
+	FailOnP1              bool           // abort the run when codex reports a P0/P1 finding, skipping claude evaluation
 	CodexEnabled          bool           // whether codex review is enabled
 	FinalizeEnabled       bool           // whether finalize step is enabled
 	DefaultBranch         string         // default branch name (detected from repo)
+	CodexExtraConfig      []string       // extra codex -c key=value overrides (from --codex-config)
+	TestCommand           string         // test command from config or detected from repo markers
+	CommitTrailer         string         // commit_trailer with placeholders resolved, claude is asked to add it to its commits
+	ClaudeModel           string         // claude model passed as --model, empty = model from claude_args
+	StartPhase            StartPhase     // full mode entry point, empty = StartTasks
 	AppConfig             *config.Config // full application config (for executors and prompts)
 }
 
@@ -77,19 +160,26 @@ type Logger interface {
 	LogQuestion(question string, options []string)
 	LogAnswer(answer string)
 	LogDraftReview(action string, feedback string)
+	StartTask(task int)
 	Path() string
 }
 
-// InputCollector provides interactive input collection for plan creation.
+// InputCollector provides interactive input collection for plan creation and NEEDS_INPUT questions.
 type InputCollector interface {
 	AskQuestion(ctx context.Context, question string, options []string) (string, error)
 	AskDraftReview(ctx context.Context, question string, planContent string) (action string, feedback string, err error)
 }
 
-// GitChecker provides git state inspection for the review loop.
+// GitChecker provides git state inspection for the review loop and prompt variables.
 type GitChecker interface {
 	HeadHash() (string, error)
 	DiffFingerprint() (string, error)
+	CommitLog(baseBranch string) ([]string, error)
+	ChangedFiles(baseBranch string) ([]string, error)
+	DiffStats(baseBranch string, exclude ...string) (git.DiffStats, error)
+	DiffStatsRange(from, to string, exclude ...string) (git.DiffStats, error)
+	DiffStatsPerFile(baseBranch string, exclude ...string) ([]git.FileDiffStat, error)
+	BlameLine(file string, line int) (author, commit string, err error)
 }
 
 // Executors groups the executor dependencies for the Runner.
@@ -97,6 +187,10 @@ type Executors struct {
 	Claude Executor
 	Codex  Executor
 	Custom *executor.CustomExecutor
+
+	// codex executors for codex_reviewers with their own project_doc, keyed by reviewer name.
+	// reviewers without an entry use Codex.
+	CodexByReviewer map[string]Executor
 }
 
 // Runner orchestrates the execution loop.
@@ -105,15 +199,29 @@ type Runner struct {
 	log                 Logger
 	claude              Executor
 	codex               Executor
+	codexByReviewer     map[string]Executor // codex with a reviewer's project_doc, keyed by reviewer name
 	custom              *executor.CustomExecutor
 	git                 GitChecker
 	inputCollector      InputCollector
+	hooks               *hooks.Runner
+	cmdRunner           executor.CommandRunner // runs command agents and the test command, nil uses the default
 	phaseHolder         *status.PhaseHolder
 	iterationDelay      time.Duration
 	taskRetryCount      int
 	waitOnLimit         time.Duration
-	breakCh             <-chan struct{} // nil = feature disabled; close to break external review loop
-	lastSessionTimedOut bool            // set by runWithSessionTimeout, checked by review loops
+	maxLimitRetries     int
+	heartbeatInterval   time.Duration                                   // "still working" log interval during executor calls, 0 = disabled
+	breakCh             <-chan struct{}                                 // nil = feature disabled; close to break external review loop
+	confirmFn           func(ctx context.Context, question string) bool // asks the user, nil = not interactive
+	lastSessionTimedOut bool                                            // set by runWithSessionTimeout, checked by review loops
+	retryPending        bool                                            // next recorded iteration repeats a previous one
+	userAnswer          string                                          // answered NEEDS_INPUT question, added to the next task prompt
+	planContentWarned   bool                                            // {{PLAN_CONTENT}} truncation warning already logged
+	gateArmed           bool                                            // first phase started, later phases go through the gate
+	externalRounds      int                                             // external review rounds run so far, checked against max_external_rounds
+	iterations          []IterationRecord
+	taskReviews         []taskReview // outcome of each task review, with review_per_task
+	planTasks           []string     // task labels from the last plan parse, to detect plan edits
 }
 
 // New creates a new Runner with the given configuration and shared phase holder.
@@ -131,14 +239,19 @@ func New(cfg Config, log Logger, holder *status.PhaseHolder) *Runner {
 		claudeExec.Args = cfg.AppConfig.ClaudeArgs
 		claudeExec.ErrorPatterns = cfg.AppConfig.ClaudeErrorPatterns
 		claudeExec.LimitPatterns = cfg.AppConfig.ClaudeLimitPatterns
+		claudeExec.OutputFilter = cfg.AppConfig.ClaudeOutputFilter
+		claudeExec.StripANSI = cfg.AppConfig.StripOutputANSI
+		claudeExec.IdleTimeout = cfg.AppConfig.ClaudeIdleTimeout
 	}
+	claudeExec.Model = cfg.ClaudeModel
 
 	// build codex executor with config values
 	codexExec := &executor.CodexExecutor{
 		OutputHandler: func(text string) {
 			log.PrintAligned(text)
 		},
-		Debug: cfg.Debug,
+		Debug:     cfg.Debug,
+		StreamRaw: cfg.StreamRaw,
 	}
 	if cfg.AppConfig != nil {
 		codexExec.Command = cfg.AppConfig.CodexCommand
@@ -148,7 +261,9 @@ func New(cfg Config, log Logger, holder *status.PhaseHolder) *Runner {
 		codexExec.Sandbox = cfg.AppConfig.CodexSandbox
 		codexExec.ErrorPatterns = cfg.AppConfig.CodexErrorPatterns
 		codexExec.LimitPatterns = cfg.AppConfig.CodexLimitPatterns
+		codexExec.StripANSI = cfg.AppConfig.StripOutputANSI
 	}
+	codexExec.ExtraConfig = cfg.CodexExtraConfig
 
 	// build custom executor if custom review script is configured
 	var customExec *executor.CustomExecutor
@@ -160,6 +275,7 @@ func New(cfg Config, log Logger, holder *status.PhaseHolder) *Runner {
 			},
 			ErrorPatterns: cfg.AppConfig.CodexErrorPatterns, // reuse codex error patterns
 			LimitPatterns: cfg.AppConfig.CodexLimitPatterns, // reuse codex limit patterns
+			StripANSI:     cfg.AppConfig.StripOutputANSI,
 		}
 	}
 
@@ -176,7 +292,24 @@ func New(cfg Config, log Logger, holder *status.PhaseHolder) *Runner {
 		}
 	}
 
-	return NewWithExecutors(cfg, log, Executors{Claude: claudeExec, Codex: codexExec, Custom: customExec}, holder)
+	// reviewers with their own instructions get a codex executor with that project_doc
+	var codexByReviewer map[string]Executor
+	if cfg.AppConfig != nil {
+		for _, rv := range cfg.AppConfig.CodexReviewers {
+			if rv.ProjectDoc == "" {
+				continue
+			}
+			if codexByReviewer == nil {
+				codexByReviewer = make(map[string]Executor)
+			}
+			rvExec := *codexExec
+			rvExec.ProjectDoc = rv.ProjectDoc
+			codexByReviewer[rv.Name] = &rvExec
+		}
+	}
+
+	return NewWithExecutors(cfg, log, Executors{Claude: claudeExec, Codex: codexExec, Custom: customExec,
+		CodexByReviewer: codexByReviewer}, holder)
 }
 
 // NewWithExecutors creates a new Runner with custom executors (for testing).
@@ -197,25 +330,32 @@ func NewWithExecutors(cfg Config, log Logger, execs Executors, holder *status.Ph
 	}
 
 	// determine wait-on-limit duration from config
-	var waitOnLimit time.Duration
+	var waitOnLimit, heartbeatInterval time.Duration
+	var maxLimitRetries int
 	if cfg.AppConfig != nil {
 		waitOnLimit = cfg.AppConfig.WaitOnLimit
+		maxLimitRetries = cfg.AppConfig.MaxLimitRetries
+		heartbeatInterval = cfg.AppConfig.HeartbeatInterval
 	}
 
 	return &Runner{
-		cfg:            cfg,
-		log:            log,
-		claude:         execs.Claude,
-		codex:          execs.Codex,
-		custom:         execs.Custom,
-		phaseHolder:    holder,
-		iterationDelay: iterDelay,
-		taskRetryCount: retryCount,
-		waitOnLimit:    waitOnLimit,
+		cfg:               cfg,
+		log:               log,
+		claude:            execs.Claude,
+		codex:             execs.Codex,
+		codexByReviewer:   execs.CodexByReviewer,
+		custom:            execs.Custom,
+		phaseHolder:       holder,
+		iterationDelay:    iterDelay,
+		taskRetryCount:    retryCount,
+		waitOnLimit:       waitOnLimit,
+		maxLimitRetries:   maxLimitRetries,
+		heartbeatInterval: heartbeatInterval,
 	}
 }
 
-// SetInputCollector sets the input collector for plan creation mode.
+// SetInputCollector sets the input collector for plan creation mode and for NEEDS_INPUT questions
+// during task execution. without it a NEEDS_INPUT signal fails the run.
 func (r *Runner) SetInputCollector(c InputCollector) {
 	r.inputCollector = c
 }
@@ -225,6 +365,18 @@ func (r *Runner) SetGitChecker(g GitChecker) {
 	r.git = g
 }
 
+// SetHooks sets the hooks directory runner for lifecycle scripts around pipeline stages.
+func (r *Runner) SetHooks(h *hooks.Runner) {
+	r.hooks = h
+}
+
+// SetConfirm sets the function asking the user a yes/no question, used to confirm reviewing
+// a diff over MaxDiffLines and for the InteractiveGates phase gates. without it the diff check aborts
+// the run and the gates are disabled.
+func (r *Runner) SetConfirm(fn func(ctx context.Context, question string) bool) {
+	r.confirmFn = fn
+}
+
 // SetBreakCh sets the break channel for manual termination of the external review loop.
 // closing the channel causes the current executor run to be canceled and the loop to exit.
 func (r *Runner) SetBreakCh(ch <-chan struct{}) {
@@ -238,8 +390,8 @@ func (r *Runner) Run(ctx context.Context) error {
 		return r.runFull(ctx)
 	case ModeReview:
 		return r.runReviewOnly(ctx)
-	case ModeCodexOnly:
-		return r.runCodexOnly(ctx)
+	case ModeExternalOnly:
+		return r.runExternalOnly(ctx)
 	case ModeTasksOnly:
 		return r.runTasksOnly(ctx)
 	case ModePlan:
@@ -249,31 +401,108 @@ func (r *Runner) Run(ctx context.Context) error {
 	}
 }
 
+// RunPhase runs a single phase once and returns the executor result, including the detected signal.
+// it builds the phase prompt, sets the matching status phase and runs the executor with the same
+// rate limit and session timeout handling as Run, but without any looping or iteration limits.
+// allows embedding callers to compose phases into their own orchestration.
+func (r *Runner) RunPhase(ctx context.Context, phase Phase) (executor.Result, error) {
+	if r.cfg.AppConfig == nil {
+		return executor.Result{}, errors.New("app config required to build prompts")
+	}
+
+	tool, run := "claude", r.claude.Run
+	var prompt string
+	switch phase {
+	case PhaseTask:
+		r.phaseHolder.Set(status.PhaseTask)
+		prompt = r.replacePromptVariables(r.cfg.AppConfig.TaskPrompt)
+	case PhaseFirstReview:
+		r.phaseHolder.Set(status.PhaseReview)
+		prompt = r.buildFirstReviewPrompt(ctx)
+	case PhaseSecondReview:
+		r.phaseHolder.Set(status.PhaseReview)
+		prompt = r.buildSecondReviewPrompt(ctx)
+	case PhaseExternalReview:
+		r.phaseHolder.Set(status.PhaseCodex)
+		switch tool = r.externalReviewTool(); tool {
+		case "none":
+			return executor.Result{}, errors.New("external review disabled")
+		case "custom":
+			if r.custom == nil {
+				return executor.Result{}, errors.New("custom review script not configured")
+			}
+			run = r.custom.Run
+			prompt = r.buildCustomReviewPrompt(true, "")
+		default:
+			run = r.codex.Run
+			prompt = r.buildCodexPrompt(true, "")
+		}
+	case PhaseFinalize:
+		r.phaseHolder.Set(status.PhaseFinalize)
+		prompt = r.replacePromptVariables(r.cfg.AppConfig.FinalizePrompt)
+	default:
+		return executor.Result{}, fmt.Errorf("unknown phase: %s", phase)
+	}
+
+	result := r.runWithLimitRetry(ctx, run, prompt, tool)
+	if result.Error != nil {
+		if err := r.handlePatternMatchError(result.Error, tool); err != nil {
+			return result, err
+		}
+		return result, fmt.Errorf("%s execution: %w", tool, result.Error)
+	}
+	return result, nil
+}
+
 // runFull executes the complete pipeline: tasks → review → codex → review.
+// cfg.StartPhase skips the phases before the given one.
 func (r *Runner) runFull(ctx context.Context) error {
 	if r.cfg.PlanFile == "" {
 		return errors.New("plan file required for full mode")
 	}
 
-	// phase 1: task execution
-	r.phaseHolder.Set(status.PhaseTask)
-	r.log.PrintRaw("starting task execution phase\n")
+	start := r.cfg.StartPhase
+	switch start {
+	case "", StartTasks:
+		start = StartTasks
+	case StartReview, StartCodex, StartFinalize:
+		r.log.Print("starting at %s phase, earlier phases skipped", start)
+	default:
+		return fmt.Errorf("unknown start phase: %s", start)
+	}
 
-	if err := r.runTaskPhase(ctx); err != nil {
-		return fmt.Errorf("task phase: %w", err)
+	if start == StartFinalize {
+		return r.runFinalize(ctx)
 	}
 
-	// phase 2: first review pass - address ALL findings
-	r.phaseHolder.Set(status.PhaseReview)
-	r.log.PrintSection(status.NewGenericSection("claude review 0: all findings"))
+	if start == StartTasks {
+		// phase 1: task execution
+		if err := r.phaseGate(ctx, "task execution"); err != nil {
+			return err
+		}
+		r.phaseHolder.Set(status.PhaseTask)
+		r.log.PrintRaw("starting task execution phase\n")
 
-	if err := r.runClaudeReview(ctx, r.replacePromptVariables(r.cfg.AppConfig.ReviewFirstPrompt)); err != nil {
-		return fmt.Errorf("first review: %w", err)
+		if err := r.withHooks(ctx, hooks.PreTask, hooks.PostTask, r.runTaskPhase); err != nil {
+			return fmt.Errorf("task phase: %w", err)
+		}
 	}
 
-	// phase 2.1: claude review loop (critical/major) before codex
-	if err := r.runClaudeReviewLoop(ctx); err != nil {
-		return fmt.Errorf("pre-codex review loop: %w", err)
+	if err := r.checkDiffSize(ctx); err != nil {
+		return err
+	}
+
+	if start == StartTasks && r.reviewPerTask() && r.cfg.AppConfig.SkipFinalReview {
+		r.log.Print("tasks were reviewed individually, whole-branch claude review skipped")
+	} else if start == StartTasks || start == StartReview {
+		// phase 2: first review pass and claude review loop before codex
+		if err := r.phaseGate(ctx, "claude review"); err != nil {
+			return err
+		}
+		r.phaseHolder.Set(status.PhaseReview)
+		if err := r.withHooks(ctx, hooks.PreReview, hooks.PostReview, r.runFirstReviewStage); err != nil {
+			return err
+		}
 	}
 
 	// phase 2.5+3: codex → post-codex review → finalize
@@ -287,17 +516,17 @@ func (r *Runner) runFull(ctx context.Context) error {
 
 // runReviewOnly executes only the review pipeline: review → codex → review.
 func (r *Runner) runReviewOnly(ctx context.Context) error {
-	// phase 1: first review
-	r.phaseHolder.Set(status.PhaseReview)
-	r.log.PrintSection(status.NewGenericSection("claude review 0: all findings"))
-
-	if err := r.runClaudeReview(ctx, r.replacePromptVariables(r.cfg.AppConfig.ReviewFirstPrompt)); err != nil {
-		return fmt.Errorf("first review: %w", err)
+	if err := r.checkDiffSize(ctx); err != nil {
+		return err
 	}
 
-	// phase 1.1: claude review loop (critical/major) before codex
-	if err := r.runClaudeReviewLoop(ctx); err != nil {
-		return fmt.Errorf("pre-codex review loop: %w", err)
+	// phase 1: first review pass and claude review loop before codex
+	if err := r.phaseGate(ctx, "claude review"); err != nil {
+		return err
+	}
+	r.phaseHolder.Set(status.PhaseReview)
+	if err := r.withHooks(ctx, hooks.PreReview, hooks.PostReview, r.runFirstReviewStage); err != nil {
+		return err
 	}
 
 	// phase 2+3: codex → post-codex review → finalize
@@ -309,8 +538,26 @@ func (r *Runner) runReviewOnly(ctx context.Context) error {
 	return nil
 }
 
-// runCodexOnly executes only the codex pipeline: codex → review → finalize.
-func (r *Runner) runCodexOnly(ctx context.Context) error {
+// runFirstReviewStage runs the first claude review pass (all findings) followed by
+// the critical/major review loop that precedes external review.
+func (r *Runner) runFirstReviewStage(ctx context.Context) error {
+	r.log.PrintSection(status.NewGenericSection("claude review 0: all findings"))
+
+	if err := r.runClaudeReview(ctx); err != nil {
+		return fmt.Errorf("first review: %w", err)
+	}
+
+	if err := r.runClaudeReviewLoop(ctx); err != nil {
+		return fmt.Errorf("pre-codex review loop: %w", err)
+	}
+	return nil
+}
+
+// runExternalOnly executes only the external review pipeline: external review → review → finalize.
+func (r *Runner) runExternalOnly(ctx context.Context) error {
+	if err := r.checkDiffSize(ctx); err != nil {
+		return err
+	}
 	if err := r.runCodexAndPostReview(ctx); err != nil {
 		return err
 	}
@@ -320,13 +567,24 @@ func (r *Runner) runCodexOnly(ctx context.Context) error {
 }
 
 // runCodexAndPostReview runs the shared codex → post-codex claude review → finalize pipeline.
-// used by runFull, runReviewOnly, and runCodexOnly to avoid duplicating this sequence.
+// used by runFull, runReviewOnly, and runExternalOnly to avoid duplicating this sequence.
 func (r *Runner) runCodexAndPostReview(ctx context.Context) error {
 	// codex external review loop
+	if r.externalReviewTool() != "none" {
+		if err := r.phaseGate(ctx, "external review"); err != nil {
+			return err
+		}
+	}
 	r.phaseHolder.Set(status.PhaseCodex)
 	r.log.PrintSection(status.NewGenericSection("codex external review"))
 
-	if err := r.runCodexLoop(ctx); err != nil {
+	runCodex := r.runCodexLoop
+	if r.externalReviewTool() != "none" {
+		runCodex = func(ctx context.Context) error {
+			return r.withHooks(ctx, hooks.PreCodex, hooks.PostCodex, r.runCodexLoop)
+		}
+	}
+	if err := runCodex(ctx); err != nil {
 		return fmt.Errorf("codex loop: %w", err)
 	}
 
@@ -344,7 +602,8 @@ func (r *Runner) runCodexAndPostReview(ctx context.Context) error {
 			"`fix: address code review findings`\n" +
 			"Then continue with the sequence below.\n\n"
 	}
-	if err := r.runClaudeReviewLoop(ctx, commitPrefix); err != nil {
+	postCodexReview := func(ctx context.Context) error { return r.runClaudeReviewLoop(ctx, commitPrefix) }
+	if err := r.withHooks(ctx, hooks.PreReview, hooks.PostReview, postCodexReview); err != nil {
 		return fmt.Errorf("post-codex review loop: %w", err)
 	}
 
@@ -361,7 +620,7 @@ func (r *Runner) runTasksOnly(ctx context.Context) error {
 	r.phaseHolder.Set(status.PhaseTask)
 	r.log.PrintRaw("starting task execution phase\n")
 
-	if err := r.runTaskPhase(ctx); err != nil {
+	if err := r.withHooks(ctx, hooks.PreTask, hooks.PostTask, r.runTaskPhase); err != nil {
 		return fmt.Errorf("task phase: %w", err)
 	}
 
@@ -372,8 +631,8 @@ func (r *Runner) runTasksOnly(ctx context.Context) error {
 // runTaskPhase executes tasks until completion or max iterations.
 // executes ONE Task section per iteration.
 func (r *Runner) runTaskPhase(ctx context.Context) error {
-	prompt := r.replacePromptVariables(r.cfg.AppConfig.TaskPrompt)
 	retryCount := 0
+	emptyCount := 0
 
 	for i := 1; i <= r.cfg.MaxIterations; i++ {
 		select {
@@ -387,8 +646,17 @@ func (r *Runner) runTaskPhase(ctx context.Context) error {
 		if pos := r.nextPlanTaskPosition(); pos > 0 {
 			taskNum = pos
 		}
+		r.log.StartTask(taskNum)
 		r.log.PrintSection(status.NewTaskIterationSection(taskNum))
 
+		// built per iteration, {{NEXT_TASK}} names the task picked from the current plan state
+		prompt := r.withUserAnswer(r.replacePromptVariables(r.cfg.AppConfig.TaskPrompt))
+
+		headBefore := ""
+		if r.reviewPerTask() {
+			headBefore = r.headHash()
+		}
+
 		result := r.runWithLimitRetry(ctx, r.claude.Run, prompt, "claude")
 		if result.Error != nil {
 			if err := r.handlePatternMatchError(result.Error, "claude"); err != nil {
@@ -397,12 +665,29 @@ func (r *Runner) runTaskPhase(ctx context.Context) error {
 			return fmt.Errorf("claude execution: %w", result.Error)
 		}
 
+		// claude stopped to ask for a decision, the answer goes into the next iteration's prompt
+		asked, err := r.handleNeedsInput(ctx, result.Output)
+		if err != nil {
+			return err
+		}
+		if asked {
+			continue
+		}
+
+		// review the task's commits before the next task builds on them
+		if r.reviewPerTask() && result.Signal != SignalFailed {
+			if err := r.runTaskReview(ctx, taskNum, headBefore); err != nil {
+				return err
+			}
+		}
+
 		if result.Signal == SignalCompleted {
 			// verify plan actually has no uncompleted checkboxes
 			if r.hasUncompletedTasks() {
 				r.log.Print("warning: completion signal received but plan still has [ ] items, continuing...")
 				continue
 			}
+			r.printTaskReviewSummary()
 			r.log.PrintRaw("\nall tasks completed, starting code review...\n")
 			return nil
 		}
@@ -410,6 +695,7 @@ func (r *Runner) runTaskPhase(ctx context.Context) error {
 		if result.Signal == SignalFailed {
 			if retryCount < r.taskRetryCount {
 				r.log.Print("task failed, retrying...")
+				r.retryPending = true
 				retryCount++
 				if err := r.sleepWithContext(ctx, r.iterationDelay); err != nil {
 					return fmt.Errorf("interrupted: %w", err)
@@ -419,6 +705,18 @@ func (r *Runner) runTaskPhase(ctx context.Context) error {
 			return errors.New("task execution failed after retry (FAILED signal received)")
 		}
 
+		// an empty result without a signal means claude did nothing; a killed session is not counted
+		if r.cfg.EmptyIterationLimit > 0 && result.Signal == "" && strings.TrimSpace(result.Output) == "" &&
+			!r.lastSessionTimedOut {
+			emptyCount++
+			r.log.Print("warning: claude produced no output (%d/%d)", emptyCount, r.cfg.EmptyIterationLimit)
+			if emptyCount >= r.cfg.EmptyIterationLimit {
+				return fmt.Errorf("claude produced no output in %d consecutive iterations", emptyCount)
+			}
+		} else {
+			emptyCount = 0
+		}
+
 		retryCount = 0
 		// continue with same prompt - it reads from plan file each time
 		if err := r.sleepWithContext(ctx, r.iterationDelay); err != nil {
@@ -429,24 +727,78 @@ func (r *Runner) runTaskPhase(ctx context.Context) error {
 	return fmt.Errorf("max iterations (%d) reached without completion", r.cfg.MaxIterations)
 }
 
-// runClaudeReview runs Claude review with the given prompt until REVIEW_DONE.
-func (r *Runner) runClaudeReview(ctx context.Context, prompt string) error {
-	result := r.runWithLimitRetry(ctx, r.claude.Run, prompt, "claude")
-	if result.Error != nil {
-		if err := r.handlePatternMatchError(result.Error, "claude"); err != nil {
-			return err
+// handleNeedsInput processes a NEEDS_INPUT signal in task output by asking the user the question.
+// the answer is kept for the next task prompt. returns true if a question was found and answered.
+// without an input collector (no terminal, or --yes) the run fails with the question.
+func (r *Runner) handleNeedsInput(ctx context.Context, output string) (bool, error) {
+	question, err := parseNeedsInputPayload(output)
+	if err != nil {
+		// log malformed signals (but not "no signal" which is expected)
+		if !errors.Is(err, errNoNeedsInputSignal) {
+			r.log.Print("warning: %v", err)
 		}
-		return fmt.Errorf("claude execution: %w", result.Error)
+		return false, nil
 	}
 
-	if result.Signal == SignalFailed {
-		return errors.New("review failed (FAILED signal received)")
+	r.log.LogQuestion(question.Question, question.Options)
+	if r.inputCollector == nil {
+		return false, fmt.Errorf("claude needs input, but the run is not interactive: %s", question.Question)
 	}
 
-	if !isReviewDone(result.Signal) {
-		r.log.Print("warning: first review pass did not complete cleanly, continuing...")
+	answer, askErr := r.inputCollector.AskQuestion(ctx, question.Question, question.Options)
+	if askErr != nil {
+		return false, fmt.Errorf("collect answer: %w", askErr)
 	}
 
+	r.log.LogAnswer(answer)
+	r.userAnswer = fmt.Sprintf("Question: %s\nAnswer: %s", question.Question, answer)
+	return true, nil
+}
+
+// withUserAnswer appends the answer to the last NEEDS_INPUT question to the task prompt, once.
+func (r *Runner) withUserAnswer(prompt string) string {
+	if r.userAnswer == "" {
+		return prompt
+	}
+	answer := r.userAnswer
+	r.userAnswer = ""
+	return fmt.Sprintf("%s\n\n---\nUSER INPUT:\nYou asked the user for a decision in the previous iteration.\n%s\n\nContinue the task using this answer.", prompt, answer)
+}
+
+// runClaudeReview runs the first Claude review pass with the first review prompt.
+// a pass that fails is retried up to ReviewRetryCount times.
+func (r *Runner) runClaudeReview(ctx context.Context) error {
+	for failures := 0; ; failures++ {
+		result, err := r.RunPhase(ctx, PhaseFirstReview)
+		if err != nil {
+			return err
+		}
+
+		if result.Signal == SignalFailed {
+			if failures < r.cfg.ReviewRetryCount {
+				if err := r.retryFailedReview(ctx, failures+1); err != nil {
+					return err
+				}
+				continue
+			}
+			return errors.New("review failed (FAILED signal received)")
+		}
+
+		if !isReviewDone(result.Signal) {
+			r.log.Print("warning: first review pass did not complete cleanly, continuing...")
+		}
+		return nil
+	}
+}
+
+// retryFailedReview logs the retry of a review iteration that returned the FAILED signal
+// and waits the iteration delay. attempt is the 1-based retry number.
+func (r *Runner) retryFailedReview(ctx context.Context, attempt int) error {
+	r.log.Print("review failed, retrying (%d/%d)...", attempt, r.cfg.ReviewRetryCount)
+	r.retryPending = true
+	if err := r.sleepWithContext(ctx, r.iterationDelay); err != nil {
+		return fmt.Errorf("interrupted: %w", err)
+	}
 	return nil
 }
 
@@ -454,13 +806,14 @@ func (r *Runner) runClaudeReview(ctx context.Context, prompt string) error {
 // optional promptPrefix is prepended to the review prompt (used for commit-pending instruction after codex).
 func (r *Runner) runClaudeReviewLoop(ctx context.Context, promptPrefix ...string) error {
 	// review iterations = 10% of max_iterations
-	maxReviewIterations := max(minReviewIterations, r.cfg.MaxIterations/reviewIterationDivisor)
+	maxReviewIterations := ReviewIterationLimit(r.cfg.MaxIterations)
 
 	prefix := ""
 	if len(promptPrefix) > 0 {
 		prefix = promptPrefix[0]
 	}
 
+	failures := 0
 	for i := 1; i <= maxReviewIterations; i++ {
 		select {
 		case <-ctx.Done():
@@ -473,8 +826,8 @@ func (r *Runner) runClaudeReviewLoop(ctx context.Context, promptPrefix ...string
 		// capture HEAD hash before running claude for no-commit detection
 		headBefore := r.headHash()
 
-		result := r.runWithLimitRetry(ctx, r.claude.Run,
-			prefix+r.replacePromptVariables(r.cfg.AppConfig.ReviewSecondPrompt), "claude")
+		prompt := r.buildSecondReviewPrompt(ctx)
+		result := r.runWithLimitRetry(ctx, r.claude.Run, prefix+prompt, "claude")
 		if result.Error != nil {
 			if err := r.handlePatternMatchError(result.Error, "claude"); err != nil {
 				return err
@@ -483,8 +836,17 @@ func (r *Runner) runClaudeReviewLoop(ctx context.Context, promptPrefix ...string
 		}
 
 		if result.Signal == SignalFailed {
+			if failures < r.cfg.ReviewRetryCount {
+				failures++
+				if err := r.retryFailedReview(ctx, failures); err != nil {
+					return err
+				}
+				i-- // a retry repeats the iteration, it doesn't use up the review iteration budget
+				continue
+			}
 			return errors.New("review failed (FAILED signal received)")
 		}
+		failures = 0
 
 		if isReviewDone(result.Signal) {
 			r.log.Print("claude review complete - no more findings")
@@ -495,6 +857,7 @@ func (r *Runner) runClaudeReviewLoop(ctx context.Context, promptPrefix ...string
 		// it could finish, so "no changes" doesn't mean "nothing to fix"
 		if r.lastSessionTimedOut {
 			r.log.Print("session timed out, retrying review iteration...")
+			r.retryPending = true
 			continue
 		}
 
@@ -604,6 +967,11 @@ func (r *Runner) runCodexLoop(ctx context.Context) error {
 		return nil
 	}
 
+	if tool == "codex" && r.diffTooSmallForCodex() {
+		r.log.Print("diff too small for external review, skipping codex")
+		return nil
+	}
+
 	// custom review tool
 	if tool == "custom" {
 		if r.custom == nil {
@@ -620,40 +988,306 @@ func (r *Runner) runCodexLoop(ctx context.Context) error {
 	}
 
 	// default: codex review
-	return r.runExternalReviewLoop(ctx, externalReviewConfig{
+	cfg := externalReviewConfig{
 		name:            "codex",
 		runReview:       r.codex.Run,
 		buildPrompt:     r.buildCodexPrompt,
 		buildEvalPrompt: r.buildCodexEvaluationPrompt,
 		showSummary:     r.showCodexSummary,
+		checkFindings:   r.checkCodexP1,
 		makeSection:     status.NewCodexIterationSection,
-	})
+	}
+	if r.cfg.AppConfig != nil && len(r.cfg.AppConfig.CodexReviewers) > 0 {
+		cfg.runRound = r.runCodexReviewers
+	}
+	return r.runExternalReviewLoop(ctx, cfg)
+}
+
+// runCodexReviewers runs every codex_reviewers pass in order and combines their output for one evaluation.
+// each reviewer uses its own prompt and project_doc when configured, its findings are put under a
+// "reviewer: <name>" header so the evaluation can attribute them. reviewers without output are left out.
+func (r *Runner) runCodexReviewers(ctx context.Context, isFirst bool, claudeResponse string) executor.Result {
+	var parts []string
+	var signal string
+	diff := &reviewerDiff{}
+	for _, rv := range r.cfg.AppConfig.CodexReviewers {
+		if reason := r.codexReviewerSkipReason(rv.Name, diff); reason != "" {
+			r.log.Print("skipping codex reviewer %s: %s", rv.Name, reason)
+			continue
+		}
+		prompt := rv.Prompt
+		if prompt == "" {
+			prompt = r.cfg.AppConfig.CodexReviewPrompt
+		}
+		rvExec := r.codex
+		if e, ok := r.codexByReviewer[rv.Name]; ok {
+			rvExec = e
+		}
+
+		r.log.Print("running codex reviewer %s", rv.Name)
+		result := r.runWithLimitRetry(ctx, rvExec.Run, r.replaceVariablesWithIteration(prompt, isFirst, claudeResponse), "codex")
+		if result.Error != nil {
+			return executor.Result{Error: fmt.Errorf("reviewer %s: %w", rv.Name, result.Error)}
+		}
+		if strings.TrimSpace(result.Output) == "" {
+			r.log.Print("codex reviewer %s returned no output", rv.Name)
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("--- reviewer: %s ---\n%s", rv.Name, strings.TrimSpace(result.Output)))
+		if signal == "" {
+			signal = result.Signal
+		}
+	}
+	return executor.Result{Output: strings.Join(parts, "\n\n"), Signal: signal}
+}
+
+// reviewerDiff is the diff codex_reviewers conditions are checked against, loaded once per round on first use.
+type reviewerDiff struct {
+	loaded bool
+	err    error
+	lines  int      // added+deleted lines
+	files  []string // changed file paths relative to the repository root, nil in review_working_tree mode
+}
+
+// codexReviewerSkipReason checks the conditions of a codex reviewer and returns why it doesn't run in this round,
+// empty when it runs. conditions that can't be checked, without git or on git errors, let the reviewer run.
+func (r *Runner) codexReviewerSkipReason(name string, diff *reviewerDiff) string {
+	cond, ok := r.cfg.AppConfig.CodexReviewerConditions[name]
+	if !ok {
+		return ""
+	}
+	if len(cond.Modes) > 0 && !slices.Contains(cond.Modes, string(r.cfg.Mode)) {
+		return fmt.Sprintf("not enabled in %s mode", r.cfg.Mode)
+	}
+	if (cond.MinDiffLines <= 0 && len(cond.Paths) == 0) || r.git == nil {
+		return ""
+	}
+
+	if !diff.loaded {
+		diff.loaded = true
+		diff.lines, diff.files, diff.err = r.reviewerDiffStats()
+		if diff.err != nil {
+			r.log.Print("warning: failed to get diff for codex reviewer conditions, running reviewers anyway: %v", diff.err)
+		}
+	}
+	if diff.err != nil {
+		return ""
+	}
+	if cond.MinDiffLines > 0 && diff.lines < cond.MinDiffLines {
+		return fmt.Sprintf("diff has %d changed lines, below %d", diff.lines, cond.MinDiffLines)
+	}
+	if len(cond.Paths) > 0 && diff.files != nil && !slices.ContainsFunc(diff.files, func(f string) bool {
+		return matchesAnyPattern(cond.Paths, f)
+	}) {
+		return "no changed files match " + strings.Join(cond.Paths, ", ")
+	}
+	return ""
+}
+
+// reviewerDiffStats returns the changed line count and changed files of the reviewed diff.
+// per-file stats exist only for the branch diff, in review_working_tree mode files is nil.
+func (r *Runner) reviewerDiffStats() (lines int, files []string, err error) {
+	stats, err := r.diffStats()
+	if err != nil {
+		return 0, nil, err
+	}
+	if r.reviewWorkingTree() {
+		return stats.Additions + stats.Deletions, nil, nil
+	}
+	perFile, err := r.git.DiffStatsPerFile(r.getDefaultBranch(), r.reviewExcludes()...)
+	if err != nil {
+		return 0, nil, fmt.Errorf("branch diff files: %w", err)
+	}
+	files = make([]string, 0, len(perFile))
+	for _, f := range perFile {
+		files = append(files, f.Path)
+	}
+	return stats.Additions + stats.Deletions, files, nil
+}
+
+// matchesAnyPattern reports whether file matches one of the patterns. patterns without a slash match the
+// file name in any directory (e.g. "*.go"), others match the whole path, where "**" stands for any number
+// of directories (e.g. "auth/**/*.go").
+func matchesAnyPattern(patterns []string, file string) bool {
+	for _, p := range patterns {
+		if !strings.Contains(p, "/") {
+			if ok, _ := path.Match(p, path.Base(file)); ok {
+				return true
+			}
+			continue
+		}
+		if matchPathSegments(strings.Split(p, "/"), strings.Split(file, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathSegments matches path segments against pattern segments, "**" matches zero or more segments.
+func matchPathSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchPathSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchPathSegments(pattern[1:], segments[1:])
+}
+
+// checkCodexP1 fails when FailOnP1 is set and codex output contains P0 or P1 findings.
+// the error lists the findings, so the failure notification carries them to a human.
+func (r *Runner) checkCodexP1(output string) error {
+	if !r.cfg.FailOnP1 {
+		return nil
+	}
+	critical := blockingFindings(parseCodexFindings(output), 1)
+	if len(critical) == 0 {
+		return nil
+	}
+	details := make([]string, 0, len(critical))
+	for _, f := range critical {
+		details = append(details, f.String())
+	}
+	r.log.Print("codex reported %d P1 finding(s), stopping for human review", len(critical))
+	return fmt.Errorf("codex reported %d P1 finding(s), automated fixing disabled by codex_fail_on_p1:\n%s",
+		len(critical), strings.Join(details, "\n"))
+}
+
+// diffTooSmallForCodex reports whether the branch diff is below codex_min_diff_lines.
+// external-only mode never skips, the user asked for codex explicitly. on git errors the review runs.
+func (r *Runner) diffTooSmallForCodex() bool {
+	if r.cfg.Mode == ModeExternalOnly || r.git == nil || r.cfg.AppConfig == nil || r.cfg.AppConfig.CodexMinDiffLines <= 0 {
+		return false
+	}
+	stats, err := r.diffStats()
+	if err != nil {
+		r.log.Print("warning: failed to get diff stats, running codex anyway: %v", err)
+		return false
+	}
+	return stats.Additions+stats.Deletions < r.cfg.AppConfig.CodexMinDiffLines
+}
+
+// ErrExternalReviewNotConverged is returned when the external review rounds of the run exceed max_external_rounds.
+var ErrExternalReviewNotConverged = errors.New("external review did not converge")
+
+// checkExternalRounds fails once the run has used up max_external_rounds, a safety bound against a review
+// and its fixes chasing each other forever. the error carries the last findings for a human to pick up.
+func (r *Runner) checkExternalRounds(name, lastFindings string) error {
+	if r.cfg.AppConfig == nil || r.cfg.AppConfig.MaxExternalRounds <= 0 || r.externalRounds < r.cfg.AppConfig.MaxExternalRounds {
+		return nil
+	}
+	r.log.Print("%s review still has findings after %d round(s), max_external_rounds reached", name, r.externalRounds)
+	err := fmt.Errorf("%w after %d %s round(s), max_external_rounds is %d", ErrExternalReviewNotConverged,
+		r.externalRounds, name, r.cfg.AppConfig.MaxExternalRounds)
+	if findings := strings.TrimSpace(lastFindings); findings != "" {
+		return fmt.Errorf("%w, last findings:\n%s", err, findings)
+	}
+	return err
+}
+
+// ErrStoppedAtGate is returned when the user declines to proceed at an interactive phase gate.
+// the run ends cleanly, the branch is left as is.
+var ErrStoppedAtGate = errors.New("stopped at phase gate")
+
+// phaseGate asks the user whether to proceed to the next phase, with InteractiveGates.
+// the first phase of the run starts without asking, declining a later one returns ErrStoppedAtGate.
+func (r *Runner) phaseGate(ctx context.Context, next string) error {
+	if !r.cfg.InteractiveGates || r.confirmFn == nil {
+		return nil
+	}
+	if !r.gateArmed {
+		r.gateArmed = true
+		return nil
+	}
+	if r.confirmFn(ctx, fmt.Sprintf("proceed to %s?", next)) {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("phase gate: %w", ctx.Err())
+	}
+	r.log.Print("%s declined, stopping the run", next)
+	return ErrStoppedAtGate
+}
+
+// checkDiffSize guards the review phases against a diff over MaxDiffLines, a cost-safety limit.
+// an oversized diff aborts the run unless the user confirms it interactively.
+// without git or when the stats can't be read, the review runs.
+func (r *Runner) checkDiffSize(ctx context.Context) error {
+	if r.cfg.MaxDiffLines <= 0 || r.git == nil {
+		return nil
+	}
+	stats, err := r.diffStats()
+	if err != nil {
+		r.log.Print("warning: failed to get diff stats, max diff lines not checked: %v", err)
+		return nil
+	}
+	lines := stats.Additions + stats.Deletions
+	if lines <= r.cfg.MaxDiffLines {
+		return nil
+	}
+	msg := fmt.Sprintf("diff has %d changed lines in %d files, over the --max-diff-lines limit of %d",
+		lines, stats.Files, r.cfg.MaxDiffLines)
+	if r.confirmFn != nil && r.confirmFn(ctx, msg+", review it anyway?") {
+		r.log.Print("%s, review confirmed", msg)
+		return nil
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("diff size check: %w", ctx.Err())
+	}
+	return errors.New(msg)
+}
+
+// diffStats returns the size of the reviewed changes: the branch diff, or uncommitted changes with review_working_tree.
+func (r *Runner) diffStats() (git.DiffStats, error) {
+	if r.reviewWorkingTree() {
+		stats, err := r.git.DiffStatsRange("HEAD", "", r.reviewExcludes()...)
+		if err != nil {
+			return git.DiffStats{}, fmt.Errorf("working tree diff stats: %w", err)
+		}
+		return stats, nil
+	}
+	stats, err := r.git.DiffStats(r.getDefaultBranch(), r.reviewExcludes()...)
+	if err != nil {
+		return git.DiffStats{}, fmt.Errorf("branch diff stats: %w", err)
+	}
+	return stats, nil
 }
 
 // externalReviewConfig holds callbacks for running an external review tool.
 type externalReviewConfig struct {
-	name            string                                                   // tool name for error messages
-	runReview       func(ctx context.Context, prompt string) executor.Result // run the external review tool
-	buildPrompt     func(isFirst bool, claudeResponse string) string         // build prompt for review tool
-	buildEvalPrompt func(output string) string                               // build evaluation prompt for claude
-	showSummary     func(output string)                                      // display review findings summary
-	makeSection     func(iteration int) status.Section                       // create section header
+	name            string                                                                         // tool name for error messages
+	runReview       func(ctx context.Context, prompt string) executor.Result                       // run the external review tool
+	runRound        func(ctx context.Context, isFirst bool, claudeResponse string) executor.Result // optional, replaces runReview+buildPrompt
+	buildPrompt     func(isFirst bool, claudeResponse string) string                               // build prompt for review tool
+	buildEvalPrompt func(output string) string                                                     // build evaluation prompt for claude
+	showSummary     func(output string)                                                            // display review findings summary
+	checkFindings   func(output string) error                                                      // optional, aborts the loop before claude evaluation
+	makeSection     func(iteration int) status.Section                                             // create section header
 }
 
 // runExternalReviewLoop runs a generic external review tool-claude loop.
 // it terminates when no findings remain, max iterations are reached,
 // stalemate is detected (review patience), or a manual break is requested.
 func (r *Runner) runExternalReviewLoop(ctx context.Context, cfg externalReviewConfig) error {
-	maxIterations := max(minCodexIterations, r.cfg.MaxIterations/codexIterationDivisor)
-	if r.cfg.MaxExternalIterations > 0 {
-		maxIterations = r.cfg.MaxExternalIterations
-	}
+	maxIterations := ExternalIterationLimit(r.cfg.MaxIterations, r.cfg.MaxExternalIterations)
 
 	// derive a child context that cancels when break channel fires
 	loopCtx, loopCancel := r.breakContext(ctx)
 	defer loopCancel()
 
 	var claudeResponse string // first iteration has no prior response
+	var lastFindings string   // output of the last review round, reported when max_external_rounds is exceeded
 	var unchangedRounds int   // consecutive iterations with no commits (for stalemate detection)
 	firstCompleted := false   // tracks if any successful eval completed; controls diff scope for external tool
 
@@ -668,11 +1302,21 @@ func (r *Runner) runExternalReviewLoop(ctx context.Context, cfg externalReviewCo
 		default:
 		}
 
+		if err := r.checkExternalRounds(cfg.name, lastFindings); err != nil {
+			return err
+		}
+		r.externalRounds++
+
 		r.log.PrintSection(cfg.makeSection(i))
 
 		// run external review tool. use branch-wide diff until a successful claude eval completes,
 		// so that a timeout on the first eval doesn't narrow subsequent reviews to working-tree only
-		reviewResult := r.runWithLimitRetry(loopCtx, cfg.runReview, cfg.buildPrompt(!firstCompleted, claudeResponse), cfg.name)
+		var reviewResult executor.Result
+		if cfg.runRound != nil {
+			reviewResult = cfg.runRound(loopCtx, !firstCompleted, claudeResponse)
+		} else {
+			reviewResult = r.runWithLimitRetry(loopCtx, cfg.runReview, cfg.buildPrompt(!firstCompleted, claudeResponse), cfg.name)
+		}
 		if reviewResult.Error != nil {
 			if r.isManualBreak(ctx) {
 				r.log.Print("manual break requested, external review terminated early")
@@ -689,8 +1333,15 @@ func (r *Runner) runExternalReviewLoop(ctx context.Context, cfg externalReviewCo
 			break
 		}
 
+		lastFindings = reviewResult.Output
+
 		// show findings summary before Claude evaluation
 		cfg.showSummary(reviewResult.Output)
+		if cfg.checkFindings != nil {
+			if err := cfg.checkFindings(reviewResult.Output); err != nil {
+				return err
+			}
+		}
 
 		// capture state before claude evaluation for stalemate detection (only when enabled)
 		var headBefore, diffBefore string
@@ -722,6 +1373,7 @@ func (r *Runner) runExternalReviewLoop(ctx context.Context, cfg externalReviewCo
 		// "no changes" doesn't mean "nothing to fix"
 		if r.lastSessionTimedOut {
 			r.log.Print("claude eval session timed out, retrying %s iteration...", cfg.name)
+			r.retryPending = true
 			continue
 		}
 
@@ -730,7 +1382,7 @@ func (r *Runner) runExternalReviewLoop(ctx context.Context, cfg externalReviewCo
 
 		// exit only when claude sees "no findings"
 		if isCodexDone(claudeResult.Signal) {
-			r.log.Print("%s review complete - no more findings", cfg.name)
+			r.log.Print("%s review complete - no more findings after %d round(s)", cfg.name, i)
 			return nil
 		}
 
@@ -743,6 +1395,9 @@ func (r *Runner) runExternalReviewLoop(ctx context.Context, cfg externalReviewCo
 		if stalemate {
 			return nil
 		}
+		if i < maxIterations {
+			r.log.Print("%s round %d/%d done, findings addressed, re-running review", cfg.name, i, maxIterations)
+		}
 
 		if err := r.sleepWithContext(loopCtx, r.iterationDelay); err != nil {
 			if r.isManualBreak(ctx) {
@@ -753,7 +1408,7 @@ func (r *Runner) runExternalReviewLoop(ctx context.Context, cfg externalReviewCo
 		}
 	}
 
-	r.log.Print("max %s iterations reached, continuing to next phase...", cfg.name)
+	r.log.Print("max %s iterations reached (%d rounds) without a clean review, continuing to next phase...", cfg.name, maxIterations)
 	return nil
 }
 
@@ -805,7 +1460,7 @@ func (r *Runner) hasUncompletedTasks() bool {
 	if path == "" {
 		return false // no plan file, nothing to complete
 	}
-	p, err := plan.ParsePlanFile(path)
+	p, err := r.parsePlan(path)
 	if err != nil {
 		r.log.Print("[WARN] failed to parse plan file for completion check: %v", err)
 		return true // assume incomplete if can't read
@@ -828,26 +1483,132 @@ func (r *Runner) hasUncompletedTasks() bool {
 	return false
 }
 
-// nextPlanTaskPosition returns the 1-indexed position of the first uncompleted task in the plan.
+// nextPlanTaskPosition returns the 1-indexed position of the next uncompleted task in the plan, picked by task_order.
 // returns 0 if the plan file can't be read/parsed or no uncompleted tasks exist (caller falls back to loop counter).
 func (r *Runner) nextPlanTaskPosition() int {
-	p, err := plan.ParsePlanFile(r.resolvePlanFilePath())
+	p, err := r.parsePlan(r.resolvePlanFilePath())
 	if err != nil {
 		r.log.Print("[WARN] failed to parse plan file for task position: %v", err)
 		return 0
 	}
+	return p.NextTask(r.taskOrder()) + 1 // 1-indexed, -1 (none) becomes 0
+}
+
+// taskOrder returns the configured task selection strategy, empty means sequential.
+func (r *Runner) taskOrder() plan.TaskOrder {
+	if r.cfg.AppConfig == nil {
+		return ""
+	}
+	return plan.TaskOrder(r.cfg.AppConfig.TaskOrder)
+}
+
+// parsePlan parses the plan file and logs when its task structure changed since the previous parse,
+// e.g. claude added a task 2.5 or renumbered tasks mid-run. the plan is never cached, every task loop
+// decision reads the current file.
+func (r *Runner) parsePlan(path string) (*plan.Plan, error) {
+	p, err := plan.ParsePlanFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("parse plan: %w", err)
+	}
+	tasks := make([]string, len(p.Tasks))
 	for i, t := range p.Tasks {
-		if t.HasUncompletedActionableWork() {
-			return i + 1 // 1-indexed
+		tasks[i] = taskLabel(t)
+	}
+	if r.planTasks != nil && !slices.Equal(r.planTasks, tasks) {
+		r.log.Print("plan task structure changed: %s", describeTaskChanges(r.planTasks, tasks))
+	}
+	r.planTasks = tasks
+	return p, nil
+}
+
+// taskLabel identifies a plan task by number and title, e.g. "Task 2: add parser".
+// tasks with a non-integer number, like 2.5, have number 0 and are labeled by title only.
+func taskLabel(t plan.Task) string {
+	if t.Number == 0 {
+		return "Task: " + t.Title
+	}
+	return fmt.Sprintf("Task %d: %s", t.Number, t.Title)
+}
+
+// describeTaskChanges summarizes the difference between two task lists: added and removed tasks,
+// and tasks that kept their title but got another number. tasks are matched by title.
+func describeTaskChanges(before, after []string) string {
+	title := func(label string) string {
+		_, t, _ := strings.Cut(label, ": ")
+		return t
+	}
+	titlesBefore := make(map[string]int, len(before))
+	for i, l := range before {
+		titlesBefore[title(l)] = i
+	}
+	titlesAfter := make(map[string]bool, len(after))
+
+	var parts []string
+	renumbered := 0
+	for _, l := range after {
+		titlesAfter[title(l)] = true
+		j, ok := titlesBefore[title(l)]
+		switch {
+		case !ok:
+			parts = append(parts, "added "+l)
+		case before[j] != l:
+			renumbered++
 		}
 	}
-	return 0
+	for _, l := range before {
+		if !titlesAfter[title(l)] {
+			parts = append(parts, "removed "+l)
+		}
+	}
+	if renumbered > 0 {
+		parts = append(parts, fmt.Sprintf("%d renumbered", renumbered))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "tasks reordered")
+	}
+	return fmt.Sprintf("%d tasks, was %d (%s)", len(after), len(before), strings.Join(parts, ", "))
 }
 
 // showCodexSummary displays a condensed summary of codex output before Claude evaluation.
 // extracts text until first code block or maxCodexSummaryLen chars, whichever is shorter.
 func (r *Runner) showCodexSummary(output string) {
 	r.showExternalReviewSummary("codex", output)
+	if r.cfg.AppConfig != nil && r.cfg.AppConfig.CodexBlameHints {
+		r.showBlameHints(output)
+	}
+}
+
+// showBlameHints logs who last changed each file:line referenced in review output, to help route findings.
+// references that can't be blamed (new files, uncommitted lines, paths outside the repo) are skipped.
+func (r *Runner) showBlameHints(output string) {
+	if r.git == nil {
+		return
+	}
+	var hints []string
+	seen := make(map[string]bool)
+	for _, m := range fileLineRefPattern.FindAllStringSubmatch(output, -1) {
+		ref := m[1] + ":" + m[2]
+		if seen[ref] || len(seen) >= maxBlameHints {
+			continue
+		}
+		seen[ref] = true
+		line, err := strconv.Atoi(m[2])
+		if err != nil {
+			continue
+		}
+		author, commit, err := r.git.BlameLine(m[1], line)
+		if err != nil || author == "" {
+			continue
+		}
+		hints = append(hints, fmt.Sprintf("  %s last changed by %s (%s)", ref, author, commit))
+	}
+	if len(hints) == 0 {
+		return
+	}
+	r.log.Print("codex findings ownership:")
+	for _, h := range hints {
+		r.log.PrintAligned(h)
+	}
 }
 
 // showExternalReviewSummary displays a condensed summary of external review output.
@@ -1010,6 +1771,7 @@ func (r *Runner) runPlanCreation(ctx context.Context) error {
 		// preserve lastRevisionFeedback so the next attempt re-sends the user's revision request
 		if r.lastSessionTimedOut {
 			r.log.Print("plan creation session timed out, retrying iteration...")
+			r.retryPending = true
 			if err := r.sleepWithContext(ctx, r.iterationDelay); err != nil {
 				return fmt.Errorf("interrupted: %w", err)
 			}
@@ -1079,12 +1841,20 @@ func (r *Runner) handlePatternMatchError(err error, tool string) error {
 // other errors (including PatternMatchError) are returned without retry.
 // when SessionTimeout > 0, each run() call gets a child context with deadline.
 // on session timeout (child timed out but parent alive), logs a warning and returns result with error cleared.
-// retries indefinitely until success or context cancellation.
+// retries until success or context cancellation, or up to maxLimitRetries times when it is set.
 func (r *Runner) runWithLimitRetry(ctx context.Context, run func(context.Context, string) executor.Result,
 	prompt, toolName string) executor.Result {
-	for {
+	for retries := 0; ; retries++ {
+		started := time.Now()
+		stopHeartbeat := r.startHeartbeat(started)
 		result := r.runWithSessionTimeout(ctx, run, prompt, toolName)
+		stopHeartbeat()
+		r.recordIteration(toolName, result, started, retries > 0)
 		if result.Error == nil {
+			if result.SignalAmbiguous {
+				r.log.Print("warning: %s output contains multiple distinct signals, using the last one: %s",
+					toolName, result.Signal)
+			}
 			return result
 		}
 
@@ -1097,6 +1867,11 @@ func (r *Runner) runWithLimitRetry(ctx context.Context, run func(context.Context
 			return result // no wait configured, return limit error as-is
 		}
 
+		if r.maxLimitRetries > 0 && retries >= r.maxLimitRetries {
+			r.log.Print("rate limit still present in %s output after %d retries, giving up", toolName, retries)
+			return result
+		}
+
 		r.log.Print("rate limit detected: %q in %s output, waiting %s before retry...",
 			limitErr.Pattern, toolName, r.waitOnLimit)
 
@@ -1106,17 +1881,45 @@ func (r *Runner) runWithLimitRetry(ctx context.Context, run func(context.Context
 	}
 }
 
+// startHeartbeat logs "still working in <phase> (Ns elapsed)" every heartbeat_interval while an executor
+// call runs, so non-TTY output doesn't go silent. returns a stop function that must be called when the
+// call returns, it waits for the heartbeat goroutine to exit so nothing is logged after it.
+func (r *Runner) startHeartbeat(started time.Time) func() {
+	if r.heartbeatInterval <= 0 {
+		return func() {}
+	}
+	done, exited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(r.heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.log.Print("still working in %s (%ds elapsed)", r.phaseHolder.Get(), int(time.Since(started).Seconds()))
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
 // runWithSessionTimeout runs the executor with an optional session timeout.
 // if SessionTimeout > 0 and toolName is "claude", wraps ctx with context.WithTimeout before calling run.
 // on session timeout (child timed out but parent alive), logs a warning and clears the error
 // so callers treat it as a non-completing iteration that continues naturally.
+// a claude idle timeout (claude_idle_timeout) is handled the same way.
 // only applies to claude sessions; codex and custom executors are not affected.
 func (r *Runner) runWithSessionTimeout(ctx context.Context, run func(context.Context, string) executor.Result,
 	prompt, toolName string) executor.Result {
 	r.lastSessionTimedOut = false
 	sessionTimeout := r.sessionTimeout()
 	if sessionTimeout <= 0 || toolName != "claude" {
-		return run(ctx, prompt) // no timeout configured or non-claude tool
+		return r.handleIdleTimeout(run(ctx, prompt)) // no timeout configured or non-claude tool
 	}
 
 	childCtx, cancel := context.WithTimeout(ctx, sessionTimeout)
@@ -1133,9 +1936,22 @@ func (r *Runner) runWithSessionTimeout(ctx context.Context, run func(context.Con
 			toolName, sessionTimeout)
 		result.Error = nil
 		result.Signal = "" // clear any signal emitted before timeout; can't trust partial session
+		result.SignalAmbiguous = false
 		r.lastSessionTimedOut = true
 	}
 
+	return r.handleIdleTimeout(result)
+}
+
+// handleIdleTimeout turns a claude idle timeout into a timed-out session: it logs a warning and clears
+// the error, so the calling loop retries the iteration as it does after a session timeout.
+func (r *Runner) handleIdleTimeout(result executor.Result) executor.Result {
+	if !errors.Is(result.Error, executor.ErrIdleTimeout) {
+		return result
+	}
+	r.log.Print("warning: %v, the session stopped producing output and was killed", result.Error)
+	result.Error = nil
+	r.lastSessionTimedOut = true
 	return result
 }
 
@@ -1155,11 +1971,28 @@ func (r *Runner) runFinalize(ctx context.Context) error {
 	if !r.cfg.FinalizeEnabled {
 		return nil
 	}
+	if err := r.phaseGate(ctx, "finalize"); err != nil {
+		return err
+	}
 
 	r.phaseHolder.Set(status.PhaseFinalize)
 	r.log.PrintSection(status.NewGenericSection("finalize step"))
 
+	return r.withHooks(ctx, hooks.PreFinalize, hooks.PostFinalize, r.runFinalizeStep)
+}
+
+// runFinalizeStep runs the finalize prompt, see runFinalize for the failure semantics.
+// with finalize_no_commit the prompt asks claude to stage its changes without committing them.
+func (r *Runner) runFinalizeStep(ctx context.Context) error {
+	noCommit := r.cfg.AppConfig.FinalizeNoCommit
 	prompt := r.replacePromptVariables(r.cfg.AppConfig.FinalizePrompt)
+	headBefore := ""
+	if noCommit {
+		prompt += "\n\nIMPORTANT: Do NOT commit, rebase or rewrite history in this step. " +
+			"Make your changes and stage them with `git add`, leaving them uncommitted for manual review. " +
+			"Skip any steps above that require committing."
+		headBefore = r.headHash()
+	}
 	result := r.runWithLimitRetry(ctx, r.claude.Run, prompt, "claude")
 
 	if result.Error != nil {
@@ -1181,10 +2014,34 @@ func (r *Runner) runFinalize(ctx context.Context) error {
 		return nil
 	}
 
+	if noCommit {
+		if headAfter := r.headHash(); headBefore != "" && headAfter != "" && headAfter != headBefore {
+			r.log.Print("warning: finalize step created commits despite finalize_no_commit")
+		}
+		r.log.Print("finalize step completed, changes left staged for review")
+		return nil
+	}
 	r.log.Print("finalize step completed")
 	return nil
 }
 
+// withHooks runs fn between the pre and post hook scripts of a pipeline stage.
+// both hooks get the stage's phase, and the post hook runs only when fn succeeded.
+// hook failures are returned only when fatal.
+func (r *Runner) withHooks(ctx context.Context, pre, post hooks.Point, fn func(ctx context.Context) error) error {
+	phase := string(r.phaseHolder.Get())
+	if err := r.hooks.Run(ctx, pre, phase); err != nil {
+		return fmt.Errorf("hooks: %w", err)
+	}
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	if err := r.hooks.Run(ctx, post, phase); err != nil {
+		return fmt.Errorf("hooks: %w", err)
+	}
+	return nil
+}
+
 // sleepWithContext pauses for the given duration but returns immediately if context is canceled.
 // returns ctx.Err() on cancellation, nil on normal completion.
 func (r *Runner) sleepWithContext(ctx context.Context, d time.Duration) error {
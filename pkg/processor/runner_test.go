@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -16,6 +17,8 @@ import (
 
 	"github.com/umputun/ralphex/pkg/config"
 	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/hooks"
 	"github.com/umputun/ralphex/pkg/processor"
 	"github.com/umputun/ralphex/pkg/processor/mocks"
 	"github.com/umputun/ralphex/pkg/status"
@@ -54,10 +57,26 @@ func newMockLogger(path string) *mocks.LoggerMock {
 		LogQuestionFunc:    func(_ string, _ []string) {},
 		LogAnswerFunc:      func(_ string) {},
 		LogDraftReviewFunc: func(_, _ string) {},
+		StartTaskFunc:      func(_ int) {},
 		PathFunc:           func() string { return path },
 	}
 }
 
+func TestMode_Description(t *testing.T) {
+	for _, m := range processor.AllModes {
+		assert.NotEmpty(t, m.Description(), "mode %s", m)
+	}
+	assert.Empty(t, processor.Mode("unknown").Description())
+}
+
+func TestIterationLimits(t *testing.T) {
+	assert.Equal(t, 3, processor.ReviewIterationLimit(20))
+	assert.Equal(t, 5, processor.ReviewIterationLimit(50))
+	assert.Equal(t, 3, processor.ExternalIterationLimit(10, 0))
+	assert.Equal(t, 10, processor.ExternalIterationLimit(50, 0))
+	assert.Equal(t, 2, processor.ExternalIterationLimit(50, 2))
+}
+
 func TestRunner_Run_UnknownMode(t *testing.T) {
 	log := newMockLogger("")
 	claude := newMockExecutor(nil)
@@ -70,6 +89,203 @@ func TestRunner_Run_UnknownMode(t *testing.T) {
 	assert.Contains(t, err.Error(), "unknown mode")
 }
 
+func TestRunner_RunPhase(t *testing.T) {
+	appCfg := testAppConfig(t)
+
+	tests := []struct {
+		name         string
+		phase        processor.Phase
+		codexEnabled bool
+		result       executor.Result
+		wantTool     string // "claude" or "codex", empty when no executor should run
+		wantPhase    status.Phase
+		wantPrompt   string // substring of the prompt passed to the executor
+		wantErr      string
+	}{
+		{name: "task", phase: processor.PhaseTask, result: executor.Result{Output: "done", Signal: status.Completed},
+			wantTool: "claude", wantPhase: status.PhaseTask, wantPrompt: "ALL_TASKS_DONE"},
+		{name: "first review", phase: processor.PhaseFirstReview, result: executor.Result{Signal: status.ReviewDone},
+			wantTool: "claude", wantPhase: status.PhaseReview, wantPrompt: "REVIEW_DONE"},
+		{name: "second review", phase: processor.PhaseSecondReview, result: executor.Result{Signal: status.ReviewDone},
+			wantTool: "claude", wantPhase: status.PhaseReview, wantPrompt: "REVIEW_DONE"},
+		{name: "external review", phase: processor.PhaseExternalReview, codexEnabled: true,
+			result: executor.Result{Output: "no issues"}, wantTool: "codex", wantPhase: status.PhaseCodex},
+		{name: "finalize", phase: processor.PhaseFinalize, result: executor.Result{Output: "finalized"},
+			wantTool: "claude", wantPhase: status.PhaseFinalize},
+		{name: "external review disabled", phase: processor.PhaseExternalReview, wantErr: "external review disabled"},
+		{name: "unknown phase", phase: "bogus", wantErr: "unknown phase: bogus"},
+		{name: "executor error", phase: processor.PhaseTask, result: executor.Result{Error: errors.New("boom")},
+			wantTool: "claude", wantPhase: status.PhaseTask, wantErr: "claude execution: boom"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			claude := newMockExecutor([]executor.Result{tc.result})
+			codex := newMockExecutor([]executor.Result{tc.result})
+			holder := &status.PhaseHolder{}
+			cfg := processor.Config{Mode: processor.ModeFull, PlanFile: "plan.md", MaxIterations: 10,
+				CodexEnabled: tc.codexEnabled, AppConfig: appCfg}
+			r := processor.NewWithExecutors(cfg, newMockLogger(""), processor.Executors{Claude: claude, Codex: codex}, holder)
+
+			res, err := r.RunPhase(t.Context(), tc.phase)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.result.Signal, res.Signal)
+				assert.Equal(t, tc.result.Output, res.Output)
+			}
+
+			var calls []struct {
+				Ctx    context.Context
+				Prompt string
+			}
+			switch tc.wantTool {
+			case "claude":
+				calls = claude.RunCalls()
+				assert.Empty(t, codex.RunCalls())
+			case "codex":
+				calls = codex.RunCalls()
+				assert.Empty(t, claude.RunCalls())
+			default:
+				assert.Empty(t, claude.RunCalls())
+				assert.Empty(t, codex.RunCalls())
+				return
+			}
+			require.Len(t, calls, 1)
+			assert.Contains(t, calls[0].Prompt, tc.wantPrompt)
+			assert.Equal(t, tc.wantPhase, holder.Get())
+		})
+	}
+}
+
+func TestRunner_RunPhase_NoAppConfig(t *testing.T) {
+	claude := newMockExecutor(nil)
+	r := processor.NewWithExecutors(processor.Config{Mode: processor.ModeFull}, newMockLogger(""),
+		processor.Executors{Claude: claude, Codex: newMockExecutor(nil)}, &status.PhaseHolder{})
+
+	_, err := r.RunPhase(t.Context(), processor.PhaseTask)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "app config required")
+	assert.Empty(t, claude.RunCalls())
+}
+
+func TestRunner_RunFull_StartPhase(t *testing.T) {
+	tests := []struct {
+		name       string
+		start      processor.StartPhase
+		finalize   bool
+		results    []executor.Result
+		wantCalls  int
+		wantFirst  string // substring of the first claude prompt
+		wantPhases []status.Phase
+		wantErr    string
+	}{
+		{name: "review skips tasks", start: processor.StartReview,
+			results: []executor.Result{
+				{Output: "review done", Signal: status.ReviewDone}, // first review
+				{Output: "review done", Signal: status.ReviewDone}, // pre-codex review loop
+				{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop (codex disabled)
+			},
+			wantCalls: 3, wantFirst: "REVIEW_DONE", wantPhases: []status.Phase{status.PhaseReview, status.PhaseCodex, status.PhaseReview}},
+		{name: "codex skips tasks and first reviews", start: processor.StartCodex,
+			results:   []executor.Result{{Output: "review done", Signal: status.ReviewDone}}, // post-codex review loop
+			wantCalls: 1, wantFirst: "REVIEW_DONE", wantPhases: []status.Phase{status.PhaseCodex, status.PhaseReview}},
+		{name: "finalize runs only finalize", start: processor.StartFinalize, finalize: true,
+			results:   []executor.Result{{Output: "finalized"}},
+			wantCalls: 1, wantPhases: []status.Phase{status.PhaseFinalize}},
+		{name: "unknown start phase", start: "deploy", wantErr: "unknown start phase: deploy"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			claude := newMockExecutor(tc.results)
+			holder := &status.PhaseHolder{}
+			var phases []status.Phase
+			holder.OnChange(func(_, cur status.Phase) { phases = append(phases, cur) })
+			cfg := processor.Config{Mode: processor.ModeFull, PlanFile: "plan.md", MaxIterations: 50,
+				FinalizeEnabled: tc.finalize, StartPhase: tc.start, AppConfig: testAppConfig(t)}
+			r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"),
+				processor.Executors{Claude: claude, Codex: newMockExecutor(nil)}, holder)
+
+			err := r.Run(t.Context())
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				assert.Empty(t, claude.RunCalls())
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, claude.RunCalls(), tc.wantCalls)
+			assert.Contains(t, claude.RunCalls()[0].Prompt, tc.wantFirst)
+			assert.NotContains(t, phases, status.PhaseTask, "task phase must be skipped")
+			assert.Equal(t, tc.wantPhases, phases[:len(tc.wantPhases)])
+		})
+	}
+}
+
+func TestRunner_RunFull_Hooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh scripts")
+	}
+
+	// every hook script appends "hook:phase" to a shared file
+	setup := func(t *testing.T, failing hooks.Point) (*hooks.Runner, string) {
+		t.Helper()
+		dir := t.TempDir()
+		record := filepath.Join(dir, "calls.txt")
+		for _, p := range hooks.AllPoints {
+			body := fmt.Sprintf("#!/bin/sh\necho \"$RALPHEX_HOOK:$RALPHEX_PHASE\" >> %s\n", record)
+			if p == failing {
+				body += "exit 1\n"
+			}
+			require.NoError(t, os.WriteFile(filepath.Join(dir, string(p)), []byte(body), 0o700))
+		}
+		return &hooks.Runner{Dir: dir, Log: newMockLogger("")}, record
+	}
+	readCalls := func(t *testing.T, record string) []string {
+		t.Helper()
+		data, err := os.ReadFile(record) //nolint:gosec // test file path
+		require.NoError(t, err)
+		return strings.Fields(string(data))
+	}
+
+	t.Run("runs around each stage", func(t *testing.T) {
+		hookRunner, record := setup(t, "")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "review done", Signal: status.ReviewDone}, // first review
+			{Output: "review done", Signal: status.ReviewDone}, // pre-codex review loop
+			{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop (codex disabled)
+			{Output: "finalized"},
+		})
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: "plan.md", MaxIterations: 50,
+			FinalizeEnabled: true, StartPhase: processor.StartReview, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"),
+			processor.Executors{Claude: claude, Codex: newMockExecutor(nil)}, &status.PhaseHolder{})
+		r.SetHooks(hookRunner)
+
+		require.NoError(t, r.Run(t.Context()))
+		assert.Equal(t, []string{"pre-review:review", "post-review:review", "pre-review:review", "post-review:review",
+			"pre-finalize:finalize", "post-finalize:finalize"}, readCalls(t, record))
+	})
+
+	t.Run("fatal pre hook aborts the stage", func(t *testing.T) {
+		hookRunner, record := setup(t, hooks.PreTask)
+		claude := newMockExecutor(nil)
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: "plan.md", MaxIterations: 50, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"),
+			processor.Executors{Claude: claude, Codex: newMockExecutor(nil)}, &status.PhaseHolder{})
+		r.SetHooks(hookRunner)
+
+		err := r.Run(t.Context())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "task phase: hooks: pre-task hook:")
+		assert.Empty(t, claude.RunCalls())
+		assert.Equal(t, []string{"pre-task:task"}, readCalls(t, record))
+	})
+}
+
 func TestRunner_RunFull_NoPlanFile(t *testing.T) {
 	log := newMockLogger("")
 	claude := newMockExecutor(nil)
@@ -108,6 +324,96 @@ func TestRunner_RunFull_Success(t *testing.T) {
 	assert.Len(t, codex.RunCalls(), 1)
 }
 
+func TestRunner_RunFull_ReviewPerTask(t *testing.T) {
+	// run executes a full-mode run with two tasks; claude "commits" by moving head when its output says so
+	run := func(t *testing.T, skipFinal bool, results []executor.Result) (*mocks.ExecutorMock, *mocks.GitCheckerMock, *mocks.LoggerMock, error) {
+		t.Helper()
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n### Task 1: one\n- [x] a\n### Task 2: two\n- [x] b\n"), 0o600))
+
+		head, idx := 0, 0
+		claude := &mocks.ExecutorMock{RunFunc: func(_ context.Context, _ string) executor.Result {
+			if idx >= len(results) {
+				return executor.Result{Error: errors.New("no more mock results")}
+			}
+			res := results[idx]
+			idx++
+			if strings.HasPrefix(res.Output, "commit") {
+				head++
+			}
+			return res
+		}}
+		gitMock := &mocks.GitCheckerMock{
+			HeadHashFunc:        func() (string, error) { return fmt.Sprintf("h%d", head), nil },
+			DiffFingerprintFunc: func() (string, error) { return "", nil },
+			DiffStatsRangeFunc: func(_, _ string, _ ...string) (git.DiffStats, error) {
+				return git.DiffStats{Files: 2, Additions: 10, Deletions: 1}, nil
+			},
+		}
+		appCfg := testAppConfig(t)
+		appCfg.ReviewPerTask = true
+		appCfg.SkipFinalReview = skipFinal
+		appCfg.TaskReviewPrompt = "review task {{TASK_NUMBER}} range {{TASK_DIFF_RANGE}}"
+		log := newMockLogger("progress.txt")
+
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, IterationDelayMs: 1, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: newMockExecutor(nil)}, &status.PhaseHolder{})
+		r.SetGitChecker(gitMock)
+		return claude, gitMock, log, r.Run(t.Context())
+	}
+
+	t.Run("reviews each task's commits and skips final review", func(t *testing.T) {
+		claude, gitMock, log, err := run(t, true, []executor.Result{
+			{Output: "commit task 1"},                           // task 1, h0 -> h1
+			{Output: "commit fix"},                              // task 1 review round 1 fixes, h1 -> h2
+			{Output: "clean", Signal: status.ReviewDone},        // task 1 review round 2
+			{Output: "commit task 2", Signal: status.Completed}, // task 2 and completion, h2 -> h3
+			{Output: "clean", Signal: status.ReviewDone},        // task 2 review
+			{Output: "review done", Signal: status.ReviewDone},  // post-codex review loop
+		})
+		require.NoError(t, err)
+
+		calls := claude.RunCalls()
+		require.Len(t, calls, 6)
+		assert.Equal(t, "review task 1 range h0..h1", calls[1].Prompt)
+		assert.Equal(t, "review task 1 range h0..h2", calls[2].Prompt, "later rounds include the fixes")
+		assert.Equal(t, "review task 2 range h2..h3", calls[4].Prompt)
+
+		ranges := gitMock.DiffStatsRangeCalls()
+		require.Len(t, ranges, 2)
+		assert.Equal(t, "h0", ranges[0].From)
+		assert.Equal(t, "h1", ranges[0].To)
+
+		var summary []string
+		for _, c := range log.PrintCalls() {
+			summary = append(summary, fmt.Sprintf(c.Format, c.Args...))
+		}
+		assert.Contains(t, summary, "tasks were reviewed individually, whole-branch claude review skipped")
+		assert.Contains(t, summary, "  task 1: 2 files, +10/-1, fixed in 1 round")
+		assert.Contains(t, summary, "  task 2: 2 files, +10/-1, no findings")
+	})
+
+	t.Run("task without commits is not reviewed, final review runs", func(t *testing.T) {
+		claude, gitMock, _, err := run(t, false, []executor.Result{
+			{Output: "nothing to do", Signal: status.Completed}, // no commit, no task review
+			{Output: "review done", Signal: status.ReviewDone},  // first review
+			{Output: "review done", Signal: status.ReviewDone},  // pre-codex review loop
+			{Output: "review done", Signal: status.ReviewDone},  // post-codex review loop
+		})
+		require.NoError(t, err)
+		assert.Len(t, claude.RunCalls(), 4)
+		assert.Empty(t, gitMock.DiffStatsRangeCalls())
+	})
+
+	t.Run("failed task review stops the run", func(t *testing.T) {
+		_, _, _, err := run(t, false, []executor.Result{
+			{Output: "commit task 1"},
+			{Output: "can't fix", Signal: status.Failed},
+		})
+		require.ErrorContains(t, err, "task 1 review failed")
+	})
+}
+
 func TestRunner_RunFull_NoCodexFindings(t *testing.T) {
 	tmpDir := t.TempDir()
 	planFile := filepath.Join(tmpDir, "plan.md")
@@ -151,7 +457,7 @@ func TestRunner_RunReviewOnly_Success(t *testing.T) {
 	assert.Len(t, codex.RunCalls(), 1)
 }
 
-func TestRunner_RunCodexOnly_Success(t *testing.T) {
+func TestRunner_RunExternalOnly_Success(t *testing.T) {
 	log := newMockLogger("progress.txt")
 	claude := newMockExecutor([]executor.Result{
 		{Output: "done", Signal: status.CodexDone},         // codex evaluation
@@ -161,7 +467,7 @@ func TestRunner_RunCodexOnly_Success(t *testing.T) {
 		{Output: "found issue"},
 	})
 
-	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
+	cfg := processor.Config{Mode: processor.ModeExternalOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
 	err := r.Run(t.Context())
 
@@ -169,7 +475,7 @@ func TestRunner_RunCodexOnly_Success(t *testing.T) {
 	assert.Len(t, codex.RunCalls(), 1)
 }
 
-func TestRunner_RunCodexOnly_NoFindings(t *testing.T) {
+func TestRunner_RunExternalOnly_NoFindings(t *testing.T) {
 	log := newMockLogger("progress.txt")
 	claude := newMockExecutor([]executor.Result{
 		{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop
@@ -178,13 +484,205 @@ func TestRunner_RunCodexOnly_NoFindings(t *testing.T) {
 		{Output: ""}, // no findings
 	})
 
-	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
+	cfg := processor.Config{Mode: processor.ModeExternalOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
 	err := r.Run(t.Context())
 
 	require.NoError(t, err)
 }
 
+func TestRunner_RunExternalOnly_BlameHints(t *testing.T) {
+	codexOutput := "- [P1] nil deref in pkg/git/service.go:42\n- [P2] new helper in pkg/new.go:3\n" +
+		"- [P2] same line again pkg/git/service.go:42\n- [P3] uncommitted main.go:7"
+	blame := func(file string, line int) (string, string, error) {
+		switch file {
+		case "pkg/git/service.go":
+			return "Jane Doe", "abc1234", nil
+		case "main.go":
+			return "", "", nil // not committed yet
+		}
+		return "", "", errors.New("no such path in HEAD")
+	}
+
+	tests := []struct {
+		name      string
+		enabled   bool
+		wantHints []string
+	}{
+		{name: "enabled", enabled: true, wantHints: []string{"  pkg/git/service.go:42 last changed by Jane Doe (abc1234)"}},
+		{name: "disabled", enabled: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var aligned []string
+			log := newMockLogger("progress.txt")
+			log.PrintAlignedFunc = func(text string) { aligned = append(aligned, text) }
+			claude := newMockExecutor([]executor.Result{
+				{Output: "done", Signal: status.CodexDone},
+				{Output: "review done", Signal: status.ReviewDone},
+			})
+			codex := newMockExecutor([]executor.Result{{Output: codexOutput}})
+			gitMock := &mocks.GitCheckerMock{
+				HeadHashFunc:        func() (string, error) { return "abc123", nil },
+				DiffFingerprintFunc: func() (string, error) { return "diff", nil },
+				BlameLineFunc:       blame,
+			}
+
+			appCfg := testAppConfig(t)
+			appCfg.CodexBlameHints = tc.enabled
+			cfg := processor.Config{Mode: processor.ModeExternalOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
+			r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
+			r.SetGitChecker(gitMock)
+			require.NoError(t, r.Run(t.Context()))
+
+			var hints []string
+			for _, line := range aligned {
+				if strings.Contains(line, "last changed by") {
+					hints = append(hints, line)
+				}
+			}
+			assert.Equal(t, tc.wantHints, hints)
+			if !tc.enabled {
+				assert.Empty(t, gitMock.BlameLineCalls())
+				return
+			}
+			assert.Len(t, gitMock.BlameLineCalls(), 3, "duplicate references are blamed once")
+		})
+	}
+}
+
+func TestRunner_RunExternalOnly_FailOnP1(t *testing.T) {
+	t.Run("P1 finding aborts before claude evaluation", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor(nil)
+		codex := newMockExecutor([]executor.Result{
+			{Output: "- [P1] SQL injection in query builder — pkg/db/query.go:42-48\n  user input is concatenated\n- [P3] typo — README.md:3"},
+		})
+
+		cfg := processor.Config{Mode: processor.ModeExternalOnly, MaxIterations: 50, CodexEnabled: true, FailOnP1: true,
+			AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
+		err := r.Run(t.Context())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "codex reported 1 P1 finding(s)")
+		assert.Contains(t, err.Error(), "[P1] SQL injection in query builder — pkg/db/query.go:42-48")
+		assert.Contains(t, err.Error(), "user input is concatenated")
+		assert.NotContains(t, err.Error(), "typo")
+		assert.Empty(t, claude.RunCalls(), "claude evaluation must be skipped")
+	})
+
+	t.Run("lower priority findings proceed", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "done", Signal: status.CodexDone},
+			{Output: "review done", Signal: status.ReviewDone},
+		})
+		codex := newMockExecutor([]executor.Result{{Output: "- [P2] missing test — pkg/db/query.go:10"}})
+
+		cfg := processor.Config{Mode: processor.ModeExternalOnly, MaxIterations: 50, CodexEnabled: true, FailOnP1: true,
+			AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
+		require.NoError(t, r.Run(t.Context()))
+		assert.NotEmpty(t, claude.RunCalls())
+	})
+
+	t.Run("disabled lets claude fix P1", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "done", Signal: status.CodexDone},
+			{Output: "review done", Signal: status.ReviewDone},
+		})
+		codex := newMockExecutor([]executor.Result{{Output: "- [P1] nil deref — main.go:7"}})
+
+		cfg := processor.Config{Mode: processor.ModeExternalOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
+		require.NoError(t, r.Run(t.Context()))
+	})
+}
+
+func TestRunner_RunExternalOnly_CodexReviewers(t *testing.T) {
+	claude := newMockExecutor([]executor.Result{
+		{Output: "done", Signal: status.CodexDone},
+		{Output: "review done", Signal: status.ReviewDone},
+	})
+	codex := newMockExecutor([]executor.Result{{Output: "- [P2] broad finding — a.go:1"}, {Output: ""}})
+	security := newMockExecutor([]executor.Result{{Output: "- [P2] token logged — auth.go:9\n"}})
+
+	appCfg := testAppConfig(t)
+	appCfg.CodexReviewers = []config.CodexReviewer{
+		{Name: "broad"},
+		{Name: "security", ProjectDoc: "docs/security.md", Prompt: "security review: {{DIFF_INSTRUCTION}}"},
+		{Name: "quiet"},
+	}
+	cfg := processor.Config{Mode: processor.ModeExternalOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), processor.Executors{Claude: claude, Codex: codex,
+		CodexByReviewer: map[string]processor.Executor{"security": security}}, &status.PhaseHolder{})
+	require.NoError(t, r.Run(t.Context()))
+
+	// broad and quiet run on the default codex executor, security on its own
+	require.Len(t, codex.RunCalls(), 2)
+	require.Len(t, security.RunCalls(), 1)
+	assert.Contains(t, codex.RunCalls()[0].Prompt, "Review the code changes", "no reviewer prompt uses codex_review")
+	assert.True(t, strings.HasPrefix(security.RunCalls()[0].Prompt, "security review: git diff"), "reviewer prompt expanded")
+
+	// one evaluation with the findings attributed to each reviewer, the reviewer without output left out
+	evalPrompt := claude.RunCalls()[0].Prompt
+	assert.Contains(t, evalPrompt, "--- reviewer: broad ---\n- [P2] broad finding — a.go:1\n\n--- reviewer: security ---\n- [P2] token logged — auth.go:9")
+	assert.NotContains(t, evalPrompt, "reviewer: quiet")
+}
+
+func TestRunner_CodexReviewerConditions(t *testing.T) {
+	claude := newMockExecutor([]executor.Result{
+		{Output: "done", Signal: status.CodexDone},
+		{Output: "review done", Signal: status.ReviewDone},
+	})
+	codex := newMockExecutor([]executor.Result{{Output: "- [P2] broad finding — pkg/api/handler.go:1"}})
+	security := newMockExecutor(nil)
+
+	appCfg := testAppConfig(t)
+	appCfg.CodexReviewers = []config.CodexReviewer{{Name: "broad"}, {Name: "security"}, {Name: "big"}, {Name: "full-only"}}
+	appCfg.CodexReviewerConditions = map[string]config.ReviewerConditions{
+		"security":  {Paths: []string{"auth/**/*.go"}},
+		"big":       {MinDiffLines: 500},
+		"full-only": {Modes: []string{"full"}},
+	}
+	gitMock := &mocks.GitCheckerMock{
+		HeadHashFunc:        func() (string, error) { return "abc123", nil },
+		DiffFingerprintFunc: func() (string, error) { return "diff", nil },
+		CommitLogFunc:       func(string) ([]string, error) { return nil, nil },
+		DiffStatsFunc: func(string, ...string) (git.DiffStats, error) {
+			return git.DiffStats{Files: 2, Additions: 40, Deletions: 2}, nil
+		},
+		DiffStatsPerFileFunc: func(string, ...string) ([]git.FileDiffStat, error) {
+			return []git.FileDiffStat{{Path: "pkg/api/handler.go"}, {Path: "docs/auth.md"}}, nil
+		},
+	}
+
+	log := newMockLogger("progress.txt")
+	cfg := processor.Config{Mode: processor.ModeExternalOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex,
+		CodexByReviewer: map[string]processor.Executor{"security": security}}, &status.PhaseHolder{})
+	r.SetGitChecker(gitMock)
+	require.NoError(t, r.Run(t.Context()))
+
+	require.Len(t, codex.RunCalls(), 1, "only broad runs, big and full-only are skipped")
+	assert.Empty(t, security.RunCalls(), "security skipped, no changed file matches its paths")
+	assert.Len(t, gitMock.DiffStatsPerFileCalls(), 1, "changed files loaded once per round")
+
+	var skipped []string
+	for _, call := range log.PrintCalls() {
+		if strings.HasPrefix(call.Format, "skipping codex reviewer") {
+			skipped = append(skipped, fmt.Sprintf(call.Format, call.Args...))
+		}
+	}
+	assert.Equal(t, []string{
+		"skipping codex reviewer security: no changed files match auth/**/*.go",
+		"skipping codex reviewer big: diff has 42 changed lines, below 500",
+		"skipping codex reviewer full-only: not enabled in external-only mode",
+	}, skipped)
+}
+
 func TestRunner_MaxExternalIterations_ExplicitLimit(t *testing.T) {
 	log := newMockLogger("progress.txt")
 	// codex loop: 2 iterations (each = codex + claude eval), then post-codex review
@@ -199,7 +697,7 @@ func TestRunner_MaxExternalIterations_ExplicitLimit(t *testing.T) {
 	})
 
 	cfg := processor.Config{
-		Mode: processor.ModeCodexOnly, MaxIterations: 50, IterationDelayMs: 1,
+		Mode: processor.ModeExternalOnly, MaxIterations: 50, IterationDelayMs: 1,
 		MaxExternalIterations: 2, CodexEnabled: true, AppConfig: testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
@@ -209,6 +707,115 @@ func TestRunner_MaxExternalIterations_ExplicitLimit(t *testing.T) {
 	assert.Len(t, codex.RunCalls(), 2, "codex should be called exactly MaxExternalIterations times")
 }
 
+func TestRunner_ExternalReviewLoop_RoundsLogged(t *testing.T) {
+	tests := []struct {
+		name      string
+		evals     []executor.Result
+		wantLogs  []string
+		wantCodex int
+	}{
+		{
+			name: "clean after second round",
+			evals: []executor.Result{
+				{Output: "fixed"},
+				{Output: "all clean", Signal: status.CodexDone},
+			},
+			wantLogs: []string{
+				"codex round 1/3 done, findings addressed, re-running review",
+				"codex review complete - no more findings after 2 round(s)",
+			},
+			wantCodex: 2,
+		},
+		{
+			name: "cap reached",
+			evals: []executor.Result{
+				{Output: "fixed"},
+				{Output: "fixed"},
+				{Output: "fixed"},
+			},
+			wantLogs: []string{
+				"codex round 1/3 done, findings addressed, re-running review",
+				"codex round 2/3 done, findings addressed, re-running review",
+				"max codex iterations reached (3 rounds) without a clean review, continuing to next phase...",
+			},
+			wantCodex: 3,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := newMockLogger("progress.txt")
+			claude := newMockExecutor(append(tc.evals, executor.Result{Output: "review done", Signal: status.ReviewDone}))
+			codex := newMockExecutor([]executor.Result{{Output: "issue 1"}, {Output: "issue 2"}, {Output: "issue 3"}})
+
+			cfg := processor.Config{
+				Mode: processor.ModeExternalOnly, MaxIterations: 50, IterationDelayMs: 1,
+				MaxExternalIterations: 3, CodexEnabled: true, AppConfig: testAppConfig(t),
+			}
+			r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
+			require.NoError(t, r.Run(t.Context()))
+			assert.Len(t, codex.RunCalls(), tc.wantCodex)
+
+			var logs []string
+			for _, call := range log.PrintCalls() {
+				logs = append(logs, fmt.Sprintf(call.Format, call.Args...))
+			}
+			for _, want := range tc.wantLogs {
+				assert.Contains(t, logs, want)
+			}
+		})
+	}
+}
+
+func TestRunner_MaxExternalRounds(t *testing.T) {
+	t.Run("oscillating review stops at the cap", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		evals := make([]executor.Result, 0, 20)
+		findings := make([]executor.Result, 0, 20)
+		for i := range 20 {
+			evals = append(evals, executor.Result{Output: "fixed it"})
+			findings = append(findings, executor.Result{Output: fmt.Sprintf("- [P2] new issue %d — a.go:%d", i+1, i+1)})
+		}
+		claude := newMockExecutor(evals)
+		codex := newMockExecutor(findings)
+
+		appCfg := testAppConfig(t)
+		appCfg.MaxExternalRounds = 3
+		cfg := processor.Config{
+			Mode: processor.ModeExternalOnly, MaxIterations: 50, IterationDelayMs: 1,
+			MaxExternalIterations: 20, CodexEnabled: true, AppConfig: appCfg,
+		}
+		r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
+		err := r.Run(t.Context())
+
+		require.ErrorIs(t, err, processor.ErrExternalReviewNotConverged)
+		assert.Contains(t, err.Error(), "external review did not converge after 3 codex round(s), max_external_rounds is 3")
+		assert.Contains(t, err.Error(), "last findings:\n- [P2] new issue 3 — a.go:3")
+		assert.Len(t, codex.RunCalls(), 3, "no review round past the cap")
+		assert.Len(t, claude.RunCalls(), 3)
+	})
+
+	t.Run("converging review within the cap", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{
+			{Output: "fixed it"},
+			{Output: "all clean", Signal: status.CodexDone},
+			{Output: "review done", Signal: status.ReviewDone},
+		})
+		codex := newMockExecutor([]executor.Result{{Output: "issue 1"}, {Output: "issue 2"}})
+
+		appCfg := testAppConfig(t)
+		appCfg.MaxExternalRounds = 2
+		cfg := processor.Config{
+			Mode: processor.ModeExternalOnly, MaxIterations: 50, IterationDelayMs: 1,
+			MaxExternalIterations: 20, CodexEnabled: true, AppConfig: appCfg,
+		}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), processor.Executors{Claude: claude, Codex: codex},
+			&status.PhaseHolder{})
+		require.NoError(t, r.Run(t.Context()))
+		assert.Len(t, codex.RunCalls(), 2)
+	})
+}
+
 func TestRunner_MaxExternalIterations_DerivedFormula(t *testing.T) {
 	log := newMockLogger("progress.txt")
 	// with MaxIterations=15 and MaxExternalIterations=0 (auto): derived = max(3, 15/5) = 3
@@ -225,7 +832,7 @@ func TestRunner_MaxExternalIterations_DerivedFormula(t *testing.T) {
 	})
 
 	cfg := processor.Config{
-		Mode: processor.ModeCodexOnly, MaxIterations: 15, IterationDelayMs: 1,
+		Mode: processor.ModeExternalOnly, MaxIterations: 15, IterationDelayMs: 1,
 		MaxExternalIterations: 0, CodexEnabled: true, AppConfig: testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
@@ -242,7 +849,7 @@ func TestRunner_CodexDisabled_SkipsCodexPhase(t *testing.T) {
 	})
 	codex := newMockExecutor(nil)
 
-	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: false, AppConfig: testAppConfig(t)}
+	cfg := processor.Config{Mode: processor.ModeExternalOnly, MaxIterations: 50, CodexEnabled: false, AppConfig: testAppConfig(t)}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
 	err := r.Run(t.Context())
 
@@ -269,6 +876,65 @@ func TestRunner_RunTasksOnly_Success(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, codex.RunCalls(), "codex should not be called in tasks-only mode")
 	assert.Len(t, claude.RunCalls(), 1)
+	require.Len(t, log.StartTaskCalls(), 1)
+	assert.Equal(t, 1, log.StartTaskCalls()[0].Task)
+}
+
+func TestRunner_TaskPhase_PlanEditedMidRun(t *testing.T) {
+	// run writes plans[i] after the i-th claude call; the last call signals completion
+	run := func(t *testing.T, initial string, plans []string) (*mocks.LoggerMock, error) {
+		t.Helper()
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte(initial), 0o600))
+		idx := 0
+		claude := &mocks.ExecutorMock{RunFunc: func(_ context.Context, _ string) executor.Result {
+			require.Less(t, idx, len(plans), "unexpected claude call")
+			require.NoError(t, os.WriteFile(planFile, []byte(plans[idx]), 0o600))
+			idx++
+			if idx == len(plans) {
+				return executor.Result{Output: "done", Signal: status.Completed}
+			}
+			return executor.Result{Output: "task done"}
+		}}
+		log := newMockLogger("progress.txt")
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: newMockExecutor(nil)}, &status.PhaseHolder{})
+		return log, r.Run(t.Context())
+	}
+	printed := func(log *mocks.LoggerMock) []string {
+		var res []string
+		for _, c := range log.PrintCalls() {
+			res = append(res, fmt.Sprintf(c.Format, c.Args...))
+		}
+		return res
+	}
+
+	t.Run("picks up an inserted task", func(t *testing.T) {
+		log, err := run(t, "# Plan\n### Task 1: one\n- [ ] a\n### Task 2: two\n- [ ] b\n", []string{
+			"# Plan\n### Task 1: one\n- [x] a\n### Task 1.5: hotfix\n- [ ] fix\n### Task 2: two\n- [ ] b\n",
+			"# Plan\n### Task 1: one\n- [x] a\n### Task 1.5: hotfix\n- [x] fix\n### Task 2: two\n- [ ] b\n",
+			"# Plan\n### Task 1: one\n- [x] a\n### Task 1.5: hotfix\n- [x] fix\n### Task 2: two\n- [x] b\n",
+		})
+		require.NoError(t, err)
+
+		var tasks []int
+		for _, c := range log.StartTaskCalls() {
+			tasks = append(tasks, c.Task)
+		}
+		assert.Equal(t, []int{1, 2, 3}, tasks, "inserted task is worked on before task 2")
+		assert.Contains(t, printed(log), "plan task structure changed: 3 tasks, was 2 (added Task: hotfix)")
+	})
+
+	t.Run("reports renumbered tasks", func(t *testing.T) {
+		log, err := run(t, "# Plan\n### Task 1: one\n- [ ] a\n", []string{
+			"# Plan\n### Task 1: setup\n- [ ] s\n### Task 2: one\n- [x] a\n",
+			"# Plan\n### Task 1: setup\n- [x] s\n### Task 2: one\n- [x] a\n",
+		})
+		require.NoError(t, err)
+		assert.Len(t, log.StartTaskCalls(), 2)
+		assert.Contains(t, printed(log), "plan task structure changed: 2 tasks, was 1 (added Task 1: setup, 1 renumbered)")
+	})
 }
 
 func TestRunner_RunTasksOnly_NoPlanFile(t *testing.T) {
@@ -371,6 +1037,47 @@ func TestRunner_TaskPhase_MaxIterations(t *testing.T) {
 	assert.Contains(t, err.Error(), "max iterations")
 }
 
+func TestRunner_TaskPhase_EmptyOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+
+	tests := []struct {
+		name      string
+		limit     int
+		results   []executor.Result
+		wantErr   string
+		wantCalls int
+	}{
+		{name: "fails after limit", limit: 3,
+			results: []executor.Result{{Output: ""}, {Output: "  \n"}, {Output: ""}, {Output: ""}, {Output: ""}},
+			wantErr: "claude produced no output in 3 consecutive iterations", wantCalls: 3},
+		{name: "non-empty output resets counter", limit: 2,
+			results: []executor.Result{{Output: ""}, {Output: "working..."}, {Output: ""}, {Output: ""}, {Output: ""}},
+			wantErr: "claude produced no output in 2 consecutive iterations", wantCalls: 4},
+		{name: "disabled loops until max iterations", limit: 0,
+			results: []executor.Result{{Output: ""}, {Output: ""}, {Output: ""}, {Output: ""}, {Output: ""}},
+			wantErr: "max iterations (5) reached", wantCalls: 5},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := newMockLogger("progress.txt")
+			claude := newMockExecutor(tc.results)
+			codex := newMockExecutor(nil)
+
+			cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+				EmptyIterationLimit: tc.limit, AppConfig: testAppConfig(t)}
+			r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
+			err := r.Run(t.Context())
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+			assert.Len(t, claude.RunCalls(), tc.wantCalls)
+		})
+	}
+}
+
 func TestRunner_TaskPhase_ContextCanceled(t *testing.T) {
 	tmpDir := t.TempDir()
 	planFile := filepath.Join(tmpDir, "plan.md")
@@ -406,6 +1113,53 @@ func TestRunner_ClaudeReview_FailedSignal(t *testing.T) {
 	assert.Contains(t, err.Error(), "FAILED signal")
 }
 
+func TestRunner_ReviewRetryCount(t *testing.T) {
+	t.Run("failed then succeeding reviews", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "error", Signal: status.Failed},    // first review
+			{Output: "error", Signal: status.Failed},    // first review, retry 1
+			{Output: "done", Signal: status.ReviewDone}, // first review, retry 2
+			{Output: "error", Signal: status.Failed},    // review loop
+			{Output: "done", Signal: status.ReviewDone}, // review loop, retry 1
+			{Output: "done", Signal: status.ReviewDone}, // post-codex review loop
+		})
+
+		cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, ReviewRetryCount: 2, IterationDelayMs: 1,
+			AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: newMockExecutor(nil)},
+			&status.PhaseHolder{})
+		require.NoError(t, r.Run(t.Context()))
+		assert.Len(t, claude.RunCalls(), 6)
+
+		var retries []string
+		for _, c := range log.PrintCalls() {
+			if msg := fmt.Sprintf(c.Format, c.Args...); strings.HasPrefix(msg, "review failed, retrying") {
+				retries = append(retries, msg)
+			}
+		}
+		assert.Equal(t, []string{"review failed, retrying (1/2)...", "review failed, retrying (2/2)...",
+			"review failed, retrying (1/2)..."}, retries, "retry count resets after a review that didn't fail")
+	})
+
+	t.Run("fails after retries are used up", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "error", Signal: status.Failed},
+			{Output: "error", Signal: status.Failed},
+		})
+
+		cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, ReviewRetryCount: 1, IterationDelayMs: 1,
+			AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: newMockExecutor(nil)},
+			&status.PhaseHolder{})
+		err := r.Run(t.Context())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "FAILED signal")
+		assert.Len(t, claude.RunCalls(), 2, "initial review + 1 retry")
+	})
+}
+
 func TestRunner_CodexPhase_Error(t *testing.T) {
 	log := newMockLogger("progress.txt")
 	claude := newMockExecutor([]executor.Result{
@@ -610,6 +1364,82 @@ func TestRunner_BuildCodexPrompt_CompletedDir(t *testing.T) {
 	assert.NotContains(t, prompt, originalPath)
 }
 
+func TestRunner_TaskPhase_NeedsInput(t *testing.T) {
+	needsInput := "checked the config\n<<<RALPHEX:NEEDS_INPUT>>>\n" +
+		`{"question": "Keep the v1 endpoint?", "options": ["keep", "remove"]}` + "\n<<<RALPHEX:END>>>"
+
+	newRunner := func(t *testing.T, claude *mocks.ExecutorMock) (*processor.Runner, *mocks.LoggerMock) {
+		t.Helper()
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+		log := newMockLogger("progress.txt")
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10,
+			IterationDelayMs: 1, AppConfig: testAppConfig(t)}
+		return processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: newMockExecutor(nil)},
+			&status.PhaseHolder{}), log
+	}
+
+	t.Run("answer is fed into the next prompt", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{
+			{Output: needsInput},
+			{Output: "done", Signal: status.Completed},
+		})
+		r, log := newRunner(t, claude)
+		collector := newMockInputCollector([]string{"remove"})
+		r.SetInputCollector(collector)
+
+		require.NoError(t, r.Run(t.Context()))
+
+		require.Len(t, collector.AskQuestionCalls(), 1)
+		assert.Equal(t, "Keep the v1 endpoint?", collector.AskQuestionCalls()[0].Question)
+		assert.Equal(t, []string{"keep", "remove"}, collector.AskQuestionCalls()[0].Options)
+		require.Len(t, log.LogAnswerCalls(), 1)
+		assert.Equal(t, "remove", log.LogAnswerCalls()[0].Answer)
+
+		calls := claude.RunCalls()
+		require.Len(t, calls, 2)
+		assert.NotContains(t, calls[0].Prompt, "USER INPUT")
+		assert.Contains(t, calls[1].Prompt, "USER INPUT")
+		assert.Contains(t, calls[1].Prompt, "Question: Keep the v1 endpoint?\nAnswer: remove")
+	})
+
+	t.Run("answer is used once", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{
+			{Output: needsInput},
+			{Output: "working"},
+			{Output: "done", Signal: status.Completed},
+		})
+		r, _ := newRunner(t, claude)
+		r.SetInputCollector(newMockInputCollector([]string{"keep"}))
+
+		require.NoError(t, r.Run(t.Context()))
+		calls := claude.RunCalls()
+		require.Len(t, calls, 3)
+		assert.Contains(t, calls[1].Prompt, "Answer: keep")
+		assert.NotContains(t, calls[2].Prompt, "USER INPUT")
+	})
+
+	t.Run("fails without input collector", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{{Output: needsInput}})
+		r, _ := newRunner(t, claude)
+
+		err := r.Run(t.Context())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not interactive")
+		assert.Contains(t, err.Error(), "Keep the v1 endpoint?")
+	})
+
+	t.Run("collector error fails the run", func(t *testing.T) {
+		claude := newMockExecutor([]executor.Result{{Output: needsInput}})
+		r, _ := newRunner(t, claude)
+		r.SetInputCollector(newMockInputCollector(nil))
+
+		err := r.Run(t.Context())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "collect answer")
+	})
+}
+
 func TestRunner_TaskRetryCount_UsedCorrectly(t *testing.T) {
 	tmpDir := t.TempDir()
 	planFile := filepath.Join(tmpDir, "plan.md")
@@ -879,7 +1709,7 @@ func TestRunner_New_CodexNotInstalled_AutoDisables(t *testing.T) {
 	appCfg.CodexCommand = "/nonexistent/path/to/codex" // command that doesn't exist
 
 	cfg := processor.Config{
-		Mode:          processor.ModeCodexOnly,
+		Mode:          processor.ModeExternalOnly,
 		MaxIterations: 50,
 		CodexEnabled:  true,
 		AppConfig:     appCfg,
@@ -912,7 +1742,7 @@ func TestRunner_New_CodexNotInstalled_CustomReviewStillWorks(t *testing.T) {
 	appCfg.CustomReviewScript = "/path/to/script.sh"
 
 	cfg := processor.Config{
-		Mode:          processor.ModeCodexOnly,
+		Mode:          processor.ModeExternalOnly,
 		MaxIterations: 50,
 		CodexEnabled:  true,
 		AppConfig:     appCfg,
@@ -943,7 +1773,7 @@ func TestRunner_New_CodexNotInstalled_NoneReviewStillWorks(t *testing.T) {
 	appCfg.ExternalReviewTool = "none"                 // external review disabled
 
 	cfg := processor.Config{
-		Mode:          processor.ModeCodexOnly,
+		Mode:          processor.ModeExternalOnly,
 		MaxIterations: 50,
 		CodexEnabled:  true,
 		AppConfig:     appCfg,
@@ -1425,6 +2255,66 @@ func TestRunner_Finalize_RunsWhenEnabled(t *testing.T) {
 	assert.True(t, foundFinalizeSection, "should print finalize section header")
 }
 
+func TestRunner_InteractiveGates(t *testing.T) {
+	tests := []struct {
+		name        string
+		gates       bool
+		answers     []bool
+		wantErr     error
+		wantClaude  int
+		wantAsked   []string
+		noConfirmFn bool
+	}{
+		{name: "all approved", gates: true, answers: []bool{true, true}, wantClaude: 5,
+			wantAsked: []string{"proceed to claude review?", "proceed to finalize?"}},
+		{name: "declined before review", gates: true, answers: []bool{false}, wantErr: processor.ErrStoppedAtGate,
+			wantClaude: 1, wantAsked: []string{"proceed to claude review?"}},
+		{name: "declined before finalize", gates: true, answers: []bool{true, false}, wantErr: processor.ErrStoppedAtGate,
+			wantClaude: 4, wantAsked: []string{"proceed to claude review?", "proceed to finalize?"}},
+		{name: "gates disabled", gates: false, wantClaude: 5},
+		{name: "no confirm function", gates: true, noConfirmFn: true, wantClaude: 5},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			planFile := filepath.Join(t.TempDir(), "plan.md")
+			require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+			claude := newMockExecutor([]executor.Result{
+				{Output: "task done", Signal: status.Completed},
+				{Output: "review done", Signal: status.ReviewDone},
+				{Output: "review done", Signal: status.ReviewDone},
+				{Output: "review done", Signal: status.ReviewDone},
+				{Output: "finalize done"},
+			})
+			cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50,
+				FinalizeEnabled: true, InteractiveGates: tc.gates, AppConfig: testAppConfig(t)}
+			r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"),
+				processor.Executors{Claude: claude, Codex: newMockExecutor(nil)}, &status.PhaseHolder{})
+
+			var asked []string
+			if !tc.noConfirmFn {
+				r.SetConfirm(func(_ context.Context, q string) bool {
+					asked = append(asked, q)
+					if len(asked) > len(tc.answers) {
+						t.Fatalf("unexpected question %q", q)
+					}
+					return tc.answers[len(asked)-1]
+				})
+			}
+
+			err := r.Run(t.Context())
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Len(t, claude.RunCalls(), tc.wantClaude)
+			assert.Equal(t, tc.wantAsked, asked)
+		})
+	}
+}
+
 func TestRunner_Finalize_SkippedWhenDisabled(t *testing.T) {
 	tmpDir := t.TempDir()
 	planFile := filepath.Join(tmpDir, "plan.md")
@@ -1455,6 +2345,58 @@ func TestRunner_Finalize_SkippedWhenDisabled(t *testing.T) {
 	assert.Len(t, claude.RunCalls(), 4)
 }
 
+func TestRunner_Finalize_NoCommit(t *testing.T) {
+	run := func(t *testing.T, noCommit, commits bool) (*mocks.ExecutorMock, *mocks.LoggerMock) {
+		t.Helper()
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+		head := 0
+		claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			if commits {
+				head++
+			}
+			return executor.Result{Output: "finalized"}
+		}}
+		appCfg := testAppConfig(t)
+		appCfg.FinalizeNoCommit = noCommit
+		log := newMockLogger("progress.txt")
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, FinalizeEnabled: true,
+			StartPhase: processor.StartFinalize, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: newMockExecutor(nil)}, &status.PhaseHolder{})
+		r.SetGitChecker(&mocks.GitCheckerMock{HeadHashFunc: func() (string, error) { return fmt.Sprintf("h%d", head), nil }})
+		require.NoError(t, r.Run(t.Context()))
+		require.Len(t, claude.RunCalls(), 1)
+		return claude, log
+	}
+	logged := func(log *mocks.LoggerMock, part string) bool {
+		for _, call := range log.PrintCalls() {
+			if strings.Contains(call.Format, part) {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("prompt asks to stage without committing", func(t *testing.T) {
+		claude, log := run(t, true, false)
+		assert.Contains(t, claude.RunCalls()[0].Prompt, "Do NOT commit, rebase or rewrite history")
+		assert.True(t, logged(log, "changes left staged for review"))
+		assert.False(t, logged(log, "despite finalize_no_commit"))
+	})
+
+	t.Run("warns when finalize committed anyway", func(t *testing.T) {
+		_, log := run(t, true, true)
+		assert.True(t, logged(log, "despite finalize_no_commit"))
+	})
+
+	t.Run("default prompt is unchanged", func(t *testing.T) {
+		claude, log := run(t, false, true)
+		assert.NotContains(t, claude.RunCalls()[0].Prompt, "Do NOT commit")
+		assert.False(t, logged(log, "despite finalize_no_commit"))
+	})
+}
+
 func TestRunner_Finalize_FailureDoesNotBlockSuccess(t *testing.T) {
 	tmpDir := t.TempDir()
 	planFile := filepath.Join(tmpDir, "plan.md")
@@ -1560,7 +2502,7 @@ func TestRunner_Finalize_RunsInReviewOnlyMode(t *testing.T) {
 	assert.Len(t, claude.RunCalls(), 4)
 }
 
-func TestRunner_Finalize_RunsInCodexOnlyMode(t *testing.T) {
+func TestRunner_Finalize_RunsInExternalOnlyMode(t *testing.T) {
 	log := newMockLogger("progress.txt")
 	claude := newMockExecutor([]executor.Result{
 		{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop (codex disabled)
@@ -1569,7 +2511,7 @@ func TestRunner_Finalize_RunsInCodexOnlyMode(t *testing.T) {
 	codex := newMockExecutor(nil)
 
 	cfg := processor.Config{
-		Mode:            processor.ModeCodexOnly,
+		Mode:            processor.ModeExternalOnly,
 		MaxIterations:   50,
 		CodexEnabled:    false,
 		FinalizeEnabled: true,
@@ -1596,7 +2538,7 @@ func TestRunner_CodexAndPostReview_PipelineOrder(t *testing.T) {
 	}{
 		{
 			name: "codex-only runs codex then review then finalize",
-			mode: processor.ModeCodexOnly,
+			mode: processor.ModeExternalOnly,
 			claudeResults: []executor.Result{
 				{Output: "done", Signal: status.CodexDone},         // codex evaluation
 				{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop
@@ -1688,7 +2630,7 @@ func TestRunner_CodexAndPostReview_CommitPendingPrefix(t *testing.T) {
 		}
 		codex := newMockExecutor([]executor.Result{{Output: "found issue"}})
 
-		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
+		cfg := processor.Config{Mode: processor.ModeExternalOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
 		r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
 		err := r.Run(t.Context())
 
@@ -1710,7 +2652,7 @@ func TestRunner_CodexAndPostReview_CommitPendingPrefix(t *testing.T) {
 		}
 		codex := newMockExecutor(nil)
 
-		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: false, AppConfig: testAppConfig(t)}
+		cfg := processor.Config{Mode: processor.ModeExternalOnly, MaxIterations: 50, CodexEnabled: false, AppConfig: testAppConfig(t)}
 		r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
 		err := r.Run(t.Context())
 
@@ -1760,7 +2702,7 @@ func TestRunner_ExternalReviewTool_CodexEnabled(t *testing.T) {
 	appCfg.ExternalReviewTool = "codex"
 
 	cfg := processor.Config{
-		Mode:          processor.ModeCodexOnly,
+		Mode:          processor.ModeExternalOnly,
 		MaxIterations: 50,
 		CodexEnabled:  true,
 		AppConfig:     appCfg,
@@ -1783,7 +2725,7 @@ func TestRunner_ExternalReviewTool_None(t *testing.T) {
 	appCfg.ExternalReviewTool = "none"
 
 	cfg := processor.Config{
-		Mode:          processor.ModeCodexOnly,
+		Mode:          processor.ModeExternalOnly,
 		MaxIterations: 50,
 		CodexEnabled:  true, // enabled but tool is none
 		AppConfig:     appCfg,
@@ -1807,7 +2749,7 @@ func TestRunner_ExternalReviewTool_BackwardCompat_CodexDisabled(t *testing.T) {
 	appCfg.ExternalReviewTool = "codex"
 
 	cfg := processor.Config{
-		Mode:          processor.ModeCodexOnly,
+		Mode:          processor.ModeExternalOnly,
 		MaxIterations: 50,
 		CodexEnabled:  false, // this should override external_review_tool
 		AppConfig:     appCfg,
@@ -1852,7 +2794,7 @@ func TestRunner_ExternalReviewTool_Custom_Success(t *testing.T) {
 	customExec.SetRunner(mockCustomRunner)
 
 	cfg := processor.Config{
-		Mode:          processor.ModeCodexOnly,
+		Mode:          processor.ModeExternalOnly,
 		MaxIterations: 50,
 		CodexEnabled:  true,
 		AppConfig:     appCfg,
@@ -1891,7 +2833,7 @@ func TestRunner_ExternalReviewTool_Custom_NoDuplicateOutput(t *testing.T) {
 	}
 	customExec.SetRunner(mockCustomRunner)
 
-	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
+	cfg := processor.Config{Mode: processor.ModeExternalOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex, Custom: customExec}, &status.PhaseHolder{})
 	err := r.Run(t.Context())
 	require.NoError(t, err)
@@ -1916,7 +2858,7 @@ func TestRunner_ExternalReviewTool_Custom_NotConfigured(t *testing.T) {
 	// CustomReviewScript is not set
 
 	cfg := processor.Config{
-		Mode:          processor.ModeCodexOnly,
+		Mode:          processor.ModeExternalOnly,
 		MaxIterations: 50,
 		CodexEnabled:  true,
 		AppConfig:     appCfg,
@@ -2152,6 +3094,23 @@ func TestRunner_NextPlanTaskPosition(t *testing.T) {
 	}
 }
 
+func TestRunner_NextPlanTaskPosition_TaskOrder(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	content := "# Plan\n### Task 1: setup\n- [x] done\n### Task 3: tests\n- [ ] test\n### Task 2: build\n- [ ] build it"
+	require.NoError(t, os.WriteFile(planFile, []byte(content), 0o600))
+
+	for order, expected := range map[string]int{"sequential": 2, "as-listed": 2, "by-number": 3} {
+		t.Run(order, func(t *testing.T) {
+			appCfg := testAppConfig(t)
+			appCfg.TaskOrder = order
+			cfg := processor.Config{PlanFile: planFile, AppConfig: appCfg}
+			r := processor.NewWithExecutors(cfg, newMockLogger(""), processor.Executors{Claude: newMockExecutor(nil),
+				Codex: newMockExecutor(nil)}, &status.PhaseHolder{})
+			assert.Equal(t, expected, r.TestNextPlanTaskPosition())
+		})
+	}
+}
+
 func TestRunner_NextPlanTaskPosition_MissingFile(t *testing.T) {
 	log := newMockLogger("")
 	claude := newMockExecutor(nil)
@@ -2375,6 +3334,41 @@ func TestRunner_RunWithLimitRetry_MultipleRetries(t *testing.T) {
 	assert.Equal(t, 4, callCount, "should retry multiple times until success")
 }
 
+func TestRunner_RunWithLimitRetry_MaxRetriesExhausted(t *testing.T) {
+	log := newMockLogger("")
+	claude := newMockExecutor(nil)
+	codex := newMockExecutor(nil)
+
+	appCfg := testAppConfig(t)
+	appCfg.WaitOnLimit = time.Millisecond
+	appCfg.WaitOnLimitSet = true
+	appCfg.MaxLimitRetries = 2
+
+	cfg := processor.Config{AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
+	assert.Equal(t, 2, r.TestConfig().MaxLimitRetries)
+
+	callCount := 0
+	mockRun := func(_ context.Context, _ string) executor.Result {
+		callCount++
+		return executor.Result{Error: &executor.LimitPatternError{Pattern: "rate limit", HelpCmd: "claude /usage"}}
+	}
+
+	result := r.TestRunWithLimitRetry(t.Context(), mockRun, "test prompt", "claude")
+
+	require.ErrorIs(t, result.Error, executor.ErrUsageLimited)
+	assert.Equal(t, 3, callCount, "initial call plus two retries")
+
+	var foundLog bool
+	for _, call := range log.PrintCalls() {
+		if strings.Contains(call.Format, "giving up") {
+			foundLog = true
+			break
+		}
+	}
+	assert.True(t, foundLog, "should log that retries are exhausted")
+}
+
 func TestRunner_RunWithLimitRetry_NoErrorPassesThrough(t *testing.T) {
 	log := newMockLogger("")
 	claude := newMockExecutor(nil)
@@ -2401,6 +3395,33 @@ func TestRunner_RunWithLimitRetry_NoErrorPassesThrough(t *testing.T) {
 	assert.Equal(t, 1, callCount, "should not retry on success")
 }
 
+func TestRunner_RunWithLimitRetry_AmbiguousSignalWarns(t *testing.T) {
+	log := newMockLogger("")
+	claude := newMockExecutor(nil)
+	codex := newMockExecutor(nil)
+
+	cfg := processor.Config{AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
+
+	mockRun := func(_ context.Context, _ string) executor.Result {
+		return executor.Result{Output: "done", Signal: status.Completed, SignalAmbiguous: true}
+	}
+
+	result := r.TestRunWithLimitRetry(t.Context(), mockRun, "test prompt", "claude")
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, status.Completed, result.Signal)
+	assert.True(t, result.SignalAmbiguous)
+
+	var warned bool
+	for _, call := range log.PrintCalls() {
+		if strings.Contains(fmt.Sprintf(call.Format, call.Args...), "multiple distinct signals") {
+			warned = true
+		}
+	}
+	assert.True(t, warned, "expected ambiguity warning to be logged")
+}
+
 func TestRunner_WaitOnLimit_PopulatedFromConfig(t *testing.T) {
 	log := newMockLogger("")
 	claude := newMockExecutor(nil)
@@ -2453,7 +3474,7 @@ func TestRunner_Finalize_LimitPatternWithWaitRetries(t *testing.T) {
 	appCfg.WaitOnLimitSet = true
 
 	cfg := processor.Config{
-		Mode:            processor.ModeCodexOnly,
+		Mode:            processor.ModeExternalOnly,
 		MaxIterations:   50,
 		CodexEnabled:    false, // skip codex phase
 		FinalizeEnabled: true,
@@ -2483,7 +3504,7 @@ func TestRunner_Finalize_LimitPatternWithoutWaitLogsAndContinues(t *testing.T) {
 	codex := newMockExecutor(nil)
 
 	cfg := processor.Config{
-		Mode:            processor.ModeCodexOnly,
+		Mode:            processor.ModeExternalOnly,
 		MaxIterations:   50,
 		CodexEnabled:    false,
 		FinalizeEnabled: true,
@@ -2529,7 +3550,7 @@ func TestRunner_ExternalReviewLoop_StalemateDetection_BreaksAfterN(t *testing.T)
 	}
 
 	cfg := processor.Config{
-		Mode: processor.ModeCodexOnly, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
+		Mode: processor.ModeExternalOnly, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
 		ReviewPatience: 2, AppConfig: testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
@@ -2590,7 +3611,7 @@ func TestRunner_ExternalReviewLoop_StalemateDetection_ResetsOnCommit(t *testing.
 	}
 
 	cfg := processor.Config{
-		Mode: processor.ModeCodexOnly, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
+		Mode: processor.ModeExternalOnly, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
 		ReviewPatience: 2, AppConfig: testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
@@ -2652,7 +3673,7 @@ func TestRunner_ExternalReviewLoop_StalemateDetection_ResetsOnDiffChange(t *test
 	}
 
 	cfg := processor.Config{
-		Mode: processor.ModeCodexOnly, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
+		Mode: processor.ModeExternalOnly, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
 		ReviewPatience: 2, AppConfig: testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
@@ -2698,7 +3719,7 @@ func TestRunner_ExternalReviewLoop_StalemateDetection_DisabledWhenZero(t *testin
 	}
 
 	cfg := processor.Config{
-		Mode: processor.ModeCodexOnly, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
+		Mode: processor.ModeExternalOnly, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
 		ReviewPatience: 0, MaxExternalIterations: 3, AppConfig: testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
@@ -2740,7 +3761,7 @@ func TestRunner_ExternalReviewLoop_StalemateDetection_NilGitChecker(t *testing.T
 
 	// no git checker set (nil)
 	cfg := processor.Config{
-		Mode: processor.ModeCodexOnly, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
+		Mode: processor.ModeExternalOnly, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
 		ReviewPatience: 2, MaxExternalIterations: 3, AppConfig: testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
@@ -2783,7 +3804,7 @@ func TestRunner_ExternalReviewLoop_BreakChannel_ExitsEarly(t *testing.T) {
 	}
 
 	cfg := processor.Config{
-		Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true,
+		Mode: processor.ModeExternalOnly, MaxIterations: 50, CodexEnabled: true,
 		MaxExternalIterations: 5, AppConfig: testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
@@ -2822,7 +3843,7 @@ func TestRunner_ExternalReviewLoop_NilBreakChannel_RunsNormally(t *testing.T) {
 	})
 
 	cfg := processor.Config{
-		Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true,
+		Mode: processor.ModeExternalOnly, MaxIterations: 50, CodexEnabled: true,
 		MaxExternalIterations: 5, AppConfig: testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
@@ -3016,6 +4037,87 @@ func TestRunner_SessionTimeout_TaskPhaseContinues(t *testing.T) {
 	assert.True(t, foundLog, "should log session timeout warning")
 }
 
+func TestRunner_IdleTimeout_RetriesIteration(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	log := newMockLogger("progress.txt")
+	idleErr := fmt.Errorf("%w: no output for 10m0s", executor.ErrIdleTimeout)
+	claude := newMockExecutor([]executor.Result{
+		{Output: "partial", Error: idleErr},
+		{Output: "", Error: idleErr},
+		{Output: "task done", Signal: status.Completed},
+	})
+
+	appCfg := testAppConfig(t)
+	appCfg.EmptyIterationLimit = 1 // idle timeouts don't count as empty iterations
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1, AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: newMockExecutor(nil)}, &status.PhaseHolder{})
+
+	require.NoError(t, r.Run(t.Context()))
+	assert.Len(t, claude.RunCalls(), 3, "claude should be called again after each idle timeout")
+
+	var warnings int
+	for _, call := range log.PrintCalls() {
+		if strings.Contains(call.Format, "stopped producing output") {
+			warnings++
+		}
+	}
+	assert.Equal(t, 2, warnings)
+}
+
+func TestRunner_Heartbeat(t *testing.T) {
+	heartbeats := func(log *mocks.LoggerMock) []string {
+		var res []string
+		for _, call := range log.PrintCalls() {
+			if strings.HasPrefix(call.Format, "still working in") {
+				res = append(res, fmt.Sprintf(call.Format, call.Args...))
+			}
+		}
+		return res
+	}
+
+	t.Run("logs while the call runs and stops after it", func(t *testing.T) {
+		log := newMockLogger("")
+		appCfg := testAppConfig(t)
+		appCfg.HeartbeatInterval = 10 * time.Millisecond
+		holder := &status.PhaseHolder{}
+		holder.Set(status.PhaseReview)
+		r := processor.NewWithExecutors(processor.Config{AppConfig: appCfg}, log,
+			processor.Executors{Claude: newMockExecutor(nil), Codex: newMockExecutor(nil)}, holder)
+
+		slowRun := func(context.Context, string) executor.Result {
+			time.Sleep(55 * time.Millisecond)
+			return executor.Result{Output: "done"}
+		}
+		result := r.TestRunWithLimitRetry(t.Context(), slowRun, "prompt", "claude")
+		require.NoError(t, result.Error)
+
+		lines := heartbeats(log)
+		require.NotEmpty(t, lines)
+		assert.Equal(t, "still working in review (0s elapsed)", lines[0])
+		count := len(lines)
+		time.Sleep(30 * time.Millisecond)
+		assert.Len(t, heartbeats(log), count, "no heartbeat after the call returned")
+	})
+
+	t.Run("zero interval disables heartbeat", func(t *testing.T) {
+		log := newMockLogger("")
+		appCfg := testAppConfig(t)
+		appCfg.HeartbeatInterval = 0
+		r := processor.NewWithExecutors(processor.Config{AppConfig: appCfg}, log,
+			processor.Executors{Claude: newMockExecutor(nil), Codex: newMockExecutor(nil)}, &status.PhaseHolder{})
+
+		slowRun := func(context.Context, string) executor.Result {
+			time.Sleep(20 * time.Millisecond)
+			return executor.Result{Output: "done"}
+		}
+		r.TestRunWithLimitRetry(t.Context(), slowRun, "prompt", "claude")
+		assert.Empty(t, heartbeats(log))
+	})
+}
+
 func TestRunner_SessionTimeout_NonClaudeToolNotAffected(t *testing.T) {
 	log := newMockLogger("")
 	claude := newMockExecutor(nil)
@@ -3197,7 +4299,7 @@ func TestRunner_SessionTimeout_ExternalReviewLoopSkipsStalemateOnTimeout(t *test
 	appCfg.SessionTimeoutSet = true
 
 	cfg := processor.Config{
-		Mode: processor.ModeCodexOnly, MaxIterations: 50, IterationDelayMs: 1,
+		Mode: processor.ModeExternalOnly, MaxIterations: 50, IterationDelayMs: 1,
 		CodexEnabled: true, ReviewPatience: 2, AppConfig: appCfg,
 	}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
@@ -3307,7 +4409,7 @@ func TestRunner_SessionTimeout_ExternalReviewKeepsBranchDiffAfterTimeout(t *test
 	appCfg.SessionTimeoutSet = true
 
 	cfg := processor.Config{
-		Mode: processor.ModeCodexOnly, MaxIterations: 50, IterationDelayMs: 1,
+		Mode: processor.ModeExternalOnly, MaxIterations: 50, IterationDelayMs: 1,
 		CodexEnabled: true, AppConfig: appCfg,
 	}
 	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
@@ -3389,3 +4491,167 @@ func TestRunner_SessionTimeout_PlanCreationPreservesRevisionFeedback(t *testing.
 	assert.Contains(t, secondPrompt, "please add error handling task",
 		"revision feedback should be in the timed-out attempt too")
 }
+
+func TestRunner_MaxDiffLines(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       processor.Mode
+		maxLines   int
+		confirm    func(context.Context, string) bool
+		wantErr    string
+		wantClaude bool
+	}{
+		{name: "under limit runs", mode: processor.ModeReview, maxLines: 100, wantClaude: true},
+		{name: "no limit runs", mode: processor.ModeReview, maxLines: 0, wantClaude: true},
+		{name: "over limit aborts", mode: processor.ModeReview, maxLines: 10,
+			wantErr: "diff has 40 changed lines in 3 files, over the --max-diff-lines limit of 10"},
+		{name: "over limit aborts external-only", mode: processor.ModeExternalOnly, maxLines: 10,
+			wantErr: "over the --max-diff-lines limit of 10"},
+		{name: "confirmed runs", mode: processor.ModeReview, maxLines: 10,
+			confirm: func(context.Context, string) bool { return true }, wantClaude: true},
+		{name: "declined aborts", mode: processor.ModeReview, maxLines: 10,
+			confirm: func(context.Context, string) bool { return false }, wantErr: "over the --max-diff-lines limit"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+				return executor.Result{Output: "review done", Signal: status.ReviewDone}
+			}}
+			codex := newMockExecutor([]executor.Result{{Output: ""}})
+			gitMock := &mocks.GitCheckerMock{
+				HeadHashFunc:        func() (string, error) { return "abc123def456abc123def456abc123def456abcd", nil },
+				DiffFingerprintFunc: func() (string, error) { return "diff", nil },
+				DiffStatsFunc: func(string, ...string) (git.DiffStats, error) {
+					return git.DiffStats{Files: 3, Additions: 30, Deletions: 10}, nil
+				},
+			}
+
+			cfg := processor.Config{Mode: tc.mode, MaxIterations: 50, CodexEnabled: true, DefaultBranch: "main",
+				MaxDiffLines: tc.maxLines, AppConfig: testAppConfig(t)}
+			r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), processor.Executors{Claude: claude, Codex: codex},
+				&status.PhaseHolder{})
+			r.SetGitChecker(gitMock)
+			var asked []string
+			if tc.confirm != nil {
+				r.SetConfirm(func(ctx context.Context, q string) bool {
+					asked = append(asked, q)
+					return tc.confirm(ctx, q)
+				})
+			}
+
+			err := r.Run(t.Context())
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				assert.Empty(t, claude.RunCalls(), "no review before the diff check")
+				assert.Empty(t, codex.RunCalls())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.wantClaude, len(claude.RunCalls()) > 0)
+			}
+			if tc.confirm != nil {
+				require.Len(t, asked, 1)
+				assert.Contains(t, asked[0], "review it anyway?")
+			}
+		})
+	}
+}
+
+func TestRunner_CodexMinDiffLines(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         processor.Mode
+		minDiffLines int
+		stats        git.DiffStats
+		statsErr     error
+		wantCodex    bool
+		wantSkipLog  bool
+	}{
+		{name: "below threshold skips", mode: processor.ModeReview, minDiffLines: 20,
+			stats: git.DiffStats{Files: 1, Additions: 5, Deletions: 3}, wantSkipLog: true},
+		{name: "at threshold runs", mode: processor.ModeReview, minDiffLines: 20,
+			stats: git.DiffStats{Files: 2, Additions: 15, Deletions: 5}, wantCodex: true},
+		{name: "zero never skips", mode: processor.ModeReview, minDiffLines: 0,
+			stats: git.DiffStats{}, wantCodex: true},
+		{name: "codex-only bypasses gate", mode: processor.ModeExternalOnly, minDiffLines: 20,
+			stats: git.DiffStats{Files: 1, Additions: 1}, wantCodex: true},
+		{name: "diff stats error runs codex", mode: processor.ModeReview, minDiffLines: 20,
+			statsErr: errors.New("git failed"), wantCodex: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := newMockLogger("progress.txt")
+			claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+				return executor.Result{Output: "review done", Signal: status.ReviewDone}
+			}}
+			codex := newMockExecutor([]executor.Result{{Output: ""}}) // no findings
+			gitMock := &mocks.GitCheckerMock{
+				HeadHashFunc:        func() (string, error) { return "abc123def456abc123def456abc123def456abcd", nil },
+				DiffFingerprintFunc: func() (string, error) { return "diff", nil },
+				DiffStatsFunc:       func(string, ...string) (git.DiffStats, error) { return tc.stats, tc.statsErr },
+			}
+
+			appCfg := testAppConfig(t)
+			appCfg.CodexMinDiffLines = tc.minDiffLines
+			cfg := processor.Config{Mode: tc.mode, MaxIterations: 50, CodexEnabled: true, DefaultBranch: "main", AppConfig: appCfg}
+			r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: codex}, &status.PhaseHolder{})
+			r.SetGitChecker(gitMock)
+			require.NoError(t, r.Run(t.Context()))
+
+			if tc.wantCodex {
+				assert.Len(t, codex.RunCalls(), 1)
+			} else {
+				assert.Empty(t, codex.RunCalls())
+			}
+
+			var skipLogged bool
+			for _, call := range log.PrintCalls() {
+				if call.Format == "diff too small for external review, skipping codex" {
+					skipLogged = true
+				}
+			}
+			assert.Equal(t, tc.wantSkipLog, skipLogged)
+
+			if tc.minDiffLines == 0 || tc.mode == processor.ModeExternalOnly {
+				assert.Empty(t, gitMock.DiffStatsCalls(), "diff stats should not be queried")
+			} else {
+				require.Len(t, gitMock.DiffStatsCalls(), 1)
+				assert.Equal(t, "main", gitMock.DiffStatsCalls()[0].BaseBranch)
+			}
+		})
+	}
+}
+
+func TestRunner_Iterations(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	log := newMockLogger("progress.txt")
+	claude := newMockExecutor([]executor.Result{
+		{Output: "working"},
+		{Output: "broken", Signal: status.Failed},
+		{Output: "done", Signal: status.Completed},
+	})
+
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10,
+		IterationDelayMs: 1, TaskRetryCount: 1, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, processor.Executors{Claude: claude, Codex: newMockExecutor(nil)},
+		&status.PhaseHolder{})
+	require.NoError(t, r.Run(t.Context()))
+
+	recs := r.Iterations()
+	require.Len(t, recs, 3)
+	for i, rec := range recs {
+		assert.Equal(t, i+1, rec.Num)
+		assert.Equal(t, status.PhaseTask, rec.Phase)
+		assert.Equal(t, "claude", rec.Tool)
+		assert.Empty(t, rec.Error)
+	}
+	assert.Empty(t, recs[0].Signal)
+	assert.Equal(t, "TASK_FAILED", recs[1].Signal)
+	assert.Equal(t, "ALL_TASKS_DONE", recs[2].Signal)
+	assert.False(t, recs[1].Retry)
+	assert.True(t, recs[2].Retry, "run after FAILED is a retry")
+}
@@ -18,6 +18,7 @@ const (
 	SignalReviewDone = status.ReviewDone
 	SignalCodexDone  = status.CodexDone
 	SignalQuestion   = status.Question
+	SignalNeedsInput = status.NeedsInput
 	SignalPlanReady  = status.PlanReady
 	SignalPlanDraft  = status.PlanDraft
 )
@@ -25,10 +26,14 @@ const (
 // questionSignalRe matches the QUESTION signal block with JSON payload
 var questionSignalRe = regexp.MustCompile(`<<<RALPHEX:QUESTION>>>\s*([\s\S]*?)\s*<<<RALPHEX:END>>>`)
 
+// needsInputSignalRe matches the NEEDS_INPUT signal block with JSON payload
+var needsInputSignalRe = regexp.MustCompile(`<<<RALPHEX:NEEDS_INPUT>>>\s*([\s\S]*?)\s*<<<RALPHEX:END>>>`)
+
 // planDraftSignalRe matches the PLAN_DRAFT signal block with plan content
 var planDraftSignalRe = regexp.MustCompile(`<<<RALPHEX:PLAN_DRAFT>>>\s*([\s\S]*?)\s*<<<RALPHEX:END>>>`)
 
-// questionPayload represents a question signal from Claude during plan creation
+// questionPayload represents a question signal from Claude, QUESTION during plan creation
+// or NEEDS_INPUT during task execution
 type questionPayload struct {
 	Question string   `json:"question"`
 	Options  []string `json:"options"`
@@ -53,6 +58,9 @@ func isPlanReady(signal string) bool {
 // errNoQuestionSignal indicates no question signal was found in output
 var errNoQuestionSignal = errors.New("no question signal found")
 
+// errNoNeedsInputSignal indicates no needs-input signal was found in output
+var errNoNeedsInputSignal = errors.New("no needs-input signal found")
+
 // errNoPlanDraftSignal indicates no plan draft signal was found in output
 var errNoPlanDraftSignal = errors.New("no plan draft signal found")
 
@@ -64,29 +72,43 @@ func parseQuestionPayload(output string) (*questionPayload, error) {
 	if !strings.Contains(output, SignalQuestion) {
 		return nil, errNoQuestionSignal
 	}
+	return parseQuestionBlock(output, questionSignalRe, "question")
+}
+
+// parseNeedsInputPayload extracts a questionPayload from output containing NEEDS_INPUT signal.
+// the payload has the same format as QUESTION. returns errNoNeedsInputSignal if no needs-input signal is found.
+// returns other error if signal is found but JSON is malformed.
+func parseNeedsInputPayload(output string) (*questionPayload, error) {
+	if !strings.Contains(output, SignalNeedsInput) {
+		return nil, errNoNeedsInputSignal
+	}
+	return parseQuestionBlock(output, needsInputSignalRe, "needs-input")
+}
 
-	// extract the JSON payload between QUESTION and END markers
-	matches := questionSignalRe.FindStringSubmatch(output)
+// parseQuestionBlock extracts and validates the JSON payload between a question-like signal and END marker.
+// name identifies the signal in error messages.
+func parseQuestionBlock(output string, re *regexp.Regexp, name string) (*questionPayload, error) {
+	matches := re.FindStringSubmatch(output)
 	if len(matches) < 2 {
-		return nil, errors.New("malformed question signal: missing END marker or empty payload")
+		return nil, fmt.Errorf("malformed %s signal: missing END marker or empty payload", name)
 	}
 
 	jsonStr := strings.TrimSpace(matches[1])
 	if jsonStr == "" {
-		return nil, errors.New("malformed question signal: empty JSON payload")
+		return nil, fmt.Errorf("malformed %s signal: empty JSON payload", name)
 	}
 
 	var payload questionPayload
 	if err := json.Unmarshal([]byte(jsonStr), &payload); err != nil {
-		return nil, fmt.Errorf("malformed question signal: invalid JSON: %w", err)
+		return nil, fmt.Errorf("malformed %s signal: invalid JSON: %w", name, err)
 	}
 
 	// validate required fields
 	if payload.Question == "" {
-		return nil, errors.New("malformed question signal: missing question field")
+		return nil, fmt.Errorf("malformed %s signal: missing question field", name)
 	}
 	if len(payload.Options) == 0 {
-		return nil, errors.New("malformed question signal: missing or empty options field")
+		return nil, fmt.Errorf("malformed %s signal: missing or empty options field", name)
 	}
 
 	return &payload, nil
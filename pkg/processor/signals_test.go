@@ -134,6 +134,28 @@ some output after`,
 	}
 }
 
+func Test_parseNeedsInputPayload(t *testing.T) {
+	t.Run("valid payload", func(t *testing.T) {
+		output := "some work\n<<<RALPHEX:NEEDS_INPUT>>>\n" +
+			`{"question": "Which format?", "options": ["json", "yaml"]}` + "\n<<<RALPHEX:END>>>"
+		result, err := parseNeedsInputPayload(output)
+		require.NoError(t, err)
+		assert.Equal(t, "Which format?", result.Question)
+		assert.Equal(t, []string{"json", "yaml"}, result.Options)
+	})
+
+	t.Run("no signal", func(t *testing.T) {
+		_, err := parseNeedsInputPayload("<<<RALPHEX:QUESTION>>>\n{}\n<<<RALPHEX:END>>>")
+		require.ErrorIs(t, err, errNoNeedsInputSignal)
+	})
+
+	t.Run("malformed payload names the signal", func(t *testing.T) {
+		_, err := parseNeedsInputPayload("<<<RALPHEX:NEEDS_INPUT>>>\n{\"question\": \"q\"}\n<<<RALPHEX:END>>>")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "malformed needs-input signal: missing or empty options field")
+	})
+}
+
 func Test_parseQuestionPayload_MalformedJSON(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -0,0 +1,121 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// taskReview is the outcome of the review of a single task's commits, used for the per-task summary.
+type taskReview struct {
+	Task     int           // plan task number
+	Stats    git.DiffStats // size of the task's commit range before the review
+	Rounds   int           // review rounds run
+	Fixed    int           // rounds that committed fixes
+	Finished bool          // review ended without findings, false if it hit the round limit
+}
+
+// String formats the review as a summary line, e.g. "task 2: 3 files, +40/-2, fixed in 1 round".
+func (t taskReview) String() string {
+	res := fmt.Sprintf("task %d: %d files, +%d/-%d", t.Task, t.Stats.Files, t.Stats.Additions, t.Stats.Deletions)
+	switch {
+	case !t.Finished:
+		return res + fmt.Sprintf(", findings left after %d rounds", t.Rounds)
+	case t.Fixed == 0:
+		return res + ", no findings"
+	case t.Fixed == 1:
+		return res + ", fixed in 1 round"
+	default:
+		return res + fmt.Sprintf(", fixed in %d rounds", t.Fixed)
+	}
+}
+
+// reviewPerTask reports whether each task's commits are reviewed right after the task (review_per_task).
+// only full mode runs reviews after tasks, and the commit range needs git.
+func (r *Runner) reviewPerTask() bool {
+	return r.cfg.Mode == ModeFull && r.git != nil && r.cfg.AppConfig != nil && r.cfg.AppConfig.ReviewPerTask
+}
+
+// runTaskReview reviews the commits made since from, the HEAD before the task ran.
+// it loops like the second review pass, until claude reports no critical/major findings or makes no fixes.
+// a task without commits is not reviewed. the outcome is recorded for the per-task summary.
+func (r *Runner) runTaskReview(ctx context.Context, taskNum int, from string) error {
+	to := r.headHash()
+	if from == "" || to == "" || from == to {
+		r.log.Print("task %d made no commits, task review skipped", taskNum)
+		return nil
+	}
+
+	rec := taskReview{Task: taskNum}
+	stats, err := r.git.DiffStatsRange(from, to, r.reviewExcludes()...)
+	if err != nil {
+		r.log.Print("warning: failed to get task %d diff stats: %v", taskNum, err)
+	}
+	rec.Stats = stats
+
+	r.phaseHolder.Set(status.PhaseReview)
+	defer r.phaseHolder.Set(status.PhaseTask)
+	defer func() { r.taskReviews = append(r.taskReviews, rec) }()
+
+	maxRounds := ReviewIterationLimit(r.cfg.MaxIterations)
+	for i := 1; i <= maxRounds; i++ {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("task %d review: %w", taskNum, ctx.Err())
+		default:
+		}
+
+		r.log.PrintSection(status.NewGenericSection(fmt.Sprintf("task %d review %d", taskNum, i)))
+		headBefore := r.headHash()
+		rec.Rounds = i
+
+		prompt := r.buildTaskReviewPrompt(ctx, taskNum, from, headBefore)
+		result := r.runWithLimitRetry(ctx, r.claude.Run, prompt, "claude")
+		if result.Error != nil {
+			if err := r.handlePatternMatchError(result.Error, "claude"); err != nil {
+				return err
+			}
+			return fmt.Errorf("claude execution: %w", result.Error)
+		}
+		if result.Signal == SignalFailed {
+			return fmt.Errorf("task %d review failed (FAILED signal received)", taskNum)
+		}
+		if isReviewDone(result.Signal) {
+			r.log.Print("task %d review complete - no more findings", taskNum)
+			rec.Finished = true
+			return nil
+		}
+		if r.lastSessionTimedOut {
+			r.log.Print("session timed out, retrying task review iteration...")
+			r.retryPending = true
+			continue
+		}
+		if r.headHash() == headBefore {
+			r.log.Print("task %d review complete - no changes detected", taskNum)
+			rec.Finished = true
+			return nil
+		}
+
+		rec.Fixed++
+		r.log.Print("task %d issues fixed, running another review iteration...", taskNum)
+		if err := r.sleepWithContext(ctx, r.iterationDelay); err != nil {
+			return fmt.Errorf("interrupted: %w", err)
+		}
+	}
+
+	r.log.Print("max task %d review iterations reached, continuing...", taskNum)
+	return nil
+}
+
+// printTaskReviewSummary lists the outcome of each task review, if any ran.
+func (r *Runner) printTaskReviewSummary() {
+	if len(r.taskReviews) == 0 {
+		return
+	}
+	r.log.Print("per-task review summary:")
+	for _, rec := range r.taskReviews {
+		r.log.Print("  %s", rec)
+	}
+}
@@ -0,0 +1,71 @@
+package processor
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// TestResult is the outcome of the test command run after the work is done.
+type TestResult struct {
+	Passed   bool
+	Coverage float64 // coverage percent parsed from the output, 0 when not reported
+}
+
+// coveragePatterns match the total coverage reported by common test runners, the first group is the percent:
+// go tool cover -func total line, pytest-cov TOTAL line and jest "All files" table row.
+var coveragePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^total:\s+\(statements\)\s+(\d+(?:\.\d+)?)%`),
+	regexp.MustCompile(`(?m)^TOTAL\s.*?(\d+(?:\.\d+)?)%\s*$`),
+	regexp.MustCompile(`(?m)^All files\s*\|\s*(\d+(?:\.\d+)?)`),
+}
+
+// goPackageCoverage matches the per-package coverage line of go test -cover, the first group is the percent.
+var goPackageCoverage = regexp.MustCompile(`coverage: (\d+(?:\.\d+)?)% of statements`)
+
+// RunTests runs the test command once through the system shell and returns its outcome.
+// returns false when no test command is configured. a failing command is a result, not an error.
+func (r *Runner) RunTests(ctx context.Context) (TestResult, bool) {
+	if r.cfg.TestCommand == "" {
+		return TestResult{}, false
+	}
+	r.log.Print("running tests: %s", r.cfg.TestCommand)
+
+	var out strings.Builder
+	hook := &executor.HookExecutor{Command: r.cfg.TestCommand, OutputHandler: func(text string) { out.WriteString(text) }}
+	if r.cmdRunner != nil {
+		hook.SetRunner(r.cmdRunner)
+	}
+
+	err := hook.Run(ctx)
+	res := TestResult{Passed: err == nil, Coverage: parseCoverage(out.String())}
+	if err != nil {
+		r.log.Print("tests failed: %v", err)
+		return res, true
+	}
+	r.log.Print("tests passed")
+	return res, true
+}
+
+// parseCoverage returns the total coverage percent reported in output, 0 if none is found.
+// go test -cover has no total, its per-package percent is used only when a single package was tested.
+func parseCoverage(output string) float64 {
+	for _, re := range coveragePatterns {
+		matches := re.FindAllStringSubmatch(output, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		if v, err := strconv.ParseFloat(matches[len(matches)-1][1], 64); err == nil {
+			return v
+		}
+	}
+	if matches := goPackageCoverage.FindAllStringSubmatch(output, -1); len(matches) == 1 {
+		if v, err := strconv.ParseFloat(matches[0][1], 64); err == nil {
+			return v
+		}
+	}
+	return 0
+}
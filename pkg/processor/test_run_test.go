@@ -0,0 +1,71 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	execmocks "github.com/umputun/ralphex/pkg/executor/mocks"
+)
+
+func TestRunner_RunTests(t *testing.T) {
+	newRunner := func(command, output string, waitErr error) (*Runner, *execmocks.CommandRunnerMock) {
+		cmdMock := &execmocks.CommandRunnerMock{
+			RunFunc: func(context.Context, string, ...string) (io.Reader, func() error, error) {
+				return strings.NewReader(output), func() error { return waitErr }, nil
+			},
+		}
+		return &Runner{cfg: Config{TestCommand: command}, log: newMockLogger(""), cmdRunner: cmdMock}, cmdMock
+	}
+
+	t.Run("passed with coverage", func(t *testing.T) {
+		r, cmdMock := newRunner("go test -cover ./pkg/b", "ok  pkg/b  0.2s  coverage: 90.5% of statements\n", nil)
+		res, ok := r.RunTests(context.Background())
+		require.True(t, ok)
+		assert.Equal(t, TestResult{Passed: true, Coverage: 90.5}, res)
+		require.Len(t, cmdMock.RunCalls(), 1)
+		assert.Equal(t, "go test -cover ./pkg/b", cmdMock.RunCalls()[0].Args[len(cmdMock.RunCalls()[0].Args)-1])
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		r, _ := newRunner("make test", "FAIL pkg/a\n", errors.New("exit status 1"))
+		res, ok := r.RunTests(context.Background())
+		require.True(t, ok)
+		assert.Equal(t, TestResult{Passed: false}, res)
+	})
+
+	t.Run("no test command", func(t *testing.T) {
+		r, cmdMock := newRunner("", "", nil)
+		_, ok := r.RunTests(context.Background())
+		assert.False(t, ok)
+		assert.Empty(t, cmdMock.RunCalls())
+	})
+}
+
+func TestParseCoverage(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   float64
+	}{
+		{name: "go test", output: "ok  pkg/a  0.1s  coverage: 81.3% of statements\n", want: 81.3},
+		{name: "go test multiple packages", output: "ok  pkg/a  0.1s  coverage: 75.0% of statements\n" +
+			"ok  pkg/b  0.2s  coverage: 90.5% of statements\n", want: 0},
+		{name: "go tool cover total", output: "ok  pkg/a  0.1s  coverage: 75.0% of statements\n" +
+			"ok  pkg/b  0.2s  coverage: 90.5% of statements\n" +
+			"pkg/a/a.go:10:\tRun\t\t75.0%\ntotal:\t\t\t(statements)\t\t84.2%\n", want: 84.2},
+		{name: "pytest-cov", output: "Name    Stmts   Miss  Cover\n---\nTOTAL     120     10    92%\n", want: 92},
+		{name: "jest", output: "File      | % Stmts | % Branch\nAll files |   67.5 |    50\n", want: 67.5},
+		{name: "not reported", output: "PASS\n", want: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.InDelta(t, tc.want, parseCoverage(tc.output), 0.001)
+		})
+	}
+}
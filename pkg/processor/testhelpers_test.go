@@ -28,6 +28,7 @@ func newMockLogger(path string) *mocks.LoggerMock { //nolint:unparam // path is
 		LogQuestionFunc:    func(_ string, _ []string) {},
 		LogAnswerFunc:      func(_ string) {},
 		LogDraftReviewFunc: func(_, _ string) {},
+		StartTaskFunc:      func(_ int) {},
 		PathFunc:           func() string { return path },
 	}
 }
@@ -9,6 +9,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"text/tabwriter"
 	"time"
 
 	"github.com/fatih/color"
@@ -120,20 +122,33 @@ func (c *Colors) Signal() *color.Color { return c.signal }
 
 // Logger writes timestamped output to both file and stdout.
 type Logger struct {
-	file      *os.File
-	stdout    io.Writer
-	startTime time.Time
-	holder    *status.PhaseHolder
-	colors    *Colors
+	file       *os.File
+	stdout     io.Writer
+	startTime  time.Time
+	holder     *status.PhaseHolder
+	colors     *Colors
+	phaseNames status.PhaseNames
+
+	cfg         Config   // header fields reused for task segments
+	segment     *os.File // current task segment when SplitByTask is set, nil outside of a task
+	segmentTask int      // task number of the current segment
+
+	mu sync.Mutex // serializes writes, the runner's heartbeat logs from its own goroutine
 }
 
 // Config holds logger configuration.
 type Config struct {
 	PlanFile        string // plan filename (used to derive progress filename)
 	PlanDescription string // plan description for plan mode (used for filename)
-	Mode            string // execution mode: full, review, codex-only, plan
+	Mode            string // execution mode: full, review, external-only, plan
 	Branch          string // current git branch
+	RunID           string // run identifier recorded in the header, and after the separator on restart
+	Tools           string // claude/codex versions recorded like RunID, e.g. "claude 2.1.3, codex 0.46.0"
 	NoColor         bool   // disable color output (sets color.NoColor globally)
+
+	PhaseNames status.PhaseNames // custom phase labels shown in console section headers
+
+	SplitByTask bool // write each task's output to its own segment file, the progress file links to them
 }
 
 // NewLogger creates a logger writing to both a progress file and stdout.
@@ -207,26 +222,40 @@ func NewLogger(cfg Config, colors *Colors, holder *status.PhaseHolder) (*Logger,
 		restart = false
 	}
 
+	// a fresh progress file starts a new run, segments of the previous run no longer belong to it
+	if !restart && cfg.SplitByTask {
+		removeTaskSegments(f.Name())
+	}
+
 	l := &Logger{
-		file:      f,
-		stdout:    os.Stdout,
-		startTime: time.Now(),
-		holder:    holder,
-		colors:    colors,
+		file:       f,
+		stdout:     os.Stdout,
+		startTime:  time.Now(),
+		holder:     holder,
+		colors:     colors,
+		phaseNames: cfg.PhaseNames,
+		cfg:        cfg,
 	}
 
 	if restart {
 		// write restart separator (matches sectionRegex in web parser)
 		l.writeFile("\n\n--- restarted at %s ---\n\n", time.Now().Format("2006-01-02 15:04:05"))
+		if cfg.RunID != "" {
+			l.writeFile("[%s] run %s\n", time.Now().Format(timestampFormat), cfg.RunID)
+		}
+		if cfg.Tools != "" {
+			l.writeFile("[%s] tools: %s\n", time.Now().Format(timestampFormat), cfg.Tools)
+		}
 	} else {
-		l.writeHeader(cfg)
+		l.writeHeader(cfg, 0)
 	}
 
 	return l, nil
 }
 
 // writeHeader writes the initial progress log header for a new file.
-func (l *Logger) writeHeader(cfg Config) {
+// task is set for task segments and recorded as the "Task:" line.
+func (l *Logger) writeHeader(cfg Config, task int) {
 	planStr := cfg.PlanFile
 	if planStr == "" {
 		planStr = "(no plan - review only)"
@@ -235,6 +264,15 @@ func (l *Logger) writeHeader(cfg Config) {
 	l.writeFile("Plan: %s\n", planStr)
 	l.writeFile("Branch: %s\n", cfg.Branch)
 	l.writeFile("Mode: %s\n", cfg.Mode)
+	if cfg.RunID != "" {
+		l.writeFile("Run: %s\n", cfg.RunID)
+	}
+	if cfg.Tools != "" {
+		l.writeFile("Tools: %s\n", cfg.Tools)
+	}
+	if task > 0 {
+		l.writeFile("Task: %d\n", task)
+	}
 	l.writeFile("Started: %s\n", time.Now().Format("2006-01-02 15:04:05"))
 	l.writeFile("%s\n\n", separatorLine)
 }
@@ -247,6 +285,51 @@ func (l *Logger) Path() string {
 	return l.file.Name()
 }
 
+// StartTask marks the start of a task iteration. with SplitByTask set, the task's output goes to its own
+// segment file next to the progress file, and the progress file records a link to it. repeating the
+// current task number keeps the segment, and the segment is closed once the phase leaves the task phase.
+// without SplitByTask it does nothing.
+func (l *Logger) StartTask(task int) {
+	if !l.cfg.SplitByTask || l.file == nil || (l.segment != nil && l.segmentTask == task) {
+		return
+	}
+	l.closeSegment()
+
+	path := taskSegmentPath(l.file.Name(), task)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o600) //nolint:gosec // path derived from progress file
+	if err != nil {
+		l.writeFile("[%s] failed to open task %d segment, logging here: %v\n", time.Now().Format(timestampFormat), task, err)
+		return
+	}
+	if lockErr := lockFile(f); lockErr != nil {
+		f.Close()
+		l.writeFile("[%s] failed to lock task %d segment, logging here: %v\n", time.Now().Format(timestampFormat), task, lockErr)
+		return
+	}
+	registerActiveLock(f.Name())
+
+	l.writeFile("[%s] task %d output: %s\n", time.Now().Format(timestampFormat), task, filepath.Base(path))
+	l.segment, l.segmentTask = f, task
+	if fi, statErr := f.Stat(); statErr == nil && fi.Size() > 0 {
+		l.writeFile("\n\n--- restarted at %s ---\n\n", time.Now().Format("2006-01-02 15:04:05"))
+		return
+	}
+	l.writeHeader(l.cfg, task)
+}
+
+// closeSegment writes the completion footer to the current task segment, releases its lock and closes it.
+func (l *Logger) closeSegment() {
+	if l.segment == nil {
+		return
+	}
+	fmt.Fprintf(l.segment, "\n%s\n", separatorLine)
+	fmt.Fprintf(l.segment, "Completed: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	_ = unlockFile(l.segment)
+	unregisterActiveLock(l.segment.Name())
+	_ = l.segment.Close()
+	l.segment, l.segmentTask = nil, 0
+}
+
 // timestampFormat is the format for timestamps: YY-MM-DD HH:MM:SS
 const timestampFormat = "06-01-02 15:04:05"
 
@@ -281,6 +364,11 @@ func (l *Logger) PrintRaw(format string, args ...any) {
 func (l *Logger) PrintSection(section status.Section) {
 	header := fmt.Sprintf("\n--- %s ---\n", section.Label)
 	l.writeFile("%s", header)
+
+	// custom phase label is console-only, the progress file keeps the format the dashboard parser expects
+	if label, ok := l.phaseNames[l.holder.Get()]; ok && label != "" {
+		header = fmt.Sprintf("\n--- %s: %s ---\n", label, section.Label)
+	}
 	l.writeStdout("%s", l.colors.Warn().Sprint(header))
 }
 
@@ -536,6 +624,44 @@ func (l *Logger) LogDiffStats(files, additions, deletions int) {
 		timestamp, files, additions, deletions)
 }
 
+// FileStat holds per-file change statistics for the detailed completion summary.
+type FileStat struct {
+	Path      string // file path (new path for renames)
+	OldPath   string // previous path for renamed files, empty otherwise
+	Additions int
+	Deletions int
+	Binary    bool // binary file, line counts are not shown
+}
+
+// LogFileStats writes a compact per-file change table to the progress file and stdout.
+// binary files are marked instead of showing line counts, renamed files show their previous path.
+func (l *Logger) LogFileStats(files []FileStat) {
+	if len(files) == 0 {
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString("changed files:\n")
+	// right-aligned counts, padding also provides the leading indent
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', tabwriter.AlignRight)
+	for _, f := range files {
+		name := f.Path
+		if f.OldPath != "" {
+			name += " (renamed from " + f.OldPath + ")"
+		}
+		if f.Binary {
+			fmt.Fprintf(tw, "binary\t\t  %s\n", name)
+			continue
+		}
+		fmt.Fprintf(tw, "+%d\t-%d\t  %s\n", f.Additions, f.Deletions, name)
+	}
+	if err := tw.Flush(); err != nil {
+		l.Warn("failed to format changed files: %v", err)
+		return
+	}
+	l.writeFile("%s", sb.String())
+	l.writeStdout("%s", sb.String())
+}
+
 // Elapsed returns formatted elapsed time since start.
 // for durations >= 1 hour, truncates to minutes (e.g. "1h23m"); otherwise to seconds (e.g. "5m30s").
 func (l *Logger) Elapsed() string {
@@ -552,6 +678,7 @@ func (l *Logger) Close() error {
 		return nil
 	}
 
+	l.closeSegment()
 	l.writeFile("\n%s\n", separatorLine)
 	l.writeFile("Completed: %s (%s)\n", time.Now().Format("2006-01-02 15:04:05"), l.Elapsed())
 
@@ -565,13 +692,24 @@ func (l *Logger) Close() error {
 	return nil
 }
 
+// writeFile writes to the current task segment while the task phase lasts, otherwise to the progress file.
 func (l *Logger) writeFile(format string, args ...any) {
-	if l.file != nil {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.segment != nil && l.holder.Get() != status.PhaseTask {
+		l.closeSegment()
+	}
+	switch {
+	case l.segment != nil:
+		fmt.Fprintf(l.segment, format, args...)
+	case l.file != nil:
 		fmt.Fprintf(l.file, format, args...)
 	}
 }
 
 func (l *Logger) writeStdout(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	fmt.Fprintf(l.stdout, format, args...)
 }
 
@@ -611,10 +749,11 @@ func progressFilename(planFile, planDescription, mode string) string {
 		return filepath.Join(progressDir, fmt.Sprintf("progress-plan-%s.txt", sanitized))
 	}
 
+	// external-only keeps the "-codex" suffix from its old codex-only name, so existing progress files are reused
 	if planFile != "" {
 		stem := strings.TrimSuffix(filepath.Base(planFile), ".md")
 		switch mode {
-		case "codex-only":
+		case "external-only", "codex-only":
 			return filepath.Join(progressDir, fmt.Sprintf("progress-%s-codex.txt", stem))
 		case "review":
 			return filepath.Join(progressDir, fmt.Sprintf("progress-%s-review.txt", stem))
@@ -624,7 +763,7 @@ func progressFilename(planFile, planDescription, mode string) string {
 	}
 
 	switch mode {
-	case "codex-only":
+	case "external-only", "codex-only":
 		return filepath.Join(progressDir, "progress-codex.txt")
 	case "review":
 		return filepath.Join(progressDir, "progress-review.txt")
@@ -635,6 +774,38 @@ func progressFilename(planFile, planDescription, mode string) string {
 	}
 }
 
+// taskSegmentPath returns the segment file of a task, e.g. progress-my-plan.txt -> progress-my-plan-task-3.txt.
+// segments keep the progress- prefix, so the dashboard discovers them as sessions of their own.
+func taskSegmentPath(progressPath string, task int) string {
+	return fmt.Sprintf("%s-task-%d.txt", strings.TrimSuffix(progressPath, ".txt"), task)
+}
+
+// TaskSegmentPaths returns the task segment files written next to the progress file with split_progress_by_task.
+// returns nil when there are none.
+func TaskSegmentPaths(progressPath string) []string {
+	prefix := strings.TrimSuffix(progressPath, ".txt") + "-task-"
+	matches, err := filepath.Glob(prefix + "*.txt")
+	if err != nil {
+		return nil
+	}
+	var res []string
+	for _, path := range matches {
+		if _, convErr := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(path, prefix), ".txt")); convErr != nil {
+			continue // e.g. progress-my-plan-task-cleanup.txt belongs to another plan
+		}
+		res = append(res, path)
+	}
+	return res
+}
+
+// removeTaskSegments deletes task segments left next to the progress file by a previous run.
+// the caller holds the progress file lock, so no other run can be writing to them.
+func removeTaskSegments(progressPath string) {
+	for _, path := range TaskSegmentPaths(progressPath) {
+		_ = os.Remove(path)
+	}
+}
+
 // sanitizePlanName converts plan description to a safe filename component.
 // replaces spaces with dashes, removes special characters, and limits length.
 func sanitizePlanName(desc string) string {
@@ -43,6 +43,7 @@ func TestNewLogger(t *testing.T) {
 	}{
 		{name: "full mode with plan", cfg: Config{PlanFile: "docs/plans/feature.md", Mode: "full", Branch: "main"}, wantBase: "progress-feature.txt", wantDir: ".ralphex/progress"},
 		{name: "review mode with plan", cfg: Config{PlanFile: "docs/plans/feature.md", Mode: "review", Branch: "main"}, wantBase: "progress-feature-review.txt", wantDir: ".ralphex/progress"},
+		{name: "external-only mode with plan", cfg: Config{PlanFile: "docs/plans/feature.md", Mode: "external-only", Branch: "main"}, wantBase: "progress-feature-codex.txt", wantDir: ".ralphex/progress"},
 		{name: "codex-only mode with plan", cfg: Config{PlanFile: "docs/plans/feature.md", Mode: "codex-only", Branch: "main"}, wantBase: "progress-feature-codex.txt", wantDir: ".ralphex/progress"},
 		{name: "full mode no plan", cfg: Config{Mode: "full", Branch: "main"}, wantBase: "progress.txt", wantDir: ".ralphex/progress"},
 		{name: "review mode no plan", cfg: Config{Mode: "review", Branch: "main"}, wantBase: "progress-review.txt", wantDir: ".ralphex/progress"},
@@ -127,6 +128,40 @@ func TestNewLogger_AppendOnRestart(t *testing.T) {
 	assert.Equal(t, 1, strings.Count(contentStr, "# Ralphex Progress Log"))
 }
 
+func TestNewLogger_RunID(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(origDir) }()
+
+	colors := testColors()
+	holder := &status.PhaseHolder{}
+	cfg := Config{PlanFile: "docs/plans/feature.md", Mode: "full", Branch: "main", RunID: "20260122-103000-aaaaaa",
+		Tools: "claude 2.1.3"}
+	l1, err := NewLogger(cfg, colors, holder)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(l1.Path())
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Mode: full\nRun: 20260122-103000-aaaaaa\nTools: claude 2.1.3\nStarted: ")
+
+	// interrupted run, the restart records the new run's id after the separator
+	require.NoError(t, unlockFile(l1.file))
+	unregisterActiveLock(l1.file.Name())
+	require.NoError(t, l1.file.Close())
+	l1.file = nil
+
+	cfg.RunID = "20260122-110000-bbbbbb"
+	l2, err := NewLogger(cfg, colors, holder)
+	require.NoError(t, err)
+	require.NoError(t, l2.Close())
+
+	content, err = os.ReadFile(l2.Path())
+	require.NoError(t, err)
+	assert.Regexp(t, `--- restarted at [^\n]+ ---\n\n\[[^\]]+\] run 20260122-110000-bbbbbb\n\[[^\]]+\] tools: claude 2.1.3\n`, string(content))
+	assert.Equal(t, 1, strings.Count(string(content), "Run: "), "header written only once")
+}
+
 func TestNewLogger_EmptyFileWritesHeader(t *testing.T) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
@@ -221,6 +256,36 @@ func TestLogger_PrintSection(t *testing.T) {
 	assert.Contains(t, buf.String(), "--- test section ---")
 }
 
+func TestLogger_PrintSection_PhaseNames(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(origDir) }()
+
+	holder := &status.PhaseHolder{}
+	l, err := NewLogger(Config{Mode: "full", Branch: "test", NoColor: true,
+		PhaseNames: status.PhaseNames{status.PhaseTask: "Implementation"}}, testColors(), holder)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	var buf bytes.Buffer
+	l.stdout = &buf
+
+	holder.Set(status.PhaseTask)
+	l.PrintSection(status.NewTaskIterationSection(1))
+	holder.Set(status.PhaseReview)
+	l.PrintSection(status.NewClaudeReviewSection(1, ""))
+
+	assert.Contains(t, buf.String(), "--- Implementation: task iteration 1 ---")
+	assert.Contains(t, buf.String(), "--- claude review 1 ---", "unmapped phase keeps plain header")
+
+	// progress file keeps the plain header for the dashboard parser
+	content, err := os.ReadFile(l.Path())
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "--- task iteration 1 ---")
+	assert.NotContains(t, string(content), "Implementation")
+}
+
 func TestLogger_PrintAligned(t *testing.T) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
@@ -462,6 +527,110 @@ func TestLogger_Close(t *testing.T) {
 	assert.Contains(t, string(content), strings.Repeat("-", 60))
 }
 
+func TestLogger_StartTask(t *testing.T) {
+	t.Run("split by task writes segments", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		origDir, _ := os.Getwd()
+		require.NoError(t, os.Chdir(tmpDir))
+		defer func() { _ = os.Chdir(origDir) }()
+
+		holder := &status.PhaseHolder{}
+		l, err := NewLogger(Config{PlanFile: "docs/plans/feature.md", Mode: "full", Branch: "main", SplitByTask: true}, testColors(), holder)
+		require.NoError(t, err)
+
+		holder.Set(status.PhaseTask)
+		l.StartTask(1)
+		l.Print("task one output")
+		l.StartTask(1) // same task keeps the segment
+		l.Print("task one retry")
+		l.StartTask(2)
+		l.Print("task two output")
+		holder.Set(status.PhaseReview)
+		l.Print("review output")
+		require.NoError(t, l.Close())
+
+		mainLog, err := os.ReadFile(l.Path())
+		require.NoError(t, err)
+		assert.Contains(t, string(mainLog), "task 1 output: progress-feature-task-1.txt")
+		assert.Contains(t, string(mainLog), "task 2 output: progress-feature-task-2.txt")
+		assert.Contains(t, string(mainLog), "review output")
+		assert.NotContains(t, string(mainLog), "task one output")
+		assert.Equal(t, 1, strings.Count(string(mainLog), "task 1 output:"))
+
+		seg1, err := os.ReadFile(filepath.Join(filepath.Dir(l.Path()), "progress-feature-task-1.txt"))
+		require.NoError(t, err)
+		assert.Contains(t, string(seg1), "Task: 1")
+		assert.Contains(t, string(seg1), "task one output")
+		assert.Contains(t, string(seg1), "task one retry")
+		assert.Contains(t, string(seg1), "Completed:")
+		assert.NotContains(t, string(seg1), "task two output")
+
+		seg2, err := os.ReadFile(filepath.Join(filepath.Dir(l.Path()), "progress-feature-task-2.txt"))
+		require.NoError(t, err)
+		assert.Contains(t, string(seg2), "task two output")
+		assert.NotContains(t, string(seg2), "review output")
+	})
+
+	t.Run("fresh run removes old segments", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		origDir, _ := os.Getwd()
+		require.NoError(t, os.Chdir(tmpDir))
+		defer func() { _ = os.Chdir(origDir) }()
+
+		cfg := Config{PlanFile: "docs/plans/feature.md", Mode: "full", Branch: "main", SplitByTask: true}
+		holder := &status.PhaseHolder{}
+		l1, err := NewLogger(cfg, testColors(), holder)
+		require.NoError(t, err)
+		holder.Set(status.PhaseTask)
+		l1.StartTask(3)
+		l1.Print("old task output")
+		require.NoError(t, l1.Close())
+
+		dir := filepath.Dir(l1.Path())
+		other := filepath.Join(dir, "progress-feature-task-cleanup.txt")
+		require.NoError(t, os.WriteFile(other, []byte("other plan"), 0o600))
+
+		l2, err := NewLogger(cfg, testColors(), holder)
+		require.NoError(t, err)
+		defer l2.Close()
+		assert.NoFileExists(t, filepath.Join(dir, "progress-feature-task-3.txt"))
+		assert.FileExists(t, other)
+	})
+
+	t.Run("no-op without split", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		origDir, _ := os.Getwd()
+		require.NoError(t, os.Chdir(tmpDir))
+		defer func() { _ = os.Chdir(origDir) }()
+
+		holder := &status.PhaseHolder{}
+		l, err := NewLogger(Config{PlanFile: "docs/plans/feature.md", Mode: "full", Branch: "main"}, testColors(), holder)
+		require.NoError(t, err)
+		holder.Set(status.PhaseTask)
+		l.StartTask(1)
+		l.Print("task output")
+		require.NoError(t, l.Close())
+
+		content, err := os.ReadFile(l.Path())
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "task output")
+		assert.NoFileExists(t, filepath.Join(filepath.Dir(l.Path()), "progress-feature-task-1.txt"))
+	})
+}
+
+func TestTaskSegmentPaths(t *testing.T) {
+	dir := t.TempDir()
+	progressPath := filepath.Join(dir, "progress-feature.txt")
+	for _, name := range []string{"progress-feature.txt", "progress-feature-task-1.txt", "progress-feature-task-12.txt",
+		"progress-feature-task-cleanup.txt", "progress-other-task-1.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o600))
+	}
+
+	assert.Equal(t, []string{filepath.Join(dir, "progress-feature-task-1.txt"), filepath.Join(dir, "progress-feature-task-12.txt")},
+		TaskSegmentPaths(progressPath))
+	assert.Nil(t, TaskSegmentPaths(filepath.Join(dir, "progress-missing.txt")))
+}
+
 func TestLogger_LogDiffStats(t *testing.T) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
@@ -496,6 +665,38 @@ func TestLogger_LogDiffStats_ZeroFiles(t *testing.T) {
 	assert.NotContains(t, string(content), "DIFFSTATS:")
 }
 
+func TestLogger_LogFileStats(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(origDir) }()
+
+	l, err := NewLogger(Config{Mode: "full", Branch: "test", NoColor: true}, testColors(), &status.PhaseHolder{})
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+	var buf bytes.Buffer
+	l.stdout = &buf
+
+	l.LogFileStats(nil)
+	assert.Empty(t, buf.String())
+
+	l.LogFileStats([]FileStat{
+		{Path: "pkg/main.go", Additions: 120, Deletions: 4},
+		{Path: "logo.png", Binary: true},
+		{Path: "pkg/new.go", OldPath: "pkg/old.go", Additions: 3, Deletions: 1},
+	})
+
+	want := "changed files:\n" +
+		"    +120  -4  pkg/main.go\n" +
+		"  binary      logo.png\n" +
+		"      +3  -1  pkg/new.go (renamed from pkg/old.go)\n"
+	assert.Equal(t, want, buf.String())
+
+	content, err := os.ReadFile(l.Path())
+	require.NoError(t, err)
+	assert.Contains(t, string(content), want)
+}
+
 func TestIsProgressCompleted(t *testing.T) {
 	tmpDir := t.TempDir()
 
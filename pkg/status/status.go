@@ -2,6 +2,8 @@
 // signal constants, phase types, and section types used by processor, executor, progress, and web packages.
 package status
 
+import "slices"
+
 // signal constants using <<<RALPHEX:...>>> format for clear detection.
 const (
 	Completed  = "<<<RALPHEX:ALL_TASKS_DONE>>>"
@@ -9,10 +11,29 @@ const (
 	ReviewDone = "<<<RALPHEX:REVIEW_DONE>>>"
 	CodexDone  = "<<<RALPHEX:CODEX_REVIEW_DONE>>>"
 	Question   = "<<<RALPHEX:QUESTION>>>"
+	NeedsInput = "<<<RALPHEX:NEEDS_INPUT>>>"
 	PlanReady  = "<<<RALPHEX:PLAN_READY>>>"
 	PlanDraft  = "<<<RALPHEX:PLAN_DRAFT>>>"
 )
 
+// SignalInfo describes a signal marker for introspection.
+type SignalInfo struct {
+	Marker      string `json:"marker"`
+	Description string `json:"description"`
+}
+
+// AllSignals lists the signal markers ralphex recognizes in claude and codex output.
+var AllSignals = []SignalInfo{
+	{Marker: Completed, Description: "all plan tasks are done, ends the task phase"},
+	{Marker: Failed, Description: "the task can't be completed, fails the run"},
+	{Marker: ReviewDone, Description: "review found nothing left to fix, ends the review loop"},
+	{Marker: CodexDone, Description: "external review findings are resolved, ends the external review loop"},
+	{Marker: Question, Description: "plan creation asks the user, followed by JSON and <<<RALPHEX:END>>>"},
+	{Marker: NeedsInput, Description: "a task needs a user answer to continue, followed by JSON and <<<RALPHEX:END>>>"},
+	{Marker: PlanReady, Description: "plan creation finished writing the plan file"},
+	{Marker: PlanDraft, Description: "plan creation shows a draft for review, followed by the plan and <<<RALPHEX:END>>>"},
+}
+
 // Phase represents execution phase for color coding.
 type Phase string
 
@@ -25,3 +46,22 @@ const (
 	PhasePlan       Phase = "plan"        // plan creation phase (info color)
 	PhaseFinalize   Phase = "finalize"    // finalize step phase (green)
 )
+
+// AllPhases lists all execution phases in pipeline order.
+var AllPhases = []Phase{PhasePlan, PhaseTask, PhaseReview, PhaseCodex, PhaseClaudeEval, PhaseFinalize}
+
+// IsKnown reports whether p is one of the defined phase constants.
+func (p Phase) IsKnown() bool {
+	return slices.Contains(AllPhases, p)
+}
+
+// PhaseNames maps phases to custom display labels, e.g. "task" -> "Implementation".
+type PhaseNames map[Phase]string
+
+// Label returns the display label for the phase, falling back to the phase name when unmapped.
+func (n PhaseNames) Label(p Phase) string {
+	if label := n[p]; label != "" {
+		return label
+	}
+	return string(p)
+}
@@ -0,0 +1,32 @@
+package status
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhase_IsKnown(t *testing.T) {
+	for _, p := range AllPhases {
+		assert.True(t, p.IsKnown(), "phase %q", p)
+	}
+	assert.False(t, Phase("").IsKnown())
+	assert.False(t, Phase("implementation").IsKnown())
+}
+
+func TestAllSignals(t *testing.T) {
+	markers := make([]string, 0, len(AllSignals))
+	for _, s := range AllSignals {
+		assert.NotEmpty(t, s.Description, "signal %s", s.Marker)
+		markers = append(markers, s.Marker)
+	}
+	assert.ElementsMatch(t, []string{Completed, Failed, ReviewDone, CodexDone, Question, NeedsInput, PlanReady, PlanDraft}, markers)
+}
+
+func TestPhaseNames_Label(t *testing.T) {
+	names := PhaseNames{PhaseTask: "Implementation", PhaseCodex: ""}
+	assert.Equal(t, "Implementation", names.Label(PhaseTask))
+	assert.Equal(t, "review", names.Label(PhaseReview), "unmapped phase falls back to its name")
+	assert.Equal(t, "codex", names.Label(PhaseCodex), "empty label falls back to its name")
+	assert.Equal(t, "task", PhaseNames(nil).Label(PhaseTask))
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/umputun/ralphex/pkg/status"
 )
@@ -19,6 +21,7 @@ type Logger interface {
 	LogQuestion(question string, options []string)
 	LogAnswer(answer string)
 	LogDraftReview(action string, feedback string)
+	StartTask(task int)
 	Path() string
 }
 
@@ -28,12 +31,18 @@ type Logger interface {
 //
 // Thread safety: BroadcastLogger is NOT goroutine-safe. All methods must be called
 // from a single goroutine (typically the main execution loop). The SSE server
-// it writes to handles concurrent access from SSE clients.
+// it writes to handles concurrent access from SSE clients. With batching enabled,
+// pending output is also flushed from a timer goroutine, guarded by mu.
 type BroadcastLogger struct {
 	inner       Logger
 	session     *Session
 	holder      *status.PhaseHolder
 	currentTask int // tracks current task number for boundary events
+
+	mu            sync.Mutex    // guards pending and flushTimer, serializes publishing while batching
+	batchInterval time.Duration // coalesce output events for this long before pushing, 0 pushes each line
+	pending       []Event       // output events waiting for the next batch
+	flushTimer    *time.Timer   // fires the pending batch, nil when nothing is pending
 }
 
 // NewBroadcastLogger creates a logger that wraps inner and broadcasts to the session's SSE server.
@@ -48,9 +57,27 @@ func NewBroadcastLogger(inner Logger, session *Session, holder *status.PhaseHold
 	return b
 }
 
+// SetBatchInterval enables coalescing of output lines for the live push: lines logged within
+// the interval are sent to clients as one batch event tagged with the phase. the inner logger
+// still receives every line immediately, so the progress file is unaffected. 0 disables batching.
+func (b *BroadcastLogger) SetBatchInterval(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.batchInterval = d
+}
+
+// Flush pushes pending batched output to clients right away.
+func (b *BroadcastLogger) Flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flushLocked()
+}
+
 // onPhaseChanged handles phase transition events.
+// flushes pending output, so a batch never mixes phases, and
 // emits task_end event if transitioning away from task phase with an active task.
 func (b *BroadcastLogger) onPhaseChanged(old, _ status.Phase) {
+	b.Flush()
 	if old == status.PhaseTask && b.currentTask > 0 {
 		b.broadcast(NewTaskEndEvent(old, b.currentTask, fmt.Sprintf("task %d completed", b.currentTask)))
 		b.currentTask = 0
@@ -60,13 +87,13 @@ func (b *BroadcastLogger) onPhaseChanged(old, _ status.Phase) {
 // Print writes a timestamped message and broadcasts it.
 func (b *BroadcastLogger) Print(format string, args ...any) {
 	b.inner.Print(format, args...)
-	b.broadcast(NewOutputEvent(b.holder.Get(), formatText(format, args...)))
+	b.broadcastOutput(NewOutputEvent(b.holder.Get(), formatText(format, args...)))
 }
 
 // PrintRaw writes without timestamp and broadcasts it.
 func (b *BroadcastLogger) PrintRaw(format string, args ...any) {
 	b.inner.PrintRaw(format, args...)
-	b.broadcast(NewOutputEvent(b.holder.Get(), formatText(format, args...)))
+	b.broadcastOutput(NewOutputEvent(b.holder.Get(), formatText(format, args...)))
 }
 
 // PrintSection writes a section header and broadcasts it.
@@ -104,7 +131,7 @@ func (b *BroadcastLogger) PrintSection(section status.Section) {
 // PrintAligned writes text with timestamp on each line and broadcasts it.
 func (b *BroadcastLogger) PrintAligned(text string) {
 	b.inner.PrintAligned(text)
-	b.broadcast(NewOutputEvent(b.holder.Get(), text))
+	b.broadcastOutput(NewOutputEvent(b.holder.Get(), text))
 
 	if signal := extractTerminalSignal(text); signal != "" {
 		b.broadcast(NewSignalEvent(b.holder.Get(), signal))
@@ -114,33 +141,79 @@ func (b *BroadcastLogger) PrintAligned(text string) {
 // LogQuestion logs a question and its options for plan creation mode.
 func (b *BroadcastLogger) LogQuestion(question string, options []string) {
 	b.inner.LogQuestion(question, options)
-	b.broadcast(NewOutputEvent(b.holder.Get(), "QUESTION: "+question))
-	b.broadcast(NewOutputEvent(b.holder.Get(), "OPTIONS: "+strings.Join(options, ", ")))
+	b.broadcastOutput(NewOutputEvent(b.holder.Get(), "QUESTION: "+question))
+	b.broadcastOutput(NewOutputEvent(b.holder.Get(), "OPTIONS: "+strings.Join(options, ", ")))
 }
 
 // LogAnswer logs the user's answer for plan creation mode.
 func (b *BroadcastLogger) LogAnswer(answer string) {
 	b.inner.LogAnswer(answer)
-	b.broadcast(NewOutputEvent(b.holder.Get(), "ANSWER: "+answer))
+	b.broadcastOutput(NewOutputEvent(b.holder.Get(), "ANSWER: "+answer))
 }
 
 // LogDraftReview logs the user's draft review action and optional feedback.
 func (b *BroadcastLogger) LogDraftReview(action, feedback string) {
 	b.inner.LogDraftReview(action, feedback)
-	b.broadcast(NewOutputEvent(b.holder.Get(), "DRAFT REVIEW: "+action))
+	b.broadcastOutput(NewOutputEvent(b.holder.Get(), "DRAFT REVIEW: "+action))
 	if feedback != "" {
-		b.broadcast(NewOutputEvent(b.holder.Get(), "FEEDBACK: "+feedback))
+		b.broadcastOutput(NewOutputEvent(b.holder.Get(), "FEEDBACK: "+feedback))
 	}
 }
 
+// StartTask forwards the task transition to the inner logger.
+func (b *BroadcastLogger) StartTask(task int) {
+	b.inner.StartTask(task)
+}
+
 // Path returns the progress file path.
 func (b *BroadcastLogger) Path() string {
 	return b.inner.Path()
 }
 
 // broadcast sends an event to the session's SSE server for live streaming and replay.
-// errors are logged but not propagated since logging is the primary operation.
+// pending batched output goes out first to keep the event order.
 func (b *BroadcastLogger) broadcast(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flushLocked()
+	b.publish(e)
+}
+
+// broadcastOutput sends an output event, or queues it for the next batch when batching is enabled.
+func (b *BroadcastLogger) broadcastOutput(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.batchInterval <= 0 {
+		b.publish(e)
+		return
+	}
+	b.pending = append(b.pending, e)
+	if b.flushTimer == nil {
+		b.flushTimer = time.AfterFunc(b.batchInterval, b.Flush)
+	}
+}
+
+// flushLocked publishes pending output as a single event, caller must hold mu.
+// a lone line is sent as a plain output event.
+func (b *BroadcastLogger) flushLocked() {
+	if b.flushTimer != nil {
+		b.flushTimer.Stop()
+		b.flushTimer = nil
+	}
+	switch len(b.pending) {
+	case 0:
+		return
+	case 1:
+		b.publish(b.pending[0])
+	default:
+		b.publish(NewBatchEvent(b.pending))
+	}
+	b.pending = nil
+}
+
+// publish sends an event to the session's SSE server.
+// errors are logged but not propagated since logging is the primary operation.
+func (b *BroadcastLogger) publish(e Event) {
 	if err := b.session.Publish(e); err != nil {
 		log.Printf("[WARN] failed to broadcast event: %v", err)
 	}
@@ -2,6 +2,7 @@ package web
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -309,3 +310,64 @@ func TestExtractTerminalSignal(t *testing.T) {
 		})
 	}
 }
+
+func TestBroadcastLogger_Batching(t *testing.T) {
+	newLogger := func(t *testing.T, interval time.Duration) (*BroadcastLogger, *mocks.LoggerMock, *status.PhaseHolder) {
+		t.Helper()
+		mockLogger := &mocks.LoggerMock{
+			PrintFunc:        func(string, ...any) {},
+			PrintSectionFunc: func(status.Section) {},
+		}
+		session := NewSession("test", "/tmp/test.txt")
+		t.Cleanup(session.Close)
+		holder := &status.PhaseHolder{}
+		holder.Set(status.PhaseTask)
+		bl := NewBroadcastLogger(mockLogger, session, holder)
+		bl.SetBatchInterval(interval)
+		return bl, mockLogger, holder
+	}
+	pendingLen := func(bl *BroadcastLogger) int {
+		bl.mu.Lock()
+		defer bl.mu.Unlock()
+		return len(bl.pending)
+	}
+
+	t.Run("disabled pushes each line", func(t *testing.T) {
+		bl, _, _ := newLogger(t, 0)
+		bl.Print("line 1")
+		bl.Print("line 2")
+		assert.Equal(t, 0, pendingLen(bl))
+	})
+
+	t.Run("queues output but logs every line immediately", func(t *testing.T) {
+		bl, mockLogger, _ := newLogger(t, time.Hour)
+		bl.Print("line 1")
+		bl.Print("line 2")
+		assert.Equal(t, 2, pendingLen(bl))
+		assert.Len(t, mockLogger.PrintCalls(), 2)
+
+		bl.Flush()
+		assert.Equal(t, 0, pendingLen(bl))
+	})
+
+	t.Run("non-output event flushes pending first", func(t *testing.T) {
+		bl, _, _ := newLogger(t, time.Hour)
+		bl.Print("line 1")
+		bl.PrintSection(status.NewGenericSection("next"))
+		assert.Equal(t, 0, pendingLen(bl))
+	})
+
+	t.Run("phase change flushes pending", func(t *testing.T) {
+		bl, _, holder := newLogger(t, time.Hour)
+		bl.Print("line 1")
+		holder.Set(status.PhaseReview)
+		assert.Equal(t, 0, pendingLen(bl))
+	})
+
+	t.Run("timer flushes pending", func(t *testing.T) {
+		bl, _, _ := newLogger(t, 10*time.Millisecond)
+		bl.Print("line 1")
+		bl.Print("line 2")
+		assert.Eventually(t, func() bool { return pendingLen(bl) == 0 }, time.Second, 5*time.Millisecond)
+	})
+}
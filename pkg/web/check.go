@@ -0,0 +1,162 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// checkRequestTimeout caps a single request of the dashboard self-check.
+const checkRequestTimeout = 5 * time.Second
+
+// CheckResult is the outcome of one dashboard self-check request.
+type CheckResult struct {
+	Name string // what was checked, e.g. "index"
+	Path string // request path, e.g. "/"
+	Err  error  // nil when the endpoint responded as expected
+}
+
+// dashboardCheck describes an endpoint requested by RunCheck and how its response is validated.
+type dashboardCheck struct {
+	name     string
+	path     string
+	accept   string                          // Accept header, empty = none
+	validate func(resp *http.Response) error // checks headers of a 200 response
+	body     func(r io.Reader) error         // reads and checks the body
+}
+
+// RunCheck starts the dashboard server on an ephemeral port, requests its endpoints with an in-process
+// client and shuts the server down. it catches template and asset regressions without running a plan
+// and doesn't need a git repository. returns the result of each check, and an error if any failed.
+func (d *Dashboard) RunCheck(ctx context.Context) ([]CheckResult, error) {
+	// the stream sends nothing until there is an event, one is published for the handshake to receive
+	session := NewSession("check", "")
+	if err := session.Publish(NewOutputEvent(status.PhaseTask, "dashboard check")); err != nil {
+		return nil, fmt.Errorf("publish check event: %w", err)
+	}
+	srv, err := NewServer(ServerConfig{Host: d.host, PlanName: "(check)", PhaseNames: d.phaseNames}, session)
+	if err != nil {
+		return nil, fmt.Errorf("create web server: %w", err)
+	}
+
+	host := d.host
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	ln, err := (&net.ListenConfig{}).Listen(ctx, "tcp", net.JoinHostPort(host, "0"))
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+
+	srvCtx, cancel := context.WithCancel(ctx)
+	srvErrCh := make(chan error, 1)
+	go func() { srvErrCh <- srv.Serve(srvCtx, ln) }()
+	defer func() {
+		cancel()
+		<-srvErrCh
+	}()
+
+	baseURL := "http://" + ln.Addr().String()
+	client := &http.Client{Timeout: checkRequestTimeout}
+	results := make([]CheckResult, 0, len(dashboardChecks))
+	var failed int
+	for _, c := range dashboardChecks {
+		res := CheckResult{Name: c.name, Path: c.path, Err: runDashboardCheck(ctx, client, baseURL, c)}
+		if res.Err != nil {
+			failed++
+		}
+		results = append(results, res)
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("dashboard check failed: %d of %d endpoints", failed, len(results))
+	}
+	return results, nil
+}
+
+// runDashboardCheck requests a single endpoint and validates the response.
+func runDashboardCheck(ctx context.Context, client *http.Client, baseURL string, c dashboardCheck) error {
+	reqCtx, cancel := context.WithTimeout(ctx, checkRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, baseURL+c.path, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	if c.accept != "" {
+		req.Header.Set("Accept", c.accept)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if err := c.validate(resp); err != nil {
+		return err
+	}
+	return c.body(resp.Body)
+}
+
+// dashboardChecks lists the endpoints RunCheck requests, in order.
+var dashboardChecks = []dashboardCheck{
+	{name: "index", path: "/", validate: contentType("text/html"), body: bodyContains("</html>")},
+	{name: "script", path: "/static/app.js", validate: contentType("javascript"), body: bodyContains("")},
+	{name: "styles", path: "/static/styles.css", validate: contentType("text/css"), body: bodyContains("")},
+	{name: "sessions", path: "/api/sessions", validate: contentType("application/json"), body: bodyContains("[")},
+	{name: "status", path: "/api/status", validate: contentType("application/json"), body: bodyContains(`"dirs":`)},
+	{name: "stream", path: "/events", accept: "text/event-stream", validate: contentType("text/event-stream"),
+		body: firstEvent},
+}
+
+// contentType returns a validator requiring the Content-Type header to contain want.
+func contentType(want string) func(resp *http.Response) error {
+	return func(resp *http.Response) error {
+		if ct := resp.Header.Get("Content-Type"); !strings.Contains(ct, want) {
+			return fmt.Errorf("unexpected content type %q, want %s", ct, want)
+		}
+		return nil
+	}
+}
+
+// bodyContains returns a body check requiring a non-empty body containing want.
+// the index page is rendered while it is written, so a template error shows as a truncated page.
+func bodyContains(want string) func(r io.Reader) error {
+	return func(r io.Reader) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("read body: %w", err)
+		}
+		if len(data) == 0 {
+			return errors.New("empty body")
+		}
+		if !strings.Contains(string(data), want) {
+			return fmt.Errorf("body doesn't contain %q", want)
+		}
+		return nil
+	}
+}
+
+// firstEvent reads the event stream until the first data line. the stream never ends,
+// so the body is not read further.
+func firstEvent(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "data:") {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read stream: %w", err)
+	}
+	return errors.New("stream ended without an event")
+}
@@ -0,0 +1,57 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboard_RunCheck(t *testing.T) {
+	d := NewDashboard(DashboardConfig{}, nil)
+	results, err := d.RunCheck(t.Context())
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(results))
+	for _, res := range results {
+		require.NoError(t, res.Err, res.Name)
+		names = append(names, res.Name)
+	}
+	assert.Equal(t, []string{"index", "script", "styles", "sessions", "status", "stream"}, names)
+}
+
+func TestRunDashboardCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/truncated":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html><body>"))
+		case "/plain":
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("</html>"))
+		case "/ok":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	check := func(path string) error {
+		return runDashboardCheck(t.Context(), srv.Client(), srv.URL,
+			dashboardCheck{name: "test", path: path, validate: contentType("text/html"), body: bodyContains("</html>")})
+	}
+	require.NoError(t, check("/ok"))
+	require.EqualError(t, check("/truncated"), `body doesn't contain "</html>"`)
+	require.EqualError(t, check("/plain"), `unexpected content type "text/plain", want text/html`)
+	require.EqualError(t, check("/missing"), "unexpected status 404 Not Found")
+}
+
+func TestFirstEvent(t *testing.T) {
+	require.NoError(t, firstEvent(strings.NewReader(": ping\nid: 1\ndata: {}\n\n")))
+	require.EqualError(t, firstEvent(strings.NewReader(": ping\n")), "stream ended without an event")
+}
@@ -26,14 +26,18 @@ func ConnectHost(host string) string {
 
 // DashboardConfig holds configuration for dashboard initialization.
 type DashboardConfig struct {
-	BaseLog         Logger           // base progress logger
-	Port            int              // web server port
-	Host            string           // host/IP to bind to (default "127.0.0.1")
-	PlanFile        string           // path to plan file (empty for watch-only mode)
-	Branch          string           // current git branch
-	WatchDirs       []string         // CLI watch directories
-	ConfigWatchDirs []string         // config file watch directories
-	Colors          *progress.Colors // colors for output
+	BaseLog         Logger            // base progress logger
+	Port            int               // web server port
+	Host            string            // host/IP to bind to (default "127.0.0.1")
+	PlanFile        string            // path to plan file (empty for watch-only mode)
+	Branch          string            // current git branch
+	RunID           string            // run identifier shown in the dashboard title
+	WatchDirs       []string          // CLI watch directories
+	ConfigWatchDirs []string          // config file watch directories
+	Colors          *progress.Colors  // colors for output
+	PhaseNames      status.PhaseNames // custom phase display labels
+	BatchInterval   time.Duration     // coalesce live output for this long before pushing (0 = push every line)
+	PlanPreview     func() string     // plan mode: path of the plan file being written, empty until it exists
 }
 
 // Dashboard manages web server and file watching for progress monitoring.
@@ -42,11 +46,16 @@ type Dashboard struct {
 	host            string
 	planFile        string
 	branch          string
+	runID           string
 	baseLog         Logger
 	watchDirs       []string
 	configWatchDirs []string
 	colors          *progress.Colors
+	phaseNames      status.PhaseNames
+	batchInterval   time.Duration
+	planPreview     func() string
 	holder          *status.PhaseHolder
+	done            chan struct{} // closed when the web server started by Start has stopped
 }
 
 // NewDashboard creates a new dashboard with the given configuration.
@@ -56,11 +65,16 @@ func NewDashboard(cfg DashboardConfig, holder *status.PhaseHolder) *Dashboard {
 		host:            cfg.Host,
 		planFile:        cfg.PlanFile,
 		branch:          cfg.Branch,
+		runID:           cfg.RunID,
 		baseLog:         cfg.BaseLog,
 		watchDirs:       cfg.WatchDirs,
 		configWatchDirs: cfg.ConfigWatchDirs,
 		colors:          cfg.Colors,
+		phaseNames:      cfg.PhaseNames,
+		batchInterval:   cfg.BatchInterval,
+		planPreview:     cfg.PlanPreview,
 		holder:          holder,
+		done:            make(chan struct{}),
 	}
 }
 
@@ -70,7 +84,12 @@ func NewDashboard(cfg DashboardConfig, holder *status.PhaseHolder) *Dashboard {
 func (d *Dashboard) Start(ctx context.Context) (*BroadcastLogger, error) {
 	// create session for SSE streaming (handles both live streaming and history replay)
 	session := NewSession("main", d.baseLog.Path())
+	// the header carries the start time reported by /api/status, the session manager refreshes it in multi-session mode
+	if meta, err := ParseProgressHeader(session.Path); err == nil {
+		session.SetMetadata(meta)
+	}
 	broadcastLog := NewBroadcastLogger(d.baseLog, session, d.holder)
+	broadcastLog.SetBatchInterval(d.batchInterval)
 
 	// extract plan name for display
 	planName := "(no plan)"
@@ -79,11 +98,14 @@ func (d *Dashboard) Start(ctx context.Context) (*BroadcastLogger, error) {
 	}
 
 	cfg := ServerConfig{
-		Port:     d.port,
-		Host:     d.host,
-		PlanName: planName,
-		Branch:   d.branch,
-		PlanFile: d.planFile,
+		Port:       d.port,
+		Host:       d.host,
+		PlanName:   planName,
+		Branch:     d.branch,
+		RunID:      d.runID,
+		PlanFile:   d.planFile,
+		PhaseNames: d.phaseNames,
+		Holder:     d.holder,
 	}
 
 	// determine if we should use multi-session mode
@@ -103,6 +125,7 @@ func (d *Dashboard) Start(ctx context.Context) (*BroadcastLogger, error) {
 
 		// resolve watch directories (CLI > config > cwd)
 		dirs := ResolveWatchDirs(d.watchDirs, d.configWatchDirs)
+		cfg.WatchDirs = dirs
 
 		var err error
 		watcher, err = NewWatcher(dirs, sm)
@@ -114,6 +137,7 @@ func (d *Dashboard) Start(ctx context.Context) (*BroadcastLogger, error) {
 		if err != nil {
 			return nil, fmt.Errorf("create web server: %w", err)
 		}
+		srv.live = session
 	} else {
 		// single-session mode: direct session for current execution
 		var err error
@@ -142,15 +166,27 @@ func (d *Dashboard) Start(ctx context.Context) (*BroadcastLogger, error) {
 	// monitor for late server errors in background
 	// these are logged but don't fail the main execution since the dashboard is supplementary
 	go func() {
+		defer close(d.done)
 		if srvErr := <-srvErrCh; srvErr != nil {
 			log.Printf("[WARN] web server error during execution: %v", srvErr)
 		}
 	}()
 
+	// plan mode has no plan file yet, the preview follows the file claude writes
+	if d.planPreview != nil {
+		go watchPlanPreview(ctx, session.Publish, d.planPreview, planPreviewInterval)
+	}
+
 	d.colors.Info().Printf("web dashboard: http://%s:%d\n", ConnectHost(d.host), d.port)
 	return broadcastLog, nil
 }
 
+// Done returns a channel closed once the web server started by Start has stopped after ctx cancellation,
+// so its port can be reused by the next dashboard.
+func (d *Dashboard) Done() <-chan struct{} {
+	return d.done
+}
+
 // RunWatchOnly runs the web dashboard in watch-only mode without plan execution.
 // monitors directories for progress files and serves the multi-session dashboard.
 func (d *Dashboard) RunWatchOnly(ctx context.Context, dirs []string) error {
@@ -182,11 +218,13 @@ func (d *Dashboard) setupWatchMode(ctx context.Context, dirs []string) (chan err
 	}
 
 	serverCfg := ServerConfig{
-		Port:     d.port,
-		Host:     d.host,
-		PlanName: "(watch mode)",
-		Branch:   "",
-		PlanFile: "",
+		Port:       d.port,
+		Host:       d.host,
+		PlanName:   "(watch mode)",
+		Branch:     "",
+		PlanFile:   "",
+		PhaseNames: d.phaseNames,
+		WatchDirs:  dirs,
 	}
 
 	srv, err := NewServerWithSessions(serverCfg, sm)
@@ -75,6 +75,14 @@ func TestDashboard_Start_SingleSession(t *testing.T) {
 
 	// verify it's a broadcast logger by checking it has the path from base logger
 	assert.Equal(t, baseLog.Path(), broadcastLog.Path())
+
+	// the server stops on cancellation, Done reports it
+	cancel()
+	select {
+	case <-d.Done():
+	case <-time.After(time.Second):
+		t.Fatal("dashboard not stopped after cancel")
+	}
 }
 
 func TestDashboard_Start_MultiSession(t *testing.T) {
@@ -23,6 +23,8 @@ const (
 	EventTypeTaskStart      EventType = "task_start"      // task execution started
 	EventTypeTaskEnd        EventType = "task_end"        // task execution ended
 	EventTypeIterationStart EventType = "iteration_start" // review/codex iteration started
+	EventTypeBatch          EventType = "batch"           // coalesced output lines, see Event.Events
+	EventTypePlanUpdate     EventType = "plan_update"     // plan file written in plan mode changed, Text has its content
 )
 
 // Event represents a single event to be streamed to web clients.
@@ -35,6 +37,8 @@ type Event struct {
 	Signal       string       `json:"signal,omitempty"`
 	TaskNum      int          `json:"task_num,omitempty"`      // 1-based task position in plan (array index + 1)
 	IterationNum int          `json:"iteration_num,omitempty"` // 1-based iteration index for review/codex phases
+	Events       []Event      `json:"events,omitempty"`        // output events carried by a batch event
+	PlanFile     string       `json:"plan_file,omitempty"`     // plan file name of a plan_update event
 }
 
 // NewOutputEvent creates an output event with current timestamp.
@@ -47,6 +51,17 @@ func NewOutputEvent(phase status.Phase, text string) Event {
 	}
 }
 
+// NewBatchEvent creates a batch event carrying several output events of one phase.
+// the batch takes the phase and timestamp of its first event.
+func NewBatchEvent(events []Event) Event {
+	return Event{
+		Type:      EventTypeBatch,
+		Phase:     events[0].Phase,
+		Timestamp: events[0].Timestamp,
+		Events:    events,
+	}
+}
+
 // NewSectionEvent creates a section header event.
 func NewSectionEvent(phase status.Phase, name string) Event {
 	return Event{
@@ -102,6 +117,17 @@ func NewIterationStartEvent(phase status.Phase, iterationNum int, text string) E
 	}
 }
 
+// NewPlanUpdateEvent creates a plan update event carrying the current content of the plan file.
+func NewPlanUpdateEvent(planFile, content string) Event {
+	return Event{
+		Type:      EventTypePlanUpdate,
+		Phase:     status.PhasePlan,
+		Text:      content,
+		PlanFile:  planFile,
+		Timestamp: time.Now(),
+	}
+}
+
 // MarshalJSON implements json.Marshaler for SSE streaming.
 // this allows Event to be used directly with json.Marshal.
 func (e Event) MarshalJSON() ([]byte, error) {
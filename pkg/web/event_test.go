@@ -239,3 +239,19 @@ func TestEvent_ToSSEMessage(t *testing.T) {
 		assert.Contains(t, string(data), "task_start")
 	})
 }
+
+func TestNewBatchEvent(t *testing.T) {
+	first := NewOutputEvent(status.PhaseTask, "line 1")
+	second := NewOutputEvent(status.PhaseTask, "line 2")
+	e := NewBatchEvent([]Event{first, second})
+
+	assert.Equal(t, EventTypeBatch, e.Type)
+	assert.Equal(t, status.PhaseTask, e.Phase)
+	assert.Equal(t, first.Timestamp, e.Timestamp)
+	assert.Equal(t, []Event{first, second}, e.Events)
+
+	data, err := json.Marshal(e)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"type":"batch"`)
+	assert.Contains(t, string(data), `"text":"line 2"`)
+}
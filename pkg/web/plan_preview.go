@@ -0,0 +1,43 @@
+package web
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// planPreviewInterval is how often the plan being written in plan mode is checked for changes.
+const planPreviewInterval = 500 * time.Millisecond
+
+// watchPlanPreview publishes the content of the plan file returned by find as a plan_update event
+// whenever it changes, until ctx is canceled. find returns an empty path until the plan file exists.
+// polling is used since the file name is only known once claude creates it.
+func watchPlanPreview(ctx context.Context, publish func(Event) error, find func() string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var lastPath, lastContent string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		path := find()
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path) //nolint:gosec // path comes from the plans dir lookup
+		if err != nil {
+			continue // the file may be mid-rename, the next tick retries
+		}
+		if path == lastPath && string(data) == lastContent {
+			continue
+		}
+		lastPath, lastContent = path, string(data)
+		if err := publish(NewPlanUpdateEvent(filepath.Base(path), lastContent)); err != nil {
+			log.Printf("[WARN] failed to publish plan preview: %v", err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package web
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestWatchPlanPreview(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "add-auth.md")
+	var mu sync.Mutex
+	var found string
+	var events []Event
+	find := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return found
+	}
+	publish := func(e Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+		return nil
+	}
+	published := func() []Event {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]Event(nil), events...)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		watchPlanPreview(ctx, publish, find, time.Millisecond)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Empty(t, published(), "nothing published before the plan exists")
+
+	require.NoError(t, os.WriteFile(planFile, []byte("# Add auth\n"), 0o600))
+	mu.Lock()
+	found = planFile
+	mu.Unlock()
+	require.Eventually(t, func() bool { return len(published()) == 1 }, time.Second, time.Millisecond)
+
+	time.Sleep(10 * time.Millisecond)
+	require.Len(t, published(), 1, "unchanged content is not published again")
+
+	require.NoError(t, os.WriteFile(planFile, []byte("# Add auth\n\n### Task 1: Login\n"), 0o600))
+	require.Eventually(t, func() bool { return len(published()) == 2 }, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+	got := published()
+	assert.Equal(t, EventTypePlanUpdate, got[0].Type)
+	assert.Equal(t, status.PhasePlan, got[0].Phase)
+	assert.Equal(t, "add-auth.md", got[0].PlanFile)
+	assert.Equal(t, "# Add auth\n", got[0].Text)
+	assert.Equal(t, "# Add auth\n\n### Task 1: Login\n", got[1].Text)
+}
@@ -0,0 +1,188 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// default pacing for replayed progress files.
+const (
+	defaultReplayLineDelay = 10 * time.Millisecond // delay between lines when original timing is not used
+	defaultReplayMaxGap    = 5 * time.Second       // cap on a single pause when original timing is used
+)
+
+// ReplayConfig controls how a recorded progress file is streamed to the dashboard.
+type ReplayConfig struct {
+	Path      string        // progress file to replay
+	Realtime  bool          // reproduce original pacing from line timestamps
+	LineDelay time.Duration // delay between lines when not realtime (default 10ms)
+	MaxGap    time.Duration // cap on a single realtime pause (default 5s)
+}
+
+// RunReplay serves the dashboard for a recorded progress file, streaming its lines
+// as if the run were live, then keeps the final state available until ctx is canceled.
+// it is read-only and doesn't require a git repository.
+func (d *Dashboard) RunReplay(ctx context.Context, rc ReplayConfig) error {
+	meta, err := ParseProgressHeader(rc.Path)
+	if err != nil {
+		return fmt.Errorf("read progress file: %w", err)
+	}
+
+	session := NewSession(sessionIDFromPath(rc.Path), rc.Path)
+	session.SetMetadata(meta)
+	session.SetState(SessionStateActive)
+
+	planName := "(replay)"
+	planFile := ""
+	if meta.PlanPath != "" {
+		planName = filepath.Base(meta.PlanPath)
+		// plan panel is best-effort, recorded plan path is often relative to another checkout
+		if _, statErr := os.Stat(meta.PlanPath); statErr == nil {
+			planFile = meta.PlanPath
+		}
+	}
+
+	srv, err := NewServer(ServerConfig{
+		Port:       d.port,
+		Host:       d.host,
+		PlanName:   planName,
+		Branch:     meta.Branch,
+		PlanFile:   planFile,
+		PhaseNames: d.phaseNames,
+	}, session)
+	if err != nil {
+		return fmt.Errorf("create web server: %w", err)
+	}
+
+	srvErrCh, err := startServerAsync(ctx, srv, d.port)
+	if err != nil {
+		return err
+	}
+
+	d.colors.Info().Printf("replaying %s\n", rc.Path)
+	d.colors.Info().Printf("web dashboard: http://%s:%d\n", ConnectHost(d.host), d.port)
+
+	if err := streamProgressFile(ctx, rc, func(e Event) { publishReplayEvent(session, e) }); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("replay progress file: %w", err)
+	}
+	session.SetState(SessionStateCompleted)
+
+	d.colors.Info().Printf("replay finished, press Ctrl+C to exit\n")
+	return d.monitorErrors(ctx, srvErrCh, nil)
+}
+
+// streamProgressFile reads the progress file line by line and passes parsed events to emit,
+// pausing between lines according to rc. returns ctx error if canceled mid-stream.
+func streamProgressFile(ctx context.Context, rc ReplayConfig, emit func(Event)) error {
+	f, err := os.Open(rc.Path) //nolint:gosec // path provided by user via --replay
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	lineDelay := rc.LineDelay
+	if lineDelay <= 0 {
+		lineDelay = defaultReplayLineDelay
+	}
+	maxGap := rc.MaxGap
+	if maxGap <= 0 {
+		maxGap = defaultReplayMaxGap
+	}
+
+	// reuse tailer parsing so replayed events match what live tailing produces
+	parser := NewTailer(rc.Path, DefaultTailerConfig())
+	parser.deferSections = true
+
+	reader := bufio.NewReader(f)
+	var prevTS time.Time
+	for {
+		line, readErr := reader.ReadString('\n')
+		line = trimLineEnding(line)
+
+		if events := parseReplayLine(parser, line); len(events) > 0 {
+			delay := lineDelay
+			if rc.Realtime && timestampRegex.MatchString(line) {
+				delay, prevTS = replayGap(prevTS, events[len(events)-1].Timestamp, maxGap)
+			}
+			if err := sleepCtx(ctx, delay); err != nil {
+				return err
+			}
+			for _, event := range events {
+				emit(event)
+			}
+		}
+
+		if readErr != nil {
+			if errors.Is(readErr, io.EOF) {
+				break
+			}
+			return fmt.Errorf("read file: %w", readErr)
+		}
+	}
+
+	for _, event := range parser.emitPendingSection(time.Now()) {
+		emit(event)
+	}
+	return nil
+}
+
+// parseReplayLine converts a progress line into events, skipping empty lines.
+func parseReplayLine(parser *Tailer, line string) []Event {
+	if line == "" {
+		return nil
+	}
+	return parser.parseLineDeferred(line)
+}
+
+// replayGap returns the pause before an event recorded at ts, clamped to [0, maxGap],
+// and the timestamp to compare the next event against. the first timestamped event has no pause.
+func replayGap(prev, ts time.Time, maxGap time.Duration) (time.Duration, time.Time) {
+	if prev.IsZero() {
+		return 0, ts
+	}
+	gap := ts.Sub(prev)
+	switch {
+	case gap < 0:
+		gap = 0
+	case gap > maxGap:
+		gap = maxGap
+	}
+	return gap, ts
+}
+
+// publishReplayEvent publishes an event to the session, tracking diff stats like live tailing does.
+func publishReplayEvent(session *Session, event Event) {
+	if event.Type == EventTypeOutput {
+		if stats, ok := parseDiffStats(event.Text); ok {
+			session.SetDiffStats(stats)
+		}
+	}
+	if err := session.Publish(event); err != nil {
+		log.Printf("[WARN] failed to publish replayed event: %v", err)
+	}
+}
+
+// sleepCtx waits for d or until ctx is canceled.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,148 @@
+package web
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+const replayTestContent = `# Ralphex Progress Log
+Plan: docs/plans/feature.md
+Branch: feature
+Mode: full
+Started: 2026-01-22 10:00:00
+------------------------------------------------------------
+
+--- task iteration 1 ---
+[26-01-22 10:00:01] executing task
+[26-01-22 10:00:03] DIFFSTATS: files=2 additions=5 deletions=1
+--- claude review 0 ---
+[26-01-22 10:00:04] review started
+[26-01-22 10:00:05] <<<RALPHEX:REVIEW_DONE>>>
+`
+
+func writeReplayFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "progress-feature.txt")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestStreamProgressFile(t *testing.T) {
+	t.Run("emits parsed events in order", func(t *testing.T) {
+		path := writeReplayFile(t, replayTestContent)
+
+		var events []Event
+		err := streamProgressFile(context.Background(), ReplayConfig{Path: path, LineDelay: time.Microsecond},
+			func(e Event) { events = append(events, e) })
+		require.NoError(t, err)
+
+		require.Len(t, events, 7)
+		assert.Equal(t, EventTypeTaskStart, events[0].Type)
+		assert.Equal(t, 1, events[0].TaskNum)
+		assert.Equal(t, EventTypeSection, events[1].Type)
+		assert.Equal(t, "task iteration 1", events[1].Section)
+		assert.Equal(t, "executing task", events[2].Text)
+		assert.Equal(t, events[2].Timestamp, events[1].Timestamp, "section uses first line timestamp")
+		assert.Equal(t, EventTypeSection, events[4].Type)
+		assert.Equal(t, status.PhaseReview, events[5].Phase)
+		assert.Equal(t, EventTypeSignal, events[6].Type)
+		assert.Equal(t, "REVIEW_DONE", events[6].Signal)
+	})
+
+	t.Run("flushes trailing section", func(t *testing.T) {
+		path := writeReplayFile(t, "header\n"+"-----------------------------------------------------\n"+
+			"[26-01-22 10:00:01] line\n--- codex iteration 1 ---\n")
+
+		var events []Event
+		err := streamProgressFile(context.Background(), ReplayConfig{Path: path, LineDelay: time.Microsecond},
+			func(e Event) { events = append(events, e) })
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, EventTypeSection, events[1].Type)
+		assert.Equal(t, "codex iteration 1", events[1].Section)
+	})
+
+	t.Run("realtime pacing is capped by max gap", func(t *testing.T) {
+		path := writeReplayFile(t, replayTestContent)
+
+		start := time.Now()
+		var count int
+		err := streamProgressFile(context.Background(),
+			ReplayConfig{Path: path, Realtime: true, MaxGap: 20 * time.Millisecond},
+			func(Event) { count++ })
+		require.NoError(t, err)
+		assert.Equal(t, 7, count)
+		// three gaps between four timestamped lines, each capped at 20ms
+		assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("stops on context cancel", func(t *testing.T) {
+		path := writeReplayFile(t, replayTestContent)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var count int
+		err := streamProgressFile(ctx, ReplayConfig{Path: path, LineDelay: time.Hour}, func(Event) { count++ })
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Zero(t, count)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		err := streamProgressFile(context.Background(), ReplayConfig{Path: "/nonexistent/progress.txt"}, func(Event) {})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "open file")
+	})
+}
+
+func TestReplayGap(t *testing.T) {
+	base := time.Date(2026, 1, 22, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		name     string
+		prev, ts time.Time
+		want     time.Duration
+	}{
+		{name: "first event", prev: time.Time{}, ts: base, want: 0},
+		{name: "normal gap", prev: base, ts: base.Add(2 * time.Second), want: 2 * time.Second},
+		{name: "capped gap", prev: base, ts: base.Add(time.Hour), want: 5 * time.Second},
+		{name: "clock went back", prev: base, ts: base.Add(-time.Second), want: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gap, next := replayGap(tc.prev, tc.ts, 5*time.Second)
+			assert.Equal(t, tc.want, gap)
+			assert.Equal(t, tc.ts, next)
+		})
+	}
+}
+
+func TestDashboard_RunReplay(t *testing.T) {
+	t.Run("replays and holds until canceled", func(t *testing.T) {
+		path := writeReplayFile(t, replayTestContent)
+		d := NewDashboard(DashboardConfig{Port: 0, Colors: testColors()}, nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := d.RunReplay(ctx, ReplayConfig{Path: path, LineDelay: time.Millisecond})
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond, "should hold final state until canceled")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		d := NewDashboard(DashboardConfig{Port: 0, Colors: testColors()}, nil)
+		err := d.RunReplay(context.Background(), ReplayConfig{Path: "/nonexistent/progress.txt"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "read progress file")
+	})
+}
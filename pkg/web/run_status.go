@@ -0,0 +1,194 @@
+package web
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// RunStatus is the state of one run reported by /api/status.
+type RunStatus struct {
+	Dir        string       `json:"dir,omitempty"`     // watched directory, set for per-directory entries
+	Session    string       `json:"session,omitempty"` // session ID, empty when the directory has no runs yet
+	Phase      status.Phase `json:"phase,omitempty"`   // current execution phase
+	Active     bool         `json:"active"`            // run is in progress
+	Complete   bool         `json:"complete"`          // run has finished
+	Signal     string       `json:"signal,omitempty"`  // last signal seen, e.g. COMPLETED or FAILED
+	Task       int          `json:"task,omitempty"`    // number of the last started task
+	Iterations int          `json:"iterations"`        // task and review iterations started so far
+	StartTime  time.Time    `json:"startTime,omitzero"`
+	Elapsed    string       `json:"elapsed,omitempty"` // elapsed time, e.g. "5m30s", frozen once the run is complete
+	ElapsedSec int64        `json:"elapsedSec"`
+}
+
+// StatusResponse is the /api/status payload. the schema is the same in every mode:
+// run is null in watch-only mode and dirs is empty without watch directories.
+type StatusResponse struct {
+	Run  *RunStatus  `json:"run"`  // the run executed by this process
+	Dirs []RunStatus `json:"dirs"` // latest run of each watched directory
+}
+
+// sessionActivity is what a session has seen in its published events.
+type sessionActivity struct {
+	phase      status.Phase
+	task       int
+	iterations int
+	signal     string
+}
+
+// observe updates the activity from a published event.
+func (a *sessionActivity) observe(e Event) {
+	if e.Phase != "" {
+		a.phase = e.Phase
+	}
+	switch e.Type {
+	case EventTypeTaskStart:
+		a.task = e.TaskNum
+		a.iterations++
+	case EventTypeIterationStart:
+		a.iterations++
+	case EventTypeSignal:
+		a.signal = e.Signal
+	default:
+	}
+}
+
+// handleStatus returns the current phase, elapsed time and iteration count as JSON.
+// intended for external status widgets polling the dashboard.
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := StatusResponse{Dirs: []RunStatus{}}
+	if s.live != nil {
+		st := s.runStatus(s.live, s.cfg.Holder != nil, time.Now())
+		if s.cfg.Holder != nil {
+			st.Phase = s.cfg.Holder.Get()
+		}
+		resp.Run = &st
+	}
+	if s.sm != nil {
+		resp.Dirs = s.dirStatuses(time.Now())
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("[WARN] failed to encode status: %v", err)
+		http.Error(w, "unable to encode status", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// dirStatuses reports the most recently modified session of each watched directory.
+func (s *Server) dirStatuses(now time.Time) []RunStatus {
+	sessions := s.sm.All()
+	res := make([]RunStatus, 0, len(s.cfg.WatchDirs))
+	for _, dir := range s.cfg.WatchDirs {
+		var latest *Session
+		for _, session := range sessions {
+			if session == s.live || !pathWithin(session.Path, dir) {
+				continue
+			}
+			if latest == nil || session.GetLastModified().After(latest.GetLastModified()) {
+				latest = session
+			}
+		}
+		st := RunStatus{}
+		if latest != nil {
+			st = s.runStatus(latest, false, now)
+		}
+		st.Dir = dir
+		res = append(res, st)
+	}
+	return res
+}
+
+// runStatus builds the status of a session. for the live run the progress file lock tells
+// whether it is still going, other sessions rely on the state kept by the session manager.
+func (s *Server) runStatus(session *Session, live bool, now time.Time) RunStatus {
+	act := session.activity()
+	st := RunStatus{
+		Session:    session.ID,
+		Phase:      act.phase,
+		Signal:     act.signal,
+		Task:       act.task,
+		Iterations: act.iterations,
+		StartTime:  session.GetMetadata().StartTime,
+	}
+
+	st.Active = session.GetState() == SessionStateActive
+	if live && session.Path != "" {
+		active, err := IsActive(session.Path)
+		if err != nil {
+			log.Printf("[WARN] failed to check progress file %s: %v", session.Path, err)
+		}
+		st.Active = active
+	}
+	st.Complete = !st.Active
+
+	if st.StartTime.IsZero() {
+		return st
+	}
+	end := now
+	if st.Complete {
+		end = sessionEndTime(session, now)
+	}
+	if d := end.Sub(st.StartTime); d > 0 {
+		st.Elapsed = formatElapsed(d)
+		st.ElapsedSec = int64(d / time.Second)
+	}
+	return st
+}
+
+// sessionEndTime returns when a finished session last wrote to its progress file, or now if unknown.
+func sessionEndTime(session *Session, now time.Time) time.Time {
+	if t := session.GetLastModified(); !t.IsZero() {
+		return t
+	}
+	if session.Path == "" {
+		return now
+	}
+	fi, err := os.Stat(session.Path)
+	if err != nil {
+		return now
+	}
+	return fi.ModTime()
+}
+
+// formatElapsed formats a duration the way the progress log does,
+// truncated to minutes from one hour on (e.g. "1h23m"), otherwise to seconds (e.g. "5m30s").
+func formatElapsed(d time.Duration) string {
+	if d >= time.Hour {
+		return strings.TrimSuffix(d.Truncate(time.Minute).String(), "0s")
+	}
+	return d.Truncate(time.Second).String()
+}
+
+// pathWithin reports whether path is inside dir, both resolved to absolute paths.
+func pathWithin(path, dir string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
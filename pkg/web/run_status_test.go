@@ -0,0 +1,142 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestServer_HandleStatus(t *testing.T) {
+	getStatus := func(t *testing.T, srv *Server) StatusResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		srv.handleStatus(w, httptest.NewRequest(http.MethodGet, "/api/status", http.NoBody))
+		resp := w.Result()
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		var res StatusResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return res
+	}
+
+	t.Run("live run reports holder phase and activity", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "progress-plan.txt")
+		require.NoError(t, os.WriteFile(path, []byte("# Ralphex Progress Log\n"), 0o600))
+		session := NewSession("main", path)
+		defer session.Close()
+		session.SetMetadata(SessionMetadata{StartTime: time.Now().Add(-90 * time.Second)})
+		require.NoError(t, session.Publish(NewTaskStartEvent(status.PhaseTask, 1, "task 1")))
+		require.NoError(t, session.Publish(NewTaskStartEvent(status.PhaseTask, 2, "task 2")))
+		require.NoError(t, session.Publish(NewIterationStartEvent(status.PhaseReview, 1, "review 1")))
+
+		holder := &status.PhaseHolder{}
+		holder.Set(status.PhaseCodex)
+		srv, err := NewServer(ServerConfig{Holder: holder}, session)
+		require.NoError(t, err)
+
+		res := getStatus(t, srv)
+		require.NotNil(t, res.Run)
+		assert.Empty(t, res.Dirs)
+		assert.Equal(t, "main", res.Run.Session)
+		assert.Equal(t, status.PhaseCodex, res.Run.Phase, "phase comes from the holder")
+		assert.Equal(t, 2, res.Run.Task)
+		assert.Equal(t, 3, res.Run.Iterations)
+		assert.False(t, res.Run.Active, "unlocked progress file means the run has finished")
+		assert.True(t, res.Run.Complete)
+	})
+
+	t.Run("active run counts elapsed time to now", func(t *testing.T) {
+		session := NewSession("replay", "")
+		defer session.Close()
+		session.SetState(SessionStateActive)
+		session.SetMetadata(SessionMetadata{StartTime: time.Now().Add(-2 * time.Hour)})
+		require.NoError(t, session.Publish(NewSignalEvent(status.PhaseReview, "REVIEW_DONE")))
+		srv, err := NewServer(ServerConfig{}, session)
+		require.NoError(t, err)
+
+		res := getStatus(t, srv)
+		require.NotNil(t, res.Run)
+		assert.True(t, res.Run.Active)
+		assert.False(t, res.Run.Complete)
+		assert.Equal(t, status.PhaseReview, res.Run.Phase, "phase comes from events without a holder")
+		assert.Equal(t, "REVIEW_DONE", res.Run.Signal)
+		assert.Equal(t, "2h0m", res.Run.Elapsed)
+		assert.InDelta(t, 7200, res.Run.ElapsedSec, 5)
+	})
+
+	t.Run("watch-only mode reports the latest session per directory", func(t *testing.T) {
+		root := t.TempDir()
+		dirA, dirB, dirC := filepath.Join(root, "a"), filepath.Join(root, "b"), filepath.Join(root, "c")
+		for _, dir := range []string{dirA, dirB, dirC} {
+			require.NoError(t, os.MkdirAll(dir, 0o750))
+		}
+		header := "# Ralphex Progress Log\nPlan: plan.md\nStarted: 2026-01-22 10:30:00\n" +
+			"------------------------------------------------------------\n"
+		oldPath := filepath.Join(dirA, "progress-old.txt")
+		newPath := filepath.Join(dirA, "progress-new.txt")
+		otherPath := filepath.Join(dirB, "progress-other.txt")
+		for _, p := range []string{oldPath, newPath, otherPath} {
+			require.NoError(t, os.WriteFile(p, []byte(header), 0o600))
+		}
+		started := time.Date(2026, 1, 22, 10, 30, 0, 0, time.Local)
+		require.NoError(t, os.Chtimes(oldPath, started, started.Add(time.Minute)))
+		require.NoError(t, os.Chtimes(newPath, started, started.Add(5*time.Minute)))
+
+		sm := NewSessionManager()
+		defer sm.Close()
+		_, err := sm.DiscoverRecursive(root)
+		require.NoError(t, err)
+		srv, err := NewServerWithSessions(ServerConfig{WatchDirs: []string{dirA, dirB, dirC}}, sm)
+		require.NoError(t, err)
+
+		res := getStatus(t, srv)
+		assert.Nil(t, res.Run)
+		require.Len(t, res.Dirs, 3)
+
+		assert.Equal(t, dirA, res.Dirs[0].Dir)
+		assert.Equal(t, sessionIDFromPath(newPath), res.Dirs[0].Session)
+		assert.True(t, res.Dirs[0].Complete)
+		assert.Equal(t, "5m0s", res.Dirs[0].Elapsed, "completed run stops at the last write")
+		assert.Equal(t, int64(300), res.Dirs[0].ElapsedSec)
+
+		assert.Equal(t, dirB, res.Dirs[1].Dir)
+		assert.Equal(t, sessionIDFromPath(otherPath), res.Dirs[1].Session)
+
+		assert.Equal(t, RunStatus{Dir: dirC}, res.Dirs[2], "directory without runs")
+	})
+
+	t.Run("rejects non-GET methods", func(t *testing.T) {
+		srv, err := NewServer(ServerConfig{}, NewSession("main", ""))
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		srv.handleStatus(w, httptest.NewRequest(http.MethodPost, "/api/status", http.NoBody))
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestPathWithin(t *testing.T) {
+	tests := []struct {
+		path, dir string
+		want      bool
+	}{
+		{"/work/proj/progress.txt", "/work/proj", true},
+		{"/work/proj/.ralphex/progress/progress.txt", "/work/proj", true},
+		{"/work/project2/progress.txt", "/work/proj", false},
+		{"/work/progress.txt", "/work/proj", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, pathWithin(tt.path, tt.dir))
+		})
+	}
+}
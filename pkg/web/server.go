@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/umputun/ralphex/pkg/plan"
+	"github.com/umputun/ralphex/pkg/status"
 )
 
 //go:embed templates static
@@ -28,7 +29,13 @@ type ServerConfig struct {
 	Host     string // host/IP to bind to (default "127.0.0.1")
 	PlanName string // plan name to display in dashboard
 	Branch   string // git branch name
+	RunID    string // run identifier shown in the page title
 	PlanFile string // path to plan file for /api/plan endpoint
+
+	PhaseNames status.PhaseNames // custom phase display labels for tabs and section headers
+
+	Holder    *status.PhaseHolder // phase of the run being executed, nil when the server doesn't run a plan
+	WatchDirs []string            // watched directories, /api/status reports the latest run of each
 }
 
 // host returns the bind address, defaulting to "127.0.0.1" if not set.
@@ -44,6 +51,7 @@ type Server struct {
 	cfg     ServerConfig
 	session *Session        // used for single-session mode (direct execution)
 	sm      *SessionManager // used for multi-session mode (dashboard)
+	live    *Session        // session of the run served by this process, reported as run by /api/status
 	srv     *http.Server
 	tmpl    *template.Template
 }
@@ -59,6 +67,7 @@ func NewServer(cfg ServerConfig, session *Session) (*Server, error) {
 	return &Server{
 		cfg:     cfg,
 		session: session,
+		live:    session,
 		tmpl:    tmpl,
 	}, nil
 }
@@ -81,6 +90,16 @@ func NewServerWithSessions(cfg ServerConfig, sm *SessionManager) (*Server, error
 // Start begins listening for HTTP requests.
 // blocks until the server is stopped or an error occurs.
 func (s *Server) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", net.JoinHostPort(s.cfg.host(), strconv.Itoa(s.cfg.Port)))
+	if err != nil {
+		return fmt.Errorf("http server: %w", err)
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve handles HTTP requests on an existing listener, e.g. one bound to an ephemeral port.
+// blocks until the server is stopped or an error occurs, the listener is closed on return.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
 	mux := http.NewServeMux()
 
 	// register routes
@@ -88,16 +107,18 @@ func (s *Server) Start(ctx context.Context) error {
 	mux.HandleFunc("/events", s.handleEvents)
 	mux.HandleFunc("/api/plan", s.handlePlan)
 	mux.HandleFunc("/api/sessions", s.handleSessions)
+	mux.HandleFunc("/api/status", s.handleStatus)
 
 	// static files
 	staticFS, err := fs.Sub(embeddedFS, "static")
 	if err != nil {
+		_ = ln.Close()
 		return fmt.Errorf("static filesystem: %w", err)
 	}
 	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))))
 
 	s.srv = &http.Server{
-		Addr:              net.JoinHostPort(s.cfg.host(), strconv.Itoa(s.cfg.Port)),
+		Addr:              ln.Addr().String(),
 		Handler:           mux,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
@@ -110,7 +131,7 @@ func (s *Server) Start(ctx context.Context) error {
 		_ = s.srv.Shutdown(shutdownCtx)
 	}()
 
-	err = s.srv.ListenAndServe()
+	err = s.srv.Serve(ln)
 	if errors.Is(err, http.ErrServerClosed) {
 		return nil
 	}
@@ -137,8 +158,19 @@ func (s *Server) Session() *Session {
 
 // templateData holds data for the dashboard template.
 type templateData struct {
-	PlanName string
-	Branch   string
+	PlanName   string
+	Branch     string
+	RunID      string
+	PhaseNames map[string]string // custom phase labels, rendered into the page for app.js
+}
+
+// PhaseLabel returns the custom label for phase, or def when the phase is not mapped.
+// used by the template for phase filter tabs, whose default labels differ from phase names.
+func (d templateData) PhaseLabel(phase, def string) string {
+	if label := d.PhaseNames[phase]; label != "" {
+		return label
+	}
+	return def
 }
 
 // handleIndex serves the main dashboard page.
@@ -153,6 +185,13 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	data := templateData{
 		PlanName: s.cfg.PlanName,
 		Branch:   s.cfg.Branch,
+		RunID:    s.cfg.RunID,
+	}
+	if len(s.cfg.PhaseNames) > 0 {
+		data.PhaseNames = make(map[string]string, len(s.cfg.PhaseNames))
+		for phase, label := range s.cfg.PhaseNames {
+			data.PhaseNames[string(phase)] = label
+		}
 	}
 
 	if err := s.tmpl.Execute(w, data); err != nil {
@@ -308,6 +347,7 @@ type SessionInfo struct {
 	PlanPath     string     `json:"planPath,omitempty"`
 	Branch       string     `json:"branch,omitempty"`
 	Mode         string     `json:"mode,omitempty"`
+	Task         int        `json:"task,omitempty"` // set for per-task progress segments
 	StartTime    time.Time  `json:"startTime"`
 	LastModified time.Time  `json:"lastModified"`
 	DiffStats    *DiffStats `json:"diffStats,omitempty"`
@@ -356,6 +396,7 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 			PlanPath:     meta.PlanPath,
 			Branch:       meta.Branch,
 			Mode:         meta.Mode,
+			Task:         meta.Task,
 			StartTime:    meta.StartTime,
 			LastModified: session.GetLastModified(),
 			DiffStats:    session.GetDiffStats(),
@@ -75,6 +75,46 @@ func TestServer_HandleIndex(t *testing.T) {
 
 		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 	})
+
+	t.Run("default phase labels", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		srv.handleIndex(w, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+		body := w.Body.String()
+		assert.Contains(t, body, `data-phase="task">Implementation</button>`)
+		assert.Contains(t, body, `data-phase="codex">Codex Review</button>`)
+		assert.Regexp(t, `window\.RALPHEX_PHASE_NAMES =\s+null\s+\|\| \{\};`, body)
+	})
+
+	t.Run("custom phase labels", func(t *testing.T) {
+		custom, err := NewServer(ServerConfig{
+			PlanName:   "my-plan.md",
+			PhaseNames: status.PhaseNames{status.PhaseTask: "Build", status.PhaseClaudeEval: "Triage <x>"},
+		}, session)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		custom.handleIndex(w, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+		body := w.Body.String()
+		assert.Contains(t, body, `data-phase="task">Build</button>`)
+		assert.Contains(t, body, `data-phase="review">Claude Review</button>`, "unmapped phase keeps default label")
+		assert.Contains(t, body, `"task":"Build"`)
+		assert.Contains(t, body, `"claude-eval":"Triage \u003cx\u003e"`, "labels are escaped in script context")
+	})
+
+	t.Run("run id in title", func(t *testing.T) {
+		withID, err := NewServer(ServerConfig{PlanName: "my-plan.md", RunID: "20260122-103000-4f2a9c"}, session)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		withID.handleIndex(w, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+		assert.Contains(t, w.Body.String(), "<title>Ralphex Dashboard - my-plan.md (20260122-103000-4f2a9c)</title>")
+
+		w = httptest.NewRecorder()
+		srv.handleIndex(w, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+		assert.Contains(t, w.Body.String(), "<title>Ralphex Dashboard - my-plan.md</title>")
+	})
 }
 
 func TestServer_HandleEvents(t *testing.T) {
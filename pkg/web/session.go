@@ -54,7 +54,9 @@ const (
 type SessionMetadata struct {
 	PlanPath  string    // path to plan file (from "Plan:" header line)
 	Branch    string    // git branch (from "Branch:" header line)
-	Mode      string    // execution mode: full, review, codex-only (from "Mode:" header line)
+	Mode      string    // execution mode: full, review, external-only (from "Mode:" header line)
+	RunID     string    // run identifier (from "Run:" header line), empty for logs written before run IDs
+	Task      int       // task number of a per-task segment (from "Task:" header line), 0 for a full progress file
 	StartTime time.Time // start time (from "Started:" header line)
 }
 
@@ -81,6 +83,9 @@ type Session struct {
 	// diffStats holds git diff statistics when available (nil if not set)
 	diffStats *DiffStats
 
+	// act tracks phase, iterations and signals seen in published events, reported by /api/status
+	act sessionActivity
+
 	// stopTailCh signals the tail feeder goroutine to stop
 	stopTailCh chan struct{}
 
@@ -196,6 +201,13 @@ func (s *Session) SetDiffStats(stats DiffStats) {
 	s.diffStats = &stats
 }
 
+// activity returns what the session has seen in its published events.
+func (s *Session) activity() sessionActivity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.act
+}
+
 // IsLoaded returns whether historical data has been loaded into the SSE server.
 func (s *Session) IsLoaded() bool {
 	s.mu.RLock()
@@ -264,6 +276,10 @@ func (s *Session) IsTailing() bool {
 // Publish sends an event to all connected SSE clients and stores it for replay.
 // returns an error if publishing fails.
 func (s *Session) Publish(event Event) error {
+	s.mu.Lock()
+	s.act.observe(event)
+	s.mu.Unlock()
+
 	msg := event.ToSSEMessage()
 	if err := s.SSE.Publish(msg, defaultTopic); err != nil {
 		return fmt.Errorf("publish event: %w", err)
@@ -21,6 +21,9 @@ import (
 //	Plan: path/to/plan.md
 //	Branch: feature-branch
 //	Mode: full
+//	Run: 20260122-103000-4f2a9c (when set)
+//	Tools: claude 2.1.3 (Claude Code), codex codex-cli 0.46.0 (when detected)
+//	Task: 3 (per-task segments only)
 //	Started: 2026-01-22 10:30:00
 //	------------------------------------------------------------
 func ParseProgressHeader(path string) (SessionMetadata, error) {
@@ -53,6 +56,12 @@ func ParseProgressHeader(path string) (SessionMetadata, error) {
 			meta.Branch = val
 		} else if val, found := strings.CutPrefix(line, "Mode: "); found {
 			meta.Mode = val
+		} else if val, found := strings.CutPrefix(line, "Run: "); found {
+			meta.RunID = val
+		} else if val, found := strings.CutPrefix(line, "Task: "); found {
+			if n, convErr := strconv.Atoi(val); convErr == nil {
+				meta.Task = n
+			}
 		} else if val, found := strings.CutPrefix(line, "Started: "); found {
 			// header timestamps are written in local time without a zone offset
 			if t, parseErr := time.ParseInLocation("2006-01-02 15:04:05", val, time.Local); parseErr == nil {
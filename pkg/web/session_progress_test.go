@@ -36,6 +36,19 @@ Started: 2026-01-22 10:30:00
 		assert.Equal(t, "feature-branch", meta.Branch)
 		assert.Equal(t, "full", meta.Mode)
 		assert.Equal(t, time.Date(2026, 1, 22, 10, 30, 0, 0, time.Local), meta.StartTime)
+		assert.Empty(t, meta.RunID)
+	})
+
+	t.Run("parses run id", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "progress-test.txt")
+		content := "# Ralphex Progress Log\nPlan: docs/plans/my-plan.md\nBranch: main\nMode: full\n" +
+			"Run: 20260122-103000-4f2a9c\nStarted: 2026-01-22 10:30:00\n" + strings.Repeat("-", 60) + "\n"
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		meta, err := ParseProgressHeader(path)
+		require.NoError(t, err)
+		assert.Equal(t, "20260122-103000-4f2a9c", meta.RunID)
+		assert.Equal(t, "full", meta.Mode)
 	})
 
 	t.Run("handles review-only mode", func(t *testing.T) {
@@ -58,6 +71,27 @@ Started: 2026-01-22 11:00:00
 		assert.Equal(t, "review", meta.Mode)
 	})
 
+	t.Run("parses task segment number", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "progress-test-task-3.txt")
+
+		content := `# Ralphex Progress Log
+Plan: docs/plans/test.md
+Branch: test
+Mode: full
+Task: 3
+Started: 2026-01-22 11:00:00
+------------------------------------------------------------
+`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		meta, err := ParseProgressHeader(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, meta.Task)
+		assert.Equal(t, "full", meta.Mode)
+	})
+
 	t.Run("handles missing fields gracefully", func(t *testing.T) {
 		dir := t.TempDir()
 		path := filepath.Join(dir, "progress-test.txt")
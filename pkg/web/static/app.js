@@ -337,6 +337,12 @@
         };
     }
 
+    // display label for a phase, custom names come from phase_names config via the page template
+    function phaseName(phase) {
+        var names = window.RALPHEX_PHASE_NAMES || {};
+        return names[phase] || phase;
+    }
+
     // look up task title by position (1-indexed) from plan data
     function getTaskTitle(taskNum) {
         if (!state.planData || !state.planData.tasks) return null;
@@ -444,7 +450,7 @@
 
         const phaseLabel = document.createElement('span');
         phaseLabel.className = 'section-phase';
-        phaseLabel.textContent = event.phase;
+        phaseLabel.textContent = phaseName(event.phase);
 
         const title = document.createElement('span');
         title.className = 'section-title';
@@ -830,6 +836,12 @@
             }
         }
 
+        // plan mode streams the plan being written into the plan panel
+        if (event.type === 'plan_update') {
+            renderPlanPreview(event);
+            return; // don't render as output
+        }
+
         // update status badge
         updateStatusBadge(event);
 
@@ -922,8 +934,13 @@
                     resetOutputState();
                     state.resetOnNextEvent = false;
                 }
-                // queue event for batch processing to avoid layout thrashing
-                state.eventQueue.push(event);
+                // queue event for batch processing to avoid layout thrashing.
+                // batch events carry several output lines coalesced by the server
+                if (event.type === 'batch' && event.events) {
+                    Array.prototype.push.apply(state.eventQueue, event.events);
+                } else {
+                    state.eventQueue.push(event);
+                }
                 processEventQueue();
             } catch (err) {
                 console.error('parse error:', err);
@@ -1399,6 +1416,9 @@
         var name = document.createElement('div');
         name.className = 'session-name';
         name.textContent = extractPlanName(session.planPath);
+        if (session.task) {
+            name.textContent += ' \u00b7 task ' + session.task;
+        }
 
         topRow.appendChild(indicator);
         topRow.appendChild(name);
@@ -1653,6 +1673,18 @@
             });
     }
 
+    // render the raw content of the plan being written in plan mode, replacing the previous preview
+    function renderPlanPreview(event) {
+        clearElement(planContent);
+        if (planNameEl && event.plan_file) {
+            planNameEl.textContent = event.plan_file;
+        }
+        const pre = document.createElement('pre');
+        pre.className = 'plan-preview';
+        pre.textContent = event.text;
+        planContent.appendChild(pre);
+    }
+
     /**
      * Render plan to plan panel using DOM methods.
      * XSS-safe: uses textContent for all user-provided text,
@@ -1714,6 +1746,9 @@
                 if (checkbox.checked) {
                     icon.classList.add('checked');
                     icon.textContent = '☑';
+                } else if (checkbox.in_progress) {
+                    icon.classList.add('in-progress');
+                    icon.textContent = '◐';
                 } else {
                     icon.textContent = '☐';
                 }